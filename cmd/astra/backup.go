@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/backup"
+	"github.com/shauryagautam/Astra/pkg/crypto"
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	"github.com/shauryagautam/Astra/pkg/engine/providers"
+	"github.com/shauryagautam/Astra/pkg/mail"
+	"github.com/shauryagautam/Astra/pkg/notification"
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+// openBackups builds a backup.Manager from the application's configuration:
+//
+//	BACKUP_DISK            local (default) or s3, using the S3_* settings
+//	BACKUP_S3_BUCKET       bucket for backups (default S3_BUCKET)
+//	BACKUP_LOCAL_ROOT      root of the local backup disk (default ".")
+//	BACKUP_DIR             directory on the disk (default "backups")
+//	BACKUP_ENCRYPTION_KEY  32-byte key; archives are encrypted when set
+//	BACKUP_KEEP_LAST       number of backups always kept
+//	BACKUP_KEEP_DAYS       days every backup is kept
+//	BACKUP_NOTIFY          comma-separated addresses mailed after each run
+//
+// withDB and withFiles select what the manager backs up or restores; the
+// returned DB, if any, must be closed by the caller.
+func openBackups(ctx context.Context, withDB, withFiles bool, tables []string) (*backup.Manager, *database.DB, error) {
+	env, err := config.Load(".env")
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := config.LoadFromEnv(env)
+
+	var disk storage.Storage
+	switch driver := env.String("BACKUP_DISK", "local"); driver {
+	case "local":
+		disk = storage.NewLocalStorage(env.String("BACKUP_LOCAL_ROOT", "."))
+	case "s3":
+		s3cfg := cfg.Storage
+		s3cfg.S3Bucket = env.String("BACKUP_S3_BUCKET", s3cfg.S3Bucket)
+		if disk, err = storage.NewS3Storage(ctx, s3cfg); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("backup: unknown BACKUP_DISK %q (want local or s3)", driver)
+	}
+
+	opts := []backup.Option{
+		backup.WithDir(env.String("BACKUP_DIR", "backups")),
+		backup.WithRetention(backup.Retention{
+			KeepLast: env.Int("BACKUP_KEEP_LAST", 0),
+			MaxAge:   time.Duration(env.Int("BACKUP_KEEP_DAYS", 0)) * 24 * time.Hour,
+		}),
+	}
+	if key := env.String("BACKUP_ENCRYPTION_KEY", ""); key != "" {
+		enc, err := crypto.NewEncrypter(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("BACKUP_ENCRYPTION_KEY: %w", err)
+		}
+		opts = append(opts, backup.WithEncryption(enc))
+	}
+	if to := splitList(env.String("BACKUP_NOTIFY", "")); len(to) > 0 {
+		mailer, err := mail.NewMailer(cfg.Mail, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		notifier := notification.New()
+		notifier.AddChannel(notification.NewMailChannel(mailer))
+		opts = append(opts, backup.WithNotifier(notifier, to...))
+	}
+	if withFiles {
+		opts = append(opts, backup.WithFiles(cfg.Storage.LocalRoot))
+	}
+	var db *database.DB
+	if withDB {
+		if db, err = providers.ProvideDB(env); err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, backup.WithDatabase(db, tables...))
+	}
+	return backup.New(disk, opts...), db, nil
+}
+
+func newBackupRunCmd() *cobra.Command {
+	var (
+		noDB    bool
+		noFiles bool
+		tables  string
+	)
+	cmd := &cobra.Command{
+		Use:   "backup:run",
+		Short: "Back up the database and stored files to the backup disk",
+		Long: `Archives the database tables and the files of the local storage disk into
+one tar.gz on the backup disk (BACKUP_DISK), encrypted when
+BACKUP_ENCRYPTION_KEY is set. Old backups are then pruned according to
+BACKUP_KEEP_LAST and BACKUP_KEEP_DAYS, and BACKUP_NOTIFY is mailed the
+outcome. Schedule it with cron or the scheduler.`,
+		Example: `  astra backup:run
+  astra backup:run --no-files --tables users,orders`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if noDB && noFiles {
+				return errors.New("backup:run: --no-database and --no-files leave nothing to back up")
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			m, db, err := openBackups(ctx, !noDB, !noFiles, splitList(tables))
+			if err != nil {
+				return err
+			}
+			if db != nil {
+				defer db.Close()
+			}
+
+			entry, err := m.Run(ctx)
+			if err != nil {
+				return err
+			}
+			console := ui.FromCommand(cmd)
+			printBackups(console, []backup.Entry{*entry})
+			console.Success("stored backup %s", entry.Name)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&noDB, "no-database", false, "do not back up the database")
+	cmd.Flags().BoolVar(&noFiles, "no-files", false, "do not back up the files of the local disk")
+	cmd.Flags().StringVarP(&tables, "tables", "t", "", "comma-separated tables to back up (default: all)")
+	return cmd
+}
+
+func newBackupListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup:list",
+		Short: "List the backups on the backup disk, newest first",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			m, _, err := openBackups(ctx, false, false, nil)
+			if err != nil {
+				return err
+			}
+			entries, err := m.List(ctx)
+			if err != nil {
+				return err
+			}
+			console := ui.FromCommand(cmd)
+			if len(entries) == 0 {
+				console.Info("no backups yet")
+				return nil
+			}
+			printBackups(console, entries)
+			return nil
+		},
+	}
+}
+
+func newBackupRestoreCmd() *cobra.Command {
+	var (
+		only     string
+		truncate bool
+		force    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "backup:restore <name>",
+		Short: "Restore the database and files from a backup",
+		Long: `Restores a backup listed by backup:list. Tables are restored in one
+transaction; --truncate replaces their rows instead of adding to them. Files
+are written back to the local storage disk, overwriting files with the same
+name.`,
+		Example: `  astra backup:restore astra-20261017-030000 --truncate
+  astra backup:restore astra-20261017-030000 --only files --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := backup.RestoreOptions{Database: true, Files: true, Truncate: truncate}
+			switch only {
+			case "":
+			case "database":
+				opts.Files = false
+			case "files":
+				opts.Database = false
+			default:
+				return fmt.Errorf("backup:restore: --only must be database or files, not %q", only)
+			}
+
+			console := ui.FromCommand(cmd)
+			if !force {
+				ok, err := console.Confirm(fmt.Sprintf("Restore %s over the current data?", args[0]), false)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errors.New("backup:restore: not confirmed; re-run with --force to skip the question")
+				}
+			}
+
+			ctx, cancel := signalContext()
+			defer cancel()
+			m, db, err := openBackups(ctx, opts.Database, opts.Files, nil)
+			if err != nil {
+				return err
+			}
+			if db != nil {
+				defer db.Close()
+			}
+
+			result, err := m.Restore(ctx, args[0], opts)
+			if err != nil {
+				return err
+			}
+			printTableRows(console, result.Tables)
+			console.Success("restored %d table(s) and %d file(s) from %s", len(result.Tables), result.Files, args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&only, "only", "", "restore only the database or only the files")
+	cmd.Flags().BoolVar(&truncate, "truncate", false, "empty the restored tables first")
+	cmd.Flags().BoolVar(&force, "force", false, "do not ask for confirmation")
+	return cmd
+}
+
+func printBackups(console *ui.Console, entries []backup.Entry) {
+	t := console.Table("Name", "Created", "Tables", "Rows", "Files", "Size", "Encrypted").
+		AlignColumn(2, ui.AlignRight).
+		AlignColumn(3, ui.AlignRight).
+		AlignColumn(4, ui.AlignRight).
+		AlignColumn(5, ui.AlignRight)
+	for _, e := range entries {
+		t.Row(e.Name, e.CreatedAt.Local().Format(time.DateTime), e.Tables, e.Rows, e.Files, e.Size, e.Encrypted)
+	}
+	t.Render()
+}
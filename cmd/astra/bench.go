@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/bench"
+	"github.com/spf13/cobra"
+)
+
+func newBenchCmd() *cobra.Command {
+	var (
+		opts      bench.Options
+		scenarios []string
+		target    string
+		paths     []string
+		asJSON    bool
+		maxP99    time.Duration
+		maxAllocs float64
+	)
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Load-test the router and report latency percentiles and allocations",
+		Long: `Boots an in-process server with representative routes (static, dynamic,
+middleware-heavy and DB-backed), drives each with concurrent keep-alive
+clients and reports throughput, p50/p90/p99/max latency and heap
+allocations per request.
+
+--max-p99 and --max-allocs make the command exit with status 1 when a
+scenario exceeds them, so it can catch performance regressions in CI. With
+--target it load-tests a running server instead, on the --path routes;
+allocations are then not reported.`,
+		Example: `  astra bench
+  astra bench --scenario static,db --duration 10s --concurrency 100
+  astra bench --requests 20000 --max-p99 5ms --max-allocs 60 --json
+  astra bench --target http://localhost:3000 --path /health --path /api/posts`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			external := target != ""
+			var selected []bench.Scenario
+			if !external {
+				srv, err := bench.NewServer()
+				if err != nil {
+					return err
+				}
+				defer srv.Close()
+				target = srv.URL
+				for _, sc := range bench.Scenarios() {
+					if len(scenarios) == 0 || slices.Contains(scenarios, sc.Name) {
+						selected = append(selected, sc)
+					}
+				}
+				if len(selected) == 0 {
+					return fmt.Errorf("no scenario matches %s", strings.Join(scenarios, ", "))
+				}
+			} else {
+				if len(paths) == 0 {
+					paths = []string{"/"}
+				}
+				for _, p := range paths {
+					selected = append(selected, bench.Scenario{Name: p, Path: p})
+				}
+			}
+
+			var results []bench.Result
+			for _, sc := range selected {
+				res, err := bench.Run(ctx, nil, target, sc, opts)
+				if err != nil {
+					return fmt.Errorf("scenario %s: %w", sc.Name, err)
+				}
+				if external {
+					res.AllocsPerReq, res.BytesPerReq = 0, 0
+				}
+				results = append(results, res)
+			}
+
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			} else if err := bench.WriteTable(out, results); err != nil {
+				return err
+			}
+
+			var failures []string
+			for _, r := range results {
+				if maxP99 > 0 && r.P99 > maxP99 {
+					failures = append(failures, fmt.Sprintf("%s: p99 %s exceeds %s", r.Scenario, r.P99, maxP99))
+				}
+				if maxAllocs > 0 && r.AllocsPerReq > maxAllocs {
+					failures = append(failures, fmt.Sprintf("%s: %.0f allocs/req exceeds %.0f", r.Scenario, r.AllocsPerReq, maxAllocs))
+				}
+				if r.Errors > 0 {
+					failures = append(failures, fmt.Sprintf("%s: %d of %d requests failed", r.Scenario, r.Errors, r.Requests))
+				}
+			}
+			if len(failures) > 0 {
+				return &exitCodeError{code: 1, msg: strings.Join(failures, "\n")}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&opts.Duration, "duration", 5*time.Second, "how long each scenario runs")
+	cmd.Flags().IntVar(&opts.Requests, "requests", 0, "stop each scenario after this many requests instead of --duration")
+	cmd.Flags().IntVarP(&opts.Concurrency, "concurrency", "c", 50, "number of concurrent clients")
+	cmd.Flags().DurationVar(&opts.Warmup, "warmup", time.Second, "load sent before measuring (negative = none)")
+	cmd.Flags().StringSliceVar(&scenarios, "scenario", nil, "scenarios to run: static, dynamic, middleware, db (default all)")
+	cmd.Flags().StringVar(&target, "target", "", "base URL of a running server to load-test instead of the built-in one")
+	cmd.Flags().StringArrayVar(&paths, "path", nil, "path to request on --target (repeatable, default /)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the results as JSON")
+	cmd.Flags().DurationVar(&maxP99, "max-p99", 0, "fail when a scenario's p99 latency exceeds this (0 = never)")
+	cmd.Flags().Float64Var(&maxAllocs, "max-allocs", 0, "fail when a scenario allocates more than this per request (0 = never)")
+	return cmd
+}
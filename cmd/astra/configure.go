@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/engine/scaffold"
+	"github.com/spf13/cobra"
+)
+
+func newConfigureCmd() *cobra.Command {
+	var (
+		manifest string
+		force    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "configure <" + strings.Join(scaffold.PresetNames(), "|") + ">",
+		Short: "Enable a subsystem: config file, provider stub, .env.example entries and manifest registration",
+		Long: `Writes config/<name>.yaml and, where the subsystem needs one, a provider stub
+in app/providers. Appends the subsystem's settings to .env.example and
+registers the provider in the application manifest. Existing files are kept
+unless --force is given, so the command is safe to re-run.`,
+		Example: `  astra configure redis
+  astra configure mail --manifest start/kernel.go`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: scaffold.PresetNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := scaffold.Configure(".", args[0], scaffold.Options{Manifest: manifest, Force: force})
+			out := cmd.OutOrStdout()
+			if res != nil {
+				for _, c := range res.Changes {
+					fmt.Fprintf(out, "  %-8s %s\n", c.Action, c.Path)
+				}
+				for _, n := range res.Notes {
+					fmt.Fprintf(out, "  • %s\n", n)
+				}
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&manifest, "manifest", scaffold.DefaultManifest, "file declaring the application's engine.Manifest")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the config file and provider stub")
+	return cmd
+}
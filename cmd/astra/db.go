@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/database/dump"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	"github.com/shauryagautam/Astra/pkg/engine/providers"
+	"github.com/spf13/cobra"
+)
+
+// openDB connects using the application's configuration, exactly as the
+// DatabaseProvider does at boot.
+func openDB() (*database.DB, error) {
+	cfg, err := config.Load(".env")
+	if err != nil {
+		return nil, err
+	}
+	return providers.ProvideDB(cfg)
+}
+
+// signalContext returns a context cancelled on Ctrl-C so long dumps stop cleanly.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func newDBDumpCmd() *cobra.Command {
+	var (
+		out    string
+		tables string
+		format string
+		chunk  int
+	)
+	cmd := &cobra.Command{
+		Use:   "db:dump",
+		Short: "Export tables to JSON, CSV or SQL files",
+		Example: `  astra db:dump --out storage/dumps/today
+  astra db:dump --tables users,teams --format csv`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			db, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			results, err := dump.Dump(ctx, db, out, dump.Options{
+				Tables:    splitList(tables),
+				Format:    dump.Format(format),
+				ChunkSize: chunk,
+			})
+			console := ui.FromCommand(cmd)
+			printTableRows(console, results)
+			if err != nil {
+				return err
+			}
+			console.Success("dumped %d table(s) to %s", len(results), out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "storage/dumps", "output directory")
+	cmd.Flags().StringVarP(&tables, "tables", "t", "", "comma-separated tables to dump (default: all)")
+	cmd.Flags().StringVarP(&format, "format", "f", string(dump.FormatJSON), "json, csv or sql")
+	cmd.Flags().IntVar(&chunk, "chunk", dump.DefaultChunkSize, "rows per flush")
+	return cmd
+}
+
+func newDBRestoreCmd() *cobra.Command {
+	var (
+		in       string
+		tables   string
+		format   string
+		chunk    int
+		truncate bool
+	)
+	cmd := &cobra.Command{
+		Use:   "db:restore",
+		Short: "Load tables from a db:dump directory",
+		Example: `  astra db:restore --in storage/dumps/today --truncate
+  astra db:restore --in fixtures --tables users --format csv`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			db, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			results, err := dump.Restore(ctx, db, in, dump.Options{
+				Tables:    splitList(tables),
+				Format:    dump.Format(format),
+				ChunkSize: chunk,
+				Truncate:  truncate,
+			})
+			if err != nil {
+				return err
+			}
+			console := ui.FromCommand(cmd)
+			printTableRows(console, results)
+			console.Success("restored %d table(s) from %s", len(results), in)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&in, "in", "i", "storage/dumps", "directory written by db:dump")
+	cmd.Flags().StringVarP(&tables, "tables", "t", "", "comma-separated tables to restore (default: all files)")
+	cmd.Flags().StringVarP(&format, "format", "f", string(dump.FormatJSON), "json, csv or sql")
+	cmd.Flags().IntVar(&chunk, "chunk", dump.DefaultChunkSize, "rows per INSERT batch")
+	cmd.Flags().BoolVar(&truncate, "truncate", false, "delete existing rows before restoring")
+	return cmd
+}
+
+// printTableRows lists the rows dumped or restored per table.
+func printTableRows(console *ui.Console, results []dump.TableResult) {
+	if len(results) == 0 {
+		return
+	}
+	t := console.Table("Table", "Rows").AlignColumn(1, ui.AlignRight)
+	for _, r := range results {
+		t.Row(r.Table, r.Rows)
+	}
+	t.Render()
+}
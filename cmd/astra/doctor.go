@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCmd() *cobra.Command {
+	var env string
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Audit the configuration for settings that are unsafe in production",
+		Long: `Loads the configuration the way the application does and reports missing,
+short or well-known secrets, APP_DEBUG, CORS allowing any origin with
+credentials and unencrypted database connections. In production these stop
+the app from booting unless it is started with --force; the command exits
+with status 1 in the same case, so it can gate a deploy.`,
+		Example: `  astra doctor
+  astra doctor --env production`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			raw, err := config.Load(".env")
+			if err != nil {
+				return err
+			}
+			cfg := config.LoadFromEnv(raw)
+			if env != "" {
+				cfg.App.Environment = env
+			}
+
+			report := cfg.Doctor()
+			out := cmd.OutOrStdout()
+			for _, f := range report.Findings {
+				fmt.Fprintf(out, "  %-7s %-22s %s\n", f.Severity, f.Key, f.Message)
+			}
+			if report.Failed() {
+				return &exitCodeError{code: 1, msg: fmt.Sprintf("%s configuration is not safe to boot", report.Environment)}
+			}
+			fmt.Fprintf(out, "✓ %s configuration passed the audit\n", report.Environment)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&env, "env", "", "audit as if APP_ENV had this value, e.g. production")
+	return cmd
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newErrorsCmd() *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "errors",
+		Short: "List the framework error codes",
+		Long: `Lists the stable error codes the framework sets in the "code" field of its
+JSON error responses, with their HTTP status. --json prints the catalog in
+a machine-readable form, for generating client-side error types.`,
+		Example: `  astra errors
+  astra errors --json > error-codes.json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(astraerrors.Catalog())
+			}
+			out := ui.FromCommand(cmd)
+			table := out.Table("CODE", "STATUS", "DESCRIPTION")
+			for _, info := range astraerrors.Catalog() {
+				table.Row(info.Code, info.Status, info.Description)
+			}
+			table.Render()
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the catalog as JSON")
+	return cmd
+}
@@ -0,0 +1,64 @@
+// Command astra is the Astra framework CLI. Run it from an application root
+// so it picks up the project's .env and config/ directory.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shauryagautam/Astra/pkg/engine/console"
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		code := 1
+		var ec *exitCodeError
+		if errors.As(err, &ec) {
+			code = ec.code
+		}
+		os.Exit(code)
+	}
+}
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "astra",
+		Short:         "Astra framework command line",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.AddCommand(
+		newBuildCmd(),
+		newBenchCmd(),
+		newVersionCmd(),
+		newDBDumpCmd(),
+		newDBRestoreCmd(),
+		newDBAnonymizeCmd(),
+		newBackupRunCmd(),
+		newBackupListCmd(),
+		newBackupRestoreCmd(),
+		newMigrationStatusCmd(),
+		newMigrationRunCmd(),
+		newGenerateRoutesCmd(),
+		newGenerateClientCmd(),
+		newConfigureCmd(),
+		newDoctorCmd(),
+		newErrorsCmd(),
+		newSettingsGetCmd(),
+		newSettingsSetCmd(),
+		newSettingsListCmd(),
+		// queue:work, db:seed and migration:fresh need the application's job
+		// handlers and seeders, so they are mounted in the application's own
+		// binary instead; see package console.
+		console.MakeMigrationCommand(),
+		console.MakeRequestCommand(),
+		console.PrivacyExportCommand(nil),
+		console.PrivacyEraseCommand(nil),
+		console.VendorPublishCommand(),
+		console.ViewCacheCommand(nil),
+	)
+	return root
+}
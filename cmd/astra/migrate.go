@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/database/migration"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	"github.com/shauryagautam/Astra/pkg/engine/providers"
+	"github.com/spf13/cobra"
+)
+
+// exitCodeError makes the CLI exit with code instead of the usual 1, for
+// commands whose status is read by scripts.
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e *exitCodeError) Error() string { return e.msg }
+
+// migrationStatus is the --json output of migration:status.
+type migrationStatus struct {
+	Applied []appliedMigration `json:"applied"`
+	Pending []string           `json:"pending"`
+}
+
+type appliedMigration struct {
+	Name  string    `json:"name"`
+	Batch int       `json:"batch"`
+	RunAt time.Time `json:"run_at"`
+}
+
+func newMigrationStatusCmd() *cobra.Command {
+	var (
+		dir         string
+		asJSON      bool
+		pendingExit int
+	)
+	cmd := &cobra.Command{
+		Use:   "migration:status",
+		Short: "List applied and pending migrations",
+		Long: `Lists applied and pending migrations. With --pending-exit-code the command
+exits with that status when migrations are pending, so a deploy pipeline can
+stop before new code meets an old schema.`,
+		Example: `  astra migration:status
+  astra migration:status --json --pending-exit-code=3`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			db, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			applied, pending, err := migration.NewRunner(db.Pool(), dir, nil).Status(ctx)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if asJSON {
+				status := migrationStatus{Applied: []appliedMigration{}, Pending: []string{}}
+				for _, rec := range applied {
+					status.Applied = append(status.Applied, appliedMigration{rec.Name, rec.Batch, rec.RunAt})
+				}
+				status.Pending = append(status.Pending, pending...)
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(status); err != nil {
+					return err
+				}
+			} else {
+				console := ui.FromCommand(cmd)
+				if len(applied)+len(pending) > 0 {
+					t := console.Table("Status", "Batch", "Migration", "Run at").AlignColumn(1, ui.AlignRight)
+					for _, rec := range applied {
+						t.Row(console.Green("Applied"), rec.Batch, rec.Name, rec.RunAt.Format(time.RFC3339))
+					}
+					for _, name := range pending {
+						t.Row(console.Yellow("Pending"), "", name, "")
+					}
+					t.Render()
+				}
+				console.Printf("%d applied, %d pending\n", len(applied), len(pending))
+			}
+
+			if len(pending) > 0 && pendingExit != 0 {
+				return &exitCodeError{code: pendingExit, msg: fmt.Sprintf("%d pending migration(s)", len(pending))}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "database/migrations", "migrations directory")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the status as JSON")
+	cmd.Flags().IntVar(&pendingExit, "pending-exit-code", 0, "exit with this status when migrations are pending (0 = never fail)")
+	return cmd
+}
+
+func newMigrationRunCmd() *cobra.Command {
+	var (
+		dir   string
+		force bool
+	)
+	cmd := &cobra.Command{
+		Use:   "migration:run",
+		Short: "Apply pending migrations",
+		Long: `Applies pending migrations in one batch. When APP_ENV is production the
+command refuses to run unless --force is given, so a stray local shell pointed
+at the production database cannot migrate it by accident.`,
+		Example: `  astra migration:run
+  APP_ENV=production astra migration:run --force`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			env, err := config.Load(".env")
+			if err != nil {
+				return err
+			}
+			if env.IsProd() && !force {
+				return errors.New("migration:run: APP_ENV is production; re-run with --force to apply migrations")
+			}
+			db, err := providers.ProvideDB(env)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			spinner := ui.FromCommand(cmd).Spinner("Applying migrations")
+			if err := migration.NewRunner(db.Pool(), dir, nil).Run(ctx); err != nil {
+				spinner.Fail("migrations failed")
+				return err
+			}
+			spinner.Success("migrations applied")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "database/migrations", "migrations directory")
+	cmd.Flags().BoolVar(&force, "force", false, "allow running in production")
+	return cmd
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+// openSettings opens the settings table without a cache: the CLI must read
+// what is stored, and running instances pick up its writes once their own
+// cache entries expire.
+func openSettings(ctx context.Context) (*settings.Store, *database.DB, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	store := settings.New(db, settings.WithCache(nil, 0))
+	if err := store.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return store, db, nil
+}
+
+func newSettingsGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "settings:get <key>",
+		Short:   "Print a runtime setting as JSON",
+		Example: `  astra settings:get site.name`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			store, db, err := openSettings(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			raw, found, err := store.Raw(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			if !found {
+				return &exitCodeError{code: 1, msg: fmt.Sprintf("setting %q is not set", args[0])}
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), raw)
+			return nil
+		},
+	}
+}
+
+func newSettingsSetCmd() *cobra.Command {
+	var remove bool
+	cmd := &cobra.Command{
+		Use:   "settings:set <key> [value]",
+		Short: "Change a runtime setting without a redeploy",
+		Long: `Stores value under key. JSON values such as 20, true or ["a","b"] keep their
+type; anything else is stored as a string.`,
+		Example: `  astra settings:set site.name "Acme Inc"
+  astra settings:set uploads.max_mb 20
+  astra settings:set signups.open --delete`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !remove && len(args) != 2 {
+				return fmt.Errorf("settings:set needs a value, or --delete")
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			store, db, err := openSettings(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			if remove {
+				if err := store.Delete(ctx, args[0]); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "✓ deleted %s\n", args[0])
+				return nil
+			}
+			value := settings.ParseValue(args[1])
+			if err := store.SetRaw(ctx, args[0], value); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ %s = %s\n", args[0], value)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&remove, "delete", false, "delete the setting so its default applies")
+	return cmd
+}
+
+func newSettingsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "settings:list",
+		Short: "List runtime settings",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			store, db, err := openSettings(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			all, err := store.All(ctx)
+			if err != nil {
+				return err
+			}
+			keys := make([]string, 0, len(all))
+			for k := range all {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %-32s %s\n", k, all[k])
+			}
+			return nil
+		},
+	}
+}
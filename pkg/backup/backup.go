@@ -0,0 +1,566 @@
+// Package backup takes backups of the database and of the files on the
+// local storage disk, and stores them on another disk, typically S3. It
+// backs the astra backup:run, backup:list and backup:restore commands.
+//
+//	m := backup.New(s3Disk,
+//	    backup.WithDatabase(db),
+//	    backup.WithFiles("storage"),
+//	    backup.WithEncryption(encrypter),
+//	    backup.WithRetention(backup.Retention{KeepLast: 7, MaxAge: 30 * 24 * time.Hour}),
+//	    backup.WithNotifier(notifier, "ops@example.com"),
+//	)
+//	entry, err := m.Run(ctx)
+//
+// A backup is one tar.gz archive holding the tables as JSON files (see
+// pkg/database/dump) under database/, the files under files/ and a
+// manifest.json. The archive is streamed to the disk as it is written, and
+// encrypted archives are sealed with AES-256-GCM in authenticated chunks
+// (see crypto.Encrypter.SealWriter), so a backup is never held in memory.
+// The disk keeps an index.json next to the archives, since disks cannot
+// list files.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/crypto"
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/database/dump"
+	"github.com/shauryagautam/Astra/pkg/notification"
+	"github.com/shauryagautam/Astra/pkg/storage"
+)
+
+// ErrNotFound is returned by Restore for a backup missing from the index.
+var ErrNotFound = errors.New("backup: not found")
+
+// Entry describes a stored backup.
+type Entry struct {
+	// Name identifies the backup, e.g. "astra-20261017-030000".
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+	Encrypted bool      `json:"encrypted"`
+	Tables    int       `json:"tables"`
+	Rows      int64     `json:"rows"`
+	Files     int       `json:"files"`
+}
+
+// Retention decides which backups Prune deletes. A backup is deleted once it
+// is neither among the KeepLast newest nor younger than MaxAge; a zero field
+// does not keep anything by itself. The newest backup is always kept, and
+// the zero Retention keeps everything.
+type Retention struct {
+	KeepLast int
+	MaxAge   time.Duration
+}
+
+// Manager takes, lists, prunes and restores backups.
+type Manager struct {
+	disk      storage.Storage
+	dir       string
+	name      string
+	db        *database.DB
+	tables    []string
+	files     string
+	encrypter *crypto.Encrypter
+	retention Retention
+	notifier  *notification.Notifier
+	notifyTo  []string
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithDatabase backs up the tables of db, or only the given ones.
+func WithDatabase(db *database.DB, tables ...string) Option {
+	return func(m *Manager) { m.db, m.tables = db, tables }
+}
+
+// WithFiles backs up the files under root, the root of the local disk.
+func WithFiles(root string) Option {
+	return func(m *Manager) { m.files = root }
+}
+
+// WithEncryption encrypts archives with e. Restoring them needs the same key.
+func WithEncryption(e *crypto.Encrypter) Option {
+	return func(m *Manager) { m.encrypter = e }
+}
+
+// WithRetention prunes old backups after every successful Run.
+func WithRetention(r Retention) Option {
+	return func(m *Manager) { m.retention = r }
+}
+
+// WithNotifier sends a Notification to the addresses in to after every Run,
+// whether it succeeded or failed.
+func WithNotifier(n *notification.Notifier, to ...string) Option {
+	return func(m *Manager) { m.notifier, m.notifyTo = n, to }
+}
+
+// WithDir stores backups under dir on the disk (default "backups").
+func WithDir(dir string) Option {
+	return func(m *Manager) { m.dir = strings.Trim(dir, "/") }
+}
+
+// WithName prefixes backup names with name (default "astra").
+func WithName(name string) Option {
+	return func(m *Manager) { m.name = name }
+}
+
+// WithLogger logs pruning and notification failures, which do not fail Run.
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Manager) { m.logger = logger }
+}
+
+// New creates a Manager storing backups on disk.
+func New(disk storage.Storage, opts ...Option) *Manager {
+	m := &Manager{
+		disk:   disk,
+		dir:    "backups",
+		name:   "astra",
+		logger: slog.Default(),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// manifest is manifest.json in an archive.
+type manifest struct {
+	Name      string             `json:"name"`
+	CreatedAt time.Time          `json:"created_at"`
+	Tables    []dump.TableResult `json:"tables"`
+	Files     int                `json:"files"`
+}
+
+// Run takes a backup, stores it, prunes old backups and sends the
+// notification.
+func (m *Manager) Run(ctx context.Context) (*Entry, error) {
+	entry, err := m.run(ctx)
+	if err == nil {
+		if _, perr := m.Prune(ctx); perr != nil {
+			m.logger.Warn("backup: pruning old backups failed", "error", perr)
+		}
+	}
+	if m.notifier != nil {
+		if nerr := m.notifier.Send(ctx, &Notification{Entry: entry, Err: err, To: m.notifyTo}); nerr != nil {
+			m.logger.Warn("backup: sending the notification failed", "error", nerr)
+		}
+	}
+	return entry, err
+}
+
+func (m *Manager) run(ctx context.Context) (*Entry, error) {
+	if m.db == nil && m.files == "" {
+		return nil, errors.New("backup: nothing to back up; configure a database or files")
+	}
+	created := m.now().UTC()
+	entry := &Entry{
+		Name:      m.name + "-" + created.Format("20060102-150405"),
+		CreatedAt: created,
+		Encrypted: m.encrypter != nil,
+	}
+
+	entry.Path = path.Join(m.dir, entry.Name+".tar.gz")
+	if m.encrypter != nil {
+		entry.Path += ".enc"
+	}
+
+	// The archive is written into a pipe the disk reads from.
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := m.writeArchive(ctx, pw, entry)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+	stored := &countingReader{r: pr}
+	putErr := storage.PutStream(ctx, m.disk, entry.Path, stored)
+	// Unblock the writer if the disk gave up before the end.
+	pr.CloseWithError(errors.New("backup: the disk stopped reading the archive"))
+	archiveErr := <-done
+	if archiveErr != nil && (putErr == nil || errors.Is(putErr, archiveErr)) {
+		return nil, archiveErr
+	}
+	if putErr != nil {
+		return nil, fmt.Errorf("backup: failed to store %s: %w", entry.Path, putErr)
+	}
+	entry.Size = stored.n
+
+	entries, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.writeIndex(ctx, append(entries, *entry)); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// writeArchive writes the archive to w, encrypted when the Manager has an
+// encrypter, and fills in what entry says about its content.
+func (m *Manager) writeArchive(ctx context.Context, w io.Writer, entry *Entry) error {
+	out := w
+	var sealer io.WriteCloser
+	if m.encrypter != nil {
+		var err error
+		if sealer, err = m.encrypter.SealWriter(w); err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		out = sealer
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	man := manifest{Name: entry.Name, CreatedAt: entry.CreatedAt}
+
+	if m.db != nil {
+		tables, err := m.dumpDatabase(ctx, tw)
+		if err != nil {
+			return err
+		}
+		man.Tables = tables
+		entry.Tables = len(tables)
+		for _, t := range tables {
+			entry.Rows += t.Rows
+		}
+	}
+	if m.files != "" {
+		n, err := addFiles(ctx, tw, m.files)
+		if err != nil {
+			return err
+		}
+		man.Files, entry.Files = n, n
+	}
+	encoded, err := json.MarshalIndent(man, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := addFile(tw, "manifest.json", encoded, entry.CreatedAt); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("backup: failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("backup: failed to write archive: %w", err)
+	}
+	if sealer != nil {
+		if err := sealer.Close(); err != nil {
+			return fmt.Errorf("backup: failed to write archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// dumpDatabase dumps the tables to a temporary directory and adds them to
+// the archive under database/.
+func (m *Manager) dumpDatabase(ctx context.Context, tw *tar.Writer) ([]dump.TableResult, error) {
+	tmp, err := os.MkdirTemp("", "astra-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	results, err := dump.Dump(ctx, m.db, tmp, dump.Options{Tables: m.tables, Format: dump.FormatJSON})
+	if err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	for _, r := range results {
+		if err := copyFile(tw, "database/"+r.Table+".json", filepath.Join(tmp, r.Table+".json")); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// addFiles adds the regular files under root to the archive under files/.
+func addFiles(ctx context.Context, tw *tar.Writer, root string) (int, error) {
+	n := 0
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		n++
+		return copyFile(tw, "files/"+filepath.ToSlash(rel), p)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("backup: failed to archive files: %w", err)
+	}
+	return n, nil
+}
+
+func addFile(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), ModTime: modTime}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("backup: failed to write archive: %w", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("backup: failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// copyFile adds the file at src to the archive as name, streaming its
+// content.
+func copyFile(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src) // #nosec G304 -- src is a dump file or found by walking the files root
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0o600, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("backup: failed to write archive: %w", err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("backup: failed to write archive: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) indexPath() string {
+	return path.Join(m.dir, "index.json")
+}
+
+// List returns the stored backups, newest first.
+func (m *Manager) List(ctx context.Context) ([]Entry, error) {
+	exists, err := m.disk.Exists(ctx, m.indexPath())
+	if err != nil {
+		return nil, fmt.Errorf("backup: failed to read the index: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	content, err := m.disk.Get(ctx, m.indexPath())
+	if err != nil {
+		return nil, fmt.Errorf("backup: failed to read the index: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("backup: %s is corrupt: %w", m.indexPath(), err)
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func (m *Manager) writeIndex(ctx context.Context, entries []Entry) error {
+	sortEntries(entries)
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := m.disk.Put(ctx, m.indexPath(), content); err != nil {
+		return fmt.Errorf("backup: failed to write the index: %w", err)
+	}
+	return nil
+}
+
+func sortEntries(entries []Entry) {
+	slices.SortFunc(entries, func(a, b Entry) int { return b.CreatedAt.Compare(a.CreatedAt) })
+}
+
+// Prune deletes the backups the retention policy no longer keeps and
+// returns them.
+func (m *Manager) Prune(ctx context.Context) ([]Entry, error) {
+	if m.retention.KeepLast <= 0 && m.retention.MaxAge <= 0 {
+		return nil, nil
+	}
+	entries, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	var kept, pruned []Entry
+	for i, e := range entries {
+		keep := i == 0 ||
+			(m.retention.KeepLast > 0 && i < m.retention.KeepLast) ||
+			(m.retention.MaxAge > 0 && now.Sub(e.CreatedAt) < m.retention.MaxAge)
+		if keep {
+			kept = append(kept, e)
+			continue
+		}
+		if err := m.disk.Delete(ctx, e.Path); err != nil {
+			return nil, fmt.Errorf("backup: failed to delete %s: %w", e.Path, err)
+		}
+		pruned = append(pruned, e)
+	}
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+	return pruned, m.writeIndex(ctx, kept)
+}
+
+// RestoreOptions selects what Restore puts back.
+type RestoreOptions struct {
+	// Database restores the tables, in one transaction.
+	Database bool
+	// Truncate empties the restored tables first, so rows are replaced
+	// rather than added.
+	Truncate bool
+	// Files writes the files back under the files root, overwriting files
+	// with the same name. Other files are left alone.
+	Files bool
+}
+
+// RestoreResult is what Restore put back.
+type RestoreResult struct {
+	Tables []dump.TableResult
+	Files  int
+}
+
+// Restore puts back the backup called name, as returned by List. The
+// archive is streamed from the disk and each encrypted chunk is checked
+// before it is used. Files are written as they are read, so an archive
+// found damaged part way may leave some of them restored; the database is
+// only restored once the whole archive has been read.
+func (m *Manager) Restore(ctx context.Context, name string, opts RestoreOptions) (*RestoreResult, error) {
+	entries, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(entries, func(e Entry) bool { return e.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	entry := entries[i]
+	if opts.Database && m.db == nil {
+		return nil, errors.New("backup: restoring the database needs WithDatabase")
+	}
+	if opts.Files && m.files == "" {
+		return nil, errors.New("backup: restoring files needs WithFiles")
+	}
+
+	if entry.Encrypted && m.encrypter == nil {
+		return nil, fmt.Errorf("backup: %s is encrypted; configure the encryption key", name)
+	}
+	rc, err := storage.Open(ctx, m.disk, entry.Path)
+	if err != nil {
+		return nil, fmt.Errorf("backup: failed to read %s: %w", entry.Path, err)
+	}
+	defer rc.Close()
+	archive := io.Reader(rc)
+	if entry.Encrypted {
+		if archive, err = m.encrypter.OpenReader(rc); err != nil {
+			return nil, fmt.Errorf("backup: %w", err)
+		}
+	}
+
+	tmp, err := os.MkdirTemp("", "astra-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	result := &RestoreResult{}
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %s is not a backup archive: %w", name, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("backup: failed to read %s: %w", name, err)
+		}
+		var dst string
+		switch {
+		case opts.Database && strings.HasPrefix(hdr.Name, "database/"):
+			dst, err = safeJoin(tmp, strings.TrimPrefix(hdr.Name, "database/"))
+		case opts.Files && strings.HasPrefix(hdr.Name, "files/"):
+			dst, err = safeJoin(m.files, strings.TrimPrefix(hdr.Name, "files/"))
+			result.Files++
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFile(dst, tr); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Database {
+		tables, err := dump.Restore(ctx, m.db, tmp, dump.Options{Format: dump.FormatJSON, Truncate: opts.Truncate})
+		if err != nil {
+			return nil, fmt.Errorf("backup: %w", err)
+		}
+		result.Tables = tables
+	}
+	return result, nil
+}
+
+// safeJoin joins name to root, refusing names that escape it.
+func safeJoin(root, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("backup: archive entry %q escapes the target directory", name)
+	}
+	return filepath.Join(root, cleaned), nil
+}
+
+func writeFile(dst string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) // #nosec G304 -- dst is checked by safeJoin
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { // #nosec G110 -- archives are our own backups
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
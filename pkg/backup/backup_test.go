@@ -0,0 +1,183 @@
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/crypto"
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/notification"
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T, seed bool) *database.DB {
+	t.Helper()
+	ctx := context.Background()
+	db, err := database.Open(database.Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.Pool().SetMaxOpenConns(1)
+
+	_, err = db.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL)")
+	require.NoError(t, err)
+	if seed {
+		_, err = db.Exec(ctx, "INSERT INTO users (id, email) VALUES (1, 'ada@example.com'), (2, 'bob@example.com')")
+		require.NoError(t, err)
+	}
+	return db
+}
+
+type recordingChannel struct {
+	sent []*Notification
+}
+
+func (c *recordingChannel) Name() string { return "mail" }
+
+func (c *recordingChannel) Send(_ context.Context, n notification.Notification) error {
+	c.sent = append(c.sent, n.(*Notification))
+	return nil
+}
+
+func TestRunAndRestore(t *testing.T) {
+	ctx := context.Background()
+	files := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(files, "avatars"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(files, "avatars", "1.png"), []byte("png"), 0o600))
+
+	enc, err := crypto.NewEncrypter("01234567890123456789012345678901")
+	require.NoError(t, err)
+	disk := storage.NewMemoryStorage()
+	channel := &recordingChannel{}
+	notifier := notification.New()
+	notifier.AddChannel(channel)
+
+	m := New(disk, WithDatabase(openDB(t, true)), WithFiles(files), WithEncryption(enc), WithNotifier(notifier, "ops@example.com"))
+	entry, err := m.Run(ctx)
+	require.NoError(t, err)
+	assert.True(t, entry.Encrypted)
+	assert.Equal(t, 1, entry.Tables)
+	assert.Equal(t, int64(2), entry.Rows)
+	assert.Equal(t, 1, entry.Files)
+
+	require.Len(t, channel.sent, 1)
+	msg := channel.sent[0].ToMail()
+	assert.Equal(t, []string{"ops@example.com"}, msg.To)
+	assert.Contains(t, msg.Subject, "succeeded")
+
+	entries, err := m.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, entry.Name, entries[0].Name)
+
+	// The stored archive is not readable without the key.
+	stored, err := disk.Get(ctx, entry.Path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "ada@example.com")
+
+	// Restore into an empty database and files directory.
+	target := openDB(t, false)
+	restoredFiles := t.TempDir()
+	r := New(disk, WithDatabase(target), WithFiles(restoredFiles), WithEncryption(enc))
+	result, err := r.Restore(ctx, entry.Name, RestoreOptions{Database: true, Files: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Files)
+
+	var count int
+	require.NoError(t, target.QueryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&count))
+	assert.Equal(t, 2, count)
+	content, err := os.ReadFile(filepath.Join(restoredFiles, "avatars", "1.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(content))
+
+	_, err = New(disk, WithDatabase(target)).Restore(ctx, entry.Name, RestoreOptions{Database: true})
+	assert.ErrorContains(t, err, "encrypted")
+	_, err = r.Restore(ctx, "missing", RestoreOptions{Database: true})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRunStreamsEncryptedArchives(t *testing.T) {
+	ctx := context.Background()
+	files := t.TempDir()
+	big := make([]byte, 300<<10)
+	_, _ = rand.Read(big)
+	require.NoError(t, os.WriteFile(filepath.Join(files, "big.bin"), big, 0o600))
+
+	enc, err := crypto.NewEncrypter("01234567890123456789012345678901")
+	require.NoError(t, err)
+	root := t.TempDir()
+	disk := storage.NewLocalStorage(root)
+	entry, err := New(disk, WithFiles(files), WithEncryption(enc)).Run(ctx)
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(root, entry.Path))
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), entry.Size)
+
+	restored := t.TempDir()
+	r := New(disk, WithFiles(restored), WithEncryption(enc))
+	_, err = r.Restore(ctx, entry.Name, RestoreOptions{Files: true})
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(restored, "big.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, big, content)
+
+	// A changed byte anywhere in the archive fails the restore.
+	archive, err := os.ReadFile(filepath.Join(root, entry.Path))
+	require.NoError(t, err)
+	archive[len(archive)/2] ^= 1
+	require.NoError(t, os.WriteFile(filepath.Join(root, entry.Path), archive, 0o600))
+	_, err = r.Restore(ctx, entry.Name, RestoreOptions{Files: true})
+	assert.ErrorContains(t, err, "truncated or corrupt")
+}
+
+func TestRetention(t *testing.T) {
+	ctx := context.Background()
+	disk := storage.NewMemoryStorage()
+	now := time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+	m := New(disk, WithDatabase(openDB(t, true)), WithRetention(Retention{KeepLast: 2, MaxAge: 3 * 24 * time.Hour}))
+	m.now = func() time.Time { return now }
+
+	var paths []string
+	for range 5 {
+		entry, err := m.Run(ctx)
+		require.NoError(t, err)
+		paths = append(paths, entry.Path)
+		now = now.Add(24 * time.Hour)
+	}
+
+	// The last run was on day 5: days 3-5 are younger than three days,
+	// and the two newest would be kept anyway.
+	entries, err := m.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	for i, p := range paths {
+		exists, err := disk.Exists(ctx, p)
+		require.NoError(t, err)
+		assert.Equal(t, i >= 2, exists, p)
+	}
+}
+
+func TestRunFailureNotifies(t *testing.T) {
+	channel := &recordingChannel{}
+	notifier := notification.New()
+	notifier.AddChannel(channel)
+
+	_, err := New(storage.NewMemoryStorage(), WithNotifier(notifier)).Run(context.Background())
+	require.Error(t, err)
+	require.Len(t, channel.sent, 1)
+	assert.Equal(t, err, channel.sent[0].Err)
+	assert.Equal(t, "Backup failed", channel.sent[0].ToMail().Subject)
+}
+
+func TestSafeJoin(t *testing.T) {
+	_, err := safeJoin("/srv/storage", "../etc/passwd")
+	assert.Error(t, err)
+	p, err := safeJoin("/srv/storage", "a/b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/srv/storage", "a", "b.txt"), p)
+}
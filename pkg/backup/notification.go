@@ -0,0 +1,35 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/shauryagautam/Astra/pkg/mail"
+)
+
+// Notification reports the outcome of a Run by mail. Err is nil on success,
+// in which case Entry describes the stored backup.
+type Notification struct {
+	Entry *Entry
+	Err   error
+	To    []string
+}
+
+func (n *Notification) Via() []string { return []string{"mail"} }
+
+// ToMail implements notification.MailableNotification.
+func (n *Notification) ToMail() *mail.Message {
+	if n.Err != nil {
+		return &mail.Message{
+			To:      n.To,
+			Subject: "Backup failed",
+			Body:    fmt.Sprintf("The backup failed:\n\n%v\n", n.Err),
+		}
+	}
+	e := n.Entry
+	return &mail.Message{
+		To:      n.To,
+		Subject: "Backup " + e.Name + " succeeded",
+		Body: fmt.Sprintf("Backup %s was stored at %s.\n\nTables: %d (%d rows)\nFiles: %d\nSize: %d bytes\nEncrypted: %t\n",
+			e.Name, e.Path, e.Tables, e.Rows, e.Files, e.Size, e.Encrypted),
+	}
+}
@@ -0,0 +1,176 @@
+// Package broadcast pushes application events to realtime clients. Events
+// that implement Broadcastable are serialized once and fanned out to every
+// configured Driver — WebSocket rooms, SSE streams or outbound webhooks — so
+// controllers only emit events and never talk to a hub directly:
+//
+//	b := broadcast.New(broadcast.NewRealtimeDriver(rooms), sse)
+//	b.Channel("orders.{id}", func(ctx context.Context, user *claims.AuthClaims, p map[string]string) bool {
+//		return user != nil && ownsOrder(ctx, user.UserID, p["id"])
+//	})
+//	b.Listen(event.DefaultEmitter)
+//
+// Channel names are dot-separated. Channels prefixed with "private-" or
+// "presence-" require an authorizer registered with Channel; other channels
+// are public unless a matching authorizer says otherwise.
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+)
+
+// Broadcastable is implemented by events that should reach clients.
+type Broadcastable interface {
+	event.Event
+	// BroadcastOn lists the channels the event is published to.
+	BroadcastOn() []string
+}
+
+// Named lets a Broadcastable override the event name sent to clients,
+// which otherwise is Name().
+type Named interface {
+	BroadcastAs() string
+}
+
+// Payloader lets a Broadcastable control its serialized payload, which
+// otherwise is Data(). Use it to keep internal fields off the wire.
+type Payloader interface {
+	BroadcastWith() any
+}
+
+// Driver delivers a serialized event to one transport.
+type Driver interface {
+	Name() string
+	Broadcast(ctx context.Context, channels []string, event string, payload []byte) error
+}
+
+// ChannelAuthorizer decides whether user may subscribe to a channel. params
+// holds the values captured by the pattern's {placeholders}. user is nil
+// for guests.
+type ChannelAuthorizer func(ctx context.Context, user *identityclaims.AuthClaims, params map[string]string) bool
+
+type channelRule struct {
+	pattern   []string
+	authorize ChannelAuthorizer
+}
+
+// Broadcaster serializes Broadcastable events and hands them to drivers.
+type Broadcaster struct {
+	drivers []Driver
+
+	mu    sync.RWMutex
+	rules []channelRule
+}
+
+// New creates a Broadcaster that publishes to drivers.
+func New(drivers ...Driver) *Broadcaster {
+	return &Broadcaster{drivers: drivers}
+}
+
+// Channel registers the authorizer for channels matching pattern. Pattern
+// segments are separated by dots; "{name}" matches any single segment and
+// captures it, and "*" globs within a segment ("private-*"). The first
+// matching registration wins.
+func (b *Broadcaster) Channel(pattern string, authorize ChannelAuthorizer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rules = append(b.rules, channelRule{pattern: strings.Split(pattern, "."), authorize: authorize})
+}
+
+// Authorize reports whether user may subscribe to channel.
+func (b *Broadcaster) Authorize(ctx context.Context, user *identityclaims.AuthClaims, channel string) bool {
+	b.mu.RLock()
+	rules := b.rules
+	b.mu.RUnlock()
+
+	segments := strings.Split(channel, ".")
+	for _, rule := range rules {
+		if params, ok := match(rule.pattern, segments); ok {
+			return rule.authorize(ctx, user, params)
+		}
+	}
+	return !strings.HasPrefix(channel, "private-") && !strings.HasPrefix(channel, "presence-")
+}
+
+// Listen broadcasts every Broadcastable event emitted on em. Listeners run
+// inside Emit, so prefer EmitAsync for events fanned out to webhooks.
+func (b *Broadcaster) Listen(em *event.Emitter) {
+	em.OnFunc("*", func(ctx context.Context, ev event.Event) error {
+		if bc, ok := ev.(Broadcastable); ok {
+			return b.Broadcast(ctx, bc)
+		}
+		return nil
+	})
+}
+
+// Broadcast serializes ev and publishes it through every driver. A failing
+// driver does not stop the others; their errors are joined.
+func (b *Broadcaster) Broadcast(ctx context.Context, ev Broadcastable) error {
+	channels := ev.BroadcastOn()
+	if len(channels) == 0 {
+		return nil
+	}
+
+	name := ev.Name()
+	if n, ok := ev.(Named); ok {
+		name = n.BroadcastAs()
+	}
+	data := ev.Data()
+	if p, ok := ev.(Payloader); ok {
+		data = p.BroadcastWith()
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("broadcast: encode %s: %w", name, err)
+	}
+
+	var errs []error
+	for _, d := range b.drivers {
+		if err := d.Broadcast(ctx, channels, name, payload); err != nil {
+			slog.Warn("broadcast: driver failed", "driver", d.Name(), "event", name, "error", err)
+			errs = append(errs, fmt.Errorf("broadcast: %s: %w", d.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Matches reports whether channel matches any of patterns, using the same
+// syntax as Channel. An empty pattern list matches everything.
+func Matches(patterns []string, channel string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	segments := strings.Split(channel, ".")
+	for _, p := range patterns {
+		if _, ok := match(strings.Split(p, "."), segments); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, segments []string) (map[string]string, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			params[p[1:len(p)-1]] = segments[i]
+			continue
+		}
+		if ok, _ := path.Match(p, segments[i]); !ok {
+			return nil, false
+		}
+	}
+	return params, true
+}
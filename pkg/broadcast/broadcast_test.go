@@ -0,0 +1,129 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+	"github.com/shauryagautam/Astra/pkg/realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderShipped struct {
+	ID       string
+	Internal string
+}
+
+func (e orderShipped) Name() string          { return "order.shipped" }
+func (e orderShipped) Data() any             { return e }
+func (e orderShipped) BroadcastOn() []string { return []string{"orders." + e.ID, "admin"} }
+func (e orderShipped) BroadcastWith() any    { return map[string]string{"id": e.ID} }
+
+func TestAuthorize(t *testing.T) {
+	b := New()
+	b.Channel("orders.{id}", func(_ context.Context, user *identityclaims.AuthClaims, p map[string]string) bool {
+		return user != nil && user.UserID == "owner-"+p["id"]
+	})
+	b.Channel("private-*", func(_ context.Context, user *identityclaims.AuthClaims, _ map[string]string) bool {
+		return user != nil
+	})
+	ctx := context.Background()
+	owner := &identityclaims.AuthClaims{UserID: "owner-7"}
+
+	assert.True(t, b.Authorize(ctx, owner, "orders.7"))
+	assert.False(t, b.Authorize(ctx, owner, "orders.8"))
+	assert.False(t, b.Authorize(ctx, nil, "orders.7"))
+	assert.True(t, b.Authorize(ctx, owner, "private-feed"))
+	assert.True(t, b.Authorize(ctx, nil, "news"))
+	assert.False(t, b.Authorize(ctx, owner, "presence-lobby"), "prefixed channels need a rule")
+}
+
+func TestListen_SSE(t *testing.T) {
+	sse := NewSSEDriver()
+	b := New(sse)
+	em := event.New()
+	b.Listen(em)
+
+	stream, unsubscribe := sse.Subscribe("orders.1")
+	defer unsubscribe()
+
+	em.Emit(context.Background(), orderShipped{ID: "1", Internal: "secret"})
+	em.EmitPayload(context.Background(), "not.broadcast", nil)
+
+	select {
+	case ev := <-stream:
+		assert.Equal(t, "order.shipped", ev.Event)
+		assert.JSONEq(t, `{"id":"1"}`, ev.Data)
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+	assert.Empty(t, stream)
+}
+
+func TestSSEHandler_Forbidden(t *testing.T) {
+	b := New()
+	rec := httptest.NewRecorder()
+	NewSSEDriver().Handler(b)(rec, httptest.NewRequest("GET", "/events?channels=news,private-x", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestWebhookDriver(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	b := New(NewWebhookDriver(srv.URL, "s3cret", "admin"))
+	require.NoError(t, b.Broadcast(context.Background(), orderShipped{ID: "2"}))
+
+	r, body := <-received, <-bodies
+	assert.Equal(t, "sha256="+Sign("s3cret", body), r.Header.Get(SignatureHeader))
+
+	var got struct {
+		Event    string            `json:"event"`
+		Channels []string          `json:"channels"`
+		Data     map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, "order.shipped", got.Event)
+	assert.Equal(t, []string{"admin"}, got.Channels)
+	assert.Equal(t, "2", got.Data["id"])
+}
+
+func TestRealtimeDriver(t *testing.T) {
+	manager := realtime.NewRoomManager()
+	b := New(NewRealtimeDriver(manager))
+	b.Channel("orders.{id}", func(_ context.Context, user *identityclaims.AuthClaims, p map[string]string) bool {
+		return user != nil && user.UserID == "owner-"+p["id"]
+	})
+	b.AuthorizeRealtime(manager)
+
+	client := realtime.NewAuthenticatedClient(context.Background(), nil, &identityclaims.AuthClaims{UserID: "owner-3"}, manager)
+	manager.Register <- client
+	require.Eventually(t, func() bool { _, ok := manager.GetClientByID(client.ID); return ok }, time.Second, time.Millisecond)
+
+	assert.False(t, manager.Authorize(client, "orders.4"))
+	require.True(t, manager.Authorize(client, "orders.3"))
+	require.NoError(t, manager.Join("orders.3", client, nil))
+
+	require.NoError(t, b.Broadcast(context.Background(), orderShipped{ID: "3"}))
+	select {
+	case msg := <-client.Send:
+		assert.Equal(t, "order.shipped", msg.Type)
+		assert.Equal(t, "orders.3", msg.Room)
+		assert.Equal(t, "3", msg.Payload["id"])
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+}
@@ -0,0 +1,68 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/realtime"
+)
+
+// RealtimeDriver publishes events to the realtime rooms named after their
+// channels. Channels nobody has joined are skipped.
+type RealtimeDriver struct {
+	Manager *realtime.RoomManager
+}
+
+// NewRealtimeDriver creates a driver for manager.
+func NewRealtimeDriver(manager *realtime.RoomManager) *RealtimeDriver {
+	return &RealtimeDriver{Manager: manager}
+}
+
+func (d *RealtimeDriver) Name() string { return "realtime" }
+
+func (d *RealtimeDriver) Broadcast(ctx context.Context, channels []string, event string, payload []byte) error {
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		// Scalars and arrays are wrapped so the payload stays an object.
+		var data any
+		_ = json.Unmarshal(payload, &data)
+		body = map[string]interface{}{"data": data}
+	}
+
+	for _, channel := range channels {
+		if _, ok := d.Manager.GetRoom(channel); !ok {
+			continue
+		}
+		d.Manager.BroadcastToRoom(channel, realtime.Message{
+			Type:      event,
+			Payload:   body,
+			Timestamp: time.Now(),
+		})
+	}
+	return nil
+}
+
+// AuthorizeRealtime lets clients of manager join rooms by sending
+// {"type":"join","room":"..."} whenever b authorizes the channel. Rooms are
+// created on first join and removed when their last client leaves;
+// "presence-" channels become presence rooms. manager.MaxRoomsPerClient
+// caps how many rooms each connection can hold open this way.
+func (b *Broadcaster) AuthorizeRealtime(manager *realtime.RoomManager) {
+	manager.Authorize = func(client *realtime.Client, room string) bool {
+		if !b.Authorize(client.Context(), client.User(), room) {
+			return false
+		}
+		if _, ok := manager.GetRoom(room); !ok {
+			var r *realtime.Room
+			if strings.HasPrefix(room, "presence-") {
+				r = manager.CreatePresenceRoom(room, room, true, 0)
+			} else {
+				r = manager.CreateRoom(room, room, true, 0)
+			}
+			r.Temporary = true
+		}
+		return true
+	}
+}
@@ -0,0 +1,130 @@
+package broadcast
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shauryagautam/Astra/pkg/identity/auth"
+	"github.com/shauryagautam/Astra/pkg/ws"
+)
+
+// sseBuffer is the number of events queued per subscriber before new
+// events are dropped for that subscriber.
+const sseBuffer = 64
+
+// SSEDriver fans events out to Server-Sent Event streams subscribed to
+// their channels.
+type SSEDriver struct {
+	server *ws.SSEServer
+	seq    atomic.Uint64
+
+	mu   sync.RWMutex
+	subs map[string]map[chan ws.SSEEvent]struct{}
+}
+
+// NewSSEDriver creates an SSE driver. Mount Handler to let clients subscribe.
+func NewSSEDriver() *SSEDriver {
+	return &SSEDriver{
+		server: ws.NewSSEServer(),
+		subs:   make(map[string]map[chan ws.SSEEvent]struct{}),
+	}
+}
+
+func (d *SSEDriver) Name() string { return "sse" }
+
+func (d *SSEDriver) Broadcast(ctx context.Context, channels []string, event string, payload []byte) error {
+	ev := ws.SSEEvent{
+		ID:    strconv.FormatUint(d.seq.Add(1), 10),
+		Event: event,
+		Data:  string(payload),
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, channel := range channels {
+		for sub := range d.subs[channel] {
+			select {
+			case sub <- ev:
+			default:
+				// Slow subscriber; drop rather than block the publisher.
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a stream of the events published to channels and a
+// func that ends the subscription.
+func (d *SSEDriver) Subscribe(channels ...string) (<-chan ws.SSEEvent, func()) {
+	sub := make(chan ws.SSEEvent, sseBuffer)
+
+	d.mu.Lock()
+	for _, channel := range channels {
+		if d.subs[channel] == nil {
+			d.subs[channel] = make(map[chan ws.SSEEvent]struct{})
+		}
+		d.subs[channel][sub] = struct{}{}
+	}
+	d.mu.Unlock()
+
+	return sub, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for _, channel := range channels {
+			delete(d.subs[channel], sub)
+			if len(d.subs[channel]) == 0 {
+				delete(d.subs, channel)
+			}
+		}
+	}
+}
+
+// Handler streams the channels listed in the "channels" query parameter
+// (comma separated) after checking each one with b. The user is read from
+// the request context, so mount it behind the Auth middleware for private
+// channels. Unauthorized requests get 403.
+func (d *SSEDriver) Handler(b *Broadcaster) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var channels []string
+		for _, c := range strings.Split(r.URL.Query().Get("channels"), ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				channels = append(channels, c)
+			}
+		}
+		if len(channels) == 0 {
+			http.Error(w, "no channels requested", http.StatusBadRequest)
+			return
+		}
+
+		user := auth.GetAuthUser(r.Context())
+		for _, c := range channels {
+			if !b.Authorize(r.Context(), user, c) {
+				http.Error(w, "forbidden channel: "+c, http.StatusForbidden)
+				return
+			}
+		}
+
+		sub, unsubscribe := d.Subscribe(channels...)
+		defer unsubscribe()
+
+		done := r.Context().Done()
+		d.server.Handler(w, r, func(events chan<- ws.SSEEvent) {
+			for {
+				select {
+				case <-done:
+					return
+				case ev := <-sub:
+					select {
+					case events <- ev:
+					case <-done:
+						return
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of a webhook body, keyed
+// with the driver's secret, as "sha256=<hex>".
+const SignatureHeader = "X-Astra-Signature"
+
+// WebhookDriver POSTs events to an external URL.
+type WebhookDriver struct {
+	URL string
+	// Secret signs each body; see SignatureHeader. Empty disables signing.
+	Secret string
+	// Channels restricts delivery to matching channels (see Channel for the
+	// pattern syntax). Empty delivers everything.
+	Channels []string
+	Client   *http.Client
+}
+
+// NewWebhookDriver creates a webhook driver with a 5s timeout.
+func NewWebhookDriver(url, secret string, channels ...string) *WebhookDriver {
+	return &WebhookDriver{
+		URL:      url,
+		Secret:   secret,
+		Channels: channels,
+		Client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (d *WebhookDriver) Name() string { return "webhook" }
+
+func (d *WebhookDriver) Broadcast(ctx context.Context, channels []string, event string, payload []byte) error {
+	var matched []string
+	for _, c := range channels {
+		if Matches(d.Channels, c) {
+			matched = append(matched, c)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"event":    event,
+		"channels": matched,
+		"data":     json.RawMessage(payload),
+		"sent_at":  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(d.Secret, body))
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) // #nosec G107 -- URL is application configuration
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s responded %s", d.URL, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body keyed with secret, for
+// receivers verifying SignatureHeader.
+func Sign(secret string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
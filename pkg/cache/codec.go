@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes the values a RedisStore caches that are not already text,
+// such as structs, maps and slices.
+type Codec interface {
+	// Name returns the codec name: "json", "msgpack" or "gob".
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	tag() byte
+}
+
+// The codecs a RedisStore can use. Each tags the values it writes, so a
+// store reads back entries written with any of them, and switching codecs
+// does not invalidate the cache.
+var (
+	JSONCodec    Codec = jsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+	GobCodec     Codec = gobCodec{}
+)
+
+var codecsByTag = map[byte]Codec{
+	JSONCodec.tag():    JSONCodec,
+	MsgpackCodec.tag(): MsgpackCodec,
+	GobCodec.tag():     GobCodec,
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                       { return "json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) tag() byte                          { return 'j' }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string                       { return "msgpack" }
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+func (msgpackCodec) tag() byte                          { return 'm' }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+func (gobCodec) tag() byte { return 'g' }
+
+// CodecByName returns the codec called name, for configuration read from
+// the environment.
+func CodecByName(name string) (Codec, error) {
+	for _, c := range codecsByTag {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("astra/cache: unknown codec %q", name)
+}
+
+// A value envelope is a header followed by the payload:
+//
+//	0x00 'A' <tag> <flags> payload
+//
+// The tag is tagText for text, or the tag of the codec that encoded the
+// payload. Text that is stored as is, uncompressed, has no envelope, so it
+// stays readable by other Redis clients; text that would look like an
+// envelope is always wrapped.
+const (
+	envelopeSize = 4
+	tagText      = 's'
+	flagGzip     = 1 << 0
+)
+
+var envelopeMagic = []byte{0x00, 'A'}
+
+// ErrCorruptValue is returned when a cached value has an envelope that
+// cannot be read.
+var ErrCorruptValue = errors.New("astra/cache: corrupt cached value")
+
+// text returns the text form of value when it is a string, bytes, a scalar
+// or a binary marshaler, which are cached as is. ok is false for values that
+// need a codec.
+func text(value any) (b []byte, ok bool, err error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), true, nil
+	case []byte:
+		return v, true, nil
+	case bool:
+		return strconv.AppendBool(nil, v), true, nil
+	case int:
+		return strconv.AppendInt(nil, int64(v), 10), true, nil
+	case int8:
+		return strconv.AppendInt(nil, int64(v), 10), true, nil
+	case int16:
+		return strconv.AppendInt(nil, int64(v), 10), true, nil
+	case int32:
+		return strconv.AppendInt(nil, int64(v), 10), true, nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), true, nil
+	case uint:
+		return strconv.AppendUint(nil, uint64(v), 10), true, nil
+	case uint8:
+		return strconv.AppendUint(nil, uint64(v), 10), true, nil
+	case uint16:
+		return strconv.AppendUint(nil, uint64(v), 10), true, nil
+	case uint32:
+		return strconv.AppendUint(nil, uint64(v), 10), true, nil
+	case uint64:
+		return strconv.AppendUint(nil, v, 10), true, nil
+	case float32:
+		return strconv.AppendFloat(nil, float64(v), 'f', -1, 32), true, nil
+	case float64:
+		return strconv.AppendFloat(nil, v, 'f', -1, 64), true, nil
+	case time.Time:
+		return v.AppendFormat(nil, time.RFC3339Nano), true, nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		return b, true, err
+	}
+	return nil, false, nil
+}
+
+// encodeValue turns value into the bytes stored in Redis. Values that are not
+// text are encoded with codec; payloads of at least threshold bytes are
+// gzipped when that makes them smaller. A threshold of 0 never compresses.
+func encodeValue(value any, codec Codec, threshold int) ([]byte, error) {
+	payload, isText, err := text(value)
+	if err != nil {
+		return nil, err
+	}
+	tag := byte(tagText)
+	if !isText {
+		if payload, err = codec.Marshal(value); err != nil {
+			return nil, fmt.Errorf("astra/cache: %s encode: %w", codec.Name(), err)
+		}
+		tag = codec.tag()
+	}
+
+	var flags byte
+	if threshold > 0 && len(payload) >= threshold {
+		if zipped, err := gzipBytes(payload); err == nil && len(zipped)+envelopeSize < len(payload) {
+			payload, flags = zipped, flagGzip
+		}
+	}
+
+	if tag == tagText && flags == 0 && !bytes.HasPrefix(payload, envelopeMagic) {
+		return payload, nil
+	}
+	out := make([]byte, 0, envelopeSize+len(payload))
+	out = append(out, envelopeMagic...)
+	out = append(out, tag, flags)
+	return append(out, payload...), nil
+}
+
+// openEnvelope returns the codec tag and the uncompressed payload of a stored
+// value. Values without an envelope are text.
+func openEnvelope(raw []byte) (byte, []byte, error) {
+	if !bytes.HasPrefix(raw, envelopeMagic) {
+		return tagText, raw, nil
+	}
+	if len(raw) < envelopeSize {
+		return 0, nil, ErrCorruptValue
+	}
+	tag, flags, payload := raw[2], raw[3], raw[envelopeSize:]
+	if tag != tagText && codecsByTag[tag] == nil || flags&^flagGzip != 0 {
+		return 0, nil, ErrCorruptValue
+	}
+	if flags&flagGzip != 0 {
+		unzipped, err := gunzipBytes(payload)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w: %v", ErrCorruptValue, err)
+		}
+		payload = unzipped
+	}
+	return tag, payload, nil
+}
+
+// decodeValue decodes a stored value into dst. Text decodes into a *string
+// or *[]byte, or as JSON into anything else, so cached numbers can be read
+// into numeric variables.
+func decodeValue(raw []byte, dst any) error {
+	tag, payload, err := openEnvelope(raw)
+	if err != nil {
+		return err
+	}
+	if tag != tagText {
+		codec := codecsByTag[tag]
+		if err := codec.Unmarshal(payload, dst); err != nil {
+			return fmt.Errorf("astra/cache: %s decode: %w", codec.Name(), err)
+		}
+		return nil
+	}
+	switch d := dst.(type) {
+	case *string:
+		*d = string(payload)
+		return nil
+	case *[]byte:
+		*d = append((*d)[:0], payload...)
+		return nil
+	case *time.Time:
+		t, err := time.Parse(time.RFC3339Nano, string(payload))
+		if err != nil {
+			return fmt.Errorf("astra/cache: decode text: %w", err)
+		}
+		*d = t
+		return nil
+	case encoding.BinaryUnmarshaler:
+		return d.UnmarshalBinary(payload)
+	}
+	if err := json.Unmarshal(payload, dst); err != nil {
+		return fmt.Errorf("astra/cache: decode text: %w", err)
+	}
+	return nil
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type cachedProfile struct {
+	Name  string
+	Tags  []string
+	Score float64
+}
+
+func TestRedisStoreCodecsRoundTrip(t *testing.T) {
+	_, server, client := setupRedisStore(t)
+	defer server.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	want := cachedProfile{Name: "Ada", Tags: []string{"math", "engines"}, Score: 9.5}
+
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec, GobCodec} {
+		store := NewRedisStore(client, "astra:cache:", WithCodec(codec))
+		key := "profile:" + codec.Name()
+		if err := store.Set(ctx, key, want, 0); err != nil {
+			t.Fatalf("%s: set: %v", codec.Name(), err)
+		}
+
+		var got cachedProfile
+		if err := store.GetInto(ctx, key, &got); err != nil {
+			t.Fatalf("%s: get into: %v", codec.Name(), err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: expected %+v, got %+v", codec.Name(), want, got)
+		}
+
+		// A store configured with another codec reads the entry by its tag.
+		var other cachedProfile
+		if err := NewRedisStore(client, "astra:cache:").GetInto(ctx, key, &other); err != nil || !reflect.DeepEqual(other, want) {
+			t.Fatalf("%s: read with json store: %+v, %v", codec.Name(), other, err)
+		}
+	}
+}
+
+func TestRedisStoreTextStaysPlain(t *testing.T) {
+	store, server, client := setupRedisStore(t)
+	defer server.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := store.Set(ctx, "greeting", "hello", 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if raw, _ := server.Get("astra:cache:greeting"); raw != "hello" {
+		t.Fatalf("expected plain text in redis, got %q", raw)
+	}
+
+	if err := store.Set(ctx, "count", 42, 0); err != nil {
+		t.Fatalf("set number: %v", err)
+	}
+	var count int
+	if err := store.GetInto(ctx, "count", &count); err != nil || count != 42 {
+		t.Fatalf("expected 42, got %d, %v", count, err)
+	}
+
+	// Text that looks like an envelope is wrapped so it reads back intact.
+	tricky := "\x00Ajx"
+	if err := store.Set(ctx, "tricky", tricky, 0); err != nil {
+		t.Fatalf("set tricky: %v", err)
+	}
+	if value, err := store.Get(ctx, "tricky"); err != nil || value != tricky {
+		t.Fatalf("expected %q, got %q, %v", tricky, value, err)
+	}
+}
+
+func TestRedisStoreCompression(t *testing.T) {
+	_, server, client := setupRedisStore(t)
+	defer server.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	store := NewRedisStore(client, "astra:cache:", WithCodec(MsgpackCodec), WithCompression(1024))
+	large := strings.Repeat("all work and no play ", 500)
+
+	if err := store.Set(ctx, "page", large, 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	raw, _ := server.Get("astra:cache:page")
+	if len(raw) >= len(large)/4 || !strings.HasPrefix(raw, "\x00As\x01") {
+		t.Fatalf("expected a compressed envelope, got %d bytes", len(raw))
+	}
+	if value, err := store.Get(ctx, "page"); err != nil || value != large {
+		t.Fatalf("expected the original text back, got %d bytes, %v", len(value), err)
+	}
+
+	profile := cachedProfile{Name: strings.Repeat("Ada ", 1000)}
+	if err := store.SetMany(ctx, map[string]any{"profile": profile, "small": "tiny"}, 0); err != nil {
+		t.Fatalf("set many: %v", err)
+	}
+	var got cachedProfile
+	if err := store.GetInto(ctx, "profile", &got); err != nil || got.Name != profile.Name {
+		t.Fatalf("expected compressed profile back, got %v", err)
+	}
+	if raw, _ := server.Get("astra:cache:small"); raw != "tiny" {
+		t.Fatalf("expected small values uncompressed, got %q", raw)
+	}
+	values, err := store.GetMany(ctx, []string{"small"})
+	if err != nil || values["small"] != "tiny" {
+		t.Fatalf("get many: %v, %v", values, err)
+	}
+}
+
+func TestRedisStoreCorruptEnvelope(t *testing.T) {
+	store, server, client := setupRedisStore(t)
+	defer server.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	for _, raw := range []string{"\x00A", "\x00Az\x00{}", "\x00Aj\x01not gzip"} {
+		if err := client.Set(ctx, "astra:cache:bad", raw, 0).Err(); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		if _, err := store.Get(ctx, "bad"); !errors.Is(err, ErrCorruptValue) {
+			t.Fatalf("%q: expected ErrCorruptValue, got %v", raw, err)
+		}
+	}
+
+	var missing cachedProfile
+	if err := store.GetInto(ctx, "missing", &missing); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected ErrCacheMiss, got %v", err)
+	}
+}
+
+func TestCodecByName(t *testing.T) {
+	for _, name := range []string{"json", "msgpack", "gob"} {
+		codec, err := CodecByName(name)
+		if err != nil || codec.Name() != name {
+			t.Fatalf("%s: got %v, %v", name, codec, err)
+		}
+	}
+	if _, err := CodecByName("xml"); err == nil {
+		t.Fatal("expected an error for an unknown codec")
+	}
+}
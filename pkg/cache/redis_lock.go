@@ -2,13 +2,12 @@ package cache
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
 
 	goredis "github.com/redis/go-redis/v9"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 )
 
 const defaultRedisLockPrefix = "astra:lock:"
@@ -131,11 +130,7 @@ func (l *RedisLock) Extend(ctx context.Context, ttl time.Duration) error {
 }
 
 func generateLockToken() (string, error) {
-	var token [32]byte
-	if _, err := rand.Read(token[:]); err != nil {
-		return "", err
-	}
-	return base64.RawURLEncoding.EncodeToString(token[:]), nil
+	return secure.Token(secure.TokenBytes)
 }
 
 func normalizeLockPrefix(prefix string) string {
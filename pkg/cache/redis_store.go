@@ -14,39 +14,105 @@ const defaultRedisCachePrefix = "astra:cache:"
 
 // RedisStore is a Redis-backed implementation of Store.
 type RedisStore struct {
-	client    goredis.UniversalClient
-	keyPrefix string
+	client            goredis.UniversalClient
+	keyPrefix         string
+	codec             Codec
+	compressThreshold int
+}
+
+// RedisOption configures a RedisStore.
+type RedisOption func(*RedisStore)
+
+// WithCodec sets the codec for values that are not text, such as structs
+// and maps. The default is JSONCodec. Strings, bytes and numbers are always
+// stored as is.
+func WithCodec(codec Codec) RedisOption {
+	return func(s *RedisStore) {
+		if codec != nil {
+			s.codec = codec
+		}
+	}
+}
+
+// WithCompression gzips values of at least threshold bytes before they are
+// written, when that makes them smaller. Reads decompress transparently.
+// A threshold of 0, the default, turns compression off.
+func WithCompression(threshold int) RedisOption {
+	return func(s *RedisStore) {
+		s.compressThreshold = threshold
+	}
 }
 
 // NewRedisStore creates a new Redis-backed cache store.
-func NewRedisStore(client goredis.UniversalClient, keyPrefix string) *RedisStore {
-	return &RedisStore{
+//
+// Values that need a codec or compression are stored in a small envelope
+// naming the codec, so entries written with one codec are still read after
+// switching to another.
+func NewRedisStore(client goredis.UniversalClient, keyPrefix string, opts ...RedisOption) *RedisStore {
+	s := &RedisStore{
 		client:    client,
 		keyPrefix: normalizeRedisKeyPrefix(keyPrefix, defaultRedisCachePrefix),
+		codec:     JSONCodec,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Get retrieves a value from Redis.
+// Get retrieves a value from Redis. Compressed values are decompressed;
+// values encoded with a codec are returned in their encoded form, so use
+// GetInto to decode them.
 func (s *RedisStore) Get(ctx context.Context, key string) (string, error) {
+	raw, err := s.getRaw(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	_, payload, err := openEnvelope(raw)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// GetInto retrieves a value from Redis and decodes it into dst with the
+// codec it was written with.
+//
+//	var user User
+//	err := store.GetInto(ctx, "user:1", &user)
+func (s *RedisStore) GetInto(ctx context.Context, key string, dst any) error {
+	raw, err := s.getRaw(ctx, key)
+	if err != nil {
+		return err
+	}
+	return decodeValue(raw, dst)
+}
+
+func (s *RedisStore) getRaw(ctx context.Context, key string) ([]byte, error) {
 	if s.client == nil {
-		return "", fmt.Errorf("astra/cache: redis client is nil")
+		return nil, fmt.Errorf("astra/cache: redis client is nil")
 	}
-	value, err := s.client.Get(ctx, s.key(key)).Result()
+	value, err := s.client.Get(ctx, s.key(key)).Bytes()
 	if err == nil {
 		return value, nil
 	}
 	if errors.Is(err, goredis.Nil) {
-		return "", ErrCacheMiss
+		return nil, ErrCacheMiss
 	}
-	return "", fmt.Errorf("astra/cache: %w", err)
+	return nil, fmt.Errorf("astra/cache: %w", err)
 }
 
-// Set stores a value in Redis.
+// Set stores a value in Redis. Values that are not text are encoded with the
+// store's codec.
 func (s *RedisStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
 	if s.client == nil {
 		return fmt.Errorf("astra/cache: redis client is nil")
 	}
-	if err := s.client.Set(ctx, s.key(key), value, ttl).Err(); err != nil {
+	data, err := encodeValue(value, s.codec, s.compressThreshold)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.key(key), data, ttl).Err(); err != nil {
 		return fmt.Errorf("astra/cache: %w", err)
 	}
 	return nil
@@ -145,10 +211,14 @@ func (s *RedisStore) GetMany(ctx context.Context, keys []string) (map[string]str
 	}
 
 	for key, cmd := range cmds {
-		value, cmdErr := cmd.Result()
+		value, cmdErr := cmd.Bytes()
 		switch {
 		case cmdErr == nil:
-			results[key] = value
+			_, payload, err := openEnvelope(value)
+			if err != nil {
+				return nil, err
+			}
+			results[key] = string(payload)
 		case errors.Is(cmdErr, goredis.Nil):
 			continue
 		default:
@@ -168,9 +238,18 @@ func (s *RedisStore) SetMany(ctx context.Context, items map[string]any, ttl time
 		return nil
 	}
 
+	encoded := make(map[string][]byte, len(items))
+	for key, value := range items {
+		data, err := encodeValue(value, s.codec, s.compressThreshold)
+		if err != nil {
+			return err
+		}
+		encoded[key] = data
+	}
+
 	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
-		for key, value := range items {
-			pipe.Set(ctx, s.key(key), value, ttl)
+		for key, data := range encoded {
+			pipe.Set(ctx, s.key(key), data, ttl)
 		}
 		return nil
 	})
@@ -45,22 +45,10 @@ func (e *Encrypter) Encrypt(plaintext string) (string, error) {
 		return "", nil
 	}
 
-	block, err := aes.NewCipher(e.key)
-	if err != nil {
-		return "", fmt.Errorf("encryption: failed to create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
+	ciphertext, err := e.Seal([]byte(plaintext))
 	if err != nil {
-		return "", fmt.Errorf("encryption: failed to create GCM: %w", err)
+		return "", err
 	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", fmt.Errorf("encryption: failed to generate nonce: %w", err)
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
@@ -78,26 +66,74 @@ func (e *Encrypter) Decrypt(encodedCiphertext string) (string, error) {
 		return "", fmt.Errorf("encryption: failed to decode base64: %w", err)
 	}
 
-	block, err := aes.NewCipher(e.key)
+	plaintext, err := e.Open(ciphertext)
 	if err != nil {
-		return "", fmt.Errorf("encryption: failed to create cipher: %w", err)
+		return "", err
 	}
+	return string(plaintext), nil
+}
 
-	gcm, err := cipher.NewGCM(block)
+// Seal encrypts plaintext and returns the nonce followed by the ciphertext,
+// for binary data such as backup archives.
+func (e *Encrypter) Seal(plaintext []byte) ([]byte, error) {
+	return e.SealWithAAD(plaintext, nil)
+}
+
+// SealWithAAD is like Seal, but also authenticates additionalData, which is
+// not stored: OpenWithAAD fails unless it is given the same data. Use it to
+// bind a ciphertext to its context, such as the record it belongs to.
+func (e *Encrypter) SealWithAAD(plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := e.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("encryption: failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts data produced by Seal.
+func (e *Encrypter) Open(ciphertext []byte) ([]byte, error) {
+	return e.OpenWithAAD(ciphertext, nil)
+}
+
+// OpenWithAAD decrypts data produced by SealWithAAD with the same
+// additionalData.
+func (e *Encrypter) OpenWithAAD(ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := e.gcm()
 	if err != nil {
-		return "", fmt.Errorf("encryption: failed to create GCM: %w", err)
+		return nil, err
 	}
 
 	nonceSize := gcm.NonceSize()
 	if len(ciphertext) < nonceSize {
-		return "", errors.New("encryption: ciphertext too short")
+		return nil, errors.New("encryption: ciphertext too short")
 	}
 
 	nonce, encryptedMsg := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, encryptedMsg, nil)
+	plaintext, err := gcm.Open(nil, nonce, encryptedMsg, additionalData)
 	if err != nil {
-		return "", fmt.Errorf("encryption: decryption failed: %w", err)
+		return nil, fmt.Errorf("encryption: decryption failed: %w", err)
 	}
+	return plaintext, nil
+}
 
-	return string(plaintext), nil
+func (e *Encrypter) gcm() (cipher.AEAD, error) {
+	if e == nil || e.key == nil {
+		return nil, errors.New("encryption: encrypter is not initialized")
+	}
+
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to create GCM: %w", err)
+	}
+	return gcm, nil
 }
@@ -37,3 +37,28 @@ func TestEncryptionWithShortKey(t *testing.T) {
 		t.Error("Expected error with short key, got nil")
 	}
 }
+
+func TestSealOpen(t *testing.T) {
+	e, err := NewEncrypter("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatalf("Failed to create encrypter: %v", err)
+	}
+
+	original := []byte{0, 1, 2, 0xff, 'a'}
+	sealed, err := e.Seal(original)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	opened, err := e.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if string(opened) != string(original) {
+		t.Errorf("Expected %v, got %v", original, opened)
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	if _, err := e.Open(sealed); err == nil {
+		t.Error("Expected error for tampered ciphertext, got nil")
+	}
+}
@@ -0,0 +1,191 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A stream is a header (a version byte and a random salt) followed by
+// chunks of up to streamChunkSize bytes, each sealed with AES-256-GCM under
+// a key derived from the Encrypter's key and the salt. The nonce of a chunk
+// is its index, with a flag set on the last chunk, so chunks cannot be
+// reordered, dropped or cut off at the end without Open failing.
+const (
+	streamVersion   = 1
+	streamSaltSize  = 32
+	streamChunkSize = 64 << 10
+)
+
+var errStreamCorrupt = errors.New("encryption: stream is truncated or corrupt")
+
+// SealWriter returns a writer that encrypts what is written to it into w,
+// in authenticated chunks, for data too large to Seal in memory such as
+// backup archives. Close writes the last chunk and must be called; it does
+// not close w.
+func (e *Encrypter) SealWriter(w io.Writer) (io.WriteCloser, error) {
+	salt := make([]byte, streamSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("encryption: failed to generate salt: %w", err)
+	}
+	aead, err := e.streamAEAD(salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append([]byte{streamVersion}, salt...)); err != nil {
+		return nil, err
+	}
+	return &sealWriter{w: w, aead: aead, buf: make([]byte, 0, streamChunkSize)}, nil
+}
+
+// OpenReader returns a reader of the plaintext a SealWriter wrote to r.
+// Each chunk is authenticated before it is returned, and a stream that was
+// changed or cut short fails with an error rather than ending early.
+func (e *Encrypter) OpenReader(r io.Reader) (io.Reader, error) {
+	header := make([]byte, 1+streamSaltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errStreamCorrupt
+	}
+	if header[0] != streamVersion {
+		return nil, fmt.Errorf("encryption: unknown stream version %d", header[0])
+	}
+	aead, err := e.streamAEAD(header[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &openReader{
+		r:     bufio.NewReader(r),
+		aead:  aead,
+		frame: make([]byte, streamChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (e *Encrypter) streamAEAD(salt []byte) (cipher.AEAD, error) {
+	if e == nil || e.key == nil {
+		return nil, errors.New("encryption: encrypter is not initialized")
+	}
+	key, err := hkdf.Key(sha256.New, e.key, salt, "astra-stream", 32)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// streamNonce returns the nonce of chunk i.
+func streamNonce(i uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], i)
+	if last {
+		nonce[0] = 1
+	}
+	return nonce
+}
+
+type sealWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	buf    []byte
+	out    []byte
+	chunk  uint64
+	closed bool
+}
+
+func (s *sealWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("encryption: write to closed stream")
+	}
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is sealed only once more data follows it, since
+		// the last chunk is sealed differently, by Close.
+		if len(s.buf) == streamChunkSize {
+			if err := s.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := min(len(p), streamChunkSize-len(s.buf))
+		s.buf = append(s.buf, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (s *sealWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.flush(true)
+}
+
+func (s *sealWriter) flush(last bool) error {
+	s.out = s.aead.Seal(s.out[:0], streamNonce(s.chunk, last), s.buf, nil)
+	s.chunk++
+	s.buf = s.buf[:0]
+	_, err := s.w.Write(s.out)
+	return err
+}
+
+type openReader struct {
+	r     *bufio.Reader
+	aead  cipher.AEAD
+	frame []byte
+	plain []byte
+	chunk uint64
+	done  bool
+	err   error
+}
+
+func (o *openReader) Read(p []byte) (int, error) {
+	for len(o.plain) == 0 {
+		if o.err != nil {
+			return 0, o.err
+		}
+		if o.done {
+			return 0, io.EOF
+		}
+		o.err = o.next()
+	}
+	n := copy(p, o.plain)
+	o.plain = o.plain[n:]
+	return n, nil
+}
+
+// next opens the next chunk. A chunk is the last one when the stream ends
+// with it.
+func (o *openReader) next() error {
+	n, err := io.ReadFull(o.r, o.frame)
+	last := false
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case errors.Is(err, io.EOF):
+		return errStreamCorrupt
+	case err != nil:
+		return err
+	default:
+		if _, err := o.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		}
+	}
+	plain, err := o.aead.Open(o.frame[:0], streamNonce(o.chunk, last), o.frame[:n], nil)
+	if err != nil {
+		return errStreamCorrupt
+	}
+	o.chunk++
+	o.plain = plain
+	o.done = last
+	return nil
+}
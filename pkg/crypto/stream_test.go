@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func sealStream(t *testing.T, e *Encrypter, plain []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := e.SealWriter(&buf)
+	if err != nil {
+		t.Fatalf("SealWriter failed: %v", err)
+	}
+	// Uneven writes, so chunks do not line up with them.
+	for len(plain) > 0 {
+		n := min(len(plain), 10000)
+		if _, err := w.Write(plain[:n]); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		plain = plain[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func openStream(e *Encrypter, sealed []byte) ([]byte, error) {
+	r, err := e.OpenReader(bytes.NewReader(sealed))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	e, err := NewEncrypter("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatalf("Failed to create encrypter: %v", err)
+	}
+	for _, size := range []int{0, 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 5} {
+		plain := make([]byte, size)
+		_, _ = rand.Read(plain)
+		sealed := sealStream(t, e, plain)
+		if size > 0 && bytes.Contains(sealed, plain) {
+			t.Errorf("size %d: plaintext found in the stream", size)
+		}
+		got, err := openStream(e, sealed)
+		if err != nil {
+			t.Fatalf("size %d: open failed: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("size %d: round trip changed the data", size)
+		}
+	}
+}
+
+func TestStreamRejectsTampering(t *testing.T) {
+	e, _ := NewEncrypter("01234567890123456789012345678901")
+	plain := make([]byte, 2*streamChunkSize+100)
+	sealed := sealStream(t, e, plain)
+	frame := streamChunkSize + 16
+	header := 1 + streamSaltSize
+
+	flipped := bytes.Clone(sealed)
+	flipped[header+frame+7] ^= 1
+	// Cut after the first two chunks: the second was not sealed as the last.
+	cut := sealed[:header+2*frame]
+	// The last chunk moved in front of the others.
+	reordered := append(append(bytes.Clone(sealed[:header]), sealed[header+2*frame:]...), sealed[header:header+2*frame]...)
+
+	other, _ := NewEncrypter("abcdefghijabcdefghijabcdefghij12")
+	for name, tc := range map[string]struct {
+		e      *Encrypter
+		sealed []byte
+	}{
+		"flipped":   {e, flipped},
+		"cut":       {e, cut},
+		"truncated": {e, sealed[:len(sealed)-1]},
+		"reordered": {e, reordered},
+		"other key": {other, sealed},
+	} {
+		if _, err := openStream(tc.e, tc.sealed); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
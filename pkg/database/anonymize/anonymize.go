@@ -0,0 +1,215 @@
+// Package anonymize replaces personal data in database rows with realistic
+// fakes, so copies of production data can be handed to developers. It backs
+// the astra db:anonymize command, which applies a Profile while dumping, so
+// the database itself is never modified.
+//
+// Fakes are derived from the original value and a secret: within one run
+// the same email becomes the same fake email in every table and column,
+// which keeps joins and lookups on it working, while a new secret gives
+// unrelated fakes. NULL stays NULL.
+package anonymize
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
+)
+
+// Transformer returns the fake for v, a non-nil column value (string, bool,
+// int64, float64, time.Time or []byte). f is seeded from v, so the same v always
+// gets the same fake.
+type Transformer func(f *gofakeit.Faker, v any) any
+
+var (
+	mu           sync.RWMutex
+	transformers = map[string]Transformer{
+		"email": func(f *gofakeit.Faker, _ any) any {
+			return fmt.Sprintf("%s.%s.%08x@example.com", strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), f.Uint32())
+		},
+		"name":       func(f *gofakeit.Faker, _ any) any { return f.Name() },
+		"first_name": func(f *gofakeit.Faker, _ any) any { return f.FirstName() },
+		"last_name":  func(f *gofakeit.Faker, _ any) any { return f.LastName() },
+		"username": func(f *gofakeit.Faker, _ any) any {
+			return fmt.Sprintf("%s_%06x", strings.ToLower(f.FirstName()), f.Uint32()&0xffffff)
+		},
+		"phone":   func(f *gofakeit.Faker, _ any) any { return f.Phone() },
+		"ip":      func(f *gofakeit.Faker, _ any) any { return f.IPv4Address() },
+		"ipv6":    func(f *gofakeit.Faker, _ any) any { return f.IPv6Address() },
+		"address": func(f *gofakeit.Faker, _ any) any { return f.Street() + ", " + f.City() },
+		"street":  func(f *gofakeit.Faker, _ any) any { return f.Street() },
+		"city":    func(f *gofakeit.Faker, _ any) any { return f.City() },
+		"zip":     func(f *gofakeit.Faker, _ any) any { return f.Zip() },
+		"company": func(f *gofakeit.Faker, _ any) any { return f.Company() },
+		// text keeps roughly the number of words.
+		"text": func(f *gofakeit.Faker, v any) any {
+			words := len(strings.Fields(fmt.Sprint(v)))
+			return f.Sentence(max(words, 1))
+		},
+		"date": func(f *gofakeit.Faker, v any) any {
+			t, ok := v.(time.Time)
+			if !ok {
+				return f.Date().Format(time.DateOnly)
+			}
+			// Within a year of the original, so ages stay plausible.
+			return t.AddDate(0, 0, f.IntRange(-182, 182))
+		},
+		"hash": func(f *gofakeit.Faker, _ any) any { return fmt.Sprintf("%016x", f.Uint64()) },
+		"null": func(*gofakeit.Faker, any) any { return nil },
+	}
+)
+
+// Register adds a transformer usable in profiles by name, or replaces a
+// built-in one:
+//
+//	anonymize.Register("iban", func(f *gofakeit.Faker, _ any) any {
+//	    return "DE" + f.Numerify("####################")
+//	})
+func Register(name string, t Transformer) {
+	mu.Lock()
+	defer mu.Unlock()
+	transformers[name] = t
+}
+
+// Transformers returns the names of the registered transformers, sorted.
+func Transformers() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(transformers))
+	for name := range transformers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func lookup(name string) (Transformer, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := transformers[name]
+	return t, ok
+}
+
+// Profile maps table names to the columns to anonymize and the name of the
+// transformer applied to each.
+type Profile map[string]map[string]string
+
+// ParseProfiles reads profiles from configuration, as loaded from
+// config/anonymize.yaml under the "anonymize" key:
+//
+//	anonymize:
+//	  gdpr:
+//	    users:
+//	      email: email
+//	      name: name
+//	      last_login_ip: ip
+//
+// It fails on unknown transformers, so a typo never lets data through.
+func ParseProfiles(raw map[string]any) (map[string]Profile, error) {
+	profiles := make(map[string]Profile, len(raw))
+	for name, tables := range raw {
+		tableMap, ok := tables.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("anonymize: profile %s: want a map of tables", name)
+		}
+		p := Profile{}
+		for table, columns := range tableMap {
+			columnMap, ok := columns.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("anonymize: profile %s: %s: want a map of columns to transformers", name, table)
+			}
+			p[table] = map[string]string{}
+			for column, tr := range columnMap {
+				trName, ok := tr.(string)
+				if !ok {
+					return nil, fmt.Errorf("anonymize: profile %s: %s.%s: want a transformer name", name, table, column)
+				}
+				if _, ok := lookup(trName); !ok {
+					return nil, fmt.Errorf("anonymize: profile %s: %s.%s: unknown transformer %q (have %s)", name, table, column, trName, strings.Join(Transformers(), ", "))
+				}
+				p[table][column] = trName
+			}
+		}
+		profiles[name] = p
+	}
+	return profiles, nil
+}
+
+// Tables returns the tables p anonymizes, sorted.
+func (p Profile) Tables() []string {
+	tables := make([]string, 0, len(p))
+	for table := range p {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+	return tables
+}
+
+// Rewriter returns a dump.Options.Rewrite function applying p with secret.
+// It fails for a table whose profile names a column the table does not have
+// or an unknown transformer.
+func (p Profile) Rewriter(secret string) func(table string, columns []string) (func(row []any), error) {
+	return func(table string, columns []string) (func(row []any), error) {
+		rules := p[table]
+		if len(rules) == 0 {
+			return nil, nil
+		}
+		type step struct {
+			index int
+			fn    Transformer
+		}
+		var steps []step
+		for column, name := range rules {
+			i := slices.Index(columns, column)
+			if i < 0 {
+				return nil, fmt.Errorf("anonymize: %s.%s: no such column", table, column)
+			}
+			fn, ok := lookup(name)
+			if !ok {
+				return nil, fmt.Errorf("anonymize: %s.%s: unknown transformer %q", table, column, name)
+			}
+			steps = append(steps, step{i, fn})
+		}
+
+		f := gofakeit.NewUnlocked(0)
+		return func(row []any) {
+			for _, s := range steps {
+				v := row[s.index]
+				if v == nil {
+					continue
+				}
+				f.Rand.Seed(seed(secret, v))
+				row[s.index] = s.fn(f, v)
+			}
+		}, nil
+	}
+}
+
+// seed derives the faker seed of v from the secret.
+func seed(secret string, v any) int64 {
+	h := sha256.New()
+	h.Write([]byte(secret))
+	h.Write([]byte{0})
+	if t, ok := v.(time.Time); ok {
+		h.Write([]byte(t.UTC().Format(time.RFC3339Nano)))
+	} else {
+		fmt.Fprint(h, v)
+	}
+	return int64(binary.BigEndian.Uint64(h.Sum(nil)))
+}
+
+// SecretEnv is the environment variable db:anonymize reads its secret from.
+// Unlike a command-line flag, it does not show in the process list.
+const SecretEnv = "ANONYMIZE_SECRET"
+
+// NewSecret returns a random secret, so the fakes of one run cannot be
+// matched with those of another.
+func NewSecret() (string, error) {
+	return secure.Hex(16)
+}
@@ -0,0 +1,96 @@
+package anonymize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/database/dump"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfiles(t *testing.T) {
+	profiles, err := ParseProfiles(map[string]any{
+		"gdpr": map[string]any{
+			"users": map[string]any{"email": "email", "ip": "ip"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Profile{"users": {"email": "email", "ip": "ip"}}, profiles["gdpr"])
+
+	_, err = ParseProfiles(map[string]any{
+		"gdpr": map[string]any{"users": map[string]any{"email": "emial"}},
+	})
+	assert.ErrorContains(t, err, `unknown transformer "emial"`)
+}
+
+func TestRewriter(t *testing.T) {
+	p := Profile{"users": {"email": "email", "name": "name", "ip": "ip"}}
+	columns := []string{"id", "email", "name", "ip"}
+
+	rewrite, err := p.Rewriter("s3cret")("users", columns)
+	require.NoError(t, err)
+	row := []any{int64(1), "ada@corp.io", "Ada Lovelace", nil}
+	rewrite(row)
+
+	assert.Equal(t, int64(1), row[0])
+	assert.NotEqual(t, "ada@corp.io", row[1])
+	assert.True(t, strings.HasSuffix(row[1].(string), "@example.com"))
+	assert.NotEqual(t, "Ada Lovelace", row[2])
+	assert.Nil(t, row[3], "NULL stays NULL")
+
+	// The same value and secret give the same fake, in any table.
+	other, err := Profile{"orders": {"contact": "email"}}.Rewriter("s3cret")("orders", []string{"contact"})
+	require.NoError(t, err)
+	again := []any{"ada@corp.io"}
+	other(again)
+	assert.Equal(t, row[1], again[0])
+
+	// Another secret gives another fake.
+	rewrite, err = p.Rewriter("other")("users", columns)
+	require.NoError(t, err)
+	again = []any{int64(1), "ada@corp.io", "Ada Lovelace", nil}
+	rewrite(again)
+	assert.NotEqual(t, row[1], again[1])
+
+	// Tables outside the profile are left alone.
+	rewrite, err = p.Rewriter("s3cret")("posts", []string{"id", "title"})
+	require.NoError(t, err)
+	assert.Nil(t, rewrite)
+
+	_, err = p.Rewriter("s3cret")("users", []string{"id", "email"})
+	assert.ErrorContains(t, err, "users.")
+}
+
+func TestDumpWithProfile(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Open(database.Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.Pool().SetMaxOpenConns(1)
+
+	_, err = db.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL, plan TEXT)")
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO users (id, email, plan) VALUES (1, 'ada@corp.io', 'pro')")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	p := Profile{"users": {"email": "email"}}
+	_, err = dump.Dump(ctx, db, dir, dump.Options{Format: dump.FormatCSV, Rewrite: p.Rewriter("s3cret")})
+	require.NoError(t, err)
+
+	out, err := os.ReadFile(filepath.Join(dir, "users.csv"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "ada@corp.io")
+	assert.Contains(t, string(out), "@example.com")
+	assert.Contains(t, string(out), "pro")
+
+	// The source is untouched.
+	var email string
+	require.NoError(t, db.QueryRow(ctx, "SELECT email FROM users WHERE id = 1").Scan(&email))
+	assert.Equal(t, "ada@corp.io", email)
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultBatchSize is used by CreateMany and UpsertMany when no batch size is given.
+const DefaultBatchSize = 500
+
+// maxBindParams caps the number of placeholders in a single statement.
+// It matches SQLite's default SQLITE_MAX_VARIABLE_NUMBER, which is the lowest
+// limit among the supported drivers (PostgreSQL allows 65535).
+const maxBindParams = 32766
+
+// conflictClause describes how an INSERT should resolve unique-key conflicts.
+type conflictClause struct {
+	columns []string
+	update  []string
+}
+
+// ConflictBuilder configures the conflict resolution of an insert.
+// Obtain one via QueryBuilder.OnConflict.
+type ConflictBuilder[T any] struct {
+	q       *QueryBuilder[T]
+	columns []string
+}
+
+// OnConflict starts an upsert clause targeting the given unique columns.
+// Finish it with DoUpdate or DoNothing:
+//
+//	database.Query[User](db).
+//	    OnConflict("email").
+//	    DoUpdate("name", "updated_at").
+//	    CreateMany(users, 200, ctx)
+func (q *QueryBuilder[T]) OnConflict(columns ...string) *ConflictBuilder[T] {
+	return &ConflictBuilder[T]{q: q, columns: columns}
+}
+
+// DoUpdate overwrites the given columns with the incoming values on conflict.
+// When no columns are given, every insertable non-key column is updated.
+func (c *ConflictBuilder[T]) DoUpdate(columns ...string) *QueryBuilder[T] {
+	if len(columns) == 0 {
+		for _, col := range c.q.meta.Columns {
+			if col.IsPK || col.IsAuto || col.IsSoftDel || col.IsGuarded || col.ColumnName == "created_at" {
+				continue
+			}
+			if slices.Contains(c.columns, col.ColumnName) {
+				continue
+			}
+			columns = append(columns, col.ColumnName)
+		}
+	}
+	c.q.conflict = &conflictClause{columns: c.columns, update: columns}
+	return c.q
+}
+
+// DoNothing silently skips rows that violate the unique constraint.
+func (c *ConflictBuilder[T]) DoNothing() *QueryBuilder[T] {
+	c.q.conflict = &conflictClause{columns: c.columns}
+	return c.q
+}
+
+// conflictSQL renders the dialect-specific upsert suffix, if any.
+func (q *QueryBuilder[T]) conflictSQL() string {
+	if q.conflict == nil {
+		return ""
+	}
+	return q.db.dialect.UpsertSQL(q.conflict.columns, q.conflict.update)
+}
+
+// CreateMany inserts models using multi-row INSERT statements of at most
+// batchSize rows each. All batches run inside a single transaction so a
+// failed import leaves no partial data behind. It returns the number of rows
+// affected as reported by the driver.
+//
+// BeforeCreate and AfterCreate hooks run for every model. Primary keys are
+// written back to the slice on dialects that support RETURNING.
+func (q *QueryBuilder[T]) CreateMany(models []T, batchSize int, ctx ...context.Context) (int64, error) {
+	if len(ctx) > 0 {
+		q.ctx = ctx[0]
+	}
+	if len(models) == 0 {
+		return 0, nil
+	}
+
+	columns := q.insertColumns()
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("orm: model %s has no insertable columns", q.meta.TableName)
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if limit := maxBindParams / len(columns); batchSize > limit {
+		batchSize = limit
+	}
+
+	now := time.Now()
+	for i := range models {
+		if err := callBeforeCreate(q.ctx, q.db, &models[i]); err != nil {
+			return 0, err
+		}
+		v := reflect.ValueOf(&models[i]).Elem()
+		if err := q.checkEnums(v); err != nil {
+			return 0, err
+		}
+		setTimestamp(v, "CreatedAt", now)
+		setTimestamp(v, "UpdatedAt", now)
+		q.initVersion(v)
+	}
+
+	var affected int64
+	err := q.db.Transaction(q.ctx, func(txCtx context.Context) error {
+		txDB, _ := FromContext(txCtx)
+		for start := 0; start < len(models); start += batchSize {
+			end := min(start+batchSize, len(models))
+			n, err := q.insertBatch(txCtx, txDB, columns, models[start:end])
+			if err != nil {
+				return fmt.Errorf("orm: batch insert into %s (rows %d-%d): %w", q.meta.TableName, start, end-1, err)
+			}
+			affected += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range models {
+		_ = callAfterCreate(q.ctx, q.db, &models[i])
+	}
+	return affected, nil
+}
+
+// UpdateMany applies data to every row matched by the builder and returns the
+// number of affected rows.
+func (q *QueryBuilder[T]) UpdateMany(data map[string]any, ctx ...context.Context) (int64, error) {
+	if len(ctx) > 0 {
+		q.ctx = ctx[0]
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	q = q.ApplyScopes()
+
+	set := make(map[string]any, len(data)+1)
+	for k, v := range data {
+		set[k] = v
+	}
+	if _, ok := set["updated_at"]; !ok {
+		if _, ok := q.meta.ColumnByCol["updated_at"]; ok {
+			set["updated_at"] = time.Now()
+		}
+	}
+	evict := q.cacheEvictor()
+	sqlStr, args := q.toUpdateSQL(set)
+	res, err := q.db.conn.Exec(q.ctx, sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+	evict()
+	return res.RowsAffected()
+}
+
+// CreateMany inserts models in batches of batchSize rows.
+// See QueryBuilder.CreateMany for details.
+func CreateMany[T any](db *DB, models []T, batchSize int, ctx ...context.Context) (int64, error) {
+	return Query[T](db, ctx...).CreateMany(models, batchSize)
+}
+
+// UpsertMany inserts models in batches, updating the listed columns when a row
+// conflicts on the conflict columns. An empty update list skips conflicting rows.
+func UpsertMany[T any](db *DB, models []T, conflict, update []string, batchSize int, ctx ...context.Context) (int64, error) {
+	q := Query[T](db, ctx...).OnConflict(conflict...)
+	if len(update) == 0 {
+		return q.DoNothing().CreateMany(models, batchSize)
+	}
+	return q.DoUpdate(update...).CreateMany(models, batchSize)
+}
+
+// insertColumns returns the columns written by an INSERT, in model order.
+func (q *QueryBuilder[T]) insertColumns() []ColumnMeta {
+	cols := make([]ColumnMeta, 0, len(q.meta.Columns))
+	for _, col := range q.meta.Columns {
+		if col.IsAuto || col.IsSoftDel || col.IsGuarded {
+			continue
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// insertBatch writes one multi-row INSERT for batch using the given connection.
+func (q *QueryBuilder[T]) insertBatch(ctx context.Context, db *DB, columns []ColumnMeta, batch []T) (int64, error) {
+	d := db.dialect
+
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(d.QuoteIdentifier(q.meta.TableName))
+	sb.WriteString(" (")
+	for i, col := range columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(d.QuoteIdentifier(col.ColumnName))
+	}
+	sb.WriteString(") VALUES ")
+
+	args := make([]any, 0, len(batch)*len(columns))
+	for r := range batch {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		v := reflect.ValueOf(&batch[r]).Elem()
+		sb.WriteString("(")
+		for i, col := range columns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			value := fieldByIndex(v, col.FieldIndex).Interface()
+			sb.WriteString(geoPlaceholder(d, len(args)+1, value))
+			args = append(args, value)
+		}
+		sb.WriteString(")")
+	}
+	sb.WriteString(q.conflictSQL())
+
+	// Only plain inserts map returned keys back to models one-to-one; upserts
+	// that skip rows would shift the returned ids against the batch.
+	if d.SupportsReturning() && q.meta.PK.IsAuto && q.conflict == nil {
+		sb.WriteString(" RETURNING ")
+		sb.WriteString(d.QuoteIdentifier(q.meta.PK.ColumnName))
+		rows, err := db.conn.Query(ctx, sb.String(), args...)
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+
+		var n int64
+		for rows.Next() {
+			var id uint
+			if err := rows.Scan(&id); err != nil {
+				return n, err
+			}
+			if int(n) < len(batch) {
+				setFieldValue(reflect.ValueOf(&batch[n]).Elem(), q.meta.PK, id)
+			}
+			n++
+		}
+		return n, rows.Err()
+	}
+
+	res, err := db.conn.Exec(ctx, sb.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
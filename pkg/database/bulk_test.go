@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkOperations(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, email TEXT UNIQUE, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	assert.NoError(t, err)
+
+	t.Run("CreateMany", func(t *testing.T) {
+		users := []User{
+			{Name: "A", Email: "a@example.com"},
+			{Name: "B", Email: "b@example.com"},
+			{Name: "C", Email: "c@example.com"},
+		}
+		n, err := CreateMany(db, users, 2, ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.False(t, users[0].CreatedAt.IsZero())
+
+		count, _ := Query[User](db).Count(ctx)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("UpsertMany updates on conflict", func(t *testing.T) {
+		users := []User{
+			{Name: "A2", Email: "a@example.com"},
+			{Name: "D", Email: "d@example.com"},
+		}
+		_, err := UpsertMany(db, users, []string{"email"}, []string{"name"}, 0, ctx)
+		assert.NoError(t, err)
+
+		a, err := Query[User](db).Where("email", "=", "a@example.com").First(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "A2", a.Name)
+
+		count, _ := Query[User](db).Count(ctx)
+		assert.Equal(t, int64(4), count)
+	})
+
+	t.Run("OnConflict DoNothing", func(t *testing.T) {
+		_, err := Query[User](db).OnConflict("email").DoNothing().Create(&User{Name: "ignored", Email: "b@example.com"}, ctx)
+		assert.NoError(t, err)
+
+		b, err := Query[User](db).Where("email", "=", "b@example.com").First(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "B", b.Name)
+	})
+
+	t.Run("CreateMany rolls back the whole import", func(t *testing.T) {
+		users := []User{
+			{Name: "E", Email: "e@example.com"},
+			{Name: "dup", Email: "a@example.com"},
+		}
+		_, err := CreateMany(db, users, 1, ctx)
+		assert.Error(t, err)
+
+		exists, _ := Query[User](db).Where("email", "=", "e@example.com").Exists(ctx)
+		assert.False(t, exists)
+	})
+
+	t.Run("UpdateMany", func(t *testing.T) {
+		n, err := Query[User](db).WhereIn("email", []any{"c@example.com", "d@example.com"}).
+			UpdateMany(map[string]any{"name": "bulk"}, ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/cache"
+)
+
+// modelCaches records, per table, the cache stores FindCached has written
+// to, so writes through the ORM know which entries to invalidate.
+var modelCaches sync.Map // table name → *cacheSet
+
+type cacheSet struct {
+	mu     sync.RWMutex
+	stores []cache.Store
+}
+
+func (s *cacheSet) add(store cache.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.stores {
+		if existing == store {
+			return
+		}
+	}
+	s.stores = append(s.stores, store)
+}
+
+func (s *cacheSet) snapshot() []cache.Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]cache.Store(nil), s.stores...)
+}
+
+// ModelCacheKey returns the cache key FindCached uses for a row.
+func ModelCacheKey(table string, id any) string {
+	if b, ok := id.([]byte); ok {
+		id = string(b)
+	}
+	return fmt.Sprintf("orm:%s:%v", table, id)
+}
+
+// FindCached loads a model by primary key through store, caching the row for
+// ttl. Subsequent Save, Update, Delete and ForceDelete calls on the model
+// evict the entry once their transaction commits, so readers never see a
+// cached row that is older than the last committed write made through the ORM.
+//
+// Inside a transaction the cache is bypassed so uncommitted writes stay
+// visible to the caller.
+//
+//	user, err := database.FindCached[User](db, store, id, 5*time.Minute, ctx)
+func FindCached[T any](db *DB, store cache.Store, id any, ttl time.Duration, ctx ...context.Context) (*T, error) {
+	q := Query[T](db, ctx...)
+	set, _ := modelCaches.LoadOrStore(q.meta.TableName, &cacheSet{})
+	set.(*cacheSet).add(store)
+
+	if q.db.inTx {
+		return q.FindByID(id)
+	}
+
+	key := ModelCacheKey(q.meta.TableName, id)
+	if raw, err := store.Get(q.ctx, key); err == nil {
+		var model T
+		if err := gob.NewDecoder(bytes.NewBufferString(raw)).Decode(&model); err == nil {
+			return &model, nil
+		}
+		// A payload from an older struct layout; fall through and refresh it.
+	}
+
+	model, err := q.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(model); err != nil {
+		slog.Warn("orm: model not cacheable", "table", q.meta.TableName, "error", err)
+		return model, nil
+	}
+	if err := store.Set(q.ctx, key, buf.String(), ttl); err != nil {
+		slog.Warn("orm: cache write failed", "key", key, "error", err)
+	}
+	return model, nil
+}
+
+// cacheEvictor resolves the cached rows matched by the builder's current
+// conditions and returns a func that evicts them once the surrounding
+// transaction commits. It must be called before the write so the ids can
+// still be resolved, and the returned func only after the write succeeded.
+func (q *QueryBuilder[T]) cacheEvictor() func() {
+	v, ok := modelCaches.Load(q.meta.TableName)
+	if !ok {
+		return func() {}
+	}
+	ids := q.cachedIDs()
+	if len(ids) == 0 {
+		return func() {}
+	}
+	stores := v.(*cacheSet).snapshot()
+	ctx := context.WithoutCancel(q.ctx)
+	table := q.meta.TableName
+
+	return func() {
+		q.db.AfterCommit(func() {
+			for _, store := range stores {
+				for _, id := range ids {
+					if err := store.Delete(ctx, ModelCacheKey(table, id)); err != nil {
+						slog.Warn("orm: cache invalidation failed", "table", table, "id", id, "error", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// cachedIDs resolves the primary keys a write is about to touch. When the
+// conditions pin the primary key with = or IN, those values are used and no
+// query is made; anything else is looked up with the conditions as they
+// are, since the write already applied the scopes.
+func (q *QueryBuilder[T]) cachedIDs() []any {
+	var pk []any
+	for _, w := range q.wheres {
+		if w.Or {
+			pk = nil
+			break
+		}
+		if w.Column != q.meta.PK.ColumnName {
+			continue
+		}
+		switch w.Operator {
+		case "=":
+			pk = []any{w.Value}
+		case "IN":
+			if values, ok := w.Value.([]any); ok {
+				pk = values
+			}
+		}
+	}
+	if pk != nil {
+		return pk
+	}
+
+	ids, err := q.pluck(q.meta.PK.ColumnName)
+	if err != nil {
+		slog.Warn("orm: could not resolve rows for cache invalidation", "table", q.meta.TableName, "error", err)
+		return nil
+	}
+	return ids
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCached(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, email TEXT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	require.NoError(t, err)
+
+	store := cache.NewMemoryStore()
+	user, err := Query[User](db).Create(&User{Name: "Alice", Email: "alice@example.com"}, ctx)
+	require.NoError(t, err)
+
+	t.Run("read-through", func(t *testing.T) {
+		found, err := FindCached[User](db, store, user.ID, time.Minute, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", found.Name)
+
+		// A write that bypasses the ORM is not seen until the entry expires.
+		_, err = db.Exec(ctx, "UPDATE users SET name = ? WHERE id = ?", "Raw", user.ID)
+		require.NoError(t, err)
+		found, err = FindCached[User](db, store, user.ID, time.Minute, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", found.Name)
+	})
+
+	t.Run("save invalidates", func(t *testing.T) {
+		user.Name = "Bob"
+		require.NoError(t, Query[User](db).Save(user, ctx))
+
+		found, err := FindCached[User](db, store, user.ID, time.Minute, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Bob", found.Name)
+	})
+
+	t.Run("invalidation waits for commit", func(t *testing.T) {
+		_, err := FindCached[User](db, store, user.ID, time.Minute, ctx)
+		require.NoError(t, err)
+
+		err = db.Transaction(ctx, func(txCtx context.Context) error {
+			if err := Query[User](db, txCtx).Where("id", "=", user.ID).Update(map[string]any{"name": "Carol"}); err != nil {
+				return err
+			}
+			has, _ := store.Has(ctx, ModelCacheKey("users", user.ID))
+			assert.True(t, has, "entry must survive until commit")
+			return errors.New("rollback")
+		})
+		require.Error(t, err)
+
+		found, err := FindCached[User](db, store, user.ID, time.Minute, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Bob", found.Name)
+	})
+
+	t.Run("delete invalidates", func(t *testing.T) {
+		require.NoError(t, Query[User](db).Where("email", "=", "alice@example.com").Delete(ctx))
+
+		_, err := FindCached[User](db, store, user.ID, time.Minute, ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestFindCached_ScopedWrites(t *testing.T) {
+	ctx := context.Background()
+	var queries []string
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:", QueryHook: func(sql string, _ []any, _ time.Duration) {
+		queries = append(queries, sql)
+	}})
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, email TEXT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	require.NoError(t, err)
+	store := cache.NewMemoryStore()
+	user, err := Query[User](db).Create(&User{Name: "Alice", Email: "alice@example.com"}, ctx)
+	require.NoError(t, err)
+	_, err = FindCached[User](db, store, user.ID, time.Minute, ctx)
+	require.NoError(t, err)
+
+	notBanned := func(q *QueryBuilder[User]) *QueryBuilder[User] { return q.Where("email", "!=", "banned@example.com") }
+
+	// The lookup of the rows to evict carries the scope once, like the write.
+	queries = nil
+	require.NoError(t, Query[User](db).GlobalScope(notBanned).Where("name", "=", "Alice").Update(map[string]any{"name": "Bob"}, ctx))
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], "SELECT")
+	assert.Equal(t, 1, strings.Count(queries[0], "email"))
+	assert.Equal(t, 1, strings.Count(queries[1], "email"))
+
+	found, err := FindCached[User](db, store, user.ID, time.Minute, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", found.Name)
+
+	// A write pinned to primary keys needs no lookup.
+	queries = nil
+	require.NoError(t, Query[User](db).WhereIn("id", []any{user.ID}).Update(map[string]any{"name": "Carol"}, ctx))
+	require.Len(t, queries, 1)
+	assert.NotContains(t, queries[0], "SELECT")
+
+	found, err = FindCached[User](db, store, user.ID, time.Minute, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Carol", found.Name)
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // Dialect provides database-specific SQL generation
@@ -13,7 +14,7 @@ type Dialect interface {
 	SupportsReturning() bool
 	AutoIncrementDDL() string
 	LimitOffsetSQL(limit, offset int) string
-	UpsertSQL(table string, columns []string, conflict string) string
+	UpsertSQL(conflict []string, update []string) string
 	AdvisoryLock(id int64) string
 	AdvisoryUnlock(id int64) string
 	ConfigurePool(db *sql.DB)
@@ -30,9 +31,8 @@ func (d PostgresDialect) AutoIncrementDDL() string           { return "SERIAL" }
 func (d PostgresDialect) LimitOffsetSQL(limit, offset int) string {
 	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 }
-func (d PostgresDialect) UpsertSQL(table string, columns []string, conflict string) string {
-	// Generic implementation for now
-	return ""
+func (d PostgresDialect) UpsertSQL(conflict []string, update []string) string {
+	return onConflictSQL(d, conflict, update)
 }
 func (d PostgresDialect) AdvisoryLock(id int64) string {
 	return fmt.Sprintf("SELECT pg_advisory_lock(%d)", id)
@@ -53,8 +53,22 @@ func (d MySQLDialect) AutoIncrementDDL() string           { return "AUTO_INCREME
 func (d MySQLDialect) LimitOffsetSQL(limit, offset int) string {
 	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 }
-func (d MySQLDialect) UpsertSQL(table string, columns []string, conflict string) string {
-	return ""
+func (d MySQLDialect) UpsertSQL(conflict []string, update []string) string {
+	// MySQL resolves conflicts against any unique key, so the target columns
+	// are only used to build a no-op assignment for DO NOTHING semantics.
+	if len(update) == 0 {
+		if len(conflict) == 0 {
+			return ""
+		}
+		col := d.QuoteIdentifier(conflict[0])
+		return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", col, col)
+	}
+	sets := make([]string, len(update))
+	for i, col := range update {
+		q := d.QuoteIdentifier(col)
+		sets[i] = fmt.Sprintf("%s = VALUES(%s)", q, q)
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
 }
 func (d MySQLDialect) AdvisoryLock(id int64) string {
 	return fmt.Sprintf("SELECT GET_LOCK('astra_migration_%d', 10)", id)
@@ -75,8 +89,8 @@ func (d SQLiteDialect) AutoIncrementDDL() string           { return "INTEGER PRI
 func (d SQLiteDialect) LimitOffsetSQL(limit, offset int) string {
 	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 }
-func (d SQLiteDialect) UpsertSQL(table string, columns []string, conflict string) string {
-	return ""
+func (d SQLiteDialect) UpsertSQL(conflict []string, update []string) string {
+	return onConflictSQL(d, conflict, update)
 }
 func (d SQLiteDialect) AdvisoryLock(id int64) string {
 	return "" // SQLite doesn't need advisory locks for single-file access usually, or doesn't support them.
@@ -85,3 +99,34 @@ func (d SQLiteDialect) AdvisoryUnlock(id int64) string {
 	return ""
 }
 func (d SQLiteDialect) ConfigurePool(db *sql.DB) {}
+
+// onConflictSQL builds the standard "ON CONFLICT (...) DO UPDATE" suffix shared
+// by PostgreSQL and SQLite. An empty update list yields DO NOTHING.
+func onConflictSQL(d Dialect, conflict []string, update []string) string {
+	var sb strings.Builder
+	sb.WriteString(" ON CONFLICT")
+	if len(conflict) > 0 {
+		quoted := make([]string, len(conflict))
+		for i, col := range conflict {
+			quoted[i] = d.QuoteIdentifier(col)
+		}
+		sb.WriteString(" (")
+		sb.WriteString(strings.Join(quoted, ", "))
+		sb.WriteString(")")
+	}
+	if len(update) == 0 {
+		sb.WriteString(" DO NOTHING")
+		return sb.String()
+	}
+	sb.WriteString(" DO UPDATE SET ")
+	for i, col := range update {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		q := d.QuoteIdentifier(col)
+		sb.WriteString(q)
+		sb.WriteString(" = EXCLUDED.")
+		sb.WriteString(q)
+	}
+	return sb.String()
+}
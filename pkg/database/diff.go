@@ -0,0 +1,249 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/database/schema"
+	"github.com/shauryagautam/Astra/pkg/support/enum"
+)
+
+// SchemaDiff is the difference between a set of models and the live schema,
+// as explicit statements for a migration file. It only ever adds: a column
+// the models no longer map is listed in Notes for a human to drop, never
+// dropped automatically.
+type SchemaDiff struct {
+	Up    []string
+	Down  []string // reverses Up, in the order it must run
+	Notes []string
+}
+
+// Empty reports whether the models and the schema already agree.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.Up) == 0 && len(d.Notes) == 0
+}
+
+// DiffModels compares models (pointers or values of model structs) with the
+// connected database. See Diff.
+func (db *DB) DiffModels(ctx context.Context, models ...any) (*SchemaDiff, error) {
+	metas := make([]*ModelMeta, len(models))
+	for i, m := range models {
+		metas[i] = GetMeta(reflect.TypeOf(m))
+	}
+	return db.Diff(ctx, metas...)
+}
+
+// Diff compares model metadata with the connected database and returns the
+// statements that bring the schema in line: CREATE TABLE for missing
+// tables, ADD COLUMN for missing columns, and CREATE INDEX for fields tagged
+// `orm:"index"` or `orm:"unique"` that no existing index covers. Column
+// types come from the Go field type unless the field has an `orm:"type:..."`
+// tag. Added NOT NULL columns get the zero value as default so the ALTER
+// succeeds on tables that already hold rows.
+func (db *DB) Diff(ctx context.Context, metas ...*ModelMeta) (*SchemaDiff, error) {
+	tables, err := db.Tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b := db.Schema()
+	diff := &SchemaDiff{}
+	var down [][]string
+
+	for _, meta := range metas {
+		if !slices.Contains(tables, meta.TableName) {
+			t := &schema.Table{Name: meta.TableName}
+			for _, col := range meta.Columns {
+				t.Columns = append(t.Columns, db.schemaColumn(col, false))
+				switch {
+				case col.IsUnique:
+					t.AddUniqueIndex(col.ColumnName)
+				case col.IsIndexed:
+					t.AddIndex(col.ColumnName)
+				}
+			}
+			diff.Up = append(diff.Up, b.CreateTableSQL(t)...)
+			down = append(down, []string{b.DropTableSQL(meta.TableName)})
+			tables = append(tables, meta.TableName)
+			continue
+		}
+
+		existing, err := db.Columns(ctx, meta.TableName)
+		if err != nil {
+			return nil, fmt.Errorf("orm: inspect columns of %s: %w", meta.TableName, err)
+		}
+		indexes, err := db.Indexes(ctx, meta.TableName)
+		if err != nil {
+			return nil, fmt.Errorf("orm: inspect indexes of %s: %w", meta.TableName, err)
+		}
+
+		mapped := make(map[string]bool, len(meta.Columns))
+		for _, col := range meta.Columns {
+			mapped[col.ColumnName] = true
+			if !slices.Contains(existing, col.ColumnName) {
+				diff.Up = append(diff.Up, b.AddColumnSQL(meta.TableName, db.schemaColumn(col, true)))
+				down = append(down, []string{b.DropColumnSQL(meta.TableName, col.ColumnName)})
+			}
+			if (!col.IsUnique && !col.IsIndexed) || hasIndex(indexes, col.ColumnName, col.IsUnique) {
+				continue
+			}
+			idx := schema.Index{Columns: []string{col.ColumnName}}
+			diff.Up = append(diff.Up, b.CreateIndexSQL(meta.TableName, idx, col.IsUnique))
+			down = append(down, []string{b.DropIndexSQL(meta.TableName, schema.IndexName(meta.TableName, idx, col.IsUnique))})
+		}
+		for _, name := range existing {
+			if !mapped[name] {
+				diff.Notes = append(diff.Notes, fmt.Sprintf("%s.%s is not mapped by %s; drop it in a migration of its own if it is unused",
+					meta.TableName, name, meta.Type.Name()))
+			}
+		}
+	}
+
+	for i := len(down) - 1; i >= 0; i-- {
+		diff.Down = append(diff.Down, down[i]...)
+	}
+	return diff, nil
+}
+
+// hasIndex reports whether an index leads with column; a unique column
+// needs a single-column unique index.
+func hasIndex(indexes []IndexInfo, column string, unique bool) bool {
+	for _, idx := range indexes {
+		if unique {
+			if idx.Unique && len(idx.Columns) == 1 && idx.Columns[0] == column {
+				return true
+			}
+			continue
+		}
+		if idx.Columns[0] == column {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	pointType   = reflect.TypeOf(Point{})
+	polygonType = reflect.TypeOf(Polygon{})
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// schemaColumn derives the DDL for col. adding selects the ADD COLUMN form,
+// which gives NOT NULL columns a default.
+func (db *DB) schemaColumn(col ColumnMeta, adding bool) *schema.Column {
+	c := &schema.Column{Name: col.ColumnName}
+	if col.IsPK {
+		if col.IsAuto {
+			c.Type = "BIGINT"
+			c.IsAuto = true
+			return c
+		}
+		c.IsPrimary = true
+	}
+
+	t, nullable := nullableElem(col.Type)
+	c.IsNullable = nullable || col.IsSoftDel || col.IsNullZero
+	c.Type = col.SQLType
+	if c.Type == "" {
+		c.Type = db.sqlType(t)
+	}
+	e, isEnum := enum.Lookup(t)
+	if isEnum {
+		c.Enum = e.Strings()
+	}
+	switch {
+	case col.IsVersion:
+		c.DefaultValue = 1
+	case adding && isEnum && !c.IsNullable:
+		// The zero value "" would fail the CHECK constraint.
+		c.DefaultValue = schema.QuoteString(c.Enum[0])
+	case adding && !c.IsNullable && !c.IsPrimary:
+		c.DefaultValue = zeroDefault(t)
+		c.IsNullable = c.DefaultValue == nil
+	}
+	return c
+}
+
+// nullableElem unwraps pointers and database/sql Null types, reporting
+// whether the column may hold NULL.
+func nullableElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem(), true
+	}
+	if t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && t.NumField() == 2 && t.Field(1).Name == "Valid" {
+		return t.Field(0).Type, true
+	}
+	return t, false
+}
+
+// sqlType maps a Go type to the column type of the connected dialect.
+func (db *DB) sqlType(t reflect.Type) string {
+	dialect := db.dialect.Name()
+	pick := func(postgres, mysql, sqlite string) string {
+		switch dialect {
+		case "mysql":
+			return mysql
+		case "sqlite":
+			return sqlite
+		default:
+			return postgres
+		}
+	}
+
+	switch {
+	case t == timeType:
+		return "TIMESTAMP"
+	case t == pointType:
+		return schema.SpatialType(dialect, "Point")
+	case t == polygonType:
+		return schema.SpatialType(dialect, "Polygon")
+	case t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8:
+		return pick("UUID", "CHAR(36)", "CHAR(36)")
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return pick("BYTEA", "BLOB", "BLOB")
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "VARCHAR(255)"
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		return pick("DOUBLE PRECISION", "DOUBLE", "REAL")
+	}
+	// Types with their own encoding, such as Encrypted, store text.
+	if t.Implements(valuerType) || reflect.PointerTo(t).Implements(valuerType) {
+		return "TEXT"
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Struct:
+		return pick("JSONB", "JSON", "TEXT")
+	}
+	return "TEXT"
+}
+
+// zeroDefault is the DEFAULT for a NOT NULL column added to a table that
+// may already have rows, or nil when the type has no sensible literal.
+func zeroDefault(t reflect.Type) any {
+	if t == timeType {
+		return "CURRENT_TIMESTAMP"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "''"
+	case reflect.Bool:
+		return "FALSE"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 0
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/database/schema"
+	"github.com/shauryagautam/Astra/pkg/support/enum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type diffAccount struct {
+	Model
+	Email    string `orm:"unique"`
+	Plan     string `orm:"index"`
+	Balance  float64
+	Nickname sql.NullString
+	Active   bool
+}
+
+func (diffAccount) TableName() string { return "diff_accounts" }
+
+func TestDiffCreatesMissingTable(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	diff, err := db.DiffModels(ctx, &diffAccount{})
+	require.NoError(t, err)
+	require.Len(t, diff.Up, 3)
+	assert.Equal(t, "CREATE TABLE `diff_accounts` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, "+
+		"`created_at` TIMESTAMP NOT NULL, `updated_at` TIMESTAMP NOT NULL, `deleted_at` TIMESTAMP, "+
+		"`email` VARCHAR(255) NOT NULL, `plan` VARCHAR(255) NOT NULL, `balance` REAL NOT NULL, "+
+		"`nickname` VARCHAR(255), `active` BOOLEAN NOT NULL)", diff.Up[0])
+	assert.Equal(t, "CREATE INDEX `idx_diff_accounts_plan` ON `diff_accounts` (`plan`)", diff.Up[1])
+	assert.Equal(t, "CREATE UNIQUE INDEX `uniq_diff_accounts_email` ON `diff_accounts` (`email`)", diff.Up[2])
+	assert.Equal(t, []string{"DROP TABLE `diff_accounts`"}, diff.Down)
+
+	for _, stmt := range diff.Up {
+		_, err := db.Exec(ctx, stmt)
+		require.NoError(t, err, stmt)
+	}
+	again, err := db.DiffModels(ctx, &diffAccount{})
+	require.NoError(t, err)
+	assert.True(t, again.Empty(), "applying the diff should leave nothing to do: %v", again.Up)
+}
+
+func TestDiffAddsColumnsAndIndexes(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(ctx, "CREATE TABLE diff_accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, "+
+		"created_at DATETIME, updated_at DATETIME, deleted_at DATETIME, email TEXT, legacy TEXT)")
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO diff_accounts (email) VALUES ('a@example.com')")
+	require.NoError(t, err)
+
+	diff, err := db.DiffModels(ctx, &diffAccount{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE UNIQUE INDEX `uniq_diff_accounts_email` ON `diff_accounts` (`email`)",
+		"ALTER TABLE `diff_accounts` ADD COLUMN `plan` VARCHAR(255) NOT NULL DEFAULT ''",
+		"CREATE INDEX `idx_diff_accounts_plan` ON `diff_accounts` (`plan`)",
+		"ALTER TABLE `diff_accounts` ADD COLUMN `balance` REAL NOT NULL DEFAULT 0",
+		"ALTER TABLE `diff_accounts` ADD COLUMN `nickname` VARCHAR(255)",
+		"ALTER TABLE `diff_accounts` ADD COLUMN `active` BOOLEAN NOT NULL DEFAULT FALSE",
+	}, diff.Up)
+	assert.Equal(t, []string{
+		"ALTER TABLE `diff_accounts` DROP COLUMN `active`",
+		"ALTER TABLE `diff_accounts` DROP COLUMN `nickname`",
+		"ALTER TABLE `diff_accounts` DROP COLUMN `balance`",
+		"DROP INDEX IF EXISTS `idx_diff_accounts_plan`",
+		"ALTER TABLE `diff_accounts` DROP COLUMN `plan`",
+		"DROP INDEX IF EXISTS `uniq_diff_accounts_email`",
+	}, diff.Down)
+	require.Len(t, diff.Notes, 1)
+	assert.Contains(t, diff.Notes[0], "diff_accounts.legacy")
+
+	// The statements run against a table that already has rows, and the
+	// down statements undo them.
+	for _, stmt := range diff.Up {
+		_, err := db.Exec(ctx, stmt)
+		require.NoError(t, err, stmt)
+	}
+	for _, stmt := range diff.Down {
+		_, err := db.Exec(ctx, stmt)
+		require.NoError(t, err, stmt)
+	}
+	cols, err := db.Columns(ctx, "diff_accounts")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "created_at", "updated_at", "deleted_at", "email", "legacy"}, cols)
+}
+
+type ticketStatus string
+
+var ticketStatuses = enum.New[ticketStatus]("open", "closed", "won't fix")
+
+type ticket struct {
+	ID     uint `orm:"primaryKey;autoIncrement"`
+	Status ticketStatus
+	Triage *ticketStatus
+}
+
+func (ticket) TableName() string { return "tickets" }
+
+func TestEnumColumns(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	diff, err := db.DiffModels(ctx, &ticket{})
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE `tickets` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, "+
+		"`status` VARCHAR(255) NOT NULL CHECK (`status` IN ('open', 'closed', 'won''t fix')), "+
+		"`triage` VARCHAR(255) CHECK (`triage` IN ('open', 'closed', 'won''t fix')))", diff.Up[0])
+	for _, stmt := range diff.Up {
+		_, err := db.Exec(ctx, stmt)
+		require.NoError(t, err, stmt)
+	}
+
+	created, err := Query[ticket](db).Create(&ticket{Status: "won't fix"}, ctx)
+	require.NoError(t, err)
+	found, err := Query[ticket](db).FindByID(created.ID, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ticketStatus("won't fix"), found.Status)
+	assert.Nil(t, found.Triage)
+
+	_, err = Query[ticket](db).Create(&ticket{Status: "reopened"}, ctx)
+	var invalid *InvalidEnumError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, `orm: tickets.status cannot be "reopened"; it must be one of open, closed, won't fix`, err.Error())
+	triage := ticketStatus("later")
+	assert.ErrorAs(t, Query[ticket](db).Save(&ticket{ID: created.ID, Status: "open", Triage: &triage}, ctx), &invalid)
+	_, err = db.Exec(ctx, "INSERT INTO tickets (status) VALUES ('reopened')")
+	assert.Error(t, err, "the CHECK constraint guards writes that bypass the ORM")
+
+	b := &schema.Builder{Dialect: MySQLDialect{}}
+	assert.Equal(t, "ALTER TABLE `tickets` ADD COLUMN `status` ENUM('open', 'closed', 'won''t fix') NOT NULL DEFAULT 'open'",
+		b.AddColumnSQL("tickets", db.schemaColumn(GetMeta(reflect.TypeFor[ticket]()).ColumnByCol["status"], true)))
+}
@@ -0,0 +1,112 @@
+package dump
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openFixture(t *testing.T, seed bool) *database.DB {
+	t.Helper()
+	ctx := context.Background()
+	db, err := database.Open(database.Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	// A single connection keeps the in-memory database shared.
+	db.Pool().SetMaxOpenConns(1)
+
+	for _, stmt := range []string{
+		"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT NOT NULL, bio TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, author_id INTEGER NOT NULL REFERENCES authors(id), title TEXT, score REAL)",
+	} {
+		_, err := db.Exec(ctx, stmt)
+		require.NoError(t, err)
+	}
+	if seed {
+		_, err = db.Exec(ctx, "INSERT INTO authors (id, name, bio) VALUES (1, 'Ada', NULL), (2, 'O''Brien', 'semi;colon, \"quoted\"')")
+		require.NoError(t, err)
+		_, err = db.Exec(ctx, "INSERT INTO posts (id, author_id, title, score) VALUES (10, 1, 'First', 1.5), (11, 2, 'Second', 2)")
+		require.NoError(t, err)
+	}
+	return db
+}
+
+func TestSortTablesByDependency(t *testing.T) {
+	db := openFixture(t, false)
+	tables, err := db.SortTablesByDependency(context.Background(), []string{"posts", "authors"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"authors", "posts"}, tables)
+}
+
+func TestDumpRestoreRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatCSV, FormatSQL} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+			src := openFixture(t, true)
+			dir := t.TempDir()
+
+			dumped, err := Dump(ctx, src, dir, Options{Format: format, ChunkSize: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []TableResult{{"authors", 2}, {"posts", 2}}, dumped)
+			_, err = os.Stat(filepath.Join(dir, "posts."+string(format)))
+			require.NoError(t, err)
+
+			dst := openFixture(t, false)
+			_, err = Restore(ctx, dst, dir, Options{Format: format, ChunkSize: 1})
+			require.NoError(t, err)
+
+			var name string
+			var bio *string
+			require.NoError(t, dst.QueryRow(ctx, "SELECT name, bio FROM authors WHERE id = 2").Scan(&name, &bio))
+			assert.Equal(t, "O'Brien", name)
+			require.NotNil(t, bio)
+			assert.Equal(t, `semi;colon, "quoted"`, *bio)
+
+			require.NoError(t, dst.QueryRow(ctx, "SELECT bio FROM authors WHERE id = 1").Scan(&bio))
+			assert.Nil(t, bio)
+
+			var count int
+			require.NoError(t, dst.QueryRow(ctx, "SELECT COUNT(*) FROM posts WHERE author_id IN (1, 2)").Scan(&count))
+			assert.Equal(t, 2, count)
+
+			// Restoring again with Truncate replaces rather than duplicates.
+			_, err = Restore(ctx, dst, dir, Options{Format: format, Truncate: true})
+			require.NoError(t, err)
+			require.NoError(t, dst.QueryRow(ctx, "SELECT COUNT(*) FROM authors").Scan(&count))
+			assert.Equal(t, 2, count)
+		})
+	}
+}
+
+func TestDumpRestoreBinary(t *testing.T) {
+	blob := []byte{0xff, 0x00, 0xfe, '\n', ',', '"'}
+	for _, format := range []Format{FormatJSON, FormatCSV, FormatSQL} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+			src := openFixture(t, false)
+			_, err := src.Exec(ctx, "CREATE TABLE files (id INTEGER PRIMARY KEY, data BLOB)")
+			require.NoError(t, err)
+			_, err = src.Exec(ctx, "INSERT INTO files (id, data) VALUES (1, ?)", blob)
+			require.NoError(t, err)
+			dir := t.TempDir()
+			_, err = Dump(ctx, src, dir, Options{Format: format, Tables: []string{"files"}})
+			require.NoError(t, err)
+
+			dst := openFixture(t, false)
+			_, err = dst.Exec(ctx, "CREATE TABLE files (id INTEGER PRIMARY KEY, data BLOB)")
+			require.NoError(t, err)
+			_, err = Restore(ctx, dst, dir, Options{Format: format})
+			require.NoError(t, err)
+
+			var got []byte
+			require.NoError(t, dst.QueryRow(ctx, "SELECT data FROM files WHERE id = 1").Scan(&got))
+			assert.Equal(t, blob, got)
+		})
+	}
+}
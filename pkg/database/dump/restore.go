@@ -0,0 +1,357 @@
+package dump
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+)
+
+// maxBindParams keeps restore batches under SQLite's placeholder limit, the
+// lowest of the supported drivers.
+const maxBindParams = 32766
+
+// Restore loads every <table>.<format> file in dir, parents first, inside a
+// single transaction. With opts.Truncate the affected tables are emptied
+// (children first) before any rows are inserted.
+func Restore(ctx context.Context, db *database.DB, dir string, opts Options) ([]TableResult, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*."+string(opts.Format)))
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for _, m := range matches {
+		tables = append(tables, strings.TrimSuffix(filepath.Base(m), filepath.Ext(m)))
+	}
+	tables = selectTables(tables, opts.Tables)
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("dump: no %s files found in %s", opts.Format, dir)
+	}
+	if tables, err = db.SortTablesByDependency(ctx, tables); err != nil {
+		return nil, err
+	}
+
+	var results []TableResult
+	err = db.Transaction(ctx, func(txCtx context.Context) error {
+		if opts.Truncate {
+			for _, table := range slices.Backward(tables) {
+				if _, err := db.Exec(txCtx, "DELETE FROM "+db.Dialect().QuoteIdentifier(table)); err != nil {
+					return fmt.Errorf("dump: truncate %s: %w", table, err)
+				}
+			}
+		}
+		for _, table := range tables {
+			n, err := restoreFile(txCtx, db, table, filepath.Join(dir, table+"."+string(opts.Format)), opts)
+			if err != nil {
+				return fmt.Errorf("dump: restore %s: %w", table, err)
+			}
+			results = append(results, TableResult{Table: table, Rows: n})
+		}
+		return resetSequences(txCtx, db, tables)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func restoreFile(ctx context.Context, db *database.DB, table, path string, opts Options) (int64, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is built from the input dir and a table name
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return RestoreTable(ctx, db, table, f, opts)
+}
+
+// RestoreTable reads one table in the configured format from r and inserts
+// its rows in batches of opts.ChunkSize. For FormatSQL the file is executed
+// as-is and the returned count is the number of statements run.
+func RestoreTable(ctx context.Context, db *database.DB, table string, r io.Reader, opts Options) (int64, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return 0, err
+	}
+	switch opts.Format {
+	case FormatSQL:
+		return execStatements(ctx, db, r)
+	case FormatCSV:
+		return restoreCSV(ctx, db, table, r, opts.ChunkSize)
+	default:
+		return restoreJSON(ctx, db, table, r, opts.ChunkSize)
+	}
+}
+
+// batchInserter accumulates rows and flushes them as multi-row INSERTs.
+type batchInserter struct {
+	ctx     context.Context
+	db      *database.DB
+	table   string
+	columns []string
+	limit   int
+	rows    [][]any
+	total   int64
+}
+
+func newBatchInserter(ctx context.Context, db *database.DB, table string, columns []string, chunk int) *batchInserter {
+	limit := chunk
+	if len(columns) > 0 {
+		limit = min(chunk, maxBindParams/len(columns))
+	}
+	return &batchInserter{ctx: ctx, db: db, table: table, columns: columns, limit: max(limit, 1)}
+}
+
+func (b *batchInserter) add(row []any) error {
+	b.rows = append(b.rows, row)
+	if len(b.rows) >= b.limit {
+		return b.flush()
+	}
+	return nil
+}
+
+func (b *batchInserter) flush() error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+	d := b.db.Dialect()
+	quoted := make([]string, len(b.columns))
+	for i, c := range b.columns {
+		quoted[i] = d.QuoteIdentifier(c)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(d.QuoteIdentifier(b.table))
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(quoted, ", "))
+	sb.WriteString(") VALUES ")
+
+	args := make([]any, 0, len(b.rows)*len(b.columns))
+	for r, row := range b.rows {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for i, v := range row {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			args = append(args, v)
+			sb.WriteString(d.Placeholder(len(args)))
+		}
+		sb.WriteString(")")
+	}
+
+	if _, err := b.db.Exec(b.ctx, sb.String(), args...); err != nil {
+		return err
+	}
+	b.total += int64(len(b.rows))
+	b.rows = b.rows[:0]
+	return nil
+}
+
+func restoreJSON(ctx context.Context, db *database.DB, table string, r io.Reader, chunk int) (int64, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return 0, errors.New("expected a JSON array of rows")
+	}
+
+	var ins *batchInserter
+	for dec.More() {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return 0, err
+		}
+		if ins == nil {
+			columns := make([]string, 0, len(obj))
+			for k := range obj {
+				columns = append(columns, k)
+			}
+			slices.Sort(columns)
+			ins = newBatchInserter(ctx, db, table, columns, chunk)
+		}
+		row := make([]any, len(ins.columns))
+		for i, col := range ins.columns {
+			v, err := fromJSON(obj[col])
+			if err != nil {
+				return ins.total, fmt.Errorf("column %s: %w", col, err)
+			}
+			row[i] = v
+		}
+		if err := ins.add(row); err != nil {
+			return ins.total, err
+		}
+	}
+	if ins == nil {
+		return 0, nil
+	}
+	if err := ins.flush(); err != nil {
+		return ins.total, err
+	}
+	return ins.total, nil
+}
+
+// fromJSON converts a decoded JSON value into a driver argument. Binary
+// values are decoded from their tag; other nested objects and arrays are
+// re-encoded so they land in JSON/text columns intact.
+func fromJSON(v any) (any, error) {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i, nil
+		}
+		return x.Float64()
+	case map[string]any:
+		if enc, ok := x[jsonBinary].(string); ok && len(x) == 1 {
+			return base64.StdEncoding.DecodeString(enc)
+		}
+		b, err := json.Marshal(x)
+		return string(b), err
+	case []any:
+		b, err := json.Marshal(x)
+		return string(b), err
+	default:
+		return v, nil
+	}
+}
+
+func restoreCSV(ctx context.Context, db *database.DB, table string, r io.Reader, chunk int) (int64, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	ins := newBatchInserter(ctx, db, table, slices.Clone(header), chunk)
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ins.total, err
+		}
+		row := make([]any, len(record))
+		for i, field := range record {
+			switch {
+			case field == csvNull:
+				row[i] = nil
+			case strings.HasPrefix(field, csvBinary):
+				b, err := base64.StdEncoding.DecodeString(field[len(csvBinary):])
+				if err != nil {
+					return ins.total, fmt.Errorf("column %s: %w", header[i], err)
+				}
+				row[i] = b
+			default:
+				row[i] = field
+			}
+		}
+		if err := ins.add(row); err != nil {
+			return ins.total, err
+		}
+	}
+	if err := ins.flush(); err != nil {
+		return ins.total, err
+	}
+	return ins.total, nil
+}
+
+// execStatements runs every ';'-terminated statement in r, returning the
+// number of statements executed. Semicolons inside quoted literals are kept.
+func execStatements(ctx context.Context, db *database.DB, r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	var (
+		sb      strings.Builder
+		inQuote bool
+		n       int64
+	)
+	for {
+		c, _, err := br.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		if c == '\'' {
+			inQuote = !inQuote
+		}
+		if c == ';' && !inQuote {
+			if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+				if _, err := db.Exec(ctx, stmt); err != nil {
+					return n, err
+				}
+				n++
+			}
+			sb.Reset()
+			continue
+		}
+		sb.WriteRune(c)
+	}
+	if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// resetSequences moves PostgreSQL id sequences past the restored rows so the
+// next application insert does not collide with an imported primary key.
+// Other dialects derive the next id from the table itself. Tables without an
+// id column, or whose id has no sequence, are skipped.
+func resetSequences(ctx context.Context, db *database.DB, tables []string) error {
+	switch db.Dialect().Name() {
+	case "postgres", "neon":
+	default:
+		return nil
+	}
+	for _, table := range tables {
+		columns, err := db.Columns(ctx, table)
+		if err != nil {
+			return fmt.Errorf("dump: reset sequence of %s: %w", table, err)
+		}
+		if !slices.Contains(columns, "id") {
+			continue
+		}
+		var seq *string
+		if err := db.QueryRow(ctx, "SELECT pg_get_serial_sequence($1, 'id')", table).Scan(&seq); err != nil {
+			return fmt.Errorf("dump: reset sequence of %s: %w", table, err)
+		}
+		if seq == nil {
+			continue
+		}
+		q := "SELECT setval($1, COALESCE(MAX(id), 1)) FROM " + db.Dialect().QuoteIdentifier(table)
+		if _, err := db.Exec(ctx, q, *seq); err != nil {
+			return fmt.Errorf("dump: reset sequence of %s: %w", table, err)
+		}
+	}
+	return nil
+}
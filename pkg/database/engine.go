@@ -3,10 +3,12 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"iter"
 	"log/slog"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -57,6 +59,10 @@ type DB struct {
 	auditor Auditor
 	pool    *sql.DB // Exposed for raw access and compatibility
 	inTx    bool
+
+	// afterCommit collects callbacks registered inside db.Transaction; they
+	// run once the outermost transaction commits.
+	afterCommit *[]func()
 }
 
 func New(conn Connection, dialect Dialect) *DB {
@@ -128,7 +134,7 @@ func Query[T any](db *DB, ctx ...context.Context) *QueryBuilder[T] {
 func Open(cfg Config) (*DB, error) {
 	dialect, driverName := ResolveDialect(cfg.Driver)
 
-	db, err := sql.Open(driverName, cfg.DSN)
+	db, err := sql.Open(driverName, NormalizeDSN(dialect.Name(), cfg.DSN))
 	if err != nil {
 		return nil, err
 	}
@@ -145,8 +151,12 @@ func Open(cfg Config) (*DB, error) {
 	if cfg.Lifetime > 0 {
 		db.SetConnMaxLifetime(cfg.Lifetime)
 	}
+	if cfg.MaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.MaxIdleTime)
+	}
 
-	if err := db.Ping(); err != nil {
+	if err := pingWithRetry(db, cfg); err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
@@ -172,6 +182,45 @@ func Open(cfg Config) (*DB, error) {
 	}, nil
 }
 
+// pingWithRetry verifies the connection, retrying transient failures with
+// exponential backoff so a database that is still starting up (or briefly
+// unreachable during a deploy) does not fail application boot.
+func pingWithRetry(db *sql.DB, cfg Config) error {
+	backoff := cfg.ConnectBackoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	const maxBackoff = 30 * time.Second
+
+	var err error
+	for attempt := 0; attempt <= cfg.ConnectRetries; attempt++ {
+		if err = db.Ping(); err == nil {
+			return nil
+		}
+		if attempt == cfg.ConnectRetries {
+			break
+		}
+		slog.Warn("orm: database not reachable, retrying",
+			"attempt", attempt+1,
+			"max_attempts", cfg.ConnectRetries+1,
+			"backoff", backoff,
+			"error", err,
+		)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+	return fmt.Errorf("orm: connect failed after %d attempt(s): %w", cfg.ConnectRetries+1, err)
+}
+
+// Stats returns connection pool statistics, or the zero value when the DB
+// is not backed by a *sql.DB pool.
+func (db *DB) Stats() sql.DBStats {
+	if pool := db.Pool(); pool != nil {
+		return pool.Stats()
+	}
+	return sql.DBStats{}
+}
+
 // Close closes the underlying database pool.
 func (db *DB) Close() error {
 	if db.pool != nil {
@@ -207,31 +256,10 @@ func (db *DB) WithTx(tx Transaction) *DB {
 // DropAllTables drops all tables in the current database.
 // It handles foreign key constraints across different dialects.
 func (db *DB) DropAllTables(ctx context.Context) error {
-	var query string
-	switch db.dialect.Name() {
-	case "postgres":
-		query = "SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname = 'public'"
-	case "mysql":
-		query = "SHOW TABLES"
-	case "sqlite":
-		query = "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
-	default:
-		return fmt.Errorf("orm: DropAllTables not supported for driver %s", db.dialect.Name())
-	}
-
-	rows, err := db.conn.Query(ctx, query)
+	tables, err := db.Tables(ctx)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-
-	var tables []string
-	for rows.Next() {
-		var table string
-		if err := rows.Scan(&table); err == nil {
-			tables = append(tables, table)
-		}
-	}
 
 	// Disable foreign key checks if possible
 	if db.dialect.Name() == "mysql" {
@@ -276,6 +304,93 @@ func (db *DB) DropAllTables(ctx context.Context) error {
 	return nil
 }
 
+// MigrationsTable is the bookkeeping table of the migration runner, which
+// TruncateAll always keeps.
+const MigrationsTable = "schema_migrations"
+
+// TruncateAll empties every table except MigrationsTable and the tables
+// named in except, leaving the schema in place. It is meant for test setup
+// and reseeding, where dropping and re-migrating would be slow.
+//
+// PostgreSQL truncates all tables in one statement with CASCADE and restarts
+// their sequences. MySQL disables foreign key checks on one pinned
+// connection while it truncates. Other drivers delete rows children first,
+// in foreign-key order, and SQLite's AUTOINCREMENT counters are reset.
+func TruncateAll(ctx context.Context, db *DB, except ...string) error {
+	all, err := db.Tables(ctx)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for _, t := range all {
+		if t != MigrationsTable && !slices.Contains(except, t) {
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(tables))
+	for i, t := range tables {
+		quoted[i] = db.dialect.QuoteIdentifier(t)
+	}
+
+	switch db.dialect.Name() {
+	case "postgres", "neon":
+		if _, err := db.Exec(ctx, "TRUNCATE TABLE "+strings.Join(quoted, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+			return fmt.Errorf("orm: truncate tables: %w", err)
+		}
+		return nil
+	case "mysql":
+		if pool := db.Pool(); pool != nil {
+			return truncateMySQL(ctx, pool, quoted)
+		}
+	}
+
+	sorted, err := db.SortTablesByDependency(ctx, tables)
+	if err != nil {
+		return err
+	}
+	for _, table := range slices.Backward(sorted) {
+		if _, err := db.Exec(ctx, "DELETE FROM "+db.dialect.QuoteIdentifier(table)); err != nil {
+			return fmt.Errorf("orm: truncate %s: %w", table, err)
+		}
+		if db.dialect.Name() == "sqlite" {
+			// sqlite_sequence only exists once a table uses AUTOINCREMENT.
+			_, _ = db.Exec(ctx, "DELETE FROM sqlite_sequence WHERE name = ?", table)
+		}
+	}
+	return nil
+}
+
+// truncateMySQL runs the TRUNCATE statements on a single connection, since
+// FOREIGN_KEY_CHECKS is a session variable. The checks are turned back on
+// even if ctx is canceled; if that fails, the connection is discarded rather
+// than returned to the pool with the checks off.
+func truncateMySQL(ctx context.Context, pool *sql.DB, quoted []string) error {
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+	for _, table := range quoted {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE "+table); err != nil {
+			return fmt.Errorf("orm: truncate %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
 func (l *loggingConn) Begin(ctx context.Context) (Transaction, error) { return l.inner.Begin(ctx) }
 func (l *loggingConn) Close() error                                   { return l.inner.Close() }
 
@@ -600,6 +715,14 @@ type Config struct {
 	MaxOpen            int
 	MaxIdle            int
 	Lifetime           time.Duration
+	// MaxIdleTime closes connections that have been idle for longer than this.
+	MaxIdleTime time.Duration
+	// ConnectRetries is the number of additional connection attempts Open makes
+	// when the initial ping fails. Zero fails immediately.
+	ConnectRetries int
+	// ConnectBackoff is the delay before the first retry; it doubles after each
+	// failed attempt up to 30s. Defaults to 500ms.
+	ConnectBackoff time.Duration
 	SlowQueryThreshold time.Duration
 	Auditor            Auditor
 	// Optional OpenTelemetry tracer. When set, all queries emit spans.
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrStaleModel is matched by every *StaleModelError via errors.Is.
+var ErrStaleModel = errors.New("orm: stale model")
+
+// StaleModelError is returned by Save when a versioned model was modified by
+// someone else after it was loaded. Callers typically reload the record and
+// either retry or report a conflict to the user.
+type StaleModelError struct {
+	Table   string
+	ID      any
+	Version int64
+}
+
+func (e *StaleModelError) Error() string {
+	return fmt.Sprintf("orm: %s %v was modified concurrently (expected version %d)", e.Table, e.ID, e.Version)
+}
+
+// Is reports whether target is ErrStaleModel.
+func (e *StaleModelError) Is(target error) bool {
+	return target == ErrStaleModel
+}
+
+// ErrNotFound is matched by every *NotFoundError via errors.Is.
+var ErrNotFound = errors.New("orm: record not found")
+
+// NotFoundError is returned by First, Last, FindByID and FindBy when no row
+// matches. It unwraps to sql.ErrNoRows, so existing errors.Is checks against
+// either sentinel keep working, and carries the model name for user-facing
+// messages such as "User not found".
+type NotFoundError struct {
+	Model string
+	Table string
+	ID    any
+}
+
+func (e *NotFoundError) Error() string {
+	if e.ID != nil {
+		return fmt.Sprintf("orm: %s %v not found", e.Model, e.ID)
+	}
+	return fmt.Sprintf("orm: %s not found", e.Model)
+}
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// NotFoundModel returns the model name. The HTTP error handler uses it to
+// answer with a 404 such as "User not found".
+func (e *NotFoundError) NotFoundModel() string {
+	return e.Model
+}
+
+// Unwrap returns sql.ErrNoRows.
+func (e *NotFoundError) Unwrap() error {
+	return sql.ErrNoRows
+}
+
+// InvalidEnumError is returned by Create and Save when a field whose type
+// was declared with enum.New holds a value outside the enum.
+type InvalidEnumError struct {
+	Table  string
+	Column string
+	Value  string
+	Values []string
+}
+
+func (e *InvalidEnumError) Error() string {
+	return fmt.Sprintf("orm: %s.%s cannot be %q; it must be one of %s", e.Table, e.Column, e.Value, strings.Join(e.Values, ", "))
+}
@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"maps"
 	"reflect"
+	"slices"
 
 	"github.com/brianvoe/gofakeit/v6"
 )
@@ -70,7 +72,10 @@ func (fb *FactoryBuilder[T]) Make() T {
 		}
 	}
 
-	for field, value := range attrs {
+	// Fill fields in name order so generator functions draw from a seeded
+	// random source in the same order on every run.
+	for _, field := range slices.Sorted(maps.Keys(attrs)) {
+		value := attrs[field]
 		f := val.FieldByName(field)
 		if !f.IsValid() || !f.CanSet() {
 			continue
@@ -103,7 +108,11 @@ func (fb *FactoryBuilder[T]) Create(ctx context.Context, db ...*DB) (*T, error)
 	}
 
 	model := fb.Make()
-	return Query[T](database).Create(&model, ctx)
+	created, err := Query[T](database).Create(&model, ctx)
+	if err == nil {
+		CountSeeded(ctx, GetMeta(reflect.TypeFor[T]()).TableName, 1)
+	}
+	return created, err
 }
 
 // CreateMany creates and persists multiple instances
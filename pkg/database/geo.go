@@ -0,0 +1,356 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Geometry is a spatial value stored in a Point or Polygon column. It
+// encodes as a GeoJSON geometry, both in JSON responses and on its way to
+// the database.
+type Geometry interface {
+	driver.Valuer
+	json.Marshaler
+	geoJSON() geoJSON
+}
+
+// Point is a position in WGS 84 coordinates (SRID 4326), as used by GPS
+// and web maps. Use *Point for a nullable column.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Polygon is an area given by its rings: the first ring is the boundary
+// and any others are holes. Each ring is closed, ending on its first point.
+type Polygon [][]Point
+
+// geoJSON is a GeoJSON geometry object.
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+func (p Point) coordinates() [2]float64 { return [2]float64{p.Lng, p.Lat} }
+
+func (p Point) geoJSON() geoJSON {
+	raw, _ := json.Marshal(p.coordinates())
+	return geoJSON{Type: "Point", Coordinates: raw}
+}
+
+func (p Polygon) geoJSON() geoJSON {
+	rings := make([][][2]float64, len(p))
+	for i, ring := range p {
+		rings[i] = make([][2]float64, len(ring))
+		for j, pt := range ring {
+			rings[i][j] = pt.coordinates()
+		}
+	}
+	raw, _ := json.Marshal(rings)
+	return geoJSON{Type: "Polygon", Coordinates: raw}
+}
+
+// MarshalJSON encodes p as a GeoJSON Point, with the longitude first.
+func (p Point) MarshalJSON() ([]byte, error) { return json.Marshal(p.geoJSON()) }
+
+// MarshalJSON encodes p as a GeoJSON Polygon.
+func (p Polygon) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(p.geoJSON())
+}
+
+// UnmarshalJSON decodes a GeoJSON Point.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var c [2]float64
+	if err := decodeGeoJSON(data, "Point", &c); err != nil {
+		return err
+	}
+	*p = Point{Lat: c[1], Lng: c[0]}
+	return nil
+}
+
+// UnmarshalJSON decodes a GeoJSON Polygon.
+func (p *Polygon) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*p = nil
+		return nil
+	}
+	var rings [][][2]float64
+	if err := decodeGeoJSON(data, "Polygon", &rings); err != nil {
+		return err
+	}
+	poly := make(Polygon, len(rings))
+	for i, ring := range rings {
+		poly[i] = make([]Point, len(ring))
+		for j, c := range ring {
+			poly[i][j] = Point{Lat: c[1], Lng: c[0]}
+		}
+	}
+	*p = poly
+	return nil
+}
+
+func decodeGeoJSON(data []byte, want string, coordinates any) error {
+	var g geoJSON
+	if err := json.Unmarshal(data, &g); err != nil {
+		return fmt.Errorf("database: invalid GeoJSON: %w", err)
+	}
+	if g.Type != want {
+		return fmt.Errorf("database: GeoJSON %q is not a %s", g.Type, want)
+	}
+	if err := json.Unmarshal(g.Coordinates, coordinates); err != nil {
+		return fmt.Errorf("database: invalid %s coordinates: %w", want, err)
+	}
+	return nil
+}
+
+// Value stores p as GeoJSON text. The query builder wraps the placeholder
+// in ST_GeomFromGeoJSON on PostgreSQL and MySQL.
+func (p Point) Value() (driver.Value, error) {
+	b, err := p.MarshalJSON()
+	return string(b), err
+}
+
+// Value stores p as GeoJSON text; see Point.Value.
+func (p Polygon) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	b, err := p.MarshalJSON()
+	return string(b), err
+}
+
+// Scan reads a point as PostGIS returns it (hex EWKB), as MySQL returns it
+// (SRID and WKB) or as GeoJSON text.
+func (p *Point) Scan(src any) error {
+	if src == nil {
+		*p = Point{}
+		return nil
+	}
+	return scanGeometry(src, "Point", p, func(r *wkbReader) {
+		*p = r.point()
+	})
+}
+
+// Scan reads a polygon; see Point.Scan.
+func (p *Polygon) Scan(src any) error {
+	if src == nil {
+		*p = nil
+		return nil
+	}
+	return scanGeometry(src, "Polygon", p, func(r *wkbReader) {
+		rings := make(Polygon, r.count(4))
+		for i := range rings {
+			rings[i] = make([]Point, r.count(8*r.dims))
+			for j := range rings[i] {
+				rings[i][j] = r.point()
+			}
+		}
+		*p = rings
+	})
+}
+
+func scanGeometry(src any, want string, dest json.Unmarshaler, read func(*wkbReader)) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("database: cannot scan %T into a %s", src, want)
+	}
+
+	if trimmed := strings.TrimSpace(string(b)); strings.HasPrefix(trimmed, "{") {
+		return dest.UnmarshalJSON([]byte(trimmed))
+	}
+	if decoded, err := hex.DecodeString(string(b)); err == nil {
+		b = decoded // PostGIS text output
+	} else if len(b) >= 4 {
+		b = b[4:] // MySQL's SRID prefix
+	}
+
+	r := &wkbReader{b: b}
+	if typ := r.header(); r.err == nil && typ != want {
+		return fmt.Errorf("database: cannot scan a %s into a %s", typ, want)
+	}
+	read(r)
+	return r.err
+}
+
+// wkbReader decodes (E)WKB, the binary geometry format of PostGIS and
+// MySQL.
+type wkbReader struct {
+	b     []byte
+	order binary.ByteOrder
+	dims  int
+	err   error
+}
+
+var errShortWKB = errors.New("database: truncated WKB geometry")
+
+// header reads the byte order and geometry type, skipping an EWKB SRID.
+func (r *wkbReader) header() string {
+	if len(r.b) < 5 {
+		r.err = errShortWKB
+		return ""
+	}
+	r.order = binary.ByteOrder(binary.LittleEndian)
+	if r.b[0] == 0 {
+		r.order = binary.BigEndian
+	}
+	r.b = r.b[1:]
+	typ := r.uint32()
+
+	const ewkbZ, ewkbM, ewkbSRID = 0x80000000, 0x40000000, 0x20000000
+	r.dims = 2
+	if typ&ewkbZ != 0 {
+		r.dims++
+	}
+	if typ&ewkbM != 0 {
+		r.dims++
+	}
+	if typ&ewkbSRID != 0 {
+		r.uint32()
+	}
+	// ISO WKB adds 1000 for Z, 2000 for M and 3000 for both.
+	base := typ &^ (ewkbZ | ewkbM | ewkbSRID)
+	switch base / 1000 {
+	case 1, 2:
+		r.dims++
+	case 3:
+		r.dims += 2
+	}
+	switch base % 1000 {
+	case 1:
+		return "Point"
+	case 3:
+		return "Polygon"
+	default:
+		return fmt.Sprintf("WKB type %d", base%1000)
+	}
+}
+
+func (r *wkbReader) uint32() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.b) < 4 {
+		r.err = errShortWKB
+		return 0
+	}
+	v := r.order.Uint32(r.b)
+	r.b = r.b[4:]
+	return v
+}
+
+// count reads the length of a list whose items take at least size bytes,
+// so a corrupt length cannot allocate more than the input holds.
+func (r *wkbReader) count(size int) int {
+	n := int(r.uint32())
+	if r.err == nil && n > len(r.b)/size {
+		r.err = errShortWKB
+	}
+	if r.err != nil {
+		return 0
+	}
+	return n
+}
+
+// point reads x (longitude) and y (latitude), ignoring Z and M.
+func (r *wkbReader) point() Point {
+	if r.err != nil {
+		return Point{}
+	}
+	if len(r.b) < 8*r.dims {
+		r.err = errShortWKB
+		return Point{}
+	}
+	x := math.Float64frombits(r.order.Uint64(r.b))
+	y := math.Float64frombits(r.order.Uint64(r.b[8:]))
+	r.b = r.b[8*r.dims:]
+	return Point{Lat: y, Lng: x}
+}
+
+// geoPlaceholder returns the placeholder for argument n. Geometry values
+// travel as GeoJSON text, which PostGIS and MySQL parse with
+// ST_GeomFromGeoJSON; SQLite stores the text itself.
+func geoPlaceholder(d Dialect, n int, value any) string {
+	ph := d.Placeholder(n)
+	if _, ok := value.(Geometry); ok && d.Name() != "sqlite" {
+		return "ST_GeomFromGeoJSON(" + ph + ")"
+	}
+	return ph
+}
+
+// earthRadius is the mean radius of the Earth in meters, used for
+// distances on SQLite.
+const earthRadius = 6371008.8
+
+// distanceWithin is the value of a WhereDistanceWithin clause.
+type distanceWithin struct {
+	point  Point
+	meters float64
+}
+
+// distanceWithinSQL returns the condition that column lies within meters
+// of center, with placeholders numbered from offset+1. PostGIS and MySQL
+// measure on a sphere, and SQLite uses the haversine formula on the
+// GeoJSON text.
+func distanceWithinSQL(d Dialect, column string, w distanceWithin, offset int) (string, []any) {
+	col := d.QuoteIdentifier(column)
+	center, _ := w.point.Value()
+	switch d.Name() {
+	case "mysql":
+		return fmt.Sprintf("ST_Distance_Sphere(%s, ST_GeomFromGeoJSON(%s)) <= %s",
+			col, d.Placeholder(offset+1), d.Placeholder(offset+2)), []any{center, w.meters}
+	case "sqlite":
+		lat := fmt.Sprintf("json_extract(%s, '$.coordinates[1]')", col)
+		lng := fmt.Sprintf("json_extract(%s, '$.coordinates[0]')", col)
+		ph := func(i int) string { return d.Placeholder(offset + i) }
+		return fmt.Sprintf("%g * 2 * asin(sqrt(power(sin(radians(%s - %s) / 2), 2) + cos(radians(%s)) * cos(radians(%s)) * power(sin(radians(%s - %s) / 2), 2))) <= %s",
+				earthRadius, lat, ph(1), lat, ph(2), lng, ph(3), ph(4)),
+			[]any{w.point.Lat, w.point.Lat, w.point.Lng, w.meters}
+	default:
+		return fmt.Sprintf("ST_DWithin(%s::geography, ST_GeomFromGeoJSON(%s)::geography, %s)",
+			col, d.Placeholder(offset+1), d.Placeholder(offset+2)), []any{center, w.meters}
+	}
+}
+
+// Feature is a GeoJSON Feature: a geometry with properties, such as a
+// model's fields.
+type Feature struct {
+	Type       string   `json:"type"`
+	ID         any      `json:"id,omitempty"`
+	Geometry   Geometry `json:"geometry"`
+	Properties any      `json:"properties"`
+}
+
+// NewFeature returns a Feature for geometry. id may be nil.
+func NewFeature(id any, geometry Geometry, properties any) Feature {
+	return Feature{Type: "Feature", ID: id, Geometry: geometry, Properties: properties}
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection, ready to be returned
+// from a handler and drawn by a map library.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewFeatureCollection returns a collection of features. It encodes an
+// empty collection as an empty array, not null.
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/database/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type place struct {
+	ID       uint   `orm:"primaryKey;autoIncrement"`
+	Name     string `orm:"column:name"`
+	Location Point  `orm:"column:location"`
+	Area     *Polygon
+}
+
+func (place) TableName() string { return "places" }
+
+func TestGeoQueries(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+	db.Pool().SetMaxOpenConns(1)
+
+	builder := &schema.Builder{Dialect: db.Dialect(), Exec: db}
+	require.NoError(t, builder.CreateTable("places", func(t *schema.Table) {
+		t.ID()
+		t.String("name", 100)
+		t.Point("location")
+		t.Polygon("area").Nullable()
+		t.SpatialIndex("location")
+	}))
+
+	park := Polygon{{{Lat: 48.85, Lng: 2.29}, {Lat: 48.86, Lng: 2.29}, {Lat: 48.86, Lng: 2.30}, {Lat: 48.85, Lng: 2.29}}}
+	for _, p := range []place{
+		{Name: "Eiffel Tower", Location: Point{Lat: 48.8584, Lng: 2.2945}, Area: &park},
+		{Name: "Louvre", Location: Point{Lat: 48.8606, Lng: 2.3376}},
+		{Name: "Big Ben", Location: Point{Lat: 51.5007, Lng: -0.1246}},
+	} {
+		_, err := Query[place](db).Create(&p, ctx)
+		require.NoError(t, err)
+	}
+
+	// The Louvre is about 3.2 km from the Eiffel Tower.
+	near, err := Query[place](db).WhereDistanceWithin("location", 48.8584, 2.2945, 5000).OrderBy("id", "ASC").Get(ctx)
+	require.NoError(t, err)
+	require.Len(t, near, 2)
+	assert.Equal(t, "Louvre", near[1].Name)
+	assert.Equal(t, Point{Lat: 48.8606, Lng: 2.3376}, near[1].Location)
+	require.NotNil(t, near[0].Area)
+	assert.Equal(t, park, *near[0].Area)
+	assert.Nil(t, near[1].Area)
+
+	n, err := Query[place](db).WhereDistanceWithin("location", 48.8584, 2.2945, 1000).Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestGeoSQL(t *testing.T) {
+	pg := New(nil, PostgresDialect{})
+	sql, args := Query[place](pg).Where("name", "=", "x").WhereDistanceWithin("location", 1, 2, 300).ToSQL()
+	assert.Equal(t, `SELECT * FROM "places" WHERE "name" = $1 AND ST_DWithin("location"::geography, ST_GeomFromGeoJSON($2)::geography, $3)`, sql)
+	assert.Equal(t, []any{"x", `{"type":"Point","coordinates":[2,1]}`, float64(300)}, args)
+
+	insert, _ := Query[place](New(nil, MySQLDialect{})).toInsertSQL([]string{"name", "location"}, []any{"x", Point{}})
+	assert.Equal(t, "INSERT INTO `places` (`name`, `location`) VALUES (?, ST_GeomFromGeoJSON(?))", insert)
+
+	b := &schema.Builder{Dialect: PostgresDialect{}}
+	stmts := b.CreateTableSQL(&schema.Table{
+		Name:    "places",
+		Columns: []*schema.Column{{Name: "location", Type: "POINT", Spatial: "Point"}},
+		Indices: []schema.Index{{Columns: []string{"location"}, Spatial: true}},
+	})
+	assert.Equal(t, []string{
+		`CREATE TABLE "places" ("location" geography(Point, 4326) NOT NULL)`,
+		`CREATE INDEX "idx_places_location" ON "places" USING GIST ("location")`,
+	}, stmts)
+	b.Dialect = MySQLDialect{}
+	assert.Equal(t, "CREATE SPATIAL INDEX `idx_places_location` ON `places` (`location`)",
+		b.CreateTableSQL(&schema.Table{Name: "places", Indices: []schema.Index{{Columns: []string{"location"}, Spatial: true}}})[1])
+}
+
+func TestGeoScan(t *testing.T) {
+	// POINT(2.2945 48.8584) with SRID 4326, little-endian.
+	wkb := binary.LittleEndian.AppendUint32([]byte{1}, 0x20000001)
+	wkb = binary.LittleEndian.AppendUint32(wkb, 4326)
+	wkb = binary.LittleEndian.AppendUint64(wkb, math.Float64bits(2.2945))
+	wkb = binary.LittleEndian.AppendUint64(wkb, math.Float64bits(48.8584))
+	want := Point{Lat: 48.8584, Lng: 2.2945}
+
+	var p Point
+	require.NoError(t, p.Scan(hex.EncodeToString(wkb)))
+	assert.Equal(t, want, p)
+
+	// MySQL: the SRID, then plain WKB.
+	mysql := binary.LittleEndian.AppendUint32(nil, 4326)
+	mysql = append(mysql, 1)
+	mysql = binary.LittleEndian.AppendUint32(mysql, 1)
+	mysql = append(mysql, wkb[9:]...)
+	p = Point{}
+	require.NoError(t, p.Scan(mysql))
+	assert.Equal(t, want, p)
+
+	var poly Polygon
+	assert.ErrorContains(t, poly.Scan(hex.EncodeToString(wkb)), "cannot scan a Point into a Polygon")
+	assert.ErrorIs(t, p.Scan(hex.EncodeToString(wkb[:12])), errShortWKB)
+}
+
+func TestGeoJSON(t *testing.T) {
+	out, err := json.Marshal(NewFeatureCollection(NewFeature(7, Point{Lat: 1.5, Lng: 2}, map[string]string{"name": "x"})))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[{"type":"Feature","id":7,"geometry":{"type":"Point","coordinates":[2,1.5]},"properties":{"name":"x"}}]}`, string(out))
+
+	out, err = json.Marshal(NewFeatureCollection())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[]}`, string(out))
+
+	var p Point
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"Point","coordinates":[-0.12,51.5]}`), &p))
+	assert.Equal(t, Point{Lat: 51.5, Lng: -0.12}, p)
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"Polygon","coordinates":[]}`), &p))
+}
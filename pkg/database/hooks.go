@@ -33,9 +33,11 @@ type AfterFindHook interface {
 
 func callBeforeCreate[T any](ctx context.Context, db *DB, model *T) error {
 	if h, ok := any(model).(BeforeCreateHook); ok {
-		return h.BeforeCreate(ctx, db)
+		if err := h.BeforeCreate(ctx, db); err != nil {
+			return err
+		}
 	}
-	return nil
+	return fireModelEvent(ctx, ModelCreating, model)
 }
 
 func callAfterCreate[T any](ctx context.Context, db *DB, model *T) error {
@@ -44,17 +46,21 @@ func callAfterCreate[T any](ctx context.Context, db *DB, model *T) error {
 		_ = a.AfterCreate(ctx, db, model)
 	}
 
+	var err error
 	if h, ok := any(model).(AfterCreateHook); ok {
-		return h.AfterCreate(ctx, db)
+		err = h.AfterCreate(ctx, db)
 	}
-	return nil
+	fireModelEventAfterCommit(ctx, db, ModelCreated, model)
+	return err
 }
 
 func callBeforeUpdate[T any](ctx context.Context, db *DB, model *T) error {
 	if h, ok := any(model).(BeforeUpdateHook); ok {
-		return h.BeforeUpdate(ctx, db)
+		if err := h.BeforeUpdate(ctx, db); err != nil {
+			return err
+		}
 	}
-	return nil
+	return fireModelEvent(ctx, ModelUpdating, model)
 }
 
 func callAfterUpdate[T any](ctx context.Context, db *DB, model *T) error {
@@ -63,10 +69,30 @@ func callAfterUpdate[T any](ctx context.Context, db *DB, model *T) error {
 		_ = a.AfterUpdate(ctx, db, model)
 	}
 
+	var err error
 	if h, ok := any(model).(AfterUpdateHook); ok {
-		return h.AfterUpdate(ctx, db)
+		err = h.AfterUpdate(ctx, db)
 	}
-	return nil
+	fireModelEventAfterCommit(ctx, db, ModelUpdated, model)
+	return err
+}
+
+func callBeforeDelete[T any](ctx context.Context, db *DB, model *T) error {
+	if h, ok := any(model).(BeforeDeleteHook); ok {
+		if err := h.BeforeDelete(ctx, db); err != nil {
+			return err
+		}
+	}
+	return fireModelEvent(ctx, ModelDeleting, model)
+}
+
+func callAfterDelete[T any](ctx context.Context, db *DB, model *T) error {
+	var err error
+	if h, ok := any(model).(AfterDeleteHook); ok {
+		err = h.AfterDelete(ctx, db)
+	}
+	fireModelEventAfterCommit(ctx, db, ModelDeleted, model)
+	return err
 }
 
 func callAfterFind[T any](ctx context.Context, db *DB, model *T) error {
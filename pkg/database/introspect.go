@@ -0,0 +1,238 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ForeignKeyRef describes one foreign key column of a table.
+type ForeignKeyRef struct {
+	Column          string
+	ReferencedTable string
+	ReferencedCol   string
+}
+
+// Tables lists the user tables of the connected database, sorted by name.
+func (db *DB) Tables(ctx context.Context) ([]string, error) {
+	var query string
+	switch db.dialect.Name() {
+	case "postgres", "neon":
+		query = "SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname = 'public'"
+	case "mysql":
+		query = "SHOW TABLES"
+	case "sqlite":
+		query = "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
+	default:
+		return nil, fmt.Errorf("orm: table listing not supported for driver %s", db.dialect.Name())
+	}
+
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+	return tables, rows.Err()
+}
+
+// ForeignKeys returns the foreign keys declared on table.
+func (db *DB) ForeignKeys(ctx context.Context, table string) ([]ForeignKeyRef, error) {
+	var (
+		query string
+		args  []any
+	)
+	switch db.dialect.Name() {
+	case "postgres", "neon":
+		query = `SELECT kcu.column_name, ccu.table_name, ccu.column_name
+			FROM information_schema.table_constraints tc
+			JOIN information_schema.key_column_usage kcu
+			  ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+			JOIN information_schema.constraint_column_usage ccu
+			  ON tc.constraint_name = ccu.constraint_name AND tc.table_schema = ccu.table_schema
+			WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = 'public' AND tc.table_name = $1`
+		args = []any{table}
+	case "mysql":
+		query = `SELECT COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+			FROM information_schema.KEY_COLUMN_USAGE
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL`
+		args = []any{table}
+	case "sqlite":
+		query = `SELECT "from", "table", "to" FROM pragma_foreign_key_list(?)`
+		args = []any{table}
+	default:
+		return nil, fmt.Errorf("orm: foreign key introspection not supported for driver %s", db.dialect.Name())
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fks []ForeignKeyRef
+	for rows.Next() {
+		var fk ForeignKeyRef
+		if err := rows.Scan(&fk.Column, &fk.ReferencedTable, &fk.ReferencedCol); err != nil {
+			return nil, err
+		}
+		fks = append(fks, fk)
+	}
+	return fks, rows.Err()
+}
+
+// IndexInfo describes one index of a table, including those created for
+// primary keys and unique constraints.
+type IndexInfo struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// Columns lists the column names of table in declaration order. A table
+// that does not exist has no columns.
+func (db *DB) Columns(ctx context.Context, table string) ([]string, error) {
+	var query string
+	switch db.dialect.Name() {
+	case "postgres", "neon":
+		query = `SELECT column_name FROM information_schema.columns
+			WHERE table_schema = 'public' AND table_name = $1 ORDER BY ordinal_position`
+	case "mysql":
+		query = `SELECT COLUMN_NAME FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`
+	case "sqlite":
+		query = `SELECT name FROM pragma_table_info(?) ORDER BY cid`
+	default:
+		return nil, fmt.Errorf("orm: column introspection not supported for driver %s", db.dialect.Name())
+	}
+
+	rows, err := db.Query(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// Indexes returns the indexes of table with their columns in index order.
+func (db *DB) Indexes(ctx context.Context, table string) ([]IndexInfo, error) {
+	var query string
+	switch db.dialect.Name() {
+	case "postgres", "neon":
+		query = `SELECT i.relname, ix.indisunique, a.attname
+			FROM pg_class t
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			JOIN pg_index ix ON ix.indrelid = t.oid
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)
+			WHERE n.nspname = 'public' AND t.relname = $1
+			ORDER BY i.relname, array_position(ix.indkey::int2[], a.attnum)`
+	case "mysql":
+		query = `SELECT INDEX_NAME, NON_UNIQUE = 0, COLUMN_NAME
+			FROM information_schema.STATISTICS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+			ORDER BY INDEX_NAME, SEQ_IN_INDEX`
+	case "sqlite":
+		query = `SELECT il.name, il."unique", ii.name
+			FROM pragma_index_list(?) il, pragma_index_info(il.name) ii
+			ORDER BY il.name, ii.seqno`
+	default:
+		return nil, fmt.Errorf("orm: index introspection not supported for driver %s", db.dialect.Name())
+	}
+
+	rows, err := db.Query(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		var (
+			name, column string
+			unique       bool
+		)
+		if err := rows.Scan(&name, &unique, &column); err != nil {
+			return nil, err
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+			continue
+		}
+		indexes = append(indexes, IndexInfo{Name: name, Columns: []string{column}, Unique: unique})
+	}
+	return indexes, rows.Err()
+}
+
+// SortTablesByDependency orders tables so that every table comes after the
+// tables it references. Inserting in the returned order, or deleting in the
+// reverse order, never violates a foreign key. Tables caught in a reference
+// cycle (including self-references) are appended in name order.
+func (db *DB) SortTablesByDependency(ctx context.Context, tables []string) ([]string, error) {
+	deps := make(map[string][]string, len(tables))
+	for _, t := range tables {
+		fks, err := db.ForeignKeys(ctx, t)
+		if err != nil {
+			return nil, fmt.Errorf("orm: inspect foreign keys of %s: %w", t, err)
+		}
+		for _, fk := range fks {
+			if fk.ReferencedTable != t && slices.Contains(tables, fk.ReferencedTable) {
+				deps[t] = append(deps[t], fk.ReferencedTable)
+			}
+		}
+	}
+	return sortByDependency(tables, deps), nil
+}
+
+// sortByDependency is a deterministic Kahn topological sort.
+func sortByDependency(tables []string, deps map[string][]string) []string {
+	pending := slices.Clone(tables)
+	slices.Sort(pending)
+	done := make(map[string]bool, len(tables))
+	ordered := make([]string, 0, len(tables))
+
+	for len(pending) > 0 {
+		progressed := false
+		next := pending[:0]
+		for _, t := range pending {
+			ready := true
+			for _, d := range deps[t] {
+				if !done[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, t)
+				done[t] = true
+				progressed = true
+			} else {
+				next = append(next, t)
+			}
+		}
+		pending = next
+		if !progressed {
+			ordered = append(ordered, pending...)
+			break
+		}
+	}
+	return ordered
+}
@@ -4,11 +4,43 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
 // Generate creates a new migration file in the specified directory.
 func Generate(dir, name string) (string, error) {
+	content := `-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+`
+	return write(dir, name, content)
+}
+
+// GenerateSQL creates a migration file holding the given statements, such
+// as a schema diff, for review before it is run. notes are written as
+// comments at the top of the Up section.
+func GenerateSQL(dir, name string, up, down, notes []string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("-- +migrate Up\n")
+	for _, n := range notes {
+		sb.WriteString("-- NOTE: " + n + "\n")
+	}
+	writeStatements(&sb, up)
+	sb.WriteString("\n-- +migrate Down\n")
+	writeStatements(&sb, down)
+	return write(dir, name, sb.String())
+}
+
+func writeStatements(sb *strings.Builder, stmts []string) {
+	for _, s := range stmts {
+		sb.WriteString(strings.TrimSuffix(s, ";") + ";\n")
+	}
+}
+
+func write(dir, name, content string) (string, error) {
 	if err := os.MkdirAll(dir, 0750); err != nil {
 		return "", fmt.Errorf("failed to create migrations directory: %w", err)
 	}
@@ -17,13 +49,6 @@ func Generate(dir, name string) (string, error) {
 	filename := fmt.Sprintf("%s_%s.sql", timestamp, name)
 	path := filepath.Join(dir, filename)
 
-	content := `-- +migrate Up
--- SQL in section 'Up' is executed when this migration is applied
-	
--- +migrate Down
--- SQL section 'Down' is executed when this migration is rolled back
-`
-
 	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
 		return "", fmt.Errorf("failed to create migration file: %w", err)
 	}
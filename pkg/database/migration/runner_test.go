@@ -1,6 +1,8 @@
 package migration
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,3 +43,19 @@ func TestQuoteIdents(t *testing.T) {
 	quoted := quoteIdents(names)
 	assert.Equal(t, []string{`"users"`, `"post ""tags"""`}, quoted)
 }
+
+func TestGenerateSQL(t *testing.T) {
+	dir := t.TempDir()
+	path, err := GenerateSQL(dir, "sync_models",
+		[]string{"ALTER TABLE users ADD COLUMN age BIGINT", "CREATE INDEX idx_users_age ON users (age);"},
+		[]string{"DROP INDEX idx_users_age", "ALTER TABLE users DROP COLUMN age"},
+		[]string{"users.legacy is not mapped"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(path, "_sync_models.sql"))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	up, down := parseMigration(string(content))
+	assert.Equal(t, "-- NOTE: users.legacy is not mapped\nALTER TABLE users ADD COLUMN age BIGINT;\nCREATE INDEX idx_users_age ON users (age);", up)
+	assert.Equal(t, "DROP INDEX idx_users_age;\nALTER TABLE users DROP COLUMN age;", down)
+}
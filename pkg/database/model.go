@@ -10,6 +10,14 @@ type Model struct {
 	DeletedAt *time.Time `orm:"soft_delete" json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// Versioned adds an optimistic-locking counter to a model. Embed it next to
+// Model; Save then only succeeds when the stored version still matches and
+// returns a *StaleModelError otherwise. Any integer field tagged `orm:"version"`
+// behaves the same way.
+type Versioned struct {
+	Version int64 `orm:"version" json:"version" db:"version"`
+}
+
 // Relation is the base for all relationship wrappers.
 type Relation[T any] struct {
 	loaded bool
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+)
+
+// Model lifecycle actions, dispatched as "model.<table>.<action>" events.
+// The "-ing" actions run before the statement and abort it when a
+// listener fails; the others run after it, once the transaction it ran in
+// commits, and their errors are ignored, like the After* hooks.
+const (
+	ModelCreating = "creating"
+	ModelCreated  = "created"
+	ModelUpdating = "updating"
+	ModelUpdated  = "updated"
+	ModelDeleting = "deleting"
+	ModelDeleted  = "deleted"
+)
+
+// observerMethods maps the observer method names to their action.
+var observerMethods = map[string]string{
+	"Creating": ModelCreating,
+	"Created":  ModelCreated,
+	"Updating": ModelUpdating,
+	"Updated":  ModelUpdated,
+	"Deleting": ModelDeleting,
+	"Deleted":  ModelDeleted,
+}
+
+// ModelEvent is dispatched when a model is created, updated or deleted.
+// Model is a pointer to the model.
+type ModelEvent struct {
+	Action string
+	Table  string
+	Model  any
+}
+
+func (e *ModelEvent) Name() string { return ModelEventName(e.Table, e.Action) }
+func (e *ModelEvent) Data() any    { return e }
+
+// ModelEventName returns the name of the event dispatched for action on
+// the models stored in table, e.g. "model.users.created".
+func ModelEventName(table, action string) string {
+	return "model." + table + "." + action
+}
+
+var (
+	observersMu sync.Mutex
+	// modelEvents dispatches the model events; see SetEvents.
+	modelEvents = event.New()
+	// observerListeners holds the listeners added by Observe, so SetEvents
+	// can move them to a new emitter.
+	observerListeners []observerListener
+)
+
+type observerListener struct {
+	name     string
+	listener event.Listener
+}
+
+// SetEvents makes e dispatch the model events and moves the observers
+// registered so far to it. The DatabaseProvider calls it with the
+// application's emitter, so any listener registered there with
+// e.On("model.users.created", ...) sees them too.
+func SetEvents(e *event.Emitter) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+
+	for _, ol := range observerListeners {
+		modelEvents.Off(ol.name, ol.listener)
+		e.On(ol.name, ol.listener)
+	}
+	modelEvents = e
+}
+
+// Events returns the emitter the model events are dispatched through.
+func Events() *event.Emitter {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	return modelEvents
+}
+
+// Observe registers the lifecycle methods of observer for the model T, so
+// side effects live outside the model struct:
+//
+//	type UserObserver struct{ Mailer mail.Mailer }
+//
+//	func (o *UserObserver) Created(ctx context.Context, u *User) error {
+//	    return o.Mailer.Send(ctx, welcome(u))
+//	}
+//
+//	database.Observe[User](&UserObserver{Mailer: mailer})
+//
+// Methods named Creating, Created, Updating, Updated, Deleting or Deleted
+// taking (context.Context, *T) and returning nothing or an error are
+// discovered by reflection and registered as listeners of the matching
+// model events. Observe panics when a method with one of those names has
+// another signature, or when observer has none of them.
+func Observe[T any](observer any) {
+	meta := GetMeta(reflect.TypeFor[T]())
+	modelPtr := reflect.PointerTo(meta.Type)
+	ctxType := reflect.TypeFor[context.Context]()
+	errType := reflect.TypeFor[error]()
+
+	v := reflect.ValueOf(observer)
+	var listeners []observerListener
+	for i := range v.NumMethod() {
+		method := v.Type().Method(i)
+		action, ok := observerMethods[method.Name]
+		if !ok {
+			continue
+		}
+		fn := v.Method(i)
+		ft := fn.Type()
+		valid := ft.NumIn() == 2 && ft.In(0) == ctxType && ft.In(1) == modelPtr &&
+			(ft.NumOut() == 0 || ft.NumOut() == 1 && ft.Out(0) == errType)
+		if !valid {
+			panic(fmt.Sprintf("orm: observer %T: %s must be func(context.Context, *%s) error", observer, method.Name, meta.Type.Name()))
+		}
+		listeners = append(listeners, observerListener{
+			name: ModelEventName(meta.TableName, action),
+			listener: event.ListenerFunc(func(ctx context.Context, e event.Event) error {
+				me, ok := e.Data().(*ModelEvent)
+				if !ok || reflect.TypeOf(me.Model) != modelPtr {
+					return nil
+				}
+				out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(me.Model)})
+				if len(out) == 1 && !out[0].IsNil() {
+					return out[0].Interface().(error)
+				}
+				return nil
+			}),
+		})
+	}
+	if len(listeners) == 0 {
+		panic(fmt.Sprintf("orm: observer %T has no Creating, Created, Updating, Updated, Deleting or Deleted method", observer))
+	}
+
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	for _, ol := range listeners {
+		modelEvents.On(ol.name, ol.listener)
+	}
+	observerListeners = append(observerListeners, listeners...)
+}
+
+// fireModelEvent dispatches action for model, a pointer to a model, when
+// anything listens to it.
+func fireModelEvent(ctx context.Context, action string, model any) error {
+	meta := GetMeta(reflect.TypeOf(model))
+	name := ModelEventName(meta.TableName, action)
+	em := Events()
+	if !em.HasListeners(name) {
+		return nil
+	}
+	return em.Dispatch(ctx, &ModelEvent{Action: action, Table: meta.TableName, Model: model})
+}
+
+// fireModelEventAfterCommit dispatches action for model once the
+// transaction db belongs to commits, so a rolled-back insert sends no
+// welcome mail.
+func fireModelEventAfterCommit(ctx context.Context, db *DB, action string, model any) {
+	if db == nil || !db.inTx {
+		_ = fireModelEvent(ctx, action, model)
+		return
+	}
+	db.AfterCommit(func() { _ = fireModelEvent(withoutTx(ctx), action, model) })
+}
+
+// observesDeletes reports whether deleting a T must load the model first,
+// because its hooks or observers need it.
+func observesDeletes[T any]() bool {
+	var zero T
+	if _, ok := any(&zero).(BeforeDeleteHook); ok {
+		return true
+	}
+	if _, ok := any(&zero).(AfterDeleteHook); ok {
+		return true
+	}
+	meta := GetMeta(reflect.TypeFor[T]())
+	em := Events()
+	return em.HasListeners(ModelEventName(meta.TableName, ModelDeleting)) ||
+		em.HasListeners(ModelEventName(meta.TableName, ModelDeleted))
+}
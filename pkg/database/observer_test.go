@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observedPost struct {
+	Model
+	Title string `orm:"column:title"`
+}
+
+func (p *observedPost) TableName() string { return "observed_posts" }
+
+type postObserver struct {
+	events []string
+}
+
+func (o *postObserver) Creating(_ context.Context, p *observedPost) error {
+	if p.Title == "" {
+		return errors.New("title is required")
+	}
+	o.events = append(o.events, "creating "+p.Title)
+	return nil
+}
+
+func (o *postObserver) Created(_ context.Context, p *observedPost) {
+	o.events = append(o.events, "created "+p.Title)
+}
+
+func (o *postObserver) Updated(_ context.Context, p *observedPost) error {
+	o.events = append(o.events, "updated "+p.Title)
+	return nil
+}
+
+func (o *postObserver) Deleted(_ context.Context, p *observedPost) error {
+	o.events = append(o.events, "deleted "+p.Title)
+	return nil
+}
+
+func TestObserve(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(ctx, "CREATE TABLE observed_posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	require.NoError(t, err)
+
+	obs := &postObserver{}
+	Observe[observedPost](obs)
+
+	// Observers follow the model events to the application's emitter.
+	app := event.New()
+	SetEvents(app)
+	t.Cleanup(func() { SetEvents(event.New()) })
+	var seen []string
+	app.OnFunc(ModelEventName("observed_posts", ModelCreated), func(_ context.Context, e event.Event) error {
+		seen = append(seen, e.Data().(*ModelEvent).Model.(*observedPost).Title)
+		return nil
+	})
+
+	_, err = Query[observedPost](db).Create(&observedPost{}, ctx)
+	assert.ErrorContains(t, err, "title is required")
+
+	post, err := Query[observedPost](db).Create(&observedPost{Title: "Hello"}, ctx)
+	require.NoError(t, err)
+	post.Title = "Hello again"
+	require.NoError(t, Query[observedPost](db).Save(post, ctx))
+	require.NoError(t, NewBaseRepository[observedPost](db).Delete(ctx, post.ID))
+
+	assert.Equal(t, []string{"creating Hello", "created Hello", "updated Hello again", "deleted Hello again"}, obs.events)
+	assert.Equal(t, []string{"Hello"}, seen)
+}
+
+type badObserver struct{}
+
+func (badObserver) Created(p *observedPost) {}
+
+func TestObserve_InvalidObserver(t *testing.T) {
+	assert.PanicsWithValue(t, "orm: observer database.badObserver: Created must be func(context.Context, *observedPost) error", func() {
+		Observe[observedPost](badObserver{})
+	})
+	assert.Panics(t, func() { Observe[observedPost](struct{}{}) })
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -162,3 +163,36 @@ func BenchmarkScanner(b *testing.B) {
 		_, _ = Query[User](db).Get(ctx)
 	}
 }
+
+func TestNotFoundError(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, email TEXT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	assert.NoError(t, err)
+
+	_, err = Query[User](db).FindByID(42, ctx)
+	var nf *NotFoundError
+	assert.ErrorAs(t, err, &nf)
+	assert.Equal(t, "User", nf.Model)
+	assert.Equal(t, 42, nf.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestToSnakeCase_KeepsExistingColumnNames(t *testing.T) {
+	for in, want := range map[string]string{
+		"ID":         "id",
+		"UserID":     "user_id",
+		"CreatedAt":  "created_at",
+		"UserIDs":    "user_i_ds",
+		"URLs":       "ur_ls",
+		"PDFs":       "pd_fs",
+		"UUIDs":      "uui_ds",
+		"Field_Name": "field__name",
+	} {
+		assert.Equal(t, want, toSnakeCase(in), in)
+	}
+}
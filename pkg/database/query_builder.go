@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"reflect"
 	"strings"
 	"time"
+
+	"github.com/shauryagautam/Astra/pkg/support/enum"
 )
 
 // QueryBuilder is a generic fluent query builder.
@@ -25,6 +28,7 @@ type QueryBuilder[T any] struct {
 	withTrashed  bool
 	baseURL      string
 	lock         string
+	conflict     *conflictClause
 	globalScopes []func(*QueryBuilder[T]) *QueryBuilder[T]
 }
 
@@ -106,6 +110,17 @@ func (q *QueryBuilder[T]) WhereIn(column string, values []any) *QueryBuilder[T]
 	return q
 }
 
+// WhereDistanceWithin keeps rows whose Point column lies within meters of
+// lat, lng. It needs PostGIS on PostgreSQL; see Point.
+func (q *QueryBuilder[T]) WhereDistanceWithin(column string, lat, lng, meters float64) *QueryBuilder[T] {
+	q.wheres = append(q.wheres, whereClause{
+		Column:   column,
+		Operator: "DISTANCE WITHIN",
+		Value:    distanceWithin{point: Point{Lat: lat, Lng: lng}, meters: meters},
+	})
+	return q
+}
+
 func (q *QueryBuilder[T]) WhereNull(column string) *QueryBuilder[T] {
 	q.wheres = append(q.wheres, whereClause{Column: column, Operator: "IS NULL"})
 	return q
@@ -286,7 +301,7 @@ func (q *QueryBuilder[T]) First(ctx ...context.Context) (*T, error) {
 		return nil, err
 	}
 	if len(results) == 0 {
-		return nil, sql.ErrNoRows
+		return nil, q.notFound(nil)
 	}
 	return &results[0], nil
 }
@@ -298,7 +313,11 @@ func (q *QueryBuilder[T]) Last(ctx ...context.Context) (*T, error) {
 }
 
 func (q *QueryBuilder[T]) FindByID(id any, ctx ...context.Context) (*T, error) {
-	return q.Where(q.meta.PK.ColumnName, "=", id).First(ctx...)
+	model, err := q.Where(q.meta.PK.ColumnName, "=", id).First(ctx...)
+	if nf, ok := err.(*NotFoundError); ok {
+		nf.ID = id
+	}
+	return model, err
 }
 
 func (q *QueryBuilder[T]) FindBy(column string, value any, ctx ...context.Context) (*T, error) {
@@ -310,7 +329,7 @@ func (q *QueryBuilder[T]) FirstOrCreate(attributes *T, ctx ...context.Context) (
 	if err == nil {
 		return found, false, nil
 	}
-	if err != sql.ErrNoRows {
+	if !errors.Is(err, sql.ErrNoRows) {
 		return nil, false, err
 	}
 	res, err := q.Create(attributes, ctx...)
@@ -342,8 +361,12 @@ func (q *QueryBuilder[T]) Pluck(column string, ctx ...context.Context) ([]any, e
 	if len(ctx) > 0 {
 		q.ctx = ctx[0]
 	}
-	q = q.ApplyScopes()
+	return q.ApplyScopes().pluck(column)
+}
 
+// pluck selects column from the rows matched by the builder's conditions as
+// they are, for callers that already applied the scopes.
+func (q *QueryBuilder[T]) pluck(column string) ([]any, error) {
 	var sb strings.Builder
 	sb.WriteString("SELECT ")
 	sb.WriteString(q.db.dialect.QuoteIdentifier(column))
@@ -496,9 +519,13 @@ func (q *QueryBuilder[T]) Create(model *T, ctx ...context.Context) (*T, error) {
 	}
 
 	v := reflect.ValueOf(model).Elem()
+	if err := q.checkEnums(v); err != nil {
+		return nil, err
+	}
 	now := time.Now()
 	setTimestamp(v, "CreatedAt", now)
 	setTimestamp(v, "UpdatedAt", now)
+	q.initVersion(v)
 
 	var columns []string
 	var values []any
@@ -511,11 +538,16 @@ func (q *QueryBuilder[T]) Create(model *T, ctx ...context.Context) (*T, error) {
 	}
 
 	sqlStr, args := q.toInsertSQL(columns, values)
+	sqlStr += q.conflictSQL()
 
 	if q.db.dialect.SupportsReturning() {
 		sqlStr += " RETURNING " + q.db.dialect.QuoteIdentifier(q.meta.PK.ColumnName)
 		var id uint
 		if err := q.db.conn.QueryRow(q.ctx, sqlStr, args...).Scan(&id); err != nil {
+			// DO NOTHING suppresses the conflicting row, so nothing is returned.
+			if err == sql.ErrNoRows && q.conflict != nil && len(q.conflict.update) == 0 {
+				return model, nil
+			}
 			return nil, err
 		}
 		setFieldValue(v, q.meta.PK, id)
@@ -538,9 +570,13 @@ func (q *QueryBuilder[T]) Update(data map[string]any, ctx ...context.Context) er
 		q.ctx = ctx[0]
 	}
 	q = q.ApplyScopes()
+	evict := q.cacheEvictor()
 	sqlStr, args := q.toUpdateSQL(data)
-	_, err := q.db.conn.Exec(q.ctx, sqlStr, args...)
-	return err
+	if _, err := q.db.conn.Exec(q.ctx, sqlStr, args...); err != nil {
+		return err
+	}
+	evict()
+	return nil
 }
 
 func (q *QueryBuilder[T]) Save(model *T, ctx ...context.Context) error {
@@ -553,6 +589,9 @@ func (q *QueryBuilder[T]) Save(model *T, ctx ...context.Context) error {
 	}
 
 	v := reflect.ValueOf(model).Elem()
+	if err := q.checkEnums(v); err != nil {
+		return err
+	}
 	setTimestamp(v, "UpdatedAt", time.Now())
 
 	pkVal := fieldByIndex(v, q.meta.PK.FieldIndex).Interface()
@@ -566,14 +605,72 @@ func (q *QueryBuilder[T]) Save(model *T, ctx ...context.Context) error {
 	}
 
 	q.Where(q.meta.PK.ColumnName, "=", pkVal)
-	if err := q.Update(data, q.ctx); err != nil {
+
+	if !q.meta.HasVersion {
+		if err := q.Update(data, q.ctx); err != nil {
+			return err
+		}
+		_ = callAfterUpdate(q.ctx, q.db, model)
+		return nil
+	}
+
+	// Optimistic locking: only update the row if nobody else bumped the
+	// version since this model was loaded.
+	versionField := fieldByIndex(v, q.meta.Version.FieldIndex)
+	current := intValue(versionField)
+	q.Where(q.meta.Version.ColumnName, "=", current)
+	data[q.meta.Version.ColumnName] = current + 1
+
+	affected, err := q.UpdateMany(data, q.ctx)
+	if err != nil {
 		return err
 	}
+	if affected == 0 {
+		return &StaleModelError{Table: q.meta.TableName, ID: pkVal, Version: current}
+	}
+	setIntValue(versionField, current+1)
 
 	_ = callAfterUpdate(q.ctx, q.db, model)
 	return nil
 }
 
+// notFound builds the error returned when a lookup matches no row.
+func (q *QueryBuilder[T]) notFound(id any) *NotFoundError {
+	name := q.meta.TableName
+	if q.meta.Type != nil && q.meta.Type.Name() != "" {
+		name = q.meta.Type.Name()
+	}
+	return &NotFoundError{Model: name, Table: q.meta.TableName, ID: id}
+}
+
+// checkEnums rejects a model whose enum fields, those of a type declared
+// with enum.New, hold a value outside their enum. A nil pointer is NULL and
+// passes.
+func (q *QueryBuilder[T]) checkEnums(v reflect.Value) error {
+	for _, col := range q.meta.Columns {
+		e, ok := enum.Lookup(col.Type)
+		if !ok {
+			continue
+		}
+		s, ok := enum.Value(fieldByIndex(v, col.FieldIndex).Interface())
+		if ok && !e.Contains(s) {
+			return &InvalidEnumError{Table: q.meta.TableName, Column: col.ColumnName, Value: s, Values: e.Strings()}
+		}
+	}
+	return nil
+}
+
+// initVersion starts the optimistic-locking counter of a new model at 1.
+func (q *QueryBuilder[T]) initVersion(v reflect.Value) {
+	if !q.meta.HasVersion {
+		return
+	}
+	f := fieldByIndex(v, q.meta.Version.FieldIndex)
+	if intValue(f) == 0 {
+		setIntValue(f, 1)
+	}
+}
+
 func (q *QueryBuilder[T]) Delete(ctx ...context.Context) error {
 	if len(ctx) > 0 {
 		q.ctx = ctx[0]
@@ -582,9 +679,31 @@ func (q *QueryBuilder[T]) Delete(ctx ...context.Context) error {
 	if q.meta.HasSoftDel {
 		return q.Update(map[string]any{"deleted_at": time.Now()}, q.ctx)
 	}
+	evict := q.cacheEvictor()
 	sqlStr, args := q.toDeleteSQL()
-	_, err := q.db.conn.Exec(q.ctx, sqlStr, args...)
-	return err
+	if _, err := q.db.conn.Exec(q.ctx, sqlStr, args...); err != nil {
+		return err
+	}
+	evict()
+	return nil
+}
+
+// Destroy deletes model by its primary key, soft-deleting it when the
+// model supports it. Unlike Delete, it runs the BeforeDelete and
+// AfterDelete hooks and the deleting and deleted observers.
+func (q *QueryBuilder[T]) Destroy(model *T, ctx ...context.Context) error {
+	if len(ctx) > 0 {
+		q.ctx = ctx[0]
+	}
+	if err := callBeforeDelete(q.ctx, q.db, model); err != nil {
+		return err
+	}
+	pk := fieldByIndex(reflect.ValueOf(model).Elem(), q.meta.PK.FieldIndex).Interface()
+	if err := q.Where(q.meta.PK.ColumnName, "=", pk).Delete(q.ctx); err != nil {
+		return err
+	}
+	_ = callAfterDelete(q.ctx, q.db, model)
+	return nil
 }
 
 func (q *QueryBuilder[T]) ForceDelete(ctx ...context.Context) error {
@@ -592,9 +711,13 @@ func (q *QueryBuilder[T]) ForceDelete(ctx ...context.Context) error {
 		q.ctx = ctx[0]
 	}
 	q = q.ApplyScopes()
+	evict := q.cacheEvictor()
 	sqlStr, args := q.toDeleteSQL()
-	_, err := q.db.conn.Exec(q.ctx, sqlStr, args...)
-	return err
+	if _, err := q.db.conn.Exec(q.ctx, sqlStr, args...); err != nil {
+		return err
+	}
+	evict()
+	return nil
 }
 
 func (q *QueryBuilder[T]) Restore(ctx ...context.Context) error {
@@ -739,6 +862,11 @@ func (q *QueryBuilder[T]) buildWheres(offset int) (string, []any) {
 			}
 			sb.WriteString(")")
 
+		case w.Operator == "DISTANCE WITHIN":
+			cond, condArgs := distanceWithinSQL(q.db.dialect, w.Column, w.Value.(distanceWithin), offset+len(args))
+			sb.WriteString(cond)
+			args = append(args, condArgs...)
+
 		case strings.Contains(w.Operator, "NULL"):
 			sb.WriteString(q.db.dialect.QuoteIdentifier(w.Column))
 			sb.WriteString(" ")
@@ -749,7 +877,7 @@ func (q *QueryBuilder[T]) buildWheres(offset int) (string, []any) {
 			sb.WriteString(" ")
 			sb.WriteString(w.Operator)
 			sb.WriteString(" ")
-			sb.WriteString(q.db.dialect.Placeholder(offset + len(args) + 1))
+			sb.WriteString(geoPlaceholder(q.db.dialect, offset+len(args)+1, w.Value))
 			args = append(args, w.Value)
 		}
 
@@ -784,11 +912,11 @@ func (q *QueryBuilder[T]) toInsertSQL(columns []string, values []any) (string, [
 		sb.WriteString(q.db.dialect.QuoteIdentifier(col))
 	}
 	sb.WriteString(") VALUES (")
-	for i := range values {
+	for i, v := range values {
 		if i > 0 {
 			sb.WriteString(", ")
 		}
-		sb.WriteString(q.db.dialect.Placeholder(i + 1))
+		sb.WriteString(geoPlaceholder(q.db.dialect, i+1, v))
 	}
 	sb.WriteString(")")
 	return sb.String(), values
@@ -811,7 +939,7 @@ func (q *QueryBuilder[T]) toUpdateSQL(data map[string]any) (string, []any) {
 		}
 		sb.WriteString(q.db.dialect.QuoteIdentifier(col))
 		sb.WriteString(" = ")
-		sb.WriteString(q.db.dialect.Placeholder(i + 1))
+		sb.WriteString(geoPlaceholder(q.db.dialect, i+1, val))
 		args = append(args, val)
 		i++
 	}
@@ -880,6 +1008,30 @@ func setFieldValue(v reflect.Value, col ColumnMeta, val any) {
 	}
 }
 
+// intValue reads any signed or unsigned integer field as int64.
+func intValue(f reflect.Value) int64 {
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(f.Uint())
+	}
+	return 0
+}
+
+// setIntValue writes n into a signed or unsigned integer field.
+func setIntValue(f reflect.Value, n int64) {
+	if !f.CanSet() {
+		return
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.SetUint(uint64(n))
+	}
+}
+
 // setTimestamp finds a time.Time field by name and sets it (handles embedded structs).
 func setTimestamp(v reflect.Value, fieldName string, t time.Time) {
 	f := v.FieldByName(fieldName)
@@ -2,6 +2,7 @@ package database
 
 import (
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -16,6 +17,8 @@ type ModelMeta struct {
 	ColumnByCol map[string]ColumnMeta
 	PK          ColumnMeta
 	HasSoftDel  bool
+	HasVersion  bool
+	Version     ColumnMeta // optimistic-locking column, valid when HasVersion
 	Relations   []RelationMeta
 }
 
@@ -29,8 +32,12 @@ type ColumnMeta struct {
 	IsPK       bool
 	IsAuto     bool
 	IsSoftDel  bool
+	IsVersion  bool // Optimistic locking counter
 	IsGuarded  bool // Mass assignment protection
 	IsNullZero bool
+	IsUnique   bool   // `orm:"unique"`: backed by a unique index
+	IsIndexed  bool   // `orm:"index"`: backed by a plain index
+	SQLType    string // `orm:"type:..."`: column type override for generated migrations
 	Type       reflect.Type
 }
 
@@ -80,11 +87,27 @@ func RegisterModel[T any](meta ModelMeta) {
 			if col.IsPK {
 				meta.PK = col
 			}
+			if col.IsVersion {
+				meta.HasVersion = true
+				meta.Version = col
+			}
 		}
 	}
 	registry.Store(t, &meta)
 }
 
+// RegisteredModels returns the metadata of every model registered so far,
+// whether through RegisterModel or a first query, sorted by table name.
+func RegisteredModels() []*ModelMeta {
+	var metas []*ModelMeta
+	registry.Range(func(_, v any) bool {
+		metas = append(metas, v.(*ModelMeta))
+		return true
+	})
+	slices.SortFunc(metas, func(a, b *ModelMeta) int { return strings.Compare(a.TableName, b.TableName) })
+	return metas
+}
+
 // GetMeta retrieves or builds metadata for a type.
 func GetMeta(t reflect.Type) *ModelMeta {
 	if t.Kind() == reflect.Ptr {
@@ -136,6 +159,10 @@ func buildMeta(t reflect.Type, parentIndex []int) *ModelMeta {
 					if col.IsSoftDel {
 						meta.HasSoftDel = true
 					}
+					if col.IsVersion {
+						meta.HasVersion = true
+						meta.Version = col
+					}
 				}
 				meta.Relations = append(meta.Relations, embedded.Relations...)
 				continue
@@ -156,6 +183,10 @@ func buildMeta(t reflect.Type, parentIndex []int) *ModelMeta {
 		if col.IsSoftDel {
 			meta.HasSoftDel = true
 		}
+		if col.IsVersion {
+			meta.HasVersion = true
+			meta.Version = col
+		}
 	}
 
 	return meta
@@ -195,9 +226,17 @@ func parseColumn(field reflect.StructField, tag string, index []int) ColumnMeta
 			col.IsAuto = true
 		case "soft_delete":
 			col.IsSoftDel = true
+		case "version":
+			col.IsVersion = true
 		case "guarded", "protected":
 			col.IsGuarded = true
-		case "not_null", "unique":
+		case "unique":
+			col.IsUnique = true
+		case "index":
+			col.IsIndexed = true
+		case "type":
+			col.SQLType = val
+		case "not_null":
 			// reserved for future schema builder use
 		case "null_zero":
 			col.IsNullZero = true
@@ -311,6 +350,10 @@ func getTableName(t reflect.Type) string {
 // Handles consecutive uppercase abbreviations:
 //
 //	ID → id, URL → url, UserID → user_id, CreatedAt → created_at
+//
+// It is deliberately not str.Snake: column and table names derived here are
+// part of existing schemas, so names such as UserIDs (user_i_ds) or
+// Field_Name (field__name) must keep the spelling they have always had.
 func toSnakeCase(s string) string {
 	runes := []rune(s)
 	n := len(runes)
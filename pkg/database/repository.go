@@ -48,8 +48,16 @@ func (r *BaseRepository[T]) Update(ctx context.Context, model *T) error {
 	return Query[T](r.DB, ctx).Save(model)
 }
 
-// Delete removes a record by its primary key.
+// Delete removes a record by its primary key. When the model has delete
+// hooks or observers, it is loaded first so they receive it.
 func (r *BaseRepository[T]) Delete(ctx context.Context, id any) error {
+	if observesDeletes[T]() {
+		model, err := Query[T](r.DB, ctx).FindByID(id)
+		if err != nil {
+			return err
+		}
+		return Query[T](r.DB, ctx).Destroy(model)
+	}
 	// We use the PK column name from metadata to ensure correct deletion.
 	q := Query[T](r.DB, ctx)
 	return q.Where(q.meta.PK.ColumnName, "=", id).Delete()
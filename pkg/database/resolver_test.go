@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/database/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDriver(t *testing.T) {
+	assert.Equal(t, "postgres", DetectDriver("postgres://u:p@localhost/app"))
+	assert.Equal(t, "mysql", DetectDriver("mysql://u:p@localhost/app"))
+	assert.Equal(t, "sqlite", DetectDriver("sqlite://storage/app.db"))
+	assert.Equal(t, "sqlite", DetectDriver(":memory:"))
+	assert.Equal(t, "postgres", DetectDriver("host=localhost dbname=app"))
+}
+
+func TestNormalizeDSN(t *testing.T) {
+	assert.Equal(t, "u:p@tcp(db:3306)/app?parseTime=true",
+		NormalizeDSN("mysql", "mysql://u:p@db:3306/app?parseTime=true"))
+	assert.Equal(t, "u:p@tcp(db:3306)/app", NormalizeDSN("mysql", "u:p@tcp(db:3306)/app"))
+	assert.Equal(t, "storage/app.db", NormalizeDSN("sqlite", "sqlite://storage/app.db"))
+	assert.Equal(t, "postgres://u:p@localhost/app", NormalizeDSN("postgres", "postgres://u:p@localhost/app"))
+}
+
+func TestSchemaSQLite(t *testing.T) {
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	s := db.Schema()
+	require.NoError(t, s.CreateTable("posts", func(t *schema.Table) {
+		t.ID()
+		t.String("slug", 120).NotNull()
+		t.Text("body")
+		t.Timestamps()
+		t.AddUniqueIndex("slug")
+		t.AddNamedIndex("posts_created_idx", "created_at")
+	}))
+
+	ctx := context.Background()
+	_, err = db.Exec(ctx, "INSERT INTO posts (slug, body, created_at, updated_at) VALUES (?, ?, ?, ?)", "a", "x", time.Now(), time.Now())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO posts (slug, body, created_at, updated_at) VALUES (?, ?, ?, ?)", "a", "y", time.Now(), time.Now())
+	assert.Error(t, err, "unique index should reject duplicate slug")
+
+	require.NoError(t, s.AlterTable("posts", func(t *schema.Table) {
+		t.DropIndex("posts_created_idx")
+	}))
+}
+
+func TestTruncateAll(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, stmt := range []string{
+		"PRAGMA foreign_keys = ON",
+		"CREATE TABLE teams (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		"CREATE TABLE members (id INTEGER PRIMARY KEY AUTOINCREMENT, team_id INTEGER NOT NULL REFERENCES teams(id))",
+		"CREATE TABLE countries (code TEXT PRIMARY KEY)",
+		"CREATE TABLE schema_migrations (version TEXT)",
+		"INSERT INTO teams (name) VALUES ('core'), ('web')",
+		"INSERT INTO members (team_id) VALUES (1), (2)",
+		"INSERT INTO countries VALUES ('DE')",
+		"INSERT INTO schema_migrations VALUES ('001_init')",
+	} {
+		_, err := db.Exec(ctx, stmt)
+		require.NoError(t, err, stmt)
+	}
+
+	require.NoError(t, TruncateAll(ctx, db, "countries"))
+
+	count := func(table string) int {
+		var n int
+		require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n))
+		return n
+	}
+	assert.Equal(t, 0, count("teams"))
+	assert.Equal(t, 0, count("members"))
+	assert.Equal(t, 1, count("countries"), "excluded tables keep their rows")
+	assert.Equal(t, 1, count(MigrationsTable), "migration history is kept")
+
+	_, err = db.Exec(ctx, "INSERT INTO teams (name) VALUES ('new')")
+	require.NoError(t, err)
+	var id int
+	require.NoError(t, db.QueryRow(ctx, "SELECT id FROM teams").Scan(&id))
+	assert.Equal(t, 1, id, "AUTOINCREMENT restarts")
+}
@@ -9,6 +9,7 @@ import (
 
 // Dialect interface for schema generation (to avoid circular dependency)
 type Dialect interface {
+	Name() string
 	QuoteIdentifier(name string) string
 	AutoIncrementDDL() string
 }
@@ -28,8 +29,10 @@ func (b *Builder) CreateTable(name string, fn func(*Table)) error {
 	fn(t)
 
 	sqlStr := b.buildCreateTableSQL(t, false)
-	_, err := b.Exec.Exec(context.Background(), sqlStr)
-	return err
+	if _, err := b.Exec.Exec(context.Background(), sqlStr); err != nil {
+		return err
+	}
+	return b.createIndices(t, false)
 }
 
 func (b *Builder) CreateTableIfNotExists(name string, fn func(*Table)) error {
@@ -37,8 +40,46 @@ func (b *Builder) CreateTableIfNotExists(name string, fn func(*Table)) error {
 	fn(t)
 
 	sqlStr := b.buildCreateTableSQL(t, true)
-	_, err := b.Exec.Exec(context.Background(), sqlStr)
-	return err
+	if _, err := b.Exec.Exec(context.Background(), sqlStr); err != nil {
+		return err
+	}
+	return b.createIndices(t, true)
+}
+
+// CreateTableSQL returns the statements CreateTable would run for t: the
+// CREATE TABLE itself followed by one CREATE INDEX per index.
+func (b *Builder) CreateTableSQL(t *Table) []string {
+	stmts := []string{b.buildCreateTableSQL(t, false)}
+	return append(stmts, b.indexStatements(t, false)...)
+}
+
+// AddColumnSQL returns the ALTER TABLE statement that adds c to table.
+func (b *Builder) AddColumnSQL(table string, c *Column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
+		b.Dialect.QuoteIdentifier(table),
+		b.buildColumnSQL(c))
+}
+
+// DropColumnSQL returns the ALTER TABLE statement that drops column from table.
+func (b *Builder) DropColumnSQL(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s",
+		b.Dialect.QuoteIdentifier(table),
+		b.Dialect.QuoteIdentifier(column))
+}
+
+// CreateIndexSQL returns the CREATE INDEX statement for idx on table.
+func (b *Builder) CreateIndexSQL(table string, idx Index, unique bool) string {
+	return b.createIndexSQL(table, idx, unique, false)
+}
+
+// DropIndexSQL returns the DROP INDEX statement for the index called name.
+func (b *Builder) DropIndexSQL(table, name string) string {
+	return b.dropIndexSQL(table, name)
+}
+
+// DropTableSQL returns the DROP TABLE statement for name.
+func (b *Builder) DropTableSQL(name string) string {
+	return fmt.Sprintf("DROP TABLE %s", b.Dialect.QuoteIdentifier(name))
 }
 
 func (b *Builder) buildCreateTableSQL(t *Table, ifNotExists bool) string {
@@ -74,11 +115,28 @@ func (b *Builder) buildColumnSQL(c *Column) string {
 	sb.WriteString(b.Dialect.QuoteIdentifier(c.Name))
 	sb.WriteString(" ")
 
-	colType := c.Type
 	if c.IsAuto {
-		colType = b.Dialect.AutoIncrementDDL()
+		// Auto-increment keys carry their own constraints; the DDL differs
+		// enough per dialect that it is emitted as a unit.
+		switch b.Dialect.Name() {
+		case "mysql":
+			sb.WriteString(c.Type + " NOT NULL " + b.Dialect.AutoIncrementDDL() + " PRIMARY KEY")
+		case "sqlite":
+			sb.WriteString(b.Dialect.AutoIncrementDDL())
+		default:
+			sb.WriteString(b.Dialect.AutoIncrementDDL() + " PRIMARY KEY")
+		}
+		return sb.String()
+	}
+	mysqlEnum := len(c.Enum) > 0 && b.Dialect.Name() == "mysql"
+	switch {
+	case c.Spatial != "":
+		sb.WriteString(SpatialType(b.Dialect.Name(), c.Spatial))
+	case mysqlEnum:
+		sb.WriteString("ENUM(" + enumList(c.Enum) + ")")
+	default:
+		sb.WriteString(c.Type)
 	}
-	sb.WriteString(colType)
 
 	if !c.IsNullable {
 		sb.WriteString(" NOT NULL")
@@ -86,19 +144,49 @@ func (b *Builder) buildColumnSQL(c *Column) string {
 	if c.IsUnique {
 		sb.WriteString(" UNIQUE")
 	}
-	if c.IsPrimary && !c.IsAuto {
+	if c.IsPrimary {
 		sb.WriteString(" PRIMARY KEY")
 	}
 	if c.DefaultValue != nil {
 		sb.WriteString(fmt.Sprintf(" DEFAULT %v", c.DefaultValue))
 	}
+	if len(c.Enum) > 0 && !mysqlEnum {
+		sb.WriteString(fmt.Sprintf(" CHECK (%s IN (%s))", b.Dialect.QuoteIdentifier(c.Name), enumList(c.Enum)))
+	}
 
 	return sb.String()
 }
 
+// enumList renders values as a list of SQL string literals.
+func enumList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = QuoteString(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// QuoteString renders s as an SQL string literal.
+func QuoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// SpatialType returns the column type of the dialect for a geometry type,
+// "Point" or "Polygon", in WGS 84 coordinates (SRID 4326). SQLite has no
+// spatial types, so it stores GeoJSON text.
+func SpatialType(dialect, geometry string) string {
+	switch dialect {
+	case "mysql":
+		return strings.ToUpper(geometry) + " SRID 4326"
+	case "sqlite":
+		return "TEXT"
+	default:
+		return "geography(" + geometry + ", 4326)"
+	}
+}
+
 func (b *Builder) DropTable(name string) error {
-	sql := fmt.Sprintf("DROP TABLE %s", b.Dialect.QuoteIdentifier(name))
-	_, err := b.Exec.Exec(context.Background(), sql)
+	_, err := b.Exec.Exec(context.Background(), b.DropTableSQL(name))
 	return err
 }
 
@@ -113,23 +201,27 @@ func (b *Builder) AlterTable(name string, fn func(*Table)) error {
 	fn(t)
 
 	for _, col := range t.Columns {
-		sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
-			b.Dialect.QuoteIdentifier(t.Name),
-			b.buildColumnSQL(col))
-		if _, err := b.Exec.Exec(context.Background(), sql); err != nil {
+		if _, err := b.Exec.Exec(context.Background(), b.AddColumnSQL(t.Name, col)); err != nil {
 			return err
 		}
 	}
 
 	for _, colName := range t.droppedColumns {
-		sql := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s",
-			b.Dialect.QuoteIdentifier(t.Name),
-			b.Dialect.QuoteIdentifier(colName))
-		if _, err := b.Exec.Exec(context.Background(), sql); err != nil {
+		if _, err := b.Exec.Exec(context.Background(), b.DropColumnSQL(t.Name, colName)); err != nil {
 			return err
 		}
 	}
 
+	for _, name := range t.droppedIndices {
+		if _, err := b.Exec.Exec(context.Background(), b.dropIndexSQL(t.Name, name)); err != nil {
+			return err
+		}
+	}
+
+	if err := b.createIndices(t, false); err != nil {
+		return err
+	}
+
 	for old, new := range t.renameColumns {
 		sql := fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
 			b.Dialect.QuoteIdentifier(t.Name),
@@ -143,6 +235,86 @@ func (b *Builder) AlterTable(name string, fn func(*Table)) error {
 	return nil
 }
 
+// createIndices emits CREATE INDEX statements for the table's plain and
+// unique indices. Unnamed indices get an idx_/uniq_ name derived from the
+// table and columns.
+func (b *Builder) createIndices(t *Table, ifNotExists bool) error {
+	for _, sqlStr := range b.indexStatements(t, ifNotExists) {
+		if _, err := b.Exec.Exec(context.Background(), sqlStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Builder) indexStatements(t *Table, ifNotExists bool) []string {
+	stmts := make([]string, 0, len(t.Indices)+len(t.Uniques))
+	for _, idx := range t.Indices {
+		stmts = append(stmts, b.createIndexSQL(t.Name, idx, false, ifNotExists))
+	}
+	for _, idx := range t.Uniques {
+		stmts = append(stmts, b.createIndexSQL(t.Name, idx, true, ifNotExists))
+	}
+	return stmts
+}
+
+// IndexName returns the name idx is created under: its own Name, or an
+// idx_/uniq_ name derived from the table and columns.
+func IndexName(table string, idx Index, unique bool) string {
+	if idx.Name != "" {
+		return idx.Name
+	}
+	prefix := "idx"
+	if unique {
+		prefix = "uniq"
+	}
+	return prefix + "_" + table + "_" + strings.Join(idx.Columns, "_")
+}
+
+func (b *Builder) createIndexSQL(table string, idx Index, unique, ifNotExists bool) string {
+	name := IndexName(table, idx, unique)
+
+	cols := make([]string, len(idx.Columns))
+	for i, c := range idx.Columns {
+		cols[i] = b.Dialect.QuoteIdentifier(c)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	if unique {
+		sb.WriteString("UNIQUE ")
+	}
+	if idx.Spatial && b.Dialect.Name() == "mysql" {
+		sb.WriteString("SPATIAL ")
+	}
+	sb.WriteString("INDEX ")
+	// MySQL has no IF NOT EXISTS for indices.
+	if ifNotExists && b.Dialect.Name() != "mysql" {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+	sb.WriteString(b.Dialect.QuoteIdentifier(name))
+	sb.WriteString(" ON ")
+	sb.WriteString(b.Dialect.QuoteIdentifier(table))
+	if dialect := b.Dialect.Name(); idx.Spatial && dialect != "mysql" && dialect != "sqlite" {
+		sb.WriteString(" USING GIST")
+	}
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(cols, ", "))
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// dropIndexSQL returns the dialect-specific DROP INDEX statement. MySQL
+// scopes index names to their table; PostgreSQL and SQLite do not.
+func (b *Builder) dropIndexSQL(table, name string) string {
+	if b.Dialect.Name() == "mysql" {
+		return fmt.Sprintf("DROP INDEX %s ON %s",
+			b.Dialect.QuoteIdentifier(name),
+			b.Dialect.QuoteIdentifier(table))
+	}
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s", b.Dialect.QuoteIdentifier(name))
+}
+
 func (b *Builder) HasTable(name string) (bool, error) {
 	// Simple check using standard SQL if possible, but often dialect specific.
 	// For now, return false as placeholder or implement basic check.
@@ -10,6 +10,12 @@ type Column struct {
 	DefaultValue   any
 	ReferenceTable string
 	ReferenceCol   string
+	// Spatial is the geometry type of a spatial column ("Point" or
+	// "Polygon"); the builder picks the dialect's type for it.
+	Spatial string
+	// Enum lists the values an enum column accepts: MySQL gets an ENUM type
+	// and other dialects a CHECK constraint.
+	Enum []string
 }
 
 func (c *Column) Nullable() *Column {
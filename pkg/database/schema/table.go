@@ -19,6 +19,9 @@ type Table struct {
 type Index struct {
 	Columns []string
 	Name    string
+	// Spatial makes a GiST index on PostgreSQL and a SPATIAL index on MySQL;
+	// SQLite gets a plain index.
+	Spatial bool
 }
 
 type ForeignKey struct {
@@ -84,6 +87,32 @@ func (t *Table) Timestamp(name string) *Column {
 	return c
 }
 
+// Point adds a column holding a database.Point: geography(Point, 4326) on
+// PostgreSQL (PostGIS), POINT SRID 4326 on MySQL and GeoJSON text on SQLite.
+func (t *Table) Point(name string) *Column {
+	c := &Column{Name: name, Type: "POINT", Spatial: "Point"}
+	t.Columns = append(t.Columns, c)
+	return c
+}
+
+// Polygon adds a column holding a database.Polygon; see Point.
+func (t *Table) Polygon(name string) *Column {
+	c := &Column{Name: name, Type: "POLYGON", Spatial: "Polygon"}
+	t.Columns = append(t.Columns, c)
+	return c
+}
+
+// Enum adds a column that accepts only values: ENUM(...) on MySQL and
+// VARCHAR(255) with a CHECK constraint elsewhere. Pass the strings of an
+// enum.Set so the column and the Go type share one definition:
+//
+//	t.Enum("status", models.Statuses.Strings()...)
+func (t *Table) Enum(name string, values ...string) *Column {
+	c := &Column{Name: name, Type: "VARCHAR(255)", Enum: values}
+	t.Columns = append(t.Columns, c)
+	return c
+}
+
 func (t *Table) Timestamps() {
 	t.Timestamp("created_at").NotNull()
 	t.Timestamp("updated_at").NotNull()
@@ -93,6 +122,11 @@ func (t *Table) SoftDeletes() {
 	t.Timestamp("deleted_at").Nullable()
 }
 
+// Version adds the optimistic-locking counter used by database.Versioned.
+func (t *Table) Version() {
+	t.BigInteger("version").NotNull().Default(1)
+}
+
 func (t *Table) AddColumn(name, colType string) *Column {
 	c := &Column{Name: name, Type: colType}
 	t.Columns = append(t.Columns, c)
@@ -118,6 +152,18 @@ func (t *Table) AddIndex(columns ...string) {
 	t.Indices = append(t.Indices, Index{Columns: columns})
 }
 
+// AddNamedIndex adds an index with an explicit name, which DropIndex can
+// later refer to.
+func (t *Table) AddNamedIndex(name string, columns ...string) {
+	t.Indices = append(t.Indices, Index{Name: name, Columns: columns})
+}
+
+// SpatialIndex indexes a Point or Polygon column for distance and
+// containment queries. MySQL requires the column to be NOT NULL.
+func (t *Table) SpatialIndex(column string) {
+	t.Indices = append(t.Indices, Index{Columns: []string{column}, Spatial: true})
+}
+
 func (t *Table) AddUniqueIndex(columns ...string) {
 	t.Uniques = append(t.Uniques, Index{Columns: columns})
 }
@@ -5,8 +5,18 @@ package database
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/brianvoe/gofakeit/v6"
 )
 
 var (
@@ -50,36 +60,146 @@ func (r *SeederRunner) Register(seeders ...Seeder) {
 	}
 }
 
+// SeedOptions controls a SeederRunner.Seed call.
+type SeedOptions struct {
+	// Seed makes the data reproducible: the same seed and seeders produce the
+	// same fake values. 0 picks a random seed, reported in each SeedResult.
+	Seed int64
+	// Only limits the run to the named seeders, still in registration order.
+	Only []string
+	// Out receives progress lines; nil discards them.
+	Out io.Writer
+}
+
+// SeedResult reports what one seeder did.
+type SeedResult struct {
+	Name string
+	Seed int64
+	// Records counts the rows created through factories (or reported with
+	// CountSeeded), by table.
+	Records  map[string]int
+	Duration time.Duration
+}
+
+// Total returns the number of records the seeder created.
+func (r SeedResult) Total() int {
+	n := 0
+	for _, c := range r.Records {
+		n += c
+	}
+	return n
+}
+
+// Seed runs the registered seeders and reports what each created. Before
+// each seeder the fake data generator is reseeded from opts.Seed and the
+// seeder's name, so a seeder yields the same data whether it runs alone or
+// after others.
+func (r *SeederRunner) Seed(ctx context.Context, db *DB, opts SeedOptions) ([]SeedResult, error) {
+	out := opts.Out
+	if out == nil {
+		out = io.Discard
+	}
+	for _, name := range opts.Only {
+		if _, ok := r.index[name]; !ok {
+			return nil, fmt.Errorf("seeder %q not found. Available: %v", name, r.Names())
+		}
+	}
+	seed := opts.Seed
+	for seed == 0 {
+		seed = rand.Int63()
+	}
+
+	var results []SeedResult
+	for _, s := range r.seeders {
+		if len(opts.Only) > 0 && !slices.Contains(opts.Only, s.Name()) {
+			continue
+		}
+		state := &seedState{counts: make(map[string]int)}
+		state.rand = rand.New(rand.NewSource(seederSeed(seed, s.Name())))
+		gofakeit.Seed(state.rand.Int63())
+
+		fmt.Fprintf(out, "  Seeding: %s\n", s.Name())
+		start := time.Now()
+		err := s.Run(context.WithValue(ctx, seedStateKey{}, state), db)
+		result := SeedResult{Name: s.Name(), Seed: seed, Records: state.snapshot(), Duration: time.Since(start)}
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("seeder %q failed: %w", s.Name(), err)
+		}
+		fmt.Fprintf(out, "  ✓ Done:   %s (%s)\n", s.Name(), describeCounts(result))
+	}
+	return results, nil
+}
+
 // Run executes all registered seeders in the order they were registered.
 func (r *SeederRunner) Run(ctx context.Context, db *DB) error {
 	if len(r.seeders) == 0 {
 		fmt.Println("  No seeders registered.")
 		return nil
 	}
-
-	for _, s := range r.seeders {
-		fmt.Printf("  Seeding: %s\n", s.Name())
-		if err := s.Run(ctx, db); err != nil {
-			return fmt.Errorf("seeder %q failed: %w", s.Name(), err)
-		}
-		fmt.Printf("  ✓ Done:   %s\n", s.Name())
-	}
-	return nil
+	_, err := r.Seed(ctx, db, SeedOptions{Out: os.Stdout})
+	return err
 }
 
 // RunByName runs a specific seeder by its registered name.
 func (r *SeederRunner) RunByName(ctx context.Context, db *DB, name string) error {
-	s, ok := r.index[name]
-	if !ok {
-		available := r.Names()
-		return fmt.Errorf("seeder %q not found. Available: %v", name, available)
+	_, err := r.Seed(ctx, db, SeedOptions{Only: []string{name}, Out: os.Stdout})
+	return err
+}
+
+// SeedRand returns the random source of the running seeder, seeded like the
+// fake data generator, for seeders that need randomness of their own.
+// Outside a seeder it returns a randomly seeded source.
+func SeedRand(ctx context.Context) *rand.Rand {
+	if state, ok := ctx.Value(seedStateKey{}).(*seedState); ok {
+		return state.rand
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// CountSeeded adds n records in table to the running seeder's summary.
+// Factories call it for every model they create; seeders that insert rows
+// another way can call it themselves. Outside a seeder it does nothing.
+func CountSeeded(ctx context.Context, table string, n int) {
+	if state, ok := ctx.Value(seedStateKey{}).(*seedState); ok {
+		state.mu.Lock()
+		state.counts[table] += n
+		state.mu.Unlock()
+	}
+}
+
+type seedStateKey struct{}
+
+type seedState struct {
+	rand   *rand.Rand
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (s *seedState) snapshot() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.counts)
+}
+
+// seederSeed derives a seeder's own seed so that adding or removing one
+// seeder does not change the data of the others.
+func seederSeed(seed int64, name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return seed ^ int64(h.Sum64()) // #nosec G115 -- bit mixing, wrap-around is intended
+}
+
+func describeCounts(r SeedResult) string {
+	if len(r.Records) == 0 {
+		return "no records counted"
 	}
-	fmt.Printf("  Seeding: %s\n", s.Name())
-	if err := s.Run(ctx, db); err != nil {
-		return fmt.Errorf("seeder %q failed: %w", name, err)
+	tables := slices.Sorted(maps.Keys(r.Records))
+	parts := make([]string, len(tables))
+	for i, t := range tables {
+		parts[i] = fmt.Sprintf("%s: %d", t, r.Records[t])
 	}
-	fmt.Printf("  ✓ Done:   %s\n", s.Name())
-	return nil
+	return fmt.Sprintf("%d records; %s", r.Total(), strings.Join(parts, ", "))
 }
 
 // Names returns all registered seeder names, sorted alphabetically.
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type seedFunc struct {
+	name string
+	run  func(ctx context.Context, db *DB) error
+}
+
+func (s seedFunc) Name() string                          { return s.name }
+func (s seedFunc) Run(ctx context.Context, db *DB) error { return s.run(ctx, db) }
+
+func seedUsers(t *testing.T, seed int64) ([]string, []SeedResult) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, email TEXT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	require.NoError(t, err)
+
+	users := Factory(func(f *FactoryDef[User]) {
+		f.Set("Name", func() any { return FakeName() })
+		f.Set("Email", func() any { return FakeEmail() })
+	})
+	r := NewSeederRunner()
+	r.Register(
+		seedFunc{"01_users", func(ctx context.Context, db *DB) error {
+			_, err := users.CreateMany(ctx, 5, db)
+			return err
+		}},
+		seedFunc{"02_noop", func(ctx context.Context, db *DB) error {
+			CountSeeded(ctx, "audit_logs", 2)
+			return nil
+		}},
+	)
+	results, err := r.Seed(ctx, db, SeedOptions{Seed: seed})
+	require.NoError(t, err)
+
+	rows, err := Query[User](db).OrderBy("id", "asc").Get(ctx)
+	require.NoError(t, err)
+	names := make([]string, len(rows))
+	for i, u := range rows {
+		names[i] = u.Name + " <" + u.Email + ">"
+	}
+	return names, results
+}
+
+func TestSeederIsReproducibleWithSeed(t *testing.T) {
+	first, results := seedUsers(t, 42)
+	second, _ := seedUsers(t, 42)
+	other, _ := seedUsers(t, 7)
+
+	assert.Len(t, first, 5)
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, map[string]int{"users": 5}, results[0].Records)
+	assert.Equal(t, 2, results[1].Total())
+	assert.Equal(t, int64(42), results[0].Seed)
+}
+
+func TestSeederOnly(t *testing.T) {
+	r := NewSeederRunner()
+	ran := []string{}
+	for _, name := range []string{"a", "b", "c"} {
+		r.Register(seedFunc{name, func(context.Context, *DB) error { ran = append(ran, name); return nil }})
+	}
+	results, err := r.Seed(context.Background(), nil, SeedOptions{Only: []string{"c", "a"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, ran)
+	assert.Len(t, results, 2)
+	assert.NotZero(t, results[0].Seed, "a random seed is reported")
+
+	_, err = r.Seed(context.Background(), nil, SeedOptions{Only: []string{"missing"}})
+	assert.ErrorContains(t, err, `seeder "missing" not found`)
+}
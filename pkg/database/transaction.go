@@ -6,14 +6,17 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/shauryagautam/Astra/pkg/support/txhook"
 )
 
 type txKey struct{}
 type txIDKey struct{}
 
-// WithContext returns a new context with the transaction DB instance attached.
+// WithContext returns a new context with the transaction DB instance
+// attached. It also binds the context for txhook, so the queue and event
+// packages can defer work until the transaction commits.
 func WithContext(ctx context.Context, db *DB) context.Context {
-	return context.WithValue(ctx, txKey{}, db)
+	return txhook.WithDeferrer(context.WithValue(ctx, txKey{}, db), db)
 }
 
 // FromContext retrieves the transaction DB instance from the context if it exists.
@@ -45,6 +48,7 @@ func (db *DB) Transaction(ctx context.Context, fn func(txCtx context.Context) er
 		// Create a shallow clone for the nested transaction
 		nestedDB := *currentDB
 		txDB := &nestedDB
+		txDB.afterCommit = &[]func(){}
 
 		// Generate a unique sub-transaction ID for auditing
 		txID := "subtx_" + strings.ReplaceAll(uuid.NewString(), "-", "")
@@ -66,6 +70,10 @@ func (db *DB) Transaction(ctx context.Context, fn func(txCtx context.Context) er
 		if _, err := txDB.Exec(ctx, "RELEASE SAVEPOINT "+spName); err != nil {
 			return fmt.Errorf("orm: failed to release savepoint: %w", err)
 		}
+		// Callbacks from a released savepoint now depend on the outer commit.
+		for _, cb := range *txDB.afterCommit {
+			currentDB.AfterCommit(cb)
+		}
 		return nil
 	}
 
@@ -79,11 +87,12 @@ func (db *DB) Transaction(ctx context.Context, fn func(txCtx context.Context) er
 	
 	// Create a new DB instance sharing the same dialect and auditor but using the transaction connection
 	txDB := &DB{
-		conn:    connTx,
-		dialect: db.dialect,
-		auditor: db.auditor,
-		pool:    db.pool,
-		inTx:    true,
+		conn:        connTx,
+		dialect:     db.dialect,
+		auditor:     db.auditor,
+		pool:        db.pool,
+		inTx:        true,
+		afterCommit: &[]func(){},
 	}
 
 	// Inject txDB and txID into context
@@ -102,5 +111,56 @@ func (db *DB) Transaction(ctx context.Context, fn func(txCtx context.Context) er
 		return err
 	}
 
-	return connTx.Commit()
+	if err := connTx.Commit(); err != nil {
+		return err
+	}
+	for _, cb := range *txDB.afterCommit {
+		cb()
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction ctx runs in commits, or right
+// away outside a transaction. fn receives ctx without the transaction, so
+// the queries it makes use the pool:
+//
+//	err := db.Transaction(ctx, func(txCtx context.Context) error {
+//	    order, err := database.Query[Order](db, txCtx).Create(order)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    database.AfterCommit(txCtx, func(ctx context.Context) {
+//	        _ = mailer.Send(ctx, receipt(order))
+//	    })
+//	    return nil
+//	})
+//
+// Nothing is sent when the transaction rolls back.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if db, ok := FromContext(ctx); ok && db != nil {
+		db.AfterCommit(func() { fn(withoutTx(ctx)) })
+		return
+	}
+	fn(ctx)
+}
+
+// withoutTx returns ctx no longer bound to a transaction.
+func withoutTx(ctx context.Context) context.Context {
+	return context.WithValue(txhook.Detach(ctx), txKey{}, nil)
+}
+
+// InTransaction reports whether db runs inside db.Transaction.
+func (db *DB) InTransaction() bool {
+	return db.inTx
+}
+
+// AfterCommit registers fn to run once the transaction db belongs to has
+// committed. Callbacks are dropped if the transaction (or the savepoint they
+// were registered in) rolls back. Outside db.Transaction fn runs immediately.
+func (db *DB) AfterCommit(fn func()) {
+	if !db.inTx || db.afterCommit == nil {
+		fn()
+		return
+	}
+	*db.afterCommit = append(*db.afterCommit, fn)
 }
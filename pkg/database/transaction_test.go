@@ -4,7 +4,10 @@ import (
 	"context"
 	"testing"
 
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	"github.com/shauryagautam/Astra/pkg/support/txhook"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTransaction_ContextAware(t *testing.T) {
@@ -93,3 +96,49 @@ func TestTransaction_ContextAware(t *testing.T) {
 		assert.Equal(t, int64(0), count)
 	})
 }
+
+func TestAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(ctx, "CREATE TABLE observed_posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	require.NoError(t, err)
+
+	var sent []string
+	var created []string
+	events := event.New()
+	SetEvents(events)
+	t.Cleanup(func() { SetEvents(event.New()) })
+	events.OnFunc(ModelEventName("observed_posts", ModelCreated), func(ctx context.Context, e event.Event) error {
+		_, inTx := FromContext(ctx)
+		assert.False(t, inTx, "listeners run outside the transaction")
+		created = append(created, e.Data().(*ModelEvent).Model.(*observedPost).Title)
+		return nil
+	})
+
+	save := func(title string, fail bool) error {
+		return db.Transaction(ctx, func(txCtx context.Context) error {
+			if _, err := Query[observedPost](db, txCtx).Create(&observedPost{Title: title}); err != nil {
+				return err
+			}
+			AfterCommit(txCtx, func(ctx context.Context) {
+				assert.False(t, txhook.InTransaction(ctx))
+				sent = append(sent, title)
+			})
+			assert.Empty(t, created, "model events wait for the commit")
+			if fail {
+				return assert.AnError
+			}
+			return nil
+		})
+	}
+
+	assert.ErrorIs(t, save("rolled back", true), assert.AnError)
+	require.NoError(t, save("committed", false))
+	assert.Equal(t, []string{"committed"}, sent)
+	assert.Equal(t, []string{"committed"}, created)
+
+	AfterCommit(ctx, func(context.Context) { sent = append(sent, "no transaction") })
+	assert.Equal(t, []string{"committed", "no transaction"}, sent)
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Document struct {
+	Model
+	Versioned
+	Title string `orm:"column:title"`
+}
+
+func (d *Document) TableName() string {
+	return "documents"
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Driver: "sqlite", DSN: ":memory:"})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(ctx, "CREATE TABLE documents (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, version INTEGER NOT NULL DEFAULT 1, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)")
+	assert.NoError(t, err)
+
+	doc, err := Query[Document](db).Create(&Document{Title: "draft"}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), doc.Version)
+
+	first, _ := Query[Document](db).FindByID(doc.ID, ctx)
+	second, _ := Query[Document](db).FindByID(doc.ID, ctx)
+
+	first.Title = "first editor"
+	assert.NoError(t, Query[Document](db).Save(first, ctx))
+	assert.Equal(t, int64(2), first.Version)
+
+	second.Title = "second editor"
+	err = Query[Document](db).Save(second, ctx)
+	assert.True(t, errors.Is(err, ErrStaleModel))
+
+	var stale *StaleModelError
+	assert.True(t, errors.As(err, &stale))
+	assert.Equal(t, int64(1), stale.Version)
+
+	stored, _ := Query[Document](db).FindByID(doc.ID, ctx)
+	assert.Equal(t, "first editor", stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+}
@@ -4,13 +4,12 @@ import (
 	"context"
 	"errors"
 	"log/slog"
-	"os"
-	"os/signal"
+	"slices"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
 )
 
 // App is the pure Lifecycle Manager of the Astra framework.
@@ -21,6 +20,7 @@ type App struct {
 	config    *config.AstraConfig
 	env       *config.Config
 	logger    *slog.Logger
+	events    *event.Emitter
 
 	providers []Provider
 	ctx       context.Context
@@ -30,6 +30,10 @@ type App struct {
 	onStop  []func(context.Context) error
 
 	healthChecks map[string]HealthProvider
+
+	environment Environment
+	manifests   []Manifest
+	booted      bool
 }
 
 // New creates a new Astra application kernel with minimal core dependencies.
@@ -38,11 +42,14 @@ func New(
 	env *config.Config,
 	logger *slog.Logger,
 ) *App {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	// Run is the only place that listens for signals, so one Ctrl+C is
+	// never seen twice; Shutdown cancels the context.
+	ctx, cancel := context.WithCancel(context.Background())
 	return &App{
 		config:       config,
 		env:          env,
 		logger:       logger,
+		events:       event.New(),
 		ctx:          ctx,
 		cancel:       cancel,
 		providers:    make([]Provider, 0),
@@ -61,7 +68,8 @@ func (a *App) Env() *config.Config { return a.env }
 // Logger returns the application logger.
 func (a *App) Logger() *slog.Logger { return a.logger }
 
-// BaseContext returns the application's base context.
+// BaseContext returns the application's base context. It is canceled when
+// Shutdown runs, which Run does on SIGINT or SIGTERM.
 func (a *App) BaseContext() context.Context { return a.ctx }
 
 // OnStart registers a hook to run when the app boots.
@@ -80,36 +88,28 @@ func (a *App) OnStop(fn func(context.Context) error) {
 	a.onStop = append(a.onStop, fn)
 }
 
-// Run boots the application and blocks until a termination signal is received.
-// It handles the full lifecycle from Boot to Graceful Shutdown.
-func (a *App) Run() error {
-	if err := a.Boot(); err != nil {
-		return err
-	}
-	
-	a.logger.Info("Astra kernel is running. Press Ctrl+C to stop.")
-	<-a.BaseContext().Done()
-	
-	a.logger.Info("Shutdown signal received. Cleaning up...")
-	return a.Shutdown()
-}
-
 // Shutdown gracefully stops the application.
-// It executes onStop hooks and provider shutdown methods in reverse order of registration.
+// It dispatches AppShuttingDown, then executes onStop hooks and provider shutdown methods in reverse order of registration.
 // Aggregates all errors encountered using errors.Join for a single cohesive return.
-// It uses a fresh 15-second timeout context to guarantee termination.
+// Hooks get a fresh context bounded by APP_SHUTDOWN_TIMEOUT (default 15s).
 func (a *App) Shutdown() error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	a.cancel()
-
 	// Hardened Shutdown Protection: fresh context to ensure cleanup completes even if base ctx is canceled
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout())
 	defer cancel()
 
 	var errs []error
 
+	// Listeners run before the lock is taken so they may use the App.
+	if err := a.events.Dispatch(ctx, AppShuttingDown{App: a}); err != nil {
+		a.logger.Error("shutdown listener failed", "error", err)
+		errs = append(errs, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cancel()
+
 	// Execute onStop hooks in reverse order (LIFO)
 	for i := len(a.onStop) - 1; i >= 0; i-- {
 		if err := a.onStop[i](ctx); err != nil {
@@ -169,43 +169,81 @@ func (a *App) RegisterProvider(p Provider) {
 	a.providers = append(a.providers, p)
 }
 
-// Boot initializes all registered providers in a strict three-phase sequence:
-// Register → Boot → Ready. The Ready phase only executes once all providers
-// have completed their Boot phase. OnStart hooks are wrapped in a 30s timeout.
+// Boot audits the configuration (see audit), then initializes all registered
+// providers in a strict three-phase sequence: Register → Boot → Ready. The
+// Ready phase only executes once all providers have completed their Boot
+// phase. Manifest providers are filtered by Environment and appended to the
+// directly registered ones, and manifest preloads run after Ready. OnStart
+// hooks are wrapped in a 30s timeout.
+// The lifecycle events in lifecycle.go are dispatched between the steps.
+//
+// The lock is not held while providers run, so they may register hooks and
+// health checks on the App. Boot runs once: later calls return nil without
+// booting the providers again.
 func (a *App) Boot() error {
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	if a.booted {
+		a.mu.Unlock()
+		return nil
+	}
+	a.booted = true
+	preloads := a.applyManifests()
+	providers := slices.Clone(a.providers)
+	a.mu.Unlock()
+
+	if err := a.audit(); err != nil {
+		return err
+	}
+	if err := a.dispatch(AppBooting{App: a}); err != nil {
+		return err
+	}
 
 	// Phase 1: Register - All providers define their presence
-	for _, p := range a.providers {
+	for _, p := range providers {
 		if err := p.Register(a); err != nil {
 			return err
 		}
+		if err := a.dispatch(ProviderRegistered{App: a, Provider: p}); err != nil {
+			return err
+		}
 	}
 
 	// Phase 2: Boot - All providers perform initialization
-	for _, p := range a.providers {
+	for _, p := range providers {
 		if err := p.Boot(a); err != nil {
 			return err
 		}
 	}
+	if err := a.dispatch(AppBooted{App: a}); err != nil {
+		return err
+	}
 
 	// Phase 3: Ready - All providers confirm operational readiness
-	for _, p := range a.providers {
+	for _, p := range providers {
 		if err := p.Ready(a); err != nil {
 			return err
 		}
 	}
 
+	if err := runPreloads(a, preloads); err != nil {
+		return err
+	}
+	if err := a.dispatch(RoutesCommitted{App: a}); err != nil {
+		return err
+	}
+
 	// Startup Protection: Wrap OnStart hooks with a 30-second context timeout
 	ctx, cancel := context.WithTimeout(a.ctx, 30*time.Second)
 	defer cancel()
 
-	for _, fn := range a.onStart {
+	a.mu.RLock()
+	onStart := slices.Clone(a.onStart)
+	a.mu.RUnlock()
+	for _, fn := range onStart {
 		if err := fn(ctx); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return a.dispatch(AppReady{App: a})
 }
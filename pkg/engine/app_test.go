@@ -2,9 +2,16 @@ package engine_test
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"testing"
 
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
 	"github.com/shauryagautam/Astra/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestApp_Lifecycle(t *testing.T) {
@@ -47,3 +54,60 @@ func TestApp_Recover(t *testing.T) {
 	defer app.Recover()
 	// This test just ensures Recover doesn't panic itself or fail
 }
+
+func TestApp_LifecycleEvents(t *testing.T) {
+	var log []string
+	app := engine.New(&config.AstraConfig{}, &config.Config{}, slog.Default())
+	app.RegisterProvider(&recordingProvider{name: "db", log: &log})
+	app.UseManifest(engine.Manifest{Preloads: []engine.Preload{
+		{Name: "routes", Run: func(*engine.App) error { log = append(log, "preload routes"); return nil }},
+	}})
+
+	app.Events().OnFunc("*", func(ctx context.Context, e event.Event) error {
+		name := e.Name()
+		if ev, ok := e.(engine.ProviderRegistered); ok {
+			name += " " + ev.Provider.(*recordingProvider).name
+		}
+		log = append(log, name)
+		return nil
+	})
+
+	require.NoError(t, app.Boot())
+	require.NoError(t, app.Shutdown())
+	assert.Equal(t, []string{
+		engine.EventAppBooting,
+		engine.EventProviderRegistered + " db",
+		"boot db",
+		engine.EventAppBooted,
+		"preload routes",
+		engine.EventRoutesCommitted,
+		"start db",
+		engine.EventAppReady,
+		engine.EventAppShuttingDown,
+	}, log)
+}
+
+func TestApp_LifecycleListenerErrorAbortsBoot(t *testing.T) {
+	app := engine.New(&config.AstraConfig{}, &config.Config{}, slog.Default())
+	started := false
+	app.OnStart(func(context.Context) error { started = true; return nil })
+	app.Events().OnFunc(engine.EventAppBooted, func(context.Context, event.Event) error {
+		return errors.New("license check failed")
+	})
+
+	assert.EqualError(t, app.Boot(), "license check failed")
+	assert.False(t, started)
+}
+
+func TestApp_BootRefusesInsecureProduction(t *testing.T) {
+	cfg := &config.AstraConfig{App: config.AppConfig{Environment: "production", Debug: true}}
+
+	app := engine.New(cfg, &config.Config{}, slog.Default())
+	err := app.Boot()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "APP_KEY")
+	assert.ErrorContains(t, err, "APP_DEBUG")
+
+	t.Setenv(engine.SkipAuditEnv, "true")
+	require.NoError(t, engine.New(cfg, &config.Config{}, slog.Default()).Boot())
+}
@@ -0,0 +1,213 @@
+// Package bench load-tests the HTTP stack. It boots a router with
+// representative routes on an in-process server, drives it with
+// concurrent keep-alive clients, the way wrk or vegeta would, and reports
+// latency percentiles, throughput and allocations per request. The astra
+// bench command is built on it.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scenario is a request to send repeatedly.
+type Scenario struct {
+	Name   string
+	Method string
+	// Path is appended to the target URL.
+	Path string
+	// Body is sent with every request, as JSON.
+	Body string
+}
+
+// Options controls a run.
+type Options struct {
+	// Duration is how long each scenario runs. Default: 5s.
+	Duration time.Duration
+	// Requests, when positive, stops a scenario after that many requests
+	// instead of after Duration.
+	Requests int
+	// Concurrency is the number of clients sending requests at the same
+	// time. Default: 50.
+	Concurrency int
+	// Warmup is sent before measuring, to fill connection pools and
+	// caches. Default: 1s.
+	Warmup time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.Duration <= 0 {
+		o.Duration = 5 * time.Second
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 50
+	}
+	if o.Warmup < 0 {
+		o.Warmup = 0
+	} else if o.Warmup == 0 {
+		o.Warmup = time.Second
+	}
+	return o
+}
+
+// Result is the outcome of a scenario.
+type Result struct {
+	Scenario string        `json:"scenario"`
+	Requests int           `json:"requests"`
+	Errors   int           `json:"errors"`
+	Elapsed  time.Duration `json:"elapsed"`
+	// RPS is the throughput, in requests per second.
+	RPS float64       `json:"rps"`
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+	// AllocsPerReq and BytesPerReq are the heap allocations per request.
+	// They are only meaningful for an in-process target, and include the
+	// client's share.
+	AllocsPerReq float64 `json:"allocs_per_req"`
+	BytesPerReq  float64 `json:"bytes_per_req"`
+}
+
+// Run sends sc to target until opts.Duration elapses, opts.Requests are
+// sent or ctx is done. Responses with a status of 400 or more count as
+// errors.
+func Run(ctx context.Context, client *http.Client, target string, sc Scenario, opts Options) (Result, error) {
+	opts = opts.withDefaults()
+	if client == nil {
+		client = &http.Client{Transport: &http.Transport{
+			MaxIdleConns:        opts.Concurrency,
+			MaxIdleConnsPerHost: opts.Concurrency,
+		}}
+	}
+	method := sc.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	url := strings.TrimSuffix(target, "/") + sc.Path
+
+	send := func(ctx context.Context) error {
+		var body io.Reader
+		if sc.Body != "" {
+			body = strings.NewReader(sc.Body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return err
+		}
+		if sc.Body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("bench: %s %s: status %d", method, sc.Path, resp.StatusCode)
+		}
+		return nil
+	}
+
+	// Fail fast on a misconfigured scenario rather than reporting 100%
+	// errors.
+	if err := send(ctx); err != nil {
+		return Result{}, err
+	}
+	if opts.Warmup > 0 {
+		warm, cancel := context.WithTimeout(ctx, opts.Warmup)
+		drive(warm, opts.Concurrency, 0, send)
+		cancel()
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, opts.Duration)
+	if opts.Requests > 0 {
+		cancel()
+		runCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	latencies, errs := drive(runCtx, opts.Concurrency, opts.Requests, send)
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	res := Result{
+		Scenario: sc.Name,
+		Requests: len(latencies),
+		Errors:   errs,
+		Elapsed:  elapsed,
+	}
+	if res.Requests == 0 {
+		return res, nil
+	}
+	res.RPS = float64(res.Requests) / elapsed.Seconds()
+	slices.Sort(latencies)
+	res.P50 = percentile(latencies, 50)
+	res.P90 = percentile(latencies, 90)
+	res.P99 = percentile(latencies, 99)
+	res.Max = latencies[len(latencies)-1]
+	res.AllocsPerReq = float64(after.Mallocs-before.Mallocs) / float64(res.Requests)
+	res.BytesPerReq = float64(after.TotalAlloc-before.TotalAlloc) / float64(res.Requests)
+	return res, nil
+}
+
+// drive runs send from n goroutines until ctx is done or, when limit is
+// positive, limit calls were made. It returns the latency of every call
+// completed before ctx was done and the number that failed.
+func drive(ctx context.Context, n, limit int, send func(context.Context) error) ([]time.Duration, int) {
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		errs      int
+		issued    atomic.Int64
+		wg        sync.WaitGroup
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]time.Duration, 0, 1024)
+			failed := 0
+			for ctx.Err() == nil {
+				if limit > 0 && issued.Add(1) > int64(limit) {
+					break
+				}
+				start := time.Now()
+				err := send(ctx)
+				if ctx.Err() != nil {
+					break
+				}
+				local = append(local, time.Since(start))
+				if err != nil {
+					failed++
+				}
+			}
+			mu.Lock()
+			latencies = append(latencies, local...)
+			errs += failed
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return latencies, errs
+}
+
+// percentile returns the p-th percentile of sorted latencies, using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
@@ -0,0 +1,52 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_Scenarios(t *testing.T) {
+	srv, err := NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	var results []Result
+	for _, sc := range Scenarios() {
+		res, err := Run(context.Background(), nil, srv.URL, sc, Options{Requests: 200, Concurrency: 4, Warmup: -1})
+		require.NoError(t, err, sc.Name)
+		assert.Equal(t, 200, res.Requests, sc.Name)
+		assert.Zero(t, res.Errors, sc.Name)
+		assert.Positive(t, res.RPS, sc.Name)
+		assert.LessOrEqual(t, res.P50, res.P99, sc.Name)
+		assert.LessOrEqual(t, res.P99, res.Max, sc.Name)
+		results = append(results, res)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(&buf, results))
+	assert.Contains(t, buf.String(), "middleware")
+}
+
+func TestRun_FailsFastOnBadScenario(t *testing.T) {
+	srv, err := NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	_, err = Run(context.Background(), nil, srv.URL, Scenario{Name: "missing", Path: "/nope"}, Options{Duration: time.Second})
+	assert.ErrorContains(t, err, "status 404")
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	assert.Equal(t, time.Duration(50), percentile(sorted, 50))
+	assert.Equal(t, time.Duration(99), percentile(sorted, 99))
+	assert.Equal(t, time.Duration(1), percentile(sorted[:1], 99))
+}
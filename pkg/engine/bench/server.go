@@ -0,0 +1,106 @@
+package bench
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	astrahttp "github.com/shauryagautam/Astra/pkg/engine/http"
+
+	_ "modernc.org/sqlite"
+)
+
+// Scenarios returns the scenarios served by NewServer:
+//
+//   - static: a fixed plain-text response, the router's floor.
+//   - dynamic: a path parameter echoed back as JSON.
+//   - middleware: the dynamic route behind request ID, recovery, security
+//     headers, CORS and request logging.
+//   - db: a row read from SQLite by primary key and returned as JSON.
+func Scenarios() []Scenario {
+	return []Scenario{
+		{Name: "static", Path: "/static"},
+		{Name: "dynamic", Path: "/users/42"},
+		{Name: "middleware", Path: "/mw/users/42"},
+		{Name: "db", Path: "/db/users/42"},
+	}
+}
+
+// Server is an in-process server running the routes of Scenarios.
+type Server struct {
+	*httptest.Server
+	db *sql.DB
+}
+
+// NewServer boots the benchmark routes on a loopback port. Close it when
+// done.
+func NewServer() (*Server, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := astrahttp.NewRouter(&config.AstraConfig{}, logger)
+
+	r.Get("/static", func(c *astrahttp.Context) error {
+		return c.SendString("ok")
+	})
+	r.Get("/users/{id}", showUser)
+	r.Group("/mw", func(r *astrahttp.Router) {
+		r.Use(astrahttp.RequestID())
+		r.Use(astrahttp.Recover(logger))
+		r.Use(astrahttp.SecureHeaders(true))
+		r.Use(astrahttp.CORS(astrahttp.DefaultCors()))
+		r.Use(astrahttp.Logger(logger))
+		r.Get("/users/{id}", showUser)
+	})
+	r.Get("/db/users/{id}", func(c *astrahttp.Context) error {
+		var id int
+		var name, email string
+		err := db.QueryRowContext(c.Ctx(), "SELECT id, name, email FROM users WHERE id = ?", c.Param("id")).Scan(&id, &name, &email)
+		if err != nil {
+			return err
+		}
+		return c.JSON(map[string]any{"id": id, "name": name, "email": email})
+	})
+
+	return &Server{Server: httptest.NewServer(r), db: db}, nil
+}
+
+// Close stops the server and closes its database.
+func (s *Server) Close() {
+	s.Server.Close()
+	_ = s.db.Close()
+}
+
+func showUser(c *astrahttp.Context) error {
+	return c.JSON(map[string]any{"id": c.Param("id"), "name": "Ada Lovelace"})
+}
+
+// openDB creates an in-memory SQLite database seeded with users. It keeps a
+// single connection, since every connection to ":memory:" is a separate
+// database.
+func openDB() (*sql.DB, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("bench: failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	stmts := []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, email TEXT NOT NULL)",
+		"WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 1000) " +
+			"INSERT INTO users (id, name, email) SELECT n, 'user ' || n, 'user' || n || '@example.com' FROM seq",
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("bench: failed to seed database: %w", err)
+		}
+	}
+	return db, nil
+}
@@ -0,0 +1,108 @@
+// Package buildinfo reports the version a binary was built from. astra build
+// stamps the variables below through -ldflags -X; binaries built any other
+// way fall back to the module and VCS data Go records on its own.
+//
+//	info := buildinfo.Read()
+//	log.Info("starting", "version", info.Version, "commit", info.Commit)
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Stamped at link time by astra build. Leave them unset in source.
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit,omitempty"`
+	Date      time.Time `json:"date,omitzero"`
+	Modified  bool      `json:"modified,omitempty"`
+	GoVersion string    `json:"go_version"`
+	Platform  string    `json:"platform"`
+}
+
+// Read returns the stamped build information, filling anything that was not
+// stamped from debug.ReadBuildInfo. Version is "dev" when nothing is known.
+func Read() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if Date != "" {
+		info.Date, _ = time.Parse(time.RFC3339, Date)
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date.IsZero() {
+					info.Date, _ = time.Parse(time.RFC3339, s.Value)
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// String formats info on one line, e.g.
+// "v1.4.0 (3f2c1ab, 2026-03-01T10:00:00Z) go1.26.2 linux/amd64".
+func (i Info) String() string {
+	var details []string
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		if i.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, commit)
+	}
+	if !i.Date.IsZero() {
+		details = append(details, i.Date.UTC().Format(time.RFC3339))
+	}
+	s := i.Version
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+	return fmt.Sprintf("%s %s %s", s, i.GoVersion, i.Platform)
+}
+
+// LDFlags returns the -ldflags value that stamps version, commit and date
+// into this package, with symbol tables stripped for a smaller binary.
+func LDFlags(version, commit string, date time.Time) string {
+	const pkg = "github.com/shauryagautam/Astra/pkg/engine/buildinfo"
+	flags := []string{"-s", "-w", "-X", pkg + ".Version=" + version}
+	if commit != "" {
+		flags = append(flags, "-X", pkg+".Commit="+commit)
+	}
+	if !date.IsZero() {
+		flags = append(flags, "-X", pkg+".Date="+date.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(flags, " ")
+}
@@ -0,0 +1,30 @@
+package buildinfo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	t.Cleanup(func() { Version, Commit, Date = "", "", "" })
+
+	assert.NotEmpty(t, Read().Version)
+
+	Version, Commit, Date = "v1.4.0", "3f2c1ab9e0d4", "2026-03-01T10:00:00Z"
+	info := Read()
+	assert.Equal(t, "v1.4.0", info.Version)
+	assert.Equal(t, "3f2c1ab9e0d4", info.Commit)
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), info.Date.UTC())
+	assert.Contains(t, info.String(), "v1.4.0 (3f2c1ab")
+	assert.Contains(t, info.String(), "2026-03-01T10:00:00Z)")
+}
+
+func TestLDFlags(t *testing.T) {
+	const pkg = "github.com/shauryagautam/Astra/pkg/engine/buildinfo"
+	assert.Equal(t, "-s -w -X "+pkg+".Version=dev", LDFlags("dev", "", time.Time{}))
+	assert.Equal(t,
+		"-s -w -X "+pkg+".Version=v2 -X "+pkg+".Commit=abc -X "+pkg+".Date=2026-03-01T10:00:00Z",
+		LDFlags("v2", "abc", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)))
+}
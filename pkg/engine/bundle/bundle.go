@@ -0,0 +1,75 @@
+// Package bundle lets an application ship as a single static binary by
+// embedding its views, migrations and public assets with go:embed, while
+// still editing them live on disk during development:
+//
+//	//go:embed views public database/migrations
+//	var files embed.FS
+//
+//	res := bundle.New(files, app.Env().IsDev())
+//	views := http.NewTemplateEngine("views", http.WithFS(res.Dir("views")), http.WithDevMode(app.Env().IsDev()))
+//	router.Static("/public", res.Dir("public"))
+//	runner := migration.NewRunner(sqlDB, "database/migrations", res.Dir("database/migrations"))
+//	publicDisk := storage.NewFSStorage(res.Dir("public"), "/public")
+//
+// In development Dir serves the directory from disk so edits show up without
+// a rebuild; in production it serves the copy compiled into the binary.
+package bundle
+
+import (
+	"io/fs"
+	"os"
+	"path"
+)
+
+// Bundle resolves resource directories against disk or an embedded FS.
+type Bundle struct {
+	embedded   fs.FS
+	preferDisk bool
+}
+
+// New creates a Bundle over embedded. With preferDisk (typically in
+// development) directories that exist on disk win over the embedded copy.
+// embedded may be nil for applications that do not embed anything.
+func New(embedded fs.FS, preferDisk bool) *Bundle {
+	return &Bundle{embedded: embedded, preferDisk: preferDisk}
+}
+
+// Dir returns the resource directory name, a slash-separated path relative
+// to the application root such as "views" or "database/migrations".
+//
+// The embedded copy is used unless disk is preferred and the directory exists
+// there, or nothing was embedded under name. The result is never nil; a
+// directory found nowhere yields an FS whose reads fail with fs.ErrNotExist.
+func (b *Bundle) Dir(name string) fs.FS {
+	name = path.Clean(name)
+	if b.preferDisk && onDisk(name) {
+		return os.DirFS(name)
+	}
+	if b.embedded != nil {
+		if info, err := fs.Stat(b.embedded, name); err == nil && info.IsDir() {
+			sub, err := fs.Sub(b.embedded, name)
+			if err == nil {
+				return sub
+			}
+		}
+	}
+	return os.DirFS(name)
+}
+
+// Embedded reports whether Dir(name) is served from the binary.
+func (b *Bundle) Embedded(name string) bool {
+	name = path.Clean(name)
+	if b.preferDisk && onDisk(name) {
+		return false
+	}
+	if b.embedded == nil {
+		return false
+	}
+	info, err := fs.Stat(b.embedded, name)
+	return err == nil && info.IsDir()
+}
+
+func onDisk(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.IsDir()
+}
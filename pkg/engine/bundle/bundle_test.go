@@ -0,0 +1,44 @@
+package bundle
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join("views", "layouts"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join("views", "home.html"), []byte("disk"), 0o600))
+
+	embedded := fstest.MapFS{
+		"views/home.html":                  {Data: []byte("embedded")},
+		"database/migrations/001_init.sql": {Data: []byte("CREATE TABLE t (id int);")},
+	}
+
+	read := func(fsys fs.FS, name string) string {
+		t.Helper()
+		data, err := fs.ReadFile(fsys, name)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	dev := New(embedded, true)
+	assert.Equal(t, "disk", read(dev.Dir("views"), "home.html"))
+	assert.False(t, dev.Embedded("views"))
+	// Not on disk, so even development falls back to the binary.
+	assert.Equal(t, "CREATE TABLE t (id int);", read(dev.Dir("database/migrations"), "001_init.sql"))
+
+	prod := New(embedded, false)
+	assert.Equal(t, "embedded", read(prod.Dir("views"), "home.html"))
+	assert.True(t, prod.Embedded("views"))
+
+	_, err := fs.ReadFile(prod.Dir("public"), "app.css")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.NotNil(t, New(nil, false).Dir("views"))
+}
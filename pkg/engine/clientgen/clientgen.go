@@ -0,0 +1,657 @@
+// Package clientgen writes a typed TypeScript client for the routes found
+// by routegen. It backs the astra generate:client command:
+//
+//	// @route POST /users
+//	// @name users.store
+//	// @response UserResource
+//	func (uc *UserController) Store(c *http.Context) error {
+//		input := http.Validated[CreateUserInput](c)
+//		...
+//	}
+//
+// becomes a usersStore(body: CreateUserInput) method returning
+// Promise<UserResource>, with interfaces generated from the Go structs. The
+// request and response types come from routegen: the @request and
+// @response annotations, or the type the handler validates its body into.
+// A response type implementing http.Transformer is described by the type
+// its Transform method returns, and a string type declared with enum.New by
+// the union of its values.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/engine/routegen"
+	"github.com/shauryagautam/Astra/pkg/support/str"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(\.\.\.)?\}`)
+
+// typeDecl is a type declaration and the file it is declared in, whose
+// imports qualify the types it refers to.
+type typeDecl struct {
+	spec       *ast.TypeSpec
+	file       *ast.File
+	importPath string
+	// transform is the type returned by the type's Transform method.
+	transform ast.Expr
+	// enum lists the values of a string type declared with enum.New.
+	enum []string
+}
+
+// generator resolves Go types to TypeScript and collects the interfaces to
+// emit.
+type generator struct {
+	root, module string
+	fset         *token.FileSet
+	packages     map[string]map[string]*typeDecl
+	// names maps a type to its TypeScript name; emitted lists the
+	// interfaces to write, and bodies their members.
+	names   map[routegen.TypeRef]string
+	taken   map[string]bool
+	emitted []string
+	bodies  map[string]string
+}
+
+// Generate renders a TypeScript module exporting the interfaces of the
+// routes' request and response types and a createClient function with one
+// method per route. root is the module's directory and module its path.
+func Generate(root, module string, routes []routegen.Route) ([]byte, error) {
+	g := &generator{
+		root:     root,
+		module:   module,
+		fset:     token.NewFileSet(),
+		packages: map[string]map[string]*typeDecl{},
+		names:    map[routegen.TypeRef]string{},
+		taken:    map[string]bool{},
+		bodies:   map[string]string{},
+	}
+
+	routes = slices.Clone(routes)
+	slices.SortFunc(routes, func(a, b routegen.Route) int {
+		return strings.Compare(a.Path+" "+a.Method, b.Path+" "+b.Method)
+	})
+
+	methods := methodNames(routes)
+	var client bytes.Buffer
+	for i, r := range routes {
+		request, err := g.refType(r.Request)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Pos, err)
+		}
+		response, err := g.refType(r.Response)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Pos, err)
+		}
+		writeMethod(&client, methods[i], r, request, response)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by astra generate:client. DO NOT EDIT.\n\n")
+	slices.Sort(g.emitted)
+	for _, name := range g.emitted {
+		fmt.Fprintf(&buf, "export interface %s %s\n\n", name, g.bodies[name])
+	}
+	buf.WriteString(runtime)
+	buf.WriteString("\nexport function createClient(options: ClientOptions = {}) {\n")
+	buf.WriteString("  const request = requester(options);\n  return {\n")
+	buf.Write(client.Bytes())
+	buf.WriteString("  };\n}\n\nexport type Client = ReturnType<typeof createClient>;\n")
+	return buf.Bytes(), nil
+}
+
+// methodNames names the client method of each route: its @name, else the
+// controller and handler ("UserController.Show" is userShow). Routes that
+// would share a name get their HTTP method appended.
+func methodNames(routes []routegen.Route) []string {
+	names := make([]string, len(routes))
+	count := map[string]int{}
+	for i, r := range routes {
+		name := r.Name
+		if name == "" {
+			name = strings.TrimSuffix(r.Receiver, "Controller") + " " + r.Handler
+		}
+		names[i] = str.Camel(name)
+		count[names[i]]++
+	}
+	for i, r := range routes {
+		if count[names[i]] > 1 {
+			names[i] += str.Pascal(strings.ToLower(r.Method))
+		}
+	}
+	return names
+}
+
+// writeMethod writes the client method of route r.
+func writeMethod(buf *bytes.Buffer, name string, r routegen.Route, request, response string) {
+	var args []string
+	params := pathParamPattern.FindAllStringSubmatch(r.Path, -1)
+	if len(params) > 0 {
+		fields := make([]string, len(params))
+		for i, p := range params {
+			fields[i] = fmt.Sprintf("%s: string | number", tsKey(p[1]))
+		}
+		args = append(args, "params: { "+strings.Join(fields, "; ")+" }")
+	}
+	body := "undefined"
+	if request != "" {
+		args = append(args, "body: "+request)
+		body = "body"
+	}
+	args = append(args, "init?: RequestOptions")
+	if response == "" {
+		response = "unknown"
+	}
+
+	urlPath := strconv.Quote(r.Path)
+	if len(params) > 0 {
+		urlPath = "`" + pathParamPattern.ReplaceAllStringFunc(r.Path, func(m string) string {
+			p := pathParamPattern.FindStringSubmatch(m)
+			if p[2] != "" {
+				return "${encodePath(params." + p[1] + ")}"
+			}
+			return "${encodeURIComponent(String(params." + p[1] + "))}"
+		}) + "`"
+	}
+
+	fmt.Fprintf(buf, "    /** %s %s */\n", r.Method, r.Path)
+	fmt.Fprintf(buf, "    %s: (%s) =>\n", name, strings.Join(args, ", "))
+	fmt.Fprintf(buf, "      request<%s>(%q, %s, %s, init),\n", response, r.Method, urlPath, body)
+}
+
+// refType returns the TypeScript type for ref, emitting its interface, or
+// "" for nil.
+func (g *generator) refType(ref *routegen.TypeRef) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+	decl, err := g.lookup(*ref)
+	if err != nil {
+		return "", err
+	}
+	if decl == nil && strings.HasPrefix(ref.ImportPath+"/", g.module+"/") {
+		return "", fmt.Errorf("clientgen: type %s not found in %s", ref.Name, ref.ImportPath)
+	}
+	return g.named(*ref)
+}
+
+// named returns the TypeScript name of a type declared in the module,
+// emitting its interface the first time.
+func (g *generator) named(ref routegen.TypeRef) (string, error) {
+	if name, ok := g.names[ref]; ok {
+		return name, nil
+	}
+	decl, err := g.lookup(ref)
+	if err != nil {
+		return "", err
+	}
+	if decl == nil {
+		return "unknown", nil
+	}
+	if decl.transform != nil {
+		return g.expr(decl.file, decl.importPath, decl.transform)
+	}
+	if decl.enum != nil {
+		values := make([]string, len(decl.enum))
+		for i, v := range decl.enum {
+			values[i] = strconv.Quote(v)
+		}
+		return strings.Join(values, " | "), nil
+	}
+
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok || decl.spec.TypeParams != nil {
+		// Named non-struct types are written as their underlying type.
+		return g.expr(decl.file, decl.importPath, decl.spec.Type)
+	}
+
+	name := ref.Name
+	if g.taken[name] {
+		name = str.Pascal(path.Base(ref.ImportPath)) + ref.Name
+	}
+	g.taken[name] = true
+	g.names[ref] = name
+	g.emitted = append(g.emitted, name)
+
+	body, err := g.structBody(decl.file, decl.importPath, st)
+	if err != nil {
+		return "", err
+	}
+	g.bodies[name] = body
+	return name, nil
+}
+
+// structBody returns the TypeScript object type of a struct, following its
+// json tags. Fields are optional when tagged omitempty or omitzero, or when
+// they are validated but not required.
+func (g *generator) structBody(f *ast.File, importPath string, st *ast.StructType) (string, error) {
+	var fields, extends []string
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw)
+		}
+		jsonName, opts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" && opts == "" {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened by
+		// encoding/json, so the interface extends theirs.
+		if len(field.Names) == 0 && jsonName == "" {
+			embedded := field.Type
+			if star, ok := embedded.(*ast.StarExpr); ok {
+				embedded = star.X
+			}
+			typ, err := g.expr(f, importPath, embedded)
+			if err != nil {
+				return "", err
+			}
+			if identPattern.MatchString(typ) && typ != "unknown" {
+				extends = append(extends, typ)
+			}
+			continue
+		}
+
+		typ, err := g.expr(f, importPath, field.Type)
+		if err != nil {
+			return "", err
+		}
+		optional := strings.Contains(","+opts+",", ",omitempty,") || strings.Contains(","+opts+",", ",omitzero,")
+		if rules := tag.Get("validate"); rules != "" && !slices.Contains(strings.Split(rules, ","), "required") {
+			optional = true
+		}
+		mark := ""
+		if optional {
+			mark = "?"
+		}
+		if jsonName != "" {
+			fields = append(fields, fmt.Sprintf("%s%s: %s", tsKey(jsonName), mark, typ))
+			continue
+		}
+		for _, n := range field.Names {
+			if n.IsExported() {
+				fields = append(fields, fmt.Sprintf("%s%s: %s", tsKey(n.Name), mark, typ))
+			}
+		}
+	}
+
+	var b strings.Builder
+	if len(extends) > 0 {
+		b.WriteString("extends " + strings.Join(extends, ", ") + " ")
+	}
+	b.WriteString("{\n")
+	for _, field := range fields {
+		fmt.Fprintf(&b, "  %s;\n", field)
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+// expr converts a Go type expression in file f to TypeScript.
+func (g *generator) expr(f *ast.File, importPath string, e ast.Expr) (string, error) {
+	switch t := e.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string", nil
+		case "bool":
+			return "boolean", nil
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+			"float32", "float64", "byte", "rune", "uintptr":
+			return "number", nil
+		case "any", "error":
+			return "unknown", nil
+		}
+		return g.named(routegen.TypeRef{ImportPath: importPath, Name: t.Name})
+	case *ast.StarExpr:
+		inner, err := g.expr(f, importPath, t.X)
+		if err != nil || inner == "unknown" {
+			return inner, err
+		}
+		return inner + " | null", nil
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return "string", nil // encoding/json writes []byte as base64
+		}
+		inner, err := g.expr(f, importPath, t.Elt)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(inner, " ") {
+			inner = "(" + inner + ")"
+		}
+		return inner + "[]", nil
+	case *ast.MapType:
+		val, err := g.expr(f, importPath, t.Value)
+		if err != nil {
+			return "", err
+		}
+		return "Record<string, " + val + ">", nil
+	case *ast.StructType:
+		return g.structBody(f, importPath, t)
+	case *ast.InterfaceType:
+		return "unknown", nil
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "unknown", nil
+		}
+		switch pkg.Name + "." + t.Sel.Name {
+		case "time.Time", "uuid.UUID", "decimal.Decimal":
+			return "string", nil
+		case "time.Duration":
+			return "number", nil
+		case "json.RawMessage":
+			return "unknown", nil
+		}
+		if p := importedAs(f, pkg.Name); p != "" {
+			return g.named(routegen.TypeRef{ImportPath: p, Name: t.Sel.Name})
+		}
+	}
+	return "unknown", nil
+}
+
+// lookup returns the declaration of ref, or nil when it is not declared in
+// the module.
+func (g *generator) lookup(ref routegen.TypeRef) (*typeDecl, error) {
+	if ref.ImportPath != g.module && !strings.HasPrefix(ref.ImportPath, g.module+"/") {
+		return nil, nil
+	}
+	decls, ok := g.packages[ref.ImportPath]
+	if !ok {
+		var err error
+		if decls, err = g.parsePackage(ref.ImportPath); err != nil {
+			return nil, err
+		}
+		g.packages[ref.ImportPath] = decls
+	}
+	return decls[ref.Name], nil
+}
+
+// parsePackage indexes the type declarations of a package in the module,
+// the results of their Transform methods and the values of enums declared
+// with enum.New.
+func (g *generator) parsePackage(importPath string) (map[string]*typeDecl, error) {
+	dir := filepath.Join(g.root, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(importPath, g.module), "/")))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	decls := map[string]*typeDecl{}
+	transforms := map[string]struct {
+		file *ast.File
+		fn   *ast.FuncDecl
+	}{}
+	consts := map[string]enumConst{}
+	var enums []enumDecl
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						decls[spec.Name.Name] = &typeDecl{spec: spec, file: f, importPath: importPath}
+					case *ast.ValueSpec:
+						if d.Tok == token.CONST {
+							collectConsts(spec, consts)
+						} else {
+							enums = append(enums, enumCalls(f, spec)...)
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil && d.Name.Name == "Transform" && len(d.Type.Params.List) == 0 {
+					transforms[receiverName(d.Recv.List[0].Type)] = struct {
+						file *ast.File
+						fn   *ast.FuncDecl
+					}{f, d}
+				}
+			}
+		}
+	}
+	for typ, t := range transforms {
+		if decl, ok := decls[typ]; ok {
+			decl.transform = transformResult(t.fn)
+		}
+	}
+	for _, e := range enums {
+		if typ, values, ok := e.resolve(consts); ok {
+			if decl, ok := decls[typ]; ok {
+				decl.enum = values
+			}
+		}
+	}
+	return decls, nil
+}
+
+// enumConst is a typed string constant, a candidate enum value.
+type enumConst struct {
+	typ, value string
+}
+
+// collectConsts records the string constants of spec that declare a type.
+func collectConsts(spec *ast.ValueSpec, consts map[string]enumConst) {
+	typ, ok := spec.Type.(*ast.Ident)
+	if !ok || len(spec.Values) != len(spec.Names) {
+		return
+	}
+	for i, name := range spec.Names {
+		if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			v, _ := strconv.Unquote(lit.Value)
+			consts[name.Name] = enumConst{typ: typ.Name, value: v}
+		}
+	}
+}
+
+// enumDecl is a call to enum.New: its type argument, if given, and its
+// arguments.
+type enumDecl struct {
+	typ  string
+	args []ast.Expr
+}
+
+// enumCalls returns the enum.New calls initializing the variables of spec.
+func enumCalls(f *ast.File, spec *ast.ValueSpec) []enumDecl {
+	var calls []enumDecl
+	for _, v := range spec.Values {
+		call, ok := v.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		fun, typ := call.Fun, ""
+		if idx, ok := fun.(*ast.IndexExpr); ok {
+			fun = idx.X
+			if id, ok := idx.Index.(*ast.Ident); ok {
+				typ = id.Name
+			}
+		}
+		sel, ok := fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "New" {
+			continue
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && importedAs(f, pkg.Name) == enumImportPath {
+			calls = append(calls, enumDecl{typ: typ, args: call.Args})
+		}
+	}
+	return calls
+}
+
+// resolve returns the enum's type and values. Values are string literals
+// or constants of the package; any other argument leaves the type as its
+// underlying string.
+func (e enumDecl) resolve(consts map[string]enumConst) (string, []string, bool) {
+	typ := e.typ
+	values := make([]string, 0, len(e.args))
+	for _, arg := range e.args {
+		switch a := arg.(type) {
+		case *ast.BasicLit:
+			if a.Kind != token.STRING {
+				return "", nil, false
+			}
+			v, _ := strconv.Unquote(a.Value)
+			values = append(values, v)
+		case *ast.Ident:
+			c, ok := consts[a.Name]
+			if !ok || (typ != "" && c.typ != typ) {
+				return "", nil, false
+			}
+			typ = c.typ
+			values = append(values, c.value)
+		default:
+			return "", nil, false
+		}
+	}
+	return typ, values, typ != "" && len(values) > 0
+}
+
+// enumImportPath is the package whose New declares an enum.
+const enumImportPath = "github.com/shauryagautam/Astra/pkg/support/enum"
+
+// importedAs returns the import path file f refers to as name.
+func importedAs(f *ast.File, name string) string {
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		alias := path.Base(p)
+		if imp.Name != nil {
+			alias = imp.Name.Name
+		}
+		if alias == name {
+			return p
+		}
+	}
+	return ""
+}
+
+// transformResult returns the type a Transform method returns: its declared
+// result when that is not any, else the type of the composite literal in
+// its first return statement. Anything else, such as a map, is written as
+// unknown.
+func transformResult(fn *ast.FuncDecl) ast.Expr {
+	if res := fn.Type.Results; res != nil && len(res.List) == 1 {
+		declared := res.List[0].Type
+		_, isIface := declared.(*ast.InterfaceType)
+		if !isIdentNamed(declared, "any") && !isIface {
+			return declared
+		}
+	}
+	var result ast.Expr = &ast.InterfaceType{Methods: &ast.FieldList{}}
+	if fn.Body == nil {
+		return result
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return true
+		}
+		e := ret.Results[0]
+		if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			e = u.X
+		}
+		if lit, ok := e.(*ast.CompositeLit); ok && lit.Type != nil {
+			result = lit.Type
+		}
+		return false
+	})
+	return result
+}
+
+func isIdentNamed(expr ast.Expr, name string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+var identPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsKey quotes a property name when it is not a valid identifier.
+func tsKey(name string) string {
+	if identPattern.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// runtime is the fetch wrapper the generated methods call.
+const runtime = `export interface ClientOptions {
+  /** Prefix of every request URL, e.g. "https://api.example.com". */
+  baseURL?: string;
+  /** Headers sent with every request, e.g. an Authorization header. */
+  headers?: HeadersInit;
+  /** fetch implementation; defaults to the global fetch. */
+  fetch?: typeof fetch;
+}
+
+export type RequestOptions = Omit<RequestInit, "method" | "body">;
+
+/** ApiError is thrown for responses with a non-2xx status. */
+export class ApiError extends Error {
+  constructor(
+    public readonly status: number,
+    public readonly body: unknown,
+  ) {
+    super(` + "`request failed with status ${status}`" + `);
+  }
+}
+
+function encodePath(value: string | number): string {
+  return String(value).split("/").map(encodeURIComponent).join("/");
+}
+
+function requester(options: ClientOptions) {
+  const doFetch = options.fetch ?? fetch;
+  return async function request<T>(method: string, path: string, body: unknown, init?: RequestOptions): Promise<T> {
+    let url = (options.baseURL ?? "") + path;
+    const headers = new Headers(options.headers);
+    new Headers(init?.headers).forEach((value, key) => headers.set(key, value));
+    headers.set("Accept", "application/json");
+    let payload: string | undefined;
+    if (body !== undefined && (method === "GET" || method === "DELETE")) {
+      const query = new URLSearchParams();
+      for (const [key, value] of Object.entries(body as Record<string, unknown>)) {
+        if (value !== undefined && value !== null) query.append(key, String(value));
+      }
+      const qs = query.toString();
+      if (qs) url += "?" + qs;
+    } else if (body !== undefined) {
+      headers.set("Content-Type", "application/json");
+      payload = JSON.stringify(body);
+    }
+    const res = await doFetch(url, { ...init, method, headers, body: payload });
+    const text = await res.text();
+    const data = text ? JSON.parse(text) : undefined;
+    if (!res.ok) throw new ApiError(res.status, data);
+    return data as T;
+  };
+}
+`
@@ -0,0 +1,150 @@
+package clientgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/routegen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestGenerate(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app/controllers/users.go"), `package controllers
+
+import (
+	"example.com/app/app/dto"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+type UserController struct{}
+
+// @route GET /users/:id
+// @response dto.User
+func (uc *UserController) Show(c *http.Context) error { return nil }
+
+// @route POST /users
+// @name users.store
+// @response dto.User
+func (uc *UserController) Store(c *http.Context) error {
+	input := http.Validated[dto.CreateUser](c)
+	_ = input
+	return nil
+}
+
+// @route PUT /users/:id
+func (uc *UserController) Update(c *http.Context) error {
+	var input dto.CreateUser
+	return c.Bind(&input)
+}
+
+// @route GET /files/{path...}
+func Download(c *http.Context) error { return nil }
+`)
+	writeFile(t, filepath.Join(root, "app/dto/users.go"), `package dto
+
+import (
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/support/enum"
+)
+
+type Role string
+
+type Plan string
+
+const (
+	PlanFree Plan = "free"
+	PlanPro  Plan = "pro"
+)
+
+var Plans = enum.New(PlanFree, PlanPro)
+
+type CreateUser struct {
+	Email string   `+"`json:\"email\" validate:\"required,email\"`"+`
+	Name  string   `+"`json:\"name\" validate:\"max=100\"`"+`
+	Roles []Role   `+"`json:\"roles\"`"+`
+	Plans []Plan   `+"`json:\"plans\"`"+`
+	Plan  *Plan    `+"`json:\"plan\"`"+`
+	Password string `+"`json:\"-\"`"+`
+}
+
+type Timestamps struct {
+	CreatedAt time.Time `+"`json:\"created_at\"`"+`
+}
+
+type UserModel struct{ ID int64 }
+
+// User transforms the model for responses.
+type User struct{ model UserModel }
+
+func (u User) Transform() any {
+	return UserResource{ID: u.model.ID}
+}
+
+type UserResource struct {
+	Timestamps
+	ID      int64             `+"`json:\"id\"`"+`
+	Manager *UserResource     `+"`json:\"manager,omitempty\"`"+`
+	Meta    map[string]any    `+"`json:\"meta\"`"+`
+}
+`)
+
+	routes, err := routegen.Scan(root, "example.com/app", filepath.Join(root, "app"))
+	require.NoError(t, err)
+	src, err := Generate(root, "example.com/app", routes)
+	require.NoError(t, err)
+	out := string(src)
+
+	assert.Contains(t, out, `export interface CreateUser {
+  email: string;
+  name?: string;
+  roles: string[];
+  plans: ("free" | "pro")[];
+  plan: "free" | "pro" | null;
+}
+
+export interface Timestamps {
+  created_at: string;
+}
+
+export interface UserResource extends Timestamps {
+  id: number;
+  manager?: UserResource | null;
+  meta: Record<string, unknown>;
+}
+`)
+	assert.Contains(t, out, "    /** GET /files/{path...} */\n"+
+		"    download: (params: { path: string | number }, init?: RequestOptions) =>\n"+
+		"      request<unknown>(\"GET\", `/files/${encodePath(params.path)}`, undefined, init),\n")
+	assert.Contains(t, out, "    usersStore: (body: CreateUser, init?: RequestOptions) =>\n"+
+		"      request<UserResource>(\"POST\", \"/users\", body, init),\n")
+	assert.Contains(t, out, "    userShow: (params: { id: string | number }, init?: RequestOptions) =>\n"+
+		"      request<UserResource>(\"GET\", `/users/${encodeURIComponent(String(params.id))}`, undefined, init),\n")
+	assert.Contains(t, out, "    userUpdate: (params: { id: string | number }, body: CreateUser, init?: RequestOptions) =>\n")
+	assert.Contains(t, out, "export type Client = ReturnType<typeof createClient>;\n")
+}
+
+func TestGenerateUnknownType(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app/h.go"), `package app
+
+import "github.com/shauryagautam/Astra/pkg/engine/http"
+
+// @route GET /x
+// @response Missing
+func X(c *http.Context) error { return nil }
+`)
+	routes, err := routegen.Scan(root, "example.com/app", filepath.Join(root, "app"))
+	require.NoError(t, err)
+	_, err = Generate(root, "example.com/app", routes)
+	assert.ErrorContains(t, err, "type Missing not found")
+}
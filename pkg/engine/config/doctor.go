@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Severity grades a DoctorFinding.
+type Severity string
+
+const (
+	// SeverityError findings stop the app from booting in production.
+	SeverityError Severity = "error"
+	// SeverityWarning findings are reported but never stop the app.
+	SeverityWarning Severity = "warning"
+)
+
+// DoctorFinding is one problem found by Doctor.
+type DoctorFinding struct {
+	Key      string // the environment variable to fix, e.g. "APP_KEY"
+	Severity Severity
+	Message  string
+}
+
+func (f DoctorFinding) String() string {
+	return fmt.Sprintf("%s: %s", f.Key, f.Message)
+}
+
+// DoctorReport is the result of the configuration security audit.
+type DoctorReport struct {
+	Environment string
+	Findings    []DoctorFinding
+}
+
+// Failed reports whether any finding has SeverityError.
+func (r *DoctorReport) Failed() bool {
+	return slices.ContainsFunc(r.Findings, func(f DoctorFinding) bool {
+		return f.Severity == SeverityError
+	})
+}
+
+// Err returns an error listing the SeverityError findings, or nil.
+func (r *DoctorReport) Err() error {
+	var lines []string
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			lines = append(lines, f.String())
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return errors.New("astra: insecure " + r.Environment + " configuration:\n  - " +
+		strings.Join(lines, "\n  - "))
+}
+
+// knownSecrets are values that have appeared in examples and docs. A key
+// anyone can copy from a README is no key at all.
+var knownSecrets = []string{
+	"supersecretjwtkey32bytesminimum",
+	"super-secret-key-min-32-chars!!!!",
+	"secret",
+	"changeme",
+	"change-me",
+	"your-secret-key",
+}
+
+// Doctor audits the settings that must never reach production as they are
+// in development: missing, short or well-known secrets, APP_DEBUG, CORS
+// allowing any origin with credentials and unencrypted database traffic.
+// In production every finding is a SeverityError; elsewhere the secret
+// findings are warnings and the rest are not reported.
+func (c *AstraConfig) Doctor() *DoctorReport {
+	r := &DoctorReport{Environment: c.App.Environment}
+	if r.Environment == "" {
+		r.Environment = "development"
+	}
+	prod := r.Environment == "production"
+	add := func(always bool, key, msg string) {
+		switch {
+		case prod:
+			r.Findings = append(r.Findings, DoctorFinding{key, SeverityError, msg})
+		case always:
+			r.Findings = append(r.Findings, DoctorFinding{key, SeverityWarning, msg})
+		}
+	}
+
+	if msg := weakSecret(c.App.Key); msg != "" {
+		add(true, "APP_KEY", msg)
+	}
+	// JWT_SECRET falls back to APP_KEY; only audit it when it is set.
+	if c.Auth.JWTSecret != "" && c.Auth.JWTSecret != c.App.Key {
+		if msg := weakSecret(c.Auth.JWTSecret); msg != "" {
+			add(true, "JWT_SECRET", msg)
+		}
+	}
+	if c.App.Debug {
+		add(false, "APP_DEBUG", "debug mode exposes stack traces and internals; set APP_DEBUG=false")
+	}
+	if c.CORS.AllowCredentials && slices.Contains(c.CORS.AllowedOrigins, "*") {
+		add(true, "CORS_ALLOWED_ORIGINS", "\"*\" cannot be combined with CORS_ALLOW_CREDENTIALS=true; list the allowed origins")
+	}
+	if c.Database.SSL == "disable" {
+		add(false, "DB_SSL", "database traffic is unencrypted; use require or verify-full")
+	}
+	return r
+}
+
+func weakSecret(s string) string {
+	switch {
+	case s == "":
+		return "is empty; generate one with `openssl rand -base64 32`"
+	case slices.Contains(knownSecrets, strings.ToLower(s)):
+		return "is a published example value; generate a new one"
+	case len(s) < 32:
+		return "must be at least 32 characters long"
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor(t *testing.T) {
+	cfg := &AstraConfig{
+		App:      AppConfig{Environment: "production", Debug: true, Key: "01234567890123456789012345678901"},
+		Auth:     AuthConfig{JWTSecret: "supersecretjwtkey32bytesminimum"},
+		CORS:     CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		Database: DatabaseConfig{SSL: "require"},
+	}
+
+	report := cfg.Doctor()
+	require.True(t, report.Failed())
+	var keys []string
+	for _, f := range report.Findings {
+		keys = append(keys, f.Key)
+	}
+	assert.Equal(t, []string{"JWT_SECRET", "APP_DEBUG", "CORS_ALLOWED_ORIGINS"}, keys)
+	assert.ErrorContains(t, report.Err(), "published example value")
+
+	// Outside production the same settings only warn about secrets and CORS.
+	cfg.App.Environment = "development"
+	report = cfg.Doctor()
+	assert.False(t, report.Failed())
+	assert.NoError(t, report.Err())
+	assert.Len(t, report.Findings, 2)
+
+	cfg = &AstraConfig{App: AppConfig{Environment: "production", Key: "01234567890123456789012345678901"}}
+	assert.Empty(t, cfg.Doctor().Findings)
+}
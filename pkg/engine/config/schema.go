@@ -11,17 +11,61 @@ import (
 
 // AstraConfig is the root configuration struct for all Astra services.
 type AstraConfig struct {
-	App       AppConfig
-	Database  DatabaseConfig
-	Redis     RedisConfig
-	Auth      AuthConfig
-	OAuth2    OAuth2Config
-	Storage   StorageConfig
-	Mail      MailConfig
-	Queue     QueueConfig
-	Telemetry TelemetryConfig
-	Assets    AssetConfig
-	WS        WSConfig
+	App         AppConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	Auth        AuthConfig
+	OAuth2      OAuth2Config
+	Storage     StorageConfig
+	Mail        MailConfig
+	Queue       QueueConfig
+	Telemetry   TelemetryConfig
+	Assets      AssetConfig
+	WS          WSConfig
+	CORS        CORSConfig
+	Diagnostics DiagnosticsConfig
+	Resources   ResourceConfig
+	Static      StaticConfig
+	Views       ViewsConfig
+}
+
+// ResourceConfig sizes the process to its container. GOMAXPROCS needs no
+// setting: the Go runtime follows the cgroup CPU limit itself.
+type ResourceConfig struct {
+	MemoryLimitMB       int           `env:"MEMORY_LIMIT_MB"`       // 0 reads the cgroup limit
+	MemoryShedPercent   int           `env:"MEMORY_SHED_PERCENT"`   // of the limit; 0 disables shedding
+	MemoryCheckInterval time.Duration `env:"MEMORY_CHECK_INTERVAL"` // how often RSS is sampled
+}
+
+// DiagnosticsConfig controls the pprof, expvar and runtime stats routes.
+type DiagnosticsConfig struct {
+	Enabled  bool     `env:"DIAGNOSTICS_ENABLED"`
+	AllowIPs []string `env:"DIAGNOSTICS_ALLOW_IPS"`
+}
+
+// StaticConfig controls the public file server mounted by the static
+// provider.
+type StaticConfig struct {
+	Enabled   bool          `env:"STATIC_ENABLED"`
+	Dir       string        `env:"STATIC_DIR"`       // directory served, e.g. "public"
+	Prefix    string        `env:"STATIC_PREFIX"`    // URL path it is served under
+	Index     []string      `env:"STATIC_INDEX"`     // files served for a directory
+	MaxAge    time.Duration `env:"STATIC_MAX_AGE"`   // Cache-Control max-age; 0 sends none
+	Immutable []string      `env:"STATIC_IMMUTABLE"` // prefixes of fingerprinted files
+	Dotfiles  bool          `env:"STATIC_DOTFILES"`
+}
+
+// ViewsConfig holds the server-rendered view settings of ViewProvider.
+type ViewsConfig struct {
+	Dir       string `env:"VIEWS_DIR"`       // template directory, e.g. "resources/views"
+	Extension string `env:"VIEWS_EXTENSION"` // template file extension
+	Layout    string `env:"VIEWS_LAYOUT"`    // default layout, e.g. "layouts/app"
+}
+
+// CORSConfig holds the cross-origin settings read from the environment.
+type CORSConfig struct {
+	AllowedOrigins   []string `env:"CORS_ALLOWED_ORIGINS"`
+	AllowCredentials bool     `env:"CORS_ALLOW_CREDENTIALS"`
 }
 
 // WSConfig holds WebSocket settings.
@@ -51,7 +95,11 @@ type DatabaseConfig struct {
 	URL             string        `env:"DATABASE_URL"`
 	MaxConns        int32         `env:"DB_MAX_CONNS"`
 	MinConns        int32         `env:"DB_MIN_CONNS"`
+	MaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS"`
 	MaxIdle         time.Duration `env:"DB_MAX_IDLE"`
+	ConnLifetime    time.Duration `env:"DB_CONN_LIFETIME"`
+	ConnectRetries  int           `env:"DB_CONNECT_RETRIES"`
+	ConnectBackoff  time.Duration `env:"DB_CONNECT_BACKOFF"`
 	SSL             string        `env:"DB_SSL"`
 	LogQueries      bool          `env:"DB_LOG_QUERIES"`
 	SlowQueryThresh time.Duration `env:"DB_SLOW_QUERY_THRESH"`
@@ -103,6 +151,21 @@ type MailConfig struct {
 	SMTPPassword string `env:"SMTP_PASSWORD"`
 	SMTPFrom     string `env:"SMTP_FROM"`
 	ResendAPIKey string `env:"RESEND_API_KEY"`
+	// FallbackDriver names a second driver that takes over when the first
+	// one fails. Empty disables failover.
+	FallbackDriver string `env:"MAIL_FALLBACK_DRIVER"`
+	// SMTPPoolSize is how many idle SMTP connections are kept open for
+	// reuse; 0 dials a new connection for every message.
+	SMTPPoolSize     int           `env:"SMTP_POOL_SIZE"`
+	SMTPIdleTimeout  time.Duration `env:"SMTP_IDLE_TIMEOUT"`
+	SMTPRetries      int           `env:"SMTP_RETRIES"`
+	SMTPRetryBackoff time.Duration `env:"SMTP_RETRY_BACKOFF"`
+	// DKIMDomain turns on DKIM signing of SMTP mail. The private key comes
+	// from DKIMPrivateKey (PEM) or the file at DKIMPrivateKeyFile.
+	DKIMDomain         string `env:"DKIM_DOMAIN"`
+	DKIMSelector       string `env:"DKIM_SELECTOR"`
+	DKIMPrivateKey     string `env:"DKIM_PRIVATE_KEY"`
+	DKIMPrivateKeyFile string `env:"DKIM_PRIVATE_KEY_FILE"`
 }
 
 // QueueConfig holds background queue settings.
@@ -111,6 +174,12 @@ type QueueConfig struct {
 	Concurrency int      `env:"QUEUE_CONCURRENCY"`
 	Prefix      string   `env:"QUEUE_PREFIX"`
 	Queues      []string `env:"QUEUE_QUEUES"`
+	// Encrypt encrypts job payloads with APP_ENCRYPTION_KEY before they
+	// are stored in Redis.
+	Encrypt bool `env:"QUEUE_ENCRYPT"`
+	// Sign signs job payloads with APP_KEY, so workers refuse payloads
+	// changed in Redis.
+	Sign bool `env:"QUEUE_SIGN"`
 }
 
 // TelemetryConfig holds OpenTelemetry and dev dashboard settings.
@@ -289,7 +358,11 @@ func LoadFromEnv(c *Config) *AstraConfig {
 			URL:             c.String("DATABASE_URL", ""),
 			MaxConns:        c.Int32("DB_MAX_CONNS", 10),
 			MinConns:        c.Int32("DB_MIN_CONNS", 2),
+			MaxIdleConns:    c.Int("DB_MAX_IDLE_CONNS", c.Int("DB_MIN_CONNS", 2)),
 			MaxIdle:         c.Duration("DB_MAX_IDLE", 30*time.Second),
+			ConnLifetime:    c.Duration("DB_CONN_LIFETIME", 30*time.Minute),
+			ConnectRetries:  c.Int("DB_CONNECT_RETRIES", 5),
+			ConnectBackoff:  c.Duration("DB_CONNECT_BACKOFF", 500*time.Millisecond),
 			SSL:             c.String("DB_SSL", "require"),
 			LogQueries:      c.Bool("DB_LOG_QUERIES", false),
 			SlowQueryThresh: c.Duration("DB_SLOW_QUERY_THRESH", 200*time.Millisecond),
@@ -334,12 +407,25 @@ func LoadFromEnv(c *Config) *AstraConfig {
 			SMTPPassword: c.String("SMTP_PASSWORD", ""),
 			SMTPFrom:     c.String("SMTP_FROM", "noreply@example.com"),
 			ResendAPIKey: c.String("RESEND_API_KEY", ""),
+
+			FallbackDriver:   c.String("MAIL_FALLBACK_DRIVER", ""),
+			SMTPPoolSize:     c.Int("SMTP_POOL_SIZE", 2),
+			SMTPIdleTimeout:  c.Duration("SMTP_IDLE_TIMEOUT", 30*time.Second),
+			SMTPRetries:      c.Int("SMTP_RETRIES", 2),
+			SMTPRetryBackoff: c.Duration("SMTP_RETRY_BACKOFF", time.Second),
+
+			DKIMDomain:         c.String("DKIM_DOMAIN", ""),
+			DKIMSelector:       c.String("DKIM_SELECTOR", "default"),
+			DKIMPrivateKey:     c.String("DKIM_PRIVATE_KEY", ""),
+			DKIMPrivateKeyFile: c.String("DKIM_PRIVATE_KEY_FILE", ""),
 		},
 		Queue: QueueConfig{
 			Driver:      c.String("QUEUE_DRIVER", "redis"),
 			Concurrency: c.Int("QUEUE_CONCURRENCY", 5),
 			Prefix:      c.String("QUEUE_PREFIX", "astra:queue:"),
 			Queues:      strings.Split(c.String("QUEUE_QUEUES", "default"), ","),
+			Encrypt:     c.Bool("QUEUE_ENCRYPT", false),
+			Sign:        c.Bool("QUEUE_SIGN", false),
 		},
 		Telemetry: TelemetryConfig{
 			Endpoint:    c.String("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
@@ -349,6 +435,33 @@ func LoadFromEnv(c *Config) *AstraConfig {
 		WS: WSConfig{
 			AllowedOrigins: strings.Split(c.String("WS_ALLOWED_ORIGINS", ""), ","),
 		},
+		CORS: CORSConfig{
+			AllowedOrigins:   strings.Split(c.String("CORS_ALLOWED_ORIGINS", "*"), ","),
+			AllowCredentials: c.Bool("CORS_ALLOW_CREDENTIALS", false),
+		},
+		Diagnostics: DiagnosticsConfig{
+			Enabled:  c.Bool("DIAGNOSTICS_ENABLED", false),
+			AllowIPs: strings.Split(c.String("DIAGNOSTICS_ALLOW_IPS", ""), ","),
+		},
+		Static: StaticConfig{
+			Enabled:   c.Bool("STATIC_ENABLED", true),
+			Dir:       c.String("STATIC_DIR", "public"),
+			Prefix:    c.String("STATIC_PREFIX", "/public"),
+			Index:     splitList(c.String("STATIC_INDEX", "index.html")),
+			MaxAge:    c.Duration("STATIC_MAX_AGE", 0),
+			Immutable: splitList(c.String("STATIC_IMMUTABLE", "build/")),
+			Dotfiles:  c.Bool("STATIC_DOTFILES", false),
+		},
+		Views: ViewsConfig{
+			Dir:       c.String("VIEWS_DIR", "resources/views"),
+			Extension: c.String("VIEWS_EXTENSION", ".html"),
+			Layout:    c.String("VIEWS_LAYOUT", ""),
+		},
+		Resources: ResourceConfig{
+			MemoryLimitMB:       c.Int("MEMORY_LIMIT_MB", 0),
+			MemoryShedPercent:   c.Int("MEMORY_SHED_PERCENT", 90),
+			MemoryCheckInterval: c.Duration("MEMORY_CHECK_INTERVAL", time.Second),
+		},
 		OAuth2: OAuth2Config{
 			Google: OAuth2ProviderEnvConfig{
 				ClientID:     c.String("GOOGLE_CLIENT_ID", ""),
@@ -378,3 +491,15 @@ func LoadFromEnv(c *Config) *AstraConfig {
 		},
 	}
 }
+
+// splitList splits a comma-separated setting, dropping empty entries, so
+// an empty value is an empty list.
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
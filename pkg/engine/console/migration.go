@@ -0,0 +1,162 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/database/migration"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	"github.com/shauryagautam/Astra/pkg/engine/providers"
+	"github.com/spf13/cobra"
+)
+
+// MakeMigrationCommand returns the make:migration command. Without flags it
+// writes an empty migration. With --from-models it diffs models against the
+// database configured in .env and writes the detected CREATE TABLE, ADD
+// COLUMN and CREATE INDEX statements for review; it never applies them. When
+// models is empty, every model registered with database.RegisterModel in
+// this binary is used.
+func MakeMigrationCommand(models ...any) *cobra.Command {
+	var (
+		dir        string
+		fromModels bool
+	)
+	cmd := &cobra.Command{
+		Use:   "make:migration <name>",
+		Short: "Create a migration file, optionally from the difference between models and the schema",
+		Example: `  astra make:migration add_status_to_orders
+  astra make:migration sync_models --from-models`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			console := ui.FromCommand(cmd)
+			if !fromModels {
+				path, err := migration.Generate(dir, args[0])
+				if err != nil {
+					return err
+				}
+				console.Success("created %s", path)
+				return nil
+			}
+
+			var metas []*database.ModelMeta
+			if len(models) == 0 {
+				metas = database.RegisteredModels()
+			}
+			if len(models) == 0 && len(metas) == 0 {
+				return errors.New("make:migration: no models are linked into this binary; " +
+					"mount console.MakeMigrationCommand in your application's main")
+			}
+
+			env, err := config.Load(".env")
+			if err != nil {
+				return err
+			}
+			db, err := providers.ProvideDB(env)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx := context.Background()
+			var diff *database.SchemaDiff
+			if len(models) > 0 {
+				diff, err = db.DiffModels(ctx, models...)
+			} else {
+				diff, err = db.Diff(ctx, metas...)
+			}
+			if err != nil {
+				return fmt.Errorf("make:migration: %w", err)
+			}
+			if diff.Empty() {
+				console.Info("models and schema already match; no migration written")
+				return nil
+			}
+
+			path, err := migration.GenerateSQL(dir, args[0], diff.Up, diff.Down, diff.Notes)
+			if err != nil {
+				return err
+			}
+			console.Success("created %s with %d statement(s); review it before running migration:run", path, len(diff.Up))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "database/migrations", "migrations directory")
+	cmd.Flags().BoolVar(&fromModels, "from-models", false, "generate the statements from the difference between models and the database")
+	return cmd
+}
+
+// FreshCommand returns the migration:fresh command, which resets the
+// database configured in .env: by default it drops every table and re-runs
+// all migrations; with --truncate it keeps the schema and only empties the
+// tables. --seed then runs the seeders of seeders (database.DefaultRunner
+// when nil). In production it refuses to run without --force.
+func FreshCommand(seeders *database.SeederRunner) *cobra.Command {
+	var (
+		dir      string
+		truncate bool
+		seed     bool
+		seedWith int64
+		force    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "migration:fresh",
+		Short: "Reset the database, then optionally seed it",
+		Example: `  astra migration:fresh --seed
+  astra migration:fresh --truncate --seed --seed-with=42`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			runner := seeders
+			if runner == nil {
+				runner = database.DefaultRunner
+			}
+			if seed && len(runner.Names()) == 0 {
+				return errors.New("migration:fresh: --seed needs seeders linked into this binary; " +
+					"mount console.FreshCommand in your application's main")
+			}
+
+			env, err := config.Load(".env")
+			if err != nil {
+				return err
+			}
+			if env.IsProd() && !force {
+				return errors.New("migration:fresh: APP_ENV is production; re-run with --force to wipe the database")
+			}
+			db, err := providers.ProvideDB(env)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx := cmd.Context()
+			console := ui.FromCommand(cmd)
+			if truncate {
+				if err := database.TruncateAll(ctx, db); err != nil {
+					return err
+				}
+				console.Success("emptied all tables")
+			} else {
+				spinner := console.Spinner("Dropping tables and re-running migrations")
+				if err := migration.NewRunner(db.Pool(), dir, nil).Fresh(ctx); err != nil {
+					spinner.Fail("migration:fresh failed")
+					return err
+				}
+				spinner.Success("database reset")
+			}
+
+			if !seed {
+				return nil
+			}
+			results, err := runner.Seed(ctx, db, database.SeedOptions{Seed: seedWith, Out: console.Writer()})
+			printSeedSummary(console, results)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "database/migrations", "migrations directory")
+	cmd.Flags().BoolVar(&truncate, "truncate", false, "empty the tables instead of dropping and re-migrating them")
+	cmd.Flags().BoolVar(&seed, "seed", false, "run the seeders afterwards")
+	cmd.Flags().Int64Var(&seedWith, "seed-with", 0, "random seed for the seeders (default: random)")
+	cmd.Flags().BoolVar(&force, "force", false, "allow running in production")
+	return cmd
+}
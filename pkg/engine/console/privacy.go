@@ -0,0 +1,117 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	"github.com/shauryagautam/Astra/pkg/engine/providers"
+	"github.com/shauryagautam/Astra/pkg/privacy"
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+// PrivacyExportCommand returns the privacy:export command, which exports the
+// personal data of a person from the sources of registry
+// (privacy.DefaultRegistry when nil) to the local storage disk.
+func PrivacyExportCommand(registry *privacy.Registry) *cobra.Command {
+	var actor string
+	cmd := &cobra.Command{
+		Use:   "privacy:export <subject-id>",
+		Short: "Export the personal data of a person (subject access request)",
+		Long: `Collects the personal data of a person from every registered privacy
+source and writes a ZIP bundle to the storage disk. The request is recorded
+in the privacy_requests table and in the audit stream.`,
+		Example: `  astra privacy:export 42 --actor dpo@example.com`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrivacy(cmd, "privacy:export", registry, func(ctx context.Context, svc *privacy.Service) (*privacy.Request, error) {
+				return svc.RequestExport(ctx, args[0], actor)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&actor, "actor", "cli", "who asked for the export, for the audit trail")
+	return cmd
+}
+
+// PrivacyEraseCommand returns the privacy:erase command, which erases the
+// personal data of a person from the sources of registry
+// (privacy.DefaultRegistry when nil). It asks for confirmation unless
+// --force is given.
+func PrivacyEraseCommand(registry *privacy.Registry) *cobra.Command {
+	var (
+		actor string
+		force bool
+	)
+	cmd := &cobra.Command{
+		Use:   "privacy:erase <subject-id>",
+		Short: "Erase the personal data of a person (right to erasure)",
+		Long: `Erases the personal data of a person from every registered privacy source
+in a single transaction. The request is recorded in the privacy_requests
+table and in the audit stream.`,
+		Example: `  astra privacy:erase 42 --actor dpo@example.com --force`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !force {
+				ok, err := ui.FromCommand(cmd).Confirm(fmt.Sprintf("Erase the personal data of %s? This cannot be undone.", args[0]), false)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errors.New("privacy:erase: not confirmed; re-run with --force to skip the question")
+				}
+			}
+			return runPrivacy(cmd, "privacy:erase", registry, func(ctx context.Context, svc *privacy.Service) (*privacy.Request, error) {
+				return svc.RequestErasure(ctx, args[0], actor)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&actor, "actor", "cli", "who asked for the erasure, for the audit trail")
+	cmd.Flags().BoolVar(&force, "force", false, "do not ask for confirmation")
+	return cmd
+}
+
+// runPrivacy processes a request synchronously against the configured
+// database and local disk, and prints the outcome.
+func runPrivacy(cmd *cobra.Command, name string, registry *privacy.Registry, run func(context.Context, *privacy.Service) (*privacy.Request, error)) error {
+	if registry == nil {
+		registry = privacy.DefaultRegistry
+	}
+	if len(registry.Names()) == 0 {
+		return errors.New(name + ": no privacy sources are linked into this binary; " +
+			"register them with privacy.Register and mount the command in your application's main")
+	}
+
+	env, err := config.Load(".env")
+	if err != nil {
+		return err
+	}
+	db, err := providers.ProvideDB(env)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	svc := privacy.New(db, storage.NewLocalStorage(env.String("STORAGE_LOCAL_ROOT", "storage")), privacy.WithRegistry(registry))
+	if err := svc.Migrate(ctx); err != nil {
+		return err
+	}
+	req, err := run(ctx, svc)
+	if err != nil {
+		return err
+	}
+
+	console := ui.FromCommand(cmd)
+	t := console.Table("Request", "Subject", "Status", "Bundle")
+	t.Row(req.ID, req.SubjectID, string(req.Status), req.Path)
+	t.Render()
+	console.Success("%s request %s completed for %d source(s)", req.Kind, req.ID, len(registry.Names()))
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package console holds CLI commands that need application code linked in,
+// such as job handlers, models or seeders. The astra binary lists them, but an application
+// mounts them in its own binary so its hooks are available:
+//
+//	func main() {
+//		if len(os.Args) > 1 && os.Args[1] == "queue:work" {
+//			cmd := console.QueueWorkCommand(jobs.Register)
+//			cmd.SetArgs(os.Args[2:])
+//			if err := cmd.Execute(); err != nil {
+//				log.Fatal(err)
+//			}
+//			return
+//		}
+//		// ... start the HTTP server
+//	}
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	"github.com/shauryagautam/Astra/pkg/queue"
+	astraredis "github.com/shauryagautam/Astra/pkg/redis"
+	"github.com/spf13/cobra"
+)
+
+// QueueWorkCommand returns the queue:work command. register is called with
+// the worker before it starts and must register every job type the
+// application dispatches; jobs without a handler are moved to failed jobs.
+func QueueWorkCommand(register func(w *queue.Worker) error) *cobra.Command {
+	var (
+		queues         string
+		concurrency    int
+		maxJobs        int64
+		maxMemory      uint64
+		healthInterval time.Duration
+		stopTimeout    time.Duration
+		visibility     time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "queue:work",
+		Short: "Process background jobs until stopped or a limit is reached",
+		Long: `Runs a queue worker in the foreground. It exits 0 after SIGINT/SIGTERM or
+when --max-jobs or --max-memory is reached, so a supervisor can restart it.`,
+		Example: `  astra queue:work --queues=high,default --concurrency=8
+  astra queue:work --max-jobs=1000 --max-memory=256`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if register == nil {
+				return errors.New("queue:work: no job handlers are linked into this binary; " +
+					"mount console.QueueWorkCommand in your application's main")
+			}
+
+			env, err := config.Load(".env")
+			if err != nil {
+				return err
+			}
+			cfg := config.LoadFromEnv(env)
+			if queues == "" {
+				queues = strings.Join(cfg.Queue.Queues, ",")
+			}
+			if concurrency <= 0 {
+				concurrency = cfg.Queue.Concurrency
+			}
+
+			client, err := astraredis.NewClient(cfg.Redis, nil)
+			if err != nil {
+				return err
+			}
+			defer client.Stop(context.Background())
+
+			protection, err := queue.PayloadProtectionFromConfig(cfg)
+			if err != nil {
+				return err
+			}
+			worker := queue.NewWorker(client.UniversalClient, cfg.Queue.Prefix, splitList(queues), concurrency, slog.Default()).
+				WithVisibilityTimeout(visibility).
+				WithPayloadProtection(protection)
+			if err := register(worker); err != nil {
+				return fmt.Errorf("queue:work: register handlers: %w", err)
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			ui.FromCommand(cmd).Info("queue:work listening on %s (concurrency %d)", queues, concurrency)
+			return queue.Work(ctx, worker, queue.WorkOptions{
+				MaxJobs:        maxJobs,
+				MaxMemoryMB:    maxMemory,
+				HealthInterval: healthInterval,
+				StopTimeout:    stopTimeout,
+				Health:         cmd.OutOrStdout(),
+			})
+		},
+	}
+	cmd.Flags().StringVar(&queues, "queues", "", "comma-separated queues in priority order (default: QUEUE_QUEUES)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 0, "number of concurrent jobs (default: QUEUE_CONCURRENCY)")
+	cmd.Flags().Int64Var(&maxJobs, "max-jobs", 0, "exit after this many jobs (0 = unlimited)")
+	cmd.Flags().Uint64Var(&maxMemory, "max-memory", 0, "exit once the heap exceeds this many MB (0 = unlimited)")
+	cmd.Flags().DurationVar(&healthInterval, "health-interval", 30*time.Second, "how often to print worker health")
+	cmd.Flags().DurationVar(&stopTimeout, "stop-timeout", 30*time.Second, "how long in-flight jobs may drain on exit")
+	cmd.Flags().DurationVar(&visibility, "visibility-timeout", 0, "reclaim jobs left unacknowledged this long by a crashed worker (default 30s)")
+	return cmd
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package console
+
+import (
+	"errors"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/console/ui"
+	"github.com/shauryagautam/Astra/pkg/engine/providers"
+	"github.com/spf13/cobra"
+)
+
+// SeedCommand returns the db:seed command, which runs the seeders of runner
+// (database.DefaultRunner when nil) against the database configured in
+// .env. --seed makes the generated data reproducible, and the summary lists
+// the records each seeder created.
+func SeedCommand(runner *database.SeederRunner) *cobra.Command {
+	var (
+		seed int64
+		only string
+	)
+	cmd := &cobra.Command{
+		Use:   "db:seed",
+		Short: "Populate the database with the application's seeders",
+		Example: `  astra db:seed
+  astra db:seed --seed=42 --only=01_users,02_teams`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			r := runner
+			if r == nil {
+				r = database.DefaultRunner
+			}
+			if len(r.Names()) == 0 {
+				return errors.New("db:seed: no seeders are linked into this binary; " +
+					"register them with database.Register and mount console.SeedCommand in your application's main")
+			}
+
+			env, err := config.Load(".env")
+			if err != nil {
+				return err
+			}
+			db, err := providers.ProvideDB(env)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			console := ui.FromCommand(cmd)
+			results, err := r.Seed(ctx, db, database.SeedOptions{Seed: seed, Only: splitList(only), Out: console.Writer()})
+			printSeedSummary(console, results)
+			return err
+		},
+	}
+	cmd.Flags().Int64Var(&seed, "seed", 0, "random seed for reproducible data (default: random, printed in the summary)")
+	cmd.Flags().StringVar(&only, "only", "", "comma-separated seeders to run (default: all)")
+	return cmd
+}
+
+// printSeedSummary prints the records each seeder created and the seed to
+// pass to --seed to reproduce the run.
+func printSeedSummary(console *ui.Console, results []database.SeedResult) {
+	if len(results) == 0 {
+		return
+	}
+	console.Printf("\nSummary (seed %d):\n", results[0].Seed)
+	t := console.Table("Seeder", "Records", "Time").
+		AlignColumn(1, ui.AlignRight).
+		AlignColumn(2, ui.AlignRight)
+	total := 0
+	for _, res := range results {
+		t.Row(res.Name, res.Total(), res.Duration.Round(time.Millisecond))
+		total += res.Total()
+	}
+	t.Footer("total", total).Render()
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressBar shows how much of a known amount of work is done. On a
+// terminal it redraws one line in place; elsewhere it prints a single line
+// when finished.
+type ProgressBar struct {
+	c     *Console
+	label string
+	total int
+	width int
+
+	mu       sync.Mutex
+	current  int
+	start    time.Time
+	drawn    time.Time
+	finished bool
+}
+
+// Progress starts a progress bar for total units of work.
+func (c *Console) Progress(total int, label string) *ProgressBar {
+	p := &ProgressBar{c: c, label: label, total: max(total, 0), width: 30, start: time.Now()}
+	p.draw(true)
+	return p
+}
+
+// Advance marks n more units as done.
+func (p *ProgressBar) Advance(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = min(p.current+n, p.total)
+	p.draw(false)
+}
+
+// Set marks n units in total as done.
+func (p *ProgressBar) Set(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = min(max(n, 0), p.total)
+	p.draw(false)
+}
+
+// Finish draws the final state and ends the line. Further calls do
+// nothing.
+func (p *ProgressBar) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	p.finished = true
+	if p.c.tty {
+		p.render()
+		fmt.Fprintln(p.c.out)
+		return
+	}
+	fmt.Fprintf(p.c.out, "%s %d/%d in %s\n", p.label, p.current, p.total, time.Since(p.start).Round(time.Millisecond))
+}
+
+// draw redraws the bar on a terminal, at most every 50ms unless forced or
+// complete.
+func (p *ProgressBar) draw(force bool) {
+	if !p.c.tty || p.finished {
+		return
+	}
+	if !force && p.current < p.total && time.Since(p.drawn) < 50*time.Millisecond {
+		return
+	}
+	p.drawn = time.Now()
+	p.render()
+}
+
+func (p *ProgressBar) render() {
+	ratio := 1.0
+	if p.total > 0 {
+		ratio = float64(p.current) / float64(p.total)
+	}
+	filled := int(ratio * float64(p.width))
+	bar := p.c.Green(strings.Repeat("█", filled)) + p.c.Dim(strings.Repeat("░", p.width-filled))
+
+	eta := ""
+	if p.current > 0 && p.current < p.total {
+		elapsed := time.Since(p.start)
+		left := time.Duration(float64(elapsed) / ratio * (1 - ratio))
+		eta = " " + p.c.Dim("eta "+left.Round(time.Second).String())
+	}
+	fmt.Fprintf(p.c.out, "\r\x1b[2K%s %s %3.0f%% %d/%d%s", p.label, bar, ratio*100, p.current, p.total, eta)
+}
+
+// Spinner shows that work of unknown length is in progress. It is animated
+// on a terminal only.
+type Spinner struct {
+	c     *Console
+	label string
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner starts a spinner labeled label. Stop it with Success or Fail.
+func (c *Console) Spinner(label string) *Spinner {
+	s := &Spinner{c: c, label: label, stop: make(chan struct{}), done: make(chan struct{})}
+	if !c.tty {
+		close(s.done)
+		return s
+	}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(80 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(c.out, "\r\x1b[2K%s %s", c.Cyan(spinnerFrames[i%len(spinnerFrames)]), label)
+			select {
+			case <-s.stop:
+				fmt.Fprint(c.out, "\r\x1b[2K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// Success stops the spinner and replaces it with a success line, or with
+// the label when format is empty.
+func (s *Spinner) Success(format string, a ...any) {
+	s.halt()
+	s.c.Success("%s", s.message(format, a))
+}
+
+// Fail stops the spinner and replaces it with an error line, or with the
+// label when format is empty.
+func (s *Spinner) Fail(format string, a ...any) {
+	s.halt()
+	s.c.Error("%s", s.message(format, a))
+}
+
+func (s *Spinner) halt() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+func (s *Spinner) message(format string, a []any) string {
+	if format == "" {
+		return s.label
+	}
+	return fmt.Sprintf(format, a...)
+}
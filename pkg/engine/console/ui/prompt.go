@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Ask prints question and returns the line typed, or def when the line is
+// empty or the console is not interactive.
+func (c *Console) Ask(question, def string) (string, error) {
+	if !c.interactive {
+		return def, nil
+	}
+	prompt := question
+	if def != "" {
+		prompt += " " + c.Dim("["+def+"]")
+	}
+	fmt.Fprintf(c.out, "%s %s ", c.Cyan("?"), prompt)
+	line, err := c.readLine()
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// Confirm asks a yes/no question and returns the answer, or def when the
+// answer is empty or the console is not interactive.
+func (c *Console) Confirm(question string, def bool) (bool, error) {
+	if !c.interactive {
+		return def, nil
+	}
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(c.out, "%s %s %s ", c.Cyan("?"), question, c.Dim("["+hint+"]"))
+		line, err := c.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(line) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		c.Warn("please answer y or n")
+	}
+}
+
+// Select lists options, numbered from 1, and returns the index of the one
+// chosen, or def when the answer is empty or the console is not
+// interactive.
+func (c *Console) Select(question string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return -1, fmt.Errorf("ui: select %q has no options", question)
+	}
+	if def < 0 || def >= len(options) {
+		def = 0
+	}
+	if !c.interactive {
+		return def, nil
+	}
+	fmt.Fprintf(c.out, "%s %s\n", c.Cyan("?"), question)
+	for i, opt := range options {
+		marker := " "
+		if i == def {
+			marker = c.Cyan("›")
+		}
+		fmt.Fprintf(c.out, "%s %2d) %s\n", marker, i+1, opt)
+	}
+	for {
+		fmt.Fprintf(c.out, "  choice %s ", c.Dim("["+strconv.Itoa(def+1)+"]"))
+		line, err := c.readLine()
+		if err != nil {
+			return -1, err
+		}
+		if line == "" {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		for i, opt := range options {
+			if strings.EqualFold(line, opt) {
+				return i, nil
+			}
+		}
+		c.Warn("please enter a number between 1 and %d", len(options))
+	}
+}
+
+// readLine reads a line without its line ending. A last line without one
+// is returned as is; end of input without any text is an error.
+func (c *Console) readLine() (string, error) {
+	line, err := c.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("ui: failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Align is the alignment of a table column.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Table renders rows in aligned columns under a header:
+//
+//	t := out.Table("Table", "Rows")
+//	t.AlignColumn(1, ui.AlignRight)
+//	t.Row("users", 120)
+//	t.Render()
+type Table struct {
+	c       *Console
+	headers []string
+	rows    [][]string
+	align   []Align
+	footer  []string
+}
+
+// Table starts a table with the given column headers.
+func (c *Console) Table(headers ...string) *Table {
+	return &Table{c: c, headers: headers, align: make([]Align, len(headers))}
+}
+
+// AlignColumn sets the alignment of column i, counted from 0.
+func (t *Table) AlignColumn(i int, a Align) *Table {
+	if i >= 0 && i < len(t.align) {
+		t.align[i] = a
+	}
+	return t
+}
+
+// Row adds a row. Cells are formatted with fmt.Sprint; missing cells are
+// left empty and extra ones dropped.
+func (t *Table) Row(cells ...any) *Table {
+	t.rows = append(t.rows, t.format(cells))
+	return t
+}
+
+// Footer sets a row rendered below a rule, e.g. totals.
+func (t *Table) Footer(cells ...any) *Table {
+	t.footer = t.format(cells)
+	return t
+}
+
+func (t *Table) format(cells []any) []string {
+	row := make([]string, len(t.headers))
+	for i := range row {
+		if i < len(cells) {
+			row[i] = fmt.Sprint(cells[i])
+		}
+	}
+	return row
+}
+
+// Render writes the table to the console.
+func (t *Table) Render() {
+	t.RenderTo(t.c.out)
+}
+
+// RenderTo writes the table to w.
+func (t *Table) RenderTo(w io.Writer) {
+	widths := make([]int, len(t.headers))
+	for _, row := range append(append([][]string{t.headers}, t.rows...), t.footer) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], width(cell))
+		}
+	}
+
+	rule := make([]string, len(widths))
+	for i, n := range widths {
+		rule[i] = strings.Repeat("─", n)
+	}
+	header := make([]string, len(t.headers))
+	for i, h := range t.headers {
+		header[i] = t.c.Bold(h)
+	}
+
+	t.line(w, header, widths)
+	t.line(w, rule, widths)
+	for _, row := range t.rows {
+		t.line(w, row, widths)
+	}
+	if t.footer != nil {
+		t.line(w, rule, widths)
+		t.line(w, t.footer, widths)
+	}
+}
+
+func (t *Table) line(w io.Writer, cells []string, widths []int) {
+	var b strings.Builder
+	b.WriteString("  ")
+	for i, cell := range cells {
+		pad := strings.Repeat(" ", widths[i]-width(cell))
+		if t.align[i] == AlignRight {
+			b.WriteString(pad + cell)
+		} else {
+			b.WriteString(cell + pad)
+		}
+		if i < len(cells)-1 {
+			b.WriteString("  ")
+		}
+	}
+	fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+}
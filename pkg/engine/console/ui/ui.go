@@ -0,0 +1,147 @@
+// Package ui is the console toolkit of the astra commands: colored status
+// lines, prompts, progress bars, spinners and tables. Application commands
+// use it to look and behave the same:
+//
+//	func run(cmd *cobra.Command, _ []string) error {
+//	    out := ui.FromCommand(cmd)
+//	    if ok, _ := out.Confirm("Import 10,000 users?", false); !ok {
+//	        return nil
+//	    }
+//	    bar := out.Progress(len(users), "Importing")
+//	    for _, u := range users {
+//	        // ...
+//	        bar.Advance(1)
+//	    }
+//	    bar.Finish()
+//	    out.Success("imported %d users", len(users))
+//	    return nil
+//	}
+//
+// Colors are used only when the output is a terminal and NO_COLOR is not
+// set (FORCE_COLOR overrides both). Animations are only drawn on a
+// terminal, and prompts return their default when the input is not one,
+// so commands behave in CI and pipes.
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// Console writes to a command's output and reads answers from its input.
+type Console struct {
+	in          *bufio.Reader
+	out         io.Writer
+	color       bool
+	interactive bool
+	tty         bool
+}
+
+// New creates a Console for in and out. Colors and animations are enabled
+// when out is a terminal; prompts are asked when in is one too.
+func New(in io.Reader, out io.Writer) *Console {
+	tty := isTerminal(out)
+	return &Console{
+		in:          bufio.NewReader(in),
+		out:         out,
+		color:       colorEnabled(tty),
+		interactive: tty && isTerminal(in),
+		tty:         tty,
+	}
+}
+
+// FromCommand creates a Console for the input and output of cmd.
+func FromCommand(cmd *cobra.Command) *Console {
+	return New(cmd.InOrStdin(), cmd.OutOrStdout())
+}
+
+// SetColor turns colors on or off.
+func (c *Console) SetColor(on bool) *Console {
+	c.color = on
+	return c
+}
+
+// SetInteractive makes prompts read their answer (true) or return their
+// default (false), whatever the input is.
+func (c *Console) SetInteractive(on bool) *Console {
+	c.interactive = on
+	return c
+}
+
+// Writer returns the output.
+func (c *Console) Writer() io.Writer { return c.out }
+
+// Println writes a line.
+func (c *Console) Println(a ...any) { fmt.Fprintln(c.out, a...) }
+
+// Printf writes formatted text.
+func (c *Console) Printf(format string, a ...any) { fmt.Fprintf(c.out, format, a...) }
+
+// Success writes a line prefixed with a green check mark.
+func (c *Console) Success(format string, a ...any) {
+	fmt.Fprintf(c.out, "%s %s\n", c.Green("✓"), fmt.Sprintf(format, a...))
+}
+
+// Info writes a line prefixed with a cyan bullet.
+func (c *Console) Info(format string, a ...any) {
+	fmt.Fprintf(c.out, "%s %s\n", c.Cyan("•"), fmt.Sprintf(format, a...))
+}
+
+// Warn writes a line prefixed with a yellow exclamation mark.
+func (c *Console) Warn(format string, a ...any) {
+	fmt.Fprintf(c.out, "%s %s\n", c.Yellow("!"), fmt.Sprintf(format, a...))
+}
+
+// Error writes a line prefixed with a red cross.
+func (c *Console) Error(format string, a ...any) {
+	fmt.Fprintf(c.out, "%s %s\n", c.Red("✗"), fmt.Sprintf(format, a...))
+}
+
+// Bold, Dim, Red, Green, Yellow and Cyan style s when colors are enabled.
+func (c *Console) Bold(s string) string   { return c.style("1", s) }
+func (c *Console) Dim(s string) string    { return c.style("2", s) }
+func (c *Console) Red(s string) string    { return c.style("31", s) }
+func (c *Console) Green(s string) string  { return c.style("32", s) }
+func (c *Console) Yellow(s string) string { return c.style("33", s) }
+func (c *Console) Cyan(s string) string   { return c.style("36", s) }
+
+func (c *Console) style(code, s string) string {
+	if !c.color || s == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// colorEnabled follows https://no-color.org and the FORCE_COLOR convention.
+func colorEnabled(tty bool) bool {
+	if v, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		return v != "0" && v != "false"
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return tty && os.Getenv("TERM") != "dumb"
+}
+
+// isTerminal reports whether v is a character device, such as a terminal.
+func isTerminal(v any) bool {
+	f, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// width returns the number of columns s takes, ignoring color codes.
+func width(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
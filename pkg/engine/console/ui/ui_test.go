@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompts(t *testing.T) {
+	var out bytes.Buffer
+	c := New(strings.NewReader("Ada\n\nmaybe\ny\n7\nmysql\n"), &out).SetInteractive(true)
+
+	name, err := c.Ask("Name?", "guest")
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", name)
+
+	name, err = c.Ask("Name?", "guest")
+	require.NoError(t, err)
+	assert.Equal(t, "guest", name)
+
+	ok, err := c.Confirm("Continue?", false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, out.String(), "please answer y or n")
+
+	i, err := c.Select("Driver?", []string{"postgres", "mysql"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+	assert.Contains(t, out.String(), "between 1 and 2")
+
+	_, err = c.Ask("More?", "")
+	assert.Error(t, err, "end of input")
+}
+
+func TestPrompts_NonInteractiveUseDefaults(t *testing.T) {
+	var out bytes.Buffer
+	c := New(strings.NewReader("n\n"), &out)
+
+	ok, err := c.Confirm("Continue?", true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	i, _ := c.Select("Driver?", []string{"a", "b"}, 1)
+	assert.Equal(t, 1, i)
+	assert.Empty(t, out.String())
+}
+
+func TestTable(t *testing.T) {
+	var out bytes.Buffer
+	c := New(nil, &out)
+	c.Table("Table", "Rows").
+		AlignColumn(1, AlignRight).
+		Row("users", 120).
+		Row("posts", 7).
+		Footer("total", 127).
+		Render()
+
+	assert.Equal(t, strings.Join([]string{
+		"  Table  Rows",
+		"  ─────  ────",
+		"  users   120",
+		"  posts     7",
+		"  ─────  ────",
+		"  total   127",
+	}, "\n")+"\n", out.String())
+}
+
+func TestColors(t *testing.T) {
+	var out bytes.Buffer
+	c := New(nil, &out)
+	c.Success("done")
+	assert.Equal(t, "✓ done\n", out.String(), "no colors when not a terminal")
+
+	c.SetColor(true)
+	assert.Equal(t, "\x1b[32mok\x1b[0m", c.Green("ok"))
+	assert.Equal(t, 2, width(c.Green("ok")))
+
+	t.Setenv("FORCE_COLOR", "1")
+	assert.True(t, colorEnabled(false))
+	t.Setenv("FORCE_COLOR", "0")
+	assert.False(t, colorEnabled(true))
+}
+
+func TestProgressAndSpinner_NotATerminal(t *testing.T) {
+	var out bytes.Buffer
+	c := New(nil, &out)
+
+	bar := c.Progress(3, "Seeding")
+	bar.Advance(2)
+	bar.Advance(5)
+	bar.Finish()
+	bar.Finish()
+	assert.True(t, strings.HasPrefix(out.String(), "Seeding 3/3 in "))
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+
+	out.Reset()
+	s := c.Spinner("Connecting")
+	s.Success("")
+	s.Fail("gave up")
+	assert.Equal(t, "✓ Connecting\n✗ gave up\n", out.String())
+}
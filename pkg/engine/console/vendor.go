@@ -0,0 +1,73 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/spf13/cobra"
+)
+
+// VendorPublishCommand returns the vendor:publish command, which copies the
+// config, migrations and views of packages into the application so they can
+// be edited and committed. Applications usually build it from their
+// manifest:
+//
+//	Commands: []func(*engine.App) *cobra.Command{
+//		func(a *engine.App) *cobra.Command { return console.VendorPublishCommand(a.Packages()...) },
+//	},
+func VendorPublishCommand(packages ...engine.Package) *cobra.Command {
+	var (
+		only  []string
+		tags  []string
+		force bool
+		root  string
+	)
+	cmd := &cobra.Command{
+		Use:   "vendor:publish",
+		Short: "Copy the config, migrations and views of installed packages into the application",
+		Example: `  astra vendor:publish
+  astra vendor:publish --package=billing --tag=config --force`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if len(packages) == 0 {
+				return errors.New("vendor:publish: no packages are linked into this binary; " +
+					"list them in your manifest's Packages and mount console.VendorPublishCommand in your application's main")
+			}
+			for _, tag := range tags {
+				if tag != engine.PublishConfig && tag != engine.PublishMigrations && tag != engine.PublishViews {
+					return fmt.Errorf("vendor:publish: unknown tag %q (want config, migrations or views)", tag)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			matched := 0
+			for _, pkg := range packages {
+				if len(only) > 0 && !slices.Contains(only, pkg.Name) {
+					continue
+				}
+				matched++
+				files, err := pkg.Publish(root, engine.PublishOptions{Only: tags, Force: force})
+				for _, f := range files {
+					if f.Skipped {
+						fmt.Fprintf(out, "  • kept     %s (exists; use --force to overwrite)\n", f.Path)
+					} else {
+						fmt.Fprintf(out, "  ✓ copied   %s\n", f.Path)
+					}
+				}
+				if err != nil {
+					return err
+				}
+			}
+			if matched == 0 {
+				return fmt.Errorf("vendor:publish: no package named %v", only)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&only, "package", nil, "publish only these packages (default: all)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "publish only these resources: config, migrations, views (default: all)")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite files that already exist")
+	cmd.Flags().StringVar(&root, "root", ".", "application root")
+	return cmd
+}
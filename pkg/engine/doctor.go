@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"os"
+	"strconv"
+)
+
+// SkipAuditEnv names the environment variable that, set to true, lets Boot
+// continue past a failed configuration audit, for the rare emergency where
+// a known-insecure production deploy is still better than none. It is not a
+// command-line flag so that no command's own flags, such as --force, can
+// turn the audit off by accident.
+const SkipAuditEnv = "ASTRA_SKIP_AUDIT"
+
+// audit runs config.Doctor before any provider starts. In production a
+// SeverityError finding aborts Boot unless SkipAuditEnv is set; every
+// finding is logged either way.
+func (a *App) audit() error {
+	if a.config == nil {
+		return nil
+	}
+	report := a.config.Doctor()
+	for _, f := range report.Findings {
+		a.logger.Warn("astra: configuration audit", "key", f.Key, "severity", string(f.Severity), "problem", f.Message)
+	}
+	err := report.Err()
+	if err == nil {
+		return nil
+	}
+	if skip, _ := strconv.ParseBool(os.Getenv(SkipAuditEnv)); skip {
+		a.logger.Error("astra: booting despite a failed configuration audit because " + SkipAuditEnv + " is set")
+		return nil
+	}
+	return err
+}
@@ -3,11 +3,17 @@
 package event
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"reflect"
 	"runtime/debug"
+	"slices"
 	"sync"
+
+	"github.com/shauryagautam/Astra/pkg/support/txhook"
 )
 
 // Event is the interface that all events must implement.
@@ -38,17 +44,36 @@ type BaseEvent struct {
 func (e BaseEvent) Name() string { return e.EventName }
 func (e BaseEvent) Data() any    { return e.EventData }
 
+// ErrorPolicy decides what Dispatch does when a listener returns an error.
+type ErrorPolicy int
+
+const (
+	// CollectErrors runs every listener and returns all errors joined with
+	// errors.Join. It is the default.
+	CollectErrors ErrorPolicy = iota
+	// StopOnError skips the remaining listeners after the first error and
+	// returns it, for events whose later listeners depend on earlier ones.
+	StopOnError
+)
+
 // Emitter is a thread-safe in-process event bus.
 type Emitter struct {
 	mu        sync.RWMutex
-	listeners map[string][]Listener
+	listeners map[string][]registration
+	policy    ErrorPolicy
 	pool      chan struct{} // worker pool for async emissions
 }
 
+// registration is a listener with its priority.
+type registration struct {
+	listener Listener
+	priority int
+}
+
 // New creates a new Emitter with a default worker pool of 100.
 func New() *Emitter {
 	return &Emitter{
-		listeners: make(map[string][]Listener),
+		listeners: make(map[string][]registration),
 		pool:      make(chan struct{}, 100),
 	}
 }
@@ -56,11 +81,26 @@ func New() *Emitter {
 // DefaultEmitter is the package-level default emitter.
 var DefaultEmitter = New()
 
-// On registers a listener for an event name.
+// On registers a listener for an event name with priority 0.
 func (e *Emitter) On(eventName string, listener Listener) {
+	e.OnPriority(eventName, 0, listener)
+}
+
+// OnPriority registers a listener that runs before every listener with a
+// lower priority, "*" listeners included. Among listeners with the same
+// priority, those registered for the event name run before "*" ones, each
+// in registration order.
+func (e *Emitter) OnPriority(eventName string, priority int, listener Listener) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners[eventName] = append(e.listeners[eventName], registration{listener, priority})
+}
+
+// SetErrorPolicy sets how Dispatch handles listener errors.
+func (e *Emitter) SetErrorPolicy(policy ErrorPolicy) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.listeners[eventName] = append(e.listeners[eventName], listener)
+	e.policy = policy
 }
 
 // OnFunc is a helper to register a function as a listener.
@@ -92,16 +132,17 @@ func (e *Emitter) Off(eventName string, listener Listener) {
 		return
 	}
 
-	for i, l := range ls {
+	for i, r := range ls {
 		// Pointer comparison for precision
-		if reflect.ValueOf(l).Pointer() == reflect.ValueOf(listener).Pointer() {
+		if reflect.ValueOf(r.listener).Pointer() == reflect.ValueOf(listener).Pointer() {
 			e.listeners[eventName] = append(ls[:i], ls[i+1:]...)
 			break
 		}
 	}
 }
 
-// Emit fires all listeners for the given event synchronously.
+// Emit fires all listeners for the given event synchronously. Errors and
+// panics are logged; use Dispatch to handle them.
 func (e *Emitter) Emit(ctx context.Context, event Event) {
 	ls := e.getListeners(event.Name())
 	for _, l := range ls {
@@ -109,6 +150,66 @@ func (e *Emitter) Emit(ctx context.Context, event Event) {
 	}
 }
 
+// Dispatch fires all listeners for the given event synchronously and
+// returns their errors according to the emitter's ErrorPolicy. A panicking
+// listener is reported as an error instead of crashing the caller.
+func (e *Emitter) Dispatch(ctx context.Context, event Event) error {
+	e.mu.RLock()
+	policy := e.policy
+	e.mu.RUnlock()
+
+	var errs []error
+	for _, l := range e.getListeners(event.Name()) {
+		if err := callListener(ctx, l, event); err != nil {
+			if policy == StopOnError {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EmitAfterCommit emits event once the database transaction ctx runs in
+// commits, and drops it when the transaction rolls back, so listeners never
+// act on data that was not saved. Outside a transaction it emits right
+// away.
+func (e *Emitter) EmitAfterCommit(ctx context.Context, event Event) {
+	txhook.AfterCommit(ctx, func() { e.Emit(txhook.Detach(ctx), event) })
+}
+
+// HasListeners reports whether emitting eventName would reach any
+// listener, counting "*" listeners.
+func (e *Emitter) HasListeners(eventName string) bool {
+	return e.ListenerCount(eventName) > 0
+}
+
+// ListenerCount returns how many listeners emitting eventName would call,
+// counting "*" listeners.
+func (e *Emitter) ListenerCount(eventName string) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	n := len(e.listeners[eventName])
+	if eventName != "*" {
+		n += len(e.listeners["*"])
+	}
+	return n
+}
+
+// Events returns the names with registered listeners and how many each
+// has, not counting "*" listeners, for debugging tools.
+func (e *Emitter) Events() map[string]int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make(map[string]int, len(e.listeners))
+	for name, ls := range e.listeners {
+		if len(ls) > 0 {
+			out[name] = len(ls)
+		}
+	}
+	return out
+}
+
 // EmitAsync fires all listeners for the given event using a fixed worker pool.
 func (e *Emitter) EmitAsync(ctx context.Context, event Event) {
 	ls := e.getListeners(event.Name())
@@ -129,17 +230,37 @@ func (e *Emitter) getListeners(eventName string) []Listener {
 	specific := e.listeners[eventName]
 
 	// 2. Get wildcard listeners
-	wildcards := e.listeners["*"]
-
-	ls := make([]Listener, 0, len(specific)+len(wildcards))
-	ls = append(ls, specific...)
+	var wildcards []registration
 	if eventName != "*" {
-		ls = append(ls, wildcards...)
+		wildcards = e.listeners["*"]
 	}
 
+	regs := make([]registration, 0, len(specific)+len(wildcards))
+	regs = append(regs, specific...)
+	regs = append(regs, wildcards...)
+	// Stable, so equal priorities keep the order above: specific
+	// listeners, then "*" ones, each in registration order.
+	slices.SortStableFunc(regs, func(a, b registration) int {
+		return cmp.Compare(b.priority, a.priority)
+	})
+
+	ls := make([]Listener, len(regs))
+	for i, r := range regs {
+		ls[i] = r.listener
+	}
 	return ls
 }
 
+// callListener runs l, turning a panic into an error.
+func callListener(ctx context.Context, l Listener, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event: listener for %s panicked: %v", event.Name(), r)
+		}
+	}()
+	return l.Handle(ctx, event)
+}
+
 func (e *Emitter) safeHandle(ctx context.Context, l Listener, event Event) {
 	defer func() {
 		if r := recover(); r != nil {
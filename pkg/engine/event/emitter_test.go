@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 )
 
@@ -93,3 +94,77 @@ func TestEmitter_ListenerError(t *testing.T) {
 	time.Sleep(10 * time.Millisecond)
 	assert.True(t, listener.called)
 }
+
+func TestEmitter_Priority(t *testing.T) {
+	emitter := New()
+	var order []string
+	record := func(name string) ListenerFunc {
+		return func(ctx context.Context, e Event) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	emitter.On("*", record("wildcard-first"))
+	emitter.OnFunc("test.event", record("default"))
+	emitter.OnPriority("*", 10, record("wildcard-high"))
+	emitter.OnPriority("test.event", 10, record("high"))
+	emitter.OnPriority("test.event", -5, record("low"))
+	emitter.On("*", record("wildcard-default"))
+
+	require.NoError(t, emitter.Dispatch(context.Background(), testEvent{}))
+	// At equal priority, the event's own listeners run before "*" ones.
+	assert.Equal(t, []string{"high", "wildcard-high", "default", "wildcard-first", "wildcard-default", "low"}, order)
+}
+
+func TestEmitter_DispatchErrorPolicy(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	newEmitter := func() (*Emitter, *mockListener) {
+		emitter := New()
+		last := &mockListener{}
+		emitter.OnFunc("test.event", func(ctx context.Context, e Event) error { return errA })
+		emitter.OnFunc("test.event", func(ctx context.Context, e Event) error { return errB })
+		emitter.On("test.event", last)
+		return emitter, last
+	}
+
+	t.Run("collect", func(t *testing.T) {
+		emitter, last := newEmitter()
+		err := emitter.Dispatch(context.Background(), testEvent{})
+		assert.ErrorIs(t, err, errA)
+		assert.ErrorIs(t, err, errB)
+		assert.True(t, last.called)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		emitter, last := newEmitter()
+		emitter.SetErrorPolicy(StopOnError)
+		err := emitter.Dispatch(context.Background(), testEvent{})
+		assert.Equal(t, errA, err)
+		assert.False(t, last.called)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		emitter := New()
+		emitter.OnFunc("test.event", func(ctx context.Context, e Event) error { panic("boom") })
+		err := emitter.Dispatch(context.Background(), testEvent{})
+		assert.ErrorContains(t, err, "boom")
+	})
+}
+
+func TestEmitter_Introspection(t *testing.T) {
+	emitter := New()
+	assert.False(t, emitter.HasListeners("test.event"))
+	assert.Equal(t, 0, emitter.ListenerCount("test.event"))
+
+	listener := &mockListener{}
+	emitter.On("test.event", listener)
+	emitter.On("*", &mockListener{})
+	assert.True(t, emitter.HasListeners("test.event"))
+	assert.Equal(t, 2, emitter.ListenerCount("test.event"))
+	assert.Equal(t, 1, emitter.ListenerCount("other.event"))
+	assert.Equal(t, map[string]int{"test.event": 1, "*": 1}, emitter.Events())
+
+	emitter.Off("test.event", listener)
+	assert.Equal(t, 1, emitter.ListenerCount("test.event"))
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrAborted is matched, with errors.Is, by the errors of Abort and
+// AbortWith. It tells a deliberate stop, such as a failed permission check,
+// from an unexpected failure.
+var ErrAborted = errors.New("http: request aborted")
+
+// AbortError is the error returned by Abort and AbortWith. It unwraps to
+// both Err and ErrAborted, so error handlers still find the *HTTPError or
+// *errors.Error inside.
+type AbortError struct {
+	Err error
+}
+
+func (e *AbortError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AbortError) Unwrap() []error {
+	return []error{e.Err, ErrAborted}
+}
+
+// Abort stops the request with status. Return its result from the handler;
+// the router's ErrorHandler renders it like any *HTTPError. The message
+// defaults to the status text.
+//
+//	if !c.IsAuthenticated() {
+//	    return c.Abort(http.StatusUnauthorized, "unauthenticated")
+//	}
+func (c *Context) Abort(status int, message ...string) error {
+	msg := http.StatusText(status)
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	return c.AbortWith(&HTTPError{Status: status, Message: msg})
+}
+
+// AbortWith stops the request with err, typically an *errors.Error from
+// pkg/errors, which keeps its own status and code:
+//
+//	return c.AbortWith(astraerrors.Forbidden("not your team"))
+func (c *Context) AbortWith(err error) error {
+	if err == nil {
+		err = &HTTPError{Status: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError)}
+	}
+	var abort *AbortError
+	if !errors.As(err, &abort) {
+		err = &AbortError{Err: err}
+	}
+	c.err = err
+	return err
+}
+
+// IsAborted reports whether the handler stopped the request with Abort or
+// AbortWith.
+func (c *Context) IsAborted() bool {
+	return errors.Is(c.err, ErrAborted)
+}
+
+// HandlerError returns the error the route's handler returned, once it has
+// run. Middleware reads it after calling next, where the response is
+// already written, to tell aborts from failures:
+//
+//	next.ServeHTTP(w, r)
+//	if err := c.HandlerError(); err != nil && !IsAbort(err) {
+//	    reporter.Capture(err)
+//	}
+func (c *Context) HandlerError() error {
+	return c.err
+}
+
+// IsAbort reports whether err comes from Abort or AbortWith.
+func IsAbort(err error) bool {
+	return errors.Is(err, ErrAborted)
+}
+
+// abortStatus returns the status of an abort error, for routers without
+// an ErrorHandler.
+func abortStatus(err error) (int, string) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status, httpErr.Message
+	}
+	var coded statusCoder
+	if errors.As(err, &coded) {
+		return coded.HTTPStatus(), err.Error()
+	}
+	return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+}
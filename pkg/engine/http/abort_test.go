@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_Abort(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, nil)
+
+	var seen []error
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			if err := FromRequest(r).HandlerError(); err != nil && !IsAbort(err) {
+				seen = append(seen, err)
+			}
+		})
+	})
+	router.Get("/private", func(c *Context) error {
+		return c.Abort(http.StatusUnauthorized, "unauthenticated")
+	})
+	router.Get("/gone", func(c *Context) error { return c.Abort(http.StatusGone) })
+	router.Get("/forbidden", func(c *Context) error {
+		return c.AbortWith(astraerrors.Forbidden("not your team"))
+	})
+	router.Get("/boom", func(c *Context) error { return errors.New("db down") })
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/private")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unauthenticated")
+	assert.Equal(t, http.StatusGone, get("/gone").Code)
+	assert.Equal(t, http.StatusForbidden, get("/forbidden").Code)
+	assert.Equal(t, http.StatusInternalServerError, get("/boom").Code)
+
+	// Only the unexpected failure reaches the reporting middleware.
+	require.Len(t, seen, 1)
+	assert.EqualError(t, seen[0], "db down")
+}
+
+func TestContext_AbortErrorUnwraps(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	defer c.release()
+
+	assert.False(t, c.IsAborted())
+	err := c.Abort(http.StatusPaymentRequired, "upgrade your plan")
+	assert.True(t, c.IsAborted())
+	assert.ErrorIs(t, err, ErrAborted)
+
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusPaymentRequired, httpErr.Status)
+
+	// Aborting again with an abort error does not wrap it twice.
+	assert.Same(t, err, c.AbortWith(err))
+
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).Handle
+	router.Get("/api/billing", func(c *Context) error { return c.AbortWith(astraerrors.Forbidden("no seats left")) })
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/billing", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "no seats left")
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"reflect"
 )
 
 // ─── Standard Error Codes ─────────────────────────────────────────────
@@ -51,6 +52,84 @@ type CursorMeta struct {
 	HasMore    bool   `json:"has_more"`
 }
 
+// ─── Envelopes ────────────────────────────────────────────────────────
+
+// Envelope decides the JSON shape every API response shares. The success
+// helpers, the error helpers, ValidateRequest and InteractiveErrorHandler all
+// go through it, so changing Router.Envelope changes every endpoint at once.
+type Envelope interface {
+	// Success wraps response data and optional metadata such as pagination.
+	Success(data any, meta map[string]any) any
+	// Error wraps a failed request.
+	Error(status int, code, message string, details map[string]any) any
+}
+
+// DefaultEnvelope renders APIResponse and APIError:
+//
+//	{"data": ..., "meta": {...}}
+//	{"error": {"code": "NOT_FOUND", "message": "User not found"}}
+type DefaultEnvelope struct{}
+
+func (DefaultEnvelope) Success(data any, meta map[string]any) any {
+	return APIResponse{Data: data, Meta: meta}
+}
+
+func (DefaultEnvelope) Error(_ int, code, message string, details map[string]any) any {
+	return APIError{Error: APIErrorBody{Code: code, Message: message, Details: details}}
+}
+
+// FlaggedEnvelope adds a success flag and reports errors as a list, the
+// shape many frontend clients expect:
+//
+//	{"success": true, "data": ..., "meta": {...}}
+//	{"success": false, "errors": [{"code": "NOT_FOUND", "message": "User not found"}]}
+type FlaggedEnvelope struct{}
+
+type flaggedResponse struct {
+	Success bool           `json:"success"`
+	Data    any            `json:"data,omitempty"`
+	Meta    map[string]any `json:"meta,omitempty"`
+	Errors  []APIErrorBody `json:"errors,omitempty"`
+}
+
+func (FlaggedEnvelope) Success(data any, meta map[string]any) any {
+	return flaggedResponse{Success: true, Data: data, Meta: meta}
+}
+
+func (FlaggedEnvelope) Error(_ int, code, message string, details map[string]any) any {
+	return flaggedResponse{Errors: []APIErrorBody{{Code: code, Message: message, Details: details}}}
+}
+
+// Transformer is implemented by values that control their own API
+// representation, such as a model hiding internal columns. The success
+// helpers call Transform on the data, and on each element of a slice of
+// transformers, before it is enveloped.
+type Transformer interface {
+	Transform() any
+}
+
+func transform(data any) any {
+	if t, ok := data.(Transformer); ok {
+		return t.Transform()
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice || !v.Type().Elem().Implements(reflect.TypeFor[Transformer]()) {
+		return data
+	}
+	out := make([]any, v.Len())
+	for i := range out {
+		out[i] = v.Index(i).Interface().(Transformer).Transform()
+	}
+	return out
+}
+
+func (c *Context) envelope() Envelope {
+	if c.env == nil {
+		return DefaultEnvelope{}
+	}
+	return c.env
+}
+
 // ─── Success Helpers ──────────────────────────────────────────────────
 
 // Success sends a 200 JSON response wrapped in the standard envelope.
@@ -58,7 +137,7 @@ type CursorMeta struct {
 //	c.Success(user)
 //	→ {"data": {...}}
 func (c *Context) Success(data any) error {
-	return c.JSON(APIResponse{Data: data})
+	return c.JSON(c.envelope().Success(transform(data), nil))
 }
 
 // SuccessWithMeta sends a 200 JSON response with custom metadata.
@@ -66,7 +145,7 @@ func (c *Context) Success(data any) error {
 //	c.SuccessWithMeta(users, map[string]any{"cached": true})
 //	→ {"data": [...], "meta": {"cached": true}}
 func (c *Context) SuccessWithMeta(data any, meta map[string]any) error {
-	return c.JSON(APIResponse{Data: data, Meta: meta})
+	return c.JSON(c.envelope().Success(transform(data), meta))
 }
 
 // ─── Paginated Helpers ────────────────────────────────────────────────
@@ -77,15 +156,12 @@ func (c *Context) SuccessWithMeta(data any, meta map[string]any) error {
 //	result, _ := qb.Paginate(ctx, page, perPage)
 //	c.PaginatedJSON(result.Data, result.Total, result.Page, result.PerPage, result.LastPage)
 func (c *Context) PaginatedJSON(data any, total, page, perPage, lastPage int) error {
-	return c.JSON(APIResponse{
-		Data: data,
-		Meta: map[string]any{
-			"pagination": PaginationMeta{
-				Total:    total,
-				Page:     page,
-				PerPage:  perPage,
-				LastPage: lastPage,
-			},
+	return c.SuccessWithMeta(data, map[string]any{
+		"pagination": PaginationMeta{
+			Total:    total,
+			Page:     page,
+			PerPage:  perPage,
+			LastPage: lastPage,
 		},
 	})
 }
@@ -95,13 +171,10 @@ func (c *Context) PaginatedJSON(data any, total, page, perPage, lastPage int) er
 //	result, _ := qb.CursorPaginate(ctx, "id", cursor, limit)
 //	c.CursorJSON(result.Data, result.NextCursor, result.HasMore)
 func (c *Context) CursorJSON(data any, nextCursor string, hasMore bool) error {
-	return c.JSON(APIResponse{
-		Data: data,
-		Meta: map[string]any{
-			"cursor": CursorMeta{
-				NextCursor: nextCursor,
-				HasMore:    hasMore,
-			},
+	return c.SuccessWithMeta(data, map[string]any{
+		"cursor": CursorMeta{
+			NextCursor: nextCursor,
+			HasMore:    hasMore,
 		},
 	})
 }
@@ -112,13 +185,7 @@ func (c *Context) CursorJSON(data any, nextCursor string, hasMore bool) error {
 //
 //	c.ErrorWithDetails(409, "CONFLICT", "email taken", map[string]any{"field": "email"})
 func (c *Context) ErrorWithDetails(status int, code string, message string, details map[string]any) error {
-	return c.JSON(APIError{
-		Error: APIErrorBody{
-			Code:    code,
-			Message: message,
-			Details: details,
-		},
-	}, status)
+	return c.JSON(c.envelope().Error(status, code, message, details), status)
 }
 
 // NotFoundError sends a 404 error for a specific resource type.
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
+	"github.com/shauryagautam/Astra/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type apiUser struct {
+	ID       int
+	Password string
+}
+
+func (u apiUser) Transform() any { return map[string]any{"id": u.ID} }
+
+func TestEnvelopes(t *testing.T) {
+	cases := []struct {
+		name     string
+		envelope Envelope
+		success  string
+		page     string
+		failure  string
+	}{
+		{
+			name:    "default",
+			success: `{"data":{"id":1}}`,
+			page:    `{"data":[{"id":1},{"id":2}],"meta":{"pagination":{"total":2,"page":1,"per_page":10,"last_page":1}}}`,
+			failure: `{"error":{"code":"CONFLICT","message":"email taken"}}`,
+		},
+		{
+			name:     "flagged",
+			envelope: FlaggedEnvelope{},
+			success:  `{"success":true,"data":{"id":1}}`,
+			page:     `{"success":true,"data":[{"id":1},{"id":2}],"meta":{"pagination":{"total":2,"page":1,"per_page":10,"last_page":1}}}`,
+			failure:  `{"success":false,"errors":[{"code":"CONFLICT","message":"email taken"}]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := NewRouter(&config.AstraConfig{}, nil)
+			router.Envelope = tc.envelope
+			router.Get("/api/user", func(c *Context) error { return c.Success(apiUser{ID: 1, Password: "secret"}) })
+			router.Get("/api/users", func(c *Context) error {
+				return c.PaginatedJSON([]apiUser{{ID: 1}, {ID: 2}}, 2, 1, 10, 1)
+			})
+			router.Get("/api/fail", func(c *Context) error { return c.ConflictError("email taken") })
+
+			for path, want := range map[string]string{"/api/user": tc.success, "/api/users": tc.page, "/api/fail": tc.failure} {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				assert.JSONEq(t, want, rec.Body.String(), path)
+			}
+		})
+	}
+}
+
+func TestInteractiveErrorHandler_UsesEnvelope(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.Envelope = FlaggedEnvelope{}
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).Handle
+	router.Get("/api/team", func(c *Context) error {
+		return astraerrors.New(astraerrors.ErrCodeTenantNotFound, "no such team").WithDetail("team", "acme")
+	})
+	router.Get("/api/signup", func(c *Context) error {
+		ve := validate.NewValidationErrors()
+		ve.Add("email", "email is required")
+		return ve
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/team")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"success":false,"errors":[{"code":"TENANT_NOT_FOUND","message":"no such team","details":{"team":"acme"}}]}`, rec.Body.String())
+
+	rec = get("/api/signup")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"success":false,"errors":[{"code":"VALIDATION_ERROR","message":"the given data was invalid","details":{"fields":{"email":["email is required"]}}}]}`, rec.Body.String())
+}
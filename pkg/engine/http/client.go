@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+)
+
+// ClientConfig controls how a Context identifies the client behind a
+// request, through ClientIP, UserAgent, Fingerprint and Geo.
+type ClientConfig struct {
+	// TrustedProxies may supply the client address in forwarded headers.
+	// NewRouter fills it from TRUSTED_PROXIES.
+	TrustedProxies []netip.Prefix
+	// GeoIP resolves client addresses to locations for Context.Geo. It is
+	// called on every Geo call, so wrap slow lookups in a cache.
+	GeoIP GeoResolver
+}
+
+// GeoLocation is where a client address is, as far as a GeoResolver knows.
+type GeoLocation struct {
+	CountryCode string  `json:"country_code"` // ISO 3166-1 alpha-2, such as "DE"
+	Country     string  `json:"country,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	City        string  `json:"city,omitempty"`
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+}
+
+// GeoResolver looks up the location of an address. Implement it over a
+// MaxMind database, a lookup API, or the country header a CDN adds. It
+// returns nil, nil for addresses it does not know.
+type GeoResolver interface {
+	Resolve(ctx context.Context, ip netip.Addr) (*GeoLocation, error)
+}
+
+// GeoResolverFunc adapts a function to a GeoResolver.
+type GeoResolverFunc func(ctx context.Context, ip netip.Addr) (*GeoLocation, error)
+
+func (f GeoResolverFunc) Resolve(ctx context.Context, ip netip.Addr) (*GeoLocation, error) {
+	return f(ctx, ip)
+}
+
+// UserAgent parses the request's User-Agent header. Use c.Request.UserAgent()
+// for the raw value.
+func (c *Context) UserAgent() UserAgent {
+	return ParseUserAgent(c.Request.UserAgent())
+}
+
+// Fingerprint returns a stable identifier for the client's address and
+// browser: a hex SHA-256 of ClientIP and the User-Agent header. Store it
+// with a session and compare on later requests to spot a session used from
+// somewhere new. It is not anonymous; an IPv4 address can be recovered from
+// it by brute force, so treat it like the address itself.
+func (c *Context) Fingerprint() string {
+	sum := sha256.Sum256([]byte(c.ClientIP() + "\x00" + c.Request.UserAgent()))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Geo resolves the client's location with the router's ClientConfig.GeoIP.
+// It returns nil, nil when no resolver is set and for private, loopback and
+// other addresses that have no location.
+func (c *Context) Geo() (*GeoLocation, error) {
+	if c.client.GeoIP == nil {
+		return nil, nil
+	}
+	ip, err := netip.ParseAddr(c.ClientIP())
+	if err != nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return nil, nil
+	}
+	return c.client.GeoIP.Resolve(c.Ctx(), ip.Unmap())
+}
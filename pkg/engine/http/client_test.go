@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	cases := []struct {
+		name, raw string
+		want      UserAgent
+		str       string
+	}{
+		{
+			name: "chrome on macos",
+			raw:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.6478.127 Safari/537.36",
+			want: UserAgent{Browser: "Chrome", BrowserVersion: "126.0.6478.127", OS: "macOS", OSVersion: "10.15.7", Device: DeviceDesktop},
+			str:  "Chrome 126 on macOS 10.15.7",
+		},
+		{
+			name: "edge on windows",
+			raw:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36 Edg/125.0.2535.92",
+			want: UserAgent{Browser: "Edge", BrowserVersion: "125.0.2535.92", OS: "Windows", OSVersion: "10", Device: DeviceDesktop},
+			str:  "Edge 125 on Windows 10",
+		},
+		{
+			name: "safari on iphone",
+			raw:  "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+			want: UserAgent{Browser: "Safari", BrowserVersion: "17.5", OS: "iOS", OSVersion: "17.5", Device: DeviceMobile},
+			str:  "Safari 17 on iOS 17.5",
+		},
+		{
+			name: "firefox on android tablet",
+			raw:  "Mozilla/5.0 (Android 14; Tablet; rv:127.0) Gecko/127.0 Firefox/127.0",
+			want: UserAgent{Browser: "Firefox", BrowserVersion: "127.0", OS: "Android", OSVersion: "14", Device: DeviceTablet},
+			str:  "Firefox 127 on Android 14",
+		},
+		{
+			name: "crawler",
+			raw:  "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: UserAgent{Device: DeviceBot},
+			str:  "Unknown browser",
+		},
+		{
+			name: "empty",
+			raw:  "",
+			want: UserAgent{},
+			str:  "Unknown browser",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ParseUserAgent(tc.raw)
+			tc.want.Raw = tc.raw
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.str, got.String())
+		})
+	}
+	assert.True(t, ParseUserAgent("curl/8.6.0").IsBot())
+	assert.True(t, ParseUserAgent(cases[2].raw).IsMobile())
+}
+
+func TestContext_ClientIdentity(t *testing.T) {
+	var resolved netip.Addr
+	router := NewRouter(nil, nil)
+	router.Clients.TrustedProxies = ParsePrefixes("10.0.0.0/8")
+	router.Clients.GeoIP = GeoResolverFunc(func(_ context.Context, ip netip.Addr) (*GeoLocation, error) {
+		resolved = ip
+		return &GeoLocation{CountryCode: "DE", City: "Berlin"}, nil
+	})
+
+	var ip, fingerprint string
+	var geo *GeoLocation
+	router.Get("/whoami", func(c *Context) error {
+		ip, fingerprint = c.ClientIP(), c.Fingerprint()
+		var err error
+		geo, err = c.Geo()
+		return err
+	})
+
+	serve := func(remote, forwarded, ua string) {
+		req := httptest.NewRequest("GET", "/whoami", nil)
+		req.RemoteAddr = remote
+		req.Header.Set("X-Forwarded-For", forwarded)
+		req.Header.Set("User-Agent", ua)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Forwarded headers are believed from a trusted proxy only.
+	serve("10.1.2.3:5000", "203.0.113.7", "agent/1")
+	assert.Equal(t, "203.0.113.7", ip)
+	require.NotNil(t, geo)
+	assert.Equal(t, "Berlin", geo.City)
+	assert.Equal(t, netip.MustParseAddr("203.0.113.7"), resolved)
+	first := fingerprint
+	assert.Len(t, first, 32)
+
+	// Same client on a new connection: same fingerprint.
+	serve("10.9.9.9:6000", "203.0.113.7", "agent/1")
+	assert.Equal(t, first, fingerprint)
+
+	// Another browser: another fingerprint.
+	serve("10.1.2.3:5000", "203.0.113.7", "agent/2")
+	assert.NotEqual(t, first, fingerprint)
+
+	// An untrusted peer cannot claim an address; private addresses have no
+	// location.
+	serve("192.168.1.5:7000", "203.0.113.7", "agent/1")
+	assert.Equal(t, "192.168.1.5", ip)
+	assert.Nil(t, geo)
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ConcurrencyLimitConfig configures a ConcurrencyLimiter.
+type ConcurrencyLimitConfig struct {
+	// MaxInFlight is how many requests may run at once (default:
+	// runtime.GOMAXPROCS*16). Size it to what the database pool can serve.
+	MaxInFlight int
+	// QueueSize is how many requests may wait for a slot. Once it is full,
+	// new requests get a 503 at once. 0 means no waiting.
+	QueueSize int
+	// MaxWait is how long a queued request waits before it gets a 503
+	// (default: 1s).
+	MaxWait time.Duration
+	// RetryAfter is the Retry-After value sent with the 503, in seconds
+	// (default: 1).
+	RetryAfter int
+}
+
+// ConcurrencyStats is a snapshot of a ConcurrencyLimiter.
+type ConcurrencyStats struct {
+	Name      string `json:"name"`
+	Limit     int    `json:"limit"`
+	InFlight  int    `json:"in_flight"`
+	Waiting   int    `json:"waiting"`
+	QueueSize int    `json:"queue_size"`
+	Served    int64  `json:"served"`
+	Rejected  int64  `json:"rejected"`
+	// Saturation is InFlight plus Waiting over Limit plus QueueSize; at 1
+	// every further request is rejected.
+	Saturation float64 `json:"saturation"`
+}
+
+// ConcurrencyLimiter caps the requests running at once through its
+// Middleware, with a bounded queue in front. Use one limiter with
+// Router.Use for a global cap, and separate ones with Router.With for
+// expensive routes:
+//
+//	router.Use(http.NewConcurrencyLimiter("global", http.ConcurrencyLimitConfig{MaxInFlight: 200, QueueSize: 100}).Middleware())
+//	reports := http.NewConcurrencyLimiter("reports", http.ConcurrencyLimitConfig{MaxInFlight: 4, QueueSize: 8, MaxWait: 5 * time.Second})
+//	router.With(reports.Middleware()).Get("/reports/{id}", rc.Show)
+type ConcurrencyLimiter struct {
+	name  string
+	cfg   ConcurrencyLimitConfig
+	slots chan struct{}
+
+	waiting  atomic.Int64
+	served   atomic.Int64
+	rejected atomic.Int64
+}
+
+// NewConcurrencyLimiter creates a limiter; name identifies it in Stats and
+// metrics.
+func NewConcurrencyLimiter(name string, cfg ConcurrencyLimitConfig) *ConcurrencyLimiter {
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = runtime.GOMAXPROCS(0) * 16
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = time.Second
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = 1
+	}
+	return &ConcurrencyLimiter{name: name, cfg: cfg, slots: make(chan struct{}, cfg.MaxInFlight)}
+}
+
+// ConcurrencyLimit is a shorthand for a single global limiter's middleware.
+func ConcurrencyLimit(cfg ConcurrencyLimitConfig) MiddlewareFunc {
+	return NewConcurrencyLimiter("global", cfg).Middleware()
+}
+
+// Middleware returns the middleware enforcing the limit. Rejected requests
+// get a 503 with Retry-After and an X-Astra-Shed-Reason of "queue-full" or
+// "queue-timeout".
+func (l *ConcurrencyLimiter) Middleware() MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if reason := l.acquire(r.Context()); reason != "" {
+				if reason != "canceled" {
+					l.rejected.Add(1)
+					shedRequest(w, l.cfg.RetryAfter, reason)
+				}
+				return
+			}
+			defer func() { <-l.slots }()
+			l.served.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acquire takes a slot, waiting in the queue when there is room, and
+// returns why it could not, or "".
+func (l *ConcurrencyLimiter) acquire(ctx context.Context) string {
+	select {
+	case l.slots <- struct{}{}:
+		return ""
+	default:
+	}
+	if l.waiting.Add(1) > int64(l.cfg.QueueSize) {
+		l.waiting.Add(-1)
+		return "queue-full"
+	}
+	defer l.waiting.Add(-1)
+
+	timer := time.NewTimer(l.cfg.MaxWait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return ""
+	case <-timer.C:
+		return "queue-timeout"
+	case <-ctx.Done():
+		return "canceled"
+	}
+}
+
+// Stats returns the limiter's current saturation and counters.
+func (l *ConcurrencyLimiter) Stats() ConcurrencyStats {
+	s := ConcurrencyStats{
+		Name:      l.name,
+		Limit:     l.cfg.MaxInFlight,
+		InFlight:  len(l.slots),
+		Waiting:   int(l.waiting.Load()),
+		QueueSize: l.cfg.QueueSize,
+		Served:    l.served.Load(),
+		Rejected:  l.rejected.Load(),
+	}
+	s.Saturation = float64(s.InFlight+s.Waiting) / float64(s.Limit+s.QueueSize)
+	return s
+}
+
+// Observe registers OTel gauges for the limiter's in-flight and waiting
+// requests and a counter for rejections, labelled with its name.
+func (l *ConcurrencyLimiter) Observe(meter metric.Meter) error {
+	if meter == nil {
+		return nil
+	}
+	attrs := metric.WithAttributes(attribute.String("limiter", l.name))
+
+	_, err := meter.Int64ObservableGauge("http.server.concurrency.in_flight",
+		metric.WithDescription("Requests holding a concurrency slot"),
+		metric.WithInt64Callback(func(_ context.Context, obs metric.Int64Observer) error {
+			obs.Observe(int64(len(l.slots)), attrs)
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("astra/http: failed to create in-flight gauge: %w", err)
+	}
+
+	_, err = meter.Int64ObservableGauge("http.server.concurrency.waiting",
+		metric.WithDescription("Requests queued for a concurrency slot"),
+		metric.WithInt64Callback(func(_ context.Context, obs metric.Int64Observer) error {
+			obs.Observe(l.waiting.Load(), attrs)
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("astra/http: failed to create waiting gauge: %w", err)
+	}
+
+	_, err = meter.Int64ObservableCounter("http.server.concurrency.rejected",
+		metric.WithDescription("Requests rejected with 503 by the concurrency limiter"),
+		metric.WithInt64Callback(func(_ context.Context, obs metric.Int64Observer) error {
+			obs.Observe(l.rejected.Load(), attrs)
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("astra/http: failed to create rejected counter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := NewConcurrencyLimiter("db", ConcurrencyLimitConfig{MaxInFlight: 1, QueueSize: 1, MaxWait: time.Second})
+	release := make(chan struct{})
+	entered := make(chan struct{}, 2)
+	handler := limiter.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serve().Code
+		}()
+		if i == 0 {
+			<-entered
+		}
+	}
+	require.Eventually(t, func() bool { return limiter.Stats().Waiting == 1 }, time.Second, time.Millisecond)
+
+	stats := limiter.Stats()
+	assert.Equal(t, 1, stats.InFlight)
+	assert.InDelta(t, 1.0, stats.Saturation, 0.001)
+
+	// Slot and queue are full: rejected at once.
+	rec := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "queue-full", rec.Header().Get("X-Astra-Shed-Reason"))
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+	stats = limiter.Stats()
+	assert.EqualValues(t, 2, stats.Served)
+	assert.EqualValues(t, 1, stats.Rejected)
+	assert.Zero(t, stats.InFlight)
+}
+
+func TestConcurrencyLimiter_QueueTimeout(t *testing.T) {
+	limiter := NewConcurrencyLimiter("slow", ConcurrencyLimitConfig{MaxInFlight: 1, QueueSize: 1, MaxWait: 10 * time.Millisecond})
+	release := make(chan struct{})
+	defer close(release)
+	handler := limiter.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Eventually(t, func() bool { return limiter.Stats().InFlight == 1 }, time.Second, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "queue-timeout", rec.Header().Get("X-Astra-Shed-Reason"))
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	nethttp "net/http"
 	"sync"
 
@@ -15,9 +17,13 @@ import (
 type contextKey string
 
 // HTTPError represents an error that occurred during the processing of an HTTP request.
+// Code, when set, is the code of the JSON error envelope, typically one of
+// the framework codes of pkg/errors such as E_ROUTE_NOT_FOUND; otherwise the
+// code is derived from Status.
 type HTTPError struct {
 	Status  int
 	Message string
+	Code    string
 }
 
 func (e *HTTPError) Error() string {
@@ -28,13 +34,33 @@ const astraContextKey contextKey = "astra_context"
 const AuthUserKey = "astra_auth_user"
 
 // Context represents the Astra-specific request/response context.
-// It is recycled via a sync.Pool to minimize GC pressure.
+// It is recycled via a sync.Pool to minimize GC pressure, so it must not be
+// used once the handler has returned. Goroutines the handler starts should
+// receive c.Ctx(), or c.Detach() if they outlive the request, rather than c.
+// Set, Get, Ctx and Detach may be called concurrently; the other methods
+// may not.
 type Context struct {
-	Writer     nethttp.ResponseWriter
-	Request    *nethttp.Request
-	status     int
-	written    bool
-	params     map[string]string
+	Writer      nethttp.ResponseWriter
+	Request     *nethttp.Request
+	status      int
+	written     bool
+	params      map[string]string
+	json        JSONConfig
+	env         Envelope
+	files       FileConfig
+	serializers []Serializer
+	jsonLimits  JSONLimits
+	client      ClientConfig
+	cookies     CookieJar
+	resp        hookWriter
+	beforeSend  []func(*Context)
+	onFinished  []func(*Context)
+	trace       []MiddlewareStep
+	values      *requestValues
+	input       map[string]any
+	inputErr    error
+	// err is the error the handler returned, see HandlerError.
+	err error
 
 	// Explicit Dependencies
 	ViewEngine engine.ViewEngine
@@ -53,10 +79,25 @@ var contextPool = sync.Pool{
 // NewContext initializes or retrieves a Context from the pool.
 func NewContext(w nethttp.ResponseWriter, r *nethttp.Request) *Context {
 	c := contextPool.Get().(*Context)
-	c.Writer = w
-	c.Request = r
+	c.resp = hookWriter{ResponseWriter: w, c: c}
+	c.Writer = &c.resp
+	c.beforeSend = c.beforeSend[:0]
+	c.onFinished = c.onFinished[:0]
+	c.trace = c.trace[:0]
+	c.values = &requestValues{Context: r.Context(), c: c}
+	c.Request = r.WithContext(c.values)
 	c.written = false
 	c.status = 0
+	c.json = JSONConfig{}
+	c.env = nil
+	c.files = FileConfig{}
+	c.serializers = nil
+	c.jsonLimits = JSONLimits{}
+	c.client = ClientConfig{}
+	c.cookies = CookieJar{}
+	c.input = nil
+	c.inputErr = nil
+	c.err = nil
 	c.ViewEngine = nil
 	c.Translator = nil
 	c.Sessions = nil
@@ -68,7 +109,14 @@ func NewContext(w nethttp.ResponseWriter, r *nethttp.Request) *Context {
 }
 
 func (c *Context) release() {
+	c.finish()
 	c.Writer = nil
+	c.resp = hookWriter{}
+	clear(c.beforeSend)
+	clear(c.onFinished)
+	c.values.release()
+	c.values = nil
+	c.input = nil
 	c.Request = nil
 	contextPool.Put(c)
 }
@@ -87,6 +135,17 @@ func (c *Context) JSON(v any, status ...int) error {
 		return nil
 	}
 
+	// Encode before touching the response so an encoding error can still be
+	// reported with a proper status instead of a truncated body.
+	body, err := c.json.marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeJSON(body, status...)
+}
+
+// writeJSON sends an encoded JSON body.
+func (c *Context) writeJSON(body []byte, status ...int) error {
 	code := nethttp.StatusOK
 	if c.status != 0 {
 		code = c.status
@@ -95,10 +154,11 @@ func (c *Context) JSON(v any, status ...int) error {
 		code = status[0]
 	}
 
-	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.Header().Set("Content-Type", c.json.contentType())
 	c.Writer.WriteHeader(code)
 	c.written = true
-	return json.NewEncoder(c.Writer).Encode(v)
+	_, err := c.Writer.Write(append(body, '\n'))
+	return err
 }
 
 // Param retrieves a path parameter.
@@ -136,11 +196,19 @@ func (c *Context) Query(name string) string {
 	return c.Request.URL.Query().Get(name)
 }
 
-// Ctx returns the underlying request context.
+// Ctx returns the underlying request context. It carries the values
+// stored with Set and is canceled when the client goes away.
 func (c *Context) Ctx() context.Context {
 	return c.Request.Context()
 }
 
+// Detach returns the request context without its cancellation and
+// deadline, for work that outlives the response, such as a goroutine that
+// dispatches a job. It still carries the values stored with Set.
+func (c *Context) Detach() context.Context {
+	return context.WithoutCancel(c.Ctx())
+}
+
 // Status sets the HTTP response status code.
 func (c *Context) Status(code int) *Context {
 	c.status = code
@@ -167,14 +235,17 @@ func (c *Context) Redirect(url string, code int) error {
 	return nil
 }
 
-// Set stores a value in the request context (standard lib interop).
+// Set stores a value in the request context (standard lib interop). It is
+// visible through Ctx().Value(key) and r.Context().Value(key) on every
+// request derived from this one, including the r a middleware hands to next
+// after calling Set, and to goroutines already holding the context.
 func (c *Context) Set(key string, val any) {
-	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), key, val))
+	c.values.set(key, val)
 }
 
 // Get retrieves a value from the request context.
 func (c *Context) Get(key any) any {
-	return c.Request.Context().Value(key)
+	return c.Ctx().Value(key)
 }
 
 // GetString retrieves a string value from the request context.
@@ -185,9 +256,19 @@ func (c *Context) GetString(key string) string {
 	return ""
 }
 
-// Bind decodes the request body into v.
+// Bind decodes the JSON request body into v. A body over the router's
+// JSONLimits is not decoded: Bind returns a 400 or 413 *HTTPError instead.
+// Only the first JSON value in the body is decoded; anything after it is
+// ignored.
 func (c *Context) Bind(v any) error {
-	return json.NewDecoder(c.Request.Body).Decode(v)
+	body, err := c.readJSONBody()
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return io.EOF
+	}
+	return json.NewDecoder(bytes.NewReader(body)).Decode(v)
 }
 
 // T translates a key using the registered Translator.
@@ -195,11 +276,13 @@ func (c *Context) T(key string, args ...any) string {
 	if c.Translator == nil {
 		return key
 	}
-	return c.Translator.T("en", key, args...)
+	return c.Translator.T(c.Locale(), key, args...)
 }
 
+// Locale returns the request's locale, as resolved by the Locales
+// middleware or set with SetLocale, or "en".
 func (c *Context) Locale() string {
-	if locale, ok := c.Get("astra_locale").(string); ok {
+	if locale, ok := c.Get(ContextLocaleKey).(string); ok && locale != "" {
 		return locale
 	}
 	return "en"
@@ -236,10 +319,10 @@ func (c *Context) SendString(s string) error {
 	return err
 }
 
-// ClientIP returns the client's IP address.
+// ClientIP returns the client's address, without the port. Forwarded
+// headers are only believed from the router's ClientConfig.TrustedProxies.
 func (c *Context) ClientIP() string {
-	// Simple implementation, in production use X-Forwarded-For if behind proxy
-	return c.Request.RemoteAddr
+	return GetClientIP(c.Request, c.client.TrustedProxies)
 }
 
 // ─── auth.RequestContext Implementation ───────────────────────────────────────
@@ -259,8 +342,15 @@ func (c *Context) AuthUser() *identityclaims.AuthClaims {
 	return nil
 }
 
+// SetCookie sets cookie as given, with the router's CookieJar defaults
+// filling in what it leaves out: Path, Domain and SameSite when unset, and
+// Secure and HttpOnly when the jar turns them on. Since a false bool cannot
+// be told from an unset one, list the cookie in CookieJar.AsIs to keep its
+// own flags, or use SetCookieValue with WithCookieSecure or
+// WithCookieHTTPOnly. The value is written as is; SetCookieValue encrypts
+// it.
 func (c *Context) SetCookie(cookie *nethttp.Cookie) {
-	nethttp.SetCookie(c.Writer, cookie)
+	nethttp.SetCookie(c.Writer, c.cookies.withDefaults(cookie))
 }
 
 func (c *Context) RegenerateSession() error {
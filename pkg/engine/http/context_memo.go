@@ -0,0 +1,91 @@
+package http
+
+import "fmt"
+
+// memoEntry is a value computed by Context.Remember. done is closed once
+// val and err are set.
+type memoEntry struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// Remember returns the value memoized under key for this request, calling
+// fn to compute it the first time. Middleware and handlers that need the
+// same expensive data, such as the current user, the tenant or parsed
+// settings, share one computation:
+//
+//	user, err := c.Remember("current_user", func() (any, error) {
+//	    return users.Find(c.Ctx(), c.AuthUser().UserID)
+//	})
+//
+// Concurrent calls for the same key wait for the first one. Errors are not
+// memoized, so a later call tries again. fn must not call Remember with
+// its own key. See the generic Remember function for a typed result.
+func (c *Context) Remember(key string, fn func() (any, error)) (any, error) {
+	return c.values.remember(key, fn)
+}
+
+// Forget drops the value memoized under key, e.g. after the handler
+// changed the data it was computed from.
+func (c *Context) Forget(key string) {
+	c.values.mu.Lock()
+	delete(c.values.memo, key)
+	c.values.mu.Unlock()
+}
+
+// Remember is the typed form of Context.Remember:
+//
+//	tenant, err := http.Remember(c, "tenant", func() (*Tenant, error) {
+//	    return tenants.FromHost(c.Ctx(), c.Request.Host)
+//	})
+//
+// It returns an error when key already holds a value of another type.
+func Remember[T any](c *Context, key string, fn func() (T, error)) (T, error) {
+	v, err := c.Remember(key, func() (any, error) { return fn() })
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok && v != nil {
+		var zero T
+		return zero, fmt.Errorf("http: remembered value %q is a %T, not a %T", key, v, zero)
+	}
+	return t, nil
+}
+
+func (v *requestValues) remember(key string, fn func() (any, error)) (any, error) {
+	v.mu.Lock()
+	if e, ok := v.memo[key]; ok {
+		v.mu.Unlock()
+		<-e.done
+		return e.val, e.err
+	}
+	e := &memoEntry{done: make(chan struct{})}
+	if v.memo == nil {
+		v.memo = make(map[string]*memoEntry)
+	}
+	v.memo[key] = e
+	v.mu.Unlock()
+
+	completed := false
+	defer func() {
+		// Drop failed and panicking computations so the next call retries;
+		// waiters still get this call's result.
+		if !completed || e.err != nil {
+			v.mu.Lock()
+			if v.memo[key] == e {
+				delete(v.memo, key)
+			}
+			v.mu.Unlock()
+		}
+		if !completed {
+			e.err = fmt.Errorf("http: remembered value %q panicked", key)
+		}
+		close(e.done)
+	}()
+	e.val, e.err = fn()
+	completed = true
+	return e.val, e.err
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_Remember(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	defer c.release()
+
+	var calls atomic.Int32
+	load := func() (any, error) {
+		calls.Add(1)
+		return "ada", nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Remember("user", load)
+			assert.NoError(t, err)
+			assert.Equal(t, "ada", v)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, calls.Load())
+
+	c.Forget("user")
+	_, _ = c.Remember("user", load)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestContext_RememberRetriesErrors(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	defer c.release()
+
+	boom := errors.New("db down")
+	_, err := c.Remember("settings", func() (any, error) { return nil, boom })
+	assert.ErrorIs(t, err, boom)
+
+	assert.Panics(t, func() {
+		_, _ = c.Remember("settings", func() (any, error) { panic("oops") })
+	})
+
+	v, err := c.Remember("settings", func() (any, error) { return 42, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+}
+
+func TestRemember_Typed(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	defer c.release()
+
+	n, err := Remember(c, "count", func() (int, error) { return 3, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	_, err = Remember(c, "count", func() (string, error) { return "x", nil })
+	assert.ErrorContains(t, err, "is a int, not a string")
+}
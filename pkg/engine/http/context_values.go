@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"sync"
+)
+
+// requestValues is the context.Context a request runs under once NewContext
+// has bound it. It resolves astraContextKey to the request's Context and
+// string keys to the values stored with Context.Set, so a value set at any
+// point is seen through every request and context derived from it. Each
+// request gets its own, so a goroutine that outlives the request never sees
+// the values of the next request served by the pooled Context.
+type requestValues struct {
+	context.Context
+
+	mu sync.RWMutex
+	c  *Context
+	m  map[string]any
+	// memo holds the values computed by Context.Remember.
+	memo map[string]*memoEntry
+}
+
+func (v *requestValues) Value(key any) any {
+	if key == astraContextKey {
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		if v.c == nil {
+			return nil
+		}
+		return v.c
+	}
+	if k, ok := key.(string); ok {
+		v.mu.RLock()
+		val, found := v.m[k]
+		v.mu.RUnlock()
+		if found {
+			return val
+		}
+	}
+	return v.Context.Value(key)
+}
+
+func (v *requestValues) set(key string, val any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.m == nil {
+		v.m = make(map[string]any)
+	}
+	v.m[key] = val
+}
+
+// release unbinds the pooled Context, so FromRequest on a context kept past
+// the end of the request returns nil instead of another request's Context.
+func (v *requestValues) release() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.c = nil
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCookie is returned by Context.Cookie for an encrypted cookie
+// that does not decrypt, because it was tampered with, encrypted under
+// another key, or set by something other than the jar.
+var ErrInvalidCookie = errors.New("astra/http: invalid cookie")
+
+// CookieJar holds the attributes every cookie set with Context.SetCookieValue
+// or Context.SetCookie starts from, and the key cookie values are encrypted
+// with. NewRouter fills
+// it from the config; handlers pass options only where a cookie differs:
+//
+//	c.SetCookieValue("theme", "dark", http.WithCookieMaxAge(365*24*time.Hour))
+type CookieJar struct {
+	Path     string
+	Domain   string
+	Secure   bool
+	HttpOnly bool
+	SameSite nethttp.SameSite
+	// Plaintext names the cookies stored unencrypted, for cookies that
+	// JavaScript or another service must read. A name ending in "*" covers
+	// every cookie with that prefix, such as "analytics_*".
+	Plaintext []string
+	// AsIs names the cookies whose Secure and HttpOnly flags
+	// Context.SetCookie leaves as the cookie sets them, instead of turning
+	// on the jar's, such as a CSRF token that JavaScript reads. Names work
+	// as in Plaintext.
+	AsIs []string
+
+	aead cipher.AEAD
+}
+
+// NewCookieJar returns a jar with the default attributes: Path "/",
+// HttpOnly and SameSite=Lax. Values are encrypted with AES-256-GCM under a
+// key derived from key, usually APP_KEY; with an empty key, only plaintext
+// cookies can be set.
+func NewCookieJar(key string) CookieJar {
+	jar := CookieJar{Path: "/", HttpOnly: true, SameSite: nethttp.SameSiteLaxMode}
+	if key != "" {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte("astra-cookie-enc"))
+		block, err := aes.NewCipher(mac.Sum(nil))
+		if err == nil {
+			jar.aead, _ = cipher.NewGCM(block)
+		}
+	}
+	return jar
+}
+
+// CookieOption overrides a jar default for one cookie.
+type CookieOption func(*cookieSpec)
+
+type cookieSpec struct {
+	cookie    nethttp.Cookie
+	plaintext bool
+}
+
+// WithCookieMaxAge makes the cookie expire after d. Without it, the cookie
+// lasts until the browser session ends.
+func WithCookieMaxAge(d time.Duration) CookieOption {
+	return func(s *cookieSpec) {
+		s.cookie.MaxAge = int(d.Seconds())
+		s.cookie.Expires = time.Now().Add(d)
+	}
+}
+
+// WithCookiePath overrides CookieJar.Path.
+func WithCookiePath(path string) CookieOption {
+	return func(s *cookieSpec) { s.cookie.Path = path }
+}
+
+// WithCookieDomain overrides CookieJar.Domain.
+func WithCookieDomain(domain string) CookieOption {
+	return func(s *cookieSpec) { s.cookie.Domain = domain }
+}
+
+// WithCookieSecure overrides CookieJar.Secure.
+func WithCookieSecure(secure bool) CookieOption {
+	return func(s *cookieSpec) { s.cookie.Secure = secure }
+}
+
+// WithCookieHTTPOnly overrides CookieJar.HttpOnly.
+func WithCookieHTTPOnly(httpOnly bool) CookieOption {
+	return func(s *cookieSpec) { s.cookie.HttpOnly = httpOnly }
+}
+
+// WithCookieSameSite overrides CookieJar.SameSite.
+func WithCookieSameSite(mode nethttp.SameSite) CookieOption {
+	return func(s *cookieSpec) { s.cookie.SameSite = mode }
+}
+
+// WithCookiePlaintext stores the cookie unencrypted, like a name listed in
+// CookieJar.Plaintext. Pass it to Context.Cookie as well when reading the
+// cookie back.
+func WithCookiePlaintext() CookieOption {
+	return func(s *cookieSpec) { s.plaintext = true }
+}
+
+// spec applies the jar defaults and then opts for the cookie name.
+func (j *CookieJar) spec(name string, opts []CookieOption) cookieSpec {
+	s := cookieSpec{
+		cookie: nethttp.Cookie{
+			Name:     name,
+			Path:     j.Path,
+			Domain:   j.Domain,
+			Secure:   j.Secure,
+			HttpOnly: j.HttpOnly,
+			SameSite: j.SameSite,
+		},
+		plaintext: j.isPlaintext(name),
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// withDefaults returns a copy of cookie with the jar's attributes in place
+// of the ones it leaves unset. Since a false flag cannot be told from an
+// unset one, the jar's Secure and HttpOnly are turned on unless the cookie
+// is listed in AsIs.
+func (j *CookieJar) withDefaults(cookie *nethttp.Cookie) *nethttp.Cookie {
+	out := *cookie
+	if out.Path == "" {
+		out.Path = j.Path
+	}
+	if out.Domain == "" {
+		out.Domain = j.Domain
+	}
+	if out.SameSite == 0 {
+		out.SameSite = j.SameSite
+	}
+	if !matchCookieName(j.AsIs, out.Name) {
+		out.Secure = out.Secure || j.Secure
+		out.HttpOnly = out.HttpOnly || j.HttpOnly
+	}
+	return &out
+}
+
+func (j *CookieJar) isPlaintext(name string) bool {
+	return matchCookieName(j.Plaintext, name)
+}
+
+// matchCookieName reports whether name is in names, where a name ending in
+// "*" matches a prefix.
+func matchCookieName(names []string, name string) bool {
+	for _, p := range names {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasPrefix(name, prefix) || p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// seal encrypts value, binding it to the cookie name so it cannot be moved
+// into another cookie.
+func (j *CookieJar) seal(name, value string) (string, error) {
+	if j.aead == nil {
+		return "", fmt.Errorf("astra/http: cookie %q must be encrypted but the cookie jar has no key", name)
+	}
+	nonce := make([]byte, j.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("astra/http: cookie nonce: %w", err)
+	}
+	sealed := j.aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (j *CookieJar) open(name, value string) (string, error) {
+	if j.aead == nil {
+		return "", ErrInvalidCookie
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(sealed) < j.aead.NonceSize() {
+		return "", ErrInvalidCookie
+	}
+	n := j.aead.NonceSize()
+	plain, err := j.aead.Open(nil, sealed[:n], sealed[n:], []byte(name))
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(plain), nil
+}
+
+// SetCookieValue sets a cookie with the router's CookieJar defaults and any
+// per-cookie options, encrypting the value unless the cookie is plaintext.
+// Like SetHeader, it fails once the response is committed.
+func (c *Context) SetCookieValue(name, value string, opts ...CookieOption) error {
+	if c.resp.committed {
+		return ErrResponseCommitted
+	}
+	s := c.cookies.spec(name, opts)
+	s.cookie.Value = value
+	if !s.plaintext {
+		sealed, err := c.cookies.seal(name, value)
+		if err != nil {
+			return err
+		}
+		s.cookie.Value = sealed
+	}
+	nethttp.SetCookie(c.Writer, &s.cookie)
+	return nil
+}
+
+// Cookie returns the value of a cookie set with SetCookieValue, decrypted
+// unless the cookie is plaintext. It returns http.ErrNoCookie when the
+// request does not carry the cookie and ErrInvalidCookie when it does not
+// decrypt.
+func (c *Context) Cookie(name string, opts ...CookieOption) (string, error) {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	if c.cookies.spec(name, opts).plaintext {
+		return cookie.Value, nil
+	}
+	return c.cookies.open(name, cookie.Value)
+}
+
+// ClearCookie tells the browser to delete a cookie. Pass the path or domain
+// options the cookie was set with, if they differ from the jar's.
+func (c *Context) ClearCookie(name string, opts ...CookieOption) error {
+	if c.resp.committed {
+		return ErrResponseCommitted
+	}
+	s := c.cookies.spec(name, opts)
+	s.cookie.MaxAge = -1
+	s.cookie.Expires = time.Unix(0, 0)
+	nethttp.SetCookie(c.Writer, &s.cookie)
+	return nil
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieJar(t *testing.T) {
+	cfg := &config.AstraConfig{App: config.AppConfig{Environment: "production", Key: "0123456789abcdef0123456789abcdef"}}
+	router := NewRouter(cfg, slog.Default())
+	router.Cookies.Domain = "example.com"
+	router.Cookies.Plaintext = []string{"consent", "analytics_*"}
+
+	router.Get("/set", func(c *Context) error {
+		require.NoError(t, c.SetCookieValue("cart", "3 items", WithCookieMaxAge(time.Hour)))
+		require.NoError(t, c.SetCookieValue("consent", "yes"))
+		require.NoError(t, c.SetCookieValue("analytics_id", "abc", WithCookieHTTPOnly(false)))
+		require.NoError(t, c.SetCookieValue("ui", "compact", WithCookiePlaintext(), WithCookieSameSite(nethttp.SameSiteStrictMode)))
+		require.NoError(t, c.ClearCookie("legacy"))
+		return c.SendString("ok")
+	})
+	read := map[string]string{}
+	var readErr error
+	router.Get("/read", func(c *Context) error {
+		for _, name := range []string{"cart", "consent", "analytics_id"} {
+			read[name], _ = c.Cookie(name)
+		}
+		read["ui"], _ = c.Cookie("ui", WithCookiePlaintext())
+		_, readErr = c.Cookie("missing")
+		return c.SendString("ok")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/set", nil))
+	cookies := map[string]*nethttp.Cookie{}
+	for _, c := range rec.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+
+	cart := cookies["cart"]
+	require.NotNil(t, cart)
+	assert.NotContains(t, cart.Value, "items", "values are encrypted by default")
+	assert.True(t, cart.Secure)
+	assert.True(t, cart.HttpOnly)
+	assert.Equal(t, nethttp.SameSiteLaxMode, cart.SameSite)
+	assert.Equal(t, "example.com", cart.Domain)
+	assert.Equal(t, "/", cart.Path)
+	assert.Equal(t, 3600, cart.MaxAge)
+
+	assert.Equal(t, "yes", cookies["consent"].Value)
+	assert.Equal(t, "abc", cookies["analytics_id"].Value)
+	assert.False(t, cookies["analytics_id"].HttpOnly)
+	assert.Equal(t, "compact", cookies["ui"].Value)
+	assert.Equal(t, nethttp.SameSiteStrictMode, cookies["ui"].SameSite)
+	assert.Equal(t, -1, cookies["legacy"].MaxAge)
+
+	req := httptest.NewRequest("GET", "/read", nil)
+	for _, c := range rec.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, map[string]string{"cart": "3 items", "consent": "yes", "analytics_id": "abc", "ui": "compact"}, read)
+	assert.ErrorIs(t, readErr, nethttp.ErrNoCookie)
+}
+
+func TestContext_SetCookieUsesJarDefaults(t *testing.T) {
+	cfg := &config.AstraConfig{App: config.AppConfig{Environment: "production"}}
+	router := NewRouter(cfg, slog.Default())
+	router.Cookies.Domain = "example.com"
+	router.Cookies.AsIs = []string{"XSRF-*"}
+	router.Get("/", func(c *Context) error {
+		c.SetCookie(&nethttp.Cookie{Name: "plain", Value: "1"})
+		c.SetCookie(&nethttp.Cookie{Name: "XSRF-TOKEN", Value: "3"})
+		c.SetCookie(&nethttp.Cookie{Name: "custom", Value: "2", Path: "/admin", Domain: "admin.example.com", SameSite: nethttp.SameSiteStrictMode})
+		return c.SendString("ok")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	cookies := map[string]*nethttp.Cookie{}
+	for _, c := range rec.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+
+	plain := cookies["plain"]
+	require.NotNil(t, plain)
+	assert.Equal(t, "1", plain.Value, "SetCookie does not encrypt")
+	assert.True(t, plain.Secure)
+	assert.True(t, plain.HttpOnly)
+	assert.Equal(t, nethttp.SameSiteLaxMode, plain.SameSite)
+	assert.Equal(t, "example.com", plain.Domain)
+	assert.Equal(t, "/", plain.Path)
+
+	custom := cookies["custom"]
+	require.NotNil(t, custom)
+	assert.Equal(t, "/admin", custom.Path)
+	assert.Equal(t, "admin.example.com", custom.Domain)
+	assert.Equal(t, nethttp.SameSiteStrictMode, custom.SameSite)
+	assert.True(t, custom.Secure)
+
+	xsrf := cookies["XSRF-TOKEN"]
+	require.NotNil(t, xsrf)
+	assert.False(t, xsrf.HttpOnly, "cookies in AsIs keep their own flags")
+	assert.False(t, xsrf.Secure)
+	assert.Equal(t, nethttp.SameSiteLaxMode, xsrf.SameSite, "other defaults still apply")
+}
+
+func TestCookieJar_RejectsTampering(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{App: config.AppConfig{Key: "0123456789abcdef0123456789abcdef"}}, slog.Default())
+	router.Get("/set", func(c *Context) error {
+		require.NoError(t, c.SetCookieValue("role", "user"))
+		return c.SendString("ok")
+	})
+	var errs []error
+	router.Get("/read", func(c *Context) error {
+		for _, name := range []string{"role", "admin"} {
+			_, err := c.Cookie(name)
+			errs = append(errs, err)
+		}
+		return c.SendString("ok")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/set", nil))
+	sealed := rec.Result().Cookies()[0].Value
+	assert.False(t, rec.Result().Cookies()[0].Secure, "Secure is only the default in production")
+
+	req := httptest.NewRequest("GET", "/read", nil)
+	tampered := []byte(sealed)
+	if tampered[10] == 'A' {
+		tampered[10] = 'B'
+	} else {
+		tampered[10] = 'A'
+	}
+	req.AddCookie(&nethttp.Cookie{Name: "role", Value: string(tampered)})
+	// A value sealed for one cookie does not open under another name.
+	req.AddCookie(&nethttp.Cookie{Name: "admin", Value: sealed})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, ErrInvalidCookie), "got %v", err)
+	}
+
+	// Without a key only plaintext cookies can be set.
+	keyless := NewRouter(nil, slog.Default())
+	keyless.Get("/", func(c *Context) error {
+		assert.Error(t, c.SetCookieValue("cart", "x"))
+		assert.NoError(t, c.SetCookieValue("cart", "x", WithCookiePlaintext()))
+		return c.SendString("ok")
+	})
+	keyless.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
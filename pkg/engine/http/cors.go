@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/shauryagautam/Astra/pkg/engine/config"
 )
 
 // CorsConfig defines the CORS configuration.
@@ -30,6 +31,24 @@ func DefaultCors() CorsConfig {
 	}
 }
 
+// CorsFromConfig returns DefaultCors with the origins and credentials mode
+// taken from CORS_ALLOWED_ORIGINS and CORS_ALLOW_CREDENTIALS, the settings
+// the boot-time configuration audit checks.
+func CorsFromConfig(cfg config.CORSConfig) CorsConfig {
+	c := DefaultCors()
+	var origins []string
+	for _, o := range cfg.AllowedOrigins {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
+		}
+	}
+	if len(origins) > 0 {
+		c.AllowOrigins = origins
+	}
+	c.AllowCredentials = cfg.AllowCredentials
+	return c
+}
+
 // CORS returns a middleware that handles CORS requests securely.
 func CORS(config CorsConfig) MiddlewareFunc {
 	// Pre-validate config to prevent insecure defaults in production
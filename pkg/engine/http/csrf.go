@@ -1,13 +1,12 @@
 package http // Astra CSRF Protection Middleware
 
 import (
-	"crypto/rand"
-	"crypto/subtle"
-	"encoding/base64"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 )
 
 const (
@@ -239,7 +238,7 @@ func verifyCSRFToken(w http.ResponseWriter, r *http.Request, c *Context, config
 		token = formToken
 	}
 
-	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cookie.Value)) != 1 {
+	if token == "" || !secure.Equal(token, cookie.Value) {
 		if c != nil {
 			return c.Error(http.StatusForbidden, "CSRF token is invalid or missing")
 		}
@@ -251,14 +250,14 @@ func verifyCSRFToken(w http.ResponseWriter, r *http.Request, c *Context, config
 }
 
 func setCSRFCookieWithConfig(w http.ResponseWriter, _ *http.Request, c *Context, config *CSRFConfig) error {
-	token, err := generateRandomToken(32)
+	token, err := secure.Token(32)
 	if err != nil {
 		return err
 	}
 
-	secure := config.SecureCookie
-	if secure {
-		secure = config.IsProd
+	secureCookie := config.SecureCookie
+	if secureCookie {
+		secureCookie = config.IsProd
 	}
 
 	// Always store in context for template helpers even if just generated
@@ -271,7 +270,7 @@ func setCSRFCookieWithConfig(w http.ResponseWriter, _ *http.Request, c *Context,
 		Value:    token,
 		Expires:  time.Now().Add(config.TokenExpiry),
 		HttpOnly: config.CookieHTTPOnly,
-		Secure:   secure,
+		Secure:   secureCookie,
 		SameSite: config.SameSitePolicy,
 		Path:     "/",
 	})
@@ -349,11 +348,3 @@ func WithCSRFTokenExpiry(expiry time.Duration) func(*CSRFConfig) {
 		cfg.TokenExpiry = expiry
 	}
 }
-
-func generateRandomToken(n int) (string, error) {
-	b := make([]byte, n)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
-	}
-	return base64.URLEncoding.EncodeToString(b), nil
-}
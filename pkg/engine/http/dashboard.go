@@ -29,13 +29,25 @@ func DashboardLogger(dash *telemetry.Dashboard) MiddlewareFunc {
 			// Track in dashboard
 			status := rw.Status()
 			msg := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
-			dash.Track("http", msg, map[string]any{
+			data := map[string]any{
 				"method":   r.Method,
 				"path":     r.URL.Path,
 				"status":   status,
 				"duration": time.Since(start).String(),
 				"ip":       r.RemoteAddr,
-			})
+			}
+			if c := FromRequest(r); c != nil && len(c.trace) > 0 {
+				steps := make([]map[string]any, len(c.trace))
+				for i, step := range c.trace {
+					steps[i] = map[string]any{
+						"name":        step.Name,
+						"duration":    step.Duration.String(),
+						"called_next": step.CalledNext,
+					}
+				}
+				data["middleware"] = steps
+			}
+			dash.Track("http", msg, data)
 		})
 	}
 }
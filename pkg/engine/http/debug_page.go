@@ -0,0 +1,476 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
+)
+
+// The development debug page shows, for a server error or a panic, the
+// error chain, the stack with the source around each frame of application
+// code, the request including its body, and a summary of the environment.
+// Secrets are masked; even so, it is only ever rendered when APP_ENV is
+// development.
+
+const (
+	// debugSourceContext is how many lines are shown either side of a frame.
+	debugSourceContext = 5
+	// debugSourceFrames caps how many application frames get source.
+	debugSourceFrames = 10
+	// debugBodyLimit caps how much of an unread request body is shown.
+	debugBodyLimit = 16 << 10
+	masked         = "********"
+)
+
+// debugConfigPrefixes selects the configuration shown on the page, so the
+// rest of the process environment is left out.
+var debugConfigPrefixes = []string{
+	"APP_", "CORS_", "DATABASE_", "DB_", "DIAGNOSTICS_", "JWT_", "MAIL_", "MEMORY_",
+	"OTEL_", "QUEUE_", "REDIS_", "S3_", "SMTP_", "STORAGE_", "WS_",
+}
+
+// mainModule is the path of the application's module; frames in it are
+// application frames, everything else is the framework, dependencies or the
+// standard library.
+var mainModule = func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+}()
+
+// errorPageData is the template data for the development debug page.
+type errorPageData struct {
+	Error      errorDetails
+	Chain      []chainLink
+	Frames     []stackFrame
+	Request    requestInfo
+	Env        []envEntry
+	Config     []envEntry
+	Timestamp  time.Time
+	AppVersion string
+}
+
+type errorDetails struct {
+	Code    int
+	Message string
+	Type    string
+	// Stack is the plain-text trace, used when there are no Frames.
+	Stack string
+}
+
+type chainLink struct {
+	Type    string
+	Message string
+}
+
+type stackFrame struct {
+	Function string
+	File     string
+	Line     int
+	App      bool
+	Open     bool
+	Source   []sourceLine
+}
+
+type sourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+type requestInfo struct {
+	Method    string
+	URL       string
+	Headers   map[string]string
+	Query     map[string]string
+	Form      map[string]string
+	Body      string
+	IP        string
+	UserAgent string
+}
+
+type envEntry struct {
+	Key   string
+	Value string
+}
+
+// debugPageData collects everything the debug page shows for err.
+func (h *InteractiveErrorHandler) debugPageData(c *Context, err error, status int, message string, frames []runtime.Frame, stack string) errorPageData {
+	form, body := requestBody(c.Request)
+	return errorPageData{
+		Error: errorDetails{
+			Code:    status,
+			Message: message,
+			Type:    errorType(status),
+			Stack:   stack,
+		},
+		Chain:  errorChain(err),
+		Frames: stackFrames(frames),
+		Request: requestInfo{
+			Method:    c.Request.Method,
+			URL:       c.Request.URL.String(),
+			Headers:   flatHeaders(c.Request),
+			Query:     flatQuery(c.Request),
+			Form:      form,
+			Body:      body,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.Header.Get("User-Agent"),
+		},
+		Env:        h.envSummary(),
+		Config:     h.configSummary(),
+		Timestamp:  time.Now(),
+		AppVersion: h.appVer,
+	}
+}
+
+// errorStack returns the best stack there is for err: where it panicked,
+// the trace a pkg/errors error recorded, or else where it was handled. It
+// must be called from Handle.
+func errorStack(err error) ([]runtime.Frame, string) {
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		frames := pe.Frames()
+		return frames, formatFrames(frames)
+	}
+	var ae *astraerrors.Error
+	if errors.As(err, &ae) && len(ae.StackTrace) > 0 {
+		return nil, strings.Join(ae.StackTrace, "\n")
+	}
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := callerFrames(pcs[:n])
+	return frames, formatFrames(frames)
+}
+
+// errorChain lists err and every error it wraps, outermost first. Errors
+// joined with errors.Join are listed depth-first.
+func errorChain(err error) []chainLink {
+	var chain []chainLink
+	var walk func(error)
+	walk = func(err error) {
+		for err != nil && len(chain) < 32 {
+			chain = append(chain, chainLink{Type: fmt.Sprintf("%T", err), Message: err.Error()})
+			switch u := err.(type) {
+			case interface{ Unwrap() []error }:
+				for _, inner := range u.Unwrap() {
+					walk(inner)
+				}
+				return
+			case interface{ Unwrap() error }:
+				err = u.Unwrap()
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// stackFrames marks application frames and reads the source around the
+// first few of them; the first is shown expanded.
+func stackFrames(frames []runtime.Frame) []stackFrame {
+	out := make([]stackFrame, 0, len(frames))
+	withSource := 0
+	for _, f := range frames {
+		if f.Function == "" {
+			continue
+		}
+		sf := stackFrame{Function: f.Function, File: f.File, Line: f.Line, App: isAppFrame(f.Function)}
+		if sf.App && withSource < debugSourceFrames {
+			sf.Source = sourceLines(f.File, f.Line, debugSourceContext)
+			sf.Open = withSource == 0
+			withSource++
+		}
+		out = append(out, sf)
+	}
+	return out
+}
+
+// isAppFrame reports whether fn belongs to the application's main module.
+// Without build info, anything outside the standard library counts.
+func isAppFrame(fn string) bool {
+	pkg := fn
+	slash := strings.LastIndex(pkg, "/")
+	if dot := strings.Index(pkg[slash+1:], "."); dot >= 0 {
+		pkg = pkg[:slash+1+dot]
+	}
+	if pkg == "main" {
+		return true
+	}
+	if mainModule != "" {
+		return pkg == mainModule || strings.HasPrefix(pkg, mainModule+"/")
+	}
+	// Standard library import paths have no dot in their first element.
+	first, _, _ := strings.Cut(pkg, "/")
+	return strings.Contains(first, ".")
+}
+
+// sourceLines returns the lines of file within around of line, or nil when
+// the file cannot be read, as when the binary runs away from its source.
+func sourceLines(file string, line, around int) []sourceLine {
+	src, err := os.ReadFile(file) // #nosec G304 -- paths come from the binary's own stack frames
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(src), "\n")
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+	from, to := max(line-around, 1), min(line+around, len(lines))
+	out := make([]sourceLine, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		out = append(out, sourceLine{Number: n, Text: lines[n-1], Current: n == line})
+	}
+	return out
+}
+
+// requestBody returns the parsed form when the handler parsed one, else up
+// to debugBodyLimit of the unread body. Sensitive fields are masked in both,
+// including in JSON bodies.
+func requestBody(r *http.Request) (map[string]string, string) {
+	if len(r.PostForm) > 0 {
+		form := make(map[string]string, len(r.PostForm))
+		for k, v := range r.PostForm {
+			form[k] = maskSensitive(k, strings.Join(v, ", "))
+		}
+		return form, ""
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, ""
+	}
+	raw, err := io.ReadAll(io.LimitReader(r.Body, debugBodyLimit+1))
+	if err != nil || len(raw) == 0 {
+		return nil, ""
+	}
+	truncated := len(raw) > debugBodyLimit
+	if truncated {
+		raw = raw[:debugBodyLimit]
+	}
+
+	var body string
+	switch {
+	case !truncated && json.Valid(raw):
+		var v any
+		_ = json.Unmarshal(raw, &v)
+		pretty, _ := json.MarshalIndent(maskJSON(v), "", "  ")
+		body = string(pretty)
+	case utf8.Valid(raw):
+		body = string(raw)
+	default:
+		return nil, fmt.Sprintf("(%d bytes of binary data)", len(raw))
+	}
+	if truncated {
+		body += "\n… (truncated)"
+	}
+	return nil, body
+}
+
+// maskJSON masks sensitive object members anywhere in v.
+func maskJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, inner := range v {
+			if isSensitive(k) {
+				v[k] = masked
+			} else {
+				v[k] = maskJSON(inner)
+			}
+		}
+	case []any:
+		for i, inner := range v {
+			v[i] = maskJSON(inner)
+		}
+	}
+	return v
+}
+
+// isSensitive reports whether a header, field or config key names a secret.
+func isSensitive(key string) bool {
+	key = strings.ToUpper(key)
+	for _, s := range []string{"SECRET", "PASSWORD", "PASSWD", "TOKEN", "KEY", "AUTHORIZATION", "COOKIE"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSensitive masks value when key names a secret, and the password of a
+// URL such as DATABASE_URL.
+func maskSensitive(key, value string) string {
+	if isSensitive(key) && value != "" {
+		return masked
+	}
+	if strings.Contains(value, "://") {
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), masked)
+				return u.Redacted()
+			}
+		}
+	}
+	return value
+}
+
+// flatHeaders returns request headers as a flat string map, with
+// credentials masked.
+func flatHeaders(r *http.Request) map[string]string {
+	out := make(map[string]string, len(r.Header))
+	for k, v := range r.Header {
+		out[k] = maskSensitive(k, strings.Join(v, ", "))
+	}
+	return out
+}
+
+// flatQuery returns URL query parameters as a flat string map.
+func flatQuery(r *http.Request) map[string]string {
+	out := make(map[string]string)
+	for k, v := range r.URL.Query() {
+		out[k] = maskSensitive(k, strings.Join(v, ", "))
+	}
+	return out
+}
+
+// envSummary describes the process the error happened in.
+func (h *InteractiveErrorHandler) envSummary() []envEntry {
+	entries := []envEntry{
+		{"Go", runtime.Version()},
+		{"Platform", runtime.GOOS + "/" + runtime.GOARCH},
+		{"PID", strconv.Itoa(os.Getpid())},
+		{"Uptime", time.Since(processStart).Round(time.Second).String()},
+		{"Goroutines", strconv.Itoa(runtime.NumGoroutine())},
+	}
+	if h.cfg != nil {
+		entries = append(entries,
+			envEntry{"Application", h.cfg.App.Name},
+			envEntry{"Environment", h.cfg.App.Environment},
+		)
+	}
+	if mainModule != "" {
+		entries = append(entries, envEntry{"Module", mainModule})
+	}
+	return entries
+}
+
+// configSummary lists the framework's configuration keys, masked and sorted.
+func (h *InteractiveErrorHandler) configSummary() []envEntry {
+	if h.env == nil {
+		return nil
+	}
+	var entries []envEntry
+	for k, v := range h.env.MaskSecrets() {
+		if !slices.ContainsFunc(debugConfigPrefixes, func(p string) bool { return strings.HasPrefix(k, p) }) {
+			continue
+		}
+		entries = append(entries, envEntry{k, maskSensitive(k, fmt.Sprint(v))})
+	}
+	slices.SortFunc(entries, func(a, b envEntry) int { return strings.Compare(a.Key, b.Key) })
+	return entries
+}
+
+// renderDebugPage writes the development debug page, or a plain-text
+// fallback when the page itself fails to render.
+func (h *InteractiveErrorHandler) renderDebugPage(c *Context, data errorPageData) {
+	var buf bytes.Buffer
+	if err := h.tmpl.Execute(&buf, data); err != nil {
+		c.Writer.Header().Set("Content-Type", "text/plain")
+		c.Writer.WriteHeader(data.Error.Code)
+		_, _ = fmt.Fprintf(c.Writer, "Error rendering error page: %v\nOriginal error: %s", err, data.Error.Message)
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Writer.WriteHeader(data.Error.Code)
+	_, _ = c.Writer.Write(buf.Bytes())
+}
+
+// debugPage is the development page for server errors.
+const debugPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>{{.Error.Code}} · {{.Error.Message}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; background: #f8fafc; color: #1e293b; }
+header { background: #b91c1c; color: #fff; padding: 1.5rem 2rem; }
+header small { opacity: .8; text-transform: uppercase; letter-spacing: .05em; }
+header h1 { margin: .25rem 0 0; font-size: 1.4rem; font-weight: 600; word-break: break-word; }
+section { padding: 1rem 2rem; }
+h2 { font-size: .8rem; text-transform: uppercase; letter-spacing: .05em; color: #64748b; }
+pre { background: #0f172a; color: #e2e8f0; padding: 1rem; overflow-x: auto; font-size: .8rem; line-height: 1.5; border-radius: 6px; }
+table { border-collapse: collapse; width: 100%; font-size: .85rem; }
+td { border-bottom: 1px solid #e2e8f0; padding: .35rem .5rem; vertical-align: top; word-break: break-all; }
+td:first-child { width: 14rem; color: #64748b; word-break: normal; }
+ol.chain { margin: 0; padding-left: 1.25rem; font-size: .9rem; }
+ol.chain li { margin: .25rem 0; }
+ol.chain code { color: #b91c1c; }
+details { border: 1px solid #e2e8f0; border-radius: 6px; margin: .35rem 0; background: #fff; }
+details.vendor { opacity: .6; }
+summary { cursor: pointer; padding: .4rem .75rem; font-family: ui-monospace, monospace; font-size: .8rem; }
+summary span { color: #64748b; margin-left: .5rem; }
+details pre { margin: 0; border-radius: 0 0 6px 6px; white-space: pre; }
+pre .hl { background: #7f1d1d; display: inline-block; width: 100%; }
+footer { padding: 1rem 2rem; color: #94a3b8; font-size: .8rem; }
+</style>
+</head>
+<body>
+<header>
+<small>{{.Error.Code}} {{.Error.Type}}</small>
+<h1>{{.Error.Message}}</h1>
+</header>
+{{if gt (len .Chain) 1}}<section>
+<h2>Error chain</h2>
+<ol class="chain">{{range .Chain}}<li><code>{{.Type}}</code> {{.Message}}</li>{{end}}</ol>
+</section>{{end}}
+{{if .Frames}}<section>
+<h2>Stack</h2>
+{{range .Frames}}<details class="{{if .App}}app{{else}}vendor{{end}}"{{if .Open}} open{{end}}>
+<summary>{{.Function}}<span>{{.File}}:{{.Line}}</span></summary>
+{{with .Source}}<pre>{{range .}}<span{{if .Current}} class="hl"{{end}}>{{printf "%5d" .Number}}  {{.Text}}</span>
+{{end}}</pre>{{end}}
+</details>
+{{end}}</section>{{else}}{{with .Error.Stack}}<section>
+<h2>Stack</h2>
+<pre>{{.}}</pre>
+</section>{{end}}{{end}}
+<section>
+<h2>Request</h2>
+<table>
+<tr><td>Method</td><td>{{.Request.Method}}</td></tr>
+<tr><td>URL</td><td>{{.Request.URL}}</td></tr>
+<tr><td>Client IP</td><td>{{.Request.IP}}</td></tr>
+<tr><td>User agent</td><td>{{.Request.UserAgent}}</td></tr>
+</table>
+{{with .Request.Query}}<h2>Query</h2>
+<table>{{range $k, $v := .}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>{{end}}</table>{{end}}
+{{with .Request.Form}}<h2>Form</h2>
+<table>{{range $k, $v := .}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>{{end}}</table>{{end}}
+{{with .Request.Body}}<h2>Body</h2>
+<pre>{{.}}</pre>{{end}}
+{{with .Request.Headers}}<h2>Headers</h2>
+<table>{{range $k, $v := .}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>{{end}}</table>{{end}}
+</section>
+<section>
+<h2>Environment</h2>
+<table>{{range .Env}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+{{with .Config}}<h2>Configuration</h2>
+<table>{{range .}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>{{end}}</table>{{end}}
+</section>
+<footer>{{.Timestamp.Format "2006-01-02 15:04:05 MST"}}{{with .AppVersion}} · version {{.}}{{end}} · shown because APP_ENV is development</footer>
+</body>
+</html>`
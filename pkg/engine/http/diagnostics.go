@@ -0,0 +1,158 @@
+package http
+
+import (
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// DiagnosticsPath is where RegisterDiagnosticsRoutes mounts pprof, expvar
+// and the runtime stats.
+const DiagnosticsPath = "/_debug"
+
+// DiagnosticsOptions guards the diagnostics routes. Profiles reveal memory
+// contents and cost CPU to produce, so the routes are never open:
+// Middleware that authenticates the caller is required.
+//
+// An address check alone is not enough. Behind a reverse proxy or sidecar on
+// the same host, every request arrives from 127.0.0.1, so a loopback
+// allow-list would let the whole internet in unless TrustedProxies is set.
+type DiagnosticsOptions struct {
+	// AllowIPs, when set, lists the client addresses and CIDR ranges let in,
+	// as accepted by ParsePrefixes. Other clients get a 404.
+	AllowIPs []string
+	// TrustedProxies may supply the client address in forwarded headers
+	// when AllowIPs is matched (see GetClientIP).
+	TrustedProxies []string
+	// Middleware runs after the allow-list, e.g. Auth followed by a role
+	// check. It is required.
+	Middleware []MiddlewareFunc
+}
+
+// ErrDiagnosticsUnguarded is returned by RegisterDiagnosticsRoutes when no
+// middleware guards the routes.
+var ErrDiagnosticsUnguarded = errors.New("diagnostics: refusing to mount pprof and expvar without auth middleware")
+
+var processStart = time.Now()
+
+// RegisterDiagnosticsRoutes serves Go's runtime diagnostics under
+// DiagnosticsPath, so a production process can be profiled in place:
+//
+//	GET /_debug/pprof/            index of the available profiles
+//	GET /_debug/pprof/{profile}   heap, goroutine, allocs, block, mutex, threadcreate,
+//	                              profile (CPU, ?seconds=30), trace, cmdline, symbol
+//	GET /_debug/vars              expvar variables as JSON
+//	GET /_debug/runtime           goroutines, GOMAXPROCS, memory and GC stats
+//
+// Use it with go tool pprof:
+//
+//	go tool pprof https://app.example.com/_debug/pprof/heap
+//
+// It returns ErrDiagnosticsUnguarded, and mounts nothing, when
+// opts.Middleware is empty.
+func RegisterDiagnosticsRoutes(r *Router, opts DiagnosticsOptions) error {
+	if len(opts.Middleware) == 0 {
+		return ErrDiagnosticsUnguarded
+	}
+	allow := ParsePrefixes(opts.AllowIPs...)
+
+	r.Group(DiagnosticsPath, func(r *Router) {
+		if len(allow) > 0 {
+			r.Use(IPAllowList(allow, ParsePrefixes(opts.TrustedProxies...)))
+		}
+		for _, mw := range opts.Middleware {
+			r.Use(mw)
+		}
+
+		// Router.Handle skips the group's middleware, so every route goes
+		// through HandleContext.
+		r.Get("/pprof/{$}", serveStd(http.HandlerFunc(pprof.Index)))
+		r.Get("/pprof/{profile}", serveStd(http.HandlerFunc(servePprof)))
+		r.Post("/pprof/symbol", serveStd(http.HandlerFunc(pprof.Symbol)))
+		r.Get("/vars", serveStd(expvar.Handler()))
+		r.Get("/runtime", func(c *Context) error { return c.JSON(ReadRuntimeStats()) })
+	})
+	return nil
+}
+
+func serveStd(h http.Handler) HandlerFunc {
+	return func(c *Context) error {
+		h.ServeHTTP(c.Writer, c.Request)
+		return nil
+	}
+}
+
+// servePprof dispatches by name itself, because pprof.Index only finds
+// named profiles under the fixed /debug/pprof/ prefix.
+func servePprof(w http.ResponseWriter, r *http.Request) {
+	switch name := r.PathValue("profile"); name {
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		pprof.Handler(name).ServeHTTP(w, r)
+	}
+}
+
+// IPAllowList answers 404 to clients whose address, resolved with
+// GetClientIP, is outside allowed. The 404 keeps the route's existence
+// private.
+func IPAllowList(allowed, trustedProxies []netip.Prefix) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr, err := netip.ParseAddr(GetClientIP(r, trustedProxies))
+			if err != nil || !IsTrustedProxy(addr.Unmap(), allowed) {
+				http.NotFound(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RuntimeStats is the snapshot served at /_debug/runtime.
+type RuntimeStats struct {
+	GoVersion   string        `json:"go_version"`
+	Uptime      time.Duration `json:"uptime_ns"`
+	Goroutines  int           `json:"goroutines"`
+	GOMAXPROCS  int           `json:"gomaxprocs"`
+	NumCPU      int           `json:"num_cpu"`
+	HeapAlloc   uint64        `json:"heap_alloc_bytes"`
+	HeapInuse   uint64        `json:"heap_inuse_bytes"`
+	Sys         uint64        `json:"sys_bytes"`
+	NumGC       uint32        `json:"num_gc"`
+	LastGC      time.Time     `json:"last_gc"`
+	PauseTotal  time.Duration `json:"gc_pause_total_ns"`
+	MemoryLimit int64         `json:"memory_limit_bytes"`
+}
+
+// ReadRuntimeStats collects a RuntimeStats. It briefly stops the world to
+// read the memory statistics, so do not call it on a hot path.
+func ReadRuntimeStats() RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return RuntimeStats{
+		GoVersion:   runtime.Version(),
+		Uptime:      time.Since(processStart),
+		Goroutines:  runtime.NumGoroutine(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		NumCPU:      runtime.NumCPU(),
+		HeapAlloc:   m.HeapAlloc,
+		HeapInuse:   m.HeapInuse,
+		Sys:         m.Sys,
+		NumGC:       m.NumGC,
+		LastGC:      time.Unix(0, int64(m.LastGC)), // #nosec G115 -- nanoseconds since 1970 fit in int64
+		PauseTotal:  time.Duration(m.PauseTotalNs), // #nosec G115 -- total GC pause fits in int64
+		MemoryLimit: debug.SetMemoryLimit(-1),
+	}
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireAuthHeader stands in for an auth guard.
+func requireAuthHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRegisterDiagnosticsRoutes(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, slog.Default())
+	require.NoError(t, RegisterDiagnosticsRoutes(router, DiagnosticsOptions{Middleware: []MiddlewareFunc{requireAuthHeader}}))
+
+	get := func(path, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remote
+		req.Header.Set("Authorization", "Bearer ok")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/_debug/pprof/", "203.0.113.9:4000")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine")
+
+	rec = get("/_debug/pprof/goroutine?debug=1", "[::1]:4000")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine profile")
+
+	rec = get("/_debug/runtime", "127.0.0.1:4000")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stats RuntimeStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Positive(t, stats.Goroutines)
+
+	assert.Equal(t, http.StatusOK, get("/_debug/vars", "127.0.0.1:4000").Code)
+}
+
+func TestRegisterDiagnosticsRoutes_RequiresMiddleware(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, slog.Default())
+	err := RegisterDiagnosticsRoutes(router, DiagnosticsOptions{AllowIPs: []string{"127.0.0.1/32"}})
+	require.ErrorIs(t, err, ErrDiagnosticsUnguarded)
+
+	// Nothing is mounted, even for loopback clients such as a local proxy.
+	req := httptest.NewRequest(http.MethodGet, "/_debug/pprof/heap", nil)
+	req.RemoteAddr = "127.0.0.1:4000"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRegisterDiagnosticsRoutes_Middleware(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, slog.Default())
+	require.NoError(t, RegisterDiagnosticsRoutes(router, DiagnosticsOptions{
+		AllowIPs:   []string{"10.0.0.0/8"},
+		Middleware: []MiddlewareFunc{requireAuthHeader},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/_debug/pprof/heap", nil)
+	req.RemoteAddr = "10.1.2.3:4000"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req.Header.Set("Authorization", "Bearer ok")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req.RemoteAddr = "127.0.0.1:4000"
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
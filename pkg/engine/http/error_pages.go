@@ -0,0 +1,77 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// ErrorPageData is passed to the errors/* views rendered by
+// InteractiveErrorHandler.
+type ErrorPageData struct {
+	Status     int
+	StatusText string
+	// Message is the error's message for client errors. For server errors
+	// it is StatusText, so internal details never reach the page.
+	Message   string
+	Path      string
+	RequestID string
+}
+
+// errorViewNames lists the views tried, in order, for an HTML error page.
+func errorViewNames(status int) []string {
+	return []string{
+		fmt.Sprintf("errors/%d", status),
+		fmt.Sprintf("errors/%dxx", status/100),
+		"errors/error",
+	}
+}
+
+// renderErrorView writes the first errors/* view that exists and reports
+// whether it did.
+func (h *InteractiveErrorHandler) renderErrorView(c *Context, status int, message string) bool {
+	views := h.views
+	if views == nil {
+		views = c.ViewEngine
+	}
+	if views == nil {
+		return false
+	}
+
+	data := ErrorPageData{
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Message:    message,
+		Path:       c.Request.URL.Path,
+	}
+	if status >= 500 {
+		data.Message = data.StatusText
+	}
+	if id, ok := c.Get("request_id").(string); ok {
+		data.RequestID = id
+	}
+
+	for _, name := range errorViewNames(status) {
+		var buf bytes.Buffer
+		err := views.Render(&buf, name, data)
+		if err == nil {
+			c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+			c.Writer.WriteHeader(status)
+			_, _ = c.Writer.Write(buf.Bytes())
+			return true
+		}
+		if !isMissingView(err) && h.logger != nil {
+			h.logger.Warn("astra: error page failed to render", "view", name, "error", err)
+		}
+	}
+	return false
+}
+
+// isMissingView reports whether err means the view does not exist, as
+// opposed to a view that failed to parse or execute.
+func isMissingView(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || strings.Contains(err.Error(), "pattern matches no files")
+}
@@ -1,46 +1,73 @@
 package http
 
 import (
-	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
+	"maps"
 	"net/http"
-	"runtime/debug"
+	"runtime"
 	"strings"
-	"time"
 
+	"github.com/shauryagautam/Astra/pkg/engine"
 	"github.com/shauryagautam/Astra/pkg/engine/config"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
+	"github.com/shauryagautam/Astra/pkg/validate"
 )
 
-// InteractiveErrorHandler renders rich debug error pages in development and
-// structured JSON / minimal HTML in production.
+// InteractiveErrorHandler answers errors with structured JSON for API
+// clients and an HTML page for browsers: a debug page for server errors in
+// development, otherwise the application's errors/* views (see WithViews)
+// or a minimal built-in page.
 type InteractiveErrorHandler struct {
-	cfg     *config.AstraConfig
-	env     *config.Config
-	logger  *slog.Logger
-	tmpl    *template.Template
-	appVer  string
+	cfg    *config.AstraConfig
+	env    *config.Config
+	logger *slog.Logger
+	tmpl   *template.Template
+	views  engine.ViewEngine
+	appVer string
+
+	mapNotFound bool
 }
 
 // NewInteractiveErrorHandler creates an InteractiveErrorHandler with explicit dependencies.
 func NewInteractiveErrorHandler(cfg *config.AstraConfig, env *config.Config, logger *slog.Logger) *InteractiveErrorHandler {
 	h := &InteractiveErrorHandler{
-		cfg:    cfg,
-		env:    env,
-		logger: logger,
+		cfg:         cfg,
+		env:         env,
+		logger:      logger,
+		mapNotFound: true,
 	}
 	if cfg != nil {
 		h.appVer = cfg.App.Version
 	}
-	tmpl, err := template.New("error").Parse("")
-	if err == nil {
-		h.tmpl = tmpl
-	}
+	h.tmpl = template.Must(template.New("error").Parse(debugPage))
+	return h
+}
+
+// WithViews renders HTML error pages with views, trying errors/<status>
+// (errors/404), then errors/<class>xx (errors/5xx), then errors/error. The
+// view receives an ErrorPageData. Without a matching view, the built-in page
+// is used. When views is nil, the Context's ViewEngine is tried.
+func (h *InteractiveErrorHandler) WithViews(views engine.ViewEngine) *InteractiveErrorHandler {
+	h.views = views
+	return h
+}
+
+// MapNotFound controls whether ORM lookups that match no row
+// (*database.NotFoundError) are answered with 404 and a model-aware message
+// such as "User not found" instead of 500. It is on by default, so handlers
+// can simply return the error from FindByID. A bare sql.ErrNoRows from a
+// hand-written query is not mapped: it may just as well be a missing row
+// the handler relied on.
+func (h *InteractiveErrorHandler) MapNotFound(enabled bool) *InteractiveErrorHandler {
+	h.mapNotFound = enabled
 	return h
 }
 
-// Handle is the error handler function compatible with Router.errorHandler.
+// Handle is the error handler function compatible with Router.ErrorHandler.
 func (h *InteractiveErrorHandler) Handle(c *Context, err error) {
 	if err == nil {
 		return
@@ -49,97 +76,129 @@ func (h *InteractiveErrorHandler) Handle(c *Context, err error) {
 	isDev := h.env != nil && h.env.IsDev()
 	isAPI := isAPIRequest(c.Request)
 
-	var statusCode int
-	var message string
-
-	if httpErr, ok := err.(*HTTPError); ok {
-		statusCode = httpErr.Status
-		message = httpErr.Message
-	} else {
-		statusCode = http.StatusInternalServerError
-		message = err.Error()
-	}
+	statusCode, message := h.resolve(err)
+	recordSpanError(c, err, statusCode)
 
-	// Use debug.Stack() correctly
+	var frames []runtime.Frame
 	var stackStr string
-	if statusCode >= 500 {
-		stackStr = string(debug.Stack())
+	if isDev && statusCode >= 500 {
+		frames, stackStr = errorStack(err)
 	}
 
 	if isAPI {
-		// Structured JSON error for API routes.
-		c.Writer.Header().Set("Content-Type", "application/json")
-		c.Writer.WriteHeader(statusCode)
-		errCode := http.StatusText(statusCode)
-		if errCode == "" {
-			errCode = "INTERNAL_SERVER_ERROR"
-		}
-		
-		resp := map[string]any{
-			"error": map[string]any{
-				"code":    strings.ToUpper(strings.ReplaceAll(errCode, " ", "_")),
-				"message": message,
-			},
-		}
-		
+		// Structured JSON error for API routes, in the router's envelope.
+		code, details := errorCode(err, statusCode)
+		body := c.envelope().Error(statusCode, code, message, details)
 		if isDev && stackStr != "" {
-			resp["debug"] = map[string]any{
-				"stack": stackStr,
-			}
+			body = withDebugStack(body, stackStr)
 		}
-
-		_ = c.JSON(resp, statusCode)
+		_ = c.JSON(body, statusCode)
 		return
 	}
 
-	// SSR / browser route.
-	if !isDev || h.tmpl == nil {
-		// Minimal static 500 page for production.
+	// SSR / browser route: the application's error views, else a minimal
+	// built-in page. Server errors get the debug page in development.
+	if !isDev || statusCode < 500 {
+		if h.renderErrorView(c, statusCode, message) {
+			return
+		}
 		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
 		c.Writer.WriteHeader(statusCode)
 		_, _ = c.Writer.Write([]byte(minimalErrorPage(statusCode)))
 		return
 	}
 
-	// Development: rich interactive error page.
-	data := errorPageData{
-		Error: errorDetails{
-			Code:    statusCode,
-			Message: message,
-			Type:    errorType(statusCode),
-			Stack:   stackStr,
-		},
-		Request: requestInfo{
-			Method:    c.Request.Method,
-			URL:       c.Request.URL.String(),
-			Headers:   flatHeaders(c.Request),
-			Query:     flatQuery(c.Request),
-			IP:        c.ClientIP(),
-			UserAgent: c.Request.Header.Get("User-Agent"),
-		},
-		Timestamp:  time.Now(),
-		AppVersion: h.appVer,
-	}
-
-	var buf bytes.Buffer
-	if err2 := h.tmpl.Execute(&buf, data); err2 != nil {
-		c.Writer.Header().Set("Content-Type", "text/plain")
-		c.Writer.WriteHeader(statusCode)
-		_, _ = c.Writer.Write([]byte(fmt.Sprintf("Error rendering error page: %v\nOriginal error: %s", err2, message)))
-		return
+	// Development: the debug page.
+	h.renderDebugPage(c, h.debugPageData(c, err, statusCode, message, frames, stackStr))
+}
+
+// withDebugStack adds the development stack to an error body as a top-level
+// "debug": {"stack": ...} key, next to whatever the envelope wrote. Bodies
+// that do not encode to a JSON object are returned unchanged.
+func withDebugStack(body any, stack string) any {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return body
+	}
+	var obj map[string]any
+	if json.Unmarshal(raw, &obj) != nil || obj == nil {
+		return body
+	}
+	obj["debug"] = map[string]any{"stack": stack}
+	return obj
+}
+
+// statusCoder is implemented by errors that carry their own HTTP status,
+// such as *errors.Error from pkg/errors.
+type statusCoder interface {
+	HTTPStatus() int
+}
+
+// notFoundError is implemented by errors for a lookup that matched no
+// record, such as *database.NotFoundError. NotFoundModel returns the name of
+// the model that was looked up.
+type notFoundError interface {
+	NotFoundModel() string
+}
+
+// resolve maps err to a response status and message.
+func (h *InteractiveErrorHandler) resolve(err error) (int, string) {
+	var ve *validate.ValidationErrors
+	if errors.As(err, &ve) {
+		return http.StatusUnprocessableEntity, "the given data was invalid"
 	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status, httpErr.Message
+	}
+	var coded statusCoder
+	if errors.As(err, &coded) {
+		return coded.HTTPStatus(), err.Error()
+	}
+	if h.mapNotFound {
+		var nf notFoundError
+		if errors.As(err, &nf) {
+			return http.StatusNotFound, nf.NotFoundModel() + " not found"
+		}
+	}
+	return http.StatusInternalServerError, err.Error()
+}
 
-	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
-	c.Writer.WriteHeader(statusCode)
-	_, _ = c.Writer.Write(buf.Bytes())
+// errorCode returns the envelope code and details for err. Framework errors
+// keep their own code; anything else is named after its status.
+func errorCode(err error, status int) (string, map[string]any) {
+	var ve *validate.ValidationErrors
+	if errors.As(err, &ve) {
+		return ErrCodeValidation, map[string]any{"fields": ve.Fields}
+	}
+	var astraErr *astraerrors.Error
+	if errors.As(err, &astraErr) {
+		return string(astraErr.Code), maps.Clone(astraErr.Details)
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code != "" {
+		return httpErr.Code, nil
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return "INTERNAL_SERVER_ERROR", nil
+	}
+	return strings.ToUpper(strings.ReplaceAll(text, " ", "_")), nil
 }
 
-// isAPIRequest returns true when the request looks like an API call.
+// isAPIRequest returns true when the error should be answered with JSON:
+// for XHR, and when the Accept header ranks JSON above HTML. When it ranks
+// them equally (no header, or */*), paths under /api/ get JSON.
 func isAPIRequest(r *http.Request) bool {
+	if r.Header.Get("X-Requested-With") == "XMLHttpRequest" {
+		return true
+	}
 	accept := r.Header.Get("Accept")
-	return strings.HasPrefix(accept, "application/json") ||
-		strings.HasPrefix(r.URL.Path, "/api/") ||
-		r.Header.Get("X-Requested-With") == "XMLHttpRequest"
+	html, json := acceptQuality(accept, "text/html"), acceptQuality(accept, "application/json")
+	if html != json {
+		return json > html
+	}
+	return strings.HasPrefix(r.URL.Path, "/api/")
 }
 
 // minimalErrorPage returns a minimal static HTML error page for production.
@@ -152,7 +211,17 @@ func minimalErrorPage(code int) string {
 		statusText +
 		`</title></head><body style="font-family:sans-serif;text-align:center;padding:60px;background:#f8fafc;color:#1e293b"><h1>` +
 		fmt.Sprintf("%d %s", code, statusText) +
-		`</h1><p>Something went wrong on our end. Please try again later.</p></body></html>`
+		`</h1><p>` + minimalErrorHint(code) + `</p></body></html>`
+}
+
+func minimalErrorHint(code int) string {
+	if code == http.StatusNotFound {
+		return "The page you were looking for could not be found."
+	}
+	if code < 500 {
+		return "The request could not be completed."
+	}
+	return "Something went wrong on our end. Please try again later."
 }
 
 // errorType returns a string category for an HTTP status code.
@@ -166,45 +235,3 @@ func errorType(code int) string {
 		return "unknown"
 	}
 }
-
-// flatHeaders returns request headers as a flat string map.
-func flatHeaders(r *http.Request) map[string]string {
-	out := make(map[string]string, len(r.Header))
-	for k, v := range r.Header {
-		out[k] = strings.Join(v, ", ")
-	}
-	return out
-}
-
-// flatQuery returns URL query parameters as a flat string map.
-func flatQuery(r *http.Request) map[string]string {
-	out := make(map[string]string)
-	for k, v := range r.URL.Query() {
-		out[k] = strings.Join(v, ", ")
-	}
-	return out
-}
-
-// errorPageData is the template data for the interactive error page.
-type errorPageData struct {
-	Error      errorDetails
-	Request    requestInfo
-	Timestamp  time.Time
-	AppVersion string
-}
-
-type errorDetails struct {
-	Code    int
-	Message string
-	Type    string
-	Stack   string
-}
-
-type requestInfo struct {
-	Method    string
-	URL       string
-	Headers   map[string]string
-	Query     map[string]string
-	IP        string
-	UserAgent string
-}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInteractiveErrorHandler_NotFoundMapping(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"orm model", fmt.Errorf("load: %w", &database.NotFoundError{Model: "User", Table: "users", ID: 7}), http.StatusNotFound, "User not found"},
+		{"sql no rows", fmt.Errorf("load settings: %w", sql.ErrNoRows), http.StatusInternalServerError, "no rows"},
+		{"framework error", astraerrors.NotFound("team missing"), http.StatusNotFound, "team missing"},
+		{"http error", &HTTPError{Status: http.StatusConflict, Message: "taken"}, http.StatusConflict, "taken"},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, "boom"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := NewRouter(&config.AstraConfig{}, nil)
+			router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).Handle
+			router.Get("/api/thing", func(c *Context) error { return tc.err })
+
+			req := httptest.NewRequest("GET", "/api/thing", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.message)
+		})
+	}
+}
+
+func TestInteractiveErrorHandler_NotFoundMappingDisabled(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).MapNotFound(false).Handle
+	router.Get("/api/thing", func(c *Context) error { return &database.NotFoundError{Model: "User"} })
+
+	req := httptest.NewRequest("GET", "/api/thing", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestInteractiveErrorHandler_HTMLPages(t *testing.T) {
+	views := NewTemplateEngine("", WithFS(fstest.MapFS{
+		"errors/404.html": {Data: []byte(`<h1>{{.Status}} {{.Message}}</h1>`)},
+		"errors/5xx.html": {Data: []byte(`<h1>Oops: {{.Message}}</h1>`)},
+	}))
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).WithViews(views).Handle
+	router.Get("/api/missing", func(c *Context) error { return astraerrors.NotFound("no such team") })
+	router.Get("/boom", func(c *Context) error { return errors.New("db password leaked") })
+	router.Get("/conflict", func(c *Context) error { return &HTTPError{Status: http.StatusConflict, Message: "taken"} })
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	const browser = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+	rec := get("/api/missing", browser)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "<h1>404 no such team</h1>", rec.Body.String())
+
+	// Server errors never show the underlying message.
+	rec = get("/boom", browser)
+	assert.Equal(t, "<h1>Oops: Internal Server Error</h1>", rec.Body.String())
+
+	// No matching view: the built-in page.
+	rec = get("/conflict", browser)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "409 Conflict")
+
+	// JSON clients, and /api/ paths without a preference, get JSON.
+	assert.Contains(t, get("/boom", "application/json").Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, get("/api/missing", "*/*").Header().Get("Content-Type"), "application/json")
+}
+
+func TestInteractiveErrorHandler_DebugPage(t *testing.T) {
+	t.Setenv("APP_ENV", "development")
+	env, err := config.Load("testdata/none.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, env, nil).Handle
+	router.Get("/boom", func(c *Context) error { return errors.New("db unreachable") })
+
+	req := httptest.NewRequest("GET", "/boom?page=2", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "db unreachable")
+	assert.Contains(t, body, "HandleContext")
+	assert.Contains(t, body, "page")
+}
+
+func TestInteractiveErrorHandler_DebugStackInJSON(t *testing.T) {
+	t.Setenv("APP_ENV", "development")
+	env, err := config.Load("testdata/none.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, env, nil).Handle
+	router.Get("/api/boom", func(c *Context) error { return errors.New("db unreachable") })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/boom", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var body struct {
+		Error APIErrorBody `json:"error"`
+		Debug struct {
+			Stack string `json:"stack"`
+		} `json:"debug"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "db unreachable", body.Error.Message)
+	assert.Nil(t, body.Error.Details, "the stack stays out of the envelope's details")
+	assert.Contains(t, body.Debug.Stack, "HandleContext")
+}
+
+// Secrets for the debug page test live here, away from the source the page
+// shows around its frames.
+const (
+	debugTestPassword = "s3cret"
+	debugTestToken    = "abc.def"
+	debugTestDBPass   = "hunter2"
+)
+
+func TestInteractiveErrorHandler_DebugPagePanic(t *testing.T) {
+	t.Setenv("APP_ENV", "development")
+	t.Setenv("DB_PASSWORD", debugTestDBPass)
+	env, err := config.Load("testdata/none.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, env, nil).Handle
+	router.Post("/login", func(c *Context) error {
+		cause := fmt.Errorf("loading session: %w", sql.ErrConnDone)
+		panic(cause) // the line the page should point at
+	})
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email":"a@b.c","password":"`+debugTestPassword+`"}`))
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Authorization", "Bearer "+debugTestToken)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	body := rec.Body.String()
+	// The chain, from the panic down to the root cause.
+	assert.Contains(t, body, "*http.PanicError")
+	assert.Contains(t, body, "*fmt.wrapError")
+	assert.Contains(t, body, "sql: connection is already closed")
+	// The source around the panic, with the line highlighted.
+	assert.Contains(t, body, "TestInteractiveErrorHandler_DebugPagePanic")
+	assert.Regexp(t, `class="hl">\s*\d+\s+panic\(cause\) // the line the page should point at`, body)
+	// The request body and headers, with secrets masked.
+	assert.Contains(t, body, "a@b.c")
+	assert.NotContains(t, body, debugTestPassword)
+	assert.NotContains(t, body, debugTestToken)
+	// The environment, with secrets masked.
+	assert.Contains(t, body, runtime.Version())
+	assert.Contains(t, body, "DB_PASSWORD")
+	assert.NotContains(t, body, debugTestDBPass)
+}
+
+func TestRouter_PanicWithoutErrorHandler(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.Get("/boom", func(c *Context) error { panic("boom") })
+
+	assert.Panics(t, func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/boom", nil))
+	})
+}
+
+func TestRouter_UnmatchedRouteCodes(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.Get("/api/users", func(c *Context) error { return c.SendString("users") })
+	router.Get("/api/files/missing", func(c *Context) error { return c.Error(http.StatusNotFound, "gone") })
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := serve("GET", "/api/nothing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"E_ROUTE_NOT_FOUND"`)
+	assert.Contains(t, rec.Body.String(), "Cannot GET /api/nothing")
+
+	rec = serve("DELETE", "/api/users")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"E_METHOD_NOT_ALLOWED"`)
+	assert.Contains(t, rec.Header().Get("Allow"), "GET")
+
+	// A 404 answered by a route is left alone.
+	rec = serve("GET", "/api/files/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "gone\n", rec.Body.String())
+
+	// Through the ErrorHandler, the HTTPError keeps its code.
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).Handle
+	rec = serve("GET", "/api/nothing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"E_ROUTE_NOT_FOUND"`)
+}
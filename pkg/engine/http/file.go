@@ -0,0 +1,125 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	nethttp "net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MultiRangePolicy decides how file responses answer a Range header asking
+// for several byte ranges at once.
+type MultiRangePolicy int
+
+const (
+	// MultiRangeIgnore serves the whole file with 200, as if no Range header
+	// had been sent. Players and download managers only ask for one range,
+	// so this is the default.
+	MultiRangeIgnore MultiRangePolicy = iota
+	// MultiRangeAllow answers with a multipart/byteranges 206 response.
+	MultiRangeAllow
+	// MultiRangeReject answers with 416 Range Not Satisfiable.
+	MultiRangeReject
+)
+
+// FileConfig controls how SendFile, Download and SendContent serve files.
+type FileConfig struct {
+	MultiRange MultiRangePolicy
+}
+
+// SendFile serves the file at path inline. It honours Range and
+// conditional requests: a single range gets 206 Partial Content with
+// Content-Range, an unsatisfiable one 416, and If-None-Match/If-Modified-Since
+// 304. A missing file or a directory is reported as a 404 HTTPError.
+func (c *Context) SendFile(path string) error {
+	f, err := os.Open(path) // #nosec G304 -- the handler chooses the path
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	return c.sendFile(f, f.Name(), "")
+}
+
+// SendFileFS is SendFile for a file in fsys, such as an embedded bundle.
+func (c *Context) SendFileFS(fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	return c.sendFile(f, name, "")
+}
+
+// Download serves the file at path as an attachment the browser saves as
+// filename, with the same Range support as SendFile so downloads can resume.
+// Content-Disposition is only set once the file is open, so a missing file
+// reaches the error handler without it.
+func (c *Context) Download(path, filename string) error {
+	f, err := os.Open(path) // #nosec G304 -- the handler chooses the path
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	return c.sendFile(f, f.Name(), mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
+
+// SendContent serves content with Range support. name is only used to
+// guess the Content-Type when none is set, and a zero modtime disables
+// Last-Modified handling.
+func (c *Context) SendContent(name string, modtime time.Time, content io.ReadSeeker) error {
+	if c.written {
+		return nil
+	}
+	req := c.Request
+	if ranges := req.Header.Get("Range"); strings.Count(ranges, ",") > 0 {
+		switch c.files.MultiRange {
+		case MultiRangeIgnore:
+			req = req.Clone(req.Context())
+			req.Header.Del("Range")
+		case MultiRangeReject:
+			size, err := content.Seek(0, io.SeekEnd)
+			if err != nil {
+				return err
+			}
+			c.Writer.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+			c.written = true
+			nethttp.Error(c.Writer, "multiple ranges are not supported", nethttp.StatusRequestedRangeNotSatisfiable)
+			return nil
+		}
+	}
+	c.written = true
+	nethttp.ServeContent(c.Writer, req, path.Base(name), modtime, content)
+	return nil
+}
+
+// sendFile serves f, as an attachment when disposition is set.
+func (c *Context) sendFile(f fs.File, name, disposition string) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fileError(fs.ErrNotExist)
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		return errors.New("astra/http: file " + name + " does not support seeking")
+	}
+	if disposition != "" {
+		c.Writer.Header().Set("Content-Disposition", disposition)
+	}
+	return c.SendContent(name, info.ModTime(), content)
+}
+
+func fileError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return &HTTPError{Status: nethttp.StatusNotFound, Message: "file not found"}
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextSendFile_Ranges(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "clip.txt")
+	require.NoError(t, os.WriteFile(file, []byte("0123456789"), 0o600))
+
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).Handle
+	router.Get("/clip", func(c *Context) error { return c.SendFile(file) })
+	router.Get("/download", func(c *Context) error { return c.Download(file, "my clip.txt") })
+	router.Get("/embedded", func(c *Context) error {
+		return c.SendFileFS(fstest.MapFS{"a.txt": {Data: []byte("abcdef")}}, "a.txt")
+	})
+	router.Get("/missing", func(c *Context) error { return c.SendFile(filepath.Join(t.TempDir(), "nope")) })
+	router.Get("/missing-download", func(c *Context) error {
+		return c.Download(filepath.Join(t.TempDir(), "nope"), "nope.txt")
+	})
+
+	get := func(path, ranges string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "application/json")
+		if ranges != "" {
+			req.Header.Set("Range", ranges)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/clip", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+	assert.Equal(t, "0123456789", rec.Body.String())
+
+	rec = get("/clip", "bytes=2-5")
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "bytes 2-5/10", rec.Header().Get("Content-Range"))
+	assert.Equal(t, "2345", rec.Body.String())
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, get("/clip", "bytes=20-").Code)
+
+	// Multiple ranges are ignored by default and the whole file is sent.
+	rec = get("/clip", "bytes=0-1,4-5")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0123456789", rec.Body.String())
+
+	router.Files.MultiRange = MultiRangeReject
+	rec = get("/clip", "bytes=0-1,4-5")
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+	assert.Equal(t, "bytes */10", rec.Header().Get("Content-Range"))
+
+	router.Files.MultiRange = MultiRangeAllow
+	rec = get("/clip", "bytes=0-1,4-5")
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "multipart/byteranges")
+
+	rec = get("/download", "bytes=5-")
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, `attachment; filename="my clip.txt"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "56789", rec.Body.String())
+
+	rec = get("/embedded", "bytes=-2")
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "ef", rec.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, get("/missing", "").Code)
+
+	rec = get("/missing-download", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Disposition"), "the error response is not sent as an attachment")
+}
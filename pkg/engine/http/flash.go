@@ -2,6 +2,11 @@ package http
 
 import (
 	"encoding/gob"
+	"errors"
+	nethttp "net/http"
+	"net/url"
+
+	"github.com/shauryagautam/Astra/pkg/validate"
 )
 
 func init() {
@@ -60,3 +65,104 @@ func (c *Context) ErrorFlash(message string) {
 func (c *Context) SuccessFlash(message string) {
 	c.Flash("success", message)
 }
+
+// Redirection is a redirect that can carry flash data. Build it with
+// RedirectBack or RedirectTo, then finish with Send:
+//
+//	if err := v.ValidateStruct(&input); err != nil {
+//		return c.RedirectBack().WithErrors(err).WithInput().Send()
+//	}
+type Redirection struct {
+	c    *Context
+	url  string
+	code int
+}
+
+// RedirectBack redirects to the page that submitted the request, taken from
+// the Referer header when it points at this host. Otherwise it uses
+// fallback, or "/".
+func (c *Context) RedirectBack(fallback ...string) *Redirection {
+	target := "/"
+	if len(fallback) > 0 {
+		target = fallback[0]
+	}
+	if ref, err := url.Parse(c.Request.Referer()); err == nil && ref.Host == c.Request.Host && ref.Path != "" {
+		target = ref.RequestURI()
+	}
+	return c.RedirectTo(target)
+}
+
+// RedirectTo starts a 303 See Other redirect to target, so the browser
+// follows a form POST with a GET.
+func (c *Context) RedirectTo(target string) *Redirection {
+	return &Redirection{c: c, url: target, code: nethttp.StatusSeeOther}
+}
+
+// Status changes the redirect status code.
+func (r *Redirection) Status(code int) *Redirection {
+	r.code = code
+	return r
+}
+
+// With flashes a message, like Context.Flash.
+func (r *Redirection) With(key, message string) *Redirection {
+	r.c.Flash(key, message)
+	return r
+}
+
+// WithErrors flashes validation errors for the next request, where
+// Session().Errors and the "Errors" view variable return them. errs may be
+// a *validate.ValidationErrors (or an error wrapping one), a
+// validate.ValidationResult, a map[string][]string or a map[string]string.
+// Other errors are flashed under the "error" key.
+func (r *Redirection) WithErrors(errs any) *Redirection {
+	sess := r.c.Session()
+	if sess == nil || errs == nil {
+		return r
+	}
+	fields := map[string][]string{}
+	var ve *validate.ValidationErrors
+	switch e := errs.(type) {
+	case map[string][]string:
+		fields = e
+	case map[string]string:
+		for k, msg := range e {
+			fields[k] = []string{msg}
+		}
+	case validate.ValidationResult:
+		for k, msg := range e.Errors {
+			fields[k] = []string{msg}
+		}
+	case *validate.ValidationResult:
+		for k, msg := range e.Errors {
+			fields[k] = []string{msg}
+		}
+	case error:
+		if errors.As(e, &ve) {
+			fields = ve.Fields
+		} else {
+			fields["error"] = []string{e.Error()}
+		}
+	}
+	sess.FlashErrors(fields)
+	return r
+}
+
+// WithInput flashes the submitted form values for the next request, where
+// Session().Old and the "Old" view variable return them. Password fields
+// are never flashed.
+func (r *Redirection) WithInput() *Redirection {
+	sess := r.c.Session()
+	if sess == nil {
+		return r
+	}
+	if err := r.c.Request.ParseForm(); err == nil {
+		sess.FlashInput(r.c.Request.PostForm)
+	}
+	return r
+}
+
+// Send writes the redirect.
+func (r *Redirection) Send() error {
+	return r.c.Redirect(r.url, r.code)
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/session"
+	"github.com/shauryagautam/Astra/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectBack_WithErrorsAndInput(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, slog.Default())
+	router.Use(SessionMiddleware(session.NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))))
+
+	router.Post("/signup", func(c *Context) error {
+		ve := validate.NewValidationErrors()
+		ve.Add("email", "email is taken")
+		return c.RedirectBack("/fallback").WithErrors(ve).WithInput().Send()
+	})
+	var old, pass, fieldErr string
+	router.Get("/signup", func(c *Context) error {
+		sess := c.Session()
+		old, pass, fieldErr = sess.Old("email"), sess.Old("password", "none"), sess.FieldError("email")
+		return c.SendString("form")
+	})
+
+	form := url.Values{"email": {"ada@example.com"}, "password": {"hunter2"}}
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", "http://example.com/signup?plan=pro")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/signup?plan=pro", rec.Header().Get("Location"))
+	cookies := rec.Result().Cookies()
+	require.NotEmpty(t, cookies)
+
+	get := func(cookies []*http.Cookie) []*http.Cookie {
+		req := httptest.NewRequest(http.MethodGet, "/signup", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Result().Cookies()
+	}
+
+	next := get(cookies)
+	assert.Equal(t, "ada@example.com", old)
+	assert.Equal(t, "none", pass, "passwords are never flashed")
+	assert.Equal(t, "email is taken", fieldErr)
+
+	// The data survives exactly one request.
+	get(next)
+	assert.Empty(t, old)
+	assert.Empty(t, fieldErr)
+}
+
+func TestRedirectBack_ForeignReferer(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/x", nil))
+	c.Request.Header.Set("Referer", "https://evil.example/phish")
+	assert.Equal(t, "/home", c.RedirectBack("/home").url)
+	assert.Equal(t, "/", c.RedirectBack().url)
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shauryagautam/Astra/pkg/validate"
+)
+
+// FormRequest is a request struct that carries its own validation rules and
+// authorization check, so a handler states what it accepts next to what it
+// does. `astra make:request` generates one:
+//
+//	type CreateUserRequest struct {
+//		Email string `json:"email"`
+//		Name  string `json:"name"`
+//	}
+//
+//	func (r *CreateUserRequest) Authorize(c *http.Context) bool { return c.AuthUser() != nil }
+//
+//	func (r *CreateUserRequest) Rules() []validate.MemberSchema {
+//		return []validate.MemberSchema{
+//			validate.Member("email", func(f *validate.FieldBuilder) { f.Required().Trim().Email() }),
+//			validate.Member("name", func(f *validate.FieldBuilder) { f.Required().MaxLength(100) }),
+//		}
+//	}
+//
+//	func (r *CreateUserRequest) Messages() map[string]string {
+//		return map[string]string{"email": "we need an email address to reach you"}
+//	}
+type FormRequest interface {
+	// Authorize reports whether the current user may make the request. It
+	// runs before validation, so an unauthorized client learns nothing
+	// about the rules.
+	Authorize(c *Context) bool
+	// Rules validates the request input, as with validate.Compile.
+	Rules() []validate.MemberSchema
+	// Messages replaces the error message of a field, keyed by field name.
+	// It may return nil.
+	Messages() map[string]string
+}
+
+// CollectAll is implemented by a FormRequest that reports every failing
+// rule of a field rather than only the first, as with
+// validate.CompiledSchema.CollectAll.
+type CollectAll interface {
+	CollectAll() bool
+}
+
+// HandleForm adapts a handler taking a FormRequest to a HandlerFunc. Astra
+// resolves dependencies at compile time rather than from a container, so
+// HandleForm is what builds the request: it checks Authorize, validates the
+// input (query string and body, as returned by Context.All) against Rules
+// and decodes the validated input, mutators applied, into a new T before
+// calling handler. Only keys named in Rules are decoded, so a client cannot
+// set a field the request does not validate, such as an is_admin flag; give
+// a key a rule without checks to accept it as is:
+//
+//	r.Post("/users", http.HandleForm(users.Create))
+//
+//	func (uc *UserController) Create(c *http.Context, req *CreateUserRequest) error
+//
+// A failed Authorize is answered with 403 and invalid input with 422 and the
+// same envelope as ValidateRequest, field messages under details.fields.
+// Each failing field reports its first failure, or every failure when the
+// request also implements CollectAll. String input is converted for
+// numeric and boolean fields, so a form's "42" binds to an int; input that
+// still does not fit T, such as "yes" for a bool, gets 400, as does a body
+// over the router's JSONLimits. handler is not called in any of these
+// cases.
+func HandleForm[T any, PT interface {
+	*T
+	FormRequest
+}](handler func(*Context, PT) error) HandlerFunc {
+	return func(c *Context) error {
+		req := PT(new(T))
+		if !req.Authorize(c) {
+			return c.ForbiddenError("")
+		}
+
+		if err := c.InputError(); err != nil {
+			var limitErr *HTTPError
+			if errors.As(err, &limitErr) {
+				return c.rejectBody(limitErr)
+			}
+			return err
+		}
+		input := c.All()
+		schema := validate.Compile(req.Rules()...)
+		if ca, ok := any(req).(CollectAll); ok && ca.CollectAll() {
+			schema = schema.CollectAll()
+		}
+		result := schema.Validate(input)
+		if !result.Valid {
+			messages := req.Messages()
+			fields := make(map[string][]string, len(result.AllErrors))
+			for field, msgs := range result.AllErrors {
+				if custom, ok := messages[field]; ok {
+					msgs = []string{custom}
+				}
+				fields[field] = msgs
+			}
+			return c.ErrorWithDetails(http.StatusUnprocessableEntity, ErrCodeValidation,
+				"the given data was invalid", map[string]any{"fields": fields})
+		}
+
+		if err := validate.Bind(result.Data, req); err != nil {
+			return c.BadRequestError("the given data does not match the request")
+		}
+		return handler(c, req)
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createPostRequest struct {
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags"`
+	Draft    bool     `json:"draft"`
+	Priority int      `json:"priority"`
+	IsAdmin  bool     `json:"is_admin"`
+}
+
+func (r *createPostRequest) Authorize(c *Context) bool {
+	return c.Request.Header.Get("X-Author") != ""
+}
+
+func (r *createPostRequest) Rules() []validate.MemberSchema {
+	return []validate.MemberSchema{
+		validate.Member("title", func(f *validate.FieldBuilder) { f.Required().Trim().MaxLength(10) }),
+		validate.Member("tags", func(f *validate.FieldBuilder) { f.Array() }),
+		validate.Member("draft", func(f *validate.FieldBuilder) {}),
+		validate.Member("priority", func(f *validate.FieldBuilder) { f.Integer() }),
+	}
+}
+
+func (r *createPostRequest) Messages() map[string]string {
+	return map[string]string{"title": "give the post a short title"}
+}
+
+func TestHandleForm(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+
+	var got *createPostRequest
+	router.Post("/posts", HandleForm(func(c *Context, req *createPostRequest) error {
+		got = req
+		return c.NoContent()
+	}))
+
+	post := func(body string, author bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if author {
+			req.Header.Set("X-Author", "ada")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"title":"  Hello  ","tags":["go"],"draft":true,"is_admin":true}`, true)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	require.NotNil(t, got)
+	assert.Equal(t, &createPostRequest{Title: "Hello", Tags: []string{"go"}, Draft: true}, got, "rules' mutators apply and keys without a rule are not bound")
+
+	got = nil
+	rec = post(`{"title":"Hello"}`, false)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Nil(t, got)
+
+	rec = post(`{"title":"far too long a title"}`, true)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"VALIDATION_ERROR","message":"the given data was invalid","details":{"fields":{
+		"title":["give the post a short title"]}}}}`, rec.Body.String())
+	assert.Nil(t, got)
+
+	rec = post(`{"title":"Hello","draft":"true","priority":"3"}`, true)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, &createPostRequest{Title: "Hello", Draft: true, Priority: 3}, got, "string input converts for bool and int fields")
+
+	got = nil
+	rec = post(`{"title":"Hello","draft":"yes"}`, true)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Nil(t, got)
+}
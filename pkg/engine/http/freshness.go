@@ -0,0 +1,260 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	nethttp "net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Freshness identifies a version of a resource for conditional requests.
+type Freshness struct {
+	// ETag is the entity tag, quoted as sent on the wire, such as
+	// `W/"5f0c3e1a9b2d4c68"`. Empty means none.
+	ETag string
+	// LastModified is when the resource last changed. Zero means unknown.
+	LastModified time.Time
+}
+
+// IsZero reports whether f carries no validator.
+func (f Freshness) IsZero() bool {
+	return f.ETag == "" && f.LastModified.IsZero()
+}
+
+// FreshnessOf derives Freshness from ORM models: a model, a pointer to one or
+// a slice of them. The ETag is weak and hashes each model's type, primary key,
+// `orm:"version"` counter and UpdatedAt, so it changes on every Save and when
+// rows join or leave a list. LastModified is the latest UpdatedAt. Values
+// that are not models, or have neither a version nor an UpdatedAt field,
+// give a zero Freshness.
+func FreshnessOf(v any) Freshness {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return Freshness{}
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return Freshness{}
+	}
+
+	items := []reflect.Value{rv}
+	elem := rv.Type()
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items = make([]reflect.Value, rv.Len())
+		for i := range items {
+			items[i] = reflect.Indirect(rv.Index(i))
+		}
+		if elem = elem.Elem(); elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+	}
+	if elem.Kind() != reflect.Struct {
+		return Freshness{}
+	}
+	fields := freshnessFieldsOf(elem)
+	if fields.version == nil && fields.updated == nil {
+		return Freshness{}
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00", elem.String(), len(items))
+	var modified time.Time
+	for _, item := range items {
+		if !item.IsValid() {
+			h.Write([]byte{0})
+			continue
+		}
+		if fields.id != nil {
+			fmt.Fprintf(h, "%v\x00", fieldValue(item, fields.id))
+		}
+		if fields.version != nil {
+			fmt.Fprintf(h, "%v\x00", fieldValue(item, fields.version))
+		}
+		if fields.updated != nil {
+			t := timeValue(fieldValue(item, fields.updated))
+			_ = binary.Write(h, binary.BigEndian, t.UnixNano())
+			if t.After(modified) {
+				modified = t
+			}
+		}
+	}
+	return Freshness{
+		ETag:         `W/"` + hex.EncodeToString(h.Sum(nil)[:8]) + `"`,
+		LastModified: modified,
+	}
+}
+
+// freshnessFields are the field indexes FreshnessOf reads, nil when absent.
+type freshnessFields struct {
+	id, version, updated []int
+}
+
+var freshnessCache sync.Map // map[reflect.Type]*freshnessFields
+
+func freshnessFieldsOf(t reflect.Type) *freshnessFields {
+	if cached, ok := freshnessCache.Load(t); ok {
+		return cached.(*freshnessFields)
+	}
+	fields := &freshnessFields{}
+	collectFreshnessFields(t, nil, fields)
+	cached, _ := freshnessCache.LoadOrStore(t, fields)
+	return cached.(*freshnessFields)
+}
+
+// collectFreshnessFields follows the ORM's conventions, including fields of
+// embedded structs such as database.Model and database.Versioned.
+func collectFreshnessFields(t reflect.Type, parent []int, fields *freshnessFields) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct && ft != timeType {
+			if f.Type.Kind() != reflect.Pointer {
+				collectFreshnessFields(ft, index, fields)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tags := strings.Split(f.Tag.Get("orm"), ";")
+		switch {
+		case fields.version == nil && containsTag(tags, "version"):
+			fields.version = index
+		case fields.id == nil && (containsTag(tags, "primary_key") || f.Name == "ID"):
+			fields.id = index
+		case fields.updated == nil && f.Name == "UpdatedAt" && ft == timeType:
+			fields.updated = index
+		}
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func containsTag(tags []string, name string) bool {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldValue(v reflect.Value, index []int) any {
+	return v.FieldByIndex(index).Interface()
+}
+
+func timeValue(v any) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case *time.Time:
+		if t != nil {
+			return *t
+		}
+	}
+	return time.Time{}
+}
+
+// NotModified sets the ETag and Last-Modified headers from f and reports
+// whether the client's copy is still current, in which case it has answered
+// 304 Not Modified and the handler should return. Only GET and HEAD requests
+// are answered with 304; If-None-Match takes precedence over
+// If-Modified-Since.
+//
+//	if c.NotModified(http.FreshnessOf(post)) {
+//		return nil
+//	}
+func (c *Context) NotModified(f Freshness) bool {
+	if c.written {
+		return true
+	}
+	h := c.Writer.Header()
+	if f.ETag != "" {
+		h.Set("ETag", f.ETag)
+	}
+	if !f.LastModified.IsZero() {
+		h.Set("Last-Modified", f.LastModified.UTC().Format(nethttp.TimeFormat))
+	}
+	if c.Request.Method != nethttp.MethodGet && c.Request.Method != nethttp.MethodHead {
+		return false
+	}
+	if !isFresh(c.Request, f) {
+		return false
+	}
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	c.Writer.WriteHeader(nethttp.StatusNotModified)
+	c.written = true
+	return true
+}
+
+// isFresh evaluates If-None-Match and If-Modified-Since as RFC 9110 section
+// 13.2.2 orders them.
+func isFresh(r *nethttp.Request, f Freshness) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return f.ETag != "" && etagMatches(inm, f.ETag)
+	}
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || f.LastModified.IsZero() {
+		return false
+	}
+	since, err := nethttp.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !f.LastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches compares an If-None-Match list with etag using the weak
+// comparison, so W/"x" matches "x".
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONWithFreshness is JSON for cacheable reads. It sets ETag and
+// Last-Modified from FreshnessOf(v) and answers 304 to a conditional GET
+// when the client's copy is current, without encoding v. For values that
+// are not models the ETag is a hash of the encoded body, which saves the
+// transfer but not the encoding.
+//
+//	post, err := database.Query[Post](db).FindByID(id, c.Ctx())
+//	if err != nil {
+//		return err
+//	}
+//	return c.JSONWithFreshness(post)
+func (c *Context) JSONWithFreshness(v any, status ...int) error {
+	if f := FreshnessOf(v); !f.IsZero() {
+		if c.NotModified(f) {
+			return nil
+		}
+		return c.JSON(v, status...)
+	}
+
+	body, err := c.json.marshal(v)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	if c.NotModified(Freshness{ETag: `"` + hex.EncodeToString(sum[:8]) + `"`}) {
+		return nil
+	}
+	return c.writeJSON(body, status...)
+}
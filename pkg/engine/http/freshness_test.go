@@ -0,0 +1,101 @@
+package http
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type freshPost struct {
+	database.Model
+	database.Versioned
+	Title string `json:"title"`
+}
+
+func TestFreshnessOf(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+	post := &freshPost{Model: database.Model{ID: 7, UpdatedAt: updated}, Versioned: database.Versioned{Version: 3}}
+
+	f := FreshnessOf(post)
+	assert.Regexp(t, `^W/"[0-9a-f]{16}"$`, f.ETag)
+	assert.Equal(t, updated, f.LastModified)
+	assert.Equal(t, f, FreshnessOf(*post))
+
+	saved := *post
+	saved.Version = 4
+	assert.NotEqual(t, f.ETag, FreshnessOf(&saved).ETag)
+
+	// A list changes when any row changes or when rows come and go.
+	later := freshPost{Model: database.Model{ID: 8, UpdatedAt: updated.Add(time.Hour)}}
+	list := FreshnessOf([]*freshPost{post, &later})
+	assert.Equal(t, updated.Add(time.Hour), list.LastModified)
+	assert.NotEqual(t, list.ETag, FreshnessOf([]*freshPost{post}).ETag)
+
+	assert.True(t, FreshnessOf(map[string]int{"a": 1}).IsZero())
+	assert.True(t, FreshnessOf(struct{ Name string }{"x"}).IsZero())
+	assert.True(t, FreshnessOf((*freshPost)(nil)).IsZero())
+	assert.True(t, FreshnessOf(nil).IsZero())
+}
+
+func TestContext_JSONWithFreshness(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+	post := &freshPost{Model: database.Model{ID: 7, UpdatedAt: updated}, Versioned: database.Versioned{Version: 3}, Title: "Hello"}
+
+	router := NewRouter(nil, nil)
+	router.Get("/posts/7", func(c *Context) error { return c.JSONWithFreshness(post) })
+	router.Post("/posts/7", func(c *Context) error { return c.JSONWithFreshness(post) })
+	router.Get("/stats", func(c *Context) error { return c.JSONWithFreshness(map[string]int{"posts": 1}) })
+
+	serve := func(method, path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := serve("GET", "/posts/7")
+	require.Equal(t, nethttp.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, FreshnessOf(post).ETag, etag)
+	assert.Equal(t, "Sun, 01 Mar 2026 12:00:00 GMT", first.Header().Get("Last-Modified"))
+	assert.Contains(t, first.Body.String(), `"title":"Hello"`)
+
+	cases := []struct {
+		name         string
+		method, path string
+		header       []string
+		want         int
+	}{
+		{"matching etag", "GET", "/posts/7", []string{"If-None-Match", etag}, nethttp.StatusNotModified},
+		{"etag list", "GET", "/posts/7", []string{"If-None-Match", `"other", ` + etag}, nethttp.StatusNotModified},
+		{"stale etag", "GET", "/posts/7", []string{"If-None-Match", `W/"other"`}, nethttp.StatusOK},
+		{"etag wins over date", "GET", "/posts/7", []string{"If-None-Match", `"other"`, "If-Modified-Since", "Sun, 01 Mar 2026 12:00:00 GMT"}, nethttp.StatusOK},
+		{"not modified since", "GET", "/posts/7", []string{"If-Modified-Since", "Sun, 01 Mar 2026 12:00:00 GMT"}, nethttp.StatusNotModified},
+		{"modified since", "GET", "/posts/7", []string{"If-Modified-Since", "Sun, 01 Mar 2026 11:59:59 GMT"}, nethttp.StatusOK},
+		{"unsafe method", "POST", "/posts/7", []string{"If-None-Match", etag}, nethttp.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(tc.method, tc.path, tc.header...)
+			assert.Equal(t, tc.want, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tc.want == nethttp.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+
+	// Values that are not models get an ETag hashed from the body.
+	stats := serve("GET", "/stats")
+	statsTag := stats.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, statsTag)
+	assert.Equal(t, nethttp.StatusNotModified, serve("GET", "/stats", "If-None-Match", statsTag).Code)
+}
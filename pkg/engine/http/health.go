@@ -16,6 +16,7 @@ func HealthHandler(checks map[string]engine.HealthProvider) HandlerFunc {
 		}
 
 		results := make(map[string]string)
+		details := make(map[string]any)
 		var mu sync.Mutex
 		var wg sync.WaitGroup
 		hasError := false
@@ -33,6 +34,9 @@ func HealthHandler(checks map[string]engine.HealthProvider) HandlerFunc {
 				} else {
 					results[name] = "ok"
 				}
+				if d, ok := hp.(engine.HealthDetailer); ok {
+					details[name] = d.HealthDetails()
+				}
 			}(name, check)
 		}
 
@@ -43,6 +47,9 @@ func HealthHandler(checks map[string]engine.HealthProvider) HandlerFunc {
 			"status": "ok",
 			"checks": results,
 		}
+		if len(details) > 0 {
+			response["details"] = details
+		}
 
 		if hasError {
 			status = http.StatusServiceUnavailable
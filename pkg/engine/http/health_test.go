@@ -60,3 +60,25 @@ func TestHealthHandler_Error(t *testing.T) {
 	assert.Contains(t, rec.Body.String(), `"status":"error"`)
 	assert.Contains(t, rec.Body.String(), `"connection refused"`)
 }
+
+type detailedCheck struct{}
+
+func (detailedCheck) CheckHealth(ctx context.Context) error { return nil }
+func (detailedCheck) HealthDetails() map[string]any {
+	return map[string]any{"in_use": 3}
+}
+
+func TestHealthHandler_Details(t *testing.T) {
+	app := NewTestApp()
+	app.RegisterHealthCheck("db", detailedCheck{})
+
+	router := NewRouter(app.Config(), app.Logger())
+	router.Get("/health", HealthHandler(app.GetHealthChecks()))
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"details":{"db":{"in_use":3}}`)
+}
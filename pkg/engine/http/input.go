@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	nethttp "net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxInputMemory is how much of a multipart body is kept in memory while
+// parsing input; larger files go to temporary files, as with net/http.
+const maxInputMemory = 32 << 20
+
+// Input returns the request input at path, a dot-separated key such as
+// "email", "user.name" or "items.0.sku", or nil when it is absent.
+//
+// Input merges the query string with the body, the body winning. Form and
+// multipart bodies are read with PHP-style bracket keys, so user[name]=Ada
+// becomes {"user": {"name": "Ada"}}, tags[]=a&tags[]=b becomes ["a", "b"]
+// and a key sent more than once becomes an array. A JSON object body is
+// used as is. The input is parsed once per request; the body stays readable
+// for Bind. A body that cannot be parsed is ignored, as is a JSON body over
+// the router's JSONLimits, which InputError then reports.
+func (c *Context) Input(path string) any {
+	v, _ := lookupInput(c.parsedInput(), path)
+	return v
+}
+
+// InputString returns the input at path if it is a string, else def or "".
+func (c *Context) InputString(path string, def ...string) string {
+	if s, ok := c.Input(path).(string); ok {
+		return s
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// All returns the whole request input as Input sees it. The map is a copy:
+// changing it does not affect later calls.
+func (c *Context) All() map[string]any {
+	return copyInput(c.parsedInput()).(map[string]any)
+}
+
+// Only returns a copy of the input at the given paths, keeping their
+// nesting: Only("user.name") returns {"user": {"name": ...}}.
+func (c *Context) Only(paths ...string) map[string]any {
+	input := c.parsedInput()
+	out := make(map[string]any, len(paths))
+	for _, path := range paths {
+		if v, ok := lookupInput(input, path); ok {
+			setInput(out, strings.Split(path, "."), copyInput(v))
+		}
+	}
+	return out
+}
+
+// Except returns a copy of the input without the given paths, for example
+// to drop a password before logging a form.
+func (c *Context) Except(paths ...string) map[string]any {
+	out := c.All()
+	for _, path := range paths {
+		segs := strings.Split(path, ".")
+		parent, ok := lookupInput(out, strings.Join(segs[:len(segs)-1], "."))
+		if len(segs) == 1 {
+			parent, ok = out, true
+		}
+		if m, isMap := parent.(map[string]any); ok && isMap {
+			delete(m, segs[len(segs)-1])
+		}
+	}
+	return out
+}
+
+// InputError returns why the body was left out of the input: a JSON body
+// over the router's JSONLimits, as a 400 or 413 *HTTPError. It is nil for
+// bodies that were read, and for malformed ones, which are ignored.
+func (c *Context) InputError() error {
+	c.parsedInput()
+	return c.inputErr
+}
+
+// readCloser reads from Reader and closes Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// parsedInput parses the query and body on first use. The tree it returns
+// is shared and must not be modified.
+func (c *Context) parsedInput() map[string]any {
+	if c.input != nil {
+		return c.input
+	}
+	tree := make(map[string]any)
+	addValues(tree, c.Request.URL.Query())
+
+	r := c.Request
+	var doc map[string]any
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case r.Body == nil || r.Body == nethttp.NoBody:
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		body, err := c.readJSONBody()
+		// Put back what was read, followed by any rest of an oversized
+		// body, so Bind sees the same body and reaches the same verdict.
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		var limitErr *HTTPError
+		if errors.As(err, &limitErr) {
+			c.inputErr = err
+		}
+		if err != nil || json.Unmarshal(body, &doc) != nil {
+			doc = nil
+		}
+	case mediaType == "multipart/form-data":
+		if r.ParseMultipartForm(maxInputMemory) == nil {
+			addValues(tree, r.PostForm)
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if r.ParseForm() == nil {
+			addValues(tree, r.PostForm)
+		}
+	}
+
+	for k, v := range tree {
+		tree[k] = finishInput(v)
+	}
+	for k, v := range doc {
+		tree[k] = v
+	}
+	c.input = tree
+	return tree
+}
+
+// addValues inserts form values into tree. Keys are sorted so that appended
+// elements keep a stable order.
+func addValues(tree map[string]any, values url.Values) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		segs := splitInputKey(key)
+		vals := values[key]
+		if segs[len(segs)-1] == "" {
+			for _, v := range vals {
+				setInput(tree, segs, v)
+			}
+			continue
+		}
+		if len(vals) == 1 {
+			setInput(tree, segs, vals[0])
+			continue
+		}
+		list := make([]any, len(vals))
+		for i, v := range vals {
+			list[i] = v
+		}
+		setInput(tree, segs, list)
+	}
+}
+
+// splitInputKey splits "items[0][name]" into ["items", "0", "name"] and
+// "tags[]" into ["tags", ""]. A key with unbalanced brackets is kept whole.
+func splitInputKey(key string) []string {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+	segs := []string{key[:open]}
+	rest := key[open:]
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return []string{key}
+		}
+		segs = append(segs, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return segs
+}
+
+// setInput stores value at segs in m, creating objects on the way. An empty
+// segment appends: it is replaced by the next free index.
+func setInput(m map[string]any, segs []string, value any) {
+	for i, seg := range segs {
+		if seg == "" {
+			seg = strconv.Itoa(len(m))
+		}
+		if i == len(segs)-1 {
+			m[seg] = value
+			return
+		}
+		child, ok := m[seg].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			m[seg] = child
+		}
+		m = child
+	}
+}
+
+// finishInput turns objects whose keys are exactly 0..n-1 into arrays.
+func finishInput(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	for k, child := range m {
+		m[k] = finishInput(child)
+	}
+	if len(m) == 0 {
+		return m
+	}
+	list := make([]any, len(m))
+	for k, child := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != k {
+			return m
+		}
+		list[i] = child
+	}
+	return list
+}
+
+// lookupInput follows a dot path through objects and arrays. An empty path
+// returns v itself.
+func lookupInput(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, seg := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			child, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			v = child
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// copyInput deep-copies the objects and arrays of an input tree.
+func copyInput(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			out[k] = copyInput(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			out[i] = copyInput(child)
+		}
+		return out
+	}
+	return v
+}
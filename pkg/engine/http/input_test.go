@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_InputForm(t *testing.T) {
+	form := url.Values{
+		"user[name]":         {"Ada"},
+		"user[email]":        {"ada@example.com"},
+		"tags[]":             {"go", "web"},
+		"items[0][sku]":      {"A-1"},
+		"items[1][sku]":      {"B-2"},
+		"items[1][qty]":      {"3"},
+		"colors":             {"red", "blue"},
+		"page":               {"2"},
+		"odd[":               {"kept"},
+		"sparse[0]":          {"a"},
+		"sparse[2]":          {"c"},
+		"user[address][zip]": {"12345"},
+	}
+	req := httptest.NewRequest("POST", "/?page=1&q=search", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := NewContext(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "Ada", c.Input("user.name"))
+	assert.Equal(t, "12345", c.Input("user.address.zip"))
+	assert.Equal(t, []any{"go", "web"}, c.Input("tags"))
+	assert.Equal(t, "B-2", c.Input("items.1.sku"))
+	assert.Equal(t, map[string]any{"sku": "A-1"}, c.Input("items.0"))
+	assert.Equal(t, []any{"red", "blue"}, c.Input("colors"))
+	assert.Equal(t, "kept", c.Input("odd["))
+	assert.Equal(t, map[string]any{"0": "a", "2": "c"}, c.Input("sparse"))
+	assert.Nil(t, c.Input("items.5.sku"))
+	assert.Nil(t, c.Input("user.name.first"))
+
+	// The body wins over the query; other query keys are kept.
+	assert.Equal(t, "2", c.Input("page"))
+	assert.Equal(t, "search", c.InputString("q"))
+	assert.Equal(t, "fallback", c.InputString("missing", "fallback"))
+
+	// Callers get copies, so changing one does not leak into the next.
+	all := c.All()
+	all["user"].(map[string]any)["name"] = "Mallory"
+	delete(all, "tags")
+	assert.Equal(t, "Ada", c.Input("user.name"))
+	assert.Contains(t, c.All(), "tags")
+
+	only := c.Only("user.name", "page", "missing")
+	assert.Equal(t, map[string]any{"user": map[string]any{"name": "Ada"}, "page": "2"}, only)
+	only["user"].(map[string]any)["name"] = "Eve"
+	assert.Equal(t, "Ada", c.Input("user.name"))
+
+	except := c.Except("user.email", "tags", "items")
+	assert.NotContains(t, except, "tags")
+	assert.NotContains(t, except, "items")
+	assert.Equal(t, map[string]any{"name": "Ada", "address": map[string]any{"zip": "12345"}}, except["user"])
+	assert.Equal(t, "ada@example.com", c.Input("user.email"))
+}
+
+func TestContext_InputJSON(t *testing.T) {
+	body := `{"user":{"name":"Ada","roles":["admin"]},"page":3}`
+	req := httptest.NewRequest("POST", "/?page=1&q=x", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	c := NewContext(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "admin", c.Input("user.roles.0"))
+	assert.Equal(t, float64(3), c.Input("page"))
+	assert.Equal(t, "x", c.Input("q"))
+
+	// The body can still be bound after Input read it.
+	var bound struct {
+		User struct{ Name string } `json:"user"`
+	}
+	require.NoError(t, c.Bind(&bound))
+	assert.Equal(t, "Ada", bound.User.Name)
+
+	// Malformed bodies are ignored.
+	req = httptest.NewRequest("POST", "/?q=x", strings.NewReader(`{"user":`))
+	req.Header.Set("Content-Type", "application/json")
+	c = NewContext(httptest.NewRecorder(), req)
+	assert.Equal(t, map[string]any{"q": "x"}, c.All())
+}
+
+func TestContext_InputMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("post[title]", "Hello"))
+	require.NoError(t, mw.WriteField("post[tags][]", "a"))
+	require.NoError(t, mw.WriteField("post[tags][]", "b"))
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest("POST", "/", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	c := NewContext(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "Hello", c.Input("post.title"))
+	assert.Equal(t, []any{"a", "b"}, c.Input("post.tags"))
+}
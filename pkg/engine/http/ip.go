@@ -78,6 +78,29 @@ func GetClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
 	return remote
 }
 
+// ParsePrefixes parses CIDR ranges and single addresses, such as
+// "10.0.0.0/8" or "::1", into prefixes. Blank and invalid entries are
+// skipped, so a typo narrows the list instead of widening it.
+func ParsePrefixes(values ...string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if strings.Contains(value, "/") {
+			if prefix, err := netip.ParsePrefix(value); err == nil {
+				out = append(out, prefix)
+			}
+			continue
+		}
+		if addr, err := netip.ParseAddr(value); err == nil {
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return out
+}
+
 // IsTrustedProxy checks if an IP address matches any of the trusted proxy prefixes.
 func IsTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
 	for _, prefix := range trusted {
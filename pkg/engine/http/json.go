@@ -0,0 +1,102 @@
+package http
+
+import (
+	"iter"
+	nethttp "net/http"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	astrajson "github.com/shauryagautam/Astra/pkg/engine/json"
+)
+
+// streamFlushEvery is how many elements StreamJSON writes between flushes.
+const streamFlushEvery = 100
+
+// JSONConfig controls how Context.JSON and StreamJSON write responses.
+// NewRouter enables Pretty when APP_DEBUG is on; swap the encoder with:
+//
+//	router.JSON.Encoder = astrajson.FastEncoder{}
+type JSONConfig struct {
+	// Encoder serializes values. Nil means astrajson.StdEncoder.
+	Encoder engine.JSONEncoder
+	// Pretty indents output with two spaces.
+	Pretty bool
+	// Charset is appended to the Content-Type header when set, e.g. "utf-8".
+	Charset string
+}
+
+func (j JSONConfig) encoder() engine.JSONEncoder {
+	if j.Encoder == nil {
+		return astrajson.StdEncoder{}
+	}
+	return j.Encoder
+}
+
+func (j JSONConfig) marshal(v any) ([]byte, error) {
+	if j.Pretty {
+		return j.encoder().MarshalIndent(v, "", "  ")
+	}
+	return j.encoder().Marshal(v)
+}
+
+func (j JSONConfig) contentType() string {
+	if j.Charset == "" {
+		return "application/json"
+	}
+	return "application/json; charset=" + j.Charset
+}
+
+// StreamJSON writes seq as a JSON array without materializing it, flushing
+// periodically so clients start receiving data immediately. It pairs with
+// the ORM's iterator API:
+//
+//	return http.StreamJSON(c, database.Query[User](db).All(c.Ctx()))
+//
+// The status line is sent before the first element, so an error from seq can
+// no longer change it; the array is left unterminated and the error returned
+// for logging, which makes the truncation visible to the client.
+func StreamJSON[T any](c *Context, seq iter.Seq2[T, error], status ...int) error {
+	if c.written {
+		return nil
+	}
+
+	code := nethttp.StatusOK
+	if c.status != 0 {
+		code = c.status
+	}
+	if len(status) > 0 {
+		code = status[0]
+	}
+
+	c.Writer.Header().Set("Content-Type", c.json.contentType())
+	c.Writer.WriteHeader(code)
+	c.written = true
+
+	flusher, _ := c.Writer.(nethttp.Flusher)
+	enc := c.json.encoder()
+
+	if _, err := c.Writer.Write([]byte{'['}); err != nil {
+		return err
+	}
+	n := 0
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+		body, err := enc.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			body = append([]byte{','}, body...)
+		}
+		if _, err := c.Writer.Write(body); err != nil {
+			return err
+		}
+		n++
+		if flusher != nil && n%streamFlushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+	_, err := c.Writer.Write([]byte("]\n"))
+	return err
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"slices"
+	"strconv"
+)
+
+// JSONLimits bounds the JSON request bodies that Input, All, Bind,
+// ValidateRequest and HandleForm read, so pathological payloads are refused
+// before they reach encoding/json or a handler. A zero field means no
+// limit.
+type JSONLimits struct {
+	// MaxBytes caps the body size. A larger body is a 413.
+	MaxBytes int64
+	// MaxDepth caps how deeply objects and arrays nest.
+	MaxDepth int
+	// MaxKeys caps the number of object keys in the whole document.
+	MaxKeys int
+	// MaxStringLength caps the length in bytes of each string and key.
+	MaxStringLength int
+	// BlockedKeys are object keys refused at any depth, such as
+	// "__proto__", which pollutes prototypes when a payload is forwarded to
+	// or merged by JavaScript. Only "__proto__" is blocked by default, as
+	// keys like "prototype" or "constructor" are also ordinary field names.
+	BlockedKeys []string
+}
+
+// DefaultJSONLimits are the limits NewRouter sets.
+var DefaultJSONLimits = JSONLimits{
+	MaxBytes:    1 << 20,
+	MaxDepth:    64,
+	MaxKeys:     10000,
+	BlockedKeys: []string{"__proto__"},
+}
+
+// readJSONBody reads the request body and checks it against the limits.
+// It returns the bytes read even on error, so callers can put them back.
+func (c *Context) readJSONBody() ([]byte, error) {
+	r := c.Request
+	if r.Body == nil || r.Body == nethttp.NoBody {
+		return nil, nil
+	}
+	limits := c.jsonLimits
+	reader := io.Reader(r.Body)
+	if limits.MaxBytes > 0 {
+		reader = io.LimitReader(r.Body, limits.MaxBytes+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return body, err
+	}
+	if limits.MaxBytes > 0 && int64(len(body)) > limits.MaxBytes {
+		return body, &HTTPError{
+			Status:  nethttp.StatusRequestEntityTooLarge,
+			Message: "request body is larger than " + formatFileSize(limits.MaxBytes),
+		}
+	}
+	return body, limits.check(body)
+}
+
+// check walks the tokens of body and returns a 400 *HTTPError for the
+// first limit it breaks. Malformed JSON is left to the decoder.
+func (l JSONLimits) check(body []byte) error {
+	if l.MaxDepth <= 0 && l.MaxKeys <= 0 && l.MaxStringLength <= 0 && len(l.BlockedKeys) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	// stack holds the open containers; wantKey is set on an object
+	// between its values, where a key or its end comes next.
+	type frame struct{ object, wantKey bool }
+	var stack []frame
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].wantKey = true
+		}
+	}
+	keys := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// io.EOF, or malformed JSON, which the decoder reports.
+			return nil
+		}
+
+		if n := len(stack); n > 0 && stack[n-1].wantKey {
+			if tok == json.Delim('}') {
+				stack = stack[:n-1]
+				valueDone()
+				continue
+			}
+			key, _ := tok.(string)
+			stack[n-1].wantKey = false
+			keys++
+			switch {
+			case l.MaxKeys > 0 && keys > l.MaxKeys:
+				return jsonLimitError("has more than " + strconv.Itoa(l.MaxKeys) + " keys")
+			case l.MaxStringLength > 0 && len(key) > l.MaxStringLength:
+				return jsonLimitError("has a key longer than " + strconv.Itoa(l.MaxStringLength) + " bytes")
+			case slices.Contains(l.BlockedKeys, key):
+				return jsonLimitError(fmt.Sprintf("has the forbidden key %q", key))
+			}
+			continue
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			if l.MaxDepth > 0 && len(stack) >= l.MaxDepth {
+				return jsonLimitError("nests deeper than " + strconv.Itoa(l.MaxDepth) + " levels")
+			}
+			object := tok == json.Delim('{')
+			stack = append(stack, frame{object: object, wantKey: object})
+		case json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+		default:
+			if s, ok := tok.(string); ok && l.MaxStringLength > 0 && len(s) > l.MaxStringLength {
+				return jsonLimitError("has a string longer than " + strconv.Itoa(l.MaxStringLength) + " bytes")
+			}
+			valueDone()
+		}
+	}
+}
+
+// rejectBody answers a body refused by the limits with the error envelope.
+func (c *Context) rejectBody(err *HTTPError) error {
+	code, _ := errorCode(err, err.Status)
+	return c.ErrorWithDetails(err.Status, code, err.Message, nil)
+}
+
+func jsonLimitError(reason string) error {
+	return &HTTPError{
+		Status:  nethttp.StatusBadRequest,
+		Message: "request body " + reason,
+		Code:    ErrCodeBadRequest,
+	}
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLimits_Check(t *testing.T) {
+	l := JSONLimits{MaxDepth: 3, MaxKeys: 4, MaxStringLength: 9, BlockedKeys: []string{"__proto__"}}
+	cases := []struct {
+		body string
+		want string
+	}{
+		{`{"a":[1,{"b":"abc"}],"c":{}}`, ""},
+		{`[[[1]]]`, ""},
+		{`[[[[1]]]]`, "request body nests deeper than 3 levels"},
+		{`{"a":1,"b":2,"c":3,"d":4,"e":5}`, "request body has more than 4 keys"},
+		{`{"a":"much too long"}`, "request body has a string longer than 9 bytes"},
+		{`{"much too long":1}`, "request body has a key longer than 9 bytes"},
+		{`{"a":{"__proto__":{"admin":true}}}`, `request body has the forbidden key "__proto__"`},
+		{`{"a":"__proto__"}`, ""},
+		{`{"a":`, ""},
+	}
+	for _, tc := range cases {
+		err := l.check([]byte(tc.body))
+		if tc.want == "" {
+			assert.NoError(t, err, tc.body)
+			continue
+		}
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr, tc.body)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, tc.want, httpErr.Message)
+	}
+}
+
+func TestJSONLimits_Requests(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+	router.JSONLimits = JSONLimits{MaxBytes: 64, MaxDepth: 2, BlockedKeys: DefaultJSONLimits.BlockedKeys}
+
+	router.With(ValidateRequest[createUserInput](nil)).Post("/users", func(c *Context) error {
+		return c.NoContent()
+	})
+	router.Post("/input", func(c *Context) error {
+		if err := c.InputError(); err != nil {
+			return c.rejectBody(err.(*HTTPError))
+		}
+		var v map[string]any
+		if err := c.Bind(&v); err != nil {
+			return err
+		}
+		return c.JSON(c.All())
+	})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/input", `{"a":{"b":1}}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"a":{"b":1}}`, rec.Body.String())
+
+	rec = post("/input", `{"a":{"b":{"c":1}}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "nests deeper than 2 levels")
+
+	rec = post("/input", `{"a":"`+strings.Repeat("x", 100)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "larger than 64 bytes")
+
+	rec = post("/users", `{"email":"ada@example.com","name":"Ada","__proto__":{}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `forbidden key \"__proto__\"`)
+	assert.Equal(t, http.StatusNoContent, post("/users", `{"email":"ada@example.com","name":"Ada"}`).Code)
+
+	// "prototype" is an ordinary field name unless blocked explicitly.
+	assert.Equal(t, http.StatusOK, post("/input", `{"prototype":1}`).Code)
+
+	// Bind on its own returns the limit error.
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[[[1]]]`))
+	c := NewContext(httptest.NewRecorder(), req)
+	c.jsonLimits = JSONLimits{MaxDepth: 2}
+	var v any
+	var httpErr *HTTPError
+	require.ErrorAs(t, c.Bind(&v), &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+
+	// Data after the first JSON value is ignored, as with a streaming
+	// decoder.
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{\"a\":1}\n{\"b\":2}"))
+	c = NewContext(httptest.NewRecorder(), req)
+	c.jsonLimits = DefaultJSONLimits
+	var first map[string]any
+	require.NoError(t, c.Bind(&first))
+	assert.Equal(t, map[string]any{"a": float64(1)}, first)
+}
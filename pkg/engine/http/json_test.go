@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type upperEncoder struct{}
+
+func (upperEncoder) Marshal(v any) ([]byte, error) { return []byte(`"CUSTOM"`), nil }
+func (upperEncoder) MarshalIndent(v any, _, _ string) ([]byte, error) {
+	return []byte(`"CUSTOM-PRETTY"`), nil
+}
+
+func serveJSON(router *Router, h HandlerFunc) *httptest.ResponseRecorder {
+	router.Get("/json", h)
+	req := httptest.NewRequest("GET", "/json", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestContextJSON_Config(t *testing.T) {
+	t.Run("compact with charset by default", func(t *testing.T) {
+		router := NewRouter(&config.AstraConfig{}, nil)
+		rec := serveJSON(router, func(c *Context) error {
+			return c.JSON(map[string]int{"a": 1})
+		})
+		assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "{\"a\":1}\n", rec.Body.String())
+	})
+
+	t.Run("pretty in debug", func(t *testing.T) {
+		cfg := &config.AstraConfig{}
+		cfg.App.Debug = true
+		router := NewRouter(cfg, nil)
+		rec := serveJSON(router, func(c *Context) error {
+			return c.JSON(map[string]int{"a": 1})
+		})
+		assert.Equal(t, "{\n  \"a\": 1\n}\n", rec.Body.String())
+	})
+
+	t.Run("custom encoder", func(t *testing.T) {
+		router := NewRouter(&config.AstraConfig{}, nil)
+		router.JSON.Encoder = upperEncoder{}
+		router.JSON.Charset = ""
+		rec := serveJSON(router, func(c *Context) error {
+			return c.JSON("x")
+		})
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "\"CUSTOM\"\n", rec.Body.String())
+	})
+}
+
+func TestStreamJSON(t *testing.T) {
+	seq := func(fail bool) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {
+			for i := 1; i <= 3; i++ {
+				if !yield(i, nil) {
+					return
+				}
+			}
+			if fail {
+				yield(0, errors.New("cursor closed"))
+			}
+		}
+	}
+
+	router := NewRouter(&config.AstraConfig{}, nil)
+	rec := serveJSON(router, func(c *Context) error {
+		return StreamJSON(c, seq(false))
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[1,2,3]\n", rec.Body.String())
+
+	router = NewRouter(&config.AstraConfig{}, nil)
+	rec = serveJSON(router, func(c *Context) error {
+		return StreamJSON(c, seq(true))
+	})
+	assert.Equal(t, "[1,2,3", rec.Body.String())
+}
@@ -4,9 +4,11 @@ import (
 	"math"
 	"net/http"
 	"runtime"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/shauryagautam/Astra/pkg/engine"
 )
 
 type LoadShedConfig struct {
@@ -32,6 +34,8 @@ type LoadShedConfig struct {
 	CriticalLatencyThreshold float64
 	// RetryAfter is the value of the Retry-After header sent on 503s (seconds).
 	RetryAfter int
+	// Priority classifies requests. When nil, every request is normal.
+	Priority PriorityFunc
 }
 
 type Priority string
@@ -43,6 +47,38 @@ const (
 	PriorityAnalytics  Priority = "analytics"
 )
 
+// PriorityFunc returns the priority load shedding gives r. It must decide on
+// the server, from the route or the authenticated user; a header the client
+// sets would let any client mark its requests critical.
+type PriorityFunc func(r *http.Request) Priority
+
+// PathPriorities returns a PriorityFunc giving each request the priority of
+// the longest path prefix it matches, and PriorityNormal when none match:
+//
+//	PathPriorities(map[string]Priority{"/healthz": PriorityCritical, "/api/reports": PriorityBackground})
+func PathPriorities(prefixes map[string]Priority) PriorityFunc {
+	return func(r *http.Request) Priority {
+		priority, longest := PriorityNormal, -1
+		for prefix, p := range prefixes {
+			if len(prefix) > longest && strings.HasPrefix(r.URL.Path, prefix) {
+				priority, longest = p, len(prefix)
+			}
+		}
+		return priority
+	}
+}
+
+// classify returns the priority fn gives r, or PriorityNormal.
+func (fn PriorityFunc) classify(r *http.Request) Priority {
+	if fn == nil {
+		return PriorityNormal
+	}
+	if p := fn(r); p != "" {
+		return p
+	}
+	return PriorityNormal
+}
+
 
 func (c *LoadShedConfig) setDefaults() {
 	procs := int64(runtime.GOMAXPROCS(0))
@@ -96,10 +132,7 @@ func AdaptiveLoadShedding(cfg LoadShedConfig) MiddlewareFunc {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			priority := Priority(r.Header.Get("X-Astra-Priority"))
-			if priority == "" {
-				priority = PriorityNormal
-			}
+			priority := cfg.Priority.classify(r)
 
 			current := state.inflight.Add(1)
 			defer state.inflight.Add(-1)
@@ -185,6 +218,25 @@ func AdaptiveLoadShedding(cfg LoadShedConfig) MiddlewareFunc {
 	}
 }
 
+// MemoryShedding answers 503 with Retry-After (in seconds, default 1) while
+// the watchdog reports the process over its memory threshold. Requests that
+// priority classifies as critical are still served; with a nil priority,
+// none are.
+func MemoryShedding(watchdog *engine.MemoryWatchdog, retryAfter int, priority PriorityFunc) MiddlewareFunc {
+	if retryAfter <= 0 {
+		retryAfter = 1
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if watchdog.Overloaded() && priority.classify(r) != PriorityCritical {
+				shedRequest(w, retryAfter, "memory")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func shedRequest(w http.ResponseWriter, retryAfter int, reason string) {
 	w.Header().Set("Retry-After", itoa(retryAfter))
 	w.Header().Set("X-Astra-Shed-Reason", reason)
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/mail"
+)
+
+// MailPreviewPath is where RegisterMailPreviewRoutes mounts the mail preview.
+const MailPreviewPath = "/___mails"
+
+var mailPreviewIndex = template.Must(template.New("mails").Funcs(template.FuncMap{"join": strings.Join}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>Mail preview</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; display: flex; height: 100vh; }
+nav { width: 360px; overflow-y: auto; border-right: 1px solid #ddd; }
+nav a { display: block; padding: .75rem 1rem; border-bottom: 1px solid #eee; color: inherit; text-decoration: none; }
+nav a:hover { background: #f5f5f5; }
+nav small { color: #777; display: block; }
+iframe { flex: 1; border: 0; }
+p { padding: 1rem; color: #777; }
+</style>
+</head>
+<body>
+<nav>
+{{range .Mails}}<a href="{{$.Path}}/{{.ID}}" target="mail"><strong>{{.Subject}}</strong><small>{{join .To ", "}}</small><small>{{.SentAt.Format "2006-01-02 15:04:05"}}{{with .Attachments}} · {{len .}} attachment(s){{end}}</small></a>
+{{else}}<p>No mail has been sent yet.</p>
+{{end}}
+</nav>
+<iframe name="mail"{{with .Mails}} src="{{$.Path}}/{{(index . 0).ID}}"{{end}}></iframe>
+</body>
+</html>`))
+
+// RegisterMailPreviewRoutes serves the messages captured by a
+// mail.PreviewMailer under /___mails. Only mount it outside production:
+//
+//	GET    /___mails        index page, or JSON with ?format=json
+//	GET    /___mails/{id}   the rendered message, or JSON with ?format=json
+//	DELETE /___mails        delete every captured message
+func RegisterMailPreviewRoutes(r *Router, preview *mail.PreviewMailer) {
+	r.Group(MailPreviewPath, func(r *Router) {
+		r.Get("", func(c *Context) error { return mailPreviewList(c, preview) })
+		r.Get("/{id}", func(c *Context) error { return mailPreviewShow(c, preview) })
+		r.Delete("", func(c *Context) error {
+			if err := preview.Clear(); err != nil {
+				return err
+			}
+			return c.NoContent()
+		})
+	})
+}
+
+func mailPreviewList(c *Context, preview *mail.PreviewMailer) error {
+	mails, err := preview.List()
+	if err != nil {
+		return err
+	}
+	if c.Query("format") == "json" {
+		return c.JSON(mails, http.StatusOK)
+	}
+
+	var body strings.Builder
+	err = mailPreviewIndex.Execute(&body, struct {
+		Path  string
+		Mails []mail.PreviewMail
+	}{MailPreviewPath, mails})
+	if err != nil {
+		return err
+	}
+	return writeMailPreviewHTML(c, body.String(), false)
+}
+
+func mailPreviewShow(c *Context, preview *mail.PreviewMailer) error {
+	m, err := preview.Find(c.Param("id"))
+	if errors.Is(err, mail.ErrPreviewNotFound) {
+		return c.Error(http.StatusNotFound, "mail not found")
+	}
+	if err != nil {
+		return err
+	}
+	if c.Query("format") == "json" {
+		return c.JSON(m, http.StatusOK)
+	}
+	if m.HTML != "" {
+		return writeMailPreviewHTML(c, m.HTML, true)
+	}
+	return writeMailPreviewHTML(c, "<pre>"+template.HTMLEscapeString(m.Text)+"</pre>", true)
+}
+
+// writeMailPreviewHTML writes an HTML page. Mail bodies are untrusted markup,
+// so for those the sandbox CSP keeps their scripts from running on this origin.
+func writeMailPreviewHTML(c *Context, body string, untrusted bool) error {
+	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if untrusted {
+		c.Writer.Header().Set("Content-Security-Policy", "sandbox allow-popups allow-popups-to-escape-sandbox")
+	}
+	c.Writer.WriteHeader(http.StatusOK)
+	c.written = true
+	_, err := c.Writer.Write([]byte(body))
+	return err
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/json"
+	"github.com/shauryagautam/Astra/pkg/mail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailPreviewRoutes(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+	preview := mail.NewPreviewMailer(t.TempDir())
+	RegisterMailPreviewRoutes(router, preview)
+
+	require.NoError(t, preview.Send(context.Background(), &mail.Message{
+		To: []string{"a@example.com"}, Subject: "Welcome <b>", HTML: "<h1>Hi</h1><script>alert(1)</script>",
+	}))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/___mails")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Welcome &lt;b&gt;")
+
+	rec = get("/___mails?format=json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var mails []mail.PreviewMail
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &mails))
+	require.Len(t, mails, 1)
+
+	rec = get("/___mails/" + mails[0].ID)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>Hi</h1><script>alert(1)</script>", rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Security-Policy"), "sandbox")
+
+	assert.Equal(t, http.StatusNotFound, get("/___mails/missing").Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/___mails", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	all, err := preview.List()
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
@@ -3,10 +3,14 @@ package http
 import (
 	"net/http"
 
+	"github.com/gorilla/websocket"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
 	"github.com/shauryagautam/Astra/pkg/identity/auth"
 )
 
 // Auth returns a standard middleware that protects routes using the provided guard.
+// WebSocket handshakes cannot carry custom headers from browsers, so for
+// upgrade requests a "token" query parameter is accepted as the bearer token.
 func Auth(guard auth.Guard) MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -17,11 +21,19 @@ func Auth(guard auth.Guard) MiddlewareFunc {
 				return
 			}
 
+			if websocket.IsWebSocketUpgrade(r) && r.Header.Get("Authorization") == "" {
+				if token := r.URL.Query().Get("token"); token != "" {
+					c.Request.Header.Set("Authorization", "Bearer "+token)
+				}
+			}
+
 			if err := guard.Attempt(c); err != nil {
-				c.UnauthorizedError(err.Error())
+				_ = c.ErrorWithDetails(http.StatusUnauthorized, string(astraerrors.ErrCodeInvalidAuthToken), err.Error(), nil)
 				return
 			}
-			next.ServeHTTP(w, r)
+			// The guard stores the user on c.Request's context; pass that
+			// request on so handlers can see it.
+			next.ServeHTTP(w, c.Request)
 		})
 	}
 }
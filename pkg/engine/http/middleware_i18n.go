@@ -1,8 +1,10 @@
 package http
 
 import (
-	"context"
+	"cmp"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -11,50 +13,192 @@ const (
 	CookieLocaleKey  = "astra_locale"
 )
 
-// LocaleMiddleware detects the user locale and stores it in the request context.
-func LocaleMiddleware(fallback string) MiddlewareFunc {
+// LocaleConfig configures the Locales middleware.
+type LocaleConfig struct {
+	// Supported lists the locales the application serves, such as "en",
+	// "fr" or "pt-BR"; i18n.Engine.Locales returns the loaded ones. Requests
+	// for other locales fall back to the closest supported one, then to
+	// Default. When empty, any well-formed language tag is accepted.
+	Supported []string
+	// Default is used when the request names no usable locale (default: the
+	// first Supported locale, else "en").
+	Default string
+	// Param is the route wildcard holding the locale, as in /{locale}/docs
+	// (default: "locale"). When Supported is set, a first path segment that
+	// is a supported locale, as in /fr/docs, counts as well.
+	Param string
+	// Query is the query parameter that selects a locale (default: "lang").
+	Query string
+	// Cookie is the cookie that remembers a locale (default: CookieLocaleKey).
+	Cookie string
+
+	// baseOnly reduces accepted tags to their base language when Supported
+	// is empty, as LocaleMiddleware always has.
+	baseOnly bool
+}
+
+// Locales resolves the request's locale from, in order, the route prefix,
+// the query, the cookie and the Accept-Language header. It stores the
+// locale for Context.Locale, which Context.T, the T and locale template
+// functions and validation messages use, and sets Content-Language.
+//
+//	router.Use(http.Locales(http.LocaleConfig{Supported: translator.Locales()}))
+func Locales(cfg LocaleConfig) MiddlewareFunc {
+	if cfg.Default == "" {
+		cfg.Default = "en"
+		if len(cfg.Supported) > 0 {
+			cfg.Default = cfg.Supported[0]
+		}
+	}
+	if cfg.Param == "" {
+		cfg.Param = "locale"
+	}
+	if cfg.Query == "" {
+		cfg.Query = "lang"
+	}
+	if cfg.Cookie == "" {
+		cfg.Cookie = CookieLocaleKey
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			locale := ""
-
-			// 1. Query parameter
-			if q := r.URL.Query().Get("lang"); q != "" {
-				locale = q
+			locale, negotiated := cfg.resolve(r)
+			w.Header().Set("Content-Language", locale)
+			if negotiated {
+				w.Header().Add("Vary", "Accept-Language")
 			}
 
-			// 2. Cookie
-			if locale == "" {
-				if cookie, err := r.Cookie(CookieLocaleKey); err == nil {
-					locale = cookie.Value
-				}
+			if c := FromRequest(r); c != nil {
+				c.Set(ContextLocaleKey, locale)
 			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			// 3. Header Accept-Language
-			if locale == "" {
-				accept := r.Header.Get("Accept-Language")
-				if accept != "" {
-					parts := strings.Split(accept, ",")
-					if len(parts) > 0 {
-						lang := strings.TrimSpace(parts[0])
-						if dash := strings.Index(lang, "-"); dash != -1 {
-							locale = lang[:dash]
-						} else if semi := strings.Index(lang, ";"); semi != -1 {
-							locale = lang[:semi]
-						} else {
-							locale = lang
-						}
-					}
-				}
+// LocaleMiddleware resolves the locale with fallback as the default and no
+// restriction on the locales accepted, reduced to their base language, so
+// "fr-FR" becomes "fr". Prefer Locales with Supported set.
+func LocaleMiddleware(fallback string) MiddlewareFunc {
+	return Locales(LocaleConfig{Default: fallback, baseOnly: true})
+}
+
+// resolve returns the request's locale and whether it came from
+// Accept-Language, in which case the response varies on that header.
+func (cfg LocaleConfig) resolve(r *http.Request) (string, bool) {
+	if locale, ok := cfg.match(r.PathValue(cfg.Param)); ok {
+		return locale, false
+	}
+	if len(cfg.Supported) > 0 {
+		first, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if locale, ok := cfg.match(first); ok {
+			return locale, false
+		}
+	}
+	if locale, ok := cfg.match(r.URL.Query().Get(cfg.Query)); ok {
+		return locale, false
+	}
+	if cookie, err := r.Cookie(cfg.Cookie); err == nil {
+		if locale, ok := cfg.match(cookie.Value); ok {
+			return locale, false
+		}
+	}
+	if accept := r.Header.Get("Accept-Language"); accept != "" {
+		for _, tag := range acceptLanguages(accept) {
+			if locale, ok := cfg.match(tag); ok {
+				return locale, true
 			}
+		}
+		return cfg.Default, true
+	}
+	return cfg.Default, false
+}
+
+// match maps a requested language tag to a supported locale: the same tag,
+// else one with the same base language, so "fr-CA" finds "fr" and "pt" finds
+// "pt-BR".
+func (cfg LocaleConfig) match(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if !isLanguageTag(tag) {
+		return "", false
+	}
+	if len(cfg.Supported) == 0 {
+		if cfg.baseOnly {
+			tag, _, _ = strings.Cut(tag, "-")
+		}
+		return tag, true
+	}
+	for _, s := range cfg.Supported {
+		if strings.EqualFold(s, tag) {
+			return s, true
+		}
+	}
+	base, _, _ := strings.Cut(tag, "-")
+	for _, s := range cfg.Supported {
+		if strings.EqualFold(s, base) {
+			return s, true
+		}
+	}
+	for _, s := range cfg.Supported {
+		if sBase, _, _ := strings.Cut(s, "-"); strings.EqualFold(sBase, base) {
+			return s, true
+		}
+	}
+	return "", false
+}
 
-			// Default fallback
-			if locale == "" {
-				locale = fallback
+// acceptLanguages returns the tags in an Accept-Language header, most
+// preferred first, leaving out the wildcard and refused (q=0) ones.
+func acceptLanguages(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
 			}
+		}
+		if tag == "" || tag == "*" || q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	slices.SortStableFunc(tags, func(a, b weighted) int { return cmp.Compare(b.q, a.q) })
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.tag
+	}
+	return out
+}
 
-			// Store in request context for framework Context to pick up
-			ctx := context.WithValue(r.Context(), ContextLocaleKey, locale)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+// isLanguageTag reports whether s looks like a BCP 47 tag, so that
+// arbitrary input never reaches headers or templates as a locale.
+func isLanguageTag(s string) bool {
+	if s == "" || len(s) > 35 {
+		return false
 	}
+	for i, part := range strings.Split(s, "-") {
+		if part == "" || len(part) > 8 || (i == 0 && len(part) < 2) {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SetLocale changes the request's locale, for example to a signed-in user's
+// preference, and updates Content-Language to match.
+func (c *Context) SetLocale(locale string) {
+	c.Set(ContextLocaleKey, locale)
+	c.Writer.Header().Set("Content-Language", locale)
 }
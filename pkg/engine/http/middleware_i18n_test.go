@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubTranslator map[string]string
+
+func (s stubTranslator) T(locale, key string, _ ...any) string {
+	if v, ok := s[locale+"."+key]; ok {
+		return v
+	}
+	return key
+}
+
+func TestLocales(t *testing.T) {
+	router := NewRouter(nil, nil)
+	router.Use(Locales(LocaleConfig{Supported: []string{"en", "fr", "pt-BR"}}))
+	handler := func(c *Context) error {
+		c.Translator = stubTranslator{"fr.hello": "bonjour", "en.hello": "hello"}
+		return c.SendString(c.Locale() + " " + c.T("hello"))
+	}
+	router.Get("/docs", handler)
+	router.Get("/fr/docs", handler)
+	router.Get("/{locale}/guide", handler)
+
+	cases := []struct {
+		name, path, cookie, accept string
+		want                       string
+		vary                       bool
+	}{
+		{name: "default", path: "/docs", want: "en hello"},
+		{name: "route prefix", path: "/fr/docs", accept: "en", want: "fr bonjour"},
+		{name: "route wildcard", path: "/pt-br/guide", want: "pt-BR hello"},
+		{name: "unsupported wildcard", path: "/xx/guide", want: "en hello"},
+		{name: "query", path: "/docs?lang=fr", accept: "en", want: "fr bonjour"},
+		{name: "cookie", path: "/docs", cookie: "fr", accept: "en", want: "fr bonjour"},
+		{name: "accept-language", path: "/docs", accept: "de;q=0.9, fr-CA;q=0.8, en;q=0.1", want: "fr bonjour", vary: true},
+		{name: "base language", path: "/docs", accept: "pt", want: "pt-BR hello", vary: true},
+		{name: "no match", path: "/docs", accept: "de", want: "en hello", vary: true},
+		{name: "garbage", path: "/docs?lang=<script>", want: "en hello"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: CookieLocaleKey, Value: tc.cookie})
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept-Language", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.want, rec.Body.String())
+			locale, _, _ := strings.Cut(tc.want, " ")
+			assert.Equal(t, locale, rec.Header().Get("Content-Language"))
+			assert.Equal(t, tc.vary, rec.Header().Get("Vary") == "Accept-Language")
+		})
+	}
+}
+
+func TestContext_SetLocale(t *testing.T) {
+	router := NewRouter(nil, nil)
+	router.Use(LocaleMiddleware("en"))
+	router.Get("/", func(c *Context) error {
+		c.SetLocale("de")
+		return c.SendString(c.Locale())
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "de", rec.Body.String())
+	assert.Equal(t, "de", rec.Header().Get("Content-Language"))
+}
+
+func TestLocaleMiddleware_BaseLanguage(t *testing.T) {
+	router := NewRouter(nil, nil)
+	router.Use(LocaleMiddleware("en"))
+	router.Get("/", func(c *Context) error { return c.SendString(c.Locale()) })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, "fr", rec.Body.String())
+}
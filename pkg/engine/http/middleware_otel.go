@@ -1,27 +1,128 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/shauryagautam/Astra/pkg/observability/trace"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	stdtrace "go.opentelemetry.io/otel/trace"
 )
 
+// TenantIDAttribute is the span attribute holding the tenant of a request,
+// read from the "tenant_id" context value set by TenantMiddleware.
+const TenantIDAttribute = attribute.Key("tenant.id")
+
 // OpenTelemetry returns a middleware that injects OTEL tracing into the request.
+// Spans are named after the route template ("GET /users/{id}"), not the raw
+// path, and carry the authenticated user ID, the tenant ID and the response
+// status. Responses with a 5xx status mark the span as failed; the
+// InteractiveErrorHandler also records the error itself.
 func OpenTelemetry() MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 
+			route := routeTemplate(r)
 			tracer := trace.GetTracer()
-			ctx, span := tracer.Start(ctx, r.Method+" "+r.URL.Path,
+			ctx, span := tracer.Start(ctx, r.Method+" "+route,
 				stdtrace.WithSpanKind(stdtrace.SpanKindServer),
+				stdtrace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(r.URL.Path),
+				),
 			)
 			defer span.End()
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+			c := FromRequest(r)
+			req := r.WithContext(ctx)
+			if c == nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+			// Middleware such as Auth passes c.Request on; keep the span in it.
+			c.Request = req
+			next.ServeHTTP(w, req)
+
+			if user := c.AuthUser(); user != nil && user.UserID != "" {
+				span.SetAttributes(semconv.EnduserID(user.UserID))
+			}
+			if tenant := c.Get("tenant_id"); tenant != nil {
+				span.SetAttributes(TenantIDAttribute.String(fmt.Sprint(tenant)))
+			}
+			if status := c.resp.status; status != 0 {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+				if status >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(status))
+				}
+			}
+		})
+	}
+}
+
+// Baggage returns a middleware that adds key/value members to the OTEL
+// baggage of the request and records them on its span, so a route can tag
+// its traces and the outgoing calls that propagate the context:
+//
+//	router.With(http.Baggage("feature", "checkout")).Post("/orders", orders.Store)
+//
+// Members that are not valid baggage are skipped.
+func Baggage(pairs ...string) MiddlewareFunc {
+	var members []baggage.Member
+	var attrs []attribute.KeyValue
+	for i := 0; i+1 < len(pairs); i += 2 {
+		m, err := baggage.NewMemberRaw(pairs[i], pairs[i+1])
+		if err != nil {
+			continue
+		}
+		members = append(members, m)
+		attrs = append(attrs, attribute.String(pairs[i], pairs[i+1]))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			b := baggage.FromContext(ctx)
+			for _, m := range members {
+				if nb, err := b.SetMember(m); err == nil {
+					b = nb
+				}
+			}
+			stdtrace.SpanFromContext(ctx).SetAttributes(attrs...)
+			next.ServeHTTP(w, r.WithContext(baggage.ContextWithBaggage(ctx, b)))
 		})
 	}
 }
+
+// recordSpanError records err on the request's span, and marks the span
+// as failed for server errors.
+func recordSpanError(c *Context, err error, status int) {
+	span := stdtrace.SpanFromContext(c.Request.Context())
+	if !span.IsRecording() {
+		return
+	}
+	span.RecordError(err)
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// routeTemplate returns the path template of the route that matched r, in
+// the router's syntax, or the raw path when no route matched.
+func routeTemplate(r *http.Request) string {
+	if r.Pattern == "" {
+		return r.URL.Path
+	}
+	pattern := r.Pattern
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	return strings.ReplaceAll(pattern, "{_wildcard...}", "*")
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOpenTelemetry_RouteUserAndTenantAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.ErrorHandler = NewInteractiveErrorHandler(nil, nil, nil).Handle
+	router.Use(OpenTelemetry())
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := FromRequest(r)
+			c.SetAuthUser(&identityclaims.AuthClaims{UserID: "u-7"})
+			c.Set("tenant_id", 42)
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	var feature string
+	router.With(Baggage("feature", "checkout")).Get("/teams/{id}/orders", func(c *Context) error {
+		feature = baggage.FromContext(c.Ctx()).Member("feature").Value()
+		return c.NoContent()
+	})
+	router.Get("/boom", func(c *Context) error { return errors.New("db down") })
+
+	for _, path := range []string{"/teams/9/orders", "/boom"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "checkout", feature)
+
+	ok := spans[0]
+	assert.Equal(t, "GET /teams/{id}/orders", ok.Name())
+	attrs := attribute.NewSet(ok.Attributes()...)
+	for key, want := range map[attribute.Key]string{
+		"http.route": "/teams/{id}/orders",
+		"url.path":   "/teams/9/orders",
+		"enduser.id": "u-7",
+		"tenant.id":  "42",
+		"feature":    "checkout",
+	} {
+		got, found := attrs.Value(key)
+		assert.True(t, found, key)
+		assert.Equal(t, want, got.AsString(), key)
+	}
+	status, _ := attrs.Value("http.response.status_code")
+	assert.Equal(t, int64(http.StatusNoContent), status.AsInt64())
+	assert.Equal(t, codes.Unset, ok.Status().Code)
+
+	failed := spans[1]
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	require.Len(t, failed.Events(), 1)
+	assert.Equal(t, "exception", failed.Events()[0].Name)
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	stdhttp "net/http"
+	"time"
 
 	"github.com/shauryagautam/Astra/pkg/session"
 )
@@ -10,9 +11,40 @@ import (
 // SessionContextKey is the key used to store the session in the Astra context.
 const SessionContextKey = "astra.session"
 
+// SessionConfig controls how long sessions may live.
+type SessionConfig struct {
+	// IdleTimeout ends a session that has made no request for this long.
+	// Zero disables the check.
+	IdleTimeout time.Duration
+	// AbsoluteTimeout ends a session this long after it started, however
+	// active it is. Zero disables the check.
+	AbsoluteTimeout time.Duration
+}
+
+// WithSessionIdleTimeout sets SessionConfig.IdleTimeout.
+func WithSessionIdleTimeout(d time.Duration) func(*SessionConfig) {
+	return func(c *SessionConfig) {
+		c.IdleTimeout = d
+	}
+}
+
+// WithSessionAbsoluteTimeout sets SessionConfig.AbsoluteTimeout.
+func WithSessionAbsoluteTimeout(d time.Duration) func(*SessionConfig) {
+	return func(c *SessionConfig) {
+		c.AbsoluteTimeout = d
+	}
+}
+
 // SessionMiddleware returns a standard middleware that loads the session from the
 // request and stores it in the request context.
-func SessionMiddleware(store session.Store) MiddlewareFunc {
+//
+// A session past its idle or absolute timeout is logged out and continues as
+// a new, empty session under a new ID; handlers see it already cleared.
+func SessionMiddleware(store session.Store, opts ...func(*SessionConfig)) MiddlewareFunc {
+	cfg := SessionConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	return func(next stdhttp.Handler) stdhttp.Handler {
 		return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 			sess, err := store.Load(r)
@@ -23,6 +55,11 @@ func SessionMiddleware(store session.Store) MiddlewareFunc {
 				return
 			}
 
+			sess.CheckLifetime(cfg.IdleTimeout, cfg.AbsoluteTimeout)
+
+			// Form data flashed by the previous request becomes readable now.
+			sess.AgeFlash()
+
 			// Inject into request context for Astra Context to pick up
 			ctx := context.WithValue(r.Context(), SessionContextKey, sess)
 			r = r.WithContext(ctx)
@@ -34,6 +71,13 @@ func SessionMiddleware(store session.Store) MiddlewareFunc {
 				saved:          false,
 			}
 
+			// Handlers write through their Context, which wraps the original
+			// writer rather than sw, so save from its BeforeSend hook too.
+			if c := FromRequest(r); c != nil {
+				sess.SetClientIP(c.ClientIP())
+				c.BeforeSend(func(c *Context) { sw.saveOnce(c.Writer) })
+			}
+
 			next.ServeHTTP(sw, r)
 
 			// Final save if headers weren't already written.
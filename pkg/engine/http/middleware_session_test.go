@@ -0,0 +1,52 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionMiddleware_LoginAndLifetime(t *testing.T) {
+	store := session.NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+	newRouter := func(opts ...func(*SessionConfig)) *Router {
+		router := NewRouter(&config.AstraConfig{}, slog.Default())
+		router.Use(SessionMiddleware(store, opts...))
+		router.Post("/login", func(c *Context) error {
+			c.Session().Login("42")
+			return c.SendString("ok")
+		})
+		router.Get("/me", func(c *Context) error {
+			return c.SendString(c.Session().UserID())
+		})
+		return router
+	}
+	serve := func(router *Router, method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	router := newRouter(WithSessionIdleTimeout(time.Hour))
+	login := serve(router, "POST", "/login", nil)
+	cookies := login.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "42", serve(router, "GET", "/me", cookies[0]).Body.String())
+
+	// Past the absolute timeout, the handler sees a logged-out session.
+	expired := newRouter(WithSessionAbsoluteTimeout(time.Nanosecond))
+	rec := serve(expired, "GET", "/me", cookies[0])
+	assert.Empty(t, rec.Body.String())
+	require.NotEmpty(t, rec.Result().Cookies())
+	assert.NotEqual(t, cookies[0].Value, rec.Result().Cookies()[0].Value)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/shauryagautam/Astra/pkg/engine"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -117,3 +118,31 @@ func TestCSRF(t *testing.T) {
 		assert.Equal(t, http.StatusForbidden, w.Code)
 	})
 }
+
+func TestMemoryShedding(t *testing.T) {
+	used := uint64(0)
+	watchdog := engine.NewMemoryWatchdog(engine.MemoryWatchdogConfig{
+		Threshold: 100,
+		Read:      func() uint64 { return used },
+	})
+	priority := PathPriorities(map[string]Priority{"/healthz": PriorityCritical})
+	handler := MemoryShedding(watchdog, 5, priority)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Astra-Priority", string(PriorityCritical))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/").Code)
+
+	used = 150
+	watchdog.Check()
+	w := serve("/")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "the client's priority header is ignored")
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("/healthz").Code)
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// MiddlewareTraceHeader lists the middleware a traced request went through,
+// outermost first, as "name;dur=<ms>" items. The middleware that answered
+// without calling next is marked ";stop".
+const MiddlewareTraceHeader = "X-Astra-Middleware"
+
+// MiddlewareStep is one middleware a traced request entered.
+type MiddlewareStep struct {
+	Name string
+	// Duration is the time spent in the middleware, including everything
+	// it called. It is measured up to the moment the response headers were
+	// sent for MiddlewareTraceHeader, and in full afterwards.
+	Duration time.Duration
+	// CalledNext reports whether the middleware passed the request on.
+	CalledNext bool
+
+	start time.Time
+}
+
+// MiddlewareTrace returns the middleware the request has entered so far,
+// outermost first, or nil when the router does not trace middleware (see
+// Router.TraceMiddleware). The last step with CalledNext false is where the
+// chain stopped.
+func (c *Context) MiddlewareTrace() []MiddlewareStep {
+	if c.trace == nil {
+		return nil
+	}
+	return append([]MiddlewareStep(nil), c.trace...)
+}
+
+// traceMiddleware wraps mw, the middleware at pos in a route's chain, so it
+// records itself in the request's trace.
+func traceMiddleware(pos int, mw MiddlewareFunc, inner http.Handler) http.Handler {
+	name := middlewareName(mw)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if c := FromRequest(req); c != nil && len(c.trace) > pos {
+			c.trace[pos].CalledNext = true
+		}
+		inner.ServeHTTP(w, req)
+	}))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c := FromRequest(req)
+		if c == nil {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if pos == 0 {
+			c.BeforeSend(writeMiddlewareTrace)
+		}
+		// A middleware calling next twice re-enters the rest of the chain.
+		c.trace = append(c.trace[:min(pos, len(c.trace))], MiddlewareStep{Name: name, start: time.Now()})
+		h.ServeHTTP(w, req)
+		if len(c.trace) > pos {
+			c.trace[pos].Duration = time.Since(c.trace[pos].start)
+		}
+	})
+}
+
+func writeMiddlewareTrace(c *Context) {
+	items := make([]string, len(c.trace))
+	for i, step := range c.trace {
+		items[i] = fmt.Sprintf("%s;dur=%.2f", step.Name, float64(time.Since(step.start).Microseconds())/1000)
+		if i == len(c.trace)-1 && !step.CalledNext {
+			items[i] += ";stop"
+		}
+	}
+	c.Writer.Header().Set(MiddlewareTraceHeader, strings.Join(items, ", "))
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// middlewareName turns the function behind mw into a short name such as
+// "http.SecureHeaders" for the middleware returned by SecureHeaders.
+func middlewareName(mw MiddlewareFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer())
+	if fn == nil {
+		return "middleware"
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return closureSuffix.ReplaceAllString(name, "")
+}
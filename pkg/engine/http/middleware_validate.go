@@ -1,10 +1,14 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 
 	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/validate"
 )
 
 // ValidateMiddleware handles request validation by injecting the validator service.
@@ -29,3 +33,72 @@ func (c *Context) BindAndValidate(v any) error {
 func (c *Context) Validate(v any) error {
 	return c.BindAndValidate(v)
 }
+
+// validatedKey is the request context key ValidateRequest stores input under.
+type validatedKey struct{}
+
+// ValidateRequest decodes the JSON body into a new T, validates it with v
+// and makes it available to the handler through Validated. Invalid input is
+// answered with 422 and the standard error envelope, field messages under
+// details.fields; a malformed body, or one over the router's JSONLimits,
+// gets 400, and a body over JSONLimits.MaxBytes 413. The handler is not
+// called in any of these cases:
+//
+//	type CreateUserInput struct {
+//		Email string `json:"email" validate:"required,email"`
+//		Name  string `json:"name" validate:"required,max=100"`
+//	}
+//
+//	r.With(http.ValidateRequest[CreateUserInput](validator)).Post("/users", func(c *http.Context) error {
+//		input := http.Validated[CreateUserInput](c)
+//		// ...
+//	})
+//
+// A nil v uses validate.New(). An empty body validates the zero T, so
+// required fields are still reported.
+func ValidateRequest[T any](v *validate.Validator) MiddlewareFunc {
+	if v == nil {
+		v = validate.New()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := FromRequest(r)
+			if c == nil {
+				c = NewContext(w, r)
+				defer c.release()
+			}
+			c.Request = r
+
+			input := new(T)
+			if err := c.Bind(input); err != nil && !errors.Is(err, io.EOF) {
+				var limitErr *HTTPError
+				if errors.As(err, &limitErr) {
+					_ = c.rejectBody(limitErr)
+					return
+				}
+				_ = c.BadRequestError("request body is not valid JSON")
+				return
+			}
+
+			if err := v.ValidateStruct(input, c.Locale()); err != nil {
+				var ve *validate.ValidationErrors
+				if !errors.As(err, &ve) {
+					_ = c.InternalError("")
+					return
+				}
+				_ = c.ErrorWithDetails(http.StatusUnprocessableEntity, ErrCodeValidation,
+					"the given data was invalid", map[string]any{"fields": ve.Fields})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), validatedKey{}, input)))
+		})
+	}
+}
+
+// Validated returns the input ValidateRequest[T] validated for this request,
+// or nil when the route is not guarded by ValidateRequest[T].
+func Validated[T any](c *Context) *T {
+	input, _ := c.Get(validatedKey{}).(*T)
+	return input
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createUserInput struct {
+	Email string `json:"email" validate:"required,email"`
+	Name  string `json:"name" validate:"required,max=10"`
+}
+
+func TestValidateRequest(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+
+	var got *createUserInput
+	router.With(ValidateRequest[createUserInput](nil)).Post("/users", func(c *Context) error {
+		got = Validated[createUserInput](c)
+		return c.NoContent()
+	})
+	router.Post("/plain", func(c *Context) error {
+		assert.Nil(t, Validated[createUserInput](c))
+		return c.NoContent()
+	})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post("/users", `{"email":"ada@example.com","name":"Ada"}`)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	require.NotNil(t, got)
+	assert.Equal(t, createUserInput{Email: "ada@example.com", Name: "Ada"}, *got)
+
+	got = nil
+	rec = post("/users", `{"email":"nope","name":""}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Nil(t, got)
+	assert.JSONEq(t, `{"error":{"code":"VALIDATION_ERROR","message":"the given data was invalid","details":{"fields":{
+		"email":["email must be a valid email address"],
+		"name":["name is required"]}}}}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/users", "").Code)
+	assert.Equal(t, http.StatusBadRequest, post("/users", "{").Code)
+	assert.Equal(t, http.StatusNoContent, post("/plain", "").Code, "With does not leak middleware onto the parent router")
+}
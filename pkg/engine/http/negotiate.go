@@ -0,0 +1,63 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Accepts returns the offer the client's Accept header ranks highest, or ""
+// when it accepts none of them. Ties go to the earlier offer, and a request
+// without an Accept header accepts anything.
+//
+//	switch c.Accepts("application/json", "text/html") {
+//	case "text/html":
+//		return c.Render("users/show", data)
+//	default:
+//		return c.JSON(user)
+//	}
+func (c *Context) Accepts(offers ...string) string {
+	accept := c.Request.Header.Get("Accept")
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the q-value accept gives mediaType, taken from the
+// most specific matching range: "text/html" beats "text/*" beats "*/*".
+func acceptQuality(accept, mediaType string) float64 {
+	if strings.TrimSpace(accept) == "" {
+		return 1
+	}
+	typ, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, 0
+	for _, part := range strings.Split(accept, ",") {
+		rng, params, _ := strings.Cut(part, ";")
+		rng = strings.ToLower(strings.TrimSpace(rng))
+
+		s := 0
+		switch {
+		case rng == mediaType:
+			s = 3
+		case rng == typ+"/*":
+			s = 2
+		case rng == "*/*":
+			s = 1
+		}
+		if s <= specificity {
+			continue
+		}
+		specificity, q = s, 1
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+	}
+	return q
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestContext_Accepts(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"text/html", "text/html"},
+		{"text/html;q=0.5, application/json", "application/json"},
+		{"text/*;q=0.9, */*;q=0.1", "text/html"},
+		{"image/png", ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", tc.accept)
+		c := NewContext(httptest.NewRecorder(), req)
+		assert.Equal(t, tc.want, c.Accepts("application/json", "text/html"), tc.accept)
+	}
+}
+
+type negotiateReport struct {
+	XMLName xml.Name `xml:"report" json:"-" msgpack:"-"`
+	Total   int      `xml:"total" json:"total" msgpack:"total"`
+}
+
+func TestContext_Negotiate(t *testing.T) {
+	router := NewRouter(nil, nil)
+	router.RegisterSerializer(NewSerializer("application/vnd.api+json", func(v any) ([]byte, error) {
+		return []byte(`{"data":{"total":3}}`), nil
+	}))
+	router.Get("/report", func(c *Context) error {
+		return c.Negotiate(negotiateReport{Total: 3}, 201)
+	})
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/report", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("")
+	assert.Equal(t, 201, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"total":3}`, rec.Body.String())
+	assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+	rec = get("application/xml, application/json;q=0.5")
+	assert.Equal(t, "application/xml", rec.Header().Get("Content-Type"))
+	assert.Equal(t, xml.Header+"<report><total>3</total></report>", rec.Body.String())
+
+	rec = get("application/msgpack")
+	var decoded map[string]any
+	require.NoError(t, msgpack.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.EqualValues(t, 3, decoded["total"])
+
+	rec = get("application/vnd.api+json")
+	assert.Equal(t, "application/vnd.api+json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"data":{"total":3}}`, rec.Body.String())
+
+	req := httptest.NewRequest("GET", "/report", nil)
+	req.Header.Set("Accept", "image/png")
+	rec = httptest.NewRecorder()
+	err := NewContext(rec, req).Negotiate(negotiateReport{})
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, 406, httpErr.Status)
+	assert.Zero(t, rec.Body.Len())
+}
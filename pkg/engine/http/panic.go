@@ -0,0 +1,91 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// PanicError is the error a handler panic becomes when the router has an
+// ErrorHandler. It keeps the stack of the goroutine that panicked, so error
+// pages and logs can point at the line that did.
+type PanicError struct {
+	Value any
+	pcs   []uintptr
+}
+
+// newPanicError captures the stack; call it from the deferred function that
+// recovered v.
+func newPanicError(v any) *PanicError {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	return &PanicError{Value: v, pcs: pcs[:n]}
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Frames returns the stack at the panic, innermost first, starting at the
+// frame that called panic.
+func (e *PanicError) Frames() []runtime.Frame {
+	frames := callerFrames(e.pcs)
+	// Drop the recovering function and the runtime's panic machinery above
+	// the panic site.
+	start := 0
+	for i, f := range frames {
+		if strings.HasPrefix(f.Function, "runtime.") {
+			start = i + 1
+		} else if start > 0 {
+			break
+		}
+	}
+	return frames[start:]
+}
+
+// Stack formats Frames like a goroutine trace.
+func (e *PanicError) Stack() string { return formatFrames(e.Frames()) }
+
+func callerFrames(pcs []uintptr) []runtime.Frame {
+	var out []runtime.Frame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		out = append(out, f)
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+func formatFrames(frames []runtime.Frame) string {
+	var b strings.Builder
+	for _, f := range frames {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// recoverToError turns a panic in a handler into a *PanicError for
+// ErrorHandler. http.ErrAbortHandler is re-panicked, as net/http expects.
+func (r *Router) recoverToError(c *Context) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+	err := newPanicError(v)
+	c.err = err
+	r.logger().Error("handler panic", "panic", v, "path", c.Request.URL.Path, "stack", err.Stack())
+	if !c.written {
+		r.ErrorHandler(c, err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	goredis "github.com/redis/go-redis/v9"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
 )
 
 var requestSequence uint64
@@ -60,12 +61,15 @@ return {allowed, remaining, resetAt}
 `)
 
 // TokenBucketScript is the Lua script used for token bucket rate limiting in Redis.
+// The bucket holds ARGV[1] tokens and refills ARGV[5] tokens per window,
+// ARGV[1] when omitted, so a capacity above the rate allows bursts.
 var TokenBucketScript = goredis.NewScript(`
 local key = KEYS[1]
 local capacity = tonumber(ARGV[1])
 local window = tonumber(ARGV[2])
 local now = tonumber(ARGV[3])
 local requested = tonumber(ARGV[4])
+local rate = tonumber(ARGV[5]) or capacity
 
 local state = redis.call("HMGET", key, "tokens", "last_refill")
 local tokens = tonumber(state[1])
@@ -76,7 +80,7 @@ if not tokens then
     last_refill = now
 else
     local elapsed = math.max(0, now - last_refill)
-    local refill = elapsed * (capacity / window)
+    local refill = elapsed * (rate / window)
     tokens = math.min(capacity, tokens + refill)
     last_refill = now
 end
@@ -88,22 +92,54 @@ if tokens >= requested then
 end
 
 redis.call("HMSET", key, "tokens", tokens, "last_refill", last_refill)
-redis.call("PEXPIRE", key, window)
+redis.call("PEXPIRE", key, math.max(window, math.ceil(capacity * window / rate)))
 
 local resetAt = now + window
 if tokens < capacity then
-	local time_to_fill = ((capacity - tokens) / (capacity / window))
+	local time_to_fill = ((capacity - tokens) / (rate / window))
 	resetAt = now + math.ceil(time_to_fill)
 end
 
 return {allowed, math.floor(tokens), resetAt}
 `)
 
-// RateLimitCheck performs a manual rate limit check against Redis.
-func RateLimitCheck(ctx context.Context, client goredis.UniversalClient, key string, limit int, window time.Duration, algo RateLimitAlgorithm) (bool, int64, int64, error) {
+// RateLimitResult is the outcome of one rate limit check: everything the
+// X-RateLimit-* and Retry-After headers need, read in a single Redis call.
+type RateLimitResult struct {
+	// Allowed reports whether the request fit within the limit.
+	Allowed bool
+	// Limit is the most requests the bucket holds.
+	Limit int
+	// Remaining is how many more requests are allowed right now.
+	Remaining int64
+	// ResetAt is when the window clears, or when the token bucket is full
+	// again.
+	ResetAt time.Time
+	// RetryAfter is how long a refused client must wait before its next
+	// request can be allowed. It is 0 when Allowed.
+	RetryAfter time.Duration
+}
+
+// SetHeaders writes X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset, in Unix seconds, to h.
+func (res RateLimitResult) SetHeaders(h http.Header) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(res.ResetAt.Unix(), 10))
+}
+
+// RateLimitAttempt counts one request against the bucket at key and
+// returns the result, with the remaining requests and reset time from the
+// same script run:
+//
+//	res, err := http.RateLimitAttempt(ctx, rdb, "export:"+userID, 5, time.Hour, http.SlidingWindow)
+//	if err == nil && !res.Allowed {
+//		return fmt.Errorf("try again in %s", res.RetryAfter.Round(time.Second))
+//	}
+func RateLimitAttempt(ctx context.Context, client goredis.UniversalClient, key string, limit int, window time.Duration, algo RateLimitAlgorithm) (RateLimitResult, error) {
 	now := time.Now()
 
-	var result interface{}
+	var result []interface{}
 	var err error
 
 	if algo == TokenBucket {
@@ -130,10 +166,40 @@ func RateLimitCheck(ctx context.Context, client goredis.UniversalClient, key str
 		).Slice()
 	}
 	if err != nil {
-		return false, 0, 0, fmt.Errorf("astra/rate_limit: %w", err)
+		return RateLimitResult{}, fmt.Errorf("astra/rate_limit: %w", err)
 	}
 
-	return ParseRateLimitResult(result.([]interface{}))
+	allowed, remaining, resetAt, err := ParseRateLimitResult(result)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	res := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   time.UnixMilli(resetAt),
+	}
+	if !allowed {
+		if algo == TokenBucket {
+			// The bucket is empty; the next token arrives after window/limit.
+			res.RetryAfter = window / time.Duration(max(limit, 1))
+		} else {
+			// A slot frees when the oldest request leaves the window.
+			res.RetryAfter = max(res.ResetAt.Sub(now), 0)
+		}
+	}
+	return res, nil
+}
+
+// RateLimitCheck is RateLimitAttempt returning bare values: whether the
+// request was allowed, the requests left and the reset time in Unix
+// milliseconds.
+func RateLimitCheck(ctx context.Context, client goredis.UniversalClient, key string, limit int, window time.Duration, algo RateLimitAlgorithm) (bool, int64, int64, error) {
+	res, err := RateLimitAttempt(ctx, client, key, limit, window, algo)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return res.Allowed, res.Remaining, res.ResetAt.UnixMilli(), nil
 }
 
 // ParseRateLimitResult converts the Redis Lua script result into typed values.
@@ -210,26 +276,7 @@ func WithIdentifier(fn IdentifierFunc) RateLimitOption {
 // WithTrustedProxies configures proxies allowed to supply forwarded IP headers.
 func WithTrustedProxies(values ...string) RateLimitOption {
 	return func(cfg *rateLimitConfig) {
-		cfg.trustedProxies = cfg.trustedProxies[:0]
-		for _, value := range values {
-			value = strings.TrimSpace(value)
-			if value == "" {
-				continue
-			}
-			if strings.Contains(value, "/") {
-				if prefix, err := netip.ParsePrefix(value); err == nil {
-					cfg.trustedProxies = append(cfg.trustedProxies, prefix)
-				}
-				continue
-			}
-			if addr, err := netip.ParseAddr(value); err == nil {
-				bits := 128
-				if addr.Is4() {
-					bits = 32
-				}
-				cfg.trustedProxies = append(cfg.trustedProxies, netip.PrefixFrom(addr, bits))
-			}
-		}
+		cfg.trustedProxies = ParsePrefixes(values...)
 	}
 }
 
@@ -306,7 +353,7 @@ func RateLimit(client goredis.UniversalClient, limit int, window time.Duration,
 			prefix := strings.Trim(cfg.keyPrefix, ": ")
 			key := prefix + ":rl:" + identifier
 
-			allowed, remaining, resetAt, err := RateLimitCheck(r.Context(), client, key, limit, window, cfg.algorithm)
+			res, err := RateLimitAttempt(r.Context(), client, key, limit, window, cfg.algorithm)
 			if err != nil {
 				c := FromRequest(r)
 				if c != nil {
@@ -317,27 +364,9 @@ func RateLimit(client goredis.UniversalClient, limit int, window time.Duration,
 				return
 			}
 
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
-			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
-			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.UnixMilli(resetAt).Unix(), 10))
-
-			if !allowed {
-				retryAfter := int(math.Ceil(float64(resetAt-time.Now().UnixMilli()) / 1000.0))
-				if retryAfter < 1 {
-					retryAfter = 1
-				}
-				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
-				
-				c := FromRequest(r)
-				if c != nil {
-					_ = c.JSON(map[string]any{
-						"error":       "rate_limit_exceeded",
-						"retry_after": retryAfter,
-					}, http.StatusTooManyRequests)
-				} else {
-					w.WriteHeader(http.StatusTooManyRequests)
-					fmt.Fprintf(w, `{"error":"rate_limit_exceeded","retry_after":%d}`, retryAfter)
-				}
+			res.SetHeaders(w.Header())
+			if !res.Allowed {
+				rejectRateLimited(w, r, res.RetryAfter)
 				return
 			}
 
@@ -346,6 +375,28 @@ func RateLimit(client goredis.UniversalClient, limit int, window time.Duration,
 	}, nil
 }
 
+// rejectRateLimited answers 429 with Retry-After in whole seconds, rounded
+// up and at least one.
+func rejectRateLimited(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	c := FromRequest(r)
+	if c != nil {
+		_ = c.JSON(map[string]any{
+			"error":       "rate_limit_exceeded",
+			"code":        astraerrors.ErrCodeTooManyRequests,
+			"retry_after": retryAfter,
+		}, http.StatusTooManyRequests)
+	} else {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"error":"rate_limit_exceeded","code":%q,"retry_after":%d}`, astraerrors.ErrCodeTooManyRequests, retryAfter)
+	}
+}
+
 // RateLimiter is an alias for RateLimit.
 func RateLimiter(client goredis.UniversalClient, limit int, window time.Duration, opts ...RateLimitOption) (MiddlewareFunc, error) {
 	return RateLimit(client, limit, window, opts...)
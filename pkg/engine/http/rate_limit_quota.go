@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/shauryagautam/Astra/pkg/identity/keys"
+)
+
+// Quota is the rate limit of a consumer: a token bucket refilled with Limit
+// requests per Window that holds up to Burst requests. A client idle for a
+// while may send Burst requests at once, then Limit per Window on average.
+// A Burst below Limit smooths traffic, above it absorbs spikes.
+type Quota struct {
+	// Tier names the quota in the X-RateLimit-Tier header. Consumers moving
+	// to another tier start with a full bucket.
+	Tier   string
+	Limit  int
+	Window time.Duration
+	// Burst is the bucket size; 0 means Limit.
+	Burst int
+	// Soft quotas only report: requests over the limit go through, with
+	// X-RateLimit-Remaining at 0. Use it to roll out a quota and watch who
+	// would hit it before enforcing it.
+	Soft bool
+}
+
+func (q Quota) capacity() int {
+	if q.Burst > 0 {
+		return q.Burst
+	}
+	return q.Limit
+}
+
+// QuotaFunc returns the quota that applies to r. Requests it returns false
+// for are not limited.
+type QuotaFunc func(r *http.Request) (Quota, bool)
+
+// APIKeyQuotas returns a QuotaFunc reading the quota from the API key
+// RequireAPIKey validated:
+//   - the key's own RateLimit, with the shortest window it sets;
+//   - else the tier named by its "tier" metadata;
+//   - else the fallback tier, which also applies to requests without a key.
+//
+// A fallback missing from tiers leaves those requests unlimited.
+func APIKeyQuotas(tiers map[string]Quota, fallback string) QuotaFunc {
+	named := make(map[string]Quota, len(tiers))
+	for name, q := range tiers {
+		if q.Tier == "" {
+			q.Tier = name
+		}
+		named[name] = q
+	}
+	tiers = named
+	return func(r *http.Request) (Quota, bool) {
+		if c := FromRequest(r); c != nil {
+			if key, ok := c.Get("api_key").(*keys.APIKey); ok && key != nil {
+				if q, ok := keyQuota(key.RateLimit); ok {
+					return q, true
+				}
+				if tier, ok := key.Metadata["tier"].(string); ok {
+					if q, ok := tiers[tier]; ok {
+						return q, true
+					}
+				}
+			}
+		}
+		q, ok := tiers[fallback]
+		return q, ok
+	}
+}
+
+func keyQuota(rl *keys.RateLimit) (Quota, bool) {
+	switch {
+	case rl == nil:
+		return Quota{}, false
+	case rl.RequestsPerSecond > 0:
+		return Quota{Tier: "key", Limit: rl.RequestsPerSecond, Window: time.Second}, true
+	case rl.RequestsPerHour > 0:
+		return Quota{Tier: "key", Limit: rl.RequestsPerHour, Window: time.Hour}, true
+	case rl.RequestsPerDay > 0:
+		return Quota{Tier: "key", Limit: rl.RequestsPerDay, Window: 24 * time.Hour}, true
+	}
+	return Quota{}, false
+}
+
+// QuotaRateLimit limits each consumer to the Quota quotas returns for it.
+// Consumers are told apart by ByConsumer, falling back to the client IP;
+// WithIdentifier changes that. Mount it after RequireAPIKey:
+//
+//	limit, err := http.QuotaRateLimit(redis, http.APIKeyQuotas(map[string]http.Quota{
+//	    "free": {Limit: 60, Window: time.Minute},
+//	    "pro":  {Limit: 1200, Window: time.Minute, Burst: 200},
+//	}, "free"))
+//	api.Use(apiKeys.RequireAPIKey(""), limit)
+//
+// Every limited response carries X-RateLimit-Limit (the burst size),
+// X-RateLimit-Remaining, X-RateLimit-Reset (when the bucket is full again,
+// in Unix seconds) and X-RateLimit-Tier. Rejected requests get 429 with
+// Retry-After, the time until the next request is allowed.
+func QuotaRateLimit(client goredis.UniversalClient, quotas QuotaFunc, opts ...RateLimitOption) (MiddlewareFunc, error) {
+	if client == nil {
+		return nil, fmt.Errorf("astra: Redis is required for distributed rate limiting")
+	}
+	if quotas == nil {
+		return nil, fmt.Errorf("astra: QuotaRateLimit needs a QuotaFunc")
+	}
+
+	cfg := rateLimitConfig{
+		identifier:           ByConsumer,
+		apiKeyHeader:         "X-API-Key",
+		keyPrefix:            "astra",
+		fallbackToByIP:       true,
+		algorithm:            TokenBucket,
+		ipSpoofingProtection: true,
+		maxProxyDepth:        5,
+		validateIPHeaders:    true,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q, ok := quotas(r)
+			if !ok || q.Limit <= 0 || q.Window <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := strings.Trim(cfg.keyPrefix, ": ") + ":rl:" + q.Tier + ":" + resolveIdentifier(r, cfg)
+			res, err := QuotaAttempt(r.Context(), client, key, q)
+			if err != nil {
+				if c := FromRequest(r); c != nil {
+					_ = c.InternalError(err.Error())
+				} else {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+
+			res.SetHeaders(w.Header())
+			if q.Tier != "" {
+				w.Header().Set("X-RateLimit-Tier", q.Tier)
+			}
+			if !res.Allowed && !q.Soft {
+				rejectRateLimited(w, r, res.RetryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// QuotaAttempt takes one request from the token bucket stored at key.
+// Limit in the result is the bucket size, Burst when it is set, and a
+// refused request may retry once the next token arrives, after
+// Window/Limit. A quota without a positive Limit and Window is an error.
+func QuotaAttempt(ctx context.Context, client goredis.UniversalClient, key string, q Quota) (RateLimitResult, error) {
+	if q.Limit <= 0 || q.Window <= 0 {
+		return RateLimitResult{}, fmt.Errorf("astra/rate_limit: quota %q needs a positive Limit and Window, got %d per %s", q.Tier, q.Limit, q.Window)
+	}
+	result, err := TokenBucketScript.Run(
+		ctx,
+		client,
+		[]string{key},
+		q.capacity(),
+		q.Window.Milliseconds(),
+		time.Now().UnixMilli(),
+		1,
+		q.Limit,
+	).Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("astra/rate_limit: %w", err)
+	}
+	allowed, remaining, resetAt, err := ParseRateLimitResult(result)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	res := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     q.capacity(),
+		Remaining: remaining,
+		ResetAt:   time.UnixMilli(resetAt),
+	}
+	if !allowed {
+		res.RetryAfter = q.Window / time.Duration(q.Limit)
+	}
+	return res, nil
+}
+
+// QuotaCheck is QuotaAttempt returning bare values: whether the request
+// was allowed, the requests left and when the bucket is full again, in
+// Unix milliseconds.
+func QuotaCheck(ctx context.Context, client goredis.UniversalClient, key string, q Quota) (bool, int64, int64, error) {
+	res, err := QuotaAttempt(ctx, client, key, q)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return res.Allowed, res.Remaining, res.ResetAt.UnixMilli(), nil
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/identity/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaRateLimit(t *testing.T) {
+	server, client := setupRateLimitClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	apiKeys := map[string]*keys.APIKey{
+		"free":   {KeyID: "k1"},
+		"pro":    {KeyID: "k2", Metadata: map[string]any{"tier": "pro"}},
+		"custom": {KeyID: "k3", RateLimit: &keys.RateLimit{RequestsPerHour: 1}},
+		"soft":   {KeyID: "k4", Metadata: map[string]any{"tier": "trial"}},
+	}
+	limit, err := QuotaRateLimit(client, APIKeyQuotas(map[string]Quota{
+		"free":  {Limit: 2, Window: time.Minute},
+		"pro":   {Limit: 60, Window: time.Minute, Burst: 4},
+		"trial": {Limit: 1, Window: time.Minute, Soft: true},
+	}, "free"))
+	require.NoError(t, err)
+
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := apiKeys[r.Header.Get("X-Key")]; key != nil {
+				FromRequest(r).Set("api_key", key)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	router.Use(limit)
+	router.Get("/v1/ping", func(c *Context) error { return c.SendString("pong") })
+
+	call := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/ping", nil)
+		req.Header.Set("X-Key", key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	statuses := func(key string, n int) []int {
+		var out []int
+		for range n {
+			out = append(out, call(key).Code)
+		}
+		return out
+	}
+
+	assert.Equal(t, []int{200, 200, 429}, statuses("free", 3))
+	// Pro refills 1 per second but holds only 4.
+	assert.Equal(t, []int{200, 200, 200, 200, 429}, statuses("pro", 5))
+	assert.Equal(t, []int{200, 429}, statuses("custom", 2))
+	assert.Equal(t, []int{200, 200}, statuses("soft", 2))
+
+	rec := call("pro")
+	assert.Equal(t, "4", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "pro", rec.Header().Get("X-RateLimit-Tier"))
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.NotEmpty(t, rec.Header().Get("X-RateLimit-Reset"))
+	assert.Contains(t, rec.Body.String(), "E_TOO_MANY_REQUESTS")
+
+	rec = call("custom")
+	assert.Equal(t, "key", rec.Header().Get("X-RateLimit-Tier"))
+	assert.Equal(t, "3600", rec.Header().Get("Retry-After"))
+
+	rec = call("soft")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestQuotaAttempt_RejectsEmptyQuota(t *testing.T) {
+	server, client := setupRateLimitClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	for _, q := range []Quota{{Window: time.Minute}, {Limit: 5}, {Limit: -1, Window: time.Minute}} {
+		_, err := QuotaAttempt(context.Background(), client, "quota:empty", q)
+		assert.Error(t, err, "%+v", q)
+	}
+}
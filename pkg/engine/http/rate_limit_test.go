@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -170,3 +171,39 @@ func TestTokenBucketRateLimitExceeded(t *testing.T) {
 		assert.Equal(t, "rate_limit_exceeded", body["error"])
 	}
 }
+
+func TestRateLimitAttempt(t *testing.T) {
+	server, client := setupRateLimitClient(t)
+	defer server.Close()
+	defer client.Close()
+	ctx := context.Background()
+
+	for _, algo := range []RateLimitAlgorithm{SlidingWindow, TokenBucket} {
+		key := "attempt:" + strconv.Itoa(int(algo))
+		res, err := RateLimitAttempt(ctx, client, key, 2, time.Minute, algo)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2, res.Limit)
+		assert.Equal(t, int64(1), res.Remaining)
+		assert.Zero(t, res.RetryAfter)
+		assert.True(t, res.ResetAt.After(time.Now()))
+
+		_, err = RateLimitAttempt(ctx, client, key, 2, time.Minute, algo)
+		require.NoError(t, err)
+		res, err = RateLimitAttempt(ctx, client, key, 2, time.Minute, algo)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, int64(0), res.Remaining)
+		if algo == TokenBucket {
+			assert.Equal(t, 30*time.Second, res.RetryAfter)
+		} else {
+			assert.InDelta(t, time.Minute, res.RetryAfter, float64(time.Second))
+		}
+
+		h := http.Header{}
+		res.SetHeaders(h)
+		assert.Equal(t, "2", h.Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", h.Get("X-RateLimit-Remaining"))
+		assert.Equal(t, strconv.FormatInt(res.ResetAt.Unix(), 10), h.Get("X-RateLimit-Reset"))
+	}
+}
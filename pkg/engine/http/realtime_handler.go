@@ -1,35 +1,176 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	stdhttp "net/http"
+	"net/url"
+	"slices"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/shauryagautam/Astra/pkg/engine" // Fine, http already imports engine
+	"github.com/shauryagautam/Astra/pkg/identity/auth"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
 	"github.com/shauryagautam/Astra/pkg/realtime"
 )
 
+// wsAuthTimeout bounds how long a connection may stay open before sending
+// its auth message.
+const wsAuthTimeout = 10 * time.Second
+
+// WebSocketOption configures a WebSocketHandler.
+type WebSocketOption func(*WebSocketHandler)
+
+// WithWebSocketGuard authenticates connections that reach the handler
+// without a user. Browsers cannot set headers on a WebSocket handshake, so
+// the token is read from the "token" query parameter or, failing that, from
+// a first message of the form {"type":"auth","payload":{"token":"..."}}.
+// Connections that do not authenticate in time are closed with 1008.
+func WithWebSocketGuard(guard auth.Guard) WebSocketOption {
+	return func(h *WebSocketHandler) { h.guard = guard }
+}
+
 // WebSocketHandler handles WebSocket connections.
+//
+// Whether guests may connect is decided by the route: mount Connect behind
+// Auth(guard) to reject unauthenticated upgrades with 401, or configure
+// WithWebSocketGuard to authenticate after the upgrade.
 type WebSocketHandler struct {
 	upgrader *websocket.Upgrader
 	manager  *realtime.RoomManager
 	app      *engine.App
+	guard    auth.Guard
 }
 
-func NewWebSocketHandler(manager *realtime.RoomManager, app *engine.App) *WebSocketHandler {
-	return &WebSocketHandler{
+func NewWebSocketHandler(manager *realtime.RoomManager, app *engine.App, opts ...WebSocketOption) *WebSocketHandler {
+	var (
+		allowed []string
+		dev     bool
+	)
+	if app != nil {
+		allowed = app.Config().WS.AllowedOrigins
+		dev = app.Env().IsDev()
+	}
+	h := &WebSocketHandler{
 		upgrader: &websocket.Upgrader{
-			CheckOrigin: func(r *stdhttp.Request) bool { return true },
+			HandshakeTimeout: 10 * time.Second,
+			CheckOrigin:      checkWebSocketOrigin(allowed, dev),
 		},
 		manager: manager,
 		app:     app,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
+// checkWebSocketOrigin accepts same-origin handshakes and origins listed in
+// WS_ALLOWED_ORIGINS ("*" allows any). Development mode accepts everything.
+// Handshakes without an Origin header come from non-browser clients, which
+// cross-site hijacking does not concern, and are accepted too.
+func checkWebSocketOrigin(allowed []string, dev bool) func(r *stdhttp.Request) bool {
+	return func(r *stdhttp.Request) bool {
+		if dev {
+			return true
+		}
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if slices.Contains(allowed, origin) || slices.Contains(allowed, "*") {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+}
+
+// Connect upgrades the request and registers a realtime client carrying the
+// authenticated user, if any.
 func (h *WebSocketHandler) Connect(c *Context) error {
+	user := c.AuthUser()
+	if user == nil && h.guard != nil {
+		if token := c.Query("token"); token != "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+			if err := h.guard.Attempt(c); err != nil {
+				return c.UnauthorizedError(err.Error())
+			}
+			user = c.AuthUser()
+		}
+	}
+
 	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		return err
 	}
-	_ = conn // Handle connection
+	c.written = true
+
+	if user == nil && h.guard != nil {
+		if user, err = h.authenticateFirstMessage(c, conn); err != nil {
+			slog.Warn("ws: handshake authentication failed", "remote", c.Request.RemoteAddr, "error", err)
+			deadline := time.Now().Add(time.Second)
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "unauthorized"), deadline)
+			return conn.Close()
+		}
+		c.SetAuthUser(user)
+	}
+
+	// The request context ends when this handler returns; the client's
+	// context keeps its values (including the auth user) without that.
+	ctx := context.WithoutCancel(c.Ctx())
+	client := realtime.NewAuthenticatedClient(ctx, conn, user, h.manager)
+	h.manager.Register <- client
+
+	go client.WritePump(ctx)
+	go client.ReadPump(ctx)
 	return nil
 }
+
+// authenticateFirstMessage reads the auth message and runs the guard on a
+// copy of the handshake request carrying its token.
+func (h *WebSocketHandler) authenticateFirstMessage(c *Context, conn *websocket.Conn) (*identityclaims.AuthClaims, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(wsAuthTimeout)); err != nil {
+		return nil, err
+	}
+	var msg realtime.Message
+	if err := conn.ReadJSON(&msg); err != nil {
+		return nil, err
+	}
+	token, _ := msg.Payload["token"].(string)
+	if msg.Type != "auth" || token == "" {
+		return nil, errors.New("expected an auth message with a token")
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	req := c.Request.Clone(c.Ctx())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rc := &wsAuthContext{req: req}
+	if err := h.guard.Attempt(rc); err != nil {
+		return nil, err
+	}
+	if rc.user == nil {
+		return nil, errors.New("guard did not set a user")
+	}
+	return rc.user, nil
+}
+
+// wsAuthContext adapts an upgraded connection to auth.RequestContext. The
+// HTTP response is gone by then, so cookie and session writes are dropped.
+type wsAuthContext struct {
+	req  *stdhttp.Request
+	user *identityclaims.AuthClaims
+}
+
+func (w *wsAuthContext) GetRequest() *stdhttp.Request { return w.req }
+
+func (w *wsAuthContext) SetAuthUser(claims *identityclaims.AuthClaims) { w.user = claims }
+
+func (w *wsAuthContext) SetCookie(*stdhttp.Cookie) {}
+
+func (w *wsAuthContext) RegenerateSession() error { return nil }
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/identity/auth"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+	"github.com/shauryagautam/Astra/pkg/realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenGuard accepts "Bearer <user id>" for any id except "bad".
+type tokenGuard struct{}
+
+func (tokenGuard) Name() string { return "token" }
+
+func (tokenGuard) Attempt(c auth.RequestContext) error {
+	id := strings.TrimPrefix(c.GetRequest().Header.Get("Authorization"), "Bearer ")
+	if id == "" || id == "bad" {
+		return errors.New("invalid token")
+	}
+	c.SetAuthUser(&identityclaims.AuthClaims{UserID: id})
+	return nil
+}
+
+func (tokenGuard) Login(auth.RequestContext, any) (any, error) { return nil, nil }
+func (tokenGuard) Logout(auth.RequestContext) error            { return nil }
+
+func wsServer(t *testing.T, mw []MiddlewareFunc, opts ...WebSocketOption) (*httptest.Server, *realtime.RoomManager) {
+	t.Helper()
+	manager := realtime.NewRoomManager()
+	router := NewRouter(&config.AstraConfig{}, nil)
+	for _, m := range mw {
+		router.Use(m)
+	}
+	router.Get("/ws", NewWebSocketHandler(manager, nil, opts...).Connect)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv, manager
+}
+
+func dialWS(srv *httptest.Server, query string) (*websocket.Conn, *http.Response, error) {
+	u := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws" + query
+	return websocket.DefaultDialer.Dial(u, http.Header{"Origin": {srv.URL}})
+}
+
+func waitForClient(t *testing.T, m *realtime.RoomManager, userID string) *realtime.Client {
+	t.Helper()
+	var client *realtime.Client
+	require.Eventually(t, func() bool {
+		if clients := m.UserClients(userID); len(clients) > 0 {
+			client = clients[0]
+		}
+		return client != nil
+	}, time.Second, 5*time.Millisecond)
+	return client
+}
+
+func TestWebSocketHandler_Auth(t *testing.T) {
+	t.Run("query token", func(t *testing.T) {
+		srv, manager := wsServer(t, nil, WithWebSocketGuard(tokenGuard{}))
+		conn, _, err := dialWS(srv, "?token=42")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		client := waitForClient(t, manager, "42")
+		assert.Equal(t, "42", client.User().UserID)
+		assert.Equal(t, "42", auth.GetAuthUser(client.Context()).UserID)
+	})
+
+	t.Run("first message", func(t *testing.T) {
+		srv, manager := wsServer(t, nil, WithWebSocketGuard(tokenGuard{}))
+		conn, _, err := dialWS(srv, "")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(realtime.Message{Type: "auth", Payload: map[string]any{"token": "7"}}))
+		client := waitForClient(t, manager, "7")
+		assert.Equal(t, "7", client.User().UserID)
+	})
+
+	t.Run("bad first message closes with policy violation", func(t *testing.T) {
+		srv, _ := wsServer(t, nil, WithWebSocketGuard(tokenGuard{}))
+		conn, _, err := dialWS(srv, "")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(realtime.Message{Type: "auth", Payload: map[string]any{"token": "bad"}}))
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+	})
+
+	t.Run("route middleware rejects upgrade", func(t *testing.T) {
+		srv, manager := wsServer(t, []MiddlewareFunc{Auth(tokenGuard{})})
+		_, resp, err := dialWS(srv, "")
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		conn, _, err := dialWS(srv, "?token=9")
+		require.NoError(t, err)
+		defer conn.Close()
+		assert.Equal(t, "9", waitForClient(t, manager, "9").User().UserID)
+	})
+
+	t.Run("foreign origin rejected", func(t *testing.T) {
+		srv, _ := wsServer(t, nil)
+		u := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+		_, resp, err := websocket.DefaultDialer.Dial(u, http.Header{"Origin": {"https://evil.example"}})
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("non-browser client without origin", func(t *testing.T) {
+		srv, _ := wsServer(t, nil)
+		u := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+		conn, _, err := websocket.DefaultDialer.Dial(u, nil)
+		require.NoError(t, err)
+		conn.Close()
+	})
+}
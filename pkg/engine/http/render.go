@@ -1,6 +1,8 @@
 package http
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 	"io"
@@ -8,8 +10,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -22,6 +26,11 @@ type ViewEngine interface {
 
 // TemplateEngine is the default view engine that wraps html/template
 // with layout support, helper functions, and optional auto-reload in dev mode.
+//
+// Compiled views are cached with a hash of their sources. In production the
+// cache is kept for the life of the process; in dev mode each request stats
+// the view's files, hashes only those whose modification time or size
+// changed, and recompiles the view when one of those hashes differs.
 type TemplateEngine struct {
 	fs        fs.FS
 	dir       string
@@ -29,9 +38,30 @@ type TemplateEngine struct {
 	layout    string
 	funcMap   template.FuncMap
 	isDev     bool
+	// componentDir holds the templates rendered by the component helper.
+	componentDir string
+	// routes maps route names to path patterns for the route helper.
+	routes map[string]string
+
+	mu         sync.RWMutex
+	templates  map[string]*cachedView
+	components map[string]*cachedView
+}
+
+// cachedView is a compiled view with the files it was compiled from and a
+// hash of their content.
+type cachedView struct {
+	tmpl  *template.Template
+	files []viewFile
+	hash  string
+}
 
-	mu        sync.RWMutex
-	templates map[string]*template.Template
+// viewFile is one source of a cached view as it was when compiled.
+type viewFile struct {
+	name    string
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
 }
 
 // TemplateOption is a functional option for configuring the TemplateEngine.
@@ -67,7 +97,16 @@ func WithFuncMap(funcMap template.FuncMap) TemplateOption {
 	}
 }
 
-// WithDevMode enables auto-reload of templates on every render (no caching).
+// WithComponentDir sets the directory, relative to the views, that holds
+// the templates of the component helper (default: "components").
+func WithComponentDir(dir string) TemplateOption {
+	return func(e *TemplateEngine) {
+		e.componentDir = strings.Trim(dir, "/")
+	}
+}
+
+// WithDevMode makes the engine check the views it renders for changes and
+// recompile those whose files were edited.
 func WithDevMode(isDev bool) TemplateOption {
 	return func(e *TemplateEngine) {
 		e.isDev = isDev
@@ -85,10 +124,16 @@ func WithDevMode(isDev bool) TemplateOption {
 //	app.Register("views", engine)
 func NewTemplateEngine(dir string, opts ...TemplateOption) *TemplateEngine {
 	e := &TemplateEngine{
-		dir:       dir,
-		extension: ".html",
-		funcMap:   defaultFuncMap(),
-		templates: make(map[string]*template.Template),
+		dir:          dir,
+		extension:    ".html",
+		funcMap:      defaultFuncMap(),
+		componentDir: "components",
+		routes:       make(map[string]string),
+		templates:    make(map[string]*cachedView),
+		components:   make(map[string]*cachedView),
+	}
+	for name, fn := range e.viewHelpers() {
+		e.funcMap[name] = fn
 	}
 
 	for _, opt := range opts {
@@ -113,86 +158,199 @@ func (e *TemplateEngine) Render(wr io.Writer, name string, data any) error {
 
 // getTemplate returns a cached or freshly compiled template.
 func (e *TemplateEngine) getTemplate(name string) (*template.Template, error) {
-	// In dev mode, always recompile
-	if !e.isDev {
-		e.mu.RLock()
-		if t, ok := e.templates[name]; ok {
-			e.mu.RUnlock()
-			return t, nil
-		}
-		e.mu.RUnlock()
+	e.mu.RLock()
+	v, ok := e.templates[name]
+	e.mu.RUnlock()
+	if ok && e.fresh(v) {
+		return v.tmpl, nil
 	}
 
-	tmpl, err := e.compile(name)
+	v, err := e.compile(name)
 	if err != nil {
 		return nil, err
 	}
 
+	e.mu.Lock()
+	e.templates[name] = v
+	e.mu.Unlock()
+
+	return v.tmpl, nil
+}
+
+// fresh reports whether a cached view can be used as is: always in
+// production, and in dev mode while none of its files changed. A file whose
+// modification time and size are as compiled is taken as unchanged without
+// reading it; the others are read and compared by hash. Files without a
+// modification time, as in some embedded file systems, are always hashed.
+func (e *TemplateEngine) fresh(v *cachedView) bool {
 	if !e.isDev {
-		e.mu.Lock()
-		e.templates[name] = tmpl
-		e.mu.Unlock()
+		return true
 	}
-
-	return tmpl, nil
+	for _, f := range v.files {
+		info, err := e.statFile(f.name)
+		if err != nil {
+			return false
+		}
+		if !info.ModTime().IsZero() && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+			continue
+		}
+		content, err := e.readFile(f.name)
+		if err != nil || sha256.Sum256(content) != f.sum {
+			return false
+		}
+	}
+	return true
 }
 
-// compile parses the template (and optional layout) from the filesystem.
-func (e *TemplateEngine) compile(name string) (*template.Template, error) {
-	filename := name + e.extension
+// compile parses the template together with its layouts: the default
+// layout first, then the layouts the view extends, outermost first, so that
+// inner files override the blocks of outer ones. The view is executed,
+// unless it extends a layout, in which case the outermost layout is.
+func (e *TemplateEngine) compile(name string) (*cachedView, error) {
+	chain, err := e.layoutChain(name)
+	if err != nil {
+		return nil, err
+	}
 
 	var files []string
+	if e.layout != "" && e.layout != chain[0] {
+		files = append(files, e.layout)
+	}
+	files = append(files, chain...)
+
+	return e.parse(filepath.Base(chain[0]+e.extension), files)
+}
+
+// parse reads the given views and parses them into one template set named
+// root. Each file is a template named after its base name, as with
+// template.ParseFiles.
+func (e *TemplateEngine) parse(root string, files []string) (*cachedView, error) {
+	tmpl := template.New(root).Funcs(e.funcMap)
+	v := &cachedView{tmpl: tmpl, files: make([]viewFile, 0, len(files))}
+	h := sha256.New()
+	for _, file := range files {
+		// Stat before reading, so an edit in between shows up as a change
+		// on the next check rather than being missed.
+		info, err := e.statFile(file)
+		if err != nil {
+			return nil, err
+		}
+		content, err := e.readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f := viewFile{name: file, modTime: info.ModTime(), size: info.Size(), sum: sha256.Sum256(content)}
+		v.files = append(v.files, f)
+		// The name is hashed too, so that moving content between files
+		// changes the view's hash.
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+		h.Write(f.sum[:])
+		t := tmpl
+		if base := filepath.Base(file + e.extension); base != root {
+			t = tmpl.New(base)
+		}
+		if _, err := t.Parse(string(content)); err != nil {
+			return nil, err
+		}
+	}
+	v.hash = hex.EncodeToString(h.Sum(nil))
+	return v, nil
+}
 
-	// If a layout is set, include it first
-	if e.layout != "" {
-		layoutFile := e.layout + e.extension
-		files = append(files, layoutFile)
+// readFile reads the source of a view from the engine's FS or directory.
+func (e *TemplateEngine) readFile(name string) ([]byte, error) {
+	filename := name + e.extension
+	if e.fs != nil {
+		return fs.ReadFile(e.fs, filename)
 	}
-	files = append(files, filename)
+	return os.ReadFile(filepath.Join(e.dir, filename)) // #nosec G304 -- view names come from the application
+}
 
-	// Parse from embedded FS or disk
+// statFile stats the source of a view in the engine's FS or directory.
+func (e *TemplateEngine) statFile(name string) (fs.FileInfo, error) {
+	filename := name + e.extension
 	if e.fs != nil {
-		return template.New(filepath.Base(filename)).Funcs(e.funcMap).ParseFS(e.fs, files...)
+		return fs.Stat(e.fs, filename)
 	}
+	return os.Stat(filepath.Join(e.dir, filename))
+}
 
-	// Parse from disk
-	fullPaths := make([]string, len(files))
-	for i, f := range files {
-		fullPaths[i] = filepath.Join(e.dir, f)
+// layoutDirective matches the {{/* layout "name" */}} comment a view or
+// layout uses to extend a layout.
+var layoutDirective = regexp.MustCompile(`^\s*\{\{-?\s*/\*\s*layout\s+"([^"]+)"\s*\*/\s*-?\}\}`)
+
+// layoutChain follows the layout directives from view name up and returns
+// the views involved, outermost layout first and name last.
+func (e *TemplateEngine) layoutChain(name string) ([]string, error) {
+	chain := []string{name}
+	seen := map[string]bool{name: true}
+	for current := name; ; {
+		content, err := e.readFile(current)
+		if err != nil {
+			return nil, err
+		}
+		m := layoutDirective.FindSubmatch(content)
+		if m == nil {
+			return chain, nil
+		}
+		parent := string(m[1])
+		if seen[parent] {
+			return nil, fmt.Errorf("views: layout cycle: %q extends %q", current, parent)
+		}
+		seen[parent] = true
+		chain = append([]string{parent}, chain...)
+		current = parent
 	}
-	return template.New(filepath.Base(filename)).Funcs(e.funcMap).ParseFiles(fullPaths...)
 }
 
-// Warmup pre-compiles all templates found in the engine's directory.
+// Warmup pre-compiles all templates found in the engine's directory, or in
+// its FS when one is set (e.g. views embedded with go:embed).
 // Useful for production to avoid late compilation latency.
 func (e *TemplateEngine) Warmup() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	return filepath.Walk(e.dir, func(path string, info os.FileInfo, err error) error {
+	source := e.fs
+	if source == nil {
+		source = os.DirFS(e.dir)
+	}
+	return fs.WalkDir(source, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() || !strings.HasSuffix(path, e.extension) {
+		if d.IsDir() || !strings.HasSuffix(path, e.extension) {
 			return nil
 		}
 
-		rel, err := filepath.Rel(e.dir, path)
-		if err != nil {
-			return err
-		}
-
-		name := strings.TrimSuffix(rel, e.extension)
-		tmpl, err := e.compile(name)
+		name := strings.TrimSuffix(path, e.extension)
+		v, err := e.compile(name)
 		if err != nil {
 			return fmt.Errorf("views: failed to warmup %q: %w", name, err)
 		}
 
-		e.templates[name] = tmpl
+		e.templates[name] = v
 		return nil
 	})
 }
 
+// Hashes returns the content hash of every cached view, by name. After
+// Warmup it lists all the views, which is what the view:cache command
+// prints.
+func (e *TemplateEngine) Hashes() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	hashes := make(map[string]string, len(e.templates))
+	for name, v := range e.templates {
+		hashes[name] = v.hash
+	}
+	return hashes
+}
+
+// FS returns the filesystem set with WithFS, or nil when the views are read
+// from the directory.
+func (e *TemplateEngine) FS() fs.FS { return e.fs }
+
 // defaultFuncMap returns a set of built-in template helper functions.
 func defaultFuncMap() template.FuncMap {
 	return template.FuncMap{
@@ -250,7 +408,11 @@ func defaultFuncMap() template.FuncMap {
 			return b
 		},
 
-		// Asset helpers
+		// Asset helpers. asset is replaced by the asset pipeline's helper
+		// when one is registered with WithFuncMap.
+		"asset": func(path string) string {
+			return "/static/" + strings.TrimPrefix(path, "/")
+		},
 		"asset_path": func(path string) string {
 			return "/static/" + strings.TrimPrefix(path, "/")
 		},
@@ -360,9 +522,30 @@ func (c *Context) Render(name string, data any, status ...int) error {
 		c.ClearFlashes()
 	}
 
-	// Auto-inject Context for helpers (T, locale, etc)
+	// Auto-inject the form data flashed by RedirectBack().WithInput() and
+	// WithErrors(), so templates can use {{index .Old "email"}}. Keys the
+	// handler set itself are left alone.
+	if m, ok := data.(map[string]any); ok {
+		old, errs := map[string]string{}, map[string][]string{}
+		if sess := c.Session(); sess != nil {
+			for k := range sess.OldInput() {
+				old[k] = sess.Old(k)
+			}
+			errs = sess.Errors()
+		}
+		if _, set := m["Old"]; !set {
+			m["Old"] = old
+		}
+		if _, set := m["Errors"]; !set {
+			m["Errors"] = errs
+		}
+	}
+
+	// Auto-inject Context for helpers (T, locale, etc), and the locale for
+	// <html lang="{{.Locale}}">.
 	if m, ok := data.(map[string]any); ok {
 		m["Context"] = c
+		m["Locale"] = c.Locale()
 	}
 
 	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
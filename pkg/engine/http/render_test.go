@@ -0,0 +1,88 @@
+package http
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateEngine_ReloadsChangedViews(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/app.html": {Data: []byte(`<main>{{block "body" .}}{{end}}</main>`)},
+		"page.html":        {Data: []byte(`{{template "app.html" .}}{{define "body"}}v1{{end}}`)},
+	}
+	prod := NewTemplateEngine("", WithFS(files), WithLayout("layouts/app"))
+	dev := NewTemplateEngine("", WithFS(files), WithLayout("layouts/app"), WithDevMode(true))
+	render := func(e *TemplateEngine) string {
+		var buf bytes.Buffer
+		require.NoError(t, e.Render(&buf, "page", nil))
+		return buf.String()
+	}
+
+	assert.Equal(t, "<main>v1</main>", render(prod))
+	assert.Equal(t, "<main>v1</main>", render(dev))
+	before := dev.Hashes()["page"]
+	require.NotEmpty(t, before)
+
+	files["layouts/app.html"] = &fstest.MapFile{Data: []byte(`<body>{{block "body" .}}{{end}}</body>`)}
+
+	// Production keeps what it compiled; dev mode picks up the edit to the
+	// layout.
+	assert.Equal(t, "<main>v1</main>", render(prod))
+	assert.Equal(t, "<body>v1</body>", render(dev))
+	assert.NotEqual(t, before, dev.Hashes()["page"])
+}
+
+func TestTemplateEngine_DevModeChecksModTimeFirst(t *testing.T) {
+	stamp := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := fstest.MapFS{"page.html": {Data: []byte(`v1`), ModTime: stamp}}
+	dev := NewTemplateEngine("", WithFS(files), WithDevMode(true))
+	assert.Equal(t, "v1", renderView(t, dev, "page", nil))
+
+	// Same modification time and size: the file is not read again.
+	files["page.html"] = &fstest.MapFile{Data: []byte(`v2`), ModTime: stamp}
+	assert.Equal(t, "v1", renderView(t, dev, "page", nil))
+
+	// A touched file with the same content keeps the compiled view.
+	before := dev.Hashes()["page"]
+	files["page.html"] = &fstest.MapFile{Data: []byte(`v1`), ModTime: stamp.Add(time.Second)}
+	assert.Equal(t, "v1", renderView(t, dev, "page", nil))
+	assert.Equal(t, before, dev.Hashes()["page"])
+
+	files["page.html"] = &fstest.MapFile{Data: []byte(`v3`), ModTime: stamp.Add(2 * time.Second)}
+	assert.Equal(t, "v3", renderView(t, dev, "page", nil))
+}
+
+func TestTemplateEngine_WarmupHashes(t *testing.T) {
+	e := NewTemplateEngine("", WithFS(fstest.MapFS{
+		"home.html":       {Data: []byte(`home`)},
+		"users/show.html": {Data: []byte(`{{.}}`)},
+	}))
+	require.NoError(t, e.Warmup())
+	hashes := e.Hashes()
+	assert.Len(t, hashes, 2)
+	assert.NotEqual(t, hashes["home"], hashes["users/show"])
+
+	broken := NewTemplateEngine("", WithFS(fstest.MapFS{"bad.html": {Data: []byte(`{{if}}`)}}))
+	assert.ErrorContains(t, broken.Warmup(), `failed to warmup "bad"`)
+}
+
+func TestTemplateEngine_ReloadsChangedComponents(t *testing.T) {
+	files := fstest.MapFS{
+		"components/note.html": {Data: []byte(`<i>a</i>`)},
+		"note.html":            {Data: []byte(`{{ component "note" }}`)},
+	}
+	prod := NewTemplateEngine("", WithFS(files))
+	dev := NewTemplateEngine("", WithFS(files), WithDevMode(true))
+	assert.Equal(t, "<i>a</i>", renderView(t, prod, "note", nil))
+	assert.Equal(t, "<i>a</i>", renderView(t, dev, "note", nil))
+
+	files["components/note.html"] = &fstest.MapFile{Data: []byte(`<i>b</i>`)}
+
+	assert.Equal(t, "<i>a</i>", renderView(t, prod, "note", nil))
+	assert.Equal(t, "<i>b</i>", renderView(t, dev, "note", nil))
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	nethttp "net/http"
+)
+
+// ErrResponseCommitted is returned when a header is changed after the status
+// line has been sent, where net/http would silently drop it.
+var ErrResponseCommitted = errors.New("astra/http: response already committed")
+
+// hookWriter wraps the connection's ResponseWriter for a Context. It
+// runs the BeforeSend hooks right before the status line goes out and keeps
+// the status and size for OnFinished hooks. It lives inside the pooled
+// Context, so wrapping costs no allocation.
+type hookWriter struct {
+	nethttp.ResponseWriter
+	c         *Context
+	status    int
+	size      int64
+	committed bool
+	sending   bool
+	hijacked  bool
+	// unrouted is set while the mux looks for a route, until one runs, and
+	// missed holds the 404 or 405 the mux answered meanwhile. The mux's
+	// plain-text response is dropped so the Router can answer it with a
+	// coded error instead.
+	unrouted bool
+	missed   int
+}
+
+func (w *hookWriter) WriteHeader(code int) {
+	if w.unrouted {
+		w.unrouted = false
+		if code == nethttp.StatusNotFound || code == nethttp.StatusMethodNotAllowed {
+			w.missed = code
+			return
+		}
+	}
+	if w.committed || w.sending {
+		return
+	}
+	w.sending = true
+	for _, fn := range w.c.beforeSend {
+		fn(w.c)
+	}
+	w.sending = false
+	w.committed = true
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *hookWriter) Write(b []byte) (int, error) {
+	if w.missed != 0 {
+		return len(b), nil
+	}
+	if !w.committed {
+		w.WriteHeader(nethttp.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *hookWriter) Flush() {
+	if !w.committed {
+		w.WriteHeader(nethttp.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(nethttp.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *hookWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(nethttp.Hijacker)
+	if !ok {
+		return nil, nil, nethttp.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.committed, w.hijacked = true, true
+		w.status = nethttp.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *hookWriter) Unwrap() nethttp.ResponseWriter { return w.ResponseWriter }
+
+// BeforeSend registers fn to run once, right before the status line and
+// headers are sent, whichever helper or middleware writes first. Hooks run in
+// registration order and may still set headers:
+//
+//	c.BeforeSend(func(c *http.Context) {
+//		_ = c.SetHeader("Server-Timing", timing.String())
+//	})
+func (c *Context) BeforeSend(fn func(c *Context)) {
+	c.beforeSend = append(c.beforeSend, fn)
+}
+
+// OnFinished registers fn to run after the handler chain returns and the
+// body has been flushed to the client, for audit logging or metrics that
+// must not delay the response. ResponseStatus and ResponseSize describe what
+// was sent. Hooks run in reverse registration order, like deferred calls.
+func (c *Context) OnFinished(fn func(c *Context)) {
+	c.onFinished = append(c.onFinished, fn)
+}
+
+// Committed reports whether the status line and headers have been sent.
+func (c *Context) Committed() bool {
+	return c.resp.committed
+}
+
+// SetHeader sets a response header, or returns ErrResponseCommitted when
+// the headers have already been sent and the change would be lost.
+func (c *Context) SetHeader(key, value string) error {
+	if c.resp.committed {
+		return ErrResponseCommitted
+	}
+	c.resp.Header().Set(key, value)
+	return nil
+}
+
+// ResponseStatus returns the status code sent, or 0 before the response is
+// committed.
+func (c *Context) ResponseStatus() int {
+	return c.resp.status
+}
+
+// ResponseSize returns the number of body bytes written so far.
+func (c *Context) ResponseSize() int64 {
+	return c.resp.size
+}
+
+// finish commits a response the handler left empty, so BeforeSend hooks
+// still run, then flushes it and runs the OnFinished hooks.
+func (c *Context) finish() {
+	if !c.resp.committed && len(c.beforeSend) > 0 {
+		c.resp.WriteHeader(nethttp.StatusOK)
+	}
+	if len(c.onFinished) == 0 {
+		return
+	}
+	if c.resp.committed && !c.resp.hijacked {
+		if f, ok := c.resp.ResponseWriter.(nethttp.Flusher); ok {
+			f.Flush()
+		}
+	}
+	for i := len(c.onFinished) - 1; i >= 0; i-- {
+		c.onFinished[i](c)
+	}
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextResponseHooks(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, nil)
+
+	var events []string
+	var guardErr error
+	router.Get("/report", func(c *Context) error {
+		c.BeforeSend(func(c *Context) {
+			events = append(events, "before send")
+			assert.NoError(t, c.SetHeader("X-Timing", "12ms"))
+		})
+		c.OnFinished(func(c *Context) {
+			events = append(events, "finished second")
+		})
+		c.OnFinished(func(c *Context) {
+			events = append(events, "finished first")
+			assert.Equal(t, http.StatusCreated, c.ResponseStatus())
+			assert.Equal(t, int64(len("{\"ok\":true}\n")), c.ResponseSize())
+		})
+
+		assert.False(t, c.Committed())
+		err := c.JSON(map[string]bool{"ok": true}, http.StatusCreated)
+		assert.True(t, c.Committed())
+		guardErr = c.SetHeader("X-Late", "dropped")
+		events = append(events, "handler done")
+		return err
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "12ms", rec.Header().Get("X-Timing"))
+	assert.Empty(t, rec.Header().Get("X-Late"))
+	require.ErrorIs(t, guardErr, ErrResponseCommitted)
+	assert.Equal(t, []string{"before send", "handler done", "finished first", "finished second"}, events)
+	assert.True(t, rec.Flushed)
+}
+
+func TestContextBeforeSend_EmptyResponse(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, nil)
+	router.Get("/empty", func(c *Context) error {
+		c.BeforeSend(func(c *Context) { _ = c.SetHeader("X-Hook", "ran") })
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/empty", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ran", rec.Header().Get("X-Hook"))
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RouteFile is one file's share of the application's routes. Splitting a
+// large route table into start/routes/users.go, start/routes/admin.go and so
+// on keeps each file small; each registers itself from init:
+//
+//	// start/routes/admin.go
+//	func init() {
+//		http.RegisterRouteFile(http.RouteFile{
+//			Name:   "admin",
+//			Prefix: "/admin",
+//			Group:  "web",
+//			Routes: func(r *http.Router) {
+//				r.Get("/users", admin.Users)
+//			},
+//		})
+//	}
+//
+// and the application loads them all at once with Router.LoadRoutes.
+type RouteFile struct {
+	// Name identifies the file in errors and orders the files; it must be
+	// unique.
+	Name string
+	// Prefix is prepended to every route of the file.
+	Prefix string
+	// Group names the middleware group, defined with
+	// Router.MiddlewareGroup, that every route of the file goes through,
+	// typically "web" or "api". Empty means none.
+	Group string
+	// Routes registers the file's routes.
+	Routes func(r *Router)
+}
+
+var (
+	routeFilesMu sync.Mutex
+	routeFiles   []RouteFile
+)
+
+// RegisterRouteFile adds f to the files LoadRoutes registers by default.
+// Call it from init, so importing the routes package for its side effects
+// is enough:
+//
+//	import _ "example.com/app/start/routes"
+func RegisterRouteFile(f RouteFile) {
+	routeFilesMu.Lock()
+	defer routeFilesMu.Unlock()
+	routeFiles = append(routeFiles, f)
+}
+
+// RegisteredRouteFiles returns the files added with RegisterRouteFile,
+// sorted by name.
+func RegisteredRouteFiles() []RouteFile {
+	routeFilesMu.Lock()
+	files := slices.Clone(routeFiles)
+	routeFilesMu.Unlock()
+	slices.SortStableFunc(files, func(a, b RouteFile) int { return strings.Compare(a.Name, b.Name) })
+	return files
+}
+
+// MiddlewareGroup defines the middleware group name, replacing any earlier
+// definition. Route files select a group by name, so the middleware of
+// browser pages and of the JSON API is declared once:
+//
+//	r.MiddlewareGroup("web", http.SessionMiddleware(store), http.CSRF(isProd))
+//	r.MiddlewareGroup("api", apiLimiter)
+//
+// Groups are shared by the router, its groups and With routers. Define them
+// before LoadRoutes.
+func (r *Router) MiddlewareGroup(name string, mws ...MiddlewareFunc) {
+	if r.groups == nil {
+		r.groups = make(map[string][]MiddlewareFunc)
+	}
+	r.groups[name] = slices.Clone(mws)
+}
+
+// LoadRoutes registers files, or every file added with RegisterRouteFile
+// when files is empty, in name order. Each file's routes get the router's
+// middleware, then those of the file's group. It registers nothing and
+// returns an error when two files share a name or a file names an
+// undefined group, so a typo fails at startup rather than serving routes
+// without their middleware.
+func (r *Router) LoadRoutes(files ...RouteFile) error {
+	if len(files) == 0 {
+		files = RegisteredRouteFiles()
+	}
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		if seen[f.Name] {
+			return fmt.Errorf("astra/http: route file %q is registered twice", f.Name)
+		}
+		seen[f.Name] = true
+		if _, ok := r.groups[f.Group]; f.Group != "" && !ok {
+			return fmt.Errorf("astra/http: route file %q uses undefined middleware group %q", f.Name, f.Group)
+		}
+		if f.Routes == nil {
+			return fmt.Errorf("astra/http: route file %q has no Routes", f.Name)
+		}
+	}
+	for _, f := range files {
+		r.Group(f.Prefix, func(sub *Router) {
+			sub.middleware = append(sub.middleware, r.groups[f.Group]...)
+			f.Routes(sub)
+		})
+	}
+	return nil
+}
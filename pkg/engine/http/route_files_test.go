@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagMiddleware appends name to the X-Through response header.
+func tagMiddleware(name string) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Through", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestLoadRoutes(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+	router.Use(tagMiddleware("global"))
+	router.MiddlewareGroup("web", tagMiddleware("session"), tagMiddleware("csrf"))
+	router.MiddlewareGroup("api", tagMiddleware("throttle"))
+
+	ok := func(c *Context) error { return c.SendString("ok") }
+	require.NoError(t, router.LoadRoutes(
+		RouteFile{Name: "pages", Group: "web", Routes: func(r *Router) { r.Get("/", ok) }},
+		RouteFile{Name: "api", Prefix: "/api", Group: "api", Routes: func(r *Router) { r.Get("/users", ok) }},
+		RouteFile{Name: "health", Routes: func(r *Router) { r.Get("/health", ok) }},
+	))
+
+	through := func(path string) []string {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		return rec.Header().Values("X-Through")
+	}
+	assert.Equal(t, []string{"global", "session", "csrf"}, through("/"))
+	assert.Equal(t, []string{"global", "throttle"}, through("/api/users"))
+	assert.Equal(t, []string{"global"}, through("/health"))
+}
+
+func TestLoadRoutes_Errors(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+	noop := func(*Router) {}
+
+	err := router.LoadRoutes(RouteFile{Name: "admin", Group: "wbe", Routes: noop})
+	assert.ErrorContains(t, err, `undefined middleware group "wbe"`)
+
+	err = router.LoadRoutes(RouteFile{Name: "users", Routes: noop}, RouteFile{Name: "users", Routes: noop})
+	assert.ErrorContains(t, err, "registered twice")
+}
+
+func TestRegisteredRouteFiles(t *testing.T) {
+	routeFilesMu.Lock()
+	saved := routeFiles
+	routeFiles = nil
+	routeFilesMu.Unlock()
+	t.Cleanup(func() {
+		routeFilesMu.Lock()
+		routeFiles = saved
+		routeFilesMu.Unlock()
+	})
+
+	RegisterRouteFile(RouteFile{Name: "users", Routes: func(r *Router) {
+		r.Get("/users", func(c *Context) error { return c.SendString("users") })
+	}})
+	RegisterRouteFile(RouteFile{Name: "admin", Prefix: "/admin", Routes: func(r *Router) {
+		r.Get("/", func(c *Context) error { return c.SendString("admin") })
+	}})
+	files := RegisteredRouteFiles()
+	require.Len(t, files, 2)
+	assert.Equal(t, "admin", files[0].Name)
+
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+	require.NoError(t, router.LoadRoutes())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/", nil))
+	assert.Equal(t, "admin", rec.Body.String())
+}
@@ -1,13 +1,14 @@
 package http
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 
+	"github.com/shauryagautam/Astra/pkg/engine"
 	"github.com/shauryagautam/Astra/pkg/engine/config"
+	astraerrors "github.com/shauryagautam/Astra/pkg/errors"
 )
 
 // Router represents the Astra HTTP router.
@@ -18,27 +19,140 @@ type Router struct {
 	Logger     *slog.Logger
 	middleware []MiddlewareFunc
 	prefix     string
+	// groups holds the named middleware groups of MiddlewareGroup, shared
+	// with every group and With router.
+	groups map[string][]MiddlewareFunc
+
+	// JSON controls how Context.JSON encodes responses for this router.
+	JSON JSONConfig
+	// JSONLimits bounds the JSON request bodies the Context reads.
+	// NewRouter sets DefaultJSONLimits; the zero value has no limits.
+	JSONLimits JSONLimits
+	// Envelope shapes the API responses of the Context helpers such as
+	// Success and ErrorWithDetails. Nil means DefaultEnvelope.
+	Envelope Envelope
+	// Files controls Range handling in SendFile, Download and SendContent.
+	Files FileConfig
+	// Views renders the templates of Context.Render. ViewProvider sets it
+	// to a TemplateEngine over VIEWS_DIR.
+	Views engine.ViewEngine
+	// Serializers are the media types Context.Negotiate offers besides
+	// JSON, XML and MessagePack; add to them with RegisterSerializer.
+	Serializers []Serializer
+	// Clients controls how Context identifies the client: trusted proxies
+	// and the geo-IP resolver.
+	Clients ClientConfig
+	// Cookies holds the defaults and encryption key for
+	// Context.SetCookieValue. NewRouter derives the key from APP_KEY and
+	// marks cookies Secure in production.
+	Cookies CookieJar
+	// ErrorHandler renders errors returned by handlers, and handler panics
+	// as a *PanicError. When nil, errors are logged and answered with a bare
+	// 500, and panics are left to the Recover middleware. Set it before
+	// registering routes (groups copy it), typically to
+	// NewInteractiveErrorHandler(...).Handle.
+	ErrorHandler func(c *Context, err error)
+	// TraceMiddleware records the middleware each request passes through,
+	// with timings and the point where the chain stopped, in the
+	// X-Astra-Middleware response header and Context.MiddlewareTrace. It
+	// defaults to Config.App.Debug and applies to routes registered after
+	// it is set.
+	TraceMiddleware bool
 }
 
 // NewRouter creates a new Astra HTTP router.
 func NewRouter(cfg *config.AstraConfig, logger *slog.Logger) *Router {
+	jsonCfg := JSONConfig{Charset: "utf-8"}
+	debug := false
+	var clients ClientConfig
+	cookies := NewCookieJar("")
+	if cfg != nil {
+		debug = cfg.App.Debug
+		jsonCfg.Pretty = debug
+		clients.TrustedProxies = ParsePrefixes(cfg.App.TrustedProxies...)
+		cookies = NewCookieJar(cfg.App.Key)
+		cookies.Secure = cfg.App.Environment == "production"
+	}
 	return &Router{
-		mux:        http.NewServeMux(),
-		Config:     cfg,
-		Logger:     logger,
-		middleware: make([]MiddlewareFunc, 0),
+		mux:             http.NewServeMux(),
+		Config:          cfg,
+		Logger:          logger,
+		JSON:            jsonCfg,
+		JSONLimits:      DefaultJSONLimits,
+		Clients:         clients,
+		Cookies:         cookies,
+		TraceMiddleware: debug,
+		middleware:      make([]MiddlewareFunc, 0),
+		groups:          make(map[string][]MiddlewareFunc),
 	}
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	c := NewContext(w, req)
+	c.json = r.JSON
+	c.jsonLimits = r.JSONLimits
+	c.env = r.Envelope
+	c.files = r.Files
+	c.serializers = r.Serializers
+	c.ViewEngine = r.Views
+	c.client = r.Clients
+	c.cookies = r.Cookies
 	defer c.release()
 
-	// Inject into request context
-	ctx := context.WithValue(req.Context(), astraContextKey, c)
-	
-	// Delegate to the multiplexer with the injected context
-	r.mux.ServeHTTP(w, req.WithContext(ctx))
+	// Delegate to the multiplexer with the request NewContext bound to c
+	c.resp.unrouted = true
+	r.mux.ServeHTTP(c.Writer, c.Request)
+	if c.resp.missed != 0 {
+		r.routeMissed(c)
+	}
+}
+
+// routeMissed answers a request no route matched with an HTTPError coded
+// E_ROUTE_NOT_FOUND, or E_METHOD_NOT_ALLOWED when the path has routes for
+// other methods. It goes through the ErrorHandler; without one, API
+// requests get the JSON error envelope and others plain text.
+func (r *Router) routeMissed(c *Context) {
+	status := c.resp.missed
+	c.resp.missed = 0
+	// Drop the headers of the mux's plain-text answer; Allow stays.
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("X-Content-Type-Options")
+
+	err := &HTTPError{
+		Status:  status,
+		Message: "Cannot " + c.Request.Method + " " + c.Request.URL.Path,
+		Code:    string(astraerrors.ErrCodeRouteNotFound),
+	}
+	if status == http.StatusMethodNotAllowed {
+		err.Message = "Method " + c.Request.Method + " is not allowed on " + c.Request.URL.Path
+		err.Code = string(astraerrors.ErrCodeMethodNotAllowed)
+	}
+	switch {
+	case r.ErrorHandler != nil:
+		r.ErrorHandler(c, err)
+	case isAPIRequest(c.Request):
+		_ = c.ErrorWithDetails(err.Status, err.Code, err.Message, nil)
+	default:
+		_ = c.Error(err.Status, err.Message)
+	}
+}
+
+// routed marks the request as matched, so the 404s and 405s h answers
+// itself reach the client unchanged.
+func routed(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if hw, ok := w.(*hookWriter); ok {
+			hw.unrouted = false
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+func (r *Router) logger() *slog.Logger {
+	if r.Logger == nil {
+		return slog.Default()
+	}
+	return r.Logger
 }
 
 func (r *Router) Get(path string, h HandlerFunc) {
@@ -69,7 +183,7 @@ func (r *Router) Handle(method, path string, h http.Handler) {
 	}
 	pattern := method + " " + fullPath
 	
-	r.mux.Handle(pattern, h)
+	r.mux.Handle(pattern, routed(h))
 }
 
 // HandleContext registers an Astra-style HandlerFunc.
@@ -98,14 +212,30 @@ func (r *Router) HandleContext(method, path string, h HandlerFunc) {
 		}
 
 		c.Request = req
+		if r.ErrorHandler != nil {
+			defer r.recoverToError(c)
+		}
 
 		if err := h(c); err != nil {
-			logger := r.Logger
-			if logger == nil {
-				logger = slog.Default()
+			c.err = err
+			aborted := IsAbort(err)
+			if aborted {
+				r.logger().Debug("request aborted", "error", err, "path", req.URL.Path)
+			} else {
+				r.logger().Error("handler error", "error", err, "path", req.URL.Path)
+			}
+			if r.ErrorHandler != nil {
+				if !c.written {
+					r.ErrorHandler(c, err)
+				}
+				return
 			}
-			logger.Error("handler error", "error", err, "path", req.URL.Path)
 			if !c.written {
+				if aborted {
+					status, message := abortStatus(err)
+					_ = c.Error(status, message)
+					return
+				}
 				w.WriteHeader(http.StatusInternalServerError)
 				fmt.Fprintf(w, "INTERNAL_SERVER_ERROR")
 			}
@@ -115,20 +245,36 @@ func (r *Router) HandleContext(method, path string, h HandlerFunc) {
 	// 2. Wrap with the middleware chain (right-to-left)
 	var final http.Handler = finalHandler
 	for i := len(r.middleware) - 1; i >= 0; i-- {
-		final = r.middleware[i](final)
+		if r.TraceMiddleware {
+			final = traceMiddleware(i, r.middleware[i], final)
+		} else {
+			final = r.middleware[i](final)
+		}
 	}
 
 	// 3. Register on the mux
-	r.mux.Handle(pattern, final)
+	r.mux.Handle(pattern, routed(final))
 }
 
 func (r *Router) Group(prefix string, fn func(*Router)) {
 	sub := &Router{
-		mux:        r.mux,
-		Config:     r.Config,
-		Logger:     r.Logger,
-		middleware: append([]MiddlewareFunc{}, r.middleware...),
-		prefix:     r.prefix + prefix,
+		mux:          r.mux,
+		Config:       r.Config,
+		Logger:       r.Logger,
+		JSON:         r.JSON,
+		JSONLimits:   r.JSONLimits,
+		Envelope:     r.Envelope,
+		Files:        r.Files,
+		Serializers:  r.Serializers,
+		Views:        r.Views,
+		Clients:      r.Clients,
+		Cookies:      r.Cookies,
+		ErrorHandler: r.ErrorHandler,
+		middleware:   append([]MiddlewareFunc{}, r.middleware...),
+		prefix:       r.prefix + prefix,
+		groups:       r.groups,
+
+		TraceMiddleware: r.TraceMiddleware,
 	}
 	fn(sub)
 }
@@ -136,3 +282,13 @@ func (r *Router) Group(prefix string, fn func(*Router)) {
 func (r *Router) Use(m MiddlewareFunc) {
 	r.middleware = append(r.middleware, m)
 }
+
+// With returns a router for the same routes with extra middleware appended,
+// for middleware that guards a single route:
+//
+//	r.With(http.ValidateRequest[CreateUserInput](v)).Post("/users", uc.Store)
+func (r *Router) With(mws ...MiddlewareFunc) *Router {
+	sub := *r
+	sub.middleware = append(append([]MiddlewareFunc{}, r.middleware...), mws...)
+	return &sub
+}
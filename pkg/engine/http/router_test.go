@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -69,3 +70,82 @@ func TestRouter_ErrorHandling(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, rec.Code)
 	require.Contains(t, rec.Body.String(), "INTERNAL_SERVER_ERROR")
 }
+
+func passThrough(next http.Handler) http.Handler { return next }
+
+func denyAll(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+}
+
+func TestRouter_TraceMiddleware(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{App: config.AppConfig{Debug: true}}, slog.Default())
+	router.Use(passThrough)
+	var trace []MiddlewareStep
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			trace = FromRequest(r).MiddlewareTrace()
+		})
+	})
+	router.Get("/open", func(c *Context) error { return c.SendString("ok") })
+	router.With(denyAll).Get("/closed", func(c *Context) error { return c.SendString("ok") })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/closed", nil))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	header := rec.Header().Get(MiddlewareTraceHeader)
+	require.Regexp(t, `^http\.passThrough;dur=[\d.]+, http\.TestRouter_TraceMiddleware;dur=[\d.]+, http\.denyAll;dur=[\d.]+;stop$`, header)
+	require.Len(t, trace, 3)
+	require.True(t, trace[0].CalledNext)
+	require.False(t, trace[2].CalledNext)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/open", nil))
+	require.NotContains(t, rec.Header().Get(MiddlewareTraceHeader), "stop")
+	require.Len(t, trace, 2)
+
+	quiet := NewRouter(&config.AstraConfig{}, slog.Default())
+	quiet.Use(passThrough)
+	quiet.Get("/", func(c *Context) error { return c.SendString("ok") })
+	rec = httptest.NewRecorder()
+	quiet.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Empty(t, rec.Header().Get(MiddlewareTraceHeader))
+}
+
+func TestRouter_ContextValuesFromGoroutines(t *testing.T) {
+	router := NewRouter(&config.AstraConfig{}, slog.Default())
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			FromRequest(r).Set("tenant", "acme")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	var detached []any
+	router.Get("/", func(c *Context) error {
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = c.Get("tenant")
+				_ = c.Ctx().Value("tenant")
+			}()
+			c.Set(fmt.Sprintf("k%d", i), i)
+		}
+		wg.Wait()
+
+		ctx := c.Detach()
+		c.Set("late", true)
+		detached = []any{ctx.Value("tenant"), ctx.Value("k7"), ctx.Value("late")}
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []any{"acme", 7, true}, detached)
+}
@@ -0,0 +1,27 @@
+package http
+
+// Scoped returns a handler that builds a controller for each request with
+// newController and calls method on it. Use it for controllers that hold
+// per-request state, such as the current user, so that state is
+// passed to the constructor instead of looked up inside every handler:
+//
+//	func NewInvoiceController(repo *InvoiceRepo) func(*http.Context) (*InvoiceController, error) {
+//		return func(c *http.Context) (*InvoiceController, error) {
+//			return &InvoiceController{repo: repo, user: c.AuthUser()}, nil
+//		}
+//	}
+//
+//	r.Get("/invoices", http.Scoped(NewInvoiceController(repo), (*InvoiceController).Index))
+//
+// An error from newController is returned from the handler, reaching the
+// router's error handler like any handler error. generate:routes emits
+// these calls for controllers whose New function returns such a factory.
+func Scoped[T any](newController func(*Context) (T, error), method func(T, *Context) error) HandlerFunc {
+	return func(c *Context) error {
+		controller, err := newController(c)
+		if err != nil {
+			return err
+		}
+		return method(controller, c)
+	}
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type greeter struct{ name string }
+
+func (g *greeter) Hello(c *Context) error { return c.SendString("hello " + g.name) }
+
+func TestScoped(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+
+	built := 0
+	newGreeter := func(c *Context) (*greeter, error) {
+		built++
+		if c.Param("name") == "nobody" {
+			return nil, errors.New("no such user")
+		}
+		return &greeter{name: c.Param("name")}, nil
+	}
+	router.Get("/hello/{name}", Scoped(newGreeter, (*greeter).Hello))
+
+	for _, name := range []string{"ada", "grace"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello/"+name, nil))
+		assert.Equal(t, "hello "+name, rec.Body.String())
+	}
+	assert.Equal(t, 2, built, "a controller is built for each request")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello/nobody", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
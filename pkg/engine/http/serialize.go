@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/xml"
+	nethttp "net/http"
+	"slices"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Serializer writes response bodies in one media type for Negotiate.
+type Serializer interface {
+	// MediaType is the type the serializer writes and the Accept range it
+	// answers, such as "application/xml".
+	MediaType() string
+	// Marshal encodes v.
+	Marshal(v any) ([]byte, error)
+}
+
+// NewSerializer returns a Serializer writing mediaType with marshal, for
+// example for JSON:API:
+//
+//	router.RegisterSerializer(http.NewSerializer("application/vnd.api+json", jsonapi.Marshal))
+func NewSerializer(mediaType string, marshal func(v any) ([]byte, error)) Serializer {
+	return funcSerializer{mediaType: mediaType, marshal: marshal}
+}
+
+type funcSerializer struct {
+	mediaType string
+	marshal   func(v any) ([]byte, error)
+}
+
+func (s funcSerializer) MediaType() string             { return s.mediaType }
+func (s funcSerializer) Marshal(v any) ([]byte, error) { return s.marshal(v) }
+
+var (
+	// XMLSerializer writes application/xml with encoding/xml, so values
+	// must be types it can encode: structs and slices, not maps.
+	XMLSerializer = NewSerializer("application/xml", func(v any) ([]byte, error) {
+		body, err := xml.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), body...), nil
+	})
+	// MsgpackSerializer writes application/msgpack.
+	MsgpackSerializer = NewSerializer("application/msgpack", msgpack.Marshal)
+)
+
+// RegisterSerializer adds s to the media types Negotiate offers, after
+// JSON, XML and MessagePack. A serializer for a media type already offered,
+// including the built-in ones, replaces it. Register serializers before the
+// server starts.
+func (r *Router) RegisterSerializer(s Serializer) {
+	r.Serializers = slices.DeleteFunc(slices.Clone(r.Serializers), func(old Serializer) bool {
+		return strings.EqualFold(old.MediaType(), s.MediaType())
+	})
+	r.Serializers = append(r.Serializers, s)
+}
+
+// Negotiate sends data in the format the client's Accept header ranks
+// highest: JSON, which follows Router.JSON and wins ties and requests
+// without an Accept header, XML, MessagePack or a type added with
+// Router.RegisterSerializer:
+//
+//	return c.Negotiate(report)
+//
+// It answers Vary: Accept so caches keep the formats apart. When the client
+// accepts none of them, Negotiate sends nothing and returns a 406
+// HTTPError.
+func (c *Context) Negotiate(data any, status ...int) error {
+	if c.written {
+		return nil
+	}
+	c.Writer.Header().Add("Vary", "Accept")
+
+	offers := []Serializer{nil, XMLSerializer, MsgpackSerializer}
+	for _, s := range c.serializers {
+		i := slices.IndexFunc(offers, func(o Serializer) bool {
+			return strings.EqualFold(offerType(o), s.MediaType())
+		})
+		if i < 0 {
+			offers = append(offers, s)
+		} else {
+			offers[i] = s
+		}
+	}
+	types := make([]string, len(offers))
+	for i, o := range offers {
+		types[i] = offerType(o)
+	}
+
+	chosen := c.Accepts(types...)
+	if chosen == "" {
+		return &HTTPError{
+			Status:  nethttp.StatusNotAcceptable,
+			Message: "acceptable types: " + strings.Join(types, ", "),
+		}
+	}
+	s := offers[slices.Index(types, chosen)]
+	if s == nil {
+		return c.JSON(data, status...)
+	}
+
+	body, err := s.Marshal(data)
+	if err != nil {
+		return err
+	}
+	code := nethttp.StatusOK
+	if c.status != 0 {
+		code = c.status
+	}
+	if len(status) > 0 {
+		code = status[0]
+	}
+	c.Writer.Header().Set("Content-Type", s.MediaType())
+	c.Writer.WriteHeader(code)
+	c.written = true
+	_, err = c.Writer.Write(body)
+	return err
+}
+
+// offerType is the media type of an offer; nil stands for Context.JSON.
+func offerType(s Serializer) string {
+	if s == nil {
+		return "application/json"
+	}
+	return s.MediaType()
+}
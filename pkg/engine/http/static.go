@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticConfig controls how Router.Static serves files.
+type StaticConfig struct {
+	// Index lists the files served for a directory, tried in order, such as
+	// "index.html". Without it a directory is a 404.
+	Index []string
+	// MaxAge sets Cache-Control: public, max-age for the files. 0 sends no
+	// Cache-Control, leaving freshness to Last-Modified.
+	MaxAge time.Duration
+	// Immutable lists path prefixes, relative to the served directory, of
+	// files whose names change with their content, such as "build/" for
+	// the asset pipeline's fingerprinted output. They are cached for a year
+	// and marked immutable, so browsers never revalidate them.
+	Immutable []string
+	// Dotfiles serves files and directories whose names start with a dot,
+	// such as .well-known. They are 404s by default, as they tend to be
+	// configuration that was not meant to be public.
+	Dotfiles bool
+}
+
+// immutableMaxAge is how long Immutable files are cached: a year, the
+// longest period browsers honour.
+const immutableMaxAge = 365 * 24 * time.Hour
+
+// Static serves the files of fsys under prefix, for example public assets
+// from disk or from a go:embed FS (see package bundle):
+//
+//	router.Static("/public", res.Dir("public"))
+//	router.Static("/", os.DirFS("public"), http.StaticConfig{
+//		Index:     []string{"index.html"},
+//		MaxAge:    time.Hour,
+//		Immutable: []string{"build/"},
+//	})
+//
+// Directory listings are never served, and paths that would leave fsys,
+// such as ones with ".." segments, are 404s. Requests go straight to the
+// files, bypassing the router's middleware.
+func (r *Router) Static(prefix string, fsys fs.FS, cfg ...StaticConfig) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		prefix = ""
+	}
+	s := &staticFiles{fsys: fsys}
+	if len(cfg) > 0 {
+		s.cfg = cfg[0]
+	}
+	r.Handle(http.MethodGet, prefix+"/{path...}", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.serve(w, req, req.PathValue("path"))
+	}))
+}
+
+// staticFiles serves the files of one Router.Static mount.
+type staticFiles struct {
+	fsys fs.FS
+	cfg  StaticConfig
+}
+
+func (s *staticFiles) serve(w http.ResponseWriter, req *http.Request, name string) {
+	dir := name == "" || strings.HasSuffix(name, "/")
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		name = "."
+	}
+	if !s.allowed(name) {
+		http.NotFound(w, req)
+		return
+	}
+
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	if info.IsDir() {
+		index := s.index(name)
+		if index == "" {
+			http.NotFound(w, req)
+			return
+		}
+		if !dir {
+			// Relative links in the index resolve against the directory.
+			target := req.URL.Path + "/"
+			if req.URL.RawQuery != "" {
+				target += "?" + req.URL.RawQuery
+			}
+			http.Redirect(w, req, target, http.StatusMovedPermanently)
+			return
+		}
+		name = index
+	} else if dir {
+		http.NotFound(w, req)
+		return
+	}
+
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	defer f.Close()
+	if info, err = f.Stat(); err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	if cc := s.cacheControl(name); cc != "" {
+		w.Header().Set("Cache-Control", cc)
+	}
+	http.ServeContent(w, req, info.Name(), info.ModTime(), content)
+}
+
+// allowed reports whether name may be served: a valid fs.FS path, which
+// has no ".." or empty segments, without backslashes, which some file
+// systems read as separators, and without dotfiles unless they are on.
+func (s *staticFiles) allowed(name string) bool {
+	if !fs.ValidPath(name) || strings.Contains(name, `\`) {
+		return false
+	}
+	if s.cfg.Dotfiles || name == "." {
+		return true
+	}
+	return !slices.ContainsFunc(strings.Split(name, "/"), func(seg string) bool {
+		return strings.HasPrefix(seg, ".")
+	})
+}
+
+// index returns the first Index file present in dir, or "".
+func (s *staticFiles) index(dir string) string {
+	for _, index := range s.cfg.Index {
+		name := index
+		if dir != "." {
+			name = dir + "/" + index
+		}
+		if info, err := fs.Stat(s.fsys, name); err == nil && !info.IsDir() {
+			return name
+		}
+	}
+	return ""
+}
+
+// cacheControl returns the Cache-Control value for name, or "".
+func (s *staticFiles) cacheControl(name string) string {
+	for _, prefix := range s.cfg.Immutable {
+		if strings.HasPrefix(name, strings.TrimPrefix(prefix, "/")) {
+			return "public, max-age=" + strconv.Itoa(int(immutableMaxAge.Seconds())) + ", immutable"
+		}
+	}
+	if s.cfg.MaxAge > 0 {
+		return "public, max-age=" + strconv.Itoa(int(s.cfg.MaxAge.Seconds()))
+	}
+	return ""
+}
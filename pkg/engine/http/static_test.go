@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterStatic(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+	router.Static("/public", fstest.MapFS{
+		"css/app.css": {Data: []byte("body{}")},
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/public/css/app.css")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "body{}", rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/css")
+
+	assert.Equal(t, http.StatusNotFound, get("/public/css/").Code)
+	assert.Equal(t, http.StatusNotFound, get("/public/css").Code)
+	assert.Equal(t, http.StatusNotFound, get("/public/missing.js").Code)
+}
+
+func TestRouterStaticConfig(t *testing.T) {
+	app := NewTestApp()
+	router := NewRouter(app.Config(), app.Logger())
+	router.Static("/", fstest.MapFS{
+		"index.html":           {Data: []byte("home")},
+		"docs/index.html":      {Data: []byte("docs")},
+		"build/app-3f2a.js":    {Data: []byte("js")},
+		"robots.txt":           {Data: []byte("robots")},
+		".env":                 {Data: []byte("SECRET=1")},
+		".well-known/security": {Data: []byte("contact")},
+	}, StaticConfig{Index: []string{"index.html"}, MaxAge: time.Hour, Immutable: []string{"build/"}})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "home", rec.Body.String())
+
+	rec = get("/docs?v=2")
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/docs/?v=2", rec.Header().Get("Location"))
+	assert.Equal(t, "docs", get("/docs/").Body.String())
+
+	rec = get("/build/app-3f2a.js")
+	assert.Equal(t, "js", rec.Body.String())
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "public, max-age=3600", get("/robots.txt").Header().Get("Cache-Control"))
+
+	assert.Equal(t, http.StatusNotFound, get("/.env").Code)
+	assert.Equal(t, http.StatusNotFound, get("/.well-known/security").Code)
+	assert.Equal(t, http.StatusNotFound, get("/robots.txt/").Code)
+
+	// Traversal attempts that reach the handler undecoded by the mux.
+	for _, name := range []string{"../secret", "docs/../../secret", `..\secret`, "docs//index.html"} {
+		rec := httptest.NewRecorder()
+		(&staticFiles{fsys: fstest.MapFS{}}).serve(rec, httptest.NewRequest(http.MethodGet, "/", nil), name)
+		assert.Equal(t, http.StatusNotFound, rec.Code, name)
+	}
+
+	dotfiles := &staticFiles{fsys: fstest.MapFS{".well-known/security": {Data: []byte("contact")}}, cfg: StaticConfig{Dotfiles: true}}
+	rec = httptest.NewRecorder()
+	dotfiles.serve(rec, httptest.NewRequest(http.MethodGet, "/", nil), ".well-known/security")
+	assert.Equal(t, "contact", rec.Body.String())
+}
@@ -0,0 +1,236 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
+	"github.com/shauryagautam/Astra/pkg/validate"
+)
+
+// FileRules are the checks File and Files run on an upload. The zero value
+// accepts any file.
+type FileRules struct {
+	// MaxSize is the largest accepted size in bytes; 0 accepts any size.
+	MaxSize int64
+	// Extnames lists the accepted extensions without the dot, such as "jpg"
+	// or "png". Empty accepts any extension.
+	Extnames []string
+}
+
+// UploadedFile is a file sent in a multipart request, as returned by File.
+type UploadedFile struct {
+	// FieldName is the form field the file was sent in.
+	FieldName string
+	// ClientName is the file name the client sent. It is not safe to use
+	// as a path: MoveTo to a directory picks a random name instead.
+	ClientName string
+	// Extension is the lowercase extension of ClientName without the dot,
+	// or "" when it has none.
+	Extension string
+	// ContentType is the MIME type the client sent for the file.
+	ContentType string
+	// Size is the length of the file in bytes.
+	Size int64
+	// Errors lists the rules the file broke, such as "must be one of: jpg,
+	// png". It is empty for a valid file.
+	Errors []string
+	// FilePath is where MoveTo stored the file on its disk, or "" before.
+	FilePath string
+
+	header *multipart.FileHeader
+	// extChecked is set once Extension passed an Extnames allow-list.
+	extChecked bool
+}
+
+// File returns the file uploaded in the form field name, checked against
+// rules, or nil when the request has none. The multipart body is parsed
+// with the rest of the input, so File and Input can be mixed freely:
+//
+//	avatar := c.File("avatar", http.FileRules{MaxSize: 2 << 20, Extnames: []string{"jpg", "png"}})
+//	if avatar == nil {
+//		return c.BadRequestError("avatar is required")
+//	}
+//	if err := avatar.MoveTo(c.Ctx(), disk, "avatars/"); err != nil {
+//		return err // a broken rule is a 422 with the field's errors
+//	}
+//
+// When the field holds several files, File returns the first.
+func (c *Context) File(name string, rules ...FileRules) *UploadedFile {
+	files := c.Files(name, rules...)
+	if len(files) == 0 {
+		return nil
+	}
+	return files[0]
+}
+
+// Files returns every file uploaded in the form field name, each checked
+// against rules, or nil when the request has none.
+func (c *Context) Files(name string, rules ...FileRules) []*UploadedFile {
+	c.parsedInput()
+	form := c.Request.MultipartForm
+	if form == nil || len(form.File[name]) == 0 {
+		return nil
+	}
+	files := make([]*UploadedFile, len(form.File[name]))
+	for i, h := range form.File[name] {
+		f := newUploadedFile(name, h)
+		for _, r := range rules {
+			f.Validate(r)
+		}
+		files[i] = f
+	}
+	return files
+}
+
+func newUploadedFile(field string, h *multipart.FileHeader) *UploadedFile {
+	return &UploadedFile{
+		FieldName:   field,
+		ClientName:  h.Filename,
+		Extension:   strings.ToLower(strings.TrimPrefix(path.Ext(h.Filename), ".")),
+		ContentType: h.Header.Get("Content-Type"),
+		Size:        h.Size,
+		header:      h,
+	}
+}
+
+// Validate checks the file against rules, adding what it breaks to Errors.
+// It reports whether the file is still valid.
+func (f *UploadedFile) Validate(rules FileRules) bool {
+	if rules.MaxSize > 0 && f.Size > rules.MaxSize {
+		f.Errors = append(f.Errors, "must be smaller than "+formatFileSize(rules.MaxSize))
+	}
+	if len(rules.Extnames) > 0 {
+		if slices.ContainsFunc(rules.Extnames, func(ext string) bool {
+			return strings.EqualFold(strings.TrimPrefix(ext, "."), f.Extension)
+		}) {
+			f.extChecked = true
+		} else {
+			f.Errors = append(f.Errors, "must be one of: "+strings.Join(rules.Extnames, ", "))
+		}
+	}
+	return f.IsValid()
+}
+
+// IsValid reports whether the file broke none of its rules.
+func (f *UploadedFile) IsValid() bool {
+	return len(f.Errors) == 0
+}
+
+// Err returns the file's Errors as a *validate.ValidationErrors keyed by
+// FieldName, which the error handler answers with 422, or nil when the
+// file is valid.
+func (f *UploadedFile) Err() error {
+	if f.IsValid() {
+		return nil
+	}
+	ve := validate.NewValidationErrors()
+	for _, msg := range f.Errors {
+		ve.Add(f.FieldName, msg)
+	}
+	return ve
+}
+
+// Open opens the uploaded content for reading.
+func (f *UploadedFile) Open() (multipart.File, error) {
+	return f.header.Open()
+}
+
+// MoveTo stores the file on disk at dest and records it in FilePath. When
+// dest ends in "/" the file goes in that directory under a random name, as
+// ClientName cannot be trusted. The name keeps Extension only when it was
+// checked against an Extnames allow-list; otherwise the extension is that
+// of the content type detected from the file's bytes, so a client cannot
+// store a .html or .php file by naming it so. An invalid file is not
+// stored: MoveTo returns Err instead.
+func (f *UploadedFile) MoveTo(ctx context.Context, disk storage.Storage, dest string) error {
+	if err := f.Err(); err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("upload: open %s: %w", f.FieldName, err)
+	}
+	defer src.Close()
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("upload: read %s: %w", f.FieldName, err)
+	}
+	if dest == "" || strings.HasSuffix(dest, "/") {
+		ext := f.Extension
+		if !f.extChecked {
+			ext = detectedExtension(content)
+		}
+		name, err := randomFileName(ext)
+		if err != nil {
+			return fmt.Errorf("upload: name %s: %w", f.FieldName, err)
+		}
+		dest += name
+	}
+	if err := disk.Put(ctx, dest, content); err != nil {
+		return fmt.Errorf("upload: store %s at %s: %w", f.FieldName, dest, err)
+	}
+	f.FilePath = dest
+	return nil
+}
+
+// commonExtensions names the extension of content types whose
+// mime.ExtensionsByType list does not start with the usual one.
+var commonExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"text/plain": "txt",
+	"text/html":  "html",
+	"audio/mpeg": "mp3",
+	"video/mp4":  "mp4",
+}
+
+// detectedExtension returns the extension of the content type sniffed from
+// content, without the dot, or "" when it has none.
+func detectedExtension(content []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	if ext, ok := commonExtensions[mediaType]; ok {
+		return ext
+	}
+	if mediaType == "application/octet-stream" {
+		return ""
+	}
+	exts, _ := mime.ExtensionsByType(mediaType)
+	if len(exts) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(exts[0], ".")
+}
+
+// randomFileName returns an unguessable file name with the extension ext.
+func randomFileName(ext string) (string, error) {
+	name, err := secure.Hex(16)
+	if err != nil {
+		return "", err
+	}
+	if ext != "" {
+		name += "." + ext
+	}
+	return name, nil
+}
+
+// formatFileSize writes n bytes in the largest whole unit, such as "2MB",
+// falling back to bytes.
+func formatFileSize(n int64) string {
+	for _, u := range []struct {
+		size int64
+		name string
+	}{{1 << 30, "GB"}, {1 << 20, "MB"}, {1 << 10, "KB"}} {
+		if n >= u.size && n%u.size == 0 {
+			return fmt.Sprintf("%d%s", n/u.size, u.name)
+		}
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
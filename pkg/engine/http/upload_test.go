@@ -0,0 +1,105 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/shauryagautam/Astra/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_File(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("name", "Ada"))
+	fw, err := mw.CreateFormFile("avatar", "Me.PNG")
+	require.NoError(t, err)
+	fw.Write([]byte("png-bytes"))
+	for _, name := range []string{"a.pdf", "b.exe"} {
+		fw, err := mw.CreateFormFile("docs", name)
+		require.NoError(t, err)
+		fw.Write([]byte(strings.Repeat("x", 2048)))
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest("POST", "/", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	c := NewContext(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "Ada", c.Input("name"))
+	assert.Nil(t, c.File("missing"))
+
+	avatar := c.File("avatar", FileRules{MaxSize: 1 << 20, Extnames: []string{"jpg", "png"}})
+	require.NotNil(t, avatar)
+	assert.Equal(t, "Me.PNG", avatar.ClientName)
+	assert.Equal(t, "png", avatar.Extension)
+	assert.Equal(t, int64(9), avatar.Size)
+	assert.True(t, avatar.IsValid())
+	assert.NoError(t, avatar.Err())
+
+	disk := storage.NewMemoryStorage()
+	ctx := context.Background()
+	require.NoError(t, avatar.MoveTo(ctx, disk, "avatars/"))
+	assert.True(t, strings.HasPrefix(avatar.FilePath, "avatars/"))
+	assert.True(t, strings.HasSuffix(avatar.FilePath, ".png"))
+	got, err := disk.Get(ctx, avatar.FilePath)
+	require.NoError(t, err)
+	assert.Equal(t, "png-bytes", string(got))
+
+	docs := c.Files("docs", FileRules{MaxSize: 1 << 10, Extnames: []string{"pdf"}})
+	require.Len(t, docs, 2)
+	assert.Equal(t, []string{"must be smaller than 1KB"}, docs[0].Errors)
+	assert.Equal(t, []string{"must be smaller than 1KB", "must be one of: pdf"}, docs[1].Errors)
+
+	err = docs[1].MoveTo(ctx, disk, "docs/b.exe")
+	var ve *validate.ValidationErrors
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, docs[1].Errors, ve.Fields["docs"])
+	exists, _ := disk.Exists(ctx, "docs/b.exe")
+	assert.False(t, exists)
+	assert.Empty(t, docs[1].FilePath)
+
+	assert.Equal(t, "1500 bytes", formatFileSize(1500))
+}
+
+func TestContext_FileWithoutMultipart(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	c := NewContext(httptest.NewRecorder(), req)
+	assert.Nil(t, c.File("avatar"))
+	assert.Nil(t, c.Files("avatar"))
+}
+
+func TestUploadedFile_MoveToWithoutExtnames(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"shell.php":  "<html><script>alert(1)</script></html>",
+		"photo.html": "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16),
+		"blob.exe":   "\x00\x01\x02\x03",
+	} {
+		fw, err := mw.CreateFormFile("upload", name)
+		require.NoError(t, err)
+		fw.Write([]byte(content))
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest("POST", "/", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	c := NewContext(httptest.NewRecorder(), req)
+
+	disk := storage.NewMemoryStorage()
+	want := map[string]string{"shell.php": ".html", "photo.html": ".png", "blob.exe": ""}
+	for _, f := range c.Files("upload", FileRules{MaxSize: 1 << 20}) {
+		require.NoError(t, f.MoveTo(context.Background(), disk, "uploads/"))
+		assert.Equal(t, want[f.ClientName], path.Ext(f.FilePath), f.ClientName)
+	}
+}
@@ -0,0 +1,322 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/shauryagautam/Astra/pkg/identity/keys"
+)
+
+const usageDay = "20060102"
+
+// UsageTracker counts requests per consumer and route in Redis, one bucket
+// per UTC day, for billing and for deciding when a route can be retired.
+// Mount Middleware with router.Use so routes are recorded by their pattern
+// (GET /users/{id}) rather than by path, and serve ExportHandler behind an
+// admin guard:
+//
+//	usage, _ := http.NewUsageTracker(redis)
+//	api.Use(usage.Middleware())
+//	admin.Get("/usage", usage.ExportHandler())
+//
+// For each day it keeps, under <prefix>:usage:<yyyymmdd>:
+//   - c:<consumer> and e:<consumer>, hashes of requests and error responses
+//     (status >= 400) by route;
+//   - r:<route>, a HyperLogLog of the consumers that called the route;
+//   - consumers and routes, the sets of both seen that day.
+type UsageTracker struct {
+	client    goredis.UniversalClient
+	prefix    string
+	consumer  IdentifierFunc
+	retention time.Duration
+	now       func() time.Time
+}
+
+// UsageOption configures a UsageTracker.
+type UsageOption func(*UsageTracker)
+
+// WithUsageConsumer sets how requests are attributed to a consumer. A
+// request it returns "" for is not recorded. The default is ByConsumer.
+func WithUsageConsumer(fn IdentifierFunc) UsageOption {
+	return func(t *UsageTracker) {
+		if fn != nil {
+			t.consumer = fn
+		}
+	}
+}
+
+// WithUsageRetention sets how long daily usage is kept (default 90 days).
+func WithUsageRetention(d time.Duration) UsageOption {
+	return func(t *UsageTracker) {
+		if d > 0 {
+			t.retention = d
+		}
+	}
+}
+
+// WithUsageKeyPrefix overrides the Redis key namespace (default "astra").
+func WithUsageKeyPrefix(prefix string) UsageOption {
+	return func(t *UsageTracker) {
+		t.prefix = strings.Trim(prefix, ": ")
+	}
+}
+
+// NewUsageTracker creates a UsageTracker storing its counters in client.
+func NewUsageTracker(client goredis.UniversalClient, opts ...UsageOption) (*UsageTracker, error) {
+	if client == nil {
+		return nil, fmt.Errorf("astra: Redis is required for usage tracking")
+	}
+	t := &UsageTracker{
+		client:    client,
+		prefix:    "astra",
+		consumer:  ByConsumer,
+		retention: 90 * 24 * time.Hour,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t, nil
+}
+
+// ByConsumer attributes a request to the API key that RequireAPIKey
+// validated ("key:<key id>"), else to the authenticated user
+// ("user:<id>"). Anonymous requests get "".
+func ByConsumer(r *http.Request) string {
+	c := FromRequest(r)
+	if c == nil {
+		return ""
+	}
+	if key, ok := c.Get("api_key").(*keys.APIKey); ok && key != nil {
+		return "key:" + key.KeyID
+	}
+	if id := ByUser(r); id != "" {
+		return "user:" + id
+	}
+	return ""
+}
+
+// Middleware records each request once the response has been sent, so the
+// consumer set by auth middleware further down the chain is known and
+// Redis never delays the response. Recording failures are logged and
+// otherwise ignored.
+func (t *UsageTracker) Middleware() MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := FromRequest(r)
+			if c != nil {
+				c.OnFinished(func(c *Context) {
+					t.record(r, c.ResponseStatus())
+				})
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (t *UsageTracker) record(r *http.Request, status int) {
+	consumer := t.consumer(r)
+	if consumer == "" {
+		return
+	}
+	route := r.Method + " " + routeTemplate(r)
+	now := t.now().UTC()
+	base := t.dayKey(now)
+	expireAt := now.Truncate(24 * time.Hour).Add(24*time.Hour + t.retention)
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), time.Second)
+	defer cancel()
+	_, err := t.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		touched := []string{base + "c:" + consumer, base + "r:" + route, base + "consumers", base + "routes"}
+		pipe.HIncrBy(ctx, touched[0], route, 1)
+		pipe.PFAdd(ctx, touched[1], consumer)
+		pipe.SAdd(ctx, touched[2], consumer)
+		pipe.SAdd(ctx, touched[3], route)
+		if status >= http.StatusBadRequest {
+			touched = append(touched, base+"e:"+consumer)
+			pipe.HIncrBy(ctx, touched[4], route, 1)
+		}
+		for _, key := range touched {
+			pipe.ExpireAt(ctx, key, expireAt)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Default().Warn("astra: recording API usage failed", "error", err, "consumer", consumer, "route", route)
+	}
+}
+
+func (t *UsageTracker) dayKey(day time.Time) string {
+	return t.prefix + ":usage:" + day.Format(usageDay) + ":"
+}
+
+// UsageRecord is the usage of one route by one consumer on one day.
+type UsageRecord struct {
+	Date     string `json:"date"`
+	Consumer string `json:"consumer"`
+	Route    string `json:"route"`
+	Requests int64  `json:"requests"`
+	Errors   int64  `json:"errors"`
+}
+
+// RouteUsage is the number of distinct consumers that called a route over
+// a period, an estimate within about 1%.
+type RouteUsage struct {
+	Route     string `json:"route"`
+	Consumers int64  `json:"consumers"`
+}
+
+// Usage returns the usage recorded between the days of from and to,
+// inclusive, ordered by date, consumer and route. A non-empty consumer
+// limits it to that consumer.
+func (t *UsageTracker) Usage(ctx context.Context, from, to time.Time, consumer string) ([]UsageRecord, error) {
+	var records []UsageRecord
+	for _, day := range usageDays(from, to) {
+		base := t.dayKey(day)
+		consumers := []string{consumer}
+		if consumer == "" {
+			var err error
+			if consumers, err = t.client.SMembers(ctx, base+"consumers").Result(); err != nil {
+				return nil, err
+			}
+			slices.Sort(consumers)
+		}
+		for _, cons := range consumers {
+			requests, err := t.client.HGetAll(ctx, base+"c:"+cons).Result()
+			if err != nil {
+				return nil, err
+			}
+			failures, err := t.client.HGetAll(ctx, base+"e:"+cons).Result()
+			if err != nil {
+				return nil, err
+			}
+			routes := make([]string, 0, len(requests))
+			for route := range requests {
+				routes = append(routes, route)
+			}
+			slices.Sort(routes)
+			for _, route := range routes {
+				n, _ := strconv.ParseInt(requests[route], 10, 64)
+				e, _ := strconv.ParseInt(failures[route], 10, 64)
+				records = append(records, UsageRecord{
+					Date:     day.Format(time.DateOnly),
+					Consumer: cons,
+					Route:    route,
+					Requests: n,
+					Errors:   e,
+				})
+			}
+		}
+	}
+	return records, nil
+}
+
+// RouteConsumers returns, for every route called between the days of from
+// and to, how many distinct consumers called it, ordered by route. Routes
+// nobody called are missing from it.
+func (t *UsageTracker) RouteConsumers(ctx context.Context, from, to time.Time) ([]RouteUsage, error) {
+	perRoute := map[string][]string{}
+	for _, day := range usageDays(from, to) {
+		base := t.dayKey(day)
+		routes, err := t.client.SMembers(ctx, base+"routes").Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range routes {
+			perRoute[route] = append(perRoute[route], base+"r:"+route)
+		}
+	}
+	out := make([]RouteUsage, 0, len(perRoute))
+	for route, dayKeys := range perRoute {
+		// PFCOUNT over several days counts the union, so a consumer seen
+		// every day is counted once.
+		n, err := t.client.PFCount(ctx, dayKeys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, RouteUsage{Route: route, Consumers: n})
+	}
+	slices.SortFunc(out, func(a, b RouteUsage) int { return strings.Compare(a.Route, b.Route) })
+	return out, nil
+}
+
+// ExportHandler serves the recorded usage as JSON, or as CSV with
+// ?format=csv. ?from= and ?to= (YYYY-MM-DD) select the days, the last 30 by
+// default, and ?consumer= a single consumer:
+//
+//	{"from": "2026-09-17", "to": "2026-10-16",
+//	 "usage": [{"date": ..., "consumer": ..., "route": ..., "requests": ..., "errors": ...}],
+//	 "routes": [{"route": "GET /v1/users", "consumers": 12}]}
+func (t *UsageTracker) ExportHandler() HandlerFunc {
+	return func(c *Context) error {
+		to := t.now().UTC()
+		from := to.AddDate(0, 0, -29)
+		var err error
+		if v := c.Query("to"); v != "" {
+			if to, err = time.Parse(time.DateOnly, v); err != nil {
+				return c.BadRequestError("to must be a date (YYYY-MM-DD)")
+			}
+		}
+		if v := c.Query("from"); v != "" {
+			if from, err = time.Parse(time.DateOnly, v); err != nil {
+				return c.BadRequestError("from must be a date (YYYY-MM-DD)")
+			}
+		}
+		if from.After(to) {
+			return c.BadRequestError("from must not be after to")
+		}
+		if to.Sub(from) > t.retention {
+			return c.BadRequestError("the period is longer than the usage retention")
+		}
+
+		ctx := c.Ctx()
+		records, err := t.Usage(ctx, from, to, c.Query("consumer"))
+		if err != nil {
+			return err
+		}
+		if c.Query("format") == "csv" {
+			c.Writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			c.Writer.Header().Set("Content-Disposition", `attachment; filename="usage.csv"`)
+			w := csv.NewWriter(c.Writer)
+			_ = w.Write([]string{"date", "consumer", "route", "requests", "errors"})
+			for _, r := range records {
+				_ = w.Write([]string{r.Date, r.Consumer, r.Route, strconv.FormatInt(r.Requests, 10), strconv.FormatInt(r.Errors, 10)})
+			}
+			w.Flush()
+			return w.Error()
+		}
+		routes, err := t.RouteConsumers(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		if records == nil {
+			records = []UsageRecord{}
+		}
+		return c.JSON(map[string]any{
+			"from":   from.Format(time.DateOnly),
+			"to":     to.Format(time.DateOnly),
+			"usage":  records,
+			"routes": routes,
+		})
+	}
+}
+
+// usageDays returns the UTC days from the day of from to the day of to.
+func usageDays(from, to time.Time) []time.Time {
+	day := from.UTC().Truncate(24 * time.Hour)
+	last := to.UTC().Truncate(24 * time.Hour)
+	var days []time.Time
+	for !day.After(last) {
+		days = append(days, day)
+		day = day.AddDate(0, 0, 1)
+	}
+	return days
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/identity/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTracker(t *testing.T) {
+	server, client := setupRateLimitClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	usage, err := NewUsageTracker(client)
+	require.NoError(t, err)
+	day := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	usage.now = func() time.Time { return day }
+	// ExpireAt is absolute, so Redis must share the tracker's clock.
+	server.SetTime(day)
+
+	router := NewRouter(&config.AstraConfig{}, nil)
+	// Stands in for RequireAPIKey: the consumer is known only after the
+	// usage middleware has run.
+	router.Use(usage.Middleware())
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := r.Header.Get("X-Key"); id != "" {
+				FromRequest(r).Set("api_key", &keys.APIKey{KeyID: id})
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	router.Get("/v1/users/{id}", func(c *Context) error {
+		if c.Param("id") == "0" {
+			return c.NotFoundError("User")
+		}
+		return c.SendString("ok")
+	})
+	router.Get("/usage", usage.ExportHandler())
+
+	call := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if key != "" {
+			req.Header.Set("X-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	call("/v1/users/1", "acme")
+	call("/v1/users/2", "acme")
+	call("/v1/users/0", "acme")
+	call("/v1/users/1", "globex")
+	call("/v1/users/1", "") // anonymous, not recorded
+
+	records, err := usage.Usage(context.Background(), day, day, "")
+	require.NoError(t, err)
+	assert.Equal(t, []UsageRecord{
+		{Date: "2026-10-16", Consumer: "key:acme", Route: "GET /v1/users/{id}", Requests: 3, Errors: 1},
+		{Date: "2026-10-16", Consumer: "key:globex", Route: "GET /v1/users/{id}", Requests: 1},
+	}, records)
+
+	routes, err := usage.RouteConsumers(context.Background(), day.AddDate(0, 0, -1), day)
+	require.NoError(t, err)
+	assert.Equal(t, []RouteUsage{{Route: "GET /v1/users/{id}", Consumers: 2}}, routes)
+
+	ttl := server.TTL("astra:usage:20261016:c:key:acme")
+	assert.Greater(t, ttl, 90*24*time.Hour)
+
+	rec := call("/usage?consumer=key:globex", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		From  string        `json:"from"`
+		Usage []UsageRecord `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "2026-09-17", body.From)
+	assert.Len(t, body.Usage, 1)
+
+	rec = call("/usage?format=csv&from=2026-10-16&to=2026-10-16", "")
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 3, strings.Count(rec.Body.String(), "\n"))
+
+	assert.Equal(t, http.StatusBadRequest, call("/usage?from=yesterday", "").Code)
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"strings"
+)
+
+// Device classes reported in UserAgent.Device.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// UserAgent is a parsed User-Agent header. Fields that cannot be told from
+// the header are empty.
+type UserAgent struct {
+	Raw            string `json:"raw"`
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version"`
+	OS             string `json:"os"`
+	OSVersion      string `json:"os_version"`
+	Device         string `json:"device"`
+}
+
+// IsBot reports whether the header is a crawler, a monitor or an HTTP
+// library rather than a browser.
+func (u UserAgent) IsBot() bool { return u.Device == DeviceBot }
+
+// IsMobile reports whether the device is a phone or a tablet.
+func (u UserAgent) IsMobile() bool { return u.Device == DeviceMobile || u.Device == DeviceTablet }
+
+// String describes the client for people, as in "Chrome 126 on macOS 14.5",
+// which suits a list of active sessions.
+func (u UserAgent) String() string {
+	browser := u.Browser
+	if browser == "" {
+		browser = "Unknown browser"
+	}
+	if major, _, _ := strings.Cut(u.BrowserVersion, "."); major != "" {
+		browser += " " + major
+	}
+	if u.OS == "" {
+		return browser
+	}
+	platform := u.OS
+	if u.OSVersion != "" {
+		platform += " " + u.OSVersion
+	}
+	return browser + " on " + platform
+}
+
+// botMarkers are lower-cased substrings that identify automated clients.
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "headless", "curl/", "wget/",
+	"python-requests", "go-http-client", "okhttp", "axios/", "postman",
+}
+
+// browserMarkers are checked in order, since most browsers also claim to be
+// the ones they derive from: Edge says Chrome, Chrome says Safari.
+var browserMarkers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Trident/", "Internet Explorer"},
+}
+
+// windowsVersions maps Windows NT versions to release names. Windows 11
+// still reports NT 10.0.
+var windowsVersions = map[string]string{
+	"10.0": "10", "6.3": "8.1", "6.2": "8", "6.1": "7",
+}
+
+// ParseUserAgent extracts the browser, operating system and device class
+// from a User-Agent header. It knows the common browsers and platforms; it
+// is not a full device database.
+func ParseUserAgent(raw string) UserAgent {
+	u := UserAgent{Raw: raw}
+	if strings.TrimSpace(raw) == "" {
+		return u
+	}
+	lower := strings.ToLower(raw)
+	for _, m := range botMarkers {
+		if strings.Contains(lower, m) {
+			u.Device = DeviceBot
+			break
+		}
+	}
+
+	u.Browser, u.BrowserVersion = parseBrowser(raw)
+	u.OS, u.OSVersion = parseOS(raw)
+
+	if u.Device == "" {
+		switch {
+		case strings.Contains(raw, "iPad") || strings.Contains(lower, "tablet") ||
+			(u.OS == "Android" && !strings.Contains(raw, "Mobile")):
+			u.Device = DeviceTablet
+		case strings.Contains(raw, "Mobi") || strings.Contains(raw, "iPhone"):
+			u.Device = DeviceMobile
+		default:
+			u.Device = DeviceDesktop
+		}
+	}
+	return u
+}
+
+func parseBrowser(raw string) (string, string) {
+	for _, m := range browserMarkers {
+		if v, ok := versionAfter(raw, m.token); ok {
+			if m.name == "Internet Explorer" {
+				v, _ = versionAfter(raw, "rv:")
+			}
+			return m.name, v
+		}
+	}
+	if v, ok := versionAfter(raw, "MSIE "); ok {
+		return "Internet Explorer", v
+	}
+	if strings.Contains(raw, "Safari/") {
+		v, _ := versionAfter(raw, "Version/")
+		return "Safari", v
+	}
+	return "", ""
+}
+
+func parseOS(raw string) (string, string) {
+	switch {
+	case strings.Contains(raw, "Windows NT "):
+		v, _ := versionAfter(raw, "Windows NT ")
+		return "Windows", windowsVersions[v]
+	case strings.Contains(raw, "iPad"):
+		v, _ := versionAfter(raw, "CPU OS ")
+		return "iPadOS", strings.ReplaceAll(v, "_", ".")
+	case strings.Contains(raw, "iPhone"):
+		v, _ := versionAfter(raw, "iPhone OS ")
+		return "iOS", strings.ReplaceAll(v, "_", ".")
+	case strings.Contains(raw, "Mac OS X"):
+		v, _ := versionAfter(raw, "Mac OS X ")
+		return "macOS", strings.ReplaceAll(v, "_", ".")
+	case strings.Contains(raw, "Android"):
+		v, _ := versionAfter(raw, "Android ")
+		return "Android", v
+	case strings.Contains(raw, "CrOS"):
+		return "ChromeOS", ""
+	case strings.Contains(raw, "Linux"):
+		return "Linux", ""
+	}
+	return "", ""
+}
+
+// versionAfter returns the version that follows token in raw, up to the next
+// space, semicolon or parenthesis.
+func versionAfter(raw, token string) (string, bool) {
+	i := strings.Index(raw, token)
+	if i < 0 {
+		return "", false
+	}
+	rest := raw[i+len(token):]
+	if end := strings.IndexAny(rest, " ;)"); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest, true
+}
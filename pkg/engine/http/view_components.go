@@ -0,0 +1,229 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"path"
+	"strings"
+
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+)
+
+// Slot is content passed to a component, built in templates with the slot
+// helper. The slot named "default" is available to the component as .Slot,
+// every slot as .Slots.<name>.
+type Slot struct {
+	Name    string
+	Content template.HTML
+}
+
+// Helper registers fn as a template function available to every view and
+// component, replacing any function of the same name. Register helpers
+// before the first render: cached templates are dropped.
+//
+//	engine.Helper("money", func(cents int) string { return fmt.Sprintf("$%d.%02d", cents/100, cents%100) })
+func (e *TemplateEngine) Helper(name string, fn any) *TemplateEngine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.funcMap[name] = fn
+	clear(e.templates)
+	clear(e.components)
+	return e
+}
+
+// Route names a path pattern for the route helper. Patterns use the
+// router's {param} syntax:
+//
+//	engine.Route("users.show", "/users/{id}")
+//
+//	<a href="{{ route "users.show" .User.ID }}">
+func (e *TemplateEngine) Route(name, pattern string) *TemplateEngine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.routes[name] = pattern
+	return e
+}
+
+// viewHelpers returns the template functions bound to the engine.
+func (e *TemplateEngine) viewHelpers() template.FuncMap {
+	return template.FuncMap{
+		"component": e.renderComponent,
+		"partial":   e.renderPartial,
+		"slot":      newSlot,
+		"route":     e.routeURL,
+		"auth":      authUser,
+	}
+}
+
+// renderComponent renders components/<name> with the given arguments, and
+// backs the component helper:
+//
+//	{{ component "button" (dict "label" "Save" "variant" "primary") }}
+//	{{ component "card" (dict "title" .Title) (slot "default" (partial "posts/body" .)) (slot "footer" "Posted today") }}
+//
+// Maps are merged into the component's data as props; slots are exposed
+// as .Slot and .Slots.
+func (e *TemplateEngine) renderComponent(name string, args ...any) (template.HTML, error) {
+	data := map[string]any{}
+	slots := map[string]template.HTML{}
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case map[string]any:
+			for k, val := range v {
+				data[k] = val
+			}
+		case Slot:
+			slots[v.Name] = v.Content
+		case nil:
+		default:
+			return "", fmt.Errorf("views: component %q: unsupported argument of type %T", name, arg)
+		}
+	}
+	data["Slots"] = slots
+	data["Slot"] = slots["default"]
+
+	tmpl, err := e.getComponent(path.Join(e.componentDir, name))
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("views: failed to execute component %q: %w", name, err)
+	}
+	return template.HTML(buf.String()), nil // #nosec G203 -- output of html/template
+}
+
+// renderPartial renders a view without its layouts, for the partial
+// helper: {{ partial "posts/_meta" .Post }}.
+func (e *TemplateEngine) renderPartial(name string, data any) (template.HTML, error) {
+	tmpl, err := e.getComponent(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("views: failed to execute partial %q: %w", name, err)
+	}
+	return template.HTML(buf.String()), nil // #nosec G203 -- output of html/template
+}
+
+// getComponent returns a cached or freshly compiled template for a view
+// rendered on its own, without layouts.
+func (e *TemplateEngine) getComponent(name string) (*template.Template, error) {
+	e.mu.RLock()
+	v, ok := e.components[name]
+	e.mu.RUnlock()
+	if ok && e.fresh(v) {
+		return v.tmpl, nil
+	}
+
+	v, err := e.parse(path.Base(name+e.extension), []string{name})
+	if err != nil {
+		return nil, fmt.Errorf("views: failed to compile %q: %w", name, err)
+	}
+
+	e.mu.Lock()
+	e.components[name] = v
+	e.mu.Unlock()
+	return v.tmpl, nil
+}
+
+// newSlot backs the slot helper. Strings are escaped; template.HTML, such
+// as the output of component or partial, is kept as is.
+func newSlot(name string, content any) (Slot, error) {
+	switch v := content.(type) {
+	case template.HTML:
+		return Slot{Name: name, Content: v}, nil
+	case string:
+		return Slot{Name: name, Content: template.HTML(template.HTMLEscapeString(v))}, nil // #nosec G203 -- escaped
+	case fmt.Stringer:
+		return Slot{Name: name, Content: template.HTML(template.HTMLEscapeString(v.String()))}, nil // #nosec G203 -- escaped
+	default:
+		return Slot{}, fmt.Errorf("views: slot %q: unsupported content of type %T", name, content)
+	}
+}
+
+// routeURL builds the path of a named route for the route helper. Values
+// fill the {param} segments in order, or by name when a single map is
+// given, and are path-escaped.
+func (e *TemplateEngine) routeURL(name string, params ...any) (string, error) {
+	e.mu.RLock()
+	pattern, ok := e.routes[name]
+	e.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("views: unknown route %q", name)
+	}
+
+	var named map[string]any
+	if len(params) == 1 {
+		named, _ = params[0].(map[string]any)
+	}
+
+	var b strings.Builder
+	next := 0
+	for rest := pattern; ; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("views: route %q: malformed pattern %q", name, pattern)
+		}
+		b.WriteString(rest[:open])
+		param := rest[open+1 : open+end]
+		wildcard := strings.HasSuffix(param, "...")
+		param = strings.TrimSuffix(param, "...")
+		rest = rest[open+end+1:]
+
+		var value any
+		if named != nil {
+			v, ok := named[param]
+			if !ok {
+				return "", fmt.Errorf("views: route %q: missing parameter %q", name, param)
+			}
+			value = v
+		} else {
+			if next >= len(params) {
+				return "", fmt.Errorf("views: route %q: missing parameter %q", name, param)
+			}
+			value = params[next]
+			next++
+		}
+
+		s := fmt.Sprint(value)
+		if wildcard {
+			segments := strings.Split(s, "/")
+			for i, seg := range segments {
+				segments[i] = url.PathEscape(seg)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(s))
+		}
+	}
+	if named == nil && next < len(params) {
+		return "", fmt.Errorf("views: route %q: %d unused parameters", name, len(params)-next)
+	}
+	return b.String(), nil
+}
+
+// authUser backs the auth helper: it returns the authenticated user of the
+// request rendering the view, or nil.
+//
+//	{{ with auth . }}Signed in as {{ .Email }}{{ end }}
+func authUser(data any) *identityclaims.AuthClaims {
+	if m, ok := data.(map[string]any); ok {
+		if c, ok := m["Context"].(interface {
+			AuthUser() *identityclaims.AuthClaims
+		}); ok {
+			return c.AuthUser()
+		}
+	}
+	return nil
+}
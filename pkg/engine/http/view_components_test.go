@@ -0,0 +1,109 @@
+package http
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderView(t *testing.T, e *TemplateEngine, name string, data any) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, e.Render(&buf, name, data))
+	return strings.TrimSpace(buf.String())
+}
+
+func TestTemplateEngine_ComponentsAndSlots(t *testing.T) {
+	e := NewTemplateEngine("", WithFS(fstest.MapFS{
+		"components/button.html": {Data: []byte(`<button class="btn-{{.variant}}">{{.label}}</button>`)},
+		"components/card.html":   {Data: []byte(`<div><h2>{{.title}}</h2>{{.Slot}}<footer>{{.Slots.footer}}</footer></div>`)},
+		"posts/_body.html":       {Data: []byte(`<p>{{.Body}}</p>`)},
+		"posts/show.html": {Data: []byte(`{{ component "card" (dict "title" .Title)
+			(slot "default" (partial "posts/_body" .))
+			(slot "footer" (component "button" (dict "label" "<Edit>" "variant" "primary"))) }}`)},
+	}))
+
+	out := renderView(t, e, "posts/show", map[string]any{"Title": "Hi", "Body": "a & b"})
+	assert.Equal(t, `<div><h2>Hi</h2><p>a &amp; b</p><footer><button class="btn-primary">&lt;Edit&gt;</button></footer></div>`, out)
+}
+
+func TestTemplateEngine_StringSlotsAreEscaped(t *testing.T) {
+	e := NewTemplateEngine("", WithFS(fstest.MapFS{
+		"components/box.html": {Data: []byte(`<div>{{.Slot}}</div>`)},
+		"page.html":           {Data: []byte(`{{ component "box" (slot "default" .) }}`)},
+	}))
+	assert.Equal(t, `<div>&lt;script&gt;</div>`, renderView(t, e, "page", "<script>"))
+}
+
+func TestTemplateEngine_NestedLayouts(t *testing.T) {
+	e := NewTemplateEngine("", WithFS(fstest.MapFS{
+		"layouts/app.html":   {Data: []byte(`<html>{{block "body" .}}{{end}}</html>`)},
+		"layouts/admin.html": {Data: []byte(`{{/* layout "layouts/app" */}}{{define "body"}}<nav>admin</nav>{{block "content" .}}{{end}}{{end}}`)},
+		"users/index.html":   {Data: []byte(`{{/* layout "layouts/admin" */}}{{define "content"}}<p>{{.}}</p>{{end}}`)},
+		"loop/a.html":        {Data: []byte(`{{/* layout "loop/b" */}}`)},
+		"loop/b.html":        {Data: []byte(`{{/* layout "loop/a" */}}`)},
+	}))
+
+	assert.Equal(t, `<html><nav>admin</nav><p>users</p></html>`, renderView(t, e, "users/index", "users"))
+
+	err := e.Render(&bytes.Buffer{}, "loop/a", nil)
+	assert.ErrorContains(t, err, "layout cycle")
+}
+
+func TestTemplateEngine_Helpers(t *testing.T) {
+	e := NewTemplateEngine("", WithFS(fstest.MapFS{
+		"links.html": {Data: []byte(`{{ route "users.show" .ID }} {{ route "files" (dict "path" "a b/c") }} {{ asset "app.css" }} {{ shout "hi" }}`)},
+		"nav.html":   {Data: []byte(`{{ with auth . }}{{ .Email }}{{ else }}guest{{ end }}`)},
+		"bad.html":   {Data: []byte(`{{ route "nope" }}`)},
+	}))
+	e.Route("users.show", "/users/{id}").Route("files", "/files/{path...}")
+	e.Helper("shout", strings.ToUpper)
+
+	assert.Equal(t, `/users/42 /files/a%20b/c /static/app.css HI`, renderView(t, e, "links", map[string]any{"ID": 42}))
+	assert.ErrorContains(t, e.Render(&bytes.Buffer{}, "bad", nil), `unknown route "nope"`)
+
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "guest", renderView(t, e, "nav", map[string]any{"Context": c}))
+	c.SetAuthUser(&identityclaims.AuthClaims{UserID: "1", Email: "ada@example.com"})
+	assert.Equal(t, "ada@example.com", renderView(t, e, "nav", map[string]any{"Context": c}))
+}
+
+func TestRouter_Views(t *testing.T) {
+	router := NewRouter(nil, nil)
+	router.Views = NewTemplateEngine("", WithFS(fstest.MapFS{
+		"layouts/app.html": {Data: []byte(`<main>{{block "body" .}}{{end}}</main>`)},
+		"users/index.html": {Data: []byte(`{{/* layout "layouts/app" */}}{{define "body"}}{{range .Users}}<p>{{.}}</p>{{end}}{{end}}`)},
+	}))
+	router.Get("/users", func(c *Context) error {
+		return c.Render("users/index", map[string]any{"Users": []string{"Ada", "Grace"}})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "<main><p>Ada</p><p>Grace</p></main>", strings.TrimSpace(rec.Body.String()))
+}
+
+func TestRender_KeepsHandlerOldAndErrors(t *testing.T) {
+	router := NewRouter(nil, nil)
+	router.Views = NewTemplateEngine("", WithFS(fstest.MapFS{
+		"form.html": {Data: []byte(`{{index .Old "email"}} {{index .Errors "email"}}`)},
+	}))
+	router.Get("/form", func(c *Context) error {
+		return c.Render("form", map[string]any{
+			"Old":    map[string]string{"email": "ada@example.com"},
+			"Errors": map[string][]string{"email": {"taken"}},
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/form", nil))
+	assert.Equal(t, "ada@example.com [taken]", strings.TrimSpace(rec.Body.String()))
+}
@@ -0,0 +1,306 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ViteConfig configures the Vite integration of the template engine.
+type ViteConfig struct {
+	// ManifestPath is the manifest written by `vite build` (with
+	// build.manifest enabled) or by webpack-manifest-plugin. Default:
+	// "public/build/.vite/manifest.json".
+	ManifestPath string
+	// FS, when set, is read instead of the disk, e.g. an embed.FS holding
+	// the build output.
+	FS fs.FS
+	// PublicPath is the URL prefix the build output is served under.
+	// Default: "/build/".
+	PublicPath string
+	// Dev serves entries from the Vite dev server, with HMR, instead of the
+	// manifest.
+	Dev bool
+	// DevServerURL is the address of the Vite dev server. Default:
+	// "http://localhost:5173".
+	DevServerURL string
+}
+
+// viteChunk is an entry of a Vite manifest.
+type viteChunk struct {
+	File    string   `json:"file"`
+	Src     string   `json:"src"`
+	IsEntry bool     `json:"isEntry"`
+	CSS     []string `json:"css"`
+	Assets  []string `json:"assets"`
+	Imports []string `json:"imports"`
+}
+
+// Vite resolves asset URLs and renders the tags of Vite entries. In
+// production it reads the build manifest, once, on first use; in
+// development it points at the dev server.
+type Vite struct {
+	cfg ViteConfig
+
+	mu       sync.RWMutex
+	loaded   bool
+	manifest map[string]viteChunk
+	// flat holds webpack-style manifests, which map names straight to URLs.
+	flat map[string]string
+}
+
+// NewVite creates the Vite integration. Register its helpers with
+// WithVite.
+func NewVite(cfg ViteConfig) *Vite {
+	if cfg.ManifestPath == "" {
+		cfg.ManifestPath = "public/build/.vite/manifest.json"
+	}
+	if cfg.PublicPath == "" {
+		cfg.PublicPath = "/build/"
+	}
+	if !strings.HasSuffix(cfg.PublicPath, "/") {
+		cfg.PublicPath += "/"
+	}
+	if cfg.DevServerURL == "" {
+		cfg.DevServerURL = "http://localhost:5173"
+	}
+	cfg.DevServerURL = strings.TrimSuffix(cfg.DevServerURL, "/")
+	return &Vite{cfg: cfg}
+}
+
+// WithVite registers the vite, vite_preload and asset helpers of v:
+//
+//	engine := http.NewTemplateEngine("views", http.WithVite(http.NewVite(http.ViteConfig{
+//	    Dev: app.Env().IsDev(),
+//	})))
+//
+//	<head>{{ vite "src/main.ts" }}</head>
+//	<img src="{{ asset "src/images/logo.png" }}">
+func WithVite(v *Vite) TemplateOption {
+	return func(e *TemplateEngine) {
+		e.funcMap["vite"] = v.Tags
+		e.funcMap["vite_preload"] = v.Preload
+		e.funcMap["asset"] = v.Asset
+	}
+}
+
+// Load reads the manifest. It is called on first use; call it at startup
+// to fail fast on a missing build. Load does nothing in development.
+func (v *Vite) Load() error {
+	if v.cfg.Dev {
+		return nil
+	}
+
+	var data []byte
+	var err error
+	if v.cfg.FS != nil {
+		data, err = fs.ReadFile(v.cfg.FS, v.cfg.ManifestPath)
+	} else {
+		data, err = os.ReadFile(v.cfg.ManifestPath) // #nosec G304 -- path comes from the application config
+	}
+	if err != nil {
+		return fmt.Errorf("vite: failed to read manifest: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("vite: failed to parse manifest: %w", err)
+	}
+	manifest := make(map[string]viteChunk, len(raw))
+	flat := make(map[string]string)
+	for name, value := range raw {
+		if len(value) > 0 && value[0] == '"' {
+			var url string
+			if err := json.Unmarshal(value, &url); err != nil {
+				return fmt.Errorf("vite: failed to parse manifest entry %q: %w", name, err)
+			}
+			flat[name] = url
+			continue
+		}
+		var chunk viteChunk
+		if err := json.Unmarshal(value, &chunk); err != nil {
+			return fmt.Errorf("vite: failed to parse manifest entry %q: %w", name, err)
+		}
+		manifest[name] = chunk
+	}
+
+	v.mu.Lock()
+	v.manifest, v.flat, v.loaded = manifest, flat, true
+	v.mu.Unlock()
+	return nil
+}
+
+// ensureLoaded loads the manifest if it has not been loaded yet.
+func (v *Vite) ensureLoaded() error {
+	v.mu.RLock()
+	loaded := v.loaded
+	v.mu.RUnlock()
+	if loaded || v.cfg.Dev {
+		return nil
+	}
+	return v.Load()
+}
+
+// Asset returns the URL of a source file: the dev server's in development,
+// otherwise its hashed name from the manifest. Files missing from the
+// manifest are assumed to be copied as is under PublicPath.
+func (v *Vite) Asset(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if v.cfg.Dev {
+		return v.cfg.DevServerURL + "/" + name
+	}
+	if err := v.ensureLoaded(); err == nil {
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		if chunk, ok := v.manifest[name]; ok {
+			return v.cfg.PublicPath + chunk.File
+		}
+		if url, ok := v.flat[name]; ok {
+			return url
+		}
+	}
+	return v.cfg.PublicPath + name
+}
+
+// Tags renders the tags that load entries. In development these are the
+// Vite client, for HMR, and the entries from the dev server. In production
+// they are the entries' stylesheets, modulepreload links for the chunks
+// they import and their scripts.
+func (v *Vite) Tags(entries ...string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if v.cfg.Dev {
+		fmt.Fprintf(&buf, `<script type="module" src="%s/@vite/client"></script>`, v.cfg.DevServerURL)
+		for _, entry := range entries {
+			writeAssetTag(&buf, v.cfg.DevServerURL+"/"+strings.TrimPrefix(entry, "/"))
+		}
+		return template.HTML(buf.String()), nil // #nosec G203 -- URLs come from the application
+	}
+
+	if err := v.ensureLoaded(); err != nil {
+		return "", err
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	seen := map[string]bool{}
+	var styles, preloads, scripts []string
+	for _, entry := range entries {
+		entry = strings.TrimPrefix(entry, "/")
+		if url, ok := v.flat[entry]; ok {
+			scripts = append(scripts, url)
+			continue
+		}
+		chunk, ok := v.manifest[entry]
+		if !ok {
+			return "", fmt.Errorf("vite: entry %q is not in the manifest", entry)
+		}
+		if path.Ext(chunk.File) != ".css" {
+			scripts = append(scripts, v.cfg.PublicPath+chunk.File)
+		}
+		styles = append(styles, v.chunkStyles(entry, seen)...)
+		preloads = append(preloads, v.chunkImports(chunk, seen)...)
+	}
+
+	for _, href := range styles {
+		fmt.Fprintf(&buf, `<link rel="stylesheet" href="%s">`, href)
+	}
+	for _, href := range preloads {
+		fmt.Fprintf(&buf, `<link rel="modulepreload" href="%s">`, href)
+	}
+	for _, src := range scripts {
+		writeAssetTag(&buf, src)
+	}
+	return template.HTML(buf.String()), nil // #nosec G203 -- URLs come from the manifest
+}
+
+// Preload renders modulepreload links for entries loaded later, e.g. with
+// a dynamic import, so the browser fetches them ahead of time. It renders
+// nothing in development.
+func (v *Vite) Preload(entries ...string) (template.HTML, error) {
+	if v.cfg.Dev {
+		return "", nil
+	}
+	if err := v.ensureLoaded(); err != nil {
+		return "", err
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var buf bytes.Buffer
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		entry = strings.TrimPrefix(entry, "/")
+		chunk, ok := v.manifest[entry]
+		if !ok {
+			return "", fmt.Errorf("vite: entry %q is not in the manifest", entry)
+		}
+		for _, href := range v.chunkStyles(entry, seen) {
+			fmt.Fprintf(&buf, `<link rel="preload" as="style" href="%s">`, href)
+		}
+		var hrefs []string
+		if path.Ext(chunk.File) != ".css" {
+			hrefs = append(hrefs, v.cfg.PublicPath+chunk.File)
+		}
+		hrefs = append(hrefs, v.chunkImports(chunk, seen)...)
+		for _, href := range hrefs {
+			fmt.Fprintf(&buf, `<link rel="modulepreload" href="%s">`, href)
+		}
+	}
+	return template.HTML(buf.String()), nil // #nosec G203 -- URLs come from the manifest
+}
+
+// chunkStyles returns the stylesheets of a chunk and of the chunks it
+// imports, skipping those already in seen.
+func (v *Vite) chunkStyles(name string, seen map[string]bool) []string {
+	chunk := v.manifest[name]
+	var styles []string
+	if path.Ext(chunk.File) == ".css" && !seen[chunk.File] {
+		seen[chunk.File] = true
+		styles = append(styles, v.cfg.PublicPath+chunk.File)
+	}
+	for _, css := range chunk.CSS {
+		if !seen[css] {
+			seen[css] = true
+			styles = append(styles, v.cfg.PublicPath+css)
+		}
+	}
+	for _, imp := range chunk.Imports {
+		if !seen["chunk:"+imp] {
+			seen["chunk:"+imp] = true
+			styles = append(styles, v.chunkStyles(imp, seen)...)
+		}
+	}
+	return styles
+}
+
+// chunkImports returns the files of the chunks imported, directly or not,
+// by chunk, skipping those already in seen.
+func (v *Vite) chunkImports(chunk viteChunk, seen map[string]bool) []string {
+	var files []string
+	for _, imp := range chunk.Imports {
+		dep, ok := v.manifest[imp]
+		if !ok || seen[dep.File] {
+			continue
+		}
+		seen[dep.File] = true
+		files = append(files, v.cfg.PublicPath+dep.File)
+		files = append(files, v.chunkImports(dep, seen)...)
+	}
+	return files
+}
+
+// writeAssetTag writes the tag that loads src: a stylesheet link for CSS,
+// a module script otherwise.
+func writeAssetTag(buf *bytes.Buffer, src string) {
+	if path.Ext(src) == ".css" {
+		fmt.Fprintf(buf, `<link rel="stylesheet" href="%s">`, src)
+		return
+	}
+	fmt.Fprintf(buf, `<script type="module" src="%s"></script>`, src)
+}
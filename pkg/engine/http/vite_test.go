@@ -0,0 +1,65 @@
+package http
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const viteManifest = `{
+  "src/main.ts": {"file": "assets/main-4f2a.js", "src": "src/main.ts", "isEntry": true, "css": ["assets/main-9c1d.css"], "imports": ["_vendor-77aa.js"]},
+  "_vendor-77aa.js": {"file": "assets/vendor-77aa.js", "css": ["assets/vendor-01bc.css"]},
+  "src/admin.ts": {"file": "assets/admin-3e3e.js", "isEntry": true, "imports": ["_vendor-77aa.js"]},
+  "src/styles.css": {"file": "assets/styles-5d5d.css", "isEntry": true},
+  "src/logo.png": {"file": "assets/logo-aa11.png"}
+}`
+
+func TestVite_ProductionTags(t *testing.T) {
+	v := NewVite(ViteConfig{FS: fstest.MapFS{"manifest.json": {Data: []byte(viteManifest)}}, ManifestPath: "manifest.json"})
+
+	tags, err := v.Tags("src/main.ts", "src/admin.ts", "src/styles.css")
+	require.NoError(t, err)
+	assert.Equal(t, ``+
+		`<link rel="stylesheet" href="/build/assets/main-9c1d.css">`+
+		`<link rel="stylesheet" href="/build/assets/vendor-01bc.css">`+
+		`<link rel="stylesheet" href="/build/assets/styles-5d5d.css">`+
+		`<link rel="modulepreload" href="/build/assets/vendor-77aa.js">`+
+		`<script type="module" src="/build/assets/main-4f2a.js"></script>`+
+		`<script type="module" src="/build/assets/admin-3e3e.js"></script>`, string(tags))
+
+	preload, err := v.Preload("src/admin.ts")
+	require.NoError(t, err)
+	assert.Equal(t, `<link rel="preload" as="style" href="/build/assets/vendor-01bc.css">`+
+		`<link rel="modulepreload" href="/build/assets/admin-3e3e.js">`+
+		`<link rel="modulepreload" href="/build/assets/vendor-77aa.js">`, string(preload))
+
+	assert.Equal(t, "/build/assets/logo-aa11.png", v.Asset("src/logo.png"))
+	assert.Equal(t, "/build/robots.txt", v.Asset("robots.txt"))
+
+	_, err = v.Tags("src/missing.ts")
+	assert.ErrorContains(t, err, `entry "src/missing.ts" is not in the manifest`)
+}
+
+func TestVite_DevServer(t *testing.T) {
+	v := NewVite(ViteConfig{Dev: true, DevServerURL: "http://localhost:5173/"})
+
+	tags, err := v.Tags("src/main.ts", "src/styles.css")
+	require.NoError(t, err)
+	assert.Equal(t, `<script type="module" src="http://localhost:5173/@vite/client"></script>`+
+		`<script type="module" src="http://localhost:5173/src/main.ts"></script>`+
+		`<link rel="stylesheet" href="http://localhost:5173/src/styles.css">`, string(tags))
+	assert.Equal(t, "http://localhost:5173/src/logo.png", v.Asset("/src/logo.png"))
+}
+
+func TestVite_WebpackManifestAndEngine(t *testing.T) {
+	views := fstest.MapFS{
+		"manifest.json": {Data: []byte(`{"app.js": "/dist/app.81ab.js", "app.css": "/dist/app.c0de.css"}`)},
+		"page.html":     {Data: []byte(`{{ vite "app.css" "app.js" }}|{{ asset "app.js" }}`)},
+	}
+	e := NewTemplateEngine("", WithFS(views), WithVite(NewVite(ViteConfig{FS: views, ManifestPath: "manifest.json"})))
+
+	assert.Equal(t, `<link rel="stylesheet" href="/dist/app.c0de.css"><script type="module" src="/dist/app.81ab.js"></script>|/dist/app.81ab.js`,
+		renderView(t, e, "page", nil))
+}
@@ -20,6 +20,13 @@ type ViewEngine interface {
 	Render(wr io.Writer, name string, data any) error
 }
 
+// JSONEncoder serializes response bodies. It is satisfied by the encoders in
+// the engine/json package and lets applications swap in a faster codec.
+// Implementations must be safe for concurrent use.
+type JSONEncoder interface {
+	Marshal(v any) ([]byte, error)
+	MarshalIndent(v any, prefix, indent string) ([]byte, error)
+}
 
 // i18n
 type Translator interface {
@@ -71,6 +78,12 @@ type HealthProvider interface {
 	CheckHealth(ctx context.Context) error
 }
 
+// HealthDetailer is optionally implemented by a HealthProvider to attach
+// diagnostic data (pool sizes, lag, queue depth) to the health report.
+type HealthDetailer interface {
+	HealthDetails() map[string]any
+}
+
 // HealthCheckFunc is a function type that implements HealthProvider.
 type HealthCheckFunc func(context.Context) error
 
@@ -0,0 +1,16 @@
+package json
+
+import "encoding/json"
+
+// StdEncoder encodes with the standard library. It is the default encoder for
+// HTTP responses because its output (HTML escaping, sorted map keys) is the
+// most predictable.
+type StdEncoder struct{}
+
+// Marshal implements engine.JSONEncoder.
+func (StdEncoder) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// MarshalIndent implements engine.JSONEncoder.
+func (StdEncoder) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
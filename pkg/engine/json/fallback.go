@@ -35,3 +35,7 @@ func NewDecoder(r io.Reader) *json.Decoder {
 type RawMessage = json.RawMessage
 type Encoder = json.Encoder
 type Decoder = json.Decoder
+
+// FastEncoder falls back to the standard library on platforms Sonic does not
+// support, so applications can select it unconditionally.
+type FastEncoder struct{ StdEncoder }
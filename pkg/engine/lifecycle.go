@@ -0,0 +1,70 @@
+package engine
+
+import "github.com/shauryagautam/Astra/pkg/engine/event"
+
+// Lifecycle event names. App dispatches them on its own emitter (see
+// App.Events), so an extension can hook into boot from its provider's
+// Register method without changes to the application's bootstrap code:
+//
+//	func (p *AuditProvider) Register(a *engine.App) error {
+//		a.Events().OnFunc(engine.EventAppReady, func(ctx context.Context, e event.Event) error {
+//			return p.announce(ctx, e.(engine.AppReady).App)
+//		})
+//		return nil
+//	}
+//
+// An error returned by a listener of a boot event aborts Boot. Errors from
+// AppShuttingDown listeners are returned by Shutdown with the others.
+const (
+	EventAppBooting         = "app.booting"
+	EventProviderRegistered = "app.provider_registered"
+	EventAppBooted          = "app.booted"
+	EventRoutesCommitted    = "app.routes_committed"
+	EventAppReady           = "app.ready"
+	EventAppShuttingDown    = "app.shutting_down"
+)
+
+// AppBooting is dispatched when Boot starts, before any provider runs.
+// Listeners registered on App.Events before Boot see it.
+type AppBooting struct{ App *App }
+
+// ProviderRegistered is dispatched after a provider's Register phase.
+type ProviderRegistered struct {
+	App      *App
+	Provider Provider
+}
+
+// AppBooted is dispatched once every provider has completed its Boot phase.
+type AppBooted struct{ App *App }
+
+// RoutesCommitted is dispatched after the Ready phase and the manifest
+// preloads have run, when route and listener registration is finished.
+type RoutesCommitted struct{ App *App }
+
+// AppReady is dispatched at the end of Boot, after the OnStart hooks.
+type AppReady struct{ App *App }
+
+// AppShuttingDown is dispatched when Shutdown starts, before the OnStop
+// hooks.
+type AppShuttingDown struct{ App *App }
+
+func (e AppBooting) Name() string         { return EventAppBooting }
+func (e AppBooting) Data() any            { return e.App }
+func (e ProviderRegistered) Name() string { return EventProviderRegistered }
+func (e ProviderRegistered) Data() any    { return e.Provider }
+func (e AppBooted) Name() string          { return EventAppBooted }
+func (e AppBooted) Data() any             { return e.App }
+func (e RoutesCommitted) Name() string    { return EventRoutesCommitted }
+func (e RoutesCommitted) Data() any       { return e.App }
+func (e AppReady) Name() string           { return EventAppReady }
+func (e AppReady) Data() any              { return e.App }
+func (e AppShuttingDown) Name() string    { return EventAppShuttingDown }
+func (e AppShuttingDown) Data() any       { return e.App }
+
+// Events returns the emitter App dispatches its lifecycle events on.
+func (a *App) Events() *event.Emitter { return a.events }
+
+// dispatch fires ev on the app's emitter with the base context.
+func (a *App) dispatch(ev event.Event) error {
+	return a.events.Dispatch(a.ctx, ev)
+}
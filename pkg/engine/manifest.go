@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// Environment is the kind of process an App runs as. Providers and preloads
+// in a Manifest can be limited to some of them, so a console command does not
+// start the HTTP server and tests do not connect to production services.
+type Environment string
+
+const (
+	EnvironmentWeb     Environment = "web"
+	EnvironmentConsole Environment = "console"
+	EnvironmentTest    Environment = "test"
+)
+
+// Manifest declares everything an application wires into its App, in one
+// place shared by the server, the console binary and tests. It plays the
+// role of AdonisJS's adonisrc file, written in Go so providers stay typed:
+//
+//	// start/astrarc.go
+//	var Manifest = engine.Manifest{
+//		Providers: []engine.ProviderEntry{
+//			{Provider: &providers.DatabaseProvider{}},
+//			{Provider: providers.NewQueueProvider(redisClient, nil), Environments: []engine.Environment{engine.EnvironmentWeb, engine.EnvironmentConsole}},
+//			{Provider: providers.NewHTTPProvider(router), Environments: []engine.Environment{engine.EnvironmentWeb}},
+//		},
+//		Preloads: []engine.Preload{
+//			{Name: "routes", Run: registerRoutes, Environments: []engine.Environment{engine.EnvironmentWeb, engine.EnvironmentTest}},
+//		},
+//		Commands: []func(*engine.App) *cobra.Command{
+//			func(*engine.App) *cobra.Command { return console.QueueWorkCommand(jobs.Register) },
+//		},
+//	}
+//
+// An entry without Environments applies to every environment.
+type Manifest struct {
+	Providers []ProviderEntry
+	// Preloads run in order after every provider is Ready and before the
+	// OnStart hooks.
+	Preloads []Preload
+	// Commands build the console commands; see App.Commands.
+	Commands []func(a *App) *cobra.Command
+	// Packages are third-party Astra packages; see Package.
+	Packages []Package
+}
+
+// ProviderEntry is a provider and the environments it is registered in.
+type ProviderEntry struct {
+	Provider     Provider
+	Environments []Environment
+}
+
+// Preload is a function run during boot, such as route or event listener
+// registration.
+type Preload struct {
+	Name         string
+	Run          func(a *App) error
+	Environments []Environment
+}
+
+func inEnvironment(envs []Environment, env Environment) bool {
+	return len(envs) == 0 || slices.Contains(envs, env)
+}
+
+// SetEnvironment sets the environment Boot filters the manifest with. It must
+// be called before Boot.
+func (a *App) SetEnvironment(env Environment) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.environment = env
+}
+
+// Environment returns the environment set with SetEnvironment. Without one
+// it is EnvironmentTest when APP_ENV is test, and EnvironmentWeb otherwise.
+func (a *App) Environment() Environment {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.environmentLocked()
+}
+
+func (a *App) environmentLocked() Environment {
+	if a.environment != "" {
+		return a.environment
+	}
+	if a.env != nil && a.env.IsTest() {
+		return EnvironmentTest
+	}
+	return EnvironmentWeb
+}
+
+// UseManifest adds m to the application. Its providers and preloads are
+// filtered by Environment when Boot runs, after any provider registered
+// directly with RegisterProvider.
+func (a *App) UseManifest(m Manifest) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.manifests = append(a.manifests, m)
+}
+
+// Commands builds the console commands declared by the manifests and their
+// packages. Mount them on the application's root command in its console
+// entry point.
+func (a *App) Commands() []*cobra.Command {
+	a.mu.RLock()
+	manifests := slices.Clone(a.manifests)
+	a.mu.RUnlock()
+
+	var cmds []*cobra.Command
+	for _, m := range manifests {
+		for _, build := range m.Commands {
+			cmds = append(cmds, build(a))
+		}
+		for _, pkg := range m.Packages {
+			for _, build := range pkg.Commands {
+				cmds = append(cmds, build(a))
+			}
+		}
+	}
+	return cmds
+}
+
+// applyManifests registers the providers of every manifest and package that
+// apply to the current environment and returns the matching preloads. The caller holds
+// a.mu.
+func (a *App) applyManifests() []Preload {
+	env := a.environmentLocked()
+	var preloads []Preload
+	for _, m := range a.manifests {
+		for _, entry := range m.Providers {
+			if inEnvironment(entry.Environments, env) {
+				a.providers = append(a.providers, entry.Provider)
+			}
+		}
+		for _, pkg := range m.Packages {
+			if pkg.Provider != nil && inEnvironment(pkg.Environments, env) {
+				a.providers = append(a.providers, pkg.Provider)
+			}
+		}
+		for _, p := range m.Preloads {
+			if inEnvironment(p.Environments, env) {
+				preloads = append(preloads, p)
+			}
+		}
+	}
+	return preloads
+}
+
+func runPreloads(a *App, preloads []Preload) error {
+	for _, p := range preloads {
+		if err := p.Run(a); err != nil {
+			return fmt.Errorf("preload %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
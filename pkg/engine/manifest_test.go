@@ -0,0 +1,75 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingProvider struct {
+	engine.BaseProvider
+	name string
+	log  *[]string
+}
+
+func (p *recordingProvider) Boot(a *engine.App) error {
+	*p.log = append(*p.log, "boot "+p.name)
+	// Providers may register hooks while the app boots.
+	a.OnStart(func(context.Context) error {
+		*p.log = append(*p.log, "start "+p.name)
+		return nil
+	})
+	return nil
+}
+
+func TestApp_UseManifest(t *testing.T) {
+	var log []string
+	manifest := engine.Manifest{
+		Providers: []engine.ProviderEntry{
+			{Provider: &recordingProvider{name: "db", log: &log}},
+			{Provider: &recordingProvider{name: "http", log: &log}, Environments: []engine.Environment{engine.EnvironmentWeb}},
+			{Provider: &recordingProvider{name: "queue", log: &log}, Environments: []engine.Environment{engine.EnvironmentConsole}},
+		},
+		Preloads: []engine.Preload{
+			{Name: "routes", Run: func(*engine.App) error { log = append(log, "preload routes"); return nil }, Environments: []engine.Environment{engine.EnvironmentWeb}},
+			{Name: "events", Run: func(*engine.App) error { log = append(log, "preload events"); return nil }},
+		},
+		Commands: []func(*engine.App) *cobra.Command{
+			func(*engine.App) *cobra.Command { return &cobra.Command{Use: "greet"} },
+		},
+	}
+
+	app := engine.New(&config.AstraConfig{}, &config.Config{}, slog.Default())
+	app.UseManifest(manifest)
+	assert.Equal(t, engine.EnvironmentWeb, app.Environment())
+
+	require.NoError(t, app.Boot())
+	assert.Equal(t, []string{"boot db", "boot http", "preload routes", "preload events", "start db", "start http"}, log)
+	require.Len(t, app.Commands(), 1)
+	assert.Equal(t, "greet", app.Commands()[0].Use)
+	log = nil
+	require.NoError(t, app.Boot(), "booting twice is a no-op")
+	assert.Empty(t, log, "providers are not booted again")
+
+	log = nil
+	console := engine.New(&config.AstraConfig{}, &config.Config{}, slog.Default())
+	console.SetEnvironment(engine.EnvironmentConsole)
+	console.UseManifest(manifest)
+	require.NoError(t, console.Boot())
+	assert.Equal(t, []string{"boot db", "boot queue", "preload events", "start db", "start queue"}, log)
+}
+
+func TestApp_PreloadError(t *testing.T) {
+	app := engine.New(&config.AstraConfig{}, &config.Config{}, slog.Default())
+	app.UseManifest(engine.Manifest{Preloads: []engine.Preload{
+		{Name: "routes", Run: func(*engine.App) error { return errors.New("boom") }},
+	}})
+	assert.EqualError(t, app.Boot(), "preload routes: boom")
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Package is a reusable Astra package shipped as its own Go module. By
+// convention the module exports a constructor returning its Package, and
+// the application lists it in its manifest:
+//
+//	// in github.com/acme/astra-billing
+//	//go:embed config migrations views
+//	var files embed.FS
+//
+//	func Package() engine.Package {
+//		sub := func(dir string) fs.FS { f, _ := fs.Sub(files, dir); return f }
+//		return engine.Package{
+//			Name:       "billing",
+//			Provider:   &Provider{},
+//			Config:     sub("config"),
+//			Migrations: sub("migrations"),
+//			Views:      sub("views"),
+//		}
+//	}
+//
+//	// in the application's start/astrarc.go
+//	var Manifest = engine.Manifest{
+//		Packages: []engine.Package{billing.Package()},
+//	}
+//
+// Boot registers the provider like any manifest provider, App.Commands
+// includes the package's commands, and `astra vendor:publish` copies its
+// resources into the application so they can be edited and committed.
+type Package struct {
+	// Name namespaces the published config and views. It should be short
+	// and unique among the application's packages.
+	Name string
+	// Provider is optional; Environments limits it like a ProviderEntry.
+	Provider     Provider
+	Environments []Environment
+	Commands     []func(a *App) *cobra.Command
+	// Config, Migrations and Views are the publishable resources. Any of
+	// them may be nil.
+	Config     fs.FS
+	Migrations fs.FS
+	Views      fs.FS
+}
+
+// Publishable resource kinds, used as PublishOptions.Only values.
+const (
+	PublishConfig     = "config"
+	PublishMigrations = "migrations"
+	PublishViews      = "views"
+)
+
+// PublishOptions controls Package.Publish.
+type PublishOptions struct {
+	// Only limits publishing to the given resource kinds; empty means all.
+	Only []string
+	// Force overwrites files that already exist in the application.
+	Force bool
+}
+
+// PublishedFile is a file Publish copied, or skipped because it exists.
+type PublishedFile struct {
+	Path    string
+	Skipped bool
+}
+
+// Publish copies the package's resources below root, the application root:
+// config to config/<name>/, views to views/vendor/<name>/ and migrations to
+// database/migrations/, where the migration runner picks them up. Migration
+// files get the package name after their timestamp, so
+// 20240101000000_create_invoices.sql from "billing" is published as
+// 20240101000000_billing_create_invoices.sql: packages cannot collide with
+// each other or the application, and migrations still run in timestamp
+// order. Existing
+// files are kept unless opts.Force is set, so local edits survive a
+// re-publish.
+func (p Package) Publish(root string, opts PublishOptions) ([]PublishedFile, error) {
+	if p.Name == "" || p.Name != path.Base(p.Name) || p.Name == "." || p.Name == ".." {
+		return nil, fmt.Errorf("astra: invalid package name %q", p.Name)
+	}
+	targets := []struct {
+		kind   string
+		src    fs.FS
+		dst    string
+		rename func(string) string
+	}{
+		{PublishConfig, p.Config, filepath.Join(root, "config", p.Name), nil},
+		{PublishMigrations, p.Migrations, filepath.Join(root, "database", "migrations"), p.migrationName},
+		{PublishViews, p.Views, filepath.Join(root, "views", "vendor", p.Name), nil},
+	}
+
+	var files []PublishedFile
+	for _, t := range targets {
+		if t.src == nil || (len(opts.Only) > 0 && !slices.Contains(opts.Only, t.kind)) {
+			continue
+		}
+		copied, err := publishDir(t.src, t.dst, t.rename, opts.Force)
+		files = append(files, copied...)
+		if err != nil {
+			return files, fmt.Errorf("astra: publish %s %s: %w", p.Name, t.kind, err)
+		}
+	}
+	return files, nil
+}
+
+// migrationName namespaces a migration file name with the package name,
+// after the leading timestamp when there is one.
+func (p Package) migrationName(name string) string {
+	digits := strings.IndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+	if digits > 0 && name[digits] == '_' {
+		return name[:digits+1] + p.Name + "_" + name[digits+1:]
+	}
+	return p.Name + "_" + name
+}
+
+// publishDir copies src to dst. rename, when set, maps each file's base
+// name to the one it is published under.
+func publishDir(src fs.FS, dst string, rename func(string) string, force bool) ([]PublishedFile, error) {
+	var files []PublishedFile
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && name == "." {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel := name
+		if rename != nil {
+			rel = path.Join(path.Dir(name), rename(path.Base(name)))
+		}
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+		if _, err := os.Stat(target); err == nil && !force {
+			files = append(files, PublishedFile{Path: target, Skipped: true})
+			return nil
+		}
+		data, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0600); err != nil {
+			return err
+		}
+		files = append(files, PublishedFile{Path: target})
+		return nil
+	})
+	return files, err
+}
+
+// Packages returns the packages declared by the manifests, in every
+// environment.
+func (a *App) Packages() []Package {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var pkgs []Package
+	for _, m := range a.manifests {
+		pkgs = append(pkgs, m.Packages...)
+	}
+	return pkgs
+}
@@ -0,0 +1,82 @@
+package engine_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_Packages(t *testing.T) {
+	var log []string
+	billing := engine.Package{
+		Name:     "billing",
+		Provider: &recordingProvider{name: "billing", log: &log},
+		Commands: []func(*engine.App) *cobra.Command{
+			func(*engine.App) *cobra.Command { return &cobra.Command{Use: "billing:sync"} },
+		},
+	}
+	webOnly := engine.Package{
+		Name:         "admin",
+		Provider:     &recordingProvider{name: "admin", log: &log},
+		Environments: []engine.Environment{engine.EnvironmentWeb},
+	}
+
+	app := engine.New(&config.AstraConfig{}, &config.Config{}, slog.Default())
+	app.SetEnvironment(engine.EnvironmentConsole)
+	app.UseManifest(engine.Manifest{Packages: []engine.Package{billing, webOnly}})
+	require.NoError(t, app.Boot())
+
+	assert.Equal(t, []string{"boot billing", "start billing"}, log)
+	require.Len(t, app.Commands(), 1)
+	assert.Equal(t, "billing:sync", app.Commands()[0].Use)
+	assert.Len(t, app.Packages(), 2)
+}
+
+func TestPackage_Publish(t *testing.T) {
+	pkg := engine.Package{
+		Name:   "billing",
+		Config: fstest.MapFS{"billing.yaml": {Data: []byte("currency: eur")}},
+		Migrations: fstest.MapFS{
+			"20240101000000_create_invoices.sql": {Data: []byte("-- +migrate Up")},
+			"seed_plans.sql":                     {Data: []byte("-- +migrate Up plans")},
+		},
+		Views: fstest.MapFS{"invoices/show.html": {Data: []byte("<h1>invoice</h1>")}},
+	}
+	root := t.TempDir()
+	read := func(rel string) string {
+		data, err := os.ReadFile(filepath.Join(root, rel))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	files, err := pkg.Publish(root, engine.PublishOptions{})
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+	assert.Equal(t, "currency: eur", read("config/billing/billing.yaml"))
+	assert.Equal(t, "-- +migrate Up", read("database/migrations/20240101000000_billing_create_invoices.sql"))
+	assert.Equal(t, "-- +migrate Up plans", read("database/migrations/billing_seed_plans.sql"))
+	assert.Equal(t, "<h1>invoice</h1>", read("views/vendor/billing/invoices/show.html"))
+
+	// Local edits survive a re-publish unless forced.
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config/billing/billing.yaml"), []byte("currency: usd"), 0o600))
+	files, err = pkg.Publish(root, engine.PublishOptions{Only: []string{engine.PublishConfig}})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.True(t, files[0].Skipped)
+	assert.Equal(t, "currency: usd", read("config/billing/billing.yaml"))
+
+	_, err = pkg.Publish(root, engine.PublishOptions{Only: []string{engine.PublishConfig}, Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, "currency: eur", read("config/billing/billing.yaml"))
+
+	_, err = engine.Package{Name: "../evil", Config: pkg.Config}.Publish(root, engine.PublishOptions{})
+	assert.Error(t, err)
+}
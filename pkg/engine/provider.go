@@ -7,9 +7,9 @@ type Provider interface {
 	// Name returns the provider name for logging and debugging.
 	Name() string
 
-	// Register services in the application container.
-	// Providers should use engine.Instance(a, service) or a.Container() to register
-	// their managed services instead of setting fields on the App struct.
+	// Register the provider's hooks, health checks and event listeners.
+	// Services themselves are constructed by Wire and passed to the
+	// provider's constructor; App does not hold a service container.
 	Register(a *App) error
 
 	// Boot the provider.
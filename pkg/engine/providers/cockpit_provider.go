@@ -9,6 +9,7 @@ import (
 	"github.com/shauryagautam/Astra/pkg/engine/telemetry"
 	platformtelemetry "github.com/shauryagautam/Astra/internal/platform/telemetry"
 	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/mail"
 )
 
 type CockpitProvider struct {
@@ -18,6 +19,7 @@ type CockpitProvider struct {
 	queueMon *telemetry.QueueMonitor
 	db       *database.DB
 	router   *http.Router
+	preview  *mail.PreviewMailer
 }
 
 func NewCockpitProvider(
@@ -36,6 +38,13 @@ func NewCockpitProvider(
 	}
 }
 
+// WithMailPreview exposes the messages captured by a preview mailer at
+// /___mails alongside the dashboard.
+func (p *CockpitProvider) WithMailPreview(preview *mail.PreviewMailer) *CockpitProvider {
+	p.preview = preview
+	return p
+}
+
 func (p *CockpitProvider) Name() string { return "cockpit" }
 
 func (p *CockpitProvider) Register(app *engine.App) error {
@@ -67,6 +76,12 @@ func (p *CockpitProvider) Boot(app *engine.App) error {
 		slog.Info("cockpit: developer dashboard routes registered at /__astra")
 	}
 
+	// 3. Register the mail preview
+	if p.router != nil && p.preview != nil {
+		http.RegisterMailPreviewRoutes(p.router, p.preview)
+		slog.Info("cockpit: mail preview registered at " + http.MailPreviewPath)
+	}
+
 	return nil
 }
 
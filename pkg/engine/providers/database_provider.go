@@ -3,10 +3,14 @@ package providers
 import (
 	"github.com/shauryagautam/Astra/pkg/database"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/shauryagautam/Astra/pkg/engine/config"
 	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/observability/metrics"
 )
 
 // DatabaseProvider implements engine.Provider for the Database service.
@@ -16,14 +20,73 @@ type DatabaseProvider struct {
 }
 
 // ProvideDB is a static provider for the database.
+// The driver and DSN are resolved by resolveConnection, and pool sizing and
+// the connect retry policy are read by poolConfig.
 func ProvideDB(env *config.Config) (*database.DB, error) {
-	cfg := database.Config{
-		Driver: env.String("DB_DRIVER", "postgres"),
-		DSN:    env.String("DB_DSN", ""),
+	driver, dsn, err := resolveConnection(env)
+	if err != nil {
+		return nil, fmt.Errorf("db: %w", err)
 	}
+
+	cfg := poolConfig(env)
+	cfg.Driver = driver
+	cfg.DSN = dsn
 	return database.Open(cfg)
 }
 
+// resolveConnection returns the driver and DSN shared by ProvideDB and
+// ORMProvider. The DSN is DB_DSN or DATABASE_URL. The driver is taken from
+// DB_DRIVER or DB_CONNECTION (postgres, mysql, sqlite, neon) and otherwise
+// inferred from the DSN, so switching local development or CI to SQLite
+// only requires DATABASE_URL=sqlite://storage/app.db. URL-style DSNs are
+// converted to the form the driver expects.
+func resolveConnection(env *config.Config) (driver, dsn string, err error) {
+	dsn = env.String("DB_DSN", env.String("DATABASE_URL", ""))
+	if dsn == "" {
+		return "", "", errors.New("DATABASE_URL is not configured")
+	}
+	driver = env.String("DB_DRIVER", env.String("DB_CONNECTION", ""))
+	if driver == "" {
+		driver = database.DetectDriver(dsn)
+	}
+	return driver, database.NormalizeDSN(driver, dsn), nil
+}
+
+// poolConfig reads the pool sizing and connect retry policy shared by
+// ProvideDB and ORMProvider, from the same DB_* keys as
+// config.DatabaseConfig:
+//
+//	DB_MAX_CONNS         maximum open connections (default 10)
+//	DB_MAX_IDLE_CONNS    maximum idle connections kept (default 2)
+//	DB_MAX_IDLE          idle time before a connection is closed (default 30s)
+//	DB_CONN_LIFETIME     maximum connection lifetime (default 30m)
+//	DB_CONNECT_RETRIES   extra connect attempts at boot (default 5)
+//	DB_CONNECT_BACKOFF   first retry delay, doubled each time (default 500ms)
+//	DB_LOG_QUERIES       log every query (default false)
+//
+// The keys each provider read before still apply when the keys above are
+// unset: ProvideDB's DB_MIN_CONNS for the idle connections, and
+// ORMProvider's DB_MAX_OPEN, DB_LIFETIME and DB_MAX_IDLE. A bare number in
+// DB_MAX_IDLE is read as ORMProvider read it, as an idle connection count.
+func poolConfig(env *config.Config) database.Config {
+	idleConns := env.Int("DB_MIN_CONNS", 2)
+	idleTime := 30 * time.Second
+	if n := env.Int("DB_MAX_IDLE", -1); n >= 0 {
+		idleConns = n
+	} else {
+		idleTime = env.Duration("DB_MAX_IDLE", idleTime)
+	}
+	return database.Config{
+		MaxOpen:        env.Int("DB_MAX_CONNS", env.Int("DB_MAX_OPEN", 10)),
+		MaxIdle:        env.Int("DB_MAX_IDLE_CONNS", idleConns),
+		MaxIdleTime:    idleTime,
+		Lifetime:       env.Duration("DB_CONN_LIFETIME", env.Duration("DB_LIFETIME", 30*time.Minute)),
+		ConnectRetries: env.Int("DB_CONNECT_RETRIES", 5),
+		ConnectBackoff: env.Duration("DB_CONNECT_BACKOFF", 500*time.Millisecond),
+		LogQueries:     env.Bool("DB_LOG_QUERIES", false),
+	}
+}
+
 // Register assembles the DB service into the app.
 func (p *DatabaseProvider) Register(a *engine.App) error {
 	dbService, err := ProvideDB(a.Env())
@@ -31,21 +94,24 @@ func (p *DatabaseProvider) Register(a *engine.App) error {
 		return err
 	}
 	p.db = dbService
+	// Model observers and listeners of "model.<table>.<action>" events share
+	// the application's emitter.
+	database.SetEvents(a.Events())
 
-	a.RegisterHealthCheck("db", engine.HealthCheckFunc(func(ctx context.Context) error {
-		if p.db == nil {
-			return fmt.Errorf("db server: not initialized")
-		}
-
-		return p.db.Pool().PingContext(ctx)
-	}))
+	a.RegisterHealthCheck("db", &dbHealth{provider: p})
 
 	return nil
 }
 
 
-// Boot starts the database connection.
+// Boot registers connection pool metrics if telemetry is enabled.
 func (p *DatabaseProvider) Boot(a *engine.App) error {
+	if a.Env().String("OTEL_EXPORTER_OTLP_ENDPOINT", "") != "" || a.Env().String("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "") != "" {
+		meter := metrics.GetMeter()
+		if err := database.ObservePool(p.db.Pool(), meter, p.db.Dialect().Name()); err != nil {
+			slog.Warn("db: failed to register pool metrics", "error", err)
+		}
+	}
 	return nil
 }
 
@@ -54,3 +120,34 @@ func (p *DatabaseProvider) Shutdown(ctx context.Context, a *engine.App) error {
 	// Connection is closed in ORMProvider shutdown
 	return nil
 }
+
+// dbHealth reports database reachability, and whether the pool is
+// saturated, to the health response.
+type dbHealth struct {
+	provider *DatabaseProvider
+}
+
+// CheckHealth pings the database. A saturated pool is not a failure: every
+// connection being busy at peak load is no reason to take the instance out
+// of rotation, so it is only reported in HealthDetails.
+func (h *dbHealth) CheckHealth(ctx context.Context) error {
+	db := h.provider.db
+	if db == nil {
+		return fmt.Errorf("db server: not initialized")
+	}
+	return db.Pool().PingContext(ctx)
+}
+
+// HealthDetails implements engine.HealthDetailer. It only says whether
+// every connection is checked out; the pool statistics are exported as
+// metrics by Boot, not on the public health endpoint.
+func (h *dbHealth) HealthDetails() map[string]any {
+	db := h.provider.db
+	if db == nil {
+		return nil
+	}
+	stats := db.Stats()
+	return map[string]any{
+		"saturated": stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections,
+	}
+}
@@ -0,0 +1,46 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfig(t *testing.T) {
+	load := func(t *testing.T, env map[string]string) *config.Config {
+		t.Helper()
+		for k, v := range env {
+			t.Setenv(k, v)
+		}
+		cfg, err := config.Load(t.TempDir() + "/.env")
+		require.NoError(t, err)
+		return cfg
+	}
+
+	t.Run("current keys", func(t *testing.T) {
+		cfg := poolConfig(load(t, map[string]string{
+			"DB_MAX_CONNS": "20", "DB_MAX_IDLE_CONNS": "4", "DB_MAX_IDLE": "1m", "DB_CONN_LIFETIME": "1h",
+		}))
+		assert.Equal(t, 20, cfg.MaxOpen)
+		assert.Equal(t, 4, cfg.MaxIdle)
+		assert.Equal(t, time.Minute, cfg.MaxIdleTime)
+		assert.Equal(t, time.Hour, cfg.Lifetime)
+	})
+
+	t.Run("ProvideDB's old keys", func(t *testing.T) {
+		cfg := poolConfig(load(t, map[string]string{"DB_MIN_CONNS": "3", "DB_MAX_IDLE": "45s"}))
+		assert.Equal(t, 3, cfg.MaxIdle)
+		assert.Equal(t, 45*time.Second, cfg.MaxIdleTime)
+	})
+
+	t.Run("ORMProvider's old keys", func(t *testing.T) {
+		cfg := poolConfig(load(t, map[string]string{"DB_MAX_OPEN": "25", "DB_MAX_IDLE": "5", "DB_LIFETIME": "10m"}))
+		assert.Equal(t, 25, cfg.MaxOpen)
+		assert.Equal(t, 5, cfg.MaxIdle, "a bare DB_MAX_IDLE is a connection count")
+		assert.Equal(t, 30*time.Second, cfg.MaxIdleTime)
+		assert.Equal(t, 10*time.Minute, cfg.Lifetime)
+	})
+}
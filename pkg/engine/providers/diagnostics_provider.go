@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+// DiagnosticsProvider mounts pprof, expvar and runtime stats at
+// http.DiagnosticsPath when DIAGNOSTICS_ENABLED is true. Clients must pass
+// the given middleware, typically an auth guard and a role check, and match
+// DIAGNOSTICS_ALLOW_IPS when it is set. Boot fails when the routes are
+// enabled without middleware.
+type DiagnosticsProvider struct {
+	engine.BaseProvider
+	router     *http.Router
+	middleware []http.MiddlewareFunc
+}
+
+func NewDiagnosticsProvider(router *http.Router, middleware ...http.MiddlewareFunc) *DiagnosticsProvider {
+	return &DiagnosticsProvider{router: router, middleware: middleware}
+}
+
+func (p *DiagnosticsProvider) Name() string { return "diagnostics" }
+
+func (p *DiagnosticsProvider) Boot(app *engine.App) error {
+	cfg := app.Config()
+	if cfg == nil || !cfg.Diagnostics.Enabled || p.router == nil {
+		return nil
+	}
+
+	err := http.RegisterDiagnosticsRoutes(p.router, http.DiagnosticsOptions{
+		AllowIPs:       cfg.Diagnostics.AllowIPs,
+		TrustedProxies: cfg.App.TrustedProxies,
+		Middleware:     p.middleware,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: DIAGNOSTICS_ENABLED is set, so pass an auth guard to NewDiagnosticsProvider", err)
+	}
+	slog.Info("diagnostics: pprof, expvar and runtime stats registered at " + http.DiagnosticsPath)
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 
 	"github.com/shauryagautam/Astra/pkg/engine"
 	"github.com/shauryagautam/Astra/pkg/observability/metrics"
@@ -22,25 +21,16 @@ type ORMProvider struct {
 }
 
 // Register connects to the database and registers the *database.DB instance.
+// The connection, pool and connect retries are configured like ProvideDB's.
 func (p *ORMProvider) Register(a *engine.App) error {
-	driver := detectORMDriver(a.Env().String("DB_DSN", ""))
-	explicitDriver := a.Env().String("DB_DRIVER", "")
-	if explicitDriver != "" {
-		driver = explicitDriver
-	}
-
-	cfg := database.Config{
-		Driver:     driver,
-		DSN:        a.Env().String("DB_DSN", ""),
-		MaxOpen:    a.Env().Int("DB_MAX_OPEN", 25),
-		MaxIdle:    a.Env().Int("DB_MAX_IDLE", 5),
-		Lifetime:   a.Env().Duration("DB_LIFETIME", 0),
-		LogQueries: a.Env().Bool("DB_LOG_QUERIES", false),
+	driver, dsn, err := resolveConnection(a.Env())
+	if err != nil {
+		return fmt.Errorf("orm: %w", err)
 	}
 
-	if cfg.DSN == "" {
-		return fmt.Errorf("orm: DB_DSN is not configured")
-	}
+	cfg := poolConfig(a.Env())
+	cfg.Driver = driver
+	cfg.DSN = dsn
 
 	db, err := database.Open(cfg)
 	if err != nil {
@@ -57,7 +47,7 @@ func (p *ORMProvider) Register(a *engine.App) error {
 func (p *ORMProvider) Boot(a *engine.App) error {
 	if a.Env().String("OTEL_EXPORTER_OTLP_ENDPOINT", "") != "" || a.Env().String("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "") != "" {
 		meter := metrics.GetMeter()
-		if err := database.ObservePool(p.db.Pool(), meter, detectORMDriver(a.Env().String("DB_DSN", ""))); err != nil {
+		if err := database.ObservePool(p.db.Pool(), meter, p.db.Dialect().Name()); err != nil {
 			slog.Warn("orm: failed to register pool metrics", "error", err)
 		}
 	}
@@ -74,17 +64,3 @@ func (p *ORMProvider) Shutdown(ctx context.Context, _ *engine.App) error {
 	}
 	return nil
 }
-
-// detectORMDriver infers the SQL driver name from a DSN URL prefix.
-func detectORMDriver(dsn string) string {
-	switch {
-	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
-		return "postgres"
-	case strings.HasPrefix(dsn, "mysql://"):
-		return "mysql"
-	case strings.HasPrefix(dsn, "sqlite://"), strings.HasPrefix(dsn, "file:"), dsn == ":memory:":
-		return "sqlite"
-	default:
-		return "postgres"
-	}
-}
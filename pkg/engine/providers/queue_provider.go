@@ -14,6 +14,7 @@ type QueueProvider struct {
 	engine.BaseProvider
 	redisClient redis.UniversalClient
 	dash        *telemetry.Dashboard
+	dispatcher  *queue.Dispatcher
 	worker      *queue.Worker
 }
 
@@ -26,19 +27,35 @@ func NewQueueProvider(r redis.UniversalClient, d *telemetry.Dashboard) *QueuePro
 
 func (p *QueueProvider) Name() string { return "queue" }
 
+// Dispatcher returns the dispatcher for the app's jobs, set up in Register
+// with the same payload protection as the worker, so what it queues (mail,
+// broadcasts, jobs dispatched after commit) can be run.
+func (p *QueueProvider) Dispatcher() *queue.Dispatcher { return p.dispatcher }
+
+// Worker returns the worker started in Boot. Register job types on it from
+// a provider's Register, so they are known before it starts.
+func (p *QueueProvider) Worker() *queue.Worker { return p.worker }
+
 func (p *QueueProvider) Register(a *engine.App) error {
 	if p.redisClient == nil {
 		return fmt.Errorf("queue: redis client is required")
 	}
 
 	cfg := a.Config().Queue
+	// QUEUE_ENCRYPT and QUEUE_SIGN apply to both ends: a worker refuses
+	// payloads not protected the way it expects.
+	protection, err := queue.PayloadProtectionFromConfig(a.Config())
+	if err != nil {
+		return err
+	}
+	p.dispatcher = queue.NewDispatcher(p.redisClient, cfg.Prefix).WithPayloadProtection(protection)
 	p.worker = queue.NewWorker(
 		p.redisClient,
 		cfg.Prefix,
 		cfg.Queues,
 		cfg.Concurrency,
 		a.Logger(),
-	)
+	).WithPayloadProtection(protection)
 
 	// Integration: Hook into telemetry if available
 	if p.dash != nil {
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receiptJob struct {
+	queue.BaseJob
+	Email string
+	done  chan<- string
+}
+
+func (j *receiptJob) Handle(context.Context) error {
+	j.done <- j.Email
+	return nil
+}
+
+func TestQueueProvider_ProtectsDispatchedPayloads(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	cfg := &config.AstraConfig{
+		App:   config.AppConfig{Key: "signing-key", EncryptionKey: strings.Repeat("k", 32)},
+		Queue: config.QueueConfig{Prefix: "app", Concurrency: 1, Encrypt: true, Sign: true},
+	}
+	app := engine.New(cfg, &config.Config{}, slog.Default())
+	p := NewQueueProvider(client, nil)
+	require.NoError(t, p.Register(app))
+
+	done := make(chan string, 2)
+	p.Worker().Register("receipt", func() queue.Job { return &receiptJob{done: done} })
+
+	ctx := context.Background()
+	require.NoError(t, p.Dispatcher().Dispatch(ctx, &receiptJob{Email: "ada@example.com"}, "receipt"))
+	require.NoError(t, p.Dispatcher().DispatchAfterCommit(ctx, &receiptJob{Email: "grace@example.com"}, "receipt"))
+
+	msgs, err := client.XRange(ctx, "app:queue:default", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	for _, msg := range msgs {
+		assert.NotContains(t, msg.Values["payload"], "@example.com", "payloads are not stored as plaintext")
+	}
+
+	require.NoError(t, p.Boot(app))
+	defer p.Shutdown(context.Background(), app)
+
+	var got []string
+	for range 2 {
+		select {
+		case email := <-done:
+			got = append(got, email)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the worker did not run the dispatched jobs")
+		}
+	}
+	assert.ElementsMatch(t, []string{"ada@example.com", "grace@example.com"}, got)
+}
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+// ResourceProvider fits the process to its container's memory limit
+// (MEMORY_LIMIT_MB, or the cgroup limit when unset). At MEMORY_SHED_PERCENT
+// of that limit it sets the Go soft memory limit, unless GOMEMLIMIT is
+// already set, and starts a MemoryWatchdog. While the watchdog reports the
+// process over the threshold, the router answers 503 with Retry-After.
+// Pass Watchdog().Overloaded to queue.RedisWorker.WithBackpressure to pause
+// workers too.
+//
+// Register it before routes are added, so the shedding middleware wraps them.
+type ResourceProvider struct {
+	engine.BaseProvider
+	router   *http.Router
+	watchdog *engine.MemoryWatchdog
+	priority http.PriorityFunc
+}
+
+func NewResourceProvider(router *http.Router) *ResourceProvider {
+	return &ResourceProvider{router: router}
+}
+
+// WithPriority sets which requests are still served while memory is short:
+// those priority classifies as critical, such as health checks.
+func (p *ResourceProvider) WithPriority(priority http.PriorityFunc) *ResourceProvider {
+	p.priority = priority
+	return p
+}
+
+func (p *ResourceProvider) Name() string { return "resources" }
+
+// Watchdog returns the memory watchdog, or nil when no memory limit is known
+// or shedding is disabled. It is set once Register has run.
+func (p *ResourceProvider) Watchdog() *engine.MemoryWatchdog { return p.watchdog }
+
+func (p *ResourceProvider) Register(app *engine.App) error {
+	cfg := app.Config().Resources
+	limit := uint64(cfg.MemoryLimitMB) << 20 // #nosec G115 -- negative values are rejected below
+	if cfg.MemoryLimitMB <= 0 {
+		limit = engine.ContainerMemoryLimit()
+	}
+	slog.Info("resources: sized to container",
+		"gomaxprocs", runtime.GOMAXPROCS(0), "memory_limit", limit, "shed_percent", cfg.MemoryShedPercent)
+	if limit == 0 || cfg.MemoryShedPercent <= 0 {
+		return nil
+	}
+
+	threshold := limit / 100 * uint64(min(cfg.MemoryShedPercent, 100)) // #nosec G115 -- checked positive above
+	if os.Getenv("GOMEMLIMIT") == "" {
+		debug.SetMemoryLimit(int64(threshold)) // #nosec G115 -- a memory limit fits in int64
+	}
+	p.watchdog = engine.NewMemoryWatchdog(engine.MemoryWatchdogConfig{
+		Threshold: threshold,
+		Interval:  cfg.MemoryCheckInterval,
+		Logger:    app.Logger(),
+	})
+	if p.router != nil {
+		p.router.Use(http.MemoryShedding(p.watchdog, 0, p.priority))
+	}
+	return nil
+}
+
+func (p *ResourceProvider) Boot(app *engine.App) error {
+	if p.watchdog != nil {
+		go p.watchdog.Run(app.BaseContext())
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"os"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+// StaticProvider serves STATIC_DIR (public/ by default) under
+// STATIC_PREFIX with Router.Static, with the index files and cache headers
+// of the STATIC_* settings. It does nothing when STATIC_ENABLED is false or
+// the directory does not exist.
+type StaticProvider struct {
+	engine.BaseProvider
+	router *http.Router
+}
+
+func NewStaticProvider(router *http.Router) *StaticProvider {
+	return &StaticProvider{router: router}
+}
+
+func (p *StaticProvider) Name() string { return "static" }
+
+func (p *StaticProvider) Boot(app *engine.App) error {
+	cfg := app.Config()
+	if cfg == nil || !cfg.Static.Enabled || p.router == nil {
+		return nil
+	}
+	if info, err := os.Stat(cfg.Static.Dir); err != nil || !info.IsDir() {
+		return nil
+	}
+	p.router.Static(cfg.Static.Prefix, os.DirFS(cfg.Static.Dir), http.StaticConfig{
+		Index:     cfg.Static.Index,
+		MaxAge:    cfg.Static.MaxAge,
+		Immutable: cfg.Static.Immutable,
+		Dotfiles:  cfg.Static.Dotfiles,
+	})
+	return nil
+}
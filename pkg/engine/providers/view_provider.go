@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"os"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+// ViewProvider renders server-side views from VIEWS_DIR (resources/views
+// by default) with a TemplateEngine, and sets it as the router's Views so
+// handlers can call c.Render("users/index", data). Templates reload on
+// every render in development; in production they are all compiled at boot,
+// so a broken template stops the app from starting instead of failing a
+// request. Without http.WithFS, it does nothing when the directory does not
+// exist.
+type ViewProvider struct {
+	engine.BaseProvider
+	router *http.Router
+	opts   []http.TemplateOption
+	views  *http.TemplateEngine
+}
+
+// NewViewProvider returns a provider rendering views for router. opts are
+// applied after the VIEWS_* settings, for example http.WithVite or
+// http.WithFS to embed the views.
+func NewViewProvider(router *http.Router, opts ...http.TemplateOption) *ViewProvider {
+	return &ViewProvider{router: router, opts: opts}
+}
+
+func (p *ViewProvider) Name() string { return "views" }
+
+// Views returns the engine, to add helpers and named routes, or nil before
+// Register or when there are no views to load.
+func (p *ViewProvider) Views() *http.TemplateEngine { return p.views }
+
+func (p *ViewProvider) Register(app *engine.App) error {
+	cfg := app.Config()
+	if cfg == nil || p.router == nil {
+		return nil
+	}
+	opts := []http.TemplateOption{
+		http.WithExtension(cfg.Views.Extension),
+		http.WithLayout(cfg.Views.Layout),
+		http.WithDevMode(app.Env() != nil && app.Env().IsDev()),
+	}
+	views := http.NewTemplateEngine(cfg.Views.Dir, append(opts, p.opts...)...)
+	if views.FS() == nil {
+		if info, err := os.Stat(cfg.Views.Dir); err != nil || !info.IsDir() {
+			return nil
+		}
+	}
+	p.views = views
+	p.router.Views = p.views
+	return nil
+}
+
+func (p *ViewProvider) Boot(app *engine.App) error {
+	if p.views == nil || (app.Env() != nil && app.Env().IsDev()) {
+		return nil
+	}
+	return p.views.Warmup()
+}
@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewProvider_RegistersEmbeddedViews(t *testing.T) {
+	cfg := &config.AstraConfig{Views: config.ViewsConfig{Dir: "missing/views", Extension: ".html"}}
+	app := engine.New(cfg, &config.Config{}, slog.Default())
+
+	router := http.NewRouter(cfg, slog.Default())
+	p := NewViewProvider(router, http.WithFS(fstest.MapFS{
+		"home.html": {Data: []byte(`Hello {{.}}`)},
+	}))
+	require.NoError(t, p.Register(app))
+	require.NotNil(t, p.Views(), "an FS option does not need the directory on disk")
+
+	var out bytes.Buffer
+	require.NoError(t, p.Views().Render(&out, "home", "Ada"))
+	assert.Equal(t, "Hello Ada", out.String())
+
+	onDisk := NewViewProvider(http.NewRouter(cfg, slog.Default()))
+	require.NoError(t, onDisk.Register(app))
+	assert.Nil(t, onDisk.Views(), "without an FS, a missing directory means no views")
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Since Go 1.25 the runtime sets GOMAXPROCS from the cgroup CPU limit on its
+// own, and keeps it updated when the limit changes. Memory is the limit it
+// does not read, so ContainerMemoryLimit and MemoryWatchdog cover that.
+
+// cgroupMemoryFiles hold the memory limit under cgroup v2 and v1.
+var cgroupMemoryFiles = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+
+// ContainerMemoryLimit returns the memory limit, in bytes, of the cgroup the
+// process runs in, or 0 when there is none or it cannot be read.
+func ContainerMemoryLimit() uint64 {
+	for _, path := range cgroupMemoryFiles {
+		raw, err := os.ReadFile(path) // #nosec G304 -- fixed cgroup paths
+		if err != nil {
+			continue
+		}
+		s := string(bytes.TrimSpace(raw))
+		if s == "max" {
+			return 0
+		}
+		n, err := strconv.ParseUint(s, 10, 64)
+		// cgroup v1 reports "no limit" as a number close to the maximum int64.
+		if err != nil || n >= 1<<62 {
+			return 0
+		}
+		return n
+	}
+	return 0
+}
+
+// ProcessRSS returns the resident set size of the process. Where
+// /proc/self/statm is not available it falls back to the memory the Go
+// runtime holds from the OS, which is close to RSS for a pure Go program.
+func ProcessRSS() uint64 {
+	if f, err := os.Open("/proc/self/statm"); err == nil {
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		sc.Split(bufio.ScanWords)
+		// The second field is the resident page count.
+		if sc.Scan() && sc.Scan() {
+			if pages, err := strconv.ParseUint(sc.Text(), 10, 64); err == nil {
+				return pages * uint64(os.Getpagesize()) // #nosec G115 -- page size is positive
+			}
+		}
+	}
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
+
+// MemoryWatchdogConfig configures a MemoryWatchdog.
+type MemoryWatchdogConfig struct {
+	// Threshold is the RSS, in bytes, above which the process counts as
+	// overloaded. Zero disables the watchdog.
+	Threshold uint64
+	// Interval between samples (default: 1s).
+	Interval time.Duration
+	// Read samples memory use (default: ProcessRSS).
+	Read   func() uint64
+	Logger *slog.Logger
+}
+
+// MemoryWatchdog samples memory use and reports when it crosses a
+// threshold, so the HTTP layer can shed load before the container is
+// OOM-killed. It only clears once use drops below 95% of the threshold, so
+// it does not flap around it.
+type MemoryWatchdog struct {
+	cfg  MemoryWatchdogConfig
+	over atomic.Bool
+	last atomic.Uint64
+}
+
+// NewMemoryWatchdog creates a watchdog; call Run to start sampling.
+func NewMemoryWatchdog(cfg MemoryWatchdogConfig) *MemoryWatchdog {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Read == nil {
+		cfg.Read = ProcessRSS
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &MemoryWatchdog{cfg: cfg}
+}
+
+// Run samples memory use every Interval until ctx is done.
+func (w *MemoryWatchdog) Run(ctx context.Context) {
+	if w.cfg.Threshold == 0 {
+		return
+	}
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		w.Check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check takes one sample and updates Overloaded. Run calls it; it is
+// exported for tests and for callers with their own schedule.
+func (w *MemoryWatchdog) Check() bool {
+	if w.cfg.Threshold == 0 {
+		return false
+	}
+	used := w.cfg.Read()
+	w.last.Store(used)
+
+	switch {
+	case !w.over.Load() && used >= w.cfg.Threshold:
+		w.over.Store(true)
+		w.cfg.Logger.Warn("astra: memory above threshold, shedding load",
+			"rss", used, "threshold", w.cfg.Threshold)
+		// Give back what the GC can before the next sample.
+		runtime.GC()
+	case w.over.Load() && used < w.cfg.Threshold/100*95:
+		w.over.Store(false)
+		w.cfg.Logger.Info("astra: memory back below threshold", "rss", used, "threshold", w.cfg.Threshold)
+	}
+	return w.over.Load()
+}
+
+// Overloaded reports whether the last sample was over the threshold.
+func (w *MemoryWatchdog) Overloaded() bool { return w.over.Load() }
+
+// Usage returns the last sampled memory use in bytes.
+func (w *MemoryWatchdog) Usage() uint64 { return w.last.Load() }
+
+// Threshold returns the configured threshold in bytes.
+func (w *MemoryWatchdog) Threshold() uint64 { return w.cfg.Threshold }
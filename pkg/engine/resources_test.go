@@ -0,0 +1,33 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryWatchdog(t *testing.T) {
+	var used uint64
+	w := engine.NewMemoryWatchdog(engine.MemoryWatchdogConfig{
+		Threshold: 1000,
+		Read:      func() uint64 { return used },
+	})
+
+	used = 900
+	assert.False(t, w.Check())
+	used = 1000
+	assert.True(t, w.Check())
+	assert.EqualValues(t, 1000, w.Usage())
+
+	// Stays overloaded until use drops below 95% of the threshold.
+	used = 960
+	assert.True(t, w.Check())
+	used = 940
+	assert.False(t, w.Check())
+	assert.False(t, w.Overloaded())
+}
+
+func TestProcessRSS(t *testing.T) {
+	assert.Positive(t, engine.ProcessRSS())
+}
@@ -0,0 +1,579 @@
+// Package routegen builds a typed route table from handler annotations. It
+// backs the astra generate:routes command, which lets teams declare a route
+// next to its handler:
+//
+//	// @route GET /users/:id
+//	func (uc *UserController) Show(c *http.Context) error { ... }
+//
+// and writes a Register function that wires every annotated handler onto an
+// http.Router. Because the generated file calls the handlers directly, a
+// handler whose signature drifts from http.HandlerFunc fails to compile
+// instead of failing at runtime. A controller's New<Type> function, if its
+// package has one, goes into a generated Wire set, so controllers receive
+// their dependencies through their constructors.
+package routegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/support/str"
+)
+
+// HTTPImport is the import path of the Astra router the handlers target.
+const HTTPImport = "github.com/shauryagautam/Astra/pkg/engine/http"
+
+const wireImport = "github.com/google/wire"
+
+// routerMethods maps HTTP methods to the Router method that registers them.
+var routerMethods = map[string]string{
+	"GET":    "Get",
+	"POST":   "Post",
+	"PUT":    "Put",
+	"PATCH":  "Patch",
+	"DELETE": "Delete",
+}
+
+var (
+	annotationPattern = regexp.MustCompile(`^//\s*@route\s+(\S+)\s+(\S+)\s*$`)
+	paramPattern      = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+	// contractPattern matches the @name, @request and @response annotations
+	// that describe a handler to generate:client.
+	contractPattern = regexp.MustCompile(`^//\s*@(name|request|response)\s+(\S+)\s*$`)
+)
+
+// TypeRef names a Go type by the import path of its package and its name.
+type TypeRef struct {
+	ImportPath string
+	Name       string
+}
+
+// Route is one annotated handler.
+type Route struct {
+	Method string // upper-case HTTP method
+	Path   string // router syntax, e.g. /users/{id}
+	// ImportPath and Package identify the package declaring the handler.
+	ImportPath string
+	Package    string
+	// Receiver is the controller type for methods, empty for functions.
+	Receiver string
+	Handler  string
+	// Constructor is New<Receiver> when the receiver's package declares
+	// one; Generate adds it to ControllerSet.
+	Constructor string
+	// PerRequest is set when Constructor returns a
+	// func(*http.Context) (*Receiver, error) factory rather than the
+	// controller, so a new controller is built for every request.
+	PerRequest bool
+	// Name is the route's name from an @name annotation, if any.
+	Name string
+	// Request and Response are the types of the JSON bodies, from the
+	// @request and @response annotations. Request is otherwise inferred
+	// from http.Validated[T](c) or c.Bind(&v) in the handler. Nil when
+	// unknown.
+	Request, Response *TypeRef
+	// Pos is the annotation's file:line, used in error messages.
+	Pos string
+}
+
+// Scan parses every non-test Go file under dirs and returns the annotated
+// routes. root is the module's directory and module its path; together they
+// give each handler package its import path.
+func Scan(root, module string, dirs ...string) ([]Route, error) {
+	var routes []Route
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		found, err := scanDir(fset, root, module, filepath.Clean(dir))
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, found...)
+	}
+	if err := linkConstructors(fset, root, module, routes); err != nil {
+		return nil, err
+	}
+	return routes, validate(routes)
+}
+
+func scanDir(fset *token.FileSet, root, module, dir string) ([]Route, error) {
+	var routes []Route
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "testdata" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		found, err := scanFile(fset, root, module, p)
+		routes = append(routes, found...)
+		return err
+	})
+	return routes, err
+}
+
+func scanFile(fset *token.FileSet, root, module, file string) ([]Route, error) {
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	importPath := path.Join(module, filepath.ToSlash(rel))
+	httpName := importName(f, HTTPImport)
+
+	var routes []Route
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Doc == nil {
+			continue
+		}
+		var name string
+		var request, response *TypeRef
+		for _, c := range fn.Doc.List {
+			m := contractPattern.FindStringSubmatch(c.Text)
+			if m == nil {
+				continue
+			}
+			switch m[1] {
+			case "name":
+				name = m[2]
+			case "request":
+				request = resolveTypeRef(f, importPath, m[2])
+			case "response":
+				response = resolveTypeRef(f, importPath, m[2])
+			}
+		}
+		if request == nil {
+			request = inferRequest(f, importPath, fn, httpName)
+		}
+		for _, c := range fn.Doc.List {
+			m := annotationPattern.FindStringSubmatch(c.Text)
+			if m == nil {
+				continue
+			}
+			pos := fset.Position(c.Pos())
+			r := Route{
+				Method:     strings.ToUpper(m[1]),
+				Path:       paramPattern.ReplaceAllString(m[2], "{$1}"),
+				ImportPath: importPath,
+				Package:    f.Name.Name,
+				Handler:    fn.Name.Name,
+				Name:       name,
+				Request:    request,
+				Response:   response,
+				Pos:        fmt.Sprintf("%s:%d", pos.Filename, pos.Line),
+			}
+			if fn.Recv != nil {
+				r.Receiver = receiverType(fn.Recv.List[0].Type)
+			}
+			if !fn.Name.IsExported() || (r.Receiver != "" && !ast.IsExported(r.Receiver)) {
+				return nil, fmt.Errorf("%s: @route handler %s must be exported", r.Pos, fn.Name.Name)
+			}
+			if !isHandlerFunc(fn.Type, httpName) {
+				return nil, fmt.Errorf("%s: @route handler %s must have the signature func(*http.Context) error", r.Pos, fn.Name.Name)
+			}
+			routes = append(routes, r)
+		}
+	}
+	return routes, nil
+}
+
+// resolveTypeRef resolves a type name as written in f, such as
+// "CreateUserInput" or "dto.CreateUserInput", to the package declaring it.
+func resolveTypeRef(f *ast.File, importPath, name string) *TypeRef {
+	name = strings.TrimPrefix(name, "*")
+	pkg, typ, ok := strings.Cut(name, ".")
+	if !ok {
+		return &TypeRef{ImportPath: importPath, Name: name}
+	}
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		alias := path.Base(p)
+		if imp.Name != nil {
+			alias = imp.Name.Name
+		}
+		if alias == pkg {
+			return &TypeRef{ImportPath: p, Name: typ}
+		}
+	}
+	return nil
+}
+
+// inferRequest finds the request type of a handler that reads its body
+// with <httpName>.Validated[T](c), or with c.Bind(&v) or
+// c.BindAndValidate(&v) where v is declared with the type.
+func inferRequest(f *ast.File, importPath string, fn *ast.FuncDecl, httpName string) *TypeRef {
+	if fn.Body == nil {
+		return nil
+	}
+	vars := map[string]ast.Expr{}
+	var found ast.Expr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for _, id := range n.Names {
+				if n.Type != nil {
+					vars[id.Name] = n.Type
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok || i >= len(n.Rhs) {
+					continue
+				}
+				rhs := n.Rhs[i]
+				if u, ok := rhs.(*ast.UnaryExpr); ok && u.Op == token.AND {
+					rhs = u.X
+				}
+				if lit, ok := rhs.(*ast.CompositeLit); ok && lit.Type != nil {
+					vars[id.Name] = lit.Type
+				}
+			}
+		case *ast.CallExpr:
+			if idx, ok := n.Fun.(*ast.IndexExpr); ok {
+				if sel, ok := idx.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "Validated" && isIdent(sel.X, httpName) {
+					found = idx.Index
+					return false
+				}
+			}
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "Bind" && sel.Sel.Name != "BindAndValidate") || len(n.Args) != 1 {
+				return true
+			}
+			if u, ok := n.Args[0].(*ast.UnaryExpr); ok && u.Op == token.AND {
+				if id, ok := u.X.(*ast.Ident); ok && vars[id.Name] != nil {
+					found = vars[id.Name]
+				}
+			} else if id, ok := n.Args[0].(*ast.Ident); ok {
+				if star, ok := vars[id.Name].(*ast.StarExpr); ok {
+					found = star.X
+				} else if vars[id.Name] != nil {
+					found = vars[id.Name]
+				}
+			}
+		}
+		return true
+	})
+	switch t := found.(type) {
+	case *ast.Ident:
+		return resolveTypeRef(f, importPath, t.Name)
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return resolveTypeRef(f, importPath, pkg.Name+"."+t.Sel.Name)
+		}
+	}
+	return nil
+}
+
+// linkConstructors sets Constructor and PerRequest on the routes whose
+// receiver has a New<Receiver> function in its package.
+func linkConstructors(fset *token.FileSet, root, module string, routes []Route) error {
+	type ctor struct {
+		name       string
+		perRequest bool
+	}
+	byPackage := map[string]map[string]ctor{}
+	for i, r := range routes {
+		if r.Receiver == "" {
+			continue
+		}
+		ctors, ok := byPackage[r.ImportPath]
+		if !ok {
+			ctors = map[string]ctor{}
+			byPackage[r.ImportPath] = ctors
+			dir := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(r.ImportPath, module)))
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+					continue
+				}
+				f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+				if err != nil {
+					return err
+				}
+				httpName := importName(f, HTTPImport)
+				for _, decl := range f.Decls {
+					fn, ok := decl.(*ast.FuncDecl)
+					if !ok || fn.Recv != nil || fn.Type.TypeParams != nil {
+						continue
+					}
+					typ, ok := strings.CutPrefix(fn.Name.Name, "New")
+					if !ok || typ == "" {
+						continue
+					}
+					if perRequest, ok := constructorKind(fn.Type, typ, httpName); ok {
+						ctors[typ] = ctor{fn.Name.Name, perRequest}
+					}
+				}
+			}
+		}
+		if c, ok := ctors[r.Receiver]; ok {
+			routes[i].Constructor = c.name
+			routes[i].PerRequest = c.perRequest
+		}
+	}
+	return nil
+}
+
+// constructorKind reports whether ft constructs typ, returning *typ
+// (optionally with an error) or a per-request
+// func(*<httpName>.Context) (*typ, error) factory.
+func constructorKind(ft *ast.FuncType, typ, httpName string) (perRequest, ok bool) {
+	if ft.Results == nil || len(ft.Results.List) == 0 || len(ft.Results.List) > 2 {
+		return false, false
+	}
+	if len(ft.Results.List) == 2 && !isIdent(ft.Results.List[1].Type, "error") {
+		return false, false
+	}
+	switch res := ft.Results.List[0].Type.(type) {
+	case *ast.StarExpr:
+		return false, isIdent(res.X, typ)
+	case *ast.FuncType:
+		if len(ft.Results.List) != 1 || !isContextParam(res, httpName) {
+			return false, false
+		}
+		r := res.Results
+		if r == nil || len(r.List) != 2 || !isIdent(r.List[1].Type, "error") {
+			return false, false
+		}
+		star, ok := r.List[0].Type.(*ast.StarExpr)
+		return true, ok && isIdent(star.X, typ)
+	}
+	return false, false
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+// importName returns the name a file uses for importPath, or "" when the
+// file does not import it.
+func importName(f *ast.File, importPath string) string {
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == importPath {
+			if imp.Name != nil {
+				return imp.Name.Name
+			}
+			return path.Base(p)
+		}
+	}
+	return ""
+}
+
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	}
+	return ""
+}
+
+// isHandlerFunc reports whether ft is func(*<httpName>.Context) error. It
+// catches mistakes early with a readable message; the compiler remains the
+// final check on the generated code.
+func isHandlerFunc(ft *ast.FuncType, httpName string) bool {
+	if ft.TypeParams != nil || !isContextParam(ft, httpName) {
+		return false
+	}
+	if ft.Results == nil || len(ft.Results.List) != 1 || len(ft.Results.List[0].Names) > 1 {
+		return false
+	}
+	res, ok := ft.Results.List[0].Type.(*ast.Ident)
+	return ok && res.Name == "error"
+}
+
+// isContextParam reports whether ft takes a single *<httpName>.Context.
+func isContextParam(ft *ast.FuncType, httpName string) bool {
+	if httpName == "" || ft.Params == nil || len(ft.Params.List) != 1 || len(ft.Params.List[0].Names) > 1 {
+		return false
+	}
+	star, ok := ft.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Context" && isIdent(sel.X, httpName)
+}
+
+func validate(routes []Route) error {
+	seen := make(map[string]string, len(routes))
+	for _, r := range routes {
+		if _, ok := routerMethods[r.Method]; !ok {
+			return fmt.Errorf("%s: unsupported method %q in @route", r.Pos, r.Method)
+		}
+		if !strings.HasPrefix(r.Path, "/") {
+			return fmt.Errorf("%s: @route path %q must start with /", r.Pos, r.Path)
+		}
+		key := r.Method + " " + r.Path
+		if prev, ok := seen[key]; ok {
+			return fmt.Errorf("%s: duplicate route %s (first declared at %s)", r.Pos, key, prev)
+		}
+		seen[key] = r.Pos
+	}
+	return nil
+}
+
+// Generate renders the Go source of a file in package pkg that registers
+// routes. Controllers are passed in through a generated Controllers struct
+// so the application keeps control over how they are constructed. When
+// controllers have constructors, it also writes ControllerSet, a Wire
+// provider set that builds Controllers from them; a per-request
+// controller's field holds its factory and its routes go through
+// http.Scoped.
+func Generate(pkg string, routes []Route) ([]byte, error) {
+	routes = slices.Clone(routes)
+	slices.SortFunc(routes, func(a, b Route) int {
+		return strings.Compare(a.Path+" "+a.Method, b.Path+" "+b.Method)
+	})
+
+	// Assign import aliases, disambiguating packages with the same name.
+	aliases := map[string]string{HTTPImport: "http"}
+	used := map[string]bool{"http": true, "wire": true}
+	for _, r := range routes {
+		if _, ok := aliases[r.ImportPath]; ok {
+			continue
+		}
+		alias := r.Package
+		for i := 2; used[alias]; i++ {
+			alias = fmt.Sprintf("%s%d", r.Package, i)
+		}
+		aliases[r.ImportPath] = alias
+		used[alias] = true
+	}
+
+	// One Controllers field per receiver type.
+	type controller struct {
+		field, typ, ctor string
+		perRequest       bool
+	}
+	var controllers []controller
+	fields := map[string]string{}
+	taken := map[string]bool{}
+	for _, r := range routes {
+		if r.Receiver == "" {
+			continue
+		}
+		key := r.ImportPath + "." + r.Receiver
+		if _, ok := fields[key]; ok {
+			continue
+		}
+		field := r.Receiver
+		if taken[field] {
+			field = str.Pascal(aliases[r.ImportPath]) + r.Receiver
+		}
+		taken[field] = true
+		fields[key] = field
+		c := controller{field: field, typ: "*" + aliases[r.ImportPath] + "." + r.Receiver, perRequest: r.PerRequest}
+		if r.Constructor != "" {
+			c.ctor = aliases[r.ImportPath] + "." + r.Constructor
+			aliases[wireImport] = "wire"
+		}
+		controllers = append(controllers, c)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by astra generate:routes. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", pkg)
+	paths := make([]string, 0, len(aliases))
+	for p := range aliases {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	for _, p := range paths {
+		if path.Base(p) == aliases[p] {
+			fmt.Fprintf(&buf, "\t%q\n", p)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", aliases[p], p)
+		}
+	}
+	buf.WriteString(")\n\n")
+
+	buf.WriteString("// Controllers holds the controller instances the generated routes call.\n")
+	buf.WriteString("type Controllers struct {\n")
+	for _, c := range controllers {
+		if c.perRequest {
+			fmt.Fprintf(&buf, "\t%s func(*http.Context) (%s, error)\n", c.field, c.typ)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %s\n", c.field, c.typ)
+		}
+	}
+	buf.WriteString("}\n\n")
+
+	if _, ok := aliases[wireImport]; ok {
+		buf.WriteString("// ControllerSet builds Controllers with Wire from the controllers' New functions.\n")
+		buf.WriteString("var ControllerSet = wire.NewSet(\n\twire.Struct(new(Controllers), \"*\"),\n")
+		for _, c := range controllers {
+			if c.ctor != "" {
+				fmt.Fprintf(&buf, "\t%s,\n", c.ctor)
+			}
+		}
+		buf.WriteString(")\n\n")
+	}
+
+	buf.WriteString("// RegisterRoutes registers every @route-annotated handler on r.\n")
+	buf.WriteString("func RegisterRoutes(r *http.Router, c Controllers) {\n")
+	if len(controllers) == 0 {
+		buf.WriteString("\t_ = c\n")
+	}
+	for _, r := range routes {
+		handler := aliases[r.ImportPath] + "." + r.Handler
+		if r.Receiver != "" {
+			handler = "c." + fields[r.ImportPath+"."+r.Receiver] + "." + r.Handler
+		}
+		if r.PerRequest {
+			handler = fmt.Sprintf("http.Scoped(c.%s, (*%s.%s).%s)", fields[r.ImportPath+"."+r.Receiver], aliases[r.ImportPath], r.Receiver, r.Handler)
+		}
+		fmt.Fprintf(&buf, "\tr.%s(%q, %s)\n", routerMethods[r.Method], r.Path, handler)
+	}
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// ModulePath reads the module path from the go.mod in root.
+func ModulePath(root string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod")) // #nosec G304 -- root is the project directory
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok {
+			if mod := strings.Trim(strings.TrimSpace(rest), `"`); mod != "" {
+				return mod, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("routegen: no module directive in %s", filepath.Join(root, "go.mod"))
+}
@@ -0,0 +1,180 @@
+package routegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestScanAndGenerate(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app/controllers/users.go"), `package controllers
+
+import "github.com/shauryagautam/Astra/pkg/engine/http"
+
+type UserController struct{}
+
+// Show returns one user.
+// @route GET /users/:id
+func (uc *UserController) Show(c *http.Context) error { return nil }
+
+// @route POST /users
+// @route PUT /users/:id
+func (uc *UserController) Save(c *http.Context) error { return nil }
+
+// Health has no receiver.
+//
+// @route GET /health
+func Health(c *http.Context) error { return nil }
+
+func unrelated() {}
+`)
+	writeFile(t, filepath.Join(root, "app/admin/controllers/users.go"), `package controllers
+
+import astra "github.com/shauryagautam/Astra/pkg/engine/http"
+
+type UserController struct{}
+
+// @route DELETE /admin/users/:id
+func (UserController) Destroy(c *astra.Context) error { return nil }
+`)
+
+	routes, err := Scan(root, "example.com/app", filepath.Join(root, "app"))
+	require.NoError(t, err)
+	require.Len(t, routes, 5)
+
+	src, err := Generate("start", routes)
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by astra generate:routes. DO NOT EDIT.
+
+package start
+
+import (
+	"example.com/app/app/admin/controllers"
+	controllers2 "example.com/app/app/controllers"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+// Controllers holds the controller instances the generated routes call.
+type Controllers struct {
+	UserController             *controllers.UserController
+	Controllers2UserController *controllers2.UserController
+}
+
+// RegisterRoutes registers every @route-annotated handler on r.
+func RegisterRoutes(r *http.Router, c Controllers) {
+	r.Delete("/admin/users/{id}", c.UserController.Destroy)
+	r.Get("/health", controllers2.Health)
+	r.Post("/users", c.Controllers2UserController.Save)
+	r.Get("/users/{id}", c.Controllers2UserController.Show)
+	r.Put("/users/{id}", c.Controllers2UserController.Save)
+}
+`, string(src))
+}
+
+func TestScanRejectsInvalidHandlers(t *testing.T) {
+	cases := map[string]string{
+		"signature": `// @route GET /x
+func X(w int) error { return nil }`,
+		"unexported": `// @route GET /x
+func x(c *http.Context) error { return nil }`,
+		"method": `// @route FETCH /x
+func X(c *http.Context) error { return nil }`,
+		"duplicate": `// @route GET /x/:id
+func X(c *http.Context) error { return nil }
+
+// @route GET /x/{id}
+func Y(c *http.Context) error { return nil }`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFile(t, filepath.Join(root, "app/h.go"), "package app\n\nimport \"github.com/shauryagautam/Astra/pkg/engine/http\"\n\nvar _ = http.Context{}\n\n"+body+"\n")
+			_, err := Scan(root, "example.com/app", filepath.Join(root, "app"))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGenerateControllerSet(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app/controllers/users.go"), `package controllers
+
+import "github.com/shauryagautam/Astra/pkg/engine/http"
+
+type UserController struct{ repo *Repo }
+
+// @route GET /users
+func (uc *UserController) Index(c *http.Context) error { return nil }
+
+type InvoiceController struct{ user string }
+
+// @route GET /invoices
+func (ic *InvoiceController) Index(c *http.Context) error { return nil }
+
+type AuditController struct{}
+
+// @route GET /audit
+func (ac *AuditController) Index(c *http.Context) error { return nil }
+
+// NewAuditController has the wrong result, so AuditController is left to the application.
+func NewAuditController() AuditController { return AuditController{} }
+`)
+	writeFile(t, filepath.Join(root, "app/controllers/providers.go"), `package controllers
+
+import web "github.com/shauryagautam/Astra/pkg/engine/http"
+
+type Repo struct{}
+
+func NewUserController(repo *Repo) (*UserController, error) { return &UserController{repo}, nil }
+
+func NewInvoiceController() func(*web.Context) (*InvoiceController, error) {
+	return func(c *web.Context) (*InvoiceController, error) { return &InvoiceController{c.Param("user")}, nil }
+}
+`)
+
+	routes, err := Scan(root, "example.com/app", filepath.Join(root, "app"))
+	require.NoError(t, err)
+	src, err := Generate("start", routes)
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by astra generate:routes. DO NOT EDIT.
+
+package start
+
+import (
+	"example.com/app/app/controllers"
+	"github.com/google/wire"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+// Controllers holds the controller instances the generated routes call.
+type Controllers struct {
+	AuditController   *controllers.AuditController
+	InvoiceController func(*http.Context) (*controllers.InvoiceController, error)
+	UserController    *controllers.UserController
+}
+
+// ControllerSet builds Controllers with Wire from the controllers' New functions.
+var ControllerSet = wire.NewSet(
+	wire.Struct(new(Controllers), "*"),
+	controllers.NewInvoiceController,
+	controllers.NewUserController,
+)
+
+// RegisterRoutes registers every @route-annotated handler on r.
+func RegisterRoutes(r *http.Router, c Controllers) {
+	r.Get("/audit", c.AuditController.Index)
+	r.Get("/invoices", http.Scoped(c.InvoiceController, (*controllers.InvoiceController).Index))
+	r.Get("/users", c.UserController.Index)
+}
+`, string(src))
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds a graceful shutdown when APP_SHUTDOWN_TIMEOUT
+// is not set.
+const DefaultShutdownTimeout = 15 * time.Second
+
+// ErrShutdownTimeout is returned by Run when the OnStop hooks and providers
+// did not finish within the shutdown deadline.
+var ErrShutdownTimeout = errors.New("astra: graceful shutdown exceeded its deadline")
+
+// Exit statuses used by Run for a hard stop. 2 matches the Go runtime's own
+// SIGQUIT handling.
+const (
+	exitInterrupted = 1
+	exitQuit        = 2
+)
+
+// Run boots the application and blocks until it is told to stop, so main
+// only has to build the App:
+//
+//	if err := app.Run(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// SIGINT and SIGTERM start a soft stop: Shutdown runs the OnStop hooks and
+// provider shutdowns with APP_SHUTDOWN_TIMEOUT as their deadline, and Run
+// returns their errors. If they are still running at the deadline, Run
+// writes every goroutine's stack to stderr, to show what is stuck, and
+// returns ErrShutdownTimeout. A second SIGINT or SIGTERM during the drain
+// exits with status 1 at once.
+//
+// SIGQUIT is a hard stop at any point: Run writes every goroutine's stack to
+// stderr and exits with status 2 without running any hooks.
+func (a *App) Run() error {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigs)
+
+	if err := a.Boot(); err != nil {
+		return err
+	}
+	a.logger.Info("Astra kernel is running. Press Ctrl+C to stop.")
+
+	select {
+	case sig := <-sigs:
+		if sig == syscall.SIGQUIT {
+			a.hardStop(sig)
+			return nil
+		}
+		a.logger.Info("Shutdown signal received. Cleaning up...", "signal", sig.String())
+	case <-a.ctx.Done():
+		a.logger.Info("Shutdown signal received. Cleaning up...")
+	}
+
+	timeout := a.shutdownTimeout()
+	done := make(chan error, 1)
+	go func() { done <- a.Shutdown() }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case sig := <-sigs:
+		if sig == syscall.SIGQUIT {
+			a.hardStop(sig)
+			return nil
+		}
+		a.logger.Warn("second shutdown signal received, exiting without waiting", "signal", sig.String())
+		os.Exit(exitInterrupted)
+		return nil
+	case <-timer.C:
+		a.logger.Error("graceful shutdown timed out", "timeout", timeout)
+		dumpGoroutines(os.Stderr)
+		return ErrShutdownTimeout
+	}
+}
+
+// hardStop writes the goroutine dump and exits without running hooks.
+func (a *App) hardStop(sig os.Signal) {
+	a.logger.Warn("hard stop requested, dumping goroutines", "signal", sig.String())
+	dumpGoroutines(os.Stderr)
+	os.Exit(exitQuit)
+}
+
+// dumpGoroutines writes the stacks of all goroutines in the format of an
+// unrecovered panic.
+func dumpGoroutines(w io.Writer) {
+	_ = pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// shutdownTimeout returns the configured shutdown deadline.
+func (a *App) shutdownTimeout() time.Duration {
+	if a.config != nil && a.config.App.ShutdownTimeout > 0 {
+		return a.config.App.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
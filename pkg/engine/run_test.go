@@ -0,0 +1,84 @@
+package engine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSignalledApp returns an App that sends sig to the process once it has
+// booted.
+func newSignalledApp(sig syscall.Signal, timeout time.Duration) *engine.App {
+	cfg := &config.AstraConfig{App: config.AppConfig{ShutdownTimeout: timeout}}
+	app := engine.New(cfg, &config.Config{}, slog.Default())
+	app.OnStart(func(context.Context) error {
+		return syscall.Kill(os.Getpid(), sig)
+	})
+	return app
+}
+
+func TestApp_RunSoftStop(t *testing.T) {
+	app := newSignalledApp(syscall.SIGTERM, 0)
+	stopped := false
+	app.OnStop(func(context.Context) error { stopped = true; return nil })
+
+	require.NoError(t, app.Run())
+	assert.True(t, stopped)
+}
+
+func TestApp_RunOneSignalIsOneSignal(t *testing.T) {
+	// A single SIGINT must start the drain, not also count as the second
+	// signal that exits at once.
+	for range 20 {
+		app := newSignalledApp(syscall.SIGINT, time.Second)
+		app.OnStop(func(context.Context) error { time.Sleep(5 * time.Millisecond); return nil })
+		require.NoError(t, app.Run())
+		assert.Error(t, app.BaseContext().Err(), "Shutdown cancels the base context")
+	}
+}
+
+func TestApp_RunShutdownDeadline(t *testing.T) {
+	app := newSignalledApp(syscall.SIGTERM, 50*time.Millisecond)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	app.OnStop(func(context.Context) error { <-release; return nil })
+
+	start := time.Now()
+	assert.ErrorIs(t, app.Run(), engine.ErrShutdownTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestApp_RunHardStop runs itself in a child process, since SIGQUIT exits.
+func TestApp_RunHardStop(t *testing.T) {
+	if os.Getenv("ASTRA_TEST_HARD_STOP") == "1" {
+		app := newSignalledApp(syscall.SIGQUIT, 0)
+		app.OnStop(func(context.Context) error { fmt.Fprintln(os.Stderr, "OnStop ran"); return nil })
+		_ = app.Run()
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestApp_RunHardStop$") // #nosec G204 -- the test binary itself
+	cmd.Env = append(os.Environ(), "ASTRA_TEST_HARD_STOP=1")
+	var out bytes.Buffer
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "child exited cleanly: %s", out.String())
+	assert.Equal(t, 2, exitErr.ExitCode())
+	assert.Contains(t, out.String(), "goroutine ")
+	assert.Contains(t, out.String(), "engine.(*App).Run")
+	assert.NotContains(t, out.String(), "OnStop ran")
+}
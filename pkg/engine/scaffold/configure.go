@@ -0,0 +1,410 @@
+// Package scaffold implements `astra configure`, which enables a subsystem
+// in an application with one command: it writes the subsystem's config
+// file and provider stub, appends its settings to .env.example and
+// registers the provider in the application manifest (start/astrarc.go).
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/engine/routegen"
+)
+
+// DefaultManifest is where the application manifest lives by convention.
+const DefaultManifest = "start/astrarc.go"
+
+// Import is an import a manifest entry needs. Path may contain {module},
+// which is replaced with the application's module path.
+type Import struct {
+	Name string
+	Path string
+}
+
+// Preset describes what configuring one subsystem adds to an application.
+type Preset struct {
+	// Config is written to config/<name>.yaml. Its keys are the env keys in
+	// lower case, so .env and the environment still override them.
+	Config string
+	// Env lists KEY=value lines appended to .env.example when missing.
+	Env []string
+	// Stub, when set, is written to app/providers/<name>_provider.go.
+	Stub string
+	// Entry is the engine.ProviderEntry added to the manifest's Providers.
+	Entry  string
+	Import Import
+}
+
+// Presets are the subsystems `astra configure` knows about.
+var Presets = map[string]Preset{
+	"redis": {
+		Config: `# Redis connection. Values in .env or the environment override these.
+redis_host: 127.0.0.1
+redis_port: 6379
+redis_db: 0
+redis_pool_size: 10
+redis_max_retries: 3
+`,
+		Env:    []string{"REDIS_HOST=127.0.0.1", "REDIS_PORT=6379", "REDIS_PASSWORD="},
+		Entry:  "{Provider: &astraredis.RedisProvider{}}",
+		Import: Import{Name: "astraredis", Path: "github.com/shauryagautam/Astra/pkg/redis"},
+	},
+	"auth": {
+		Config: `# JWT authentication. Keep JWT_SECRET in .env, never in this file.
+jwt_access_expiry: 15m
+jwt_refresh_expiry: 720h
+`,
+		Env:    []string{"JWT_SECRET=", "JWT_ISSUER="},
+		Stub:   authStub,
+		Entry:  "{Provider: &appproviders.AuthProvider{}, Environments: []engine.Environment{engine.EnvironmentWeb, engine.EnvironmentTest}}",
+		Import: Import{Name: "appproviders", Path: "{module}/app/providers"},
+	},
+	"mail": {
+		Config: `# Mail delivery: smtp, resend, or preview to store messages locally.
+mail_driver: smtp
+smtp_host: localhost
+smtp_port: 1025
+`,
+		Env:    []string{"MAIL_DRIVER=smtp", "SMTP_HOST=", "SMTP_PORT=", "SMTP_USER=", "SMTP_PASSWORD=", "SMTP_FROM=", "RESEND_API_KEY="},
+		Stub:   mailStub,
+		Entry:  "{Provider: &appproviders.MailProvider{}, Environments: []engine.Environment{engine.EnvironmentWeb, engine.EnvironmentConsole}}",
+		Import: Import{Name: "appproviders", Path: "{module}/app/providers"},
+	},
+}
+
+const authStub = `package providers
+
+import (
+	"errors"
+
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/identity/auth"
+)
+
+// AuthProvider builds the JWT guard from the JWT_* settings.
+type AuthProvider struct {
+	engine.BaseProvider
+	Guard *auth.JWTGuard
+}
+
+func (p *AuthProvider) Name() string { return "auth" }
+
+func (p *AuthProvider) Register(a *engine.App) error {
+	cfg := a.Config().Auth
+	if len(cfg.JWTSecret) < 32 {
+		return errors.New("auth: JWT_SECRET must be at least 32 characters")
+	}
+	p.Guard = auth.NewJWTGuard("api", auth.NewJWTManager(cfg, nil))
+	return nil
+}
+`
+
+const mailStub = `package providers
+
+import (
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/mail"
+)
+
+// MailProvider builds the mailer selected by MAIL_DRIVER.
+type MailProvider struct {
+	engine.BaseProvider
+	Mailer mail.Mailer
+}
+
+func (p *MailProvider) Name() string { return "mail" }
+
+func (p *MailProvider) Register(a *engine.App) error {
+	m, err := mail.NewMailer(a.Config().Mail, a.Events())
+	if err != nil {
+		return err
+	}
+	p.Mailer = m
+	return nil
+}
+`
+
+// Options controls Configure.
+type Options struct {
+	// Manifest is the manifest file relative to the root; empty means
+	// DefaultManifest.
+	Manifest string
+	// Force overwrites the config file and provider stub if they exist.
+	Force bool
+}
+
+// Change is one file Configure touched or left alone.
+type Change struct {
+	Path   string
+	Action string // "created", "updated" or "kept"
+}
+
+// Result reports what Configure did. Notes hold steps left to the user,
+// such as registering the provider when no manifest was found.
+type Result struct {
+	Changes []Change
+	Notes   []string
+}
+
+// Configure applies the preset called name to the application in root.
+func Configure(root, name string, opts Options) (*Result, error) {
+	preset, ok := Presets[name]
+	if !ok {
+		return nil, fmt.Errorf("scaffold: unknown subsystem %q (want %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	if opts.Manifest == "" {
+		opts.Manifest = DefaultManifest
+	}
+	res := &Result{}
+
+	if err := res.writeFile(root, filepath.Join("config", name+".yaml"), preset.Config, opts.Force); err != nil {
+		return res, err
+	}
+	if preset.Stub != "" {
+		if err := res.writeFile(root, filepath.Join("app", "providers", name+"_provider.go"), preset.Stub, opts.Force); err != nil {
+			return res, err
+		}
+	}
+	if err := res.appendEnv(root, name, preset.Env); err != nil {
+		return res, err
+	}
+
+	imp := preset.Import
+	if strings.Contains(imp.Path, "{module}") {
+		module, err := routegen.ModulePath(root)
+		if err != nil {
+			return res, err
+		}
+		imp.Path = strings.ReplaceAll(imp.Path, "{module}", module)
+	}
+	if err := res.register(root, opts.Manifest, preset.Entry, imp); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// PresetNames returns the names of the presets, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (r *Result) writeFile(root, rel, content string, force bool) error {
+	path := filepath.Join(root, rel)
+	action := "created"
+	if _, err := os.Stat(path); err == nil {
+		if !force {
+			r.Changes = append(r.Changes, Change{path, "kept"})
+			return nil
+		}
+		action = "updated"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil { // #nosec G306 -- scaffolded source and config are meant to be committed
+		return err
+	}
+	r.Changes = append(r.Changes, Change{path, action})
+	return nil
+}
+
+// appendEnv adds the lines of env whose keys .env.example does not define
+// yet, under a comment naming the subsystem.
+func (r *Result) appendEnv(root, name string, env []string) error {
+	path := filepath.Join(root, ".env.example")
+	existing, err := os.ReadFile(path) // #nosec G304 -- path is inside the project root
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	defined := map[string]bool{}
+	sc := bufio.NewScanner(bytes.NewReader(existing))
+	for sc.Scan() {
+		line := strings.TrimPrefix(strings.TrimSpace(sc.Text()), "export ")
+		if key, _, ok := strings.Cut(line, "="); ok && !strings.HasPrefix(line, "#") {
+			defined[strings.TrimSpace(key)] = true
+		}
+	}
+
+	var add []string
+	for _, line := range env {
+		key, _, _ := strings.Cut(line, "=")
+		if !defined[key] {
+			add = append(add, line)
+		}
+	}
+	if len(add) == 0 {
+		r.Changes = append(r.Changes, Change{path, "kept"})
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(existing)
+	if len(existing) > 0 {
+		if !bytes.HasSuffix(existing, []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		buf.WriteByte('\n')
+	}
+	fmt.Fprintf(&buf, "# %s\n%s\n", strings.ToUpper(name[:1])+name[1:], strings.Join(add, "\n"))
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil { // #nosec G306 -- .env.example holds no secrets
+		return err
+	}
+	action := "updated"
+	if len(existing) == 0 {
+		action = "created"
+	}
+	r.Changes = append(r.Changes, Change{path, action})
+	return nil
+}
+
+// register adds entry to the Providers of the engine.Manifest literal in
+// the manifest file, importing imp if needed. When the file or literal is
+// missing it leaves a note instead.
+func (r *Result) register(root, manifest, entry string, imp Import) error {
+	path := filepath.Join(root, manifest)
+	manual := fmt.Sprintf("add %s to the Providers of your engine.Manifest (import %s %q)", entry, imp.Name, imp.Path)
+
+	src, err := os.ReadFile(path) // #nosec G304 -- path is inside the project root
+	if errors.Is(err, os.ErrNotExist) {
+		r.Notes = append(r.Notes, manifest+" not found; "+manual)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(src, []byte(entry)) {
+		r.Changes = append(r.Changes, Change{path, "kept"})
+		return nil
+	}
+
+	out, err := addProviderEntry(src, entry, imp)
+	if err != nil {
+		return fmt.Errorf("scaffold: %s: %w", manifest, err)
+	}
+	if out == nil {
+		r.Notes = append(r.Notes, "no engine.Manifest literal in "+manifest+"; "+manual)
+		return nil
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil { // #nosec G306 -- application source
+		return err
+	}
+	r.Changes = append(r.Changes, Change{path, "updated"})
+	return nil
+}
+
+// addProviderEntry returns src with entry appended to the Providers of its
+// first engine.Manifest literal, or nil when there is none. The edit is
+// made on the text so comments and layout survive, then gofmt'd.
+func addProviderEntry(src []byte, entry string, imp Import) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest *ast.CompositeLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.CompositeLit); ok && manifest == nil && isSelector(lit.Type, "engine", "Manifest") {
+			manifest = lit
+		}
+		return manifest == nil
+	})
+	if manifest == nil {
+		return nil, nil
+	}
+
+	type insertion struct {
+		at   int
+		text string
+	}
+	var edits []insertion
+
+	var providers *ast.CompositeLit
+	for _, elt := range manifest.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Providers" {
+				providers, _ = kv.Value.(*ast.CompositeLit)
+			}
+		}
+	}
+	if providers != nil {
+		at := fset.Position(providers.Rbrace).Offset
+		sep := ""
+		if n := len(providers.Elts); n > 0 && !bytes.Contains(src[fset.Position(providers.Elts[n-1].End()).Offset:at], []byte(",")) {
+			sep = ","
+		}
+		if line := src[bytes.LastIndexByte(src[:at], '\n')+1 : at]; len(bytes.TrimSpace(line)) > 0 {
+			sep += "\n"
+		}
+		text := sep + entry + ",\n"
+		edits = append(edits, insertion{at, text})
+	} else {
+		edits = append(edits, insertion{fset.Position(manifest.Lbrace).Offset + 1, "\nProviders: []engine.ProviderEntry{\n" + entry + ",\n},\n"})
+	}
+
+	if !hasImport(file, imp) {
+		spec := strconv.Quote(imp.Path)
+		if imp.Name != "" {
+			spec = imp.Name + " " + spec
+		}
+		decl := importDecl(file)
+		switch {
+		case decl != nil && decl.Lparen.IsValid():
+			edits = append(edits, insertion{fset.Position(decl.Lparen).Offset + 1, "\n" + spec + "\n"})
+		default:
+			edits = append(edits, insertion{fset.Position(file.Name.End()).Offset, "\n\nimport " + spec + "\n"})
+		}
+	}
+
+	// Apply from the end so earlier offsets stay valid.
+	slices.SortFunc(edits, func(a, b insertion) int { return b.at - a.at })
+	out := slices.Clone(src)
+	for _, e := range edits {
+		out = slices.Insert(out, e.at, []byte(e.text)...)
+	}
+	return format.Source(out)
+}
+
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && id.Name == pkg && sel.Sel.Name == name
+}
+
+func hasImport(file *ast.File, imp Import) bool {
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == imp.Path {
+			return spec.Name == nil && imp.Name == "" || spec.Name != nil && spec.Name.Name == imp.Name
+		}
+	}
+	return false
+}
+
+func importDecl(file *ast.File) *ast.GenDecl {
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			return gen
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifestSrc = `package start
+
+import (
+	"github.com/shauryagautam/Astra/pkg/engine"
+	"github.com/shauryagautam/Astra/pkg/engine/providers"
+)
+
+// Manifest wires the application.
+var Manifest = engine.Manifest{
+	Providers: []engine.ProviderEntry{
+		// Always on.
+		{Provider: &providers.DatabaseProvider{}},
+	},
+}
+`
+
+func writeApp(t *testing.T, manifest string) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/shop\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.example"), []byte("APP_KEY=\nSMTP_HOST=mail.local"), 0o600))
+	if manifest != "" {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "start"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(root, DefaultManifest), []byte(manifest), 0o600))
+	}
+	return root
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestConfigure(t *testing.T) {
+	root := writeApp(t, manifestSrc)
+
+	res, err := Configure(root, "mail", Options{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Notes)
+	assert.Contains(t, read(t, filepath.Join(root, "config/mail.yaml")), "mail_driver: smtp")
+	assert.Contains(t, read(t, filepath.Join(root, "app/providers/mail_provider.go")), "type MailProvider struct")
+
+	env := read(t, filepath.Join(root, ".env.example"))
+	assert.Contains(t, env, "SMTP_HOST=mail.local\n\n# Mail\nMAIL_DRIVER=smtp\nSMTP_PORT=\n")
+	assert.NotContains(t, env, "SMTP_HOST=\n", "existing keys are not repeated")
+
+	manifest := read(t, filepath.Join(root, DefaultManifest))
+	assert.Contains(t, manifest, `appproviders "example.com/shop/app/providers"`)
+	assert.Contains(t, manifest, "\t\t// Always on.\n\t\t{Provider: &providers.DatabaseProvider{}},\n"+
+		"\t\t{Provider: &appproviders.MailProvider{}, Environments: []engine.Environment{engine.EnvironmentWeb, engine.EnvironmentConsole}},\n\t},")
+
+	// Running it again changes nothing.
+	res, err = Configure(root, "mail", Options{})
+	require.NoError(t, err)
+	for _, c := range res.Changes {
+		assert.Equal(t, "kept", c.Action, c.Path)
+	}
+	assert.Equal(t, manifest, read(t, filepath.Join(root, DefaultManifest)))
+}
+
+func TestConfigure_ManifestShapes(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"no providers": {
+			src:  "package start\n\nimport \"github.com/shauryagautam/Astra/pkg/engine\"\n\nvar Manifest = engine.Manifest{}\n",
+			want: "var Manifest = engine.Manifest{\n\tProviders: []engine.ProviderEntry{\n\t\t{Provider: &astraredis.RedisProvider{}},\n\t},\n}\n",
+		},
+		"single line list": {
+			src:  "package start\n\nimport (\n\t\"github.com/shauryagautam/Astra/pkg/engine\"\n)\n\nvar Manifest = engine.Manifest{Providers: []engine.ProviderEntry{{Provider: nil}}}\n",
+			want: "Providers: []engine.ProviderEntry{{Provider: nil},\n\t{Provider: &astraredis.RedisProvider{}},\n}}",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := writeApp(t, tt.src)
+			_, err := Configure(root, "redis", Options{})
+			require.NoError(t, err)
+			manifest := read(t, filepath.Join(root, DefaultManifest))
+			assert.Contains(t, manifest, tt.want)
+			assert.Contains(t, manifest, `astraredis "github.com/shauryagautam/Astra/pkg/redis"`)
+		})
+	}
+}
+
+func TestConfigure_WithoutManifest(t *testing.T) {
+	root := writeApp(t, "")
+	res, err := Configure(root, "auth", Options{})
+	require.NoError(t, err)
+	require.Len(t, res.Notes, 1)
+	assert.Contains(t, res.Notes[0], "&appproviders.AuthProvider{}")
+
+	_, err = Configure(root, "sms", Options{})
+	assert.ErrorContains(t, err, "want auth, mail, redis")
+}
@@ -0,0 +1,48 @@
+package errors
+
+import "net/http"
+
+// Framework error codes. The framework sets them on the errors it answers
+// itself, in the "code" field of the JSON error envelope, so API clients can
+// branch on the code rather than on the message. A code is never renamed or
+// reused once released; see Catalog.
+const (
+	ErrCodeRouteNotFound    ErrorCode = "E_ROUTE_NOT_FOUND"
+	ErrCodeMethodNotAllowed ErrorCode = "E_METHOD_NOT_ALLOWED"
+	ErrCodeInvalidAuthToken ErrorCode = "E_INVALID_AUTH_TOKEN"
+	ErrCodeTooManyRequests  ErrorCode = "E_TOO_MANY_REQUESTS"
+)
+
+// CodeInfo describes a framework error code.
+type CodeInfo struct {
+	Code        ErrorCode `json:"code"`
+	Status      int       `json:"status"`
+	Description string    `json:"description"`
+}
+
+var catalog = []CodeInfo{
+	{ErrCodeRouteNotFound, http.StatusNotFound,
+		"No route matches the request path."},
+	{ErrCodeMethodNotAllowed, http.StatusMethodNotAllowed,
+		"The path has routes, but none for the request method. The Allow header lists the methods it accepts."},
+	{ErrCodeInvalidAuthToken, http.StatusUnauthorized,
+		"The auth middleware rejected the request: the credentials are missing, malformed, expired or revoked."},
+	{ErrCodeTooManyRequests, http.StatusTooManyRequests,
+		"The client exceeded its rate limit. Retry after the number of seconds in the Retry-After header."},
+}
+
+// Catalog returns the framework error codes with their HTTP status and
+// description, in a stable order. `astra errors --json` prints it.
+func Catalog() []CodeInfo {
+	return append([]CodeInfo(nil), catalog...)
+}
+
+// Describe returns the catalog entry of code.
+func Describe(code ErrorCode) (CodeInfo, bool) {
+	for _, info := range catalog {
+		if info.Code == code {
+			return info, true
+		}
+	}
+	return CodeInfo{}, false
+}
@@ -0,0 +1,27 @@
+package errors
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	seen := map[ErrorCode]bool{}
+	for _, info := range Catalog() {
+		if !strings.HasPrefix(string(info.Code), "E_") || info.Description == "" || info.Status < 400 {
+			t.Errorf("incomplete catalog entry %+v", info)
+		}
+		if seen[info.Code] {
+			t.Errorf("duplicate code %s", info.Code)
+		}
+		seen[info.Code] = true
+	}
+
+	if got := New(ErrCodeTooManyRequests, "slow down").HTTPStatus(); got != http.StatusTooManyRequests {
+		t.Errorf("HTTPStatus = %d, want 429", got)
+	}
+	if _, ok := Describe("E_NOPE"); ok {
+		t.Error("Describe found an unknown code")
+	}
+}
@@ -175,6 +175,9 @@ func (e *Error) HTTPStatus() int {
 	case ErrCodeStorageQuota, ErrCodeLimitExceeded, ErrCodeQuotaExceeded:
 		return http.StatusInsufficientStorage
 	default:
+		if info, ok := Describe(e.Code); ok {
+			return info.Status
+		}
 		return http.StatusInternalServerError
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/shauryagautam/Astra/pkg/engine/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -62,7 +63,8 @@ func (e *Engine) Load(dir string) error {
 	return nil
 }
 
-// T translates a key in the given locale.
+// T translates a key in the given locale, else in its base language ("fr"
+// for "fr-FR"), else in the fallback locale.
 func (e *Engine) T(locale, key string, args ...any) string {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
@@ -74,7 +76,16 @@ func (e *Engine) T(locale, key string, args ...any) string {
 		}
 	}
 
-	// 2. Try fallback locale
+	// 2. Try the base language of a regional locale
+	if base, _, ok := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-"); ok {
+		if trans, ok := e.translations[base]; ok {
+			if val, ok := trans[key]; ok {
+				return e.format(val, args...)
+			}
+		}
+	}
+
+	// 3. Try fallback locale
 	if locale != e.fallback {
 		if trans, ok := e.translations[e.fallback]; ok {
 			if val, ok := trans[key]; ok {
@@ -83,10 +94,24 @@ func (e *Engine) T(locale, key string, args ...any) string {
 		}
 	}
 
-	// 3. Return key itself if not found
+	// 4. Return key itself if not found
 	return key
 }
 
+// Locales returns the loaded locales, sorted, for the http.Locales
+// middleware's Supported list.
+func (e *Engine) Locales() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	locales := make([]string, 0, len(e.translations))
+	for locale := range e.translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
 // Has checks if a translation key exists.
 func (e *Engine) Has(locale, key string) bool {
 	e.mu.RLock()
@@ -0,0 +1,24 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_T(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.json"), []byte(`{"hello":"hello","bye":"bye"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{"hello":"bonjour"}`), 0o600))
+	e := NewEngine("en")
+	require.NoError(t, e.Load(dir))
+
+	assert.Equal(t, "bonjour", e.T("fr", "hello"))
+	assert.Equal(t, "bonjour", e.T("fr-FR", "hello"), "a regional locale falls back to its base language")
+	assert.Equal(t, "bonjour", e.T("fr_CA", "hello"))
+	assert.Equal(t, "bye", e.T("fr-FR", "bye"), "then to the fallback locale")
+	assert.Equal(t, "missing", e.T("fr-FR", "missing"))
+}
@@ -2,8 +2,6 @@ package auth
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	nethttp "net/http"
@@ -16,6 +14,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 	"sync"
 )
 
@@ -267,14 +266,13 @@ func (g *CookieGuard) Login(c RequestContext, user any) (any, error) {
 	_ = c.RegenerateSession()
 
 	// 3. Issue new auth token
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
+	token, err := secure.Token(secure.TokenBytes)
+	if err != nil {
 		return nil, fmt.Errorf("auth: failed to generate token: %w", err)
 	}
-	token := hex.EncodeToString(b)
 
 	ttl := 24 * time.Hour
-	err := g.Session.Set(req.Context(), token, map[string]any{"userID": userID}, ttl)
+	err = g.Session.Set(req.Context(), token, map[string]any{"userID": userID}, ttl)
 	if err != nil {
 		return nil, err
 	}
@@ -292,7 +290,7 @@ func (g *CookieGuard) Login(c RequestContext, user any) (any, error) {
 	return nil, nil
 }
 
-// Logout revokes the session and clears the cookie.
+// Logout revokes the session, clears the cookie and rotates the web session ID.
 func (g *CookieGuard) Logout(c RequestContext) error {
 	req := c.GetRequest()
 	cookie, err := req.Cookie(g.CookieName)
@@ -306,6 +304,9 @@ func (g *CookieGuard) Logout(c RequestContext) error {
 		MaxAge: -1,
 	})
 
+	// Rotate the web session ID so nothing issued while logged in lives on.
+	_ = c.RegenerateSession()
+
 	event.DefaultEmitter.Emit(req.Context(), audit.AuditEvent{
 		Action:    "logout",
 		Success:   true,
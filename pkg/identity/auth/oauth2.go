@@ -2,10 +2,8 @@ package auth // Identity authentication and OAuth2 core
 
 import (
 	"context"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"github.com/shauryagautam/Astra/pkg/engine/json"
 	"io"
@@ -15,6 +13,7 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 )
 
 // OAuth2User represents the user information returned by an OAuth2 provider.
@@ -95,11 +94,10 @@ func (m *OAuth2Manager) Provider(name string) (OAuth2Provider, error) {
 
 // GenerateState creates a cryptographically random state parameter and stores it in Redis.
 func (m *OAuth2Manager) GenerateState(ctx context.Context) (string, error) {
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
+	state, err := secure.Token(secure.TokenBytes)
+	if err != nil {
 		return "", fmt.Errorf("oauth2: failed to generate state: %w", err)
 	}
-	state := hex.EncodeToString(b)
 
 	if m.redis != nil {
 		key := "oauth2:state:" + state
@@ -239,11 +237,7 @@ func FetchUserInfo(ctx context.Context, url string, token *OAuth2Token) (map[str
 
 // GenerateCodeVerifier creates a cryptographically random string for PKCE.
 func GenerateCodeVerifier() (string, error) {
-	b := make([]byte, 32)
-	if _, err := io.ReadFull(rand.Reader, b); err != nil {
-		return "", err
-	}
-	return base64.RawURLEncoding.EncodeToString(b), nil
+	return secure.Token(32)
 }
 
 // GenerateCodeChallenge creates an S256 challenge from a code verifier.
@@ -1,8 +1,7 @@
 package auth
 
 import (
-	"crypto/subtle"
-
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -22,5 +21,5 @@ func CheckPasswordHash(password, hash string) bool {
 // SecureCompare performs a timing-safe comparison of two strings.
 // Returns true if they are equal, false otherwise.
 func SecureCompare(a, b string) bool {
-	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+	return secure.Equal(a, b)
 }
@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+	"github.com/shauryagautam/Astra/pkg/observability/audit"
+	"github.com/shauryagautam/Astra/pkg/session"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
+)
+
+// ErrUnauthenticated is returned by SessionGuard when the request belongs
+// to no logged-in user.
+var ErrUnauthenticated = errors.New("auth: unauthenticated")
+
+// errNoSession is returned when the request has no session, because the
+// session middleware is not mounted.
+var errNoSession = errors.New("auth: session guard needs the session middleware")
+
+// Authenticatable is a user the SessionGuard can log in and remember.
+type Authenticatable interface {
+	GetID() string
+	// GetRememberToken returns the hash stored by UpdateRememberToken, or
+	// "" when the user has none.
+	GetRememberToken() string
+}
+
+// UserProvider loads and updates the users of a SessionGuard, typically
+// from the users table.
+type UserProvider interface {
+	RetrieveByID(ctx context.Context, id string) (Authenticatable, error)
+	// UpdateRememberToken stores hash as the user's remember token; "" clears
+	// it, which signs the user out of every remembered browser.
+	UpdateRememberToken(ctx context.Context, user Authenticatable, hash string) error
+}
+
+// sessionContext is implemented by request contexts that carry the session
+// loaded by the session middleware, such as *http.Context.
+type sessionContext interface {
+	Session() *session.Session
+}
+
+// SessionGuard authenticates browsers through the server-side session: Login
+// stores the user ID in the session, which the session middleware keeps in
+// its store (RedisStore in production) under the ID in the session cookie.
+//
+// With a UserProvider it also supports "remember me": Remember sets a
+// long-lived cookie holding a random token whose hash is stored on the user,
+// and Attempt logs the user back in from it once the session has expired.
+type SessionGuard struct {
+	name  string
+	Users UserProvider
+	// RememberCookie is the name of the remember-me cookie
+	// (default "remember_<guard name>").
+	RememberCookie string
+	// RememberFor is how long a remember-me cookie lasts (default 30 days).
+	RememberFor time.Duration
+}
+
+// NewSessionGuard creates a SessionGuard. users may be nil when neither
+// Authenticate nor remember-me is used.
+func NewSessionGuard(name string, users UserProvider) *SessionGuard {
+	return &SessionGuard{
+		name:           name,
+		Users:          users,
+		RememberCookie: "remember_" + name,
+		RememberFor:    30 * 24 * time.Hour,
+	}
+}
+
+func (g *SessionGuard) Name() string { return g.name }
+
+// Attempt sets the user of the session as the request's auth user. A
+// request without one is logged in from a valid remember-me cookie, if any;
+// otherwise Attempt returns ErrUnauthenticated.
+func (g *SessionGuard) Attempt(c RequestContext) error {
+	sess, err := requestSession(c)
+	if err != nil {
+		return err
+	}
+	userID := sess.UserID()
+	if userID == "" {
+		user, err := g.recall(c)
+		if err != nil {
+			return err
+		}
+		userID = user.GetID()
+		// A new session ID, as for any login.
+		sess.Login(userID)
+	}
+	c.SetAuthUser(&identityclaims.AuthClaims{UserID: userID})
+	return nil
+}
+
+// Authenticate is Attempt returning the user, loaded from the UserProvider.
+func (g *SessionGuard) Authenticate(c RequestContext) (Authenticatable, error) {
+	if g.Users == nil {
+		return nil, errors.New("auth: Authenticate needs a UserProvider")
+	}
+	if err := g.Attempt(c); err != nil {
+		return nil, err
+	}
+	sess, _ := requestSession(c)
+	return g.Users.RetrieveByID(c.GetRequest().Context(), sess.UserID())
+}
+
+// Check reports whether the session belongs to a logged-in user. It only
+// reads the session: a remember-me cookie is not looked at, since using one
+// logs the user in and so changes the session. Attempt, which the Auth
+// middleware calls, does that.
+func (g *SessionGuard) Check(c RequestContext) bool {
+	sess, err := requestSession(c)
+	return err == nil && sess.UserID() != ""
+}
+
+// Login logs user, an ID string or a value with GetID, into the session.
+// The session gets a new ID when it is saved, so an ID planted before the
+// login is worthless afterwards.
+func (g *SessionGuard) Login(c RequestContext, user any) (any, error) {
+	var userID string
+	switch v := user.(type) {
+	case string:
+		userID = v
+	case interface{ GetID() string }:
+		userID = v.GetID()
+	default:
+		return nil, errors.New("session: user must be a string ID or implement GetID()")
+	}
+	sess, err := requestSession(c)
+	if err != nil {
+		return nil, err
+	}
+	sess.Login(userID)
+	c.SetAuthUser(&identityclaims.AuthClaims{UserID: userID})
+
+	req := c.GetRequest()
+	event.DefaultEmitter.Emit(req.Context(), audit.AuditEvent{
+		ActorID:   userID,
+		Action:    "login",
+		Success:   true,
+		IPAddress: req.RemoteAddr,
+		UserAgent: req.UserAgent(),
+	})
+	return nil, nil
+}
+
+// Remember issues a remember-me cookie for user, to call after Login when
+// the user ticked "remember me". The token it holds replaces the user's
+// previous one. The cookie takes its path, domain and Secure, HttpOnly and
+// SameSite attributes from the router's cookie jar, like every other cookie.
+func (g *SessionGuard) Remember(c RequestContext, user Authenticatable) error {
+	if g.Users == nil {
+		return errors.New("auth: Remember needs a UserProvider")
+	}
+	token, err := secure.Token(secure.TokenBytes)
+	if err != nil {
+		return fmt.Errorf("auth: failed to generate token: %w", err)
+	}
+	req := c.GetRequest()
+	if err := g.Users.UpdateRememberToken(req.Context(), user, hashRememberToken(token)); err != nil {
+		return err
+	}
+	c.SetCookie(&nethttp.Cookie{
+		Name:     g.RememberCookie,
+		Value:    user.GetID() + "|" + token,
+		MaxAge:   int(g.RememberFor.Seconds()),
+		HttpOnly: true,
+	})
+	return nil
+}
+
+// Logout clears the session, which gets a new ID, and the remember-me
+// cookie. The user's remember token is cleared too, so every browser that
+// was remembered has to log in again.
+func (g *SessionGuard) Logout(c RequestContext) error {
+	sess, err := requestSession(c)
+	if err != nil {
+		return err
+	}
+	req := c.GetRequest()
+	userID := sess.UserID()
+	sess.Logout()
+
+	if _, err := req.Cookie(g.RememberCookie); err == nil {
+		g.forget(c)
+	}
+	if userID != "" && g.Users != nil {
+		if user, err := g.Users.RetrieveByID(req.Context(), userID); err == nil && user != nil && user.GetRememberToken() != "" {
+			_ = g.Users.UpdateRememberToken(req.Context(), user, "")
+		}
+	}
+
+	event.DefaultEmitter.Emit(req.Context(), audit.AuditEvent{
+		ActorID:   userID,
+		Action:    "logout",
+		Success:   true,
+		IPAddress: req.RemoteAddr,
+		UserAgent: req.UserAgent(),
+	})
+	return nil
+}
+
+// recall returns the user of a valid remember-me cookie. An invalid cookie
+// is cleared.
+func (g *SessionGuard) recall(c RequestContext) (Authenticatable, error) {
+	req := c.GetRequest()
+	cookie, err := req.Cookie(g.RememberCookie)
+	if err != nil || g.Users == nil {
+		return nil, ErrUnauthenticated
+	}
+	i := strings.LastIndexByte(cookie.Value, '|')
+	if i <= 0 {
+		g.forget(c)
+		return nil, ErrUnauthenticated
+	}
+	userID, token := cookie.Value[:i], cookie.Value[i+1:]
+	user, err := g.Users.RetrieveByID(req.Context(), userID)
+	if err != nil || user == nil || user.GetRememberToken() == "" ||
+		!secure.Equal(user.GetRememberToken(), hashRememberToken(token)) {
+		g.forget(c)
+		return nil, ErrUnauthenticated
+	}
+	return user, nil
+}
+
+func (g *SessionGuard) forget(c RequestContext) {
+	c.SetCookie(&nethttp.Cookie{
+		Name:   g.RememberCookie,
+		Value:  "",
+		MaxAge: -1,
+	})
+}
+
+// hashRememberToken returns what is stored for a remember-me token, so a
+// leaked users table cannot be replayed as cookies.
+func hashRememberToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func requestSession(c RequestContext) (*session.Session, error) {
+	sc, ok := c.(sessionContext)
+	if !ok {
+		return nil, errNoSession
+	}
+	sess := sc.Session()
+	if sess == nil {
+		return nil, errNoSession
+	}
+	return sess, nil
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionUser struct {
+	id, remember string
+}
+
+func (u *sessionUser) GetID() string            { return u.id }
+func (u *sessionUser) GetRememberToken() string { return u.remember }
+
+type memoryUsers map[string]*sessionUser
+
+func (m memoryUsers) RetrieveByID(_ context.Context, id string) (Authenticatable, error) {
+	if u, ok := m[id]; ok {
+		return u, nil
+	}
+	return nil, errors.New("no such user")
+}
+
+func (m memoryUsers) UpdateRememberToken(_ context.Context, user Authenticatable, hash string) error {
+	m[user.GetID()].remember = hash
+	return nil
+}
+
+// sessionRequestContext is a RequestContext carrying a session, like
+// *http.Context behind the session middleware.
+type sessionRequestContext struct {
+	mockRequestContext
+	sess    *session.Session
+	cookies map[string]*nethttp.Cookie
+}
+
+func (m *sessionRequestContext) Session() *session.Session { return m.sess }
+func (m *sessionRequestContext) SetCookie(cookie *nethttp.Cookie) {
+	m.cookies[cookie.Name] = cookie
+}
+
+func newSessionRequest(t *testing.T, store session.Store, cookies ...*nethttp.Cookie) *sessionRequestContext {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	sess, err := store.Load(req)
+	require.NoError(t, err)
+	return &sessionRequestContext{
+		mockRequestContext: mockRequestContext{req: req},
+		sess:               sess,
+		cookies:            map[string]*nethttp.Cookie{},
+	}
+}
+
+func TestSessionGuard(t *testing.T) {
+	store := session.NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+	users := memoryUsers{"7": {id: "7"}}
+	guard := NewSessionGuard("web", users)
+
+	// Logged out.
+	c := newSessionRequest(t, store)
+	assert.ErrorIs(t, guard.Attempt(c), ErrUnauthenticated)
+	assert.False(t, guard.Check(c))
+
+	// Login with "remember me".
+	_, err := guard.Login(c, users["7"])
+	require.NoError(t, err)
+	require.NoError(t, guard.Remember(c, users["7"]))
+	assert.Equal(t, "7", c.claims.UserID)
+	assert.Equal(t, "7", c.sess.UserID())
+	remember := c.cookies["remember_web"]
+	require.NotNil(t, remember)
+	assert.NotContains(t, users["7"].remember, remember.Value[2:], "only the hash is stored")
+
+	user, err := guard.Authenticate(c)
+	require.NoError(t, err)
+	assert.Equal(t, "7", user.GetID())
+
+	// The session expired, but the remember-me cookie logs the user back in.
+	c = newSessionRequest(t, store, remember)
+	require.NoError(t, guard.Attempt(c))
+	assert.Equal(t, "7", c.claims.UserID)
+	assert.Equal(t, "7", c.sess.UserID())
+
+	// A forged token is rejected and cleared.
+	c = newSessionRequest(t, store, &nethttp.Cookie{Name: "remember_web", Value: "7|forged"})
+	assert.ErrorIs(t, guard.Attempt(c), ErrUnauthenticated)
+	assert.Equal(t, -1, c.cookies["remember_web"].MaxAge)
+
+	// Logout clears the session and the remember token everywhere.
+	c = newSessionRequest(t, store, remember)
+	require.NoError(t, guard.Attempt(c))
+	require.NoError(t, guard.Logout(c))
+	assert.Empty(t, c.sess.UserID())
+	assert.Empty(t, users["7"].remember)
+	assert.Equal(t, -1, c.cookies["remember_web"].MaxAge)
+
+	c = newSessionRequest(t, store, remember)
+	assert.ErrorIs(t, guard.Attempt(c), ErrUnauthenticated)
+}
+
+func TestSessionGuard_CheckIsReadOnly(t *testing.T) {
+	store := session.NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+	users := memoryUsers{"7": {id: "7"}}
+	guard := NewSessionGuard("web", users)
+
+	c := newSessionRequest(t, store)
+	c.req.Header.Set("X-Forwarded-Proto", "https")
+	_, err := guard.Login(c, users["7"])
+	require.NoError(t, err)
+	require.NoError(t, guard.Remember(c, users["7"]))
+	remember := c.cookies["remember_web"]
+	require.NotNil(t, remember)
+	// Path, Secure and SameSite are left to the cookie jar rather than
+	// guessed from the request.
+	assert.Empty(t, remember.Path)
+	assert.False(t, remember.Secure)
+	assert.Zero(t, remember.SameSite)
+	assert.True(t, remember.HttpOnly)
+
+	// A remembered browser without a session: Check neither logs it in nor
+	// touches the cookie.
+	c = newSessionRequest(t, store, remember)
+	assert.False(t, guard.Check(c))
+	assert.Empty(t, c.sess.UserID())
+	assert.Nil(t, c.claims)
+	assert.Empty(t, c.cookies)
+
+	require.NoError(t, guard.Attempt(c))
+	assert.True(t, guard.Check(c))
+}
+
+func TestSessionGuardNeedsSession(t *testing.T) {
+	guard := NewSessionGuard("web", nil)
+	c := &mockRequestContext{req: httptest.NewRequest("GET", "/", nil)}
+	assert.ErrorIs(t, guard.Attempt(c), errNoSession)
+}
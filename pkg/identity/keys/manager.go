@@ -2,10 +2,11 @@ package keys
 
 import (
 	"context"
-	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"time"
+
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 )
 
 // APIKey represents an API key
@@ -346,11 +347,11 @@ func (m *Manager) generateID() string {
 
 // generateRandomString generates a random string
 func (m *Manager) generateRandomString(length int) string {
-	bytes := make([]byte, length)
-	if _, err := rand.Read(bytes); err != nil {
-		panic(fmt.Sprintf("failed to generate random bytes: %v", err))
+	s, err := secure.String(length, secure.URLSafe)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate random string: %v", err))
 	}
-	return base64.URLEncoding.EncodeToString(bytes)[:length]
+	return s
 }
 
 // SimpleEncryptor provides simple encryption for API keys
@@ -0,0 +1,133 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"sync/atomic"
+
+	"github.com/shauryagautam/Astra/pkg/storage"
+)
+
+// DefaultMaxAttachmentSize is the total attachment size a mailer accepts per
+// message unless configured otherwise. Most providers reject larger mail.
+const DefaultMaxAttachmentSize int64 = 25 << 20
+
+// ErrAttachmentTooLarge is returned when a message's attachments exceed the
+// mailer's size limit.
+var ErrAttachmentTooLarge = errors.New("mail: attachments exceed the size limit")
+
+// AttachFile attaches path from a storage disk. The file is streamed when
+// the message is sent, so it is never held in memory as a whole.
+func AttachFile(disk storage.Storage, name string) Attachment {
+	return Attachment{
+		Name: path.Base(name),
+		MIME: mime.TypeByExtension(path.Ext(name)),
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			return storage.Open(ctx, disk, name)
+		},
+	}
+}
+
+// AttachReader attaches the content of r. A reader can only be consumed
+// once, so the message cannot be retried after a failed send.
+func AttachReader(name string, r io.Reader) Attachment {
+	var used atomic.Bool
+	return Attachment{
+		Name: name,
+		MIME: mime.TypeByExtension(path.Ext(name)),
+		Open: func(context.Context) (io.ReadCloser, error) {
+			if used.Swap(true) {
+				return nil, fmt.Errorf("mail: attachment %q was already read", name)
+			}
+			if rc, ok := r.(io.ReadCloser); ok {
+				return rc, nil
+			}
+			return io.NopCloser(r), nil
+		},
+	}
+}
+
+// Inline returns a copy of a that is embedded under cid, for use in HTML as
+// <img src="cid:logo">.
+func (a Attachment) Inline(cid string) Attachment {
+	a.ContentID = cid
+	return a
+}
+
+func (a Attachment) open(ctx context.Context) (io.ReadCloser, error) {
+	if a.Content != nil || a.Open == nil {
+		return io.NopCloser(bytes.NewReader(a.Content)), nil
+	}
+	return a.Open(ctx)
+}
+
+func (a Attachment) mimeType() string {
+	if a.MIME == "" {
+		return "application/octet-stream"
+	}
+	return a.MIME
+}
+
+// attachmentBudget enforces a size limit across all attachments of one
+// message while they are streamed.
+type attachmentBudget struct {
+	remaining int64
+}
+
+func newAttachmentBudget(limit int64) *attachmentBudget {
+	if limit <= 0 {
+		limit = DefaultMaxAttachmentSize
+	}
+	return &attachmentBudget{remaining: limit}
+}
+
+// precheck rejects messages whose in-memory attachments alone are too large,
+// before any connection is made.
+func (b *attachmentBudget) precheck(attachments []Attachment) error {
+	var total int64
+	for _, a := range attachments {
+		total += int64(len(a.Content))
+	}
+	if total > b.remaining {
+		return ErrAttachmentTooLarge
+	}
+	return nil
+}
+
+func (b *attachmentBudget) reader(r io.Reader) io.Reader {
+	return &budgetReader{r: r, budget: b}
+}
+
+type budgetReader struct {
+	r      io.Reader
+	budget *attachmentBudget
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.budget.remaining -= int64(n)
+	if r.budget.remaining < 0 {
+		return n, ErrAttachmentTooLarge
+	}
+	return n, err
+}
+
+// readAttachment loads an attachment into memory within the budget, for
+// drivers whose API needs the whole content.
+func readAttachment(ctx context.Context, a Attachment, budget *attachmentBudget) ([]byte, error) {
+	rc, err := a.open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mail: open attachment %q: %w", a.Name, err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(budget.reader(rc))
+	if err != nil {
+		return nil, fmt.Errorf("mail: read attachment %q: %w", a.Name, err)
+	}
+	return content, nil
+}
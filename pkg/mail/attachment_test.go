@@ -0,0 +1,153 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMIME_InlineAndAttachments(t *testing.T) {
+	disk := storage.NewMemoryStorage()
+	pdf := bytes.Repeat([]byte("%PDF"), 100)
+	require.NoError(t, disk.Put(context.Background(), "invoices/42.pdf", pdf))
+
+	msg := &Message{
+		To:      []string{"a@example.com"},
+		Subject: "Your invoice",
+		HTML:    `<img src="cid:logo"> Thanks!`,
+		Attachments: []Attachment{
+			AttachFile(disk, "invoices/42.pdf"),
+			AttachReader("logo.png", strings.NewReader("png-bytes")).Inline("logo"),
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, writeMIME(context.Background(), &buf, msg, "app@example.com", newAttachmentBudget(0)))
+
+	parsed, err := mail.ReadMessage(&buf)
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mixed := multipart.NewReader(parsed.Body, params["boundary"])
+	body, err := mixed.NextPart()
+	require.NoError(t, err)
+	mediaType, params, err = mime.ParseMediaType(body.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/related", mediaType)
+
+	related := multipart.NewReader(body, params["boundary"])
+	html, err := related.NextPart()
+	require.NoError(t, err)
+	content, err := io.ReadAll(html)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `cid:logo`)
+
+	logo, err := related.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "<logo>", logo.Header.Get("Content-ID"))
+	content, err = io.ReadAll(base64.NewDecoder(base64.StdEncoding, logo))
+	require.NoError(t, err)
+	assert.Equal(t, "png-bytes", string(content))
+
+	file, err := mixed.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "42.pdf", file.FileName())
+	assert.Equal(t, "application/pdf", strings.Split(file.Header.Get("Content-Type"), ";")[0])
+	content, err = io.ReadAll(base64.NewDecoder(base64.StdEncoding, file))
+	require.NoError(t, err)
+	assert.Equal(t, pdf, content)
+}
+
+func TestAttachmentSizeLimit(t *testing.T) {
+	streamed := &Message{To: []string{"a@example.com"}, Body: "hi", Attachments: []Attachment{
+		AttachReader("big.bin", bytes.NewReader(make([]byte, 2048))),
+	}}
+	err := writeMIME(context.Background(), io.Discard, streamed, "app@example.com", newAttachmentBudget(1024))
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+
+	mailer := NewSMTPMailer(config.MailConfig{SMTPFrom: "app@example.com"}, nil).WithMaxAttachmentSize(1024)
+	err = mailer.Send(context.Background(), &Message{To: []string{"a@example.com"}, Attachments: []Attachment{
+		{Name: "big.bin", Content: make([]byte, 2048)},
+	}})
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+}
+
+func TestAttachReader_SingleUse(t *testing.T) {
+	a := AttachReader("a.txt", strings.NewReader("x"))
+	_, err := a.open(context.Background())
+	require.NoError(t, err)
+	_, err = a.open(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSMTPMailer_StreamsToServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	received := make(chan string, 1)
+	go serveSMTPOnce(ln, received)
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	mailer := NewSMTPMailer(config.MailConfig{SMTPHost: host, SMTPPort: portNum, SMTPFrom: "app@example.com"}, nil)
+
+	err = mailer.Send(context.Background(), &Message{
+		To:          []string{"a@example.com"},
+		Subject:     "Report",
+		Body:        "See attached.",
+		Attachments: []Attachment{AttachReader("report.csv", strings.NewReader("a,b\n1,2\n"))},
+	})
+	require.NoError(t, err)
+
+	data := <-received
+	assert.Contains(t, data, "Subject: Report")
+	assert.Contains(t, data, `filename=report.csv`)
+}
+
+// serveSMTPOnce accepts one connection, speaks just enough SMTP for a single
+// message and sends the DATA payload to received.
+func serveSMTPOnce(ln net.Listener, received chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ready")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 localhost")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, _ := io.ReadAll(bufio.NewReader(tp.DotReader()))
+			received <- string(data)
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+)
+
+// dkimHeaders are the headers DKIMSigner signs when the message has them.
+var dkimHeaders = []string{
+	"From", "To", "Cc", "Reply-To", "Subject", "Date", "Message-ID",
+	"MIME-Version", "Content-Type", "List-Unsubscribe", "List-Unsubscribe-Post",
+}
+
+// DKIMSigner adds a DKIM-Signature (RFC 6376) to outgoing mail, so
+// receivers can verify it was sent for domain without relaying through a
+// provider that signs on your behalf. Publish the public key as a TXT
+// record at <selector>._domainkey.<domain>.
+//
+// Headers and body use relaxed canonicalization. RSA keys sign with
+// rsa-sha256 and Ed25519 keys with ed25519-sha256 (RFC 8463).
+type DKIMSigner struct {
+	domain   string
+	selector string
+	key      crypto.Signer
+	algo     string
+	now      func() time.Time
+}
+
+// NewDKIMSigner returns a signer for domain and selector. key must be an
+// *rsa.PrivateKey of at least 1024 bits or an ed25519.PrivateKey; load one
+// from PEM with ParseDKIMKey, for example after fetching it from a secret
+// manager.
+func NewDKIMSigner(domain, selector string, key crypto.Signer) (*DKIMSigner, error) {
+	if domain == "" || selector == "" {
+		return nil, errors.New("mail: dkim domain and selector are required")
+	}
+	s := &DKIMSigner{domain: domain, selector: selector, key: key, now: time.Now}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 1024 {
+			return nil, fmt.Errorf("mail: dkim rsa key has %d bits, want at least 1024", k.N.BitLen())
+		}
+		s.algo = "rsa-sha256"
+	case ed25519.PrivateKey:
+		s.algo = "ed25519-sha256"
+	default:
+		return nil, fmt.Errorf("mail: unsupported dkim key type %T", key)
+	}
+	return s, nil
+}
+
+// ParseDKIMKey parses a PEM encoded RSA (PKCS #1 or PKCS #8) or Ed25519
+// (PKCS #8) private key.
+func ParseDKIMKey(pemBytes []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("mail: dkim key is not PEM encoded")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("mail: parse dkim key: %w", err)
+		}
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("mail: parse dkim key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("mail: unsupported dkim key type %T", key)
+	}
+	return signer, nil
+}
+
+// DKIMSignerFromConfig builds the signer configured by DKIM_DOMAIN,
+// DKIM_SELECTOR and either DKIM_PRIVATE_KEY (the PEM itself, as secret
+// managers usually inject it) or DKIM_PRIVATE_KEY_FILE (a mounted secret).
+// It returns nil without an error when DKIM_DOMAIN is empty.
+func DKIMSignerFromConfig(cfg config.MailConfig) (*DKIMSigner, error) {
+	if cfg.DKIMDomain == "" {
+		return nil, nil
+	}
+	pemBytes := []byte(strings.ReplaceAll(cfg.DKIMPrivateKey, `\n`, "\n"))
+	if cfg.DKIMPrivateKey == "" {
+		if cfg.DKIMPrivateKeyFile == "" {
+			return nil, errors.New("mail: DKIM_DOMAIN is set without DKIM_PRIVATE_KEY or DKIM_PRIVATE_KEY_FILE")
+		}
+		var err error
+		if pemBytes, err = os.ReadFile(cfg.DKIMPrivateKeyFile); err != nil {
+			return nil, fmt.Errorf("mail: read dkim key: %w", err)
+		}
+	}
+	key, err := ParseDKIMKey(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	return NewDKIMSigner(cfg.DKIMDomain, cfg.DKIMSelector, key)
+}
+
+// Sign reads a complete RFC 5322 message from r and returns the
+// DKIM-Signature header to prepend to it, including the trailing CRLF.
+func (s *DKIMSigner) Sign(r io.Reader) (string, error) {
+	br := bufio.NewReader(r)
+	headers, err := readHeaders(br)
+	if err != nil {
+		return "", fmt.Errorf("mail: dkim: %w", err)
+	}
+	bodyHash := sha256.New()
+	if err := canonicalizeBody(bodyHash, br); err != nil {
+		return "", fmt.Errorf("mail: dkim: %w", err)
+	}
+
+	var signed []string
+	h := sha256.New()
+	for _, name := range dkimHeaders {
+		if field, ok := lastHeader(headers, name); ok {
+			signed = append(signed, strings.ToLower(name))
+			io.WriteString(h, canonicalizeHeader(field)+"\r\n")
+		}
+	}
+	if !slices.Contains(signed, "from") {
+		return "", errors.New("mail: dkim: message has no From header")
+	}
+
+	tags := []string{
+		"v=1",
+		"a=" + s.algo,
+		"c=relaxed/relaxed",
+		"d=" + s.domain,
+		"s=" + s.selector,
+		"t=" + strconv.FormatInt(s.now().Unix(), 10),
+		"h=" + strings.Join(signed, ":"),
+		"bh=" + base64.StdEncoding.EncodeToString(bodyHash.Sum(nil)),
+		"b=",
+	}
+	field := "DKIM-Signature: " + strings.Join(tags, "; ")
+	io.WriteString(h, canonicalizeHeader(field))
+
+	var sig []byte
+	if s.algo == "rsa-sha256" {
+		sig, err = s.key.Sign(rand.Reader, h.Sum(nil), crypto.SHA256)
+	} else {
+		sig, err = s.key.Sign(rand.Reader, h.Sum(nil), crypto.Hash(0))
+	}
+	if err != nil {
+		return "", fmt.Errorf("mail: dkim: %w", err)
+	}
+	// Folding between tags does not change the relaxed form that was signed.
+	return strings.ReplaceAll(field, "; ", ";\r\n ") + base64.StdEncoding.EncodeToString(sig) + "\r\n", nil
+}
+
+// signed renders a message into a temporary file, so its body can be
+// hashed before anything is sent, and returns a write func that streams
+// the signature and the message. The message is rendered once, so retries
+// replay the file rather than reopening attachments. cleanup removes
+// the file.
+func (s *DKIMSigner) signed(render func(io.Writer) error) (write func(io.Writer) error, cleanup func(), err error) {
+	f, err := os.CreateTemp("", "astra-mail-*.eml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("mail: dkim: %w", err)
+	}
+	cleanup = func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	bw := bufio.NewWriter(f)
+	if err := render(bw); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("mail: dkim: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("mail: dkim: %w", err)
+	}
+	signature, err := s.Sign(f)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	write = func(w io.Writer) error {
+		if _, err := io.WriteString(w, signature); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		_, err := io.Copy(w, f)
+		return err
+	}
+	return write, cleanup, nil
+}
+
+// readHeaders reads the header block, joining folded lines, up to the
+// blank line before the body.
+func readHeaders(r *bufio.Reader) ([]string, error) {
+	var headers []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			return headers, nil
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(headers) > 0 {
+			headers[len(headers)-1] += "\r\n" + line
+		} else {
+			headers = append(headers, line)
+		}
+		if err == io.EOF {
+			return headers, nil
+		}
+	}
+}
+
+// lastHeader returns the last field named name; a signer signs the
+// bottom-most instance of a repeated header.
+func lastHeader(headers []string, name string) (string, bool) {
+	for _, field := range slices.Backward(headers) {
+		key, _, ok := strings.Cut(field, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// canonicalizeHeader applies the relaxed header canonicalization of
+// RFC 6376 section 3.4.2, without the trailing CRLF.
+func canonicalizeHeader(field string) string {
+	key, value, _ := strings.Cut(field, ":")
+	value = strings.NewReplacer("\r\n", "").Replace(value)
+	return strings.ToLower(strings.TrimSpace(key)) + ":" + strings.Join(strings.Fields(value), " ")
+}
+
+// canonicalizeBody writes the relaxed canonical form of the body in r to
+// h (RFC 6376 section 3.4.4): whitespace runs become one space, trailing
+// whitespace and trailing blank lines are dropped, and a non-empty body
+// ends with CRLF.
+func canonicalizeBody(h hash.Hash, r *bufio.Reader) error {
+	blank := 0
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimRight(line, "\r\n")
+			canon := collapseWhitespace(line)
+			if len(canon) == 0 {
+				blank++
+			} else {
+				for ; blank > 0; blank-- {
+					h.Write([]byte("\r\n"))
+				}
+				h.Write(canon)
+				h.Write([]byte("\r\n"))
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func collapseWhitespace(line []byte) []byte {
+	out := make([]byte, 0, len(line))
+	space := false
+	for _, c := range line {
+		if c == ' ' || c == '\t' {
+			space = true
+			continue
+		}
+		if space {
+			out = append(out, ' ')
+			space = false
+		}
+		out = append(out, c)
+	}
+	return out
+}
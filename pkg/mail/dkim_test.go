@@ -0,0 +1,178 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+)
+
+func TestDKIMCanonicalization(t *testing.T) {
+	// The example from RFC 6376 section 3.4.5.
+	assert.Equal(t, "a:X", canonicalizeHeader("A: X"))
+	assert.Equal(t, "b:Y Z", canonicalizeHeader("B : Y\t\r\n\tZ  "))
+
+	h := sha256.New()
+	require.NoError(t, canonicalizeBody(h, bufio.NewReader(strings.NewReader(" C \r\nD \t E\r\n\r\n\r\n"))))
+	want := sha256.Sum256([]byte(" C\r\nD E\r\n"))
+	assert.Equal(t, want[:], h.Sum(nil))
+}
+
+func TestDKIMSigner_Sign(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	msg := "From: app@example.com\r\nTo: a@example.com\r\nSubject: Hi\r\nX-Other: skipped\r\n\r\nHello  there \r\n\r\n"
+	for name, key := range map[string]crypto.Signer{"ed25519": edKey, "rsa": rsaKey} {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewDKIMSigner("example.com", "mail", key)
+			require.NoError(t, err)
+			signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+			header, err := signer.Sign(strings.NewReader(msg))
+			require.NoError(t, err)
+			assert.Contains(t, header, "d=example.com;")
+			assert.Contains(t, header, "s=mail;")
+			assert.Contains(t, header, "t=1700000000;")
+			assert.Contains(t, header, "h=from:to:subject;")
+			verifyDKIM(t, header+msg, key.Public())
+		})
+	}
+}
+
+func TestDKIMSigner_Errors(t *testing.T) {
+	_, err := NewDKIMSigner("", "mail", nil)
+	assert.Error(t, err)
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := NewDKIMSigner("example.com", "mail", edKey)
+	require.NoError(t, err)
+	_, err = signer.Sign(strings.NewReader("To: a@example.com\r\n\r\nhi\r\n"))
+	assert.ErrorContains(t, err, "no From header")
+}
+
+func TestDKIMSignerFromConfig(t *testing.T) {
+	signer, err := DKIMSignerFromConfig(config.MailConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, signer)
+
+	_, err = DKIMSignerFromConfig(config.MailConfig{DKIMDomain: "example.com", DKIMSelector: "mail"})
+	assert.Error(t, err)
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	// Secret managers often flatten the PEM onto one line with literal \n.
+	signer, err = DKIMSignerFromConfig(config.MailConfig{
+		DKIMDomain: "example.com", DKIMSelector: "mail",
+		DKIMPrivateKey: strings.ReplaceAll(pemKey, "\n", `\n`),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, signer)
+	assert.Equal(t, "ed25519-sha256", signer.algo)
+}
+
+func TestSMTPMailer_SignsWithDKIM(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.failCode = 451
+	srv.failures.Store(1)
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := NewDKIMSigner("example.com", "mail", edKey)
+	require.NoError(t, err)
+	mailer := NewSMTPMailer(srv.config(), nil).WithRetry(1, time.Millisecond).WithDKIM(signer)
+
+	msg := (&Message{
+		To:          []string{"a@example.com"},
+		Subject:     "Weekly digest",
+		HTML:        "<p>news</p>",
+		Attachments: []Attachment{AttachReader("digest.txt", strings.NewReader("one-shot reader"))},
+	}).SetListUnsubscribe("https://example.com/u/1", "").MarkBulk()
+	require.NoError(t, mailer.Send(context.Background(), msg), "the signed message is replayed on retry")
+
+	require.Len(t, srv.received(), 1)
+	data := srv.received()[0]
+	require.True(t, strings.HasPrefix(data, "DKIM-Signature: v=1;"))
+	assert.Contains(t, data, "h=from:to:subject:message-id:mime-version:content-type:list-unsubscribe:list-unsubscribe-post;")
+	verifyDKIM(t, strings.ReplaceAll(data, "\n", "\r\n"), edKey.Public())
+}
+
+// verifyDKIM checks the DKIM-Signature at the top of signed against key,
+// following the verifier steps of RFC 6376 section 6.1.3.
+func verifyDKIM(t *testing.T, signed string, key crypto.PublicKey) {
+	t.Helper()
+	br := bufio.NewReader(strings.NewReader(signed))
+	headers, err := readHeaders(br)
+	require.NoError(t, err)
+	sigField := headers[0]
+	require.True(t, strings.HasPrefix(sigField, "DKIM-Signature:"))
+
+	tags := map[string]string{}
+	for _, tag := range strings.Split(strings.ReplaceAll(sigField[len("DKIM-Signature:"):], "\r\n", ""), ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(tag), "=")
+		tags[k] = v
+	}
+
+	bodyHash := sha256.New()
+	require.NoError(t, canonicalizeBody(bodyHash, br))
+	assert.Equal(t, tags["bh"], base64.StdEncoding.EncodeToString(bodyHash.Sum(nil)), "body hash")
+
+	h := sha256.New()
+	for _, name := range strings.Split(tags["h"], ":") {
+		field, ok := lastHeader(headers[1:], name)
+		require.True(t, ok, name)
+		h.Write([]byte(canonicalizeHeader(field) + "\r\n"))
+	}
+	unsigned := regexp.MustCompile(`b=[^;]*$`).ReplaceAllString(sigField, "b=")
+	h.Write([]byte(canonicalizeHeader(unsigned)))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	require.NoError(t, err)
+	switch pub := key.(type) {
+	case ed25519.PublicKey:
+		assert.True(t, ed25519.Verify(pub, h.Sum(nil), sig), "ed25519 signature")
+	case *rsa.PublicKey:
+		assert.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, h.Sum(nil), sig))
+	default:
+		t.Fatalf("unexpected key %T", key)
+	}
+}
+
+func TestMessageHeaders(t *testing.T) {
+	msg := (&Message{To: []string{"a@example.com"}, Subject: "Hi", Body: "x"}).
+		SetListUnsubscribe("https://example.com/u/1", "mailto:unsub@example.com").
+		SetHeader("X-Campaign", "spring\r\nBcc: victim@example.com").
+		SetHeader("subject", "overridden")
+
+	var buf bytes.Buffer
+	require.NoError(t, writeMIME(context.Background(), &buf, msg, "app@example.com", newAttachmentBudget(0)))
+	out := buf.String()
+
+	assert.Contains(t, out, "List-Unsubscribe: <https://example.com/u/1>, <mailto:unsub@example.com>\r\n")
+	assert.Contains(t, out, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	assert.Contains(t, out, "X-Campaign: springBcc: victim@example.com\r\n", "line breaks are stripped")
+	assert.Equal(t, 1, strings.Count(out, "Subject:"), "reserved headers cannot be replaced")
+
+	plain := (&Message{}).SetListUnsubscribe("http://example.com/u/1", "")
+	assert.NotContains(t, plain.Headers, "List-Unsubscribe-Post", "one-click needs https")
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+)
+
+// NewMailer returns the Mailer selected by MAIL_DRIVER: "smtp" (the
+// default), "resend", or "preview" (alias "log"), which stores messages in
+// DefaultPreviewDir for local inspection instead of sending them.
+//
+// The SMTP driver signs mail with DKIM when DKIM_DOMAIN is set (see
+// DKIMSignerFromConfig). When MAIL_FALLBACK_DRIVER names another driver, the result is a
+// FailoverMailer that uses it whenever the first driver fails.
+func NewMailer(cfg config.MailConfig, emitter *event.Emitter) (Mailer, error) {
+	primary, err := newDriver(cfg.Driver, cfg, emitter)
+	if err != nil || strings.TrimSpace(cfg.FallbackDriver) == "" {
+		return primary, err
+	}
+	fallback, err := newDriver(cfg.FallbackDriver, cfg, emitter)
+	if err != nil {
+		return nil, err
+	}
+	return NewFailoverMailer(primary, fallback), nil
+}
+
+func newDriver(driver string, cfg config.MailConfig, emitter *event.Emitter) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "", "smtp":
+		signer, err := DKIMSignerFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewSMTPMailer(cfg, emitter).WithDKIM(signer), nil
+	case "resend":
+		return NewResendMailer(cfg, emitter), nil
+	case "preview", "log":
+		return NewPreviewMailer(DefaultPreviewDir), nil
+	default:
+		return nil, fmt.Errorf("mail: unknown driver %q (want smtp, resend or preview)", driver)
+	}
+}
@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// FailoverMailer sends through its first mailer and falls back to the next
+// one when a send fails, so an outage of the primary provider does not stop
+// mail:
+//
+//	mailer := mail.NewFailoverMailer(
+//		mail.NewSMTPMailer(cfg, emitter),
+//		mail.NewResendMailer(cfg, emitter),
+//	)
+//
+// Every mailer sees the same *Message, so all attempts share one message
+// ID. Errors that another transport cannot fix, ErrAttachmentTooLarge and
+// a cancelled context, are returned without failing over, as is
+// ErrMaybeDelivered, since the message may already be on its way. Messages
+// with an AttachReader attachment can only be tried once.
+type FailoverMailer struct {
+	mailers []Mailer
+}
+
+// NewFailoverMailer returns a FailoverMailer trying primary, then each
+// fallback in order.
+func NewFailoverMailer(primary Mailer, fallbacks ...Mailer) *FailoverMailer {
+	return &FailoverMailer{mailers: append([]Mailer{primary}, fallbacks...)}
+}
+
+// Send sends msg through the first mailer that accepts it. When all of them
+// fail, the error joins each mailer's error.
+func (f *FailoverMailer) Send(ctx context.Context, msg *Message) error {
+	var errs []error
+	for i, m := range f.mailers {
+		err := m.Send(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || errors.Is(err, ErrAttachmentTooLarge) || errors.Is(err, ErrMaybeDelivered) {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("mailer %d: %w", i, err))
+		if i < len(f.mailers)-1 {
+			slog.Warn("mail: mailer failed, failing over to the next one", "mailer", i, "error", err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the mailers that hold connections, such as a pooled
+// SMTPMailer.
+func (f *FailoverMailer) Close() error {
+	var errs []error
+	for _, m := range f.mailers {
+		if c, ok := m.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package mail
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// reservedHeaders are written by the mailer itself and cannot be set
+// through Message.Headers.
+var reservedHeaders = map[string]bool{
+	"To":                        true,
+	"From":                      true,
+	"Subject":                   true,
+	"Message-Id":                true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+	"Dkim-Signature":            true,
+}
+
+// SetHeader sets an extra header on the message, replacing any earlier
+// value. The headers the mailer writes itself, such as From, Subject and
+// Content-Type, cannot be set this way and are ignored.
+func (m *Message) SetHeader(key, value string) *Message {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[textproto.CanonicalMIMEHeaderKey(key)] = value
+	return m
+}
+
+// SetListUnsubscribe adds the List-Unsubscribe header (RFC 2369) that mail
+// clients turn into an unsubscribe button. Either argument may be empty.
+// An https url also gets List-Unsubscribe-Post (RFC 8058), so the client
+// unsubscribes with a single POST of "List-Unsubscribe=One-Click" to url;
+// Gmail and Yahoo require this of bulk senders:
+//
+//	msg.SetListUnsubscribe("https://example.com/unsubscribe/"+token, "unsubscribe@example.com")
+func (m *Message) SetListUnsubscribe(url, mailto string) *Message {
+	var targets []string
+	if url != "" {
+		targets = append(targets, "<"+url+">")
+	}
+	if mailto != "" {
+		targets = append(targets, "<mailto:"+strings.TrimPrefix(mailto, "mailto:")+">")
+	}
+	if len(targets) == 0 {
+		return m
+	}
+	m.SetHeader("List-Unsubscribe", strings.Join(targets, ", "))
+	if strings.HasPrefix(url, "https://") {
+		m.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	return m
+}
+
+// MarkBulk marks newsletters, digests and other mail sent to many
+// recipients: Precedence: bulk and Auto-Submitted: auto-generated keep
+// vacation responders and out-of-office replies from answering it.
+func (m *Message) MarkBulk() *Message {
+	m.SetHeader("Precedence", "bulk")
+	m.SetHeader("Auto-Submitted", "auto-generated")
+	m.SetHeader("X-Auto-Response-Suppress", "OOF, AutoReply")
+	return m
+}
+
+// writableHeader reports whether key may be written from Message.Headers:
+// it is not one of the mailer's own headers and is a valid field name.
+func writableHeader(key string) bool {
+	if key == "" || reservedHeaders[key] {
+		return false
+	}
+	for _, c := range key {
+		if c <= ' ' || c > '~' || c == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// headerValue strips line breaks from v, so a value taken from user input
+// cannot inject headers.
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
@@ -60,3 +60,37 @@ func TestMessageValidation(t *testing.T) {
 	// Although SMTPMailer.Send has validation, Message itself might need it
 	// But Message is just a struct. Let's test the logic in SMTPMailer indirectly if we can.
 }
+
+func TestPreviewMailer(t *testing.T) {
+	preview := NewPreviewMailer(t.TempDir())
+
+	mails, err := preview.List()
+	require.NoError(t, err)
+	assert.Empty(t, mails)
+
+	require.NoError(t, preview.Send(context.Background(), &Message{
+		From: "app@example.com", To: []string{"a@example.com"}, Subject: "First", Body: "hello",
+	}))
+	require.NoError(t, preview.Send(context.Background(), &Message{
+		To: []string{"b@example.com"}, Subject: "Second", HTML: "<p>hi</p>",
+		Attachments: []Attachment{{Name: "a.txt", Content: []byte("abc"), MIME: "text/plain"}},
+	}))
+
+	mails, err = preview.List()
+	require.NoError(t, err)
+	require.Len(t, mails, 2)
+	assert.Equal(t, "Second", mails[0].Subject, "newest first")
+	assert.Equal(t, []PreviewAttachment{{Name: "a.txt", MIME: "text/plain", Size: 3}}, mails[0].Attachments)
+
+	found, err := preview.Find(mails[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", found.Text)
+
+	_, err = preview.Find("../../etc/passwd")
+	assert.ErrorIs(t, err, ErrPreviewNotFound)
+
+	require.NoError(t, preview.Clear())
+	mails, err = preview.List()
+	require.NoError(t, err)
+	assert.Empty(t, mails)
+}
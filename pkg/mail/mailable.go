@@ -49,6 +49,32 @@ type MailableLayout interface {
 	Layout() string
 }
 
+// MailableAttachments is optionally implemented by a Mailable to attach
+// files. Inline attachments are referenced from the template as
+// <img src="cid:logo">:
+//
+//	func (m *InvoiceMail) Attachments() []mail.Attachment {
+//	    return []mail.Attachment{
+//	        mail.AttachFile(disk, "invoices/"+m.Invoice.ID+".pdf"),
+//	        mail.AttachFile(disk, "brand/logo.png").Inline("logo"),
+//	    }
+//	}
+type MailableAttachments interface {
+	Mailable
+	Attachments() []Attachment
+}
+
+// MailableHeaders is optionally implemented by a Mailable to add headers
+// to the rendered message:
+//
+//	func (m *DigestMail) Headers() map[string]string {
+//	    return map[string]string{"Precedence": "bulk", "List-Id": "<digest.example.com>"}
+//	}
+type MailableHeaders interface {
+	Mailable
+	Headers() map[string]string
+}
+
 // MailableSender can send Mailable instances.
 // Combine with TemplateMailer to get HTML rendering + delivery.
 type MailableSender interface {
@@ -122,12 +148,21 @@ func (tm *TemplateMailer) SendMailable(m Mailable) (*Message, error) {
 		from = tm.defaultFrom
 	}
 
-	return &Message{
+	msg := &Message{
 		From:    from,
 		To:      m.To(),
 		Subject: m.Subject(),
 		HTML:    html,
-	}, nil
+	}
+	if ma, ok := m.(MailableAttachments); ok {
+		msg.Attachments = ma.Attachments()
+	}
+	if mh, ok := m.(MailableHeaders); ok {
+		for k, v := range mh.Headers() {
+			msg.SetHeader(k, v)
+		}
+	}
+	return msg, nil
 }
 
 // QueueMailable renders the mailable and returns a background job to send it.
@@ -136,6 +171,11 @@ func (tm *TemplateMailer) QueueMailable(m Mailable) (*queue.GenericJob[QueuedMai
 	if err != nil {
 		return nil, err
 	}
+	for _, a := range msg.Attachments {
+		if a.Content == nil && a.Open != nil {
+			return nil, fmt.Errorf("mail: attachment %q is streamed and cannot be queued; send the mailable directly", a.Name)
+		}
+	}
 	return NewQueuedMail(msg, "default"), nil
 }
 
@@ -1,12 +1,27 @@
 package mail
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
 
-// Attachment represents a file attached to an email.
+	"github.com/shauryagautam/Astra/pkg/support/secure"
+)
+
+// Attachment represents a file attached to an email. Content holds small
+// in-memory files; larger ones set Open instead (see AttachFile and
+// AttachReader) so drivers can stream them without buffering.
 type Attachment struct {
 	Name    string
 	Content []byte
 	MIME    string
+	// ContentID makes the attachment an inline part that HTML can reference
+	// as <img src="cid:ContentID">.
+	ContentID string
+	// Open returns the content when Content is nil. Streamed attachments
+	// cannot be serialized, so queued mail must carry Content instead.
+	Open func(ctx context.Context) (io.ReadCloser, error) `json:"-"`
 }
 
 // Message represents an email message.
@@ -17,9 +32,46 @@ type Message struct {
 	Body        string
 	HTML        string
 	Attachments []Attachment
+	// MessageID is the Message-ID header, without angle brackets. Drivers
+	// generate one when it is empty, so it can be read back after Send to
+	// correlate the mail.sent and mail.failed events and bounces.
+	MessageID string
+	// Headers holds extra headers such as List-Unsubscribe; see SetHeader,
+	// SetListUnsubscribe and MarkBulk.
+	Headers map[string]string
 }
 
 // Mailer defines the interface for sending emails.
 type Mailer interface {
 	Send(ctx context.Context, msg *Message) error
 }
+
+// ensureMessageID gives msg a Message-ID in the sender's domain unless it
+// already has one. Failover and retries reuse it, so every attempt at a
+// message is reported under the same ID.
+func ensureMessageID(msg *Message, from string) error {
+	if msg.MessageID != "" {
+		return nil
+	}
+	domain := "localhost"
+	if at := strings.LastIndexByte(from, '@'); at >= 0 {
+		domain = strings.TrimRight(from[at+1:], "> ")
+	}
+	id, err := secure.Hex(16)
+	if err != nil {
+		return fmt.Errorf("mail: message id: %w", err)
+	}
+	msg.MessageID = id + "@" + domain
+	return nil
+}
+
+// eventPayload is the payload of the mail.sent and mail.failed events.
+func eventPayload(driver string, msg *Message, from string) map[string]any {
+	return map[string]any{
+		"driver":     driver,
+		"message_id": msg.MessageID,
+		"to":         msg.To,
+		"subject":    msg.Subject,
+		"from":       from,
+	}
+}
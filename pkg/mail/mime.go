@@ -0,0 +1,165 @@
+package mail
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"slices"
+	"strings"
+)
+
+// mimePart is one node of a MIME tree: its headers and a func that streams
+// its content.
+type mimePart struct {
+	header textproto.MIMEHeader
+	write  func(w io.Writer) error
+}
+
+// writeMIME streams msg to w as an RFC 5322 message. Attachments are read
+// and base64-encoded on the fly, so their size does not affect memory use.
+//
+// Regular attachments make the message multipart/mixed; inline attachments
+// wrap the HTML body in multipart/related.
+func writeMIME(ctx context.Context, w io.Writer, msg *Message, from string, budget *attachmentBudget) error {
+	var inline, attached []mimePart
+	for _, a := range msg.Attachments {
+		if a.ContentID != "" && msg.HTML != "" {
+			inline = append(inline, attachmentPart(ctx, a, "inline", budget))
+		} else {
+			attached = append(attached, attachmentPart(ctx, a, "attachment", budget))
+		}
+	}
+
+	root := textPart(msg)
+	if len(inline) > 0 {
+		root = multipartPart("related", append([]mimePart{root}, inline...))
+	}
+	if len(attached) > 0 {
+		root = multipartPart("mixed", append([]mimePart{root}, attached...))
+	}
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "To: %s\r\n", strings.Join(msg.To, ","))
+	fmt.Fprintf(&header, "From: %s\r\n", from)
+	fmt.Fprintf(&header, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	if msg.MessageID != "" {
+		fmt.Fprintf(&header, "Message-ID: <%s>\r\n", msg.MessageID)
+	}
+	for _, k := range slices.Sorted(maps.Keys(msg.Headers)) {
+		if key := textproto.CanonicalMIMEHeaderKey(k); writableHeader(key) {
+			fmt.Fprintf(&header, "%s: %s\r\n", key, headerValue(msg.Headers[k]))
+		}
+	}
+	header.WriteString("MIME-Version: 1.0\r\n")
+	for _, k := range slices.Sorted(maps.Keys(root.header)) {
+		fmt.Fprintf(&header, "%s: %s\r\n", k, root.header.Get(k))
+	}
+	header.WriteString("\r\n")
+	if _, err := io.WriteString(w, header.String()); err != nil {
+		return err
+	}
+	return root.write(w)
+}
+
+func textPart(msg *Message) mimePart {
+	contentType, content := "text/plain", msg.Body
+	if msg.HTML != "" {
+		contentType, content = "text/html", msg.HTML
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", contentType+"; charset=UTF-8")
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+	return mimePart{header: h, write: func(w io.Writer) error {
+		qp := quotedprintable.NewWriter(w)
+		if _, err := io.WriteString(qp, content); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\r\n")
+		return err
+	}}
+}
+
+func attachmentPart(ctx context.Context, a Attachment, disposition string, budget *attachmentBudget) mimePart {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", mime.FormatMediaType(a.mimeType(), map[string]string{"name": a.Name}))
+	h.Set("Content-Transfer-Encoding", "base64")
+	h.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Name}))
+	if disposition == "inline" {
+		h.Set("Content-ID", "<"+a.ContentID+">")
+	}
+	return mimePart{header: h, write: func(w io.Writer) error {
+		rc, err := a.open(ctx)
+		if err != nil {
+			return fmt.Errorf("mail: open attachment %q: %w", a.Name, err)
+		}
+		defer rc.Close()
+
+		enc := base64.NewEncoder(base64.StdEncoding, &lineWriter{w: w})
+		if _, err := io.Copy(enc, budget.reader(rc)); err != nil {
+			return fmt.Errorf("mail: attachment %q: %w", a.Name, err)
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "\r\n")
+		return err
+	}}
+}
+
+func multipartPart(subtype string, parts []mimePart) mimePart {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", "multipart/"+subtype+"; boundary="+boundary)
+	return mimePart{header: h, write: func(w io.Writer) error {
+		mw := multipart.NewWriter(w)
+		if err := mw.SetBoundary(boundary); err != nil {
+			return err
+		}
+		for _, p := range parts {
+			pw, err := mw.CreatePart(p.header)
+			if err != nil {
+				return err
+			}
+			if err := p.write(pw); err != nil {
+				return err
+			}
+		}
+		return mw.Close()
+	}}
+}
+
+// lineWriter breaks base64 output into 76-character lines as RFC 2045
+// requires.
+type lineWriter struct {
+	w   io.Writer
+	col int
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), 76-l.col)
+		if _, err := l.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		l.col += n
+		p = p[n:]
+		if l.col == 76 {
+			if _, err := io.WriteString(l.w, "\r\n"); err != nil {
+				return written, err
+			}
+			l.col = 0
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,165 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shauryagautam/Astra/pkg/engine/json"
+)
+
+// DefaultPreviewDir is where PreviewMailer stores messages when no directory
+// is given. It sits under storage/tmp so it is never committed or deployed.
+const DefaultPreviewDir = "storage/tmp/mails"
+
+// ErrPreviewNotFound is returned by PreviewMailer.Find for unknown ids.
+var ErrPreviewNotFound = errors.New("mail: preview not found")
+
+var previewIDPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{15}-[0-9a-f]{8}$`)
+
+// PreviewAttachment describes an attachment of a previewed message. The
+// content itself is not kept, and streamed attachments are not read, so
+// their Size is 0.
+type PreviewAttachment struct {
+	Name      string `json:"name"`
+	MIME      string `json:"mime,omitempty"`
+	ContentID string `json:"content_id,omitempty"`
+	Size      int    `json:"size"`
+}
+
+// PreviewMail is a message captured by PreviewMailer.
+type PreviewMail struct {
+	ID          string              `json:"id"`
+	From        string              `json:"from"`
+	To          []string            `json:"to"`
+	Subject     string              `json:"subject"`
+	Text        string              `json:"text,omitempty"`
+	HTML        string              `json:"html,omitempty"`
+	Attachments []PreviewAttachment `json:"attachments,omitempty"`
+	Headers     map[string]string   `json:"headers,omitempty"`
+	SentAt      time.Time           `json:"sent_at"`
+}
+
+// PreviewMailer is a development driver that stores rendered messages as
+// JSON files instead of delivering them. Unlike the Cockpit mail sandbox the
+// messages survive restarts, and http.RegisterMailPreviewRoutes serves them
+// at /___mails so they can be inspected in a browser.
+type PreviewMailer struct {
+	dir string
+}
+
+// NewPreviewMailer creates a PreviewMailer writing to dir, or to
+// DefaultPreviewDir when dir is empty.
+func NewPreviewMailer(dir string) *PreviewMailer {
+	if dir == "" {
+		dir = DefaultPreviewDir
+	}
+	return &PreviewMailer{dir: filepath.Clean(dir)}
+}
+
+// Dir returns the directory messages are written to.
+func (m *PreviewMailer) Dir() string { return m.dir }
+
+// Send stores msg in the preview directory.
+func (m *PreviewMailer) Send(ctx context.Context, msg *Message) error {
+	if msg == nil {
+		return fmt.Errorf("mail: message is nil")
+	}
+	if err := os.MkdirAll(m.dir, 0750); err != nil {
+		return fmt.Errorf("mail: create preview directory: %w", err)
+	}
+
+	now := time.Now()
+	mail := PreviewMail{
+		// Ids sort chronologically, so List can order by file name alone.
+		ID:      fmt.Sprintf("%s%09d-%s", now.UTC().Format("20060102T150405"), now.Nanosecond(), uuid.NewString()[:8]),
+		From:    msg.From,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Text:    msg.Body,
+		HTML:    msg.HTML,
+		Headers: msg.Headers,
+		SentAt:  now,
+	}
+	for _, a := range msg.Attachments {
+		mail.Attachments = append(mail.Attachments, PreviewAttachment{Name: a.Name, MIME: a.MIME, ContentID: a.ContentID, Size: len(a.Content)})
+	}
+
+	body, err := json.MarshalIndent(mail, "", "  ")
+	if err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	return os.WriteFile(filepath.Join(m.dir, mail.ID+".json"), body, 0600)
+}
+
+// List returns the stored messages, newest first.
+func (m *PreviewMailer) List() ([]PreviewMail, error) {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []PreviewMail{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mails := make([]PreviewMail, 0, len(entries))
+	for _, entry := range slices.Backward(entries) {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !previewIDPattern.MatchString(id) {
+			continue
+		}
+		mail, err := m.Find(id)
+		if err != nil {
+			continue
+		}
+		mails = append(mails, mail)
+	}
+	return mails, nil
+}
+
+// Find loads a single stored message.
+func (m *PreviewMailer) Find(id string) (PreviewMail, error) {
+	if !previewIDPattern.MatchString(id) {
+		return PreviewMail{}, ErrPreviewNotFound
+	}
+	body, err := os.ReadFile(filepath.Join(m.dir, id+".json")) // #nosec G304 -- id is validated against previewIDPattern
+	if errors.Is(err, os.ErrNotExist) {
+		return PreviewMail{}, ErrPreviewNotFound
+	}
+	if err != nil {
+		return PreviewMail{}, err
+	}
+	var mail PreviewMail
+	if err := json.Unmarshal(body, &mail); err != nil {
+		return PreviewMail{}, fmt.Errorf("mail: decode preview %s: %w", id, err)
+	}
+	return mail, nil
+}
+
+// Clear deletes every stored message.
+func (m *PreviewMailer) Clear() error {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !previewIDPattern.MatchString(id) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/shauryagautam/Astra/pkg/engine/json"
 	nethttp "net/http"
+	"net/textproto"
 	"time"
 
 	"github.com/shauryagautam/Astra/pkg/engine/config"
@@ -15,9 +16,10 @@ import (
 
 // ResendMailer implements the Mailer interface using Resend.com.
 type ResendMailer struct {
-	config config.MailConfig
-	events *event.Emitter
-	cb     *fault_tolerance.CircuitBreaker
+	config            config.MailConfig
+	events            *event.Emitter
+	cb                *fault_tolerance.CircuitBreaker
+	maxAttachmentSize int64
 }
 
 // NewResendMailer creates a new ResendMailer.
@@ -29,29 +31,47 @@ func NewResendMailer(cfg config.MailConfig, emitter *event.Emitter) *ResendMaile
 	}
 }
 
-// Send sends an email via Resend HTTP API.
+// WithMaxAttachmentSize limits the total attachment bytes per message.
+// The Resend API takes attachments inline in the request, so they are read
+// into memory up to this limit. Defaults to DefaultMaxAttachmentSize.
+func (m *ResendMailer) WithMaxAttachmentSize(n int64) *ResendMailer {
+	m.maxAttachmentSize = n
+	return m
+}
+
+// Send sends an email via Resend HTTP API. Like SMTPMailer it emits
+// mail.sent or mail.failed with the message ID.
 func (m *ResendMailer) Send(ctx context.Context, msg *Message) error {
-	return m.cb.Execute(ctx, func() error {
+	from := m.config.SMTPFrom
+	if msg != nil && msg.From != "" {
+		from = msg.From
+	}
+	err := m.cb.Execute(ctx, func() error {
 		if msg == nil {
 			return fmt.Errorf("mail: message is nil")
 		}
 		if len(msg.To) == 0 {
 			return fmt.Errorf("mail: no recipients specified")
 		}
-
-		from := msg.From
-		if from == "" {
-			from = m.config.SMTPFrom
-		}
 		if from == "" {
 			return fmt.Errorf("mail: from address is required")
 		}
+		if err := ensureMessageID(msg, from); err != nil {
+			return err
+		}
 
 		payload := map[string]any{
 			"from":    from,
 			"to":      msg.To,
 			"subject": msg.Subject,
 		}
+		headers := map[string]string{"Message-ID": "<" + msg.MessageID + ">"}
+		for k, v := range msg.Headers {
+			if key := textproto.CanonicalMIMEHeaderKey(k); writableHeader(key) {
+				headers[key] = headerValue(v)
+			}
+		}
+		payload["headers"] = headers
 
 		if msg.HTML != "" {
 			payload["html"] = msg.HTML
@@ -60,12 +80,21 @@ func (m *ResendMailer) Send(ctx context.Context, msg *Message) error {
 		}
 
 		if len(msg.Attachments) > 0 {
+			budget := newAttachmentBudget(m.maxAttachmentSize)
 			attachments := make([]map[string]any, 0, len(msg.Attachments))
 			for _, a := range msg.Attachments {
-				attachments = append(attachments, map[string]any{
+				content, err := readAttachment(ctx, a, budget)
+				if err != nil {
+					return err
+				}
+				attachment := map[string]any{
 					"filename": a.Name,
-					"content":  a.Content, // Resend SDK/API usually handles []byte or requires base64
-				})
+					"content":  content, // encoded as base64 by the JSON encoder
+				}
+				if a.ContentID != "" {
+					attachment["content_id"] = a.ContentID
+				}
+				attachments = append(attachments, attachment)
 			}
 			payload["attachments"] = attachments
 		}
@@ -101,14 +130,15 @@ func (m *ResendMailer) Send(ctx context.Context, msg *Message) error {
 		}
 
 		if m.events != nil {
-			m.events.EmitPayload(ctx, "mail.sent", map[string]any{
-				"driver":  "resend",
-				"to":      msg.To,
-				"subject": msg.Subject,
-				"from":    from,
-			})
+			m.events.EmitPayload(ctx, "mail.sent", eventPayload("resend", msg, from))
 		}
 
 		return nil
 	})
+	if err != nil && msg != nil && m.events != nil {
+		payload := eventPayload("resend", msg, from)
+		payload["error"] = err.Error()
+		m.events.EmitPayload(ctx, "mail.failed", payload)
+	}
+	return err
 }
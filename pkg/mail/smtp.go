@@ -1,123 +1,316 @@
 package mail
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/smtp"
-	"strings"
+	"net/textproto"
+	"strconv"
+	"time"
 
 	"github.com/shauryagautam/Astra/pkg/engine/config"
 	"github.com/shauryagautam/Astra/pkg/engine/event"
 	"github.com/shauryagautam/Astra/pkg/observability/fault_tolerance"
 )
 
-// SMTPMailer implements the Mailer interface using SMTP.
+// SMTPMailer implements the Mailer interface using SMTP. Messages are
+// streamed to the server, so attachments are never buffered in memory.
+//
+// Connections are kept open between messages (see WithPool) and transient
+// failures are retried with backoff (see WithRetry).
 type SMTPMailer struct {
-	config config.MailConfig
-	events *event.Emitter
-	cb     *fault_tolerance.CircuitBreaker
+	config            config.MailConfig
+	events            *event.Emitter
+	cb                *fault_tolerance.CircuitBreaker
+	maxAttachmentSize int64
+	pool              *smtpPool
+	retries           int
+	backoff           time.Duration
+	dkim              *DKIMSigner
 }
 
-// NewSMTPMailer creates a new SMTPMailer.
+// NewSMTPMailer creates a new SMTPMailer, pooling connections and retrying
+// as cfg.SMTPPoolSize, SMTPIdleTimeout, SMTPRetries and SMTPRetryBackoff
+// say.
 func NewSMTPMailer(cfg config.MailConfig, emitter *event.Emitter) *SMTPMailer {
-	return &SMTPMailer{
+	m := &SMTPMailer{
 		config: cfg,
 		events: emitter,
 		cb:     fault_tolerance.NewCircuitBreaker("mail:smtp"),
 	}
+	return m.WithPool(cfg.SMTPPoolSize, cfg.SMTPIdleTimeout).WithRetry(cfg.SMTPRetries, cfg.SMTPRetryBackoff)
 }
 
-// Send sends an email using SMTP.
+// WithMaxAttachmentSize limits the total attachment bytes per message.
+// Sends over the limit fail with ErrAttachmentTooLarge. Defaults to
+// DefaultMaxAttachmentSize.
+func (m *SMTPMailer) WithMaxAttachmentSize(n int64) *SMTPMailer {
+	m.maxAttachmentSize = n
+	return m
+}
+
+// WithPool keeps up to size connections open after a message is sent, so
+// the next message skips the TCP, TLS and AUTH handshakes. A pooled
+// connection is checked with NOOP before reuse and closed once it has
+// been idle for idleTimeout (DefaultSMTPIdleTimeout when 0), before the
+// server drops it. A size of 0 dials a connection per message.
+func (m *SMTPMailer) WithPool(size int, idleTimeout time.Duration) *SMTPMailer {
+	if m.pool != nil {
+		m.pool.close()
+		m.pool = nil
+	}
+	if size > 0 {
+		if idleTimeout <= 0 {
+			idleTimeout = DefaultSMTPIdleTimeout
+		}
+		m.pool = &smtpPool{size: size, idleTimeout: idleTimeout}
+	}
+	return m
+}
+
+// WithRetry makes up to retries more attempts at a message that failed
+// transiently: a 4xx reply, or a network error such as a refused or reset
+// connection. The first retry waits backoff (one second when 0), doubling
+// each time. 5xx replies are permanent and are not retried, and neither
+// are messages with an AttachReader attachment that was already read.
+func (m *SMTPMailer) WithRetry(retries int, backoff time.Duration) *SMTPMailer {
+	m.retries = max(retries, 0)
+	m.backoff = backoff
+	if m.backoff <= 0 {
+		m.backoff = time.Second
+	}
+	return m
+}
+
+// WithDKIM signs every message with signer; nil turns signing off.
+// Signing needs the whole message before it is sent, so a signed message
+// is rendered into a temporary file first instead of streamed.
+func (m *SMTPMailer) WithDKIM(signer *DKIMSigner) *SMTPMailer {
+	m.dkim = signer
+	return m
+}
+
+// Close closes the pooled connections.
+func (m *SMTPMailer) Close() error {
+	if m.pool != nil {
+		m.pool.close()
+	}
+	return nil
+}
+
+// Send sends an email using SMTP. It emits mail.sent once the server has
+// accepted the message and mail.failed when it gives up, both carrying the
+// message ID.
 func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
-	return m.cb.Execute(ctx, func() error {
+	// Oversized messages are the caller's fault; keep them out of the breaker.
+	if msg != nil {
+		if err := newAttachmentBudget(m.maxAttachmentSize).precheck(msg.Attachments); err != nil {
+			return err
+		}
+	}
+	from := m.config.SMTPFrom
+	if msg != nil && msg.From != "" {
+		from = msg.From
+	}
+	err := m.cb.Execute(ctx, func() error {
 		if msg == nil {
 			return fmt.Errorf("mail: message is nil")
 		}
 		if len(msg.To) == 0 {
 			return fmt.Errorf("mail: no recipients specified")
 		}
-
-		auth := smtp.PlainAuth("", m.config.SMTPUser, m.config.SMTPPassword, m.config.SMTPHost)
-
-		from := msg.From
-		if from == "" {
-			from = m.config.SMTPFrom
-		}
 		if from == "" {
 			return fmt.Errorf("mail: from address is required")
 		}
+		if err := ensureMessageID(msg, from); err != nil {
+			return err
+		}
 
-		dest := strings.Join(msg.To, ",")
-
-		var body bytes.Buffer
-		body.WriteString(fmt.Sprintf("To: %s\r\n", dest))
-		body.WriteString(fmt.Sprintf("From: %s\r\n", from))
-		body.WriteString(fmt.Sprintf("Subject: %s\r\n", msg.Subject))
-
-		if len(msg.Attachments) == 0 {
-			if msg.HTML != "" {
-				body.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
-				body.WriteString(msg.HTML)
-			} else {
-				body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
-				body.WriteString(msg.Body)
-			}
-		} else {
-			boundary := "astra_mail_boundary"
-			body.WriteString("MIME-Version: 1.0\r\n")
-			body.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n\r\n", boundary))
-
-			// Body part
-			body.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-			if msg.HTML != "" {
-				body.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
-				body.WriteString(msg.HTML)
-			} else {
-				body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
-				body.WriteString(msg.Body)
-			}
-			body.WriteString("\r\n")
-
-			// Attachments
-			for _, a := range msg.Attachments {
-				body.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-				mime := a.MIME
-				if mime == "" {
-					mime = "application/octet-stream"
-				}
-				body.WriteString(fmt.Sprintf("Content-Type: %s\r\n", mime))
-				body.WriteString("Content-Transfer-Encoding: base64\r\n")
-				body.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=\"%s\"\r\n\r\n", a.Name))
-
-				encoder := base64.NewEncoder(base64.StdEncoding, &body)
-				if _, err := encoder.Write(a.Content); err != nil {
-					return err
-				}
-				if err := encoder.Close(); err != nil {
-					return err
-				}
-				body.WriteString("\r\n")
+		write := func(w io.Writer) error {
+			return writeMIME(ctx, w, msg, from, newAttachmentBudget(m.maxAttachmentSize))
+		}
+		if m.dkim != nil {
+			signed, cleanup, err := m.dkim.signed(write)
+			if err != nil {
+				return err
 			}
-			body.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
+			defer cleanup()
+			write = signed
 		}
 
-		addr := fmt.Sprintf("%s:%d", m.config.SMTPHost, m.config.SMTPPort)
-		err := smtp.SendMail(addr, auth, from, msg.To, body.Bytes())
+		attempts, err := m.deliverWithRetry(ctx, from, msg, write)
 		if err != nil {
 			return fmt.Errorf("failed to send smtp mail: %w", err)
 		}
 
 		if m.events != nil {
-			m.events.EmitPayload(ctx, "mail.sent", map[string]any{
-				"driver":  "smtp",
-				"to":      msg.To,
-				"subject": msg.Subject,
-				"from":    from,
-			})
+			payload := eventPayload("smtp", msg, from)
+			payload["attempts"] = attempts
+			m.events.EmitPayload(ctx, "mail.sent", payload)
 		}
-
 		return nil
 	})
+	if err != nil && msg != nil && m.events != nil {
+		payload := eventPayload("smtp", msg, from)
+		payload["error"] = err.Error()
+		m.events.EmitPayload(ctx, "mail.failed", payload)
+	}
+	return err
+}
+
+// ErrMaybeDelivered is returned when the server accepted DATA but the
+// message's final reply never arrived or was an error. The server may have
+// queued the message anyway, so neither SMTPMailer's retries nor
+// FailoverMailer send it again.
+var ErrMaybeDelivered = errors.New("mail: delivery failed after DATA; the message may have been sent")
+
+// dataError marks a failure after the server accepted DATA. Such failures
+// are not retried, since a second attempt could deliver the message twice.
+type dataError struct{ err error }
+
+func (e *dataError) Error() string { return e.err.Error() }
+func (e *dataError) Unwrap() error { return e.err }
+
+// deliverWithRetry delivers msg, written by write, retrying transient
+// failures before DATA, and returns the number of attempts made.
+func (m *SMTPMailer) deliverWithRetry(ctx context.Context, from string, msg *Message, write func(io.Writer) error) (int, error) {
+	backoff := m.backoff
+	const maxBackoff = time.Minute
+
+	for attempt := 0; ; attempt++ {
+		err := m.deliver(ctx, from, msg.To, write)
+		if err == nil || attempt == m.retries || !isTransient(err) {
+			return attempt + 1, err
+		}
+		slog.Warn("mail: smtp delivery failed, retrying",
+			"message_id", msg.MessageID,
+			"attempt", attempt+1,
+			"max_attempts", m.retries+1,
+			"backoff", backoff,
+			"error", err,
+		)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt + 1, err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// isTransient reports whether a failed delivery may succeed if tried again.
+func isTransient(err error) bool {
+	var afterData *dataError
+	if errors.As(err, &afterData) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return reply.Code >= 400 && reply.Code < 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// deliver runs one SMTP transaction on a pooled or new connection and lets
+// write stream the message into DATA. If write fails the connection is
+// dropped before the terminating dot, so the server discards the partial
+// message.
+func (m *SMTPMailer) deliver(ctx context.Context, from string, to []string, write func(io.Writer) error) error {
+	sc, err := m.conn(ctx)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = sc.conn.SetDeadline(deadline)
+	}
+
+	if err := transact(sc.client, from, to, write); err != nil {
+		sc.close()
+		return err
+	}
+	if m.pool == nil {
+		return sc.client.Quit()
+	}
+	m.pool.put(sc)
+	return nil
+}
+
+func transact(c *smtp.Client, from string, to []string, write func(io.Writer) error) error {
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	wc, err := c.Data()
+	if err != nil {
+		return err
+	}
+	// The terminating dot is only sent by Close, so a failed write leaves
+	// nothing delivered; a failed Close may not.
+	if err := write(wc); err != nil {
+		return &dataError{err}
+	}
+	if err := wc.Close(); err != nil {
+		return &dataError{fmt.Errorf("%w: %w", ErrMaybeDelivered, err)}
+	}
+	return nil
+}
+
+// conn returns a live pooled connection, or dials a new one.
+func (m *SMTPMailer) conn(ctx context.Context) (*smtpConn, error) {
+	if m.pool != nil {
+		if sc := m.pool.get(ctx); sc != nil {
+			return sc, nil
+		}
+	}
+	return m.dial(ctx)
+}
+
+// dial connects to the server, upgrading to TLS and authenticating when
+// the server offers it.
+func (m *SMTPMailer) dial(ctx context.Context) (*smtpConn, error) {
+	addr := net.JoinHostPort(m.config.SMTPHost, strconv.Itoa(m.config.SMTPPort))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, m.config.SMTPHost)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	sc := &smtpConn{client: c, conn: conn}
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.config.SMTPHost, MinVersion: tls.VersionTLS12}); err != nil {
+			sc.close()
+			return nil, err
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok && m.config.SMTPUser != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.config.SMTPUser, m.config.SMTPPassword, m.config.SMTPHost)); err != nil {
+			sc.close()
+			return nil, err
+		}
+	}
+	return sc, nil
 }
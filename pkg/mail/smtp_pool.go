@@ -0,0 +1,104 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+// DefaultSMTPIdleTimeout is how long a pooled SMTP connection may sit idle
+// before it is closed. Servers commonly drop idle clients after a minute
+// or more, so this stays below that.
+const DefaultSMTPIdleTimeout = 30 * time.Second
+
+// smtpConn is an authenticated SMTP session and the connection under it,
+// kept for its deadlines.
+type smtpConn struct {
+	client    *smtp.Client
+	conn      net.Conn
+	idleSince time.Time
+}
+
+func (sc *smtpConn) close() {
+	_ = sc.client.Close()
+}
+
+// quit ends the session politely, without waiting on an unresponsive
+// server for long.
+func (sc *smtpConn) quit() {
+	_ = sc.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := sc.client.Quit(); err != nil {
+		sc.close()
+	}
+}
+
+// smtpPool holds idle SMTP sessions between messages.
+type smtpPool struct {
+	size        int
+	idleTimeout time.Duration
+
+	mu   sync.Mutex
+	idle []*smtpConn
+}
+
+// get returns the most recently used idle session that still answers a
+// NOOP, closing the stale ones it passes over, or nil.
+func (p *smtpPool) get(ctx context.Context) *smtpConn {
+	for {
+		p.mu.Lock()
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			return nil
+		}
+		sc := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		if time.Since(sc.idleSince) > p.idleTimeout {
+			sc.close()
+			continue
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = sc.conn.SetDeadline(deadline)
+		}
+		if err := sc.client.Noop(); err != nil {
+			sc.close()
+			continue
+		}
+		return sc
+	}
+}
+
+// put resets sc and keeps it for the next message, or quits it when the
+// pool is full.
+func (p *smtpPool) put(sc *smtpConn) {
+	_ = sc.conn.SetDeadline(time.Time{})
+	if err := sc.client.Reset(); err != nil {
+		sc.close()
+		return
+	}
+	sc.idleSince = time.Now()
+
+	p.mu.Lock()
+	if len(p.idle) < p.size {
+		p.idle = append(p.idle, sc)
+		sc = nil
+	}
+	p.mu.Unlock()
+	if sc != nil {
+		sc.quit()
+	}
+}
+
+// close quits every idle session.
+func (p *smtpPool) close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	for _, sc := range idle {
+		sc.quit()
+	}
+}
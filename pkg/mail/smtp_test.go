@@ -0,0 +1,218 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+)
+
+// fakeSMTP is an SMTP server that answers the first failures MAIL commands
+// with failCode and records every message it accepts. For the first
+// dropsAfterData messages it hangs up instead of confirming them.
+type fakeSMTP struct {
+	ln             net.Listener
+	failCode       int
+	failures       atomic.Int32
+	dropsAfterData atomic.Int32
+	conns          atomic.Int32
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTP{ln: ln}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) config() config.MailConfig {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return config.MailConfig{SMTPHost: host, SMTPPort: portNum, SMTPFrom: "app@example.com"}
+}
+
+func (s *fakeSMTP) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.conns.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ready")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 localhost")
+		case "MAIL":
+			if s.failures.Add(-1) >= 0 {
+				_ = tp.PrintfLine("%d try again later", s.failCode)
+				continue
+			}
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, _ := io.ReadAll(bufio.NewReader(tp.DotReader()))
+			s.mu.Lock()
+			s.messages = append(s.messages, string(data))
+			s.mu.Unlock()
+			if s.dropsAfterData.Add(-1) >= 0 {
+				return
+			}
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+func TestSMTPMailer_ReusesPooledConnections(t *testing.T) {
+	srv := newFakeSMTP(t)
+	mailer := NewSMTPMailer(srv.config(), nil).WithPool(1, time.Minute)
+	defer mailer.Close()
+
+	for _, subject := range []string{"First", "Second", "Third"} {
+		require.NoError(t, mailer.Send(context.Background(), &Message{To: []string{"a@example.com"}, Subject: subject, Body: "hi"}))
+	}
+
+	assert.Len(t, srv.received(), 3)
+	assert.Equal(t, int32(1), srv.conns.Load(), "every message goes over the pooled connection")
+}
+
+func TestSMTPMailer_RetriesTransientFailures(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.failCode = 451
+	srv.failures.Store(2)
+
+	emitter := event.New()
+	var sent map[string]any
+	emitter.OnPayload("mail.sent", func(data any) { sent = data.(map[string]any) })
+
+	mailer := NewSMTPMailer(srv.config(), emitter).WithRetry(2, time.Millisecond)
+	msg := &Message{To: []string{"a@example.com"}, Subject: "Hello", Body: "hi"}
+	require.NoError(t, mailer.Send(context.Background(), msg))
+
+	require.NotEmpty(t, msg.MessageID)
+	assert.True(t, strings.HasSuffix(msg.MessageID, "@example.com"))
+	require.Len(t, srv.received(), 1)
+	assert.Contains(t, srv.received()[0], "Message-ID: <"+msg.MessageID+">")
+	require.NotNil(t, sent)
+	assert.Equal(t, msg.MessageID, sent["message_id"])
+	assert.Equal(t, 3, sent["attempts"])
+}
+
+func TestSMTPMailer_DoesNotRetryPermanentFailures(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.failCode = 550
+	srv.failures.Store(1)
+
+	emitter := event.New()
+	var failed map[string]any
+	emitter.OnPayload("mail.failed", func(data any) { failed = data.(map[string]any) })
+
+	mailer := NewSMTPMailer(srv.config(), emitter).WithRetry(3, time.Millisecond)
+	msg := &Message{To: []string{"a@example.com"}, Subject: "Hello", Body: "hi", MessageID: "fixed@example.com"}
+	err := mailer.Send(context.Background(), msg)
+
+	var reply *textproto.Error
+	require.ErrorAs(t, err, &reply)
+	assert.Equal(t, 550, reply.Code)
+	assert.Empty(t, srv.received())
+	require.NotNil(t, failed)
+	assert.Equal(t, "fixed@example.com", failed["message_id"])
+	assert.Contains(t, failed["error"], "550")
+}
+
+func TestSMTPMailer_DoesNotRetryAfterData(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.dropsAfterData.Store(1)
+
+	mailer := NewSMTPMailer(srv.config(), nil).WithRetry(3, time.Millisecond)
+	err := mailer.Send(context.Background(), &Message{To: []string{"a@example.com"}, Subject: "Hello", Body: "hi"})
+	assert.ErrorIs(t, err, ErrMaybeDelivered)
+	assert.Len(t, srv.received(), 1, "the message is not sent twice")
+
+	var calls int
+	fallback := funcMailer(func(context.Context, *Message) error { calls++; return nil })
+	srv.dropsAfterData.Store(1)
+	err = NewFailoverMailer(mailer, fallback).Send(context.Background(), &Message{To: []string{"a@example.com"}, Body: "hi"})
+	assert.ErrorIs(t, err, ErrMaybeDelivered)
+	assert.Zero(t, calls, "a message that may be delivered is not failed over")
+}
+
+type funcMailer func(ctx context.Context, msg *Message) error
+
+func (f funcMailer) Send(ctx context.Context, msg *Message) error { return f(ctx, msg) }
+
+func TestFailoverMailer(t *testing.T) {
+	down := funcMailer(func(_ context.Context, msg *Message) error {
+		msg.MessageID = "shared@example.com"
+		return errors.New("connection refused")
+	})
+
+	t.Run("falls back with the same message", func(t *testing.T) {
+		var got *Message
+		up := funcMailer(func(_ context.Context, msg *Message) error {
+			got = msg
+			return nil
+		})
+		require.NoError(t, NewFailoverMailer(down, up).Send(context.Background(), &Message{To: []string{"a@example.com"}}))
+		require.NotNil(t, got)
+		assert.Equal(t, "shared@example.com", got.MessageID)
+	})
+
+	t.Run("joins errors when every mailer fails", func(t *testing.T) {
+		err := NewFailoverMailer(down, down).Send(context.Background(), &Message{To: []string{"a@example.com"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mailer 0: connection refused")
+		assert.Contains(t, err.Error(), "mailer 1: connection refused")
+	})
+
+	t.Run("does not fail over oversized messages", func(t *testing.T) {
+		var calls int
+		fallback := funcMailer(func(context.Context, *Message) error { calls++; return nil })
+		tooLarge := funcMailer(func(context.Context, *Message) error { return ErrAttachmentTooLarge })
+		err := NewFailoverMailer(tooLarge, fallback).Send(context.Background(), &Message{})
+		assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+		assert.Zero(t, calls)
+	})
+}
@@ -0,0 +1,133 @@
+package privacy
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+)
+
+// Routes mounts the self-service endpoints for the authenticated user on r.
+// Mount them behind auth middleware:
+//
+//	router.Group("/account/privacy", func(r *http.Router) {
+//	    r.Use(requireAuth)
+//	    svc.Routes(r)
+//	})
+//
+//	POST /export       request an export of the user's data      → 202
+//	POST /erase        request the erasure of the user's data    → 202
+//	GET  /requests     the user's requests
+//	GET  /requests/{id} one request, with a download_url once an export is done
+//
+// POST /erase needs a recent re-authentication (see WithReauth). Both POST
+// endpoints answer 409 while a request of the same kind is still pending,
+// and 429 past the limit set by WithRequestLimit.
+func (s *Service) Routes(r *http.Router) {
+	r.Post("/export", s.RequestHandler(KindExport))
+	r.Post("/erase", s.RequestHandler(KindErase))
+	r.Get("/requests", s.ListHandler())
+	r.Get("/requests/{id}", s.StatusHandler())
+}
+
+// ReauthFunc reports whether the user of c recently re-authenticated.
+type ReauthFunc func(c *http.Context) bool
+
+// RequestHandler records a request of kind for the authenticated user and
+// answers 202 with it.
+func (s *Service) RequestHandler(kind Kind) http.HandlerFunc {
+	return func(c *http.Context) error {
+		user := c.AuthUser()
+		if user == nil || user.UserID == "" {
+			return c.UnauthorizedError("")
+		}
+		if kind == KindErase && (s.reauth == nil || !s.reauth(c)) {
+			return c.ForbiddenError("confirm your identity again before erasing your data")
+		}
+		if err := s.checkLimits(c, kind, user.UserID); err != nil {
+			return err
+		}
+		req, err := s.request(c.Ctx(), kind, user.UserID, user.UserID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(req, nethttp.StatusAccepted)
+	}
+}
+
+// checkLimits refuses a request of kind while another one of the user is
+// pending, and past the request limit.
+func (s *Service) checkLimits(c *http.Context, kind Kind, userID string) error {
+	reqs, err := s.Requests(c.Ctx(), userID)
+	if err != nil {
+		return err
+	}
+	since := s.now().Add(-s.limitWindow)
+	var recent []time.Time // oldest first, as Requests returns them
+	for _, req := range reqs {
+		if req.Kind != kind {
+			continue
+		}
+		if req.Status == StatusPending || req.Status == StatusRunning {
+			return c.ConflictError("a " + string(kind) + " request is already in progress")
+		}
+		if req.RequestedAt.After(since) {
+			recent = append(recent, req.RequestedAt)
+		}
+	}
+	if s.limit > 0 && len(recent) >= s.limit {
+		wait := recent[0].Add(s.limitWindow).Sub(s.now())
+		_ = c.SetHeader("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		return c.ErrorWithDetails(nethttp.StatusTooManyRequests, http.ErrCodeRateLimit, "too many "+string(kind)+" requests", nil)
+	}
+	return nil
+}
+
+// ListHandler lists the requests of the authenticated user.
+func (s *Service) ListHandler() http.HandlerFunc {
+	return func(c *http.Context) error {
+		user := c.AuthUser()
+		if user == nil || user.UserID == "" {
+			return c.UnauthorizedError("")
+		}
+		reqs, err := s.Requests(c.Ctx(), user.UserID)
+		if err != nil {
+			return err
+		}
+		if reqs == nil {
+			reqs = []Request{}
+		}
+		return c.JSON(reqs)
+	}
+}
+
+// StatusHandler returns the request {id} of the authenticated user, with a
+// download_url once an export has completed. Requests of other users are
+// not found.
+func (s *Service) StatusHandler() http.HandlerFunc {
+	return func(c *http.Context) error {
+		user := c.AuthUser()
+		if user == nil || user.UserID == "" {
+			return c.UnauthorizedError("")
+		}
+		req, err := s.Find(c.Ctx(), c.Param("id"))
+		if errors.Is(err, ErrNotFound) || (err == nil && req.SubjectID != user.UserID) {
+			return c.NotFoundError("Privacy request")
+		}
+		if err != nil {
+			return err
+		}
+		out := struct {
+			*Request
+			DownloadURL string `json:"download_url,omitempty"`
+		}{Request: req}
+		if req.Kind == KindExport && req.Status == StatusCompleted {
+			if out.DownloadURL, err = s.DownloadURL(c.Ctx(), req); err != nil {
+				return err
+			}
+		}
+		return c.JSON(out)
+	}
+}
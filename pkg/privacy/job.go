@@ -0,0 +1,34 @@
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/queue"
+)
+
+// ProcessJob processes a queued request. Register it on the worker with
+// RegisterJobs.
+type ProcessJob struct {
+	queue.BaseJob
+	RequestID string `json:"request_id"`
+
+	service *Service
+}
+
+// Handle runs the request.
+func (j *ProcessJob) Handle(ctx context.Context) error {
+	return j.service.Process(ctx, j.RequestID)
+}
+
+// Queue puts requests on the "privacy" queue, so a large export does not
+// hold up the default one. Workers must listen on it.
+func (j *ProcessJob) Queue() string { return "privacy" }
+
+// Timeout leaves room for exports of large accounts.
+func (j *ProcessJob) Timeout() time.Duration { return 10 * time.Minute }
+
+// RegisterJobs registers the handler of queued requests on w.
+func (s *Service) RegisterJobs(w *queue.Worker) {
+	w.Register(JobName, func() queue.Job { return &ProcessJob{service: s} })
+}
@@ -0,0 +1,531 @@
+// Package privacy handles data subject requests under the GDPR: exporting
+// everything an application holds about a person (right of access) and
+// erasing it (right to erasure).
+//
+// Models holding personal data implement PrivacySubject, and a Finder
+// registered per source returns the subjects belonging to a person:
+//
+//	privacy.Register("users", func(ctx context.Context, db *database.DB, id string) ([]privacy.PrivacySubject, error) {
+//	    user, err := models.FindUser(ctx, db, id)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return []privacy.PrivacySubject{user}, nil
+//	})
+//
+//	svc := privacy.New(db, disk, privacy.WithDispatcher(dispatcher))
+//	_ = svc.Migrate(ctx)
+//	svc.RegisterJobs(worker)
+//	req, err := svc.RequestExport(ctx, userID, userID)
+//
+// Every request is recorded in the privacy_requests table and in the audit
+// stream, and runs as a queued job: an export writes a ZIP bundle with one
+// JSON file per source to the storage disk, an erasure erases every subject
+// in a single transaction.
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	"github.com/shauryagautam/Astra/pkg/observability/audit"
+	"github.com/shauryagautam/Astra/pkg/queue"
+	"github.com/shauryagautam/Astra/pkg/storage"
+)
+
+// DefaultTable is the table requests are recorded in.
+const DefaultTable = "privacy_requests"
+
+// JobName is the queue job type that processes requests.
+const JobName = "privacy.process"
+
+// ErrNotFound is returned for an unknown request ID.
+var ErrNotFound = errors.New("privacy: request not found")
+
+// PrivacySubject is a record holding personal data of a person.
+type PrivacySubject interface {
+	// CollectPersonalData returns the personal data of the record, encoded
+	// as JSON in the export bundle.
+	CollectPersonalData(ctx context.Context) (any, error)
+	// Erase deletes or anonymizes the record. It runs inside the erasure
+	// transaction, which ctx carries, and must be safe to run twice.
+	Erase(ctx context.Context) error
+}
+
+// Finder returns the records of one source that belong to the person
+// subjectID. A person without records in the source gets none and no error.
+type Finder func(ctx context.Context, db *database.DB, subjectID string) ([]PrivacySubject, error)
+
+// Registry holds the sources of personal data, in registration order.
+type Registry struct {
+	mu      sync.RWMutex
+	names   []string
+	finders map[string]Finder
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{finders: make(map[string]Finder)}
+}
+
+// DefaultRegistry is used by Register and by services without WithRegistry.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a source to DefaultRegistry.
+func Register(name string, find Finder) {
+	DefaultRegistry.Register(name, find)
+}
+
+// Register adds a source, replacing any source with the same name. name
+// becomes the file name in the export bundle.
+func (r *Registry) Register(name string, find Finder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.finders[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.finders[name] = find
+}
+
+// Names returns the registered sources in registration order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.names)
+}
+
+func (r *Registry) finder(name string) Finder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.finders[name]
+}
+
+// Kind is what a request asks for.
+type Kind string
+
+const (
+	KindExport Kind = "export"
+	KindErase  Kind = "erase"
+)
+
+// Status is where a request is in its workflow.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Request is a recorded data subject request. Path is the bundle of a
+// completed export on the storage disk.
+type Request struct {
+	ID          string     `json:"id"`
+	Kind        Kind       `json:"kind"`
+	SubjectID   string     `json:"subject_id"`
+	ActorID     string     `json:"actor_id"`
+	Status      Status     `json:"status"`
+	Path        string     `json:"-"`
+	Error       string     `json:"error,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Dispatcher queues jobs; *queue.Dispatcher implements it.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, job queue.Job, name string) error
+}
+
+// Service records and processes data subject requests.
+type Service struct {
+	db         *database.DB
+	disk       storage.Storage
+	registry   *Registry
+	dispatcher Dispatcher
+	table      string
+	dir        string
+	linkTTL    time.Duration
+	emitter    *event.Emitter
+	now        func() time.Time
+
+	reauth      ReauthFunc
+	limit       int
+	limitWindow time.Duration
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithRegistry reads sources from r instead of DefaultRegistry.
+func WithRegistry(r *Registry) Option {
+	return func(s *Service) { s.registry = r }
+}
+
+// WithDispatcher queues requests on d. Without it they are processed before
+// RequestExport and RequestErasure return, as the CLI does.
+func WithDispatcher(d Dispatcher) Option {
+	return func(s *Service) { s.dispatcher = d }
+}
+
+// WithTable records requests in table instead of DefaultTable.
+func WithTable(table string) Option {
+	return func(s *Service) { s.table = table }
+}
+
+// WithExportDir writes bundles under dir on the disk (default
+// "privacy/exports").
+func WithExportDir(dir string) Option {
+	return func(s *Service) { s.dir = dir }
+}
+
+// WithLinkTTL sets how long download links of bundles are valid (default
+// 7 days), on disks that sign URLs.
+func WithLinkTTL(d time.Duration) Option {
+	return func(s *Service) { s.linkTTL = d }
+}
+
+// WithEmitter emits audit events on emitter instead of event.DefaultEmitter.
+func WithEmitter(emitter *event.Emitter) Option {
+	return func(s *Service) { s.emitter = emitter }
+}
+
+// WithReauth lets the authenticated user erase their data over HTTP once fn
+// reports that they recently re-authenticated, for example by confirming
+// their password within the last few minutes. Without it, POST /erase is
+// refused: a stolen session must not be enough to erase an account.
+func WithReauth(fn ReauthFunc) Option {
+	return func(s *Service) { s.reauth = fn }
+}
+
+// WithRequestLimit allows each user at most limit exports, and limit
+// erasures, per window over HTTP (default 3 per 24 hours). The CLI is not
+// limited.
+func WithRequestLimit(limit int, window time.Duration) Option {
+	return func(s *Service) { s.limit, s.limitWindow = limit, window }
+}
+
+// New creates a Service reading personal data from db and writing export
+// bundles to disk.
+func New(db *database.DB, disk storage.Storage, opts ...Option) *Service {
+	s := &Service{
+		db:       db,
+		disk:     disk,
+		registry: DefaultRegistry,
+		table:    DefaultTable,
+		dir:      "privacy/exports",
+		linkTTL:  7 * 24 * time.Hour,
+		emitter:  event.DefaultEmitter,
+		now:      time.Now,
+
+		limit:       3,
+		limitWindow: 24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Migrate creates the requests table if it does not exist.
+func (s *Service) Migrate(ctx context.Context) error {
+	d := s.db.Dialect()
+	cols := s.columns()
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		%s VARCHAR(36) NOT NULL PRIMARY KEY,
+		%s VARCHAR(16) NOT NULL,
+		%s VARCHAR(191) NOT NULL,
+		%s VARCHAR(191) NOT NULL,
+		%s VARCHAR(16) NOT NULL,
+		%s TEXT NOT NULL,
+		%s TEXT NOT NULL,
+		%s TIMESTAMP NOT NULL,
+		%s TIMESTAMP NULL
+	)`, d.QuoteIdentifier(s.table), cols[0], cols[1], cols[2], cols[3], cols[4], cols[5], cols[6], cols[7], cols[8])
+	if _, err := s.db.Exec(ctx, query); err != nil {
+		return fmt.Errorf("privacy: failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// columns returns the quoted columns of the requests table, in order.
+func (s *Service) columns() []string {
+	d := s.db.Dialect()
+	names := []string{"id", "kind", "subject_id", "actor_id", "status", "path", "error", "requested_at", "completed_at"}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+	return quoted
+}
+
+// RequestExport records a request by actorID for the personal data of
+// subjectID and queues it. The returned request is pending, or already
+// completed without a dispatcher.
+func (s *Service) RequestExport(ctx context.Context, subjectID, actorID string) (*Request, error) {
+	return s.request(ctx, KindExport, subjectID, actorID)
+}
+
+// RequestErasure records a request by actorID to erase the personal data of
+// subjectID and queues it.
+func (s *Service) RequestErasure(ctx context.Context, subjectID, actorID string) (*Request, error) {
+	return s.request(ctx, KindErase, subjectID, actorID)
+}
+
+func (s *Service) request(ctx context.Context, kind Kind, subjectID, actorID string) (*Request, error) {
+	if subjectID == "" {
+		return nil, errors.New("privacy: subject ID must not be empty")
+	}
+	req := &Request{
+		ID:          uuid.NewString(),
+		Kind:        kind,
+		SubjectID:   subjectID,
+		ActorID:     actorID,
+		Status:      StatusPending,
+		RequestedAt: s.now().UTC(),
+	}
+	d := s.db.Dialect()
+	cols := s.columns()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s, %s) VALUES (%s, %s, %s, %s, %s, %s, %s, %s)",
+		d.QuoteIdentifier(s.table), cols[0], cols[1], cols[2], cols[3], cols[4], cols[5], cols[6], cols[7],
+		d.Placeholder(1), d.Placeholder(2), d.Placeholder(3), d.Placeholder(4),
+		d.Placeholder(5), d.Placeholder(6), d.Placeholder(7), d.Placeholder(8))
+	if _, err := s.db.Exec(ctx, query, req.ID, string(kind), subjectID, actorID, string(StatusPending), "", "", req.RequestedAt); err != nil {
+		return nil, fmt.Errorf("privacy: failed to record request: %w", err)
+	}
+	s.audit(ctx, req, string(kind)+".requested", nil)
+
+	if s.dispatcher != nil {
+		if err := s.dispatcher.Dispatch(ctx, &ProcessJob{RequestID: req.ID, service: s}, JobName); err != nil {
+			return nil, fmt.Errorf("privacy: failed to queue request: %w", err)
+		}
+		return req, nil
+	}
+	if err := s.Process(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	return s.Find(ctx, req.ID)
+}
+
+// Find returns the request with id, or ErrNotFound.
+func (s *Service) Find(ctx context.Context, id string) (*Request, error) {
+	reqs, err := s.list(ctx, "id", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqs) == 0 {
+		return nil, ErrNotFound
+	}
+	return &reqs[0], nil
+}
+
+// Requests returns the requests concerning subjectID, oldest first.
+func (s *Service) Requests(ctx context.Context, subjectID string) ([]Request, error) {
+	return s.list(ctx, "subject_id", subjectID)
+}
+
+func (s *Service) list(ctx context.Context, column, value string) ([]Request, error) {
+	d := s.db.Dialect()
+	cols := s.columns()
+	query := fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s, %s, %s FROM %s WHERE %s = %s ORDER BY %s",
+		cols[0], cols[1], cols[2], cols[3], cols[4], cols[5], cols[6], cols[7], cols[8],
+		d.QuoteIdentifier(s.table), d.QuoteIdentifier(column), d.Placeholder(1), cols[7])
+	rows, err := s.db.Query(ctx, query, value)
+	if err != nil {
+		return nil, fmt.Errorf("privacy: failed to read requests: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Request
+	for rows.Next() {
+		var (
+			r         Request
+			completed sql.NullTime
+		)
+		if err := rows.Scan(&r.ID, &r.Kind, &r.SubjectID, &r.ActorID, &r.Status, &r.Path, &r.Error, &r.RequestedAt, &completed); err != nil {
+			return nil, fmt.Errorf("privacy: failed to read requests: %w", err)
+		}
+		if completed.Valid {
+			r.CompletedAt = &completed.Time
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// Process runs the request with id. A completed request is left alone, so
+// a retried job does not export or erase twice. A failure is recorded on
+// the request and returned, for the queue to retry.
+func (s *Service) Process(ctx context.Context, id string) error {
+	req, err := s.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if req.Status == StatusCompleted {
+		return nil
+	}
+	if err := s.update(ctx, req.ID, StatusRunning, "", "", nil); err != nil {
+		return err
+	}
+
+	var bundle string
+	switch req.Kind {
+	case KindExport:
+		bundle, err = s.export(ctx, req)
+	case KindErase:
+		err = s.erase(ctx, req)
+	default:
+		err = fmt.Errorf("privacy: unknown request kind %q", req.Kind)
+	}
+	if err != nil {
+		_ = s.update(context.WithoutCancel(ctx), req.ID, StatusFailed, "", err.Error(), nil)
+		s.audit(ctx, req, string(req.Kind)+".failed", err)
+		return err
+	}
+
+	completed := s.now().UTC()
+	if err := s.update(ctx, req.ID, StatusCompleted, bundle, "", &completed); err != nil {
+		return err
+	}
+	s.audit(ctx, req, string(req.Kind)+".completed", nil)
+	return nil
+}
+
+func (s *Service) update(ctx context.Context, id string, status Status, bundle, failure string, completed *time.Time) error {
+	d := s.db.Dialect()
+	cols := s.columns()
+	query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s, %s = %s, %s = %s WHERE %s = %s",
+		d.QuoteIdentifier(s.table),
+		cols[4], d.Placeholder(1), cols[5], d.Placeholder(2), cols[6], d.Placeholder(3), cols[8], d.Placeholder(4),
+		cols[0], d.Placeholder(5))
+	var completedAt any
+	if completed != nil {
+		completedAt = *completed
+	}
+	if _, err := s.db.Exec(ctx, query, string(status), bundle, failure, completedAt, id); err != nil {
+		return fmt.Errorf("privacy: failed to update request %s: %w", id, err)
+	}
+	return nil
+}
+
+// manifest is manifest.json in an export bundle.
+type manifest struct {
+	RequestID   string         `json:"request_id"`
+	SubjectID   string         `json:"subject_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Sources     map[string]int `json:"sources"`
+}
+
+// export writes the bundle of req and returns its path on the disk.
+func (s *Service) export(ctx context.Context, req *Request) (string, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	m := manifest{
+		RequestID:   req.ID,
+		SubjectID:   req.SubjectID,
+		GeneratedAt: s.now().UTC(),
+		Sources:     map[string]int{},
+	}
+	for _, name := range s.registry.Names() {
+		subjects, err := s.registry.finder(name)(ctx, s.db, req.SubjectID)
+		if err != nil {
+			return "", fmt.Errorf("privacy: %s: %w", name, err)
+		}
+		records := make([]any, 0, len(subjects))
+		for _, subject := range subjects {
+			data, err := subject.CollectPersonalData(ctx)
+			if err != nil {
+				return "", fmt.Errorf("privacy: %s: %w", name, err)
+			}
+			records = append(records, data)
+		}
+		if err := writeJSON(zw, name+".json", records); err != nil {
+			return "", err
+		}
+		m.Sources[name] = len(records)
+	}
+	if err := writeJSON(zw, "manifest.json", m); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("privacy: failed to write bundle: %w", err)
+	}
+
+	bundle := path.Join(s.dir, req.ID+".zip")
+	if err := s.disk.Put(ctx, bundle, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("privacy: failed to store bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("privacy: failed to write bundle: %w", err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("privacy: failed to encode %s: %w", name, err)
+	}
+	return nil
+}
+
+// erase erases every subject of req in one transaction, so a failure leaves
+// the data as it was and the request can be retried.
+func (s *Service) erase(ctx context.Context, req *Request) error {
+	return s.db.Transaction(ctx, func(txCtx context.Context) error {
+		for _, name := range s.registry.Names() {
+			subjects, err := s.registry.finder(name)(txCtx, s.db, req.SubjectID)
+			if err != nil {
+				return fmt.Errorf("privacy: %s: %w", name, err)
+			}
+			for _, subject := range subjects {
+				if err := subject.Erase(txCtx); err != nil {
+					return fmt.Errorf("privacy: %s: %w", name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// DownloadURL returns a link to the bundle of a completed export.
+func (s *Service) DownloadURL(ctx context.Context, req *Request) (string, error) {
+	if req.Kind != KindExport || req.Status != StatusCompleted || req.Path == "" {
+		return "", errors.New("privacy: the request has no bundle")
+	}
+	return s.disk.SignedURL(ctx, req.Path, s.linkTTL)
+}
+
+// audit emits an audit event for req. Actions are privacy.<kind>.requested,
+// .completed and .failed.
+func (s *Service) audit(ctx context.Context, req *Request, action string, err error) {
+	if s.emitter == nil {
+		return
+	}
+	e := audit.AuditEvent{
+		ActorID:      req.ActorID,
+		Action:       "privacy." + action,
+		ResourceType: "privacy_request",
+		ResourceID:   req.ID,
+		Timestamp:    s.now().UTC(),
+		Success:      err == nil,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	s.emitter.Emit(ctx, e)
+}
@@ -0,0 +1,271 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	"github.com/shauryagautam/Astra/pkg/engine/http"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
+	"github.com/shauryagautam/Astra/pkg/observability/audit"
+	"github.com/shauryagautam/Astra/pkg/queue"
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUser struct {
+	db    *database.DB
+	ID    string
+	Email string
+}
+
+func (u *testUser) CollectPersonalData(context.Context) (any, error) {
+	return map[string]string{"id": u.ID, "email": u.Email}, nil
+}
+
+func (u *testUser) Erase(ctx context.Context) error {
+	_, err := u.db.Exec(ctx, "UPDATE users SET email = 'erased' WHERE id = ?", u.ID)
+	return err
+}
+
+type failingSubject struct{}
+
+func (failingSubject) CollectPersonalData(context.Context) (any, error) { return nil, nil }
+func (failingSubject) Erase(context.Context) error                      { return errors.New("boom") }
+
+func newTestService(t *testing.T, opts ...Option) (*Service, *database.DB, *storage.MemoryStorage, *[]audit.AuditEvent) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := database.Open(database.Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.Pool().SetMaxOpenConns(1)
+
+	_, err = db.Exec(ctx, "CREATE TABLE users (id TEXT PRIMARY KEY, email TEXT NOT NULL)")
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO users (id, email) VALUES ('u1', 'ada@example.com'), ('u2', 'bob@example.com')")
+	require.NoError(t, err)
+
+	registry := NewRegistry()
+	registry.Register("users", func(ctx context.Context, db *database.DB, id string) ([]PrivacySubject, error) {
+		var email string
+		err := db.QueryRow(ctx, "SELECT email FROM users WHERE id = ?", id).Scan(&email)
+		if err != nil {
+			return nil, nil
+		}
+		return []PrivacySubject{&testUser{db: db, ID: id, Email: email}}, nil
+	})
+
+	events := &[]audit.AuditEvent{}
+	emitter := event.New()
+	emitter.OnFunc("audit.event", func(_ context.Context, e event.Event) error {
+		*events = append(*events, e.(audit.AuditEvent))
+		return nil
+	})
+
+	disk := storage.NewMemoryStorage()
+	svc := New(db, disk, append([]Option{WithRegistry(registry), WithEmitter(emitter)}, opts...)...)
+	require.NoError(t, svc.Migrate(ctx))
+	return svc, db, disk, events
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	svc, _, disk, events := newTestService(t)
+
+	req, err := svc.RequestExport(ctx, "u1", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, req.Status)
+	require.NotNil(t, req.CompletedAt)
+
+	content, err := disk.Get(ctx, req.Path)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+	assert.Contains(t, files["users.json"], "ada@example.com")
+	assert.NotContains(t, files["users.json"], "bob@example.com")
+	assert.Contains(t, files["manifest.json"], `"users": 1`)
+
+	url, err := svc.DownloadURL(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "memory://"+req.Path, url)
+
+	var actions []string
+	for _, e := range *events {
+		actions = append(actions, e.Action)
+		assert.Equal(t, "admin", e.ActorID)
+		assert.Equal(t, req.ID, e.ResourceID)
+	}
+	assert.Equal(t, []string{"privacy.export.requested", "privacy.export.completed"}, actions)
+}
+
+func TestErasure(t *testing.T) {
+	ctx := context.Background()
+	svc, db, _, _ := newTestService(t)
+
+	req, err := svc.RequestErasure(ctx, "u1", "u1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, req.Status)
+
+	var email string
+	require.NoError(t, db.QueryRow(ctx, "SELECT email FROM users WHERE id = 'u1'").Scan(&email))
+	assert.Equal(t, "erased", email)
+	require.NoError(t, db.QueryRow(ctx, "SELECT email FROM users WHERE id = 'u2'").Scan(&email))
+	assert.Equal(t, "bob@example.com", email)
+
+	reqs, err := svc.Requests(ctx, "u1")
+	require.NoError(t, err)
+	assert.Len(t, reqs, 1)
+}
+
+func TestErasureRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, db, _, events := newTestService(t)
+	svc.registry.Register("broken", func(context.Context, *database.DB, string) ([]PrivacySubject, error) {
+		return []PrivacySubject{failingSubject{}}, nil
+	})
+
+	_, err := svc.RequestErasure(ctx, "u1", "u1")
+	require.ErrorContains(t, err, "broken: boom")
+
+	var email string
+	require.NoError(t, db.QueryRow(ctx, "SELECT email FROM users WHERE id = 'u1'").Scan(&email))
+	assert.Equal(t, "ada@example.com", email)
+
+	reqs, err := svc.Requests(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, reqs, 1)
+	assert.Equal(t, StatusFailed, reqs[0].Status)
+	assert.Contains(t, reqs[0].Error, "boom")
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, "privacy.erase.failed", last.Action)
+	assert.False(t, last.Success)
+}
+
+type recordingDispatcher struct {
+	jobs []queue.Job
+}
+
+func (d *recordingDispatcher) Dispatch(_ context.Context, job queue.Job, _ string) error {
+	d.jobs = append(d.jobs, job)
+	return nil
+}
+
+func TestQueuedRequest(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := &recordingDispatcher{}
+	svc, _, _, _ := newTestService(t, WithDispatcher(dispatcher))
+
+	req, err := svc.RequestExport(ctx, "u1", "u1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, req.Status)
+	require.Len(t, dispatcher.jobs, 1)
+
+	// The worker decodes the payload into a job from RegisterJobs' factory.
+	payload, err := json.Marshal(dispatcher.jobs[0])
+	require.NoError(t, err)
+	job := &ProcessJob{service: svc}
+	require.NoError(t, json.Unmarshal(payload, job))
+	require.NoError(t, job.Handle(ctx))
+	// A retry of a completed request is a no-op.
+	require.NoError(t, job.Handle(ctx))
+
+	found, err := svc.Find(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, found.Status)
+}
+
+// serve mounts svc's routes and returns a function calling them as user.
+func serve(svc *Service) func(method, path, user string) *httptest.ResponseRecorder {
+	router := http.NewRouter(&config.AstraConfig{}, nil)
+	router.Group("/privacy", func(r *http.Router) {
+		r.Use(func(next nethttp.Handler) nethttp.Handler {
+			return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				if id := r.Header.Get("X-User"); id != "" {
+					http.FromRequest(r).SetAuthUser(&identityclaims.AuthClaims{UserID: id})
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+		svc.Routes(r)
+	})
+
+	return func(method, path, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	svc, _, _, _ := newTestService(t)
+	call := serve(svc)
+
+	assert.Equal(t, nethttp.StatusUnauthorized, call("POST", "/privacy/export", "").Code)
+
+	rec := call("POST", "/privacy/export", "u1")
+	require.Equal(t, nethttp.StatusAccepted, rec.Code, rec.Body.String())
+	var created Request
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+
+	rec = call("GET", "/privacy/requests/"+created.ID, "u1")
+	require.Equal(t, nethttp.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"download_url":"memory://privacy/exports/`+created.ID+`.zip"`)
+
+	assert.Equal(t, nethttp.StatusNotFound, call("GET", "/privacy/requests/"+created.ID, "u2").Code)
+
+	rec = call("GET", "/privacy/requests", "u2")
+	assert.Equal(t, nethttp.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+}
+
+func TestEraseHandlerNeedsReauth(t *testing.T) {
+	svc, _, _, _ := newTestService(t)
+	assert.Equal(t, nethttp.StatusForbidden, serve(svc)("POST", "/privacy/erase", "u1").Code)
+
+	reauthed := false
+	svc, _, _, _ = newTestService(t, WithReauth(func(*http.Context) bool { return reauthed }))
+	call := serve(svc)
+	assert.Equal(t, nethttp.StatusForbidden, call("POST", "/privacy/erase", "u1").Code)
+	reauthed = true
+	assert.Equal(t, nethttp.StatusAccepted, call("POST", "/privacy/erase", "u1").Code)
+}
+
+func TestRequestHandlerLimits(t *testing.T) {
+	queued, _, _, _ := newTestService(t, WithDispatcher(&recordingDispatcher{}))
+	call := serve(queued)
+	require.Equal(t, nethttp.StatusAccepted, call("POST", "/privacy/export", "u1").Code)
+	assert.Equal(t, nethttp.StatusConflict, call("POST", "/privacy/export", "u1").Code, "an export is still pending")
+	assert.Equal(t, nethttp.StatusAccepted, call("POST", "/privacy/export", "u2").Code)
+
+	limited, _, _, _ := newTestService(t, WithRequestLimit(2, time.Hour))
+	call = serve(limited)
+	require.Equal(t, nethttp.StatusAccepted, call("POST", "/privacy/export", "u1").Code)
+	require.Equal(t, nethttp.StatusAccepted, call("POST", "/privacy/export", "u1").Code)
+	rec := call("POST", "/privacy/export", "u1")
+	assert.Equal(t, nethttp.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+}
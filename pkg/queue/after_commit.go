@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/support/txhook"
+)
+
+// afterCommitQueue defers pushes made inside a database transaction until
+// it commits.
+type afterCommitQueue struct {
+	Queue
+}
+
+// AfterCommit wraps q so jobs enqueued inside db.Transaction are pushed
+// once the transaction commits and dropped when it rolls back, so a worker
+// never picks up a job for a row that does not exist. Outside a
+// transaction jobs are pushed right away. A deferred push that fails is
+// logged, since the caller has already returned.
+func AfterCommit(q Queue) Queue {
+	return &afterCommitQueue{Queue: q}
+}
+
+func (q *afterCommitQueue) Enqueue(ctx context.Context, job Job) error {
+	return deferPush(ctx, job, func(ctx context.Context) error { return q.Queue.Enqueue(ctx, job) })
+}
+
+func (q *afterCommitQueue) EnqueueIn(ctx context.Context, job Job, delay time.Duration) error {
+	return deferPush(ctx, job, func(ctx context.Context) error { return q.Queue.EnqueueIn(ctx, job, delay) })
+}
+
+func (q *afterCommitQueue) EnqueueAt(ctx context.Context, job Job, at time.Time) error {
+	return deferPush(ctx, job, func(ctx context.Context) error { return q.Queue.EnqueueAt(ctx, job, at) })
+}
+
+// DispatchAfterCommit dispatches job once the database transaction ctx
+// runs in commits, or right away outside a transaction.
+func (d *RedisDispatcher) DispatchAfterCommit(ctx context.Context, job Job, name string) error {
+	return deferPush(ctx, job, func(ctx context.Context) error { return d.Dispatch(ctx, job, name) })
+}
+
+// deferPush runs push after the commit of the transaction ctx runs in, or
+// right away, returning its error, outside a transaction.
+func deferPush(ctx context.Context, job Job, push func(context.Context) error) error {
+	if !txhook.InTransaction(ctx) {
+		return push(ctx)
+	}
+	txhook.AfterCommit(ctx, func() {
+		pushCtx := context.WithoutCancel(txhook.Detach(ctx))
+		if err := push(pushCtx); err != nil {
+			slog.Error("queue: failed to push job after commit", "job", jobTypeName(job), "error", err)
+		}
+	})
+	return nil
+}
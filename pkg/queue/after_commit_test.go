@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/support/txhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingQueue struct {
+	Queue
+	pushed []string
+}
+
+func (q *recordingQueue) Enqueue(_ context.Context, job Job) error {
+	q.pushed = append(q.pushed, job.(mockJob).Payload)
+	return nil
+}
+
+type fakeTx struct {
+	open      bool
+	callbacks []func()
+}
+
+func (tx *fakeTx) AfterCommit(fn func()) { tx.callbacks = append(tx.callbacks, fn) }
+func (tx *fakeTx) InTransaction() bool   { return tx.open }
+
+func (tx *fakeTx) commit() {
+	tx.open = false
+	for _, fn := range tx.callbacks {
+		fn()
+	}
+}
+
+func TestAfterCommitQueue(t *testing.T) {
+	inner := &recordingQueue{}
+	q := AfterCommit(inner)
+
+	require.NoError(t, q.Enqueue(context.Background(), mockJob{Payload: "now"}))
+	assert.Equal(t, []string{"now"}, inner.pushed)
+
+	tx := &fakeTx{open: true}
+	txCtx := txhook.WithDeferrer(context.Background(), tx)
+	require.NoError(t, q.Enqueue(txCtx, mockJob{Payload: "later"}))
+	assert.Equal(t, []string{"now"}, inner.pushed, "held until commit")
+
+	tx.commit()
+	assert.Equal(t, []string{"now", "later"}, inner.pushed)
+
+	rolledBack := &fakeTx{open: true}
+	require.NoError(t, q.Enqueue(txhook.WithDeferrer(context.Background(), rolledBack), mockJob{Payload: "never"}))
+	assert.Equal(t, []string{"now", "later"}, inner.pushed)
+}
@@ -5,7 +5,9 @@ import "github.com/redis/go-redis/v9"
 // Dispatcher is the compatibility alias for the Redis-backed dispatcher.
 type Dispatcher = RedisDispatcher
 
-// NewDispatcher creates a Redis-backed job dispatcher.
+// NewDispatcher creates a Redis-backed job dispatcher. It stores payloads as
+// plain JSON unless given WithPayloadProtection; QueueProvider.Dispatcher
+// comes set up from QUEUE_ENCRYPT and QUEUE_SIGN.
 func NewDispatcher(client redis.UniversalClient, prefix string) *Dispatcher {
 	return NewRedisDispatcher(client, prefix)
 }
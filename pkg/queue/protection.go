@@ -0,0 +1,123 @@
+package queue
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/crypto"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+)
+
+var errPayloadTampered = errors.New("astra/queue: job payload signature is invalid")
+
+// PayloadProtection encrypts and signs job payloads before they reach
+// Redis, so sensitive job data is not stored as plaintext and workers
+// refuse payloads that were changed there. The zero value stores payloads
+// as plain JSON.
+//
+// Dispatchers and workers must share the same settings. Jobs queued before
+// protection was turned on are refused by protected workers, so drain the
+// queues first. Failed jobs keep their protected payload, and Retry
+// re-enqueues it as is.
+type PayloadProtection struct {
+	// Encrypter, when set, encrypts payloads with AES-256-GCM. The job type
+	// is authenticated with the payload, so even without signing a payload
+	// cannot be replayed as another job.
+	Encrypter *crypto.Encrypter
+	// SigningKey, when set, signs payloads with HMAC-SHA256. The signature
+	// covers the job type, so a payload cannot be replayed as another job.
+	SigningKey []byte
+}
+
+// NewPayloadProtection returns the protection for the given keys, such as
+// APP_ENCRYPTION_KEY and APP_KEY. An empty key leaves its part off:
+// encryptionKey must otherwise be 32 bytes. Payloads are not signed with
+// signingKey itself but with a key derived from it for the queue, so a
+// queue signature is worthless anywhere else APP_KEY is used.
+func NewPayloadProtection(encryptionKey, signingKey string) (PayloadProtection, error) {
+	var p PayloadProtection
+	if encryptionKey != "" {
+		enc, err := crypto.NewEncrypter(encryptionKey)
+		if err != nil {
+			return PayloadProtection{}, fmt.Errorf("astra/queue: %w", err)
+		}
+		p.Encrypter = enc
+	}
+	if signingKey != "" {
+		mac := hmac.New(sha256.New, []byte(signingKey))
+		mac.Write([]byte("astra-queue-sign"))
+		p.SigningKey = mac.Sum(nil)
+	}
+	return p, nil
+}
+
+// PayloadProtectionFromConfig returns the protection QUEUE_ENCRYPT and
+// QUEUE_SIGN turn on, keyed by APP_ENCRYPTION_KEY and APP_KEY. A missing
+// key for a part that is on is an error.
+func PayloadProtectionFromConfig(cfg *config.AstraConfig) (PayloadProtection, error) {
+	var encryptionKey, signingKey string
+	if cfg.Queue.Encrypt {
+		if encryptionKey = cfg.App.EncryptionKey; encryptionKey == "" {
+			return PayloadProtection{}, errors.New("astra/queue: QUEUE_ENCRYPT needs APP_ENCRYPTION_KEY")
+		}
+	}
+	if cfg.Queue.Sign {
+		if signingKey = cfg.App.Key; signingKey == "" {
+			return PayloadProtection{}, errors.New("astra/queue: QUEUE_SIGN needs APP_KEY")
+		}
+	}
+	return NewPayloadProtection(encryptionKey, signingKey)
+}
+
+// seal encrypts, then signs, the payload of a job of type jobType.
+func (p PayloadProtection) seal(jobType, payload string) (string, error) {
+	if p.Encrypter != nil {
+		sealed, err := p.Encrypter.SealWithAAD([]byte(payload), []byte(jobType))
+		if err != nil {
+			return "", fmt.Errorf("astra/queue: %w", err)
+		}
+		payload = base64.StdEncoding.EncodeToString(sealed)
+	}
+	if len(p.SigningKey) > 0 {
+		// The signature is base64url, which has no dots, so the first dot
+		// always ends it.
+		payload = p.sign(jobType, payload) + "." + payload
+	}
+	return payload, nil
+}
+
+// open reverses seal, failing for a payload whose signature does not match
+// or that does not decrypt.
+func (p PayloadProtection) open(jobType, payload string) (string, error) {
+	if len(p.SigningKey) > 0 {
+		sig, body, ok := strings.Cut(payload, ".")
+		if !ok || !hmac.Equal([]byte(sig), []byte(p.sign(jobType, body))) {
+			return "", errPayloadTampered
+		}
+		payload = body
+	}
+	if p.Encrypter != nil {
+		sealed, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return "", fmt.Errorf("astra/queue: job payload is not encrypted: %w", err)
+		}
+		plain, err := p.Encrypter.OpenWithAAD(sealed, []byte(jobType))
+		if err != nil {
+			return "", fmt.Errorf("astra/queue: %w", err)
+		}
+		payload = string(plain)
+	}
+	return payload, nil
+}
+
+func (p PayloadProtection) sign(jobType, payload string) string {
+	mac := hmac.New(sha256.New, p.SigningKey)
+	mac.Write([]byte(jobType))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadProtection(t *testing.T) {
+	p, err := NewPayloadProtection(strings.Repeat("k", 32), "signing-key")
+	require.NoError(t, err)
+
+	sealed, err := p.seal("SendInvoice", `{"card":"4242"}`)
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "4242")
+
+	plain, err := p.open("SendInvoice", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, `{"card":"4242"}`, plain)
+
+	_, err = p.open("RefundInvoice", sealed)
+	assert.ErrorIs(t, err, errPayloadTampered, "a payload cannot be replayed as another job")
+	_, err = p.open("SendInvoice", sealed[:len(sealed)-2]+"AA")
+	assert.ErrorIs(t, err, errPayloadTampered)
+	_, err = p.open("SendInvoice", `{"card":"4242"}`)
+	assert.ErrorIs(t, err, errPayloadTampered)
+
+	encryptOnly := PayloadProtection{Encrypter: p.Encrypter}
+	_, err = encryptOnly.open("SendInvoice", `{"card":"4242"}`)
+	assert.Error(t, err)
+	encrypted, err := encryptOnly.seal("SendInvoice", `{"card":"4242"}`)
+	require.NoError(t, err)
+	_, err = encryptOnly.open("RefundInvoice", encrypted)
+	assert.Error(t, err, "without signing, the job type is still bound to the payload")
+
+	assert.NotEqual(t, []byte("signing-key"), p.SigningKey, "APP_KEY is not used as the signing key itself")
+
+	_, err = NewPayloadProtection("short", "")
+	assert.Error(t, err)
+}
+
+func TestRedisWorker_PayloadProtection(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	p, err := NewPayloadProtection(strings.Repeat("k", 32), "signing-key")
+	require.NoError(t, err)
+	d := NewRedisDispatcher(client, "sealed").WithPayloadProtection(p)
+	require.NoError(t, d.Dispatch(ctx, &mockJob{Payload: "secret"}, "mockJob"))
+
+	stream := streamKey("sealed", defaultQueueName)
+	msgs, err := client.XRange(ctx, stream, "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	stored := msgs[0].Values["payload"].(string)
+	assert.NotContains(t, stored, "secret", "payloads are not stored as plaintext")
+
+	// A payload changed in Redis is refused, without retries.
+	forged := msgs[0].Values
+	forged["payload"] = "AAAA." + strings.SplitN(stored, ".", 2)[1]
+	require.NoError(t, client.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: forged}).Err())
+
+	w := NewRedisWorker(client, "sealed", nil, nil).WithPayloadProtection(p)
+	w.Register("mockJob", func() Job { return &mockJob{} })
+	require.NoError(t, w.Start(ctx))
+	defer w.Stop(context.Background())
+
+	require.Eventually(t, func() bool {
+		m := w.Metrics()
+		return m.JobsProcessed == 1 && m.JobsFailed == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Zero(t, w.Metrics().JobsRetried)
+
+	failed, err := w.failed.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Contains(t, failed[0].Error, "signature is invalid")
+}
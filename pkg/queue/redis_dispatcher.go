@@ -26,6 +26,13 @@ func NewRedisDispatcher(client redis.UniversalClient, prefix string) *RedisDispa
 	}
 }
 
+// WithPayloadProtection encrypts and signs the payloads of dispatched jobs.
+// Workers need the same protection to run them.
+func (d *RedisDispatcher) WithPayloadProtection(p PayloadProtection) *RedisDispatcher {
+	d.queue.WithPayloadProtection(p)
+	return d
+}
+
 // Dispatch pushes a job for immediate processing.
 func (d *RedisDispatcher) Dispatch(ctx context.Context, job Job, name string) error {
 	return d.queue.enqueue(ctx, name, job, 0)
@@ -54,7 +61,7 @@ func (d *RedisDispatcher) DispatchIn(ctx context.Context, job Job, name string,
 
 // DispatchAt pushes a job to run at a specific time.
 func (d *RedisDispatcher) DispatchAt(ctx context.Context, job Job, name string, at time.Time) error {
-	envelope, err := newQueueEnvelope(ctx, name, job, 0)
+	envelope, err := d.queue.newEnvelope(ctx, name, job, 0)
 	if err != nil {
 		return err
 	}
@@ -63,7 +70,7 @@ func (d *RedisDispatcher) DispatchAt(ctx context.Context, job Job, name string,
 		return fmt.Errorf("astra/queue: %w", err)
 	}
 	return d.client.ZAdd(ctx, delayedQueueKey(d.prefix), redis.Z{
-		Score:  float64(at.Unix()),
+		Score:  delayedScore(at),
 		Member: body,
 	}).Err()
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/shauryagautam/Astra/pkg/engine/json"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -67,9 +68,11 @@ type RedisQueue struct {
 	logger           *slog.Logger
 	prefix           string
 	delayedKey       string
+	promotingKey     string
 	promoterInterval time.Duration
 	promoterStop     chan struct{}
 	promoterDone     sync.WaitGroup
+	protection       PayloadProtection
 }
 
 // NewRedisQueue creates a new Redis-backed queue.
@@ -80,6 +83,7 @@ func NewRedisQueue(client redis.UniversalClient, prefix string, locker cache.Loc
 		logger:           slog.Default(),
 		prefix:           normalizeQueuePrefix(prefix),
 		delayedKey:       delayedQueueKey(normalizeQueuePrefix(prefix)),
+		promotingKey:     promotingQueueKey(normalizeQueuePrefix(prefix)),
 		promoterInterval: defaultPollInterval,
 		promoterStop:     make(chan struct{}),
 	}
@@ -93,6 +97,13 @@ func (q *RedisQueue) WithLogger(logger *slog.Logger) *RedisQueue {
 	return q
 }
 
+// WithPayloadProtection encrypts and signs the payloads of the jobs the
+// queue stores.
+func (q *RedisQueue) WithPayloadProtection(p PayloadProtection) *RedisQueue {
+	q.protection = p
+	return q
+}
+
 // Enqueue stores a job for immediate execution.
 func (q *RedisQueue) Enqueue(ctx context.Context, job Job) error {
 	return q.enqueue(ctx, jobTypeName(job), job, 0)
@@ -105,7 +116,7 @@ func (q *RedisQueue) EnqueueIn(ctx context.Context, job Job, delay time.Duration
 
 // EnqueueAt stores a job for execution at a specific time.
 func (q *RedisQueue) EnqueueAt(ctx context.Context, job Job, at time.Time) error {
-	envelope, err := newQueueEnvelope(ctx, jobTypeName(job), job, 0)
+	envelope, err := q.newEnvelope(ctx, jobTypeName(job), job, 0)
 	if err != nil {
 		return err
 	}
@@ -114,7 +125,7 @@ func (q *RedisQueue) EnqueueAt(ctx context.Context, job Job, at time.Time) error
 		return fmt.Errorf("astra/queue: %w", err)
 	}
 	return q.client.ZAdd(ctx, q.delayedKey, redis.Z{
-		Score:  float64(at.Unix()),
+		Score:  delayedScore(at),
 		Member: body,
 	}).Err()
 }
@@ -169,13 +180,26 @@ func (q *RedisQueue) Stop(ctx context.Context) error {
 }
 
 func (q *RedisQueue) enqueue(ctx context.Context, jobType string, job Job, attempts int) error {
-	envelope, err := newQueueEnvelope(ctx, jobType, job, attempts)
+	envelope, err := q.newEnvelope(ctx, jobType, job, attempts)
 	if err != nil {
 		return err
 	}
 	return q.enqueueEnvelope(ctx, envelope)
 }
 
+// newEnvelope wraps job like newQueueEnvelope, with the payload sealed by
+// the queue's PayloadProtection.
+func (q *RedisQueue) newEnvelope(ctx context.Context, jobType string, job Job, attempts int) (queueEnvelope, error) {
+	envelope, err := newQueueEnvelope(ctx, jobType, job, attempts)
+	if err != nil {
+		return queueEnvelope{}, err
+	}
+	if envelope.Payload, err = q.protection.seal(jobType, envelope.Payload); err != nil {
+		return queueEnvelope{}, err
+	}
+	return envelope, nil
+}
+
 func (q *RedisQueue) enqueueEnvelope(ctx context.Context, envelope queueEnvelope) error {
 	if q.client == nil {
 		return errNilRedisClient
@@ -221,7 +245,38 @@ func (q *RedisQueue) promoteLoop(ctx context.Context) {
 	}
 }
 
-// PromoteReady moves jobs from the delayed set to the ready stream once their scheduled time arrives.
+const (
+	// delayedPromotionBatch caps the jobs claimed per promotion round.
+	delayedPromotionBatch = 500
+	// delayedPromotionTimeout is how long a claimed job may sit in the
+	// promoting set before another promoter assumes the claimer crashed.
+	delayedPromotionTimeout = 30 * time.Second
+)
+
+// promoteDelayedScript atomically claims due jobs by moving them from the
+// delayed set (KEYS[1]) to the promoting set (KEYS[2]), scored by the claim
+// time. Entries whose claim is older than ARGV[2] are re-claimed, so a
+// promoter that died between claiming and enqueueing never loses a job.
+var promoteDelayedScript = redis.NewScript(`
+local stale = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[2], "LIMIT", 0, ARGV[3])
+for _, item in ipairs(stale) do
+	redis.call("ZADD", KEYS[2], ARGV[1], item)
+end
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+for _, item in ipairs(due) do
+	redis.call("ZREM", KEYS[1], item)
+	redis.call("ZADD", KEYS[2], ARGV[1], item)
+	table.insert(stale, item)
+end
+return stale
+`)
+
+// PromoteReady moves jobs from the delayed set to the ready stream once their
+// scheduled time arrives. Due jobs are claimed atomically, so concurrent
+// promoters never enqueue the same job twice; a job is only dropped from the
+// promoting set after it reached its stream. If the process dies in between,
+// the job is re-claimed after delayedPromotionTimeout, which makes delivery
+// at-least-once rather than at-most-once.
 func (q *RedisQueue) PromoteReady(ctx context.Context) error {
 	if q.client == nil {
 		return errNilRedisClient
@@ -242,23 +297,28 @@ func (q *RedisQueue) PromoteReady(ctx context.Context) error {
 		}()
 	}
 
-	now := time.Now().Unix()
-	items, err := q.client.ZRangeByScore(ctx, q.delayedKey, &redis.ZRangeBy{
-		Min: "-inf",
-		Max: fmt.Sprintf("%d", now),
-	}).Result()
+	now := time.Now()
+	items, err := promoteDelayedScript.Run(ctx, q.client,
+		[]string{q.delayedKey, q.promotingKey},
+		delayedScore(now),
+		delayedScore(now.Add(-delayedPromotionTimeout)),
+		delayedPromotionBatch,
+	).StringSlice()
 	if err != nil {
 		return fmt.Errorf("astra/queue: %w", err)
 	}
 	for _, item := range items {
 		var delayed delayedEnvelope
 		if err := json.Unmarshal([]byte(item), &delayed); err != nil {
-			return fmt.Errorf("astra/queue: %w", err)
+			// A corrupt entry would be reclaimed forever; drop it instead.
+			q.logger.Error("astra/queue: discarding undecodable delayed job", "error", err)
+			_ = q.client.ZRem(ctx, q.promotingKey, item).Err()
+			continue
 		}
 		if err := q.enqueueEnvelope(ctx, delayed.Job); err != nil {
 			return err
 		}
-		if err := q.client.ZRem(ctx, q.delayedKey, item).Err(); err != nil {
+		if err := q.client.ZRem(ctx, q.promotingKey, item).Err(); err != nil {
 			return fmt.Errorf("astra/queue: %w", err)
 		}
 	}
@@ -293,8 +353,16 @@ func newQueueEnvelope(ctx context.Context, jobType string, job Job, attempts int
 		traceState = carrier.Get("tracestate")
 	}
 
+	id := uuid.NewString()
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.AddEvent("job.dispatched", trace.WithAttributes(
+			attribute.String("messaging.message.id", id),
+			attribute.String("job.type", jobType),
+		))
+	}
+
 	return queueEnvelope{
-		ID:          uuid.NewString(),
+		ID:          id,
 		Payload:     string(payload),
 		JobType:     jobType,
 		Queue:       queueName,
@@ -374,6 +442,17 @@ func delayedQueueKey(prefix string) string {
 	return prefix + ":queue:delayed"
 }
 
+func promotingQueueKey(prefix string) string {
+	return prefix + ":queue:delayed:promoting"
+}
+
+// delayedScore is the sorted-set score of a job due at t: Unix seconds, as
+// jobs delayed by earlier releases were scored, so they stay comparable.
+// The fraction keeps short delays from being rounded up to the next second.
+func delayedScore(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
+}
+
 func consumerGroupName(prefix string, queue string) string {
 	return prefix + ":workers:" + queue
 }
@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisQueue_PromoteReady(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	q := NewRedisQueue(client, "delay", nil)
+
+	t.Run("concurrent promoters enqueue each job once", func(t *testing.T) {
+		for range 20 {
+			require.NoError(t, q.EnqueueAt(ctx, &mockJob{Payload: "x"}, time.Now().Add(-time.Millisecond)))
+		}
+		require.NoError(t, q.EnqueueIn(ctx, &mockJob{Payload: "later"}, time.Hour))
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Go(func() { assert.NoError(t, q.PromoteReady(ctx)) })
+		}
+		wg.Wait()
+
+		size, err := q.Size(ctx, defaultQueueName)
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), size)
+		assert.Equal(t, int64(1), client.ZCard(ctx, q.delayedKey).Val())
+		assert.Zero(t, client.ZCard(ctx, q.promotingKey).Val())
+	})
+
+	t.Run("scores are Unix seconds", func(t *testing.T) {
+		// Jobs delayed by earlier releases were scored in whole seconds.
+		q := NewRedisQueue(client, "seconds", nil)
+		at := time.Now().Add(time.Hour)
+		require.NoError(t, q.EnqueueAt(ctx, &mockJob{Payload: "x"}, at))
+		members := client.ZRangeWithScores(ctx, q.delayedKey, 0, -1).Val()
+		require.Len(t, members, 1)
+		assert.InDelta(t, float64(at.Unix()), members[0].Score, 1)
+	})
+
+	t.Run("stale claims are recovered", func(t *testing.T) {
+		q := NewRedisQueue(client, "crash", nil)
+		require.NoError(t, q.EnqueueAt(ctx, &mockJob{Payload: "crashed"}, time.Now()))
+		// Simulate a promoter that claimed the job and died before enqueueing it.
+		claimedAt := time.Now().Add(-2 * delayedPromotionTimeout)
+		_, err := promoteDelayedScript.Run(ctx, client,
+			[]string{q.delayedKey, q.promotingKey},
+			delayedScore(claimedAt.Add(time.Hour)), delayedScore(claimedAt.Add(-time.Hour)), 10,
+		).StringSlice()
+		require.NoError(t, err)
+		members := client.ZRangeWithScores(ctx, q.promotingKey, 0, -1).Val()
+		require.Len(t, members, 1)
+		require.NoError(t, client.ZAdd(ctx, q.promotingKey, redis.Z{Score: delayedScore(claimedAt), Member: members[0].Member}).Err())
+
+		require.NoError(t, q.PromoteReady(ctx))
+		size, err := q.Size(ctx, defaultQueueName)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), size)
+		assert.Zero(t, client.ZCard(ctx, q.promotingKey).Val())
+	})
+}
+
+func TestRedisWorker_ReapsAbandonedJobs(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	w := NewRedisWorker(client, "reap", nil, nil).WithVisibilityTimeout(20 * time.Millisecond)
+	w.Register("mockJob", func() Job { return &mockJob{} })
+
+	// A consumer from a crashed process reads the job but never acks it.
+	require.NoError(t, w.queue.Enqueue(ctx, &mockJob{Payload: "x"}))
+	stream := streamKey(w.prefix, defaultQueueName)
+	group := consumerGroupName(w.prefix, defaultQueueName)
+	_, err = client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: group, Consumer: "dead", Streams: []string{stream, ">"}, Count: 1, Block: -1,
+	}).Result()
+	require.NoError(t, err)
+
+	require.NoError(t, w.Start(ctx))
+	defer w.Stop(context.Background())
+
+	require.Eventually(t, func() bool {
+		return client.XPending(ctx, stream, group).Val().Count == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), w.Metrics().JobsProcessed)
+}
@@ -15,10 +15,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// redisPendingRecoveryIdle is the default visibility timeout of a worker.
 var redisPendingRecoveryIdle = 30 * time.Second
 
 const redisQueueProbeBlock = 50 * time.Millisecond
@@ -44,6 +48,8 @@ type RedisWorker struct {
 	events       *event.Emitter
 	dashboard    DashboardTracer // Interface for telemetry
 	consumerName string
+	visibility   time.Duration
+	paused       func() bool
 
 	stopOnce sync.Once
 	stopCh   chan struct{}
@@ -77,6 +83,7 @@ func NewRedisWorker(client redis.UniversalClient, prefix string, queues []string
 		failed:       NewRedisFailedJobsStore(client, prefix, queue),
 		events:       event.DefaultEmitter,
 		consumerName: "consumer-" + uuid.NewString(),
+		visibility:   redisPendingRecoveryIdle,
 		stopCh:       make(chan struct{}),
 	}
 }
@@ -101,6 +108,32 @@ func (w *RedisWorker) WithConcurrency(n int) *RedisWorker {
 	return w
 }
 
+// WithVisibilityTimeout sets how long a delivered job may stay unacknowledged
+// before it is considered abandoned by a crashed worker and handed to another
+// one. It must exceed the longest job runtime, or slow jobs will run twice.
+func (w *RedisWorker) WithVisibilityTimeout(d time.Duration) *RedisWorker {
+	if d > 0 {
+		w.visibility = d
+	}
+	return w
+}
+
+// WithBackpressure makes the worker stop taking new jobs while paused
+// returns true, for example engine.MemoryWatchdog.Overloaded. Jobs already
+// running are not affected.
+func (w *RedisWorker) WithBackpressure(paused func() bool) *RedisWorker {
+	w.paused = paused
+	return w
+}
+
+// WithPayloadProtection makes the worker open payloads sealed by a queue or
+// dispatcher with the same protection. Payloads that fail to verify or
+// decrypt are never run or retried: they go straight to the failed jobs.
+func (w *RedisWorker) WithPayloadProtection(p PayloadProtection) *RedisWorker {
+	w.queue.WithPayloadProtection(p)
+	return w
+}
+
 // Register registers a named job factory.
 func (w *RedisWorker) Register(name string, factory func() Job) {
 	w.handlers[name] = factory
@@ -123,6 +156,8 @@ func (w *RedisWorker) Start(ctx context.Context) error {
 		w.wg.Add(1)
 		go w.run(ctx, i)
 	}
+	w.wg.Add(1)
+	go w.reap(ctx)
 	return nil
 }
 
@@ -178,6 +213,17 @@ func (w *RedisWorker) run(ctx context.Context, workerID int) {
 		default:
 		}
 
+		if w.paused != nil && w.paused() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.stopCh:
+				return
+			case <-time.After(250 * time.Millisecond):
+			}
+			continue
+		}
+
 		_, err := w.pollQueues(ctx, consumer, queues)
 		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
@@ -189,6 +235,34 @@ func (w *RedisWorker) run(ctx context.Context, workerID int) {
 	}
 }
 
+// reap periodically reclaims jobs whose consumer stopped acknowledging them,
+// so a worker that crashes mid-job does not strand its deliveries until the
+// next restart.
+func (w *RedisWorker) reap(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(max(w.visibility/2, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+		}
+		for _, queueName := range w.queues {
+			if w.draining.Load() {
+				return
+			}
+			if err := w.recoverPending(ctx, queueName); err != nil && ctx.Err() == nil {
+				w.logger.Error("astra/queue: pending job recovery failed", "queue", queueName, "error", err)
+			}
+		}
+	}
+}
+
 func (w *RedisWorker) queuePollOrder(workerID int) []string {
 	if len(w.queues) == 0 {
 		return nil
@@ -252,8 +326,15 @@ func (w *RedisWorker) processMessage(ctx context.Context, stream string, group s
 		return
 	}
 
+	payload, err := w.queue.protection.open(envelope.JobType, envelope.Payload)
+	if err != nil {
+		w.logger.Error("astra/queue: rejected job payload", "job_id", envelope.ID, "job_type", envelope.JobType, "error", err)
+		w.rejectJob(ctx, stream, group, message.ID, envelope, err)
+		return
+	}
+
 	job := factory()
-	if err := json.Unmarshal([]byte(envelope.Payload), job); err != nil {
+	if err := json.Unmarshal([]byte(payload), job); err != nil {
 		w.failJob(ctx, stream, group, message.ID, envelope, fmt.Errorf("astra/queue: %w", err), nil)
 		return
 	}
@@ -269,15 +350,20 @@ func (w *RedisWorker) processMessage(ctx context.Context, stream string, group s
 			"traceparent": envelope.TraceParent,
 			"tracestate":  envelope.TraceState,
 		}
-		propCtx := propagation.TraceContext{}.Extract(jobCtx, carrier)
-		// Start a linked span so the job execution appears under the original trace.
-		tracer := otel.Tracer("astra/queue")
-		propCtx, span := tracer.Start(propCtx, "job/"+envelope.JobType,
-			trace.WithSpanKind(trace.SpanKindConsumer),
-		)
-		defer span.End()
-		jobCtx = propCtx
-	}
+		jobCtx = propagation.TraceContext{}.Extract(jobCtx, carrier)
+	}
+	// The job span carries the job ID, so a trace can be found from a
+	// failed job and the other way round.
+	jobCtx, span := otel.Tracer("astra/queue").Start(jobCtx, "job/"+envelope.JobType,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingMessageID(envelope.ID),
+			semconv.MessagingDestinationName(envelope.Queue),
+			attribute.String("job.type", envelope.JobType),
+			attribute.Int("job.attempt", envelope.Attempts+1),
+		),
+	)
+	defer span.End()
 
 	w.inFlight.Add(1)
 	defer w.inFlight.Add(-1)
@@ -338,6 +424,9 @@ func (w *RedisWorker) processMessage(ctx context.Context, stream string, group s
 		return
 	}
 
+	span.RecordError(runErr)
+	span.SetStatus(codes.Error, runErr.Error())
+
 	if stack == nil {
 		stack = stackTrace()
 	}
@@ -377,7 +466,19 @@ func (w *RedisWorker) failJob(ctx context.Context, stream string, group string,
 		}
 		return
 	}
+	w.storeFailed(ctx, envelope, runErr, stack)
+}
+
+// rejectJob acks a job that can never succeed, such as one whose payload
+// was tampered with, and stores it as failed without retrying it.
+func (w *RedisWorker) rejectJob(ctx context.Context, stream string, group string, messageID string, envelope queueEnvelope, runErr error) {
+	if err := w.client.XAck(ctx, stream, group, messageID).Err(); err != nil {
+		w.logger.Error("astra/queue: failed to ack rejected job", "job_id", envelope.ID, "error", err)
+	}
+	w.storeFailed(ctx, envelope, runErr, nil)
+}
 
+func (w *RedisWorker) storeFailed(ctx context.Context, envelope queueEnvelope, runErr error, stack []byte) {
 	w.jobsFailed.Add(1)
 	if err := w.failed.Store(ctx, failureFromEnvelope(envelope, runErr, stack)); err != nil {
 		w.logger.Error("astra/queue: failed storing failed job", "job_id", envelope.ID, "error", err)
@@ -413,7 +514,7 @@ func (w *RedisWorker) xautoclaim(ctx context.Context, stream string, group strin
 		Stream:   stream,
 		Group:    group,
 		Consumer: consumer,
-		MinIdle:  redisPendingRecoveryIdle,
+		MinIdle:  w.visibility,
 		Start:    start,
 		Count:    100,
 	}).Result()
@@ -445,7 +546,7 @@ func (w *RedisWorker) claimPendingFallback(ctx context.Context, stream string, g
 	}
 	var ids []string
 	for _, item := range pending {
-		if item.Idle >= redisPendingRecoveryIdle {
+		if item.Idle >= w.visibility {
 			ids = append(ids, item.ID)
 		}
 	}
@@ -456,7 +557,7 @@ func (w *RedisWorker) claimPendingFallback(ctx context.Context, stream string, g
 		Stream:   stream,
 		Group:    group,
 		Consumer: consumer,
-		MinIdle:  redisPendingRecoveryIdle,
+		MinIdle:  w.visibility,
 		Messages: ids,
 	}).Result()
 	if err != nil {
@@ -5,7 +5,9 @@ import "github.com/redis/go-redis/v9"
 // StreamsDriver is the compatibility alias for the Redis-backed dispatcher.
 type StreamsDriver = RedisDispatcher
 
-// NewStreamsDriver creates a Redis Streams dispatcher.
+// NewStreamsDriver creates a Redis Streams dispatcher. It stores payloads as
+// plain JSON unless given WithPayloadProtection; QueueProvider.Dispatcher
+// comes set up from QUEUE_ENCRYPT and QUEUE_SIGN.
 func NewStreamsDriver(client redis.UniversalClient, prefix string) *StreamsDriver {
 	return NewRedisDispatcher(client, prefix)
 }
@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+const (
+	defaultHealthInterval = 30 * time.Second
+	defaultStopTimeout    = 30 * time.Second
+	limitCheckInterval    = time.Second
+)
+
+// WorkOptions bounds a supervised worker run. Limits make the process exit
+// cleanly so a supervisor (systemd, Kubernetes) restarts it with a fresh
+// heap, which keeps slow leaks in job code from accumulating.
+type WorkOptions struct {
+	// MaxJobs stops the worker after this many attempts. Zero means no limit.
+	MaxJobs int64
+	// MaxMemoryMB stops the worker once the Go heap exceeds this size.
+	// Zero means no limit.
+	MaxMemoryMB uint64
+	// HealthInterval is how often a health line is written. Defaults to 30s.
+	HealthInterval time.Duration
+	// StopTimeout bounds how long in-flight jobs may drain. Defaults to 30s.
+	StopTimeout time.Duration
+	// Health receives one status line per interval. Nil disables the output.
+	Health io.Writer
+}
+
+// Work starts w and blocks until ctx is cancelled or a limit in opts is
+// reached, then drains in-flight jobs. Reaching a limit is a clean exit and
+// returns nil; an error means the worker failed to start or to drain in time.
+func Work(ctx context.Context, w *RedisWorker, opts WorkOptions) error {
+	if opts.HealthInterval <= 0 {
+		opts.HealthInterval = defaultHealthInterval
+	}
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = defaultStopTimeout
+	}
+
+	// Jobs inherit runCtx; it outlives ctx so a signal drains in-flight
+	// jobs instead of cancelling them.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	if err := w.Start(runCtx); err != nil {
+		return err
+	}
+	// Delayed jobs and backed-off retries only run once promoted.
+	if err := w.queue.Start(runCtx); err != nil {
+		return err
+	}
+
+	started := time.Now()
+	health := time.NewTicker(opts.HealthInterval)
+	defer health.Stop()
+	limits := time.NewTicker(limitCheckInterval)
+	defer limits.Stop()
+
+	reason := ""
+	for reason == "" {
+		select {
+		case <-ctx.Done():
+			reason = "signal"
+		case <-health.C:
+			if opts.Health != nil {
+				writeHealth(opts.Health, w.Metrics(), started)
+			}
+		case <-limits.C:
+			reason = opts.limitReached(w.Metrics())
+		}
+	}
+	w.logger.Info("astra/queue: worker stopping", "reason", reason)
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), opts.StopTimeout)
+	defer stopCancel()
+	err := errors.Join(w.Stop(stopCtx), w.queue.Stop(stopCtx))
+	if opts.Health != nil {
+		writeHealth(opts.Health, w.Metrics(), started)
+	}
+	return err
+}
+
+// limitReached returns why the worker should stop, or "" to keep running.
+func (o WorkOptions) limitReached(m WorkerMetrics) string {
+	if o.MaxJobs > 0 && m.JobsProcessed+m.JobsFailed+m.JobsRetried >= o.MaxJobs {
+		return fmt.Sprintf("max jobs (%d) reached", o.MaxJobs)
+	}
+	if o.MaxMemoryMB > 0 && heapMB() >= o.MaxMemoryMB {
+		return fmt.Sprintf("max memory (%dMB) reached", o.MaxMemoryMB)
+	}
+	return ""
+}
+
+func heapMB() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc / (1 << 20)
+}
+
+func writeHealth(out io.Writer, m WorkerMetrics, started time.Time) {
+	fmt.Fprintf(out, "queue:work processed=%d failed=%d retried=%d in_flight=%d heap=%dMB uptime=%s\n",
+		m.JobsProcessed, m.JobsFailed, m.JobsRetried, m.InFlight, heapMB(), time.Since(started).Round(time.Second))
+}
@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWork_StopsAtMaxJobs(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	q := NewRedisQueue(client, "work", nil)
+	for range 3 {
+		require.NoError(t, q.Enqueue(ctx, &mockJob{Payload: "x"}))
+	}
+
+	w := NewRedisWorker(client, "work", nil, nil)
+	w.Register("mockJob", func() Job { return &mockJob{} })
+
+	var health bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- Work(ctx, w, WorkOptions{MaxJobs: 2, HealthInterval: time.Hour, Health: &health})
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("worker did not stop at the job limit")
+	}
+	assert.GreaterOrEqual(t, w.Metrics().JobsProcessed, int64(2))
+	assert.Contains(t, health.String(), "queue:work processed=")
+}
+
+func TestWork_StopsOnCancel(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Work(ctx, NewRedisWorker(client, "work", nil, nil), WorkOptions{}) }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("worker did not stop on cancel")
+	}
+}
+
+func TestWorkOptions_LimitReached(t *testing.T) {
+	assert.Empty(t, WorkOptions{}.limitReached(WorkerMetrics{JobsProcessed: 1 << 20}))
+	assert.Contains(t, WorkOptions{MaxJobs: 5}.limitReached(WorkerMetrics{JobsProcessed: 3, JobsFailed: 2}), "max jobs")
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	identityclaims "github.com/shauryagautam/Astra/pkg/identity/claims"
 )
 
 
@@ -28,18 +31,48 @@ type Client struct {
 	Send      chan Message
 	Manager   *RoomManager
 	LastSeen  time.Time
+
+	ctx   context.Context
+	user  *identityclaims.AuthClaims
+	seq   uint64
+	rooms atomic.Int32 // rooms joined, for RoomManager.MaxRoomsPerClient
 }
 
 // NewClient creates a new client
 func NewClient(conn *websocket.Conn, userID string, manager *RoomManager) *Client {
 	return &Client{
-		ID:       userID + "-" + time.Now().Format("150405"),
+		ID:       uuid.NewString(),
 		UserID:   userID,
 		Conn:     conn,
 		Send:     make(chan Message, 256),
 		Manager:  manager,
 		LastSeen: time.Now(),
+		ctx:      context.Background(),
+	}
+}
+
+// NewAuthenticatedClient creates a client for an authenticated connection.
+// ctx is the per-client context handed to message handlers; it should
+// outlive the upgrade request and carry the auth user.
+func NewAuthenticatedClient(ctx context.Context, conn *websocket.Conn, user *identityclaims.AuthClaims, manager *RoomManager) *Client {
+	userID := ""
+	if user != nil {
+		userID = user.UserID
 	}
+	c := NewClient(conn, userID, manager)
+	c.ctx = ctx
+	c.user = user
+	return c
+}
+
+// User returns the authenticated user, or nil for guest connections.
+func (c *Client) User() *identityclaims.AuthClaims {
+	return c.user
+}
+
+// Context returns the per-client context.
+func (c *Client) Context() context.Context {
+	return c.ctx
 }
 
 // ReadPump pumps messages from the websocket connection to the manager.
@@ -79,15 +112,34 @@ func (c *Client) ReadPump(ctx context.Context) {
 			msg.From = c.UserID
 			msg.Timestamp = time.Now()
 
-			if msg.Room != "" {
+			switch {
+			case msg.Type == "join" && msg.Room != "":
+				c.joinFromClient(msg)
+			case msg.Type == "leave" && msg.Room != "":
+				c.Manager.Leave(msg.Room, c)
+			case msg.Room != "":
 				c.Manager.Broadcast <- msg
-			} else if msg.To != "" {
+			case msg.To != "":
 				c.Manager.SendToUser(msg.To, msg)
 			}
 		}
 	}
 }
 
+// joinFromClient handles a {"type":"join","room":"..."} request. Unless the
+// manager has an Authorize hook, clients may only join existing public
+// rooms. For presence rooms payload.meta becomes the member's metadata.
+func (c *Client) joinFromClient(msg Message) {
+	if !c.Manager.canJoin(c, msg.Room) {
+		slog.Warn("WebSocket join rejected", "client", c.ID, "room", msg.Room)
+		return
+	}
+	meta, _ := msg.Payload["meta"].(map[string]interface{})
+	if err := c.Manager.Join(msg.Room, c, meta); err != nil {
+		slog.Warn("WebSocket join failed", "client", c.ID, "room", msg.Room, "error", err)
+	}
+}
+
 // WritePump pumps messages from the hub to the websocket connection.
 func (c *Client) WritePump(ctx context.Context) {
 	ticker := time.NewTicker(54 * time.Second)
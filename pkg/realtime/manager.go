@@ -1,30 +1,75 @@
 package realtime
 
 import (
+	"errors"
+	"sort"
 	"sync"
 	"time"
 )
 
+// Presence event types sent to the members of a presence room.
+const (
+	PresenceJoin  = "presence:join"
+	PresenceLeave = "presence:leave"
+	PresenceState = "presence:state"
+)
+
+var (
+	// ErrRoomNotFound is returned when joining a room that does not exist.
+	ErrRoomNotFound = errors.New("realtime: room not found")
+	// ErrRoomFull is returned when a room has reached its MaxSize.
+	ErrRoomFull = errors.New("realtime: room is full")
+	// ErrClientNotConnected is returned when joining with a client that is
+	// not (or no longer) registered with the manager.
+	ErrClientNotConnected = errors.New("realtime: client is not connected")
+)
+
 // Room represents a collection of clients
 type Room struct {
 	ID          string
 	Name        string
 	Private     bool
+	Presence    bool
 	MaxSize     int
-	Clients     map[string]*Client
+	Clients     map[string]*Client // client ID -> Client
+	Members     map[string]PresenceMember
 	Metadata    map[string]interface{}
 	Created     time.Time
+	// Temporary rooms are removed once their last client leaves.
+	Temporary   bool
 	mu          sync.RWMutex
 }
 
+// PresenceMember describes a client in a presence room.
+type PresenceMember struct {
+	ClientID string                 `json:"client_id"`
+	UserID   string                 `json:"user_id,omitempty"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+	JoinedAt time.Time              `json:"joined_at"`
+}
+
+// DefaultMaxRoomsPerClient is the MaxRoomsPerClient of a new RoomManager.
+const DefaultMaxRoomsPerClient = 100
+
 // RoomManager manages WebSocket clients and rooms
 type RoomManager struct {
 	Rooms      map[string]*Room
-	Clients    map[string]*Client // All connected clients: userID -> Client
+	Clients    map[string]*Client // All connected clients: client ID -> Client
 	Broadcast  chan Message
 	Register   chan *Client
 	Unregister chan *Client
 	mu         sync.RWMutex
+	registered uint64 // registrations so far, orders Client.seq
+
+	// Authorize, when set, decides which rooms clients may join by sending
+	// a join message, replacing the default of existing public rooms only.
+	// It may create the room before returning true.
+	Authorize func(client *Client, room string) bool
+
+	// MaxRoomsPerClient caps how many rooms one connection may be in when
+	// it sends a join message; Authorize is not consulted past it. Zero or
+	// less means no cap. Joins made by the server with Join are not capped.
+	MaxRoomsPerClient int
 }
 
 // NewRoomManager creates a new room manager
@@ -35,6 +80,8 @@ func NewRoomManager() *RoomManager {
 		Broadcast:  make(chan Message),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
+
+		MaxRoomsPerClient: DefaultMaxRoomsPerClient,
 	}
 	go manager.Run()
 	return manager
@@ -46,19 +93,21 @@ func (m *RoomManager) Run() {
 		select {
 		case client := <-m.Register:
 			m.mu.Lock()
-			m.Clients[client.UserID] = client
+			m.registered++
+			client.seq = m.registered
+			m.Clients[client.ID] = client
 			m.mu.Unlock()
 		case client := <-m.Unregister:
 			m.mu.Lock()
-			if _, ok := m.Clients[client.UserID]; ok {
-				delete(m.Clients, client.UserID)
-				close(client.Send)
-			}
 			// Remove from all rooms
 			for _, room := range m.Rooms {
-				room.mu.Lock()
-				delete(room.Clients, client.UserID)
-				room.mu.Unlock()
+				if m.removeFromRoom(room, client) {
+					m.dropIfEmpty(room)
+				}
+			}
+			if _, ok := m.Clients[client.ID]; ok {
+				delete(m.Clients, client.ID)
+				close(client.Send)
 			}
 			m.mu.Unlock()
 		case message := <-m.Broadcast:
@@ -67,16 +116,7 @@ func (m *RoomManager) Run() {
 				room, ok := m.Rooms[message.Room]
 				m.mu.RUnlock()
 				if ok {
-					room.mu.RLock()
-					for _, client := range room.Clients {
-						select {
-						case client.Send <- message:
-						default:
-							close(client.Send)
-							delete(room.Clients, client.UserID)
-						}
-					}
-					room.mu.RUnlock()
+					room.send(message)
 				}
 			} else {
 				// Global broadcast
@@ -105,6 +145,7 @@ func (m *RoomManager) CreateRoom(id, name string, private bool, maxSize int) *Ro
 		Private:  private,
 		MaxSize:  maxSize,
 		Clients:  make(map[string]*Client),
+		Members:  make(map[string]PresenceMember),
 		Created:  time.Now(),
 		Metadata: make(map[string]interface{}),
 	}
@@ -112,6 +153,14 @@ func (m *RoomManager) CreateRoom(id, name string, private bool, maxSize int) *Ro
 	return room
 }
 
+// CreatePresenceRoom creates a room that tracks its members and notifies
+// them when clients join or leave.
+func (m *RoomManager) CreatePresenceRoom(id, name string, private bool, maxSize int) *Room {
+	room := m.CreateRoom(id, name, private, maxSize)
+	room.Presence = true
+	return room
+}
+
 // GetRoom returns a room by ID
 func (m *RoomManager) GetRoom(id string) (*Room, bool) {
 	m.mu.RLock()
@@ -120,14 +169,159 @@ func (m *RoomManager) GetRoom(id string) (*Room, bool) {
 	return room, ok
 }
 
-// SendToUser sends a message to a specific user
+// GetClient returns the connected client of a user. A user connected from
+// several tabs or devices has several clients; this is the one that
+// connected last. Use UserClients for all of them.
+func (m *RoomManager) GetClient(userID string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var latest *Client
+	for _, client := range m.Clients {
+		if client.UserID == userID && (latest == nil || client.seq > latest.seq) {
+			latest = client
+		}
+	}
+	return latest, latest != nil
+}
+
+// GetClientByID returns a connected client by its ID
+func (m *RoomManager) GetClientByID(id string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	client, ok := m.Clients[id]
+	return client, ok
+}
+
+// UserClients returns every connection of a user, one per tab or device
+func (m *RoomManager) UserClients(userID string) []*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var clients []*Client
+	for _, client := range m.Clients {
+		if client.UserID == userID {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// Join adds a client to an existing room. In presence rooms the other
+// members receive a presence:join event carrying meta, and the client
+// receives a presence:state event listing everyone already present.
+func (m *RoomManager) Join(roomID string, client *Client, meta map[string]interface{}) error {
+	// Holding the manager lock keeps Unregister from closing the client's
+	// Send channel while it is being added.
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Clients[client.ID] != client {
+		return ErrClientNotConnected
+	}
+	room, ok := m.Rooms[roomID]
+	if !ok {
+		return ErrRoomNotFound
+	}
+
+	room.mu.Lock()
+	if _, joined := room.Clients[client.ID]; joined {
+		room.mu.Unlock()
+		return nil
+	}
+	if room.MaxSize > 0 && len(room.Clients) >= room.MaxSize {
+		room.mu.Unlock()
+		return ErrRoomFull
+	}
+	room.Clients[client.ID] = client
+	client.rooms.Add(1)
+	if !room.Presence {
+		room.mu.Unlock()
+		return nil
+	}
+
+	member := PresenceMember{ClientID: client.ID, UserID: client.UserID, Meta: meta, JoinedAt: time.Now()}
+	room.Members[client.ID] = member
+	state := room.members()
+	room.mu.Unlock()
+
+	trySend(client, Message{
+		Type:      PresenceState,
+		Room:      roomID,
+		Payload:   map[string]interface{}{"members": state},
+		Timestamp: time.Now(),
+	})
+	room.sendExcept(client.ID, presenceMessage(PresenceJoin, roomID, member))
+	return nil
+}
+
+// Leave removes a client from a room, notifying presence members.
+func (m *RoomManager) Leave(roomID string, client *Client) {
+	room, ok := m.GetRoom(roomID)
+	if !ok || !m.removeFromRoom(room, client) {
+		return
+	}
+	m.mu.Lock()
+	m.dropIfEmpty(room)
+	m.mu.Unlock()
+}
+
+// Who returns the members of a presence room ordered by join time.
+func (m *RoomManager) Who(roomID string) []PresenceMember {
+	room, ok := m.GetRoom(roomID)
+	if !ok {
+		return nil
+	}
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.members()
+}
+
+// canJoin reports whether a client may join room on its own request.
+func (m *RoomManager) canJoin(client *Client, room string) bool {
+	if m.MaxRoomsPerClient > 0 && int(client.rooms.Load()) >= m.MaxRoomsPerClient {
+		return false
+	}
+	if m.Authorize != nil {
+		return m.Authorize(client, room)
+	}
+	r, ok := m.GetRoom(room)
+	return ok && !r.Private
+}
+
+// removeFromRoom reports whether client was in room.
+func (m *RoomManager) removeFromRoom(room *Room, client *Client) bool {
+	room.mu.Lock()
+	if _, ok := room.Clients[client.ID]; !ok {
+		room.mu.Unlock()
+		return false
+	}
+	delete(room.Clients, client.ID)
+	client.rooms.Add(-1)
+	member, present := room.Members[client.ID]
+	delete(room.Members, client.ID)
+	room.mu.Unlock()
+
+	if present {
+		room.send(presenceMessage(PresenceLeave, room.ID, member))
+	}
+	return true
+}
+
+// dropIfEmpty removes a temporary room nobody is in. The caller holds m.mu.
+func (m *RoomManager) dropIfEmpty(room *Room) {
+	room.mu.RLock()
+	empty := room.Temporary && len(room.Clients) == 0
+	room.mu.RUnlock()
+	if empty && m.Rooms[room.ID] == room {
+		delete(m.Rooms, room.ID)
+	}
+}
+
+// SendToUser sends a message to every connection of a user
 func (m *RoomManager) SendToUser(userID string, message Message) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	if client, ok := m.Clients[userID]; ok {
-		select {
-		case client.Send <- message:
-		default:
+	for _, client := range m.Clients {
+		if client.UserID == userID {
+			trySend(client, message)
 		}
 	}
 }
@@ -160,3 +354,52 @@ func (m *RoomManager) GetStats() map[string]interface{} {
 		"rooms":        rooms,
 	}
 }
+
+// members returns the presence members ordered by join time. The caller
+// must hold room.mu.
+func (r *Room) members() []PresenceMember {
+	members := make([]PresenceMember, 0, len(r.Members))
+	for _, member := range r.Members {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+	return members
+}
+
+func (r *Room) send(message Message) {
+	r.sendExcept("", message)
+}
+
+func (r *Room) sendExcept(clientID string, message Message) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for id, client := range r.Clients {
+		if id != clientID {
+			trySend(client, message)
+		}
+	}
+}
+
+func presenceMessage(event, roomID string, member PresenceMember) Message {
+	return Message{
+		Type: event,
+		Room: roomID,
+		Payload: map[string]interface{}{
+			"client_id": member.ClientID,
+			"user_id":   member.UserID,
+			"meta":      member.Meta,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// trySend queues a message without blocking; slow clients drop messages
+// rather than stalling the room.
+func trySend(client *Client, message Message) {
+	select {
+	case client.Send <- message:
+	default:
+	}
+}
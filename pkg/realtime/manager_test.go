@@ -0,0 +1,132 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func connect(m *RoomManager, userID string) *Client {
+	c := NewClient(nil, userID, m)
+	m.Register <- c
+	return c
+}
+
+func next(t *testing.T, c *Client) Message {
+	t.Helper()
+	select {
+	case msg := <-c.Send:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return Message{}
+	}
+}
+
+func waitRegistered(t *testing.T, m *RoomManager, clients ...*Client) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		for _, c := range clients {
+			if _, ok := m.GetClientByID(c.ID); !ok {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+}
+
+func TestClientIDsAreUnique(t *testing.T) {
+	m := NewRoomManager()
+	a, b := connect(m, "1"), connect(m, "1")
+	waitRegistered(t, m, a, b)
+
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.Len(t, m.UserClients("1"), 2)
+
+	latest, ok := m.GetClient("1")
+	require.True(t, ok)
+	assert.Same(t, b, latest, "GetClient looks up by user ID and returns the newest connection")
+	_, ok = m.GetClient(a.ID)
+	assert.False(t, ok)
+}
+
+func TestPresenceRoom(t *testing.T) {
+	m := NewRoomManager()
+	m.CreatePresenceRoom("lobby", "Lobby", false, 0)
+	alice, bob := connect(m, "alice"), connect(m, "bob")
+	waitRegistered(t, m, alice, bob)
+
+	require.NoError(t, m.Join("lobby", alice, map[string]interface{}{"status": "online"}))
+	state := next(t, alice)
+	assert.Equal(t, PresenceState, state.Type)
+	assert.Len(t, state.Payload["members"], 1)
+
+	require.NoError(t, m.Join("lobby", bob, nil))
+	assert.Equal(t, PresenceState, next(t, bob).Type)
+	joined := next(t, alice)
+	assert.Equal(t, PresenceJoin, joined.Type)
+	assert.Equal(t, bob.ID, joined.Payload["client_id"])
+
+	who := m.Who("lobby")
+	require.Len(t, who, 2)
+	assert.Equal(t, "alice", who[0].UserID)
+	assert.Equal(t, "online", who[0].Meta["status"])
+	assert.Equal(t, "bob", who[1].UserID)
+
+	m.Unregister <- bob
+	left := next(t, alice)
+	assert.Equal(t, PresenceLeave, left.Type)
+	assert.Equal(t, "bob", left.Payload["user_id"])
+	assert.Len(t, m.Who("lobby"), 1)
+
+	assert.ErrorIs(t, m.Join("lobby", bob, nil), ErrClientNotConnected)
+	assert.ErrorIs(t, m.Join("missing", alice, nil), ErrRoomNotFound)
+}
+
+func TestRoomMaxSize(t *testing.T) {
+	m := NewRoomManager()
+	m.CreateRoom("duo", "Duo", false, 1)
+	a, b := connect(m, "a"), connect(m, "b")
+	waitRegistered(t, m, a, b)
+
+	require.NoError(t, m.Join("duo", a, nil))
+	assert.ErrorIs(t, m.Join("duo", b, nil), ErrRoomFull)
+}
+
+func TestMaxRoomsPerClient(t *testing.T) {
+	m := NewRoomManager()
+	m.MaxRoomsPerClient = 2
+	m.Authorize = func(_ *Client, room string) bool {
+		if _, ok := m.GetRoom(room); !ok {
+			m.CreateRoom(room, room, true, 0).Temporary = true
+		}
+		return true
+	}
+	c := connect(m, "a")
+	waitRegistered(t, m, c)
+
+	for _, room := range []string{"one", "two"} {
+		require.True(t, m.canJoin(c, room))
+		require.NoError(t, m.Join(room, c, nil))
+	}
+	assert.False(t, m.canJoin(c, "three"), "a third room is over the cap")
+	_, created := m.GetRoom("three")
+	assert.False(t, created, "rooms over the cap are not created")
+
+	// Leaving frees a slot and removes the temporary room nobody is in.
+	m.Leave("one", c)
+	_, ok := m.GetRoom("one")
+	assert.False(t, ok)
+	assert.True(t, m.canJoin(c, "three"))
+
+	// So does disconnecting.
+	require.NoError(t, m.Join("three", c, nil))
+	m.Unregister <- c
+	require.Eventually(t, func() bool {
+		_, two := m.GetRoom("two")
+		_, three := m.GetRoom("three")
+		return !two && !three
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Command describes a Redis command, or a pipeline of commands, as seen by a
+// CommandHook. Argument values are never included, as they may hold secrets.
+type Command struct {
+	// Connection is the name of the Manager connection, "default" unless set.
+	Connection string
+	// Name is the lower-case command name, such as "get", or "pipeline".
+	Name string
+	// KeyPrefix is the first key of the command up to its last ':', so that
+	// "session:abc" gives "session" and "astra:cache:user:1" gives
+	// "astra:cache:user". It is empty for keyless commands and pipelines.
+	KeyPrefix string
+	// Size is the number of commands in a pipeline, and 1 otherwise.
+	Size int
+	// Duration and Err are set once the command has run. A missing key
+	// (redis.Nil) is not an error.
+	Duration time.Duration
+	Err      error
+}
+
+// CommandHook observes the commands sent on a connection. BeforeCommand runs
+// before the command is sent and may return a derived context, for example
+// one carrying a span; AfterCommand receives that context once the command
+// has returned. Hooks run in the order they were added before a command and
+// in reverse order after it.
+type CommandHook interface {
+	BeforeCommand(ctx context.Context, cmd *Command) context.Context
+	AfterCommand(ctx context.Context, cmd *Command)
+}
+
+// AddCommandHook adds a hook for every command sent on the client from now on.
+func (c *Client) AddCommandHook(hook CommandHook) {
+	c.hook.add(hook)
+}
+
+// AddCommandHook adds a hook to every connection of the manager, including
+// those connected later.
+func (m *Manager) AddCommandHook(hook CommandHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+	for _, client := range m.clients {
+		client.AddCommandHook(hook)
+	}
+}
+
+// keylessCommands are commands whose first argument is not a key.
+var keylessCommands = map[string]bool{
+	"auth": true, "client": true, "cluster": true, "command": true, "config": true,
+	"echo": true, "function": true, "hello": true, "info": true, "memory": true,
+	"ping": true, "psubscribe": true, "punsubscribe": true, "script": true,
+	"select": true, "subscribe": true, "unsubscribe": true, "xread": true,
+	"xreadgroup": true,
+}
+
+// keyPrefix returns the prefix of the first key of a command's arguments.
+func keyPrefix(args []any) string {
+	if len(args) < 2 {
+		return ""
+	}
+	name, _ := args[0].(string)
+	name = strings.ToLower(name)
+	i := 1
+	switch {
+	case keylessCommands[name]:
+		return ""
+	case name == "eval" || name == "evalsha" || name == "eval_ro" || name == "evalsha_ro" ||
+		name == "fcall" || name == "fcall_ro":
+		// EVAL script numkeys key [key ...]
+		if len(args) < 4 || argString(args[2]) == "0" {
+			return ""
+		}
+		i = 3
+	}
+	key := argString(args[i])
+	if end := strings.LastIndexByte(key, ':'); end > 0 {
+		return key[:end]
+	}
+	return ""
+}
+
+func argString(arg any) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+	return ""
+}
+
+// commandErr drops redis.Nil, which only reports a missing key.
+func commandErr(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// hookList is the set of command hooks of a client. It is copied on write
+// so that commands read it without locking.
+type hookList struct {
+	mu    sync.Mutex
+	hooks atomic.Pointer[[]CommandHook]
+}
+
+func (l *hookList) add(hook CommandHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.load()
+	hooks := make([]CommandHook, len(old), len(old)+1)
+	copy(hooks, old)
+	hooks = append(hooks, hook)
+	l.hooks.Store(&hooks)
+}
+
+func (l *hookList) load() []CommandHook {
+	if hooks := l.hooks.Load(); hooks != nil {
+		return *hooks
+	}
+	return nil
+}
+
+// run calls fn between the hooks' BeforeCommand and AfterCommand.
+func (l *hookList) run(ctx context.Context, cmd *Command, fn func(context.Context) error) error {
+	hooks := l.load()
+	for _, h := range hooks {
+		ctx = h.BeforeCommand(ctx, cmd)
+	}
+	start := time.Now()
+	err := fn(ctx)
+	cmd.Duration = time.Since(start)
+	cmd.Err = commandErr(err)
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterCommand(ctx, cmd)
+	}
+	return err
+}
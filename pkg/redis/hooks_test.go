@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type recordingHook struct {
+	mu     sync.Mutex
+	label  string
+	events *[]string
+	cmds   []Command
+}
+
+func (h *recordingHook) BeforeCommand(ctx context.Context, cmd *Command) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.events = append(*h.events, h.label+" before "+cmd.Name)
+	return ctx
+}
+
+func (h *recordingHook) AfterCommand(_ context.Context, cmd *Command) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.events = append(*h.events, h.label+" after "+cmd.Name)
+	h.cmds = append(h.cmds, *cmd)
+}
+
+func TestKeyPrefix(t *testing.T) {
+	cases := []struct {
+		args []any
+		want string
+	}{
+		{[]any{"get", "session:abc"}, "session"},
+		{[]any{"set", "astra:cache:user:1", "v"}, "astra:cache:user"},
+		{[]any{"get", "plain"}, ""},
+		{[]any{"ping"}, ""},
+		{[]any{"select", "1"}, ""},
+		{[]any{"eval", "return 1", 1, "lock:jobs", "x"}, "lock"},
+		{[]any{"evalsha", "abc123", "0"}, ""},
+		{[]any{"hset", []byte("user:1:profile"), "name", "Ada"}, "user:1"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, keyPrefix(tc.args), "%v", tc.args)
+	}
+}
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	m := NewManager(config.RedisConfig{URL: "redis://" + mr.Addr()}, nil)
+	m.AddConfig("cache", config.RedisConfig{URL: "redis://" + mr.Addr()})
+	t.Cleanup(func() { _ = m.Close(context.Background()) })
+	return m
+}
+
+func TestClient_CommandHooks(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	var events []string
+	first := &recordingHook{label: "first", events: &events}
+	second := &recordingHook{label: "second", events: &events}
+	m.AddCommandHook(first)
+
+	// Hooks added to the manager reach connections made later.
+	client, err := m.Connection("cache")
+	require.NoError(t, err)
+	client.AddCommandHook(second)
+
+	require.NoError(t, client.Set(ctx, "session:abc", "1", 0).Err())
+	assert.Equal(t, []string{"first before set", "second before set", "second after set", "first after set"}, events)
+
+	got := first.cmds[0]
+	assert.Equal(t, "cache", got.Connection)
+	assert.Equal(t, "set", got.Name)
+	assert.Equal(t, "session", got.KeyPrefix)
+	assert.Equal(t, 1, got.Size)
+	assert.Positive(t, got.Duration)
+	assert.NoError(t, got.Err)
+
+	// A missing key is not an error; a failed command is.
+	assert.ErrorIs(t, client.Get(ctx, "session:missing").Err(), redis.Nil)
+	assert.NoError(t, first.cmds[1].Err)
+	require.NoError(t, client.Set(ctx, "counter:a", "word", 0).Err())
+	assert.Error(t, client.Incr(ctx, "counter:a").Err())
+	assert.Error(t, first.cmds[len(first.cmds)-1].Err)
+
+	_, err = client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Get(ctx, "session:abc")
+		p.Get(ctx, "session:def")
+		return nil
+	})
+	assert.ErrorIs(t, err, redis.Nil)
+	pipe := first.cmds[len(first.cmds)-1]
+	assert.Equal(t, "pipeline", pipe.Name)
+	assert.Equal(t, 2, pipe.Size)
+	assert.Empty(t, pipe.KeyPrefix)
+
+	assert.Contains(t, m.PoolStats(), "cache")
+}
+
+func TestTracingAndMetricsHooks(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	spans := tracetest.NewSpanRecorder()
+	m.AddCommandHook(TracingHook(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test")))
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	hook, err := MetricsHook(meter)
+	require.NoError(t, err)
+	m.AddCommandHook(hook)
+
+	client, err := m.Default()
+	require.NoError(t, err)
+	require.NoError(t, ObservePool(client.UniversalClient, meter, "default"))
+
+	require.NoError(t, client.Set(ctx, "user:1", "Ada", 0).Err())
+	assert.Error(t, client.HGet(ctx, "user:1", "name").Err())
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "redis.set", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), commandAttrs(&Command{Name: "set", Connection: "default", KeyPrefix: "user"})[3])
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	names := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, md := range sm.Metrics {
+			names[md.Name] = md.Data
+		}
+	}
+	require.Contains(t, names, "redis.client.command.duration")
+	hist := names["redis.client.command.duration"].(metricdata.Histogram[float64])
+	var count uint64
+	for _, dp := range hist.DataPoints {
+		count += dp.Count
+	}
+	assert.Equal(t, uint64(2), count)
+	assert.Contains(t, names, "redis.client.connections.total")
+	assert.Contains(t, names, "redis.client.connections.hits")
+}
@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandAttrs returns the attributes shared by spans and metrics.
+func commandAttrs(cmd *Command) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", cmd.Name),
+		attribute.String("db.redis.connection", cmd.Connection),
+		attribute.String("db.redis.key_prefix", cmd.KeyPrefix),
+	}
+}
+
+type tracingHook struct {
+	tracer trace.Tracer
+}
+
+// TracingHook returns a CommandHook that records a client span for every
+// command and pipeline.
+func TracingHook(tracer trace.Tracer) CommandHook {
+	return &tracingHook{tracer: tracer}
+}
+
+func (h *tracingHook) BeforeCommand(ctx context.Context, cmd *Command) context.Context {
+	attrs := commandAttrs(cmd)
+	if cmd.Name == "pipeline" {
+		attrs = append(attrs, attribute.Int("db.redis.pipeline_size", cmd.Size))
+	}
+	ctx, _ = h.tracer.Start(ctx, "redis."+cmd.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (h *tracingHook) AfterCommand(ctx context.Context, cmd *Command) {
+	span := trace.SpanFromContext(ctx)
+	if cmd.Err != nil {
+		span.RecordError(cmd.Err)
+		span.SetStatus(codes.Error, cmd.Err.Error())
+	}
+	span.End()
+}
+
+type metricsHook struct {
+	duration metric.Float64Histogram
+}
+
+// MetricsHook returns a CommandHook that records the duration of every
+// command in the redis.client.command.duration histogram, by command,
+// connection, key prefix and whether it failed.
+func MetricsHook(meter metric.Meter) (CommandHook, error) {
+	duration, err := meter.Float64Histogram("redis.client.command.duration",
+		metric.WithDescription("Duration of Redis commands and pipelines"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("redis/metrics: failed to create command duration histogram: %w", err)
+	}
+	return &metricsHook{duration: duration}, nil
+}
+
+func (h *metricsHook) BeforeCommand(ctx context.Context, _ *Command) context.Context {
+	return ctx
+}
+
+func (h *metricsHook) AfterCommand(ctx context.Context, cmd *Command) {
+	attrs := append(commandAttrs(cmd), attribute.Bool("error", cmd.Err != nil))
+	h.duration.Record(ctx, float64(cmd.Duration.Microseconds())/1000, metric.WithAttributes(attrs...))
+}
+
+// ObservePool registers OTel metrics for the connection pool of a Redis
+// client, named after the connection.
+func ObservePool(client redis.UniversalClient, meter metric.Meter, connection string) error {
+	if client == nil || meter == nil {
+		return nil
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("db.system", "redis"),
+		attribute.String("db.redis.connection", connection),
+	)
+
+	gauges := []struct {
+		name, description string
+		value             func(*redis.PoolStats) uint32
+	}{
+		{"redis.client.connections.total", "Number of connections in the pool", func(s *redis.PoolStats) uint32 { return s.TotalConns }},
+		{"redis.client.connections.idle", "Number of idle connections in the pool", func(s *redis.PoolStats) uint32 { return s.IdleConns }},
+	}
+	for _, g := range gauges {
+		value := g.value
+		_, err := meter.Int64ObservableGauge(g.name,
+			metric.WithDescription(g.description),
+			metric.WithInt64Callback(func(_ context.Context, obs metric.Int64Observer) error {
+				obs.Observe(int64(value(client.PoolStats())), attrs)
+				return nil
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("redis/metrics: failed to create %s gauge: %w", g.name, err)
+		}
+	}
+
+	counters := []struct {
+		name, description string
+		value             func(*redis.PoolStats) uint32
+	}{
+		{"redis.client.connections.hits", "Times a free connection was found in the pool", func(s *redis.PoolStats) uint32 { return s.Hits }},
+		{"redis.client.connections.misses", "Times a free connection was not found in the pool", func(s *redis.PoolStats) uint32 { return s.Misses }},
+		{"redis.client.connections.timeouts", "Times waiting for a connection timed out", func(s *redis.PoolStats) uint32 { return s.Timeouts }},
+		{"redis.client.connections.stale", "Stale connections removed from the pool", func(s *redis.PoolStats) uint32 { return s.StaleConns }},
+	}
+	for _, c := range counters {
+		value := c.value
+		_, err := meter.Int64ObservableCounter(c.name,
+			metric.WithDescription(c.description),
+			metric.WithInt64Callback(func(_ context.Context, obs metric.Int64Observer) error {
+				obs.Observe(int64(value(client.PoolStats())), attrs)
+				return nil
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("redis/metrics: failed to create %s counter: %w", c.name, err)
+		}
+	}
+
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/shauryagautam/Astra/pkg/engine"
 	"github.com/shauryagautam/Astra/pkg/engine/config"
 	"github.com/shauryagautam/Astra/pkg/cache"
+	"github.com/shauryagautam/Astra/pkg/observability/metrics"
+	"github.com/shauryagautam/Astra/pkg/observability/trace"
 )
 
 // RedisProvider implements engine.Provider for Redis services.
@@ -46,6 +48,10 @@ func (p *RedisProvider) Boot(a *engine.App) error {
 		return nil
 	}
 	manager := p.manager
+	telemetry := a.Env().String("OTEL_EXPORTER_OTLP_ENDPOINT", "") != "" || a.Env().String("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "") != ""
+	if telemetry {
+		p.instrument(a)
+	}
 
 	// Boot connects and pings
 	ctx, cancel := context.WithTimeout(a.BaseContext(), 10*time.Second)
@@ -59,8 +65,26 @@ func (p *RedisProvider) Boot(a *engine.App) error {
 		return fmt.Errorf("redis.Boot: %w", err)
 	}
 
+	if telemetry {
+		meter := metrics.GetMeter()
+		manager.mu.RLock()
+		for name, c := range manager.clients {
+			if err := ObservePool(c.UniversalClient, meter, name); err != nil {
+				a.Logger().Warn("redis: failed to register pool metrics", "connection", name, "error", err)
+			}
+		}
+		manager.mu.RUnlock()
+	}
+
 	// Initialize Redis-backed cache
-	store := cache.NewRedisStore(client, "astra:cache:")
+	codec, err := cache.CodecByName(a.Env().String("CACHE_CODEC", "json"))
+	if err != nil {
+		return fmt.Errorf("redis.Boot: %w", err)
+	}
+	store := cache.NewRedisStore(client, "astra:cache:",
+		cache.WithCodec(codec),
+		cache.WithCompression(a.Env().Int("CACHE_COMPRESS_THRESHOLD", 0)),
+	)
 	// Ideally, this store should be injected into whoever needs it via Wire
 	// instead of being used here. But for now, we'll keep it as a side effect
 	// until the entire framework is fully Wire-compliant.
@@ -69,6 +93,18 @@ func (p *RedisProvider) Boot(a *engine.App) error {
 	return nil
 }
 
+// instrument feeds every command of every connection into tracing and the
+// command duration histogram.
+func (p *RedisProvider) instrument(a *engine.App) {
+	p.manager.AddCommandHook(TracingHook(trace.GetTracer()))
+	hook, err := MetricsHook(metrics.GetMeter())
+	if err != nil {
+		a.Logger().Warn("redis: failed to register command metrics", "error", err)
+		return
+	}
+	p.manager.AddCommandHook(hook)
+}
+
 // Shutdown gracefully closes all Redis connections.
 func (p *RedisProvider) Shutdown(ctx context.Context, a *engine.App) error {
 	if p.manager == nil {
@@ -1,8 +1,9 @@
-package redis
+package redis_test
 
 import (
 	"testing"
 
+	"github.com/shauryagautam/Astra/pkg/redis"
 	"github.com/shauryagautam/Astra/pkg/test_util"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +16,7 @@ func TestRedisProvider_Register(t *testing.T) {
 	app.Config().Redis.Host = ""
 	app.Config().Redis.URL = ""
 
-	p := &RedisProvider{}
+	p := &redis.RedisProvider{}
 	err := p.Register(app)
 	assert.NoError(t, err)
 }
@@ -28,7 +29,7 @@ func TestRedisProvider_IsOptIn(t *testing.T) {
 	app.Config().Redis.Host = ""
 	app.Config().Redis.URL = ""
 
-	p := &RedisProvider{}
+	p := &redis.RedisProvider{}
 	// Boot connects and pings, but it should return early if not configured
 	err := p.Boot(app)
 	assert.NoError(t, err)
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // RateLimiter provides rate limiting functionality.
@@ -17,44 +20,88 @@ func (c *Client) NewRateLimiter() *RateLimiter {
 	return &RateLimiter{client: c}
 }
 
-// Allow checks if an action should be allowed based on a rate limit.
-// It uses a sliding window algorithm implemented via a Redis Lua script.
-func (rl *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, error) {
-	script := `
-		local key = KEYS[1]
-		local limit = tonumber(ARGV[1])
-		local window = tonumber(ARGV[2])
-		local now = tonumber(ARGV[3])
-		
-		-- Remove old entries
-		redis.call("zremrangebyscore", key, 0, now - window)
-		
-		-- Count current entries
-		local current = redis.call("zcard", key)
-		
-		if current < limit then
-			-- Add new entry
-			redis.call("zadd", key, now, now)
-			redis.call("pexpire", key, window)
-			return {1, limit - current - 1}
-		else
-			return {0, 0}
-		end
-	`
+// rateLimitScript counts one request in the sliding window at KEYS[1] and
+// returns whether it was allowed, the requests left and when the oldest
+// request leaves the window, in Unix milliseconds.
+var rateLimitScript = redis.NewScript(`
+	local key = KEYS[1]
+	local limit = tonumber(ARGV[1])
+	local window = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local member = ARGV[4]
+
+	-- Remove old entries
+	redis.call("zremrangebyscore", key, 0, now - window)
+
+	-- Count current entries
+	local current = redis.call("zcard", key)
+	local allowed = 0
+	if current < limit then
+		redis.call("zadd", key, now, member)
+		redis.call("pexpire", key, window)
+		current = current + 1
+		allowed = 1
+	end
+
+	local resetAt = now + window
+	local oldest = redis.call("zrange", key, 0, 0, "WITHSCORES")
+	if oldest[2] ~= nil then
+		resetAt = tonumber(oldest[2]) + window
+	end
+
+	return {allowed, math.max(limit - current, 0), resetAt}
+`)
+
+var rateLimitSeq atomic.Uint64
+
+// RateLimit is the outcome of one RateLimiter.Attempt.
+type RateLimit struct {
+	// Allowed reports whether the action fit within the limit.
+	Allowed bool
+	// Remaining is how many more actions are allowed right now.
+	Remaining int
+	// ResetAt is when the oldest counted action leaves the window.
+	ResetAt time.Time
+	// RetryAfter is how long to wait before the next action can be
+	// allowed. It is 0 when Allowed.
+	RetryAfter time.Duration
+}
 
+// Attempt counts one action against key in a sliding window of limit
+// actions. The result comes from a single script call, so callers get the
+// reset time for headers without another round trip.
+func (rl *RateLimiter) Attempt(ctx context.Context, key string, limit int, window time.Duration) (RateLimit, error) {
 	// Sanitize key to prevent injection
 	sanitizedKey := sanitizeRedisKey(key)
-	now := time.Now().UnixMilli()
-	res, err := rl.client.Eval(ctx, script, []string{"ratelimit:" + sanitizedKey}, limit, window.Milliseconds(), now).Result()
+	now := time.Now()
+	member := fmt.Sprintf("%d:%d", now.UnixNano(), rateLimitSeq.Add(1))
+	res, err := rateLimitScript.Run(ctx, rl.client, []string{"ratelimit:" + sanitizedKey}, limit, window.Milliseconds(), now.UnixMilli(), member).Int64Slice()
 	if err != nil {
-		return false, 0, fmt.Errorf("redis: rate limit check failed: %w", err)
+		return RateLimit{}, fmt.Errorf("redis: rate limit check failed: %w", err)
+	}
+	if len(res) != 3 {
+		return RateLimit{}, fmt.Errorf("redis: rate limit check failed: unexpected reply %v", res)
 	}
 
-	parts := res.([]any)
-	allowed := parts[0].(int64) == 1
-	remaining := int(parts[1].(int64))
+	rate := RateLimit{
+		Allowed:   res[0] == 1,
+		Remaining: int(res[1]),
+		ResetAt:   time.UnixMilli(res[2]),
+	}
+	if !rate.Allowed {
+		rate.RetryAfter = max(rate.ResetAt.Sub(now), 0)
+	}
+	return rate, nil
+}
 
-	return allowed, remaining, nil
+// Allow checks if an action should be allowed based on a rate limit,
+// returning the actions left. Use Attempt when the reset time is needed too.
+func (rl *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, error) {
+	rate, err := rl.Attempt(ctx, key, limit, window)
+	if err != nil {
+		return false, 0, err
+	}
+	return rate.Allowed, rate.Remaining, nil
 }
 
 // sanitizeRedisKey removes potentially dangerous characters from Redis keys
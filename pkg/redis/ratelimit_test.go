@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Attempt(t *testing.T) {
+	m := newTestManager(t)
+	client, err := m.Connection("cache")
+	require.NoError(t, err)
+	rl := client.NewRateLimiter()
+	ctx := context.Background()
+
+	// Attempts in the same millisecond are counted separately.
+	for want := 2; want >= 0; want-- {
+		rate, err := rl.Attempt(ctx, "login:ada", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, rate.Allowed)
+		assert.Equal(t, want, rate.Remaining)
+		assert.Zero(t, rate.RetryAfter)
+	}
+
+	rate, err := rl.Attempt(ctx, "login:ada", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, rate.Allowed)
+	assert.Equal(t, 0, rate.Remaining)
+	assert.InDelta(t, time.Minute, rate.RetryAfter, float64(time.Second))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), rate.ResetAt, time.Second)
+
+	ok, remaining, err := rl.Allow(ctx, "login:grace", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, remaining)
+}
@@ -16,6 +16,7 @@ type Manager struct {
 	configs map[string]config.RedisConfig
 	clients map[string]*Client
 	events  *event.Emitter
+	hooks   []CommandHook
 	mu      sync.RWMutex
 	started bool
 }
@@ -74,7 +75,7 @@ func (m *Manager) Connection(name string) (*Client, error) {
 		return nil, err
 	}
 
-	m.clients[name] = newClient
+	m.attach(name, newClient)
 	return newClient, nil
 }
 
@@ -95,13 +96,35 @@ func (m *Manager) Connect(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("redis: failed to connect to %q: %w", name, err)
 		}
-		m.clients[name] = client
+		m.attach(name, client)
 	}
 
 	m.started = true
 	return nil
 }
 
+// attach names a new client and gives it the manager's command hooks. The
+// caller holds m.mu.
+func (m *Manager) attach(name string, client *Client) {
+	client.hook.connection = name
+	for _, hook := range m.hooks {
+		client.AddCommandHook(hook)
+	}
+	m.clients[name] = client
+}
+
+// PoolStats returns the connection pool statistics of every connected client
+// by connection name.
+func (m *Manager) PoolStats() map[string]*redis.PoolStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := make(map[string]*redis.PoolStats, len(m.clients))
+	for name, client := range m.clients {
+		stats[name] = client.PoolStats()
+	}
+	return stats
+}
+
 // Close gracefully closes all active Redis connections.
 func (m *Manager) Close(ctx context.Context) error {
 	m.mu.Lock()
@@ -125,6 +148,7 @@ type Client struct {
 	redis.UniversalClient
 	config config.RedisConfig
 	events *event.Emitter
+	hook   *redisHook
 	mu     sync.RWMutex
 	// Pipeline buffer for ultra-fast batch operations
 	pipeline  redis.Pipeliner
@@ -258,10 +282,9 @@ func NewClient(cfg config.RedisConfig, emitter *event.Emitter) (*Client, error)
 
 	client := redis.NewUniversalClient(opts)
 
-	// Add event emission hook
-	if emitter != nil {
-		client.AddHook(&redisHook{events: emitter})
-	}
+	// Events and command hooks
+	hook := &redisHook{events: emitter, connection: "default"}
+	client.AddHook(hook)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
@@ -275,11 +298,14 @@ func NewClient(cfg config.RedisConfig, emitter *event.Emitter) (*Client, error)
 		UniversalClient: client,
 		config:          cfg,
 		events:          emitter,
+		hook:            hook,
 	}, nil
 }
 
 type redisHook struct {
-	events *event.Emitter
+	events     *event.Emitter
+	connection string
+	hookList
 }
 
 func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
@@ -288,15 +314,14 @@ func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
 
 func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	return func(ctx context.Context, cmd redis.Cmder) error {
-		start := time.Now()
-		err := next(ctx, cmd)
-		duration := time.Since(start)
+		info := &Command{Connection: h.connection, Name: cmd.Name(), KeyPrefix: keyPrefix(cmd.Args()), Size: 1}
+		err := h.run(ctx, info, func(ctx context.Context) error { return next(ctx, cmd) })
 
 		if h.events != nil {
 			h.events.EmitPayload(ctx, "redis.command_executed", map[string]any{
 				"command":  cmd.Name(),
 				"args":     cmd.Args(),
-				"duration": duration,
+				"duration": info.Duration,
 				"error":    err,
 			})
 		}
@@ -306,9 +331,8 @@ func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 
 func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
-		start := time.Now()
-		err := next(ctx, cmds)
-		duration := time.Since(start)
+		info := &Command{Connection: h.connection, Name: "pipeline", Size: len(cmds)}
+		err := h.run(ctx, info, func(ctx context.Context) error { return next(ctx, cmds) })
 
 		if h.events != nil {
 			names := make([]string, len(cmds))
@@ -318,7 +342,7 @@ func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.Pr
 			h.events.EmitPayload(ctx, "redis.pipeline_executed", map[string]any{
 				"commands": names,
 				"count":    len(cmds),
-				"duration": duration,
+				"duration": info.Duration,
 				"error":    err,
 			})
 		}
@@ -0,0 +1,114 @@
+package structures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Counter is an atomic integer counter, optionally per window.
+type Counter struct {
+	keyspace
+}
+
+// Bucket is the count of one window.
+type Bucket struct {
+	Start time.Time
+	Count int64
+}
+
+// NewCounter returns the counter stored under name.
+func NewCounter(client redis.UniversalClient, name string, opts ...Option) *Counter {
+	return &Counter{newKeyspace(client, "counter", name, opts)}
+}
+
+// Incr adds one to the current window and returns the new count.
+func (c *Counter) Incr(ctx context.Context) (int64, error) {
+	return c.IncrBy(ctx, 1)
+}
+
+// IncrBy adds n, which may be negative, to the current window and returns
+// the new count.
+func (c *Counter) IncrBy(ctx context.Context, n int64) (int64, error) {
+	key, expireAt := c.current()
+	var incr *redis.IntCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.IncrBy(ctx, key, n)
+		expire(ctx, pipe, key, expireAt)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("structures: counter incr: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// Get returns the count of the current window.
+func (c *Counter) Get(ctx context.Context) (int64, error) {
+	key, _ := c.current()
+	n, err := c.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("structures: counter get: %w", err)
+	}
+	return n, nil
+}
+
+// History returns the counts of the last n windows, newest first. Windows
+// older than the history count as zero.
+func (c *Counter) History(ctx context.Context, n int) ([]Bucket, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	starts := c.last(n)
+	keys := make([]string, len(starts))
+	for i, start := range starts {
+		keys[i] = c.key(start)
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("structures: counter history: %w", err)
+	}
+
+	buckets := make([]Bucket, len(starts))
+	for i, start := range starts {
+		buckets[i].Start = start
+		if s, ok := values[i].(string); ok {
+			count, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("structures: counter history: %q is not a count", s)
+			}
+			buckets[i].Count = count
+		}
+	}
+	return buckets, nil
+}
+
+// Sum returns the total of the last n windows, which approximates a
+// sliding window of n windows.
+func (c *Counter) Sum(ctx context.Context, n int) (int64, error) {
+	buckets, err := c.History(ctx, n)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, b := range buckets {
+		total += b.Count
+	}
+	return total, nil
+}
+
+// Reset deletes the count of the current window.
+func (c *Counter) Reset(ctx context.Context) error {
+	key, _ := c.current()
+	if err := c.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("structures: counter reset: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package structures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Leaderboard ranks members by score in a sorted set, optionally per
+// window, such as a weekly leaderboard.
+type Leaderboard struct {
+	keyspace
+}
+
+// Entry is a member of a leaderboard with its rank, starting at 1.
+type Entry struct {
+	Rank   int64   `json:"rank"`
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// Page is one page of a leaderboard.
+type Page struct {
+	Entries []Entry `json:"entries"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"per_page"`
+	Total   int64   `json:"total"`
+}
+
+// NewLeaderboard returns the leaderboard stored under name.
+func NewLeaderboard(client redis.UniversalClient, name string, opts ...Option) *Leaderboard {
+	return &Leaderboard{newKeyspace(client, "leaderboard", name, opts)}
+}
+
+// Set sets the score of member, adding it if needed.
+func (l *Leaderboard) Set(ctx context.Context, member string, score float64) error {
+	key, expireAt := l.current()
+	_, err := l.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{Score: score, Member: member})
+		expire(ctx, pipe, key, expireAt)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("structures: leaderboard set: %w", err)
+	}
+	return nil
+}
+
+// Incr adds by to the score of member, starting from zero, and returns the
+// new score.
+func (l *Leaderboard) Incr(ctx context.Context, member string, by float64) (float64, error) {
+	key, expireAt := l.current()
+	var incr *redis.FloatCmd
+	_, err := l.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.ZIncrBy(ctx, key, by, member)
+		expire(ctx, pipe, key, expireAt)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("structures: leaderboard incr: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// Remove removes members from the leaderboard.
+func (l *Leaderboard) Remove(ctx context.Context, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	key, _ := l.current()
+	args := make([]any, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	if err := l.client.ZRem(ctx, key, args...).Err(); err != nil {
+		return fmt.Errorf("structures: leaderboard remove: %w", err)
+	}
+	return nil
+}
+
+// Score returns the score of member. ok is false when it is not ranked.
+func (l *Leaderboard) Score(ctx context.Context, member string) (score float64, ok bool, err error) {
+	key, _ := l.current()
+	score, err = l.client.ZScore(ctx, key, member).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("structures: leaderboard score: %w", err)
+	}
+	return score, true, nil
+}
+
+// Rank returns the rank of member, starting at 1. ok is false when it is
+// not ranked.
+func (l *Leaderboard) Rank(ctx context.Context, member string) (rank int64, ok bool, err error) {
+	key, _ := l.current()
+	rank, err = l.rank(ctx, key, member)
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("structures: leaderboard rank: %w", err)
+	}
+	return rank + 1, true, nil
+}
+
+// Count returns the number of ranked members.
+func (l *Leaderboard) Count(ctx context.Context) (int64, error) {
+	key, _ := l.current()
+	n, err := l.client.ZCard(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("structures: leaderboard count: %w", err)
+	}
+	return n, nil
+}
+
+// Top returns the first n entries.
+func (l *Leaderboard) Top(ctx context.Context, n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	key, _ := l.current()
+	return l.entries(ctx, key, 0, int64(n)-1)
+}
+
+// Page returns page (starting at 1) of the leaderboard, with perPage
+// entries per page.
+func (l *Leaderboard) Page(ctx context.Context, page, perPage int) (*Page, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		return nil, errors.New("structures: leaderboard page: perPage must be positive")
+	}
+	key, _ := l.current()
+	total, err := l.client.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("structures: leaderboard page: %w", err)
+	}
+	start := int64(page-1) * int64(perPage)
+	entries, err := l.entries(ctx, key, start, start+int64(perPage)-1)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Entries: entries, Page: page, PerPage: perPage, Total: total}, nil
+}
+
+// Around returns member with up to n entries ranked above and below it, as
+// shown on a player's own leaderboard. It returns nil when member is not
+// ranked.
+func (l *Leaderboard) Around(ctx context.Context, member string, n int) ([]Entry, error) {
+	key, _ := l.current()
+	rank, err := l.rank(ctx, key, member)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("structures: leaderboard around: %w", err)
+	}
+	return l.entries(ctx, key, max(rank-int64(n), 0), rank+int64(n))
+}
+
+// rank returns the zero-based rank of member, or redis.Nil.
+func (l *Leaderboard) rank(ctx context.Context, key, member string) (int64, error) {
+	if l.ascending {
+		return l.client.ZRank(ctx, key, member).Result()
+	}
+	return l.client.ZRevRank(ctx, key, member).Result()
+}
+
+// entries returns the entries ranked from start to stop, zero-based and
+// inclusive.
+func (l *Leaderboard) entries(ctx context.Context, key string, start, stop int64) ([]Entry, error) {
+	zs, err := l.client.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+		Key:   key,
+		Start: start,
+		Stop:  stop,
+		Rev:   !l.ascending,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("structures: leaderboard range: %w", err)
+	}
+	entries := make([]Entry, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		entries[i] = Entry{Rank: start + int64(i) + 1, Member: member, Score: z.Score}
+	}
+	return entries, nil
+}
@@ -0,0 +1,127 @@
+// Package structures provides data structures that applications commonly
+// build on Redis: counters, leaderboards and unique counters. Each works
+// with any redis.UniversalClient, including *redis.Client from pkg/redis:
+//
+//	client, _ := manager.Default()
+//	views := structures.NewCounter(client, "page_views", structures.WithWindow(time.Hour))
+//	scores := structures.NewLeaderboard(client, "weekly", structures.WithWindow(7*24*time.Hour))
+//	visitors := structures.NewUniqueCounter(client, "visitors", structures.WithWindow(24*time.Hour))
+//
+// With WithWindow, every fixed window (an hour, a day, ...) gets its own
+// key, which expires once it falls out of the history. Keys of one
+// structure share a hash slot, so they can be read together on a cluster.
+package structures
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Option configures a Counter, Leaderboard or UniqueCounter.
+type Option func(*options)
+
+type options struct {
+	prefix    string
+	window    time.Duration
+	history   int
+	ascending bool
+}
+
+// WithPrefix prefixes every key, such as "app:".
+func WithPrefix(prefix string) Option {
+	return func(o *options) { o.prefix = prefix }
+}
+
+// WithWindow splits the structure into fixed windows of d, aligned to the
+// Unix epoch, so a 24h window starts at midnight UTC. Reads and writes use
+// the current window. Without it there is a single key that never expires.
+func WithWindow(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.window = d
+		}
+	}
+}
+
+// WithHistory keeps the last n windows, the current one included, before
+// they expire (default 2). It has no effect without WithWindow.
+func WithHistory(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.history = n
+		}
+	}
+}
+
+// WithAscending ranks a leaderboard from the lowest score, as for race
+// times. By default the highest score ranks first.
+func WithAscending() Option {
+	return func(o *options) { o.ascending = true }
+}
+
+// keyspace maps a structure's windows to Redis keys.
+type keyspace struct {
+	client redis.UniversalClient
+	base   string
+	options
+	now func() time.Time
+}
+
+func newKeyspace(client redis.UniversalClient, kind, name string, opts []Option) keyspace {
+	o := options{history: 2}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// The braces make every window of the structure hash to one slot.
+	return keyspace{client: client, base: o.prefix + kind + ":{" + name + "}", options: o, now: time.Now}
+}
+
+// start returns the start of the window containing t, or the zero time
+// without a window.
+func (k *keyspace) start(t time.Time) time.Time {
+	if k.window == 0 {
+		return time.Time{}
+	}
+	return t.Truncate(k.window)
+}
+
+// key returns the key of the window starting at start.
+func (k *keyspace) key(start time.Time) string {
+	if k.window == 0 {
+		return k.base
+	}
+	return k.base + ":" + strconv.FormatInt(start.Unix(), 10)
+}
+
+// current returns the key of the current window and when it expires, which
+// is zero without a window.
+func (k *keyspace) current() (string, time.Time) {
+	start := k.start(k.now())
+	if k.window == 0 {
+		return k.base, time.Time{}
+	}
+	return k.key(start), start.Add(time.Duration(k.history) * k.window)
+}
+
+// last returns the starts of the last n windows, newest first.
+func (k *keyspace) last(n int) []time.Time {
+	if k.window == 0 {
+		n = 1
+	}
+	starts := make([]time.Time, n)
+	start := k.start(k.now())
+	for i := range starts {
+		starts[i] = start.Add(-time.Duration(i) * k.window)
+	}
+	return starts
+}
+
+// expire adds an EXPIREAT for key to pipe when it has a window.
+func expire(ctx context.Context, pipe redis.Pipeliner, key string, at time.Time) {
+	if !at.IsZero() {
+		pipe.ExpireAt(ctx, key, at)
+	}
+}
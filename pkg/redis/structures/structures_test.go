@@ -0,0 +1,162 @@
+package structures
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return server, client
+}
+
+func TestCounter(t *testing.T) {
+	ctx := context.Background()
+	server, client := setup(t)
+	now := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+	server.SetTime(now)
+
+	c := NewCounter(client, "views", WithWindow(time.Hour), WithHistory(3), WithPrefix("app:"))
+	c.now = func() time.Time { return now }
+
+	for range 3 {
+		_, err := c.Incr(ctx)
+		require.NoError(t, err)
+	}
+	n, err := c.IncrBy(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	key := "app:counter:{views}:1792144800"
+	assert.Equal(t, "5", must(server.Get(key)))
+	// The window expires three windows after it started.
+	assert.Equal(t, 2*time.Hour+30*time.Minute, server.TTL(key))
+
+	now = now.Add(time.Hour)
+	_, err = c.Incr(ctx)
+	require.NoError(t, err)
+
+	got, err := c.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got)
+	history, err := c.History(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []Bucket{
+		{Start: time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC), Count: 1},
+		{Start: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), Count: 5},
+		{Start: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), Count: 0},
+	}, history)
+	sum, err := c.Sum(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), sum)
+
+	require.NoError(t, c.Reset(ctx))
+	got, err = c.Get(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, got)
+
+	// Without a window the counter has one key that does not expire.
+	total := NewCounter(client, "signups")
+	_, err = total.IncrBy(ctx, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "4", must(server.Get("counter:{signups}")))
+	assert.Zero(t, server.TTL("counter:{signups}"))
+}
+
+func TestLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	_, client := setup(t)
+	lb := NewLeaderboard(client, "game")
+
+	for member, score := range map[string]float64{"ada": 50, "bob": 80, "cy": 20, "dee": 70, "eve": 10} {
+		require.NoError(t, lb.Set(ctx, member, score))
+	}
+	score, err := lb.Incr(ctx, "cy", 40)
+	require.NoError(t, err)
+	assert.Equal(t, float64(60), score)
+
+	rank, ok, err := lb.Rank(ctx, "bob")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), rank)
+	_, ok, err = lb.Rank(ctx, "nobody")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	score, ok, err = lb.Score(ctx, "ada")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, float64(50), score)
+
+	page, err := lb.Page(ctx, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, &Page{
+		Entries: []Entry{{Rank: 3, Member: "cy", Score: 60}, {Rank: 4, Member: "ada", Score: 50}},
+		Page:    2,
+		PerPage: 2,
+		Total:   5,
+	}, page)
+
+	around, err := lb.Around(ctx, "bob", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Rank: 1, Member: "bob", Score: 80}, {Rank: 2, Member: "dee", Score: 70}}, around)
+
+	require.NoError(t, lb.Remove(ctx, "bob"))
+	top, err := lb.Top(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Rank: 1, Member: "dee", Score: 70}}, top)
+
+	// Lowest first, as for lap times.
+	laps := NewLeaderboard(client, "laps", WithAscending())
+	require.NoError(t, laps.Set(ctx, "ada", 61.2))
+	require.NoError(t, laps.Set(ctx, "bob", 59.8))
+	top, err = laps.Top(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Rank: 1, Member: "bob", Score: 59.8}, {Rank: 2, Member: "ada", Score: 61.2}}, top)
+	rank, _, err = laps.Rank(ctx, "ada")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rank)
+}
+
+func TestUniqueCounter(t *testing.T) {
+	ctx := context.Background()
+	_, client := setup(t)
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	visitors := NewUniqueCounter(client, "visitors", WithWindow(24*time.Hour))
+	visitors.now = func() time.Time { return now }
+
+	added, err := visitors.Add(ctx, "ada", "bob", "ada")
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = visitors.Add(ctx, "bob")
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	now = now.Add(24 * time.Hour)
+	// miniredis adds up the counts of several keys where Redis counts their
+	// union, so the two days have no visitor in common.
+	_, err = visitors.Add(ctx, "cy", "dee", "eve")
+	require.NoError(t, err)
+
+	today, err := visitors.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), today)
+	twoDays, err := visitors.CountLast(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), twoDays)
+}
+
+func must(s string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
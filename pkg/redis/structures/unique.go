@@ -0,0 +1,75 @@
+package structures
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// UniqueCounter counts distinct items, such as visitors, with a
+// HyperLogLog, optionally per window. It uses at most 12 KB per window
+// whatever the number of items, and counts with a standard error of 0.81%.
+type UniqueCounter struct {
+	keyspace
+}
+
+// NewUniqueCounter returns the unique counter stored under name.
+func NewUniqueCounter(client redis.UniversalClient, name string, opts ...Option) *UniqueCounter {
+	return &UniqueCounter{newKeyspace(client, "unique", name, opts)}
+}
+
+// Add adds items to the current window. It reports whether the estimate
+// changed, which usually means an item was new.
+func (u *UniqueCounter) Add(ctx context.Context, items ...string) (bool, error) {
+	if len(items) == 0 {
+		return false, nil
+	}
+	key, expireAt := u.current()
+	args := make([]any, len(items))
+	for i, item := range items {
+		args[i] = item
+	}
+	var add *redis.IntCmd
+	_, err := u.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		add = pipe.PFAdd(ctx, key, args...)
+		expire(ctx, pipe, key, expireAt)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("structures: unique add: %w", err)
+	}
+	return add.Val() == 1, nil
+}
+
+// Count returns the number of distinct items in the current window.
+func (u *UniqueCounter) Count(ctx context.Context) (int64, error) {
+	return u.CountLast(ctx, 1)
+}
+
+// CountLast returns the number of distinct items across the last n
+// windows, so an item seen in several of them counts once.
+func (u *UniqueCounter) CountLast(ctx context.Context, n int) (int64, error) {
+	if n <= 0 {
+		return 0, nil
+	}
+	starts := u.last(n)
+	keys := make([]string, len(starts))
+	for i, start := range starts {
+		keys[i] = u.key(start)
+	}
+	count, err := u.client.PFCount(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("structures: unique count: %w", err)
+	}
+	return count, nil
+}
+
+// Reset deletes the current window.
+func (u *UniqueCounter) Reset(ctx context.Context) error {
+	key, _ := u.current()
+	if err := u.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("structures: unique reset: %w", err)
+	}
+	return nil
+}
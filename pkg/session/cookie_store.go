@@ -65,6 +65,7 @@ func (s *CookieStore) Load(r *http.Request) (*Session, error) {
 		name:  s.opts.Name,
 		opts:  s.opts,
 	}
+	sess.describeClient(r)
 
 	cookie, err := r.Cookie(s.opts.Name)
 	if err != nil {
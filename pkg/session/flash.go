@@ -0,0 +1,118 @@
+package session
+
+import "strings"
+
+// Form flash data (old input and validation errors) is written under
+// flashNextKey and read from flashNowKey. AgeFlash moves it from one to the
+// other when the next request loads the session, so it survives exactly one
+// redirect and can be read any number of times during that request.
+const (
+	flashNextKey = "_flash_next"
+	flashNowKey  = "_flash_now"
+	inputField   = "input"
+	errorsField  = "errors"
+)
+
+// AgeFlash makes the form data flashed by the previous request readable
+// through Old and Errors, and drops the data of the request before it. The
+// session middleware calls it once per request, right after loading.
+func (s *Session) AgeFlash() {
+	_, hadNow := s.data[flashNowKey]
+	next, hasNext := s.data[flashNextKey]
+	if !hadNow && !hasNext {
+		return
+	}
+	delete(s.data, flashNowKey)
+	delete(s.data, flashNextKey)
+	if hasNext {
+		s.data[flashNowKey] = next
+	}
+	s.dirty = true
+}
+
+// FlashInput keeps the submitted form values for the next request, where
+// Old returns them. Fields whose name contains "password" are left out.
+func (s *Session) FlashInput(input map[string][]string) {
+	kept := make(map[string][]string, len(input))
+	for k, v := range input {
+		if !strings.Contains(strings.ToLower(k), "password") {
+			kept[k] = v
+		}
+	}
+	s.setNext(inputField, kept)
+}
+
+// FlashErrors keeps validation errors, keyed by field, for the next
+// request, where Errors returns them.
+func (s *Session) FlashErrors(errs map[string][]string) {
+	s.setNext(errorsField, errs)
+}
+
+// Old returns the value submitted for field on the request that redirected
+// here, or def (or "") when there is none.
+func (s *Session) Old(field string, def ...string) string {
+	if vals := s.flashed(inputField)[field]; len(vals) > 0 {
+		return vals[0]
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// OldInput returns all values flashed with FlashInput by the previous
+// request.
+func (s *Session) OldInput() map[string][]string {
+	return s.flashed(inputField)
+}
+
+// Errors returns the validation errors flashed by the previous request.
+func (s *Session) Errors() map[string][]string {
+	return s.flashed(errorsField)
+}
+
+// FieldError returns the first flashed error for field, or "".
+func (s *Session) FieldError(field string) string {
+	if msgs := s.flashed(errorsField)[field]; len(msgs) > 0 {
+		return msgs[0]
+	}
+	return ""
+}
+
+func (s *Session) setNext(field string, val map[string][]string) {
+	next, _ := s.data[flashNextKey].(map[string]any)
+	if next == nil {
+		next = make(map[string]any)
+	}
+	next[field] = val
+	s.Set(flashNextKey, next)
+}
+
+// flashed reads field from the aged flash data. Stores serialise sessions
+// as JSON, so after a round trip the maps come back as map[string]any.
+func (s *Session) flashed(field string) map[string][]string {
+	now, _ := s.data[flashNowKey].(map[string]any)
+	out := make(map[string][]string)
+	switch m := now[field].(type) {
+	case map[string][]string:
+		for k, v := range m {
+			out[k] = v
+		}
+	case map[string]any:
+		for k, v := range m {
+			switch v := v.(type) {
+			case []any:
+				for _, item := range v {
+					if str, ok := item.(string); ok {
+						out[k] = append(out[k], str)
+					}
+				}
+			case []string:
+				out[k] = v
+			case string:
+				out[k] = []string{v}
+			}
+		}
+	}
+	return out
+}
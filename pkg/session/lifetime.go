@@ -0,0 +1,115 @@
+package session
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Bookkeeping keys kept in the session data alongside application values.
+const (
+	userKey      = "_auth_user"
+	authAtKey    = "_auth_at"
+	createdAtKey = "_created_at"
+	lastSeenKey  = "_last_seen"
+)
+
+// maxTouchInterval bounds how stale the last-seen time of an active session
+// may get. Refreshing it on every request would rewrite the session each
+// time; refreshing it now and then keeps the idle check within this margin.
+const maxTouchInterval = time.Minute
+
+// Login marks the session as belonging to userID. Save then issues a new
+// session ID, so an ID planted before login (session fixation) is worthless
+// afterwards, and stores that keep a registry list the session under the
+// user. The lifetime restarts too, so the absolute timeout counts from
+// authentication rather than from when the anonymous session began.
+func (s *Session) Login(userID string) {
+	if current := s.UserID(); current != userID && s.prevUser == "" {
+		s.prevUser = current
+	}
+	now := time.Now().Unix()
+	s.data[userKey] = userID
+	s.data[authAtKey] = now
+	s.data[createdAtKey] = now
+	s.data[lastSeenKey] = now
+	s.regenerate = true
+	s.dirty = true
+}
+
+// Logout clears the session and makes Save issue a new session ID. The old
+// ID is destroyed in server-side stores.
+func (s *Session) Logout() {
+	if s.prevUser == "" {
+		s.prevUser = s.UserID()
+	}
+	s.data = make(map[string]any)
+	s.regenerate = true
+	s.dirty = true
+}
+
+// UserID returns the user the session was logged in for with Login, or "".
+func (s *Session) UserID() string {
+	id, _ := s.data[userKey].(string)
+	return id
+}
+
+// SetClientIP records the client address shown for the session in the
+// registry. Stores start from the connection's address; the session
+// middleware replaces it with the address resolved through trusted proxies.
+func (s *Session) SetClientIP(ip string) {
+	s.ip = ip
+}
+
+// CheckLifetime ends the session when it has been idle for longer than idle
+// or has existed for longer than absolute, logging the user out, and reports
+// whether it did. A zero duration disables that check. The session
+// middleware calls it once per request, right after loading.
+func (s *Session) CheckLifetime(idle, absolute time.Duration) bool {
+	if !s.loaded {
+		return false
+	}
+	now := time.Now()
+	created, seen := s.timeOf(createdAtKey), s.timeOf(lastSeenKey)
+	if created.IsZero() || seen.IsZero() {
+		// Saved before lifetimes were tracked; stamp it on this request.
+		s.dirty = true
+		return false
+	}
+	if (absolute > 0 && now.Sub(created) > absolute) || (idle > 0 && now.Sub(seen) > idle) {
+		s.Logout()
+		return true
+	}
+	if idle > 0 && now.Sub(seen) >= min(idle/10, maxTouchInterval) {
+		s.dirty = true
+	}
+	return false
+}
+
+// stamp records when the session started and when it was last saved.
+func (s *Session) stamp() {
+	now := time.Now().Unix()
+	if _, ok := s.data[createdAtKey]; !ok {
+		s.data[createdAtKey] = now
+	}
+	s.data[lastSeenKey] = now
+}
+
+// timeOf reads a Unix timestamp stored under key. Values read back from JSON
+// are float64.
+func (s *Session) timeOf(key string) time.Time {
+	var sec int64
+	switch v := s.data[key].(type) {
+	case int64:
+		sec = v
+	case int:
+		sec = int64(v)
+	case float64:
+		sec = int64(v)
+	case json.Number:
+		sec, _ = v.Int64()
+	}
+	if sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
@@ -2,13 +2,14 @@ package session
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 )
 
 // RedisStore is a server-side session store backed by Redis.
@@ -18,11 +19,16 @@ import (
 //
 // The session ID is stored in a plain (non-sensitive) HTTP cookie.
 // The cookie itself does NOT contain any session data.
+//
+// Sessions logged in with Session.Login are also listed per user, in a hash
+// under {userPrefix}:{userID}, which makes RedisStore a Registry. The two
+// prefixes differ, so no session ID can name a user's hash.
 type RedisStore struct {
-	client redis.UniversalClient
-	ttl    time.Duration
-	prefix string
-	opts   CookieOptions
+	client     redis.UniversalClient
+	ttl        time.Duration
+	prefix     string
+	userPrefix string
+	opts       CookieOptions
 }
 
 // NewRedisStore creates a RedisStore backed by the given Redis client.
@@ -36,15 +42,17 @@ func NewRedisStore(client redis.UniversalClient, ttl time.Duration, options ...f
 		o(&opts)
 	}
 	return &RedisStore{
-		client: client,
-		ttl:    ttl,
-		prefix: "astra:session:",
-		opts:   opts,
+		client:     client,
+		ttl:        ttl,
+		prefix:     "astra:session:",
+		userPrefix: "astra:session-user:",
+		opts:       opts,
 	}
 }
 
 // Load reads the session ID cookie and loads session data from Redis.
-// Returns an empty session with a fresh ID if the cookie is absent or Redis has no entry.
+// Returns an empty session with a fresh ID if the cookie is absent, does not
+// hold a well-formed session ID, or Redis has no entry.
 func (s *RedisStore) Load(r *http.Request) (*Session, error) {
 	sess := &Session{
 		data:  make(map[string]any),
@@ -52,9 +60,10 @@ func (s *RedisStore) Load(r *http.Request) (*Session, error) {
 		name:  s.opts.Name,
 		opts:  s.opts,
 	}
+	sess.describeClient(r)
 
 	cookie, err := r.Cookie(s.opts.Name)
-	if err != nil || cookie.Value == "" {
+	if err != nil || !validSessionID(cookie.Value) {
 		sess.id = newSessionID()
 		return sess, nil
 	}
@@ -96,7 +105,19 @@ func (s *RedisStore) Save(w http.ResponseWriter, sess *Session) error {
 
 	key := s.redisKey(sess.id)
 	ctx := context.Background()
-	if err := s.client.Set(ctx, key, payload, s.ttl).Err(); err != nil {
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, payload, s.ttl)
+		if user := sess.UserID(); user != "" {
+			device, err := json.Marshal(sess.device())
+			if err != nil {
+				return err
+			}
+			pipe.HSet(ctx, s.userKey(user), sess.id, device)
+			pipe.Expire(ctx, s.userKey(user), s.ttl)
+		}
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("session: RedisStore.Save redis: %w", err)
 	}
 
@@ -107,7 +128,7 @@ func (s *RedisStore) Save(w http.ResponseWriter, sess *Session) error {
 // Destroy deletes the session from Redis and clears the cookie.
 func (s *RedisStore) Destroy(w http.ResponseWriter, sess *Session) error {
 	if sess.id != "" {
-		_ = s.client.Del(context.Background(), s.redisKey(sess.id))
+		s.forget(context.Background(), sess, sess.id)
 	}
 	clearCookie(w, sess.name, sess.opts.Path)
 	return nil
@@ -120,27 +141,124 @@ func (s *RedisStore) Regenerate(w http.ResponseWriter, sess *Session) error {
 
 	// If we have an old ID, delete it from Redis
 	if oldID != "" {
-		ctx := context.Background()
-		_ = s.client.Del(ctx, s.redisKey(oldID))
+		s.forget(context.Background(), sess, oldID)
 	}
 
 	// Update session with new ID and mark dirty to ensure Save is called
 	sess.id = newID
+	sess.prevUser = ""
 	sess.dirty = true
 
 	// Force an immediate save to Redis and cookie update
 	return s.Save(w, sess)
 }
 
+// forget deletes the session stored under id and removes id from the
+// registry of the session's current and previous user.
+func (s *RedisStore) forget(ctx context.Context, sess *Session, id string) {
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, s.redisKey(id))
+	for _, user := range []string{sess.UserID(), sess.prevUser} {
+		if user != "" {
+			pipe.HDel(ctx, s.userKey(user), id)
+		}
+	}
+	_, _ = pipe.Exec(ctx)
+}
+
+// UserSessions lists the active sessions of a user, most recent first.
+// Entries whose session has expired are dropped from the registry.
+func (s *RedisStore) UserSessions(ctx context.Context, userID string) ([]Device, error) {
+	entries, err := s.client.HGetAll(ctx, s.userKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("session: RedisStore.UserSessions redis: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(entries))
+	exists := make([]*redis.IntCmd, 0, len(entries))
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for id := range entries {
+			ids = append(ids, id)
+			exists = append(exists, pipe.Exists(ctx, s.redisKey(id)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("session: RedisStore.UserSessions redis: %w", err)
+	}
+
+	var devices []Device
+	var stale []string
+	for i, id := range ids {
+		var device Device
+		if exists[i].Val() == 0 || json.Unmarshal([]byte(entries[id]), &device) != nil {
+			stale = append(stale, id)
+			continue
+		}
+		devices = append(devices, device)
+	}
+	if len(stale) > 0 {
+		_ = s.client.HDel(ctx, s.userKey(userID), stale...).Err()
+	}
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].LastSeen.After(devices[j].LastSeen) })
+	return devices, nil
+}
+
+// RevokeSession deletes one session of a user. The next request made with it
+// starts a new, anonymous session.
+func (s *RedisStore) RevokeSession(ctx context.Context, userID, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.redisKey(id))
+		pipe.HDel(ctx, s.userKey(userID), id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("session: RedisStore.RevokeSession redis: %w", err)
+	}
+	return nil
+}
+
+// RevokeOtherSessions deletes every session of a user except keepID.
+func (s *RedisStore) RevokeOtherSessions(ctx context.Context, userID, keepID string) error {
+	ids, err := s.client.HKeys(ctx, s.userKey(userID)).Result()
+	if err != nil {
+		return fmt.Errorf("session: RedisStore.RevokeOtherSessions redis: %w", err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			if id != keepID {
+				pipe.Del(ctx, s.redisKey(id))
+				pipe.HDel(ctx, s.userKey(userID), id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("session: RedisStore.RevokeOtherSessions redis: %w", err)
+	}
+	return nil
+}
+
 func (s *RedisStore) redisKey(id string) string {
 	return s.prefix + id
 }
 
-// newSessionID generates a cryptographically random 128-bit hex session ID.
+func (s *RedisStore) userKey(userID string) string {
+	return s.userPrefix + userID
+}
+
+// newSessionID generates a cryptographically random 256-bit session ID.
 func newSessionID() string {
-	b := make([]byte, 16)
-	if _, err := rand.Read(b); err != nil {
-		panic("session: failed to generate session ID: " + err.Error())
-	}
-	return hex.EncodeToString(b)
+	return secure.MustToken(secure.TokenBytes)
+}
+
+// validSessionID reports whether id has the form newSessionID returns, so a
+// cookie cannot point Load at an arbitrary Redis key.
+func validSessionID(id string) bool {
+	b, err := secure.DecodeURL(id)
+	return err == nil && len(b) == secure.TokenBytes
 }
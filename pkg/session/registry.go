@@ -0,0 +1,90 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ErrNoRegistry is returned by Devices and RevokeOthers when the session
+// store cannot list sessions per user, as with CookieStore, which keeps no
+// server-side state.
+var ErrNoRegistry = errors.New("session: store does not track sessions per user")
+
+// Device describes one logged-in session of a user.
+type Device struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	LoginAt   time.Time `json:"login_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Current is set by Session.Devices on the session making the request.
+	Current bool `json:"current"`
+}
+
+// Registry is implemented by stores that track the sessions of each user,
+// such as RedisStore.
+type Registry interface {
+	// UserSessions lists the active sessions of a user, most recent first.
+	UserSessions(ctx context.Context, userID string) ([]Device, error)
+	// RevokeSession ends one session of a user.
+	RevokeSession(ctx context.Context, userID, id string) error
+	// RevokeOtherSessions ends every session of a user except keepID.
+	RevokeOtherSessions(ctx context.Context, userID, keepID string) error
+}
+
+// Devices lists the active sessions of the logged-in user, marking this one
+// as Current. It returns nothing when nobody is logged in.
+func (s *Session) Devices(ctx context.Context) ([]Device, error) {
+	reg, ok := s.store.(Registry)
+	if !ok {
+		return nil, ErrNoRegistry
+	}
+	user := s.UserID()
+	if user == "" {
+		return nil, nil
+	}
+	devices, err := reg.UserSessions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	for i := range devices {
+		devices[i].Current = devices[i].ID == s.id
+	}
+	return devices, nil
+}
+
+// RevokeOthers ends every other session of the logged-in user, for example
+// after a password change or from a "sign out other devices" button.
+func (s *Session) RevokeOthers(ctx context.Context) error {
+	reg, ok := s.store.(Registry)
+	if !ok {
+		return ErrNoRegistry
+	}
+	if user := s.UserID(); user != "" {
+		return reg.RevokeOtherSessions(ctx, user, s.id)
+	}
+	return nil
+}
+
+// device returns the registry entry for the session as it is saved.
+func (s *Session) device() Device {
+	return Device{
+		ID:        s.id,
+		UserAgent: s.agent,
+		IP:        s.ip,
+		LoginAt:   s.timeOf(authAtKey),
+		LastSeen:  s.timeOf(lastSeenKey),
+	}
+}
+
+// describeClient records the client of r on a newly loaded session.
+func (s *Session) describeClient(r *http.Request) {
+	s.agent = r.UserAgent()
+	s.ip = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		s.ip = host
+	}
+}
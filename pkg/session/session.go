@@ -22,6 +22,15 @@ type Session struct {
 	opts   CookieOptions
 	loaded bool
 	dirty  bool
+
+	// regenerate makes Save issue a new ID, set by Login and Logout.
+	regenerate bool
+	// prevUser is the user the session belonged to before Login or Logout
+	// changed it, so stores can drop the old ID from that user's registry.
+	prevUser string
+	// agent and ip describe the client for the session registry.
+	agent string
+	ip    string
 }
 
 // CookieOptions controls the session cookie attributes.
@@ -111,10 +120,17 @@ func (s *Session) ID() string { return s.id }
 // Save persists the session to the response. Must be called before the
 // response body is written. For server-side stores this writes the ID cookie.
 // For CookieStore this writes the encrypted data cookie.
+//
+// After Login or Logout, Save issues a new session ID instead.
 func (s *Session) Save(w http.ResponseWriter) error {
 	if !s.dirty {
 		return nil
 	}
+	s.stamp()
+	if s.regenerate {
+		s.regenerate = false
+		return s.store.Regenerate(w, s)
+	}
 	return s.store.Save(w, s)
 }
 
@@ -135,6 +151,8 @@ type Store interface {
 // Regenerate issues a new session ID for the current session while preserving
 // its data. This should be called after login or privilege escalation.
 func (s *Session) Regenerate(w http.ResponseWriter) error {
+	s.stamp()
+	s.regenerate = false
 	return s.store.Regenerate(w, s)
 }
 
@@ -0,0 +1,182 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip loads the session sent with cookie, lets fn change it, saves it
+// and returns the session and the cookie the response set.
+func roundTrip(t *testing.T, store Store, cookie *http.Cookie, agent string, fn func(*Session)) (*Session, *http.Cookie) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", agent)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	sess, err := store.Load(req)
+	require.NoError(t, err)
+	fn(sess)
+	rec := httptest.NewRecorder()
+	require.NoError(t, sess.Save(rec))
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == defaultCookieName {
+			return sess, c
+		}
+	}
+	return sess, cookie
+}
+
+func TestRedisStore_LoginRegistry(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	store := NewRedisStore(client, time.Hour)
+	ctx := context.Background()
+
+	// An anonymous session gets an ID; logging in replaces it.
+	_, anon := roundTrip(t, store, nil, "Firefox", func(s *Session) { s.Set("cart", "3 items") })
+	laptop, laptopCookie := roundTrip(t, store, anon, "Firefox", func(s *Session) { s.Login("42") })
+	assert.NotEqual(t, anon.Value, laptopCookie.Value)
+	assert.False(t, mr.Exists("astra:session:"+anon.Value), "the pre-login ID is destroyed")
+	assert.Equal(t, "3 items", laptop.GetString("cart"), "data survives login")
+
+	_, phoneCookie := roundTrip(t, store, nil, "Safari", func(s *Session) { s.Login("42") })
+	_, _ = roundTrip(t, store, nil, "Curl", func(s *Session) { s.Login("7") })
+
+	current, _ := roundTrip(t, store, laptopCookie, "Firefox", func(*Session) {})
+	devices, err := current.Devices(ctx)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	byAgent := map[string]Device{}
+	for _, d := range devices {
+		byAgent[d.UserAgent] = d
+	}
+	assert.True(t, byAgent["Firefox"].Current)
+	assert.False(t, byAgent["Safari"].Current)
+	assert.Equal(t, "192.0.2.1", byAgent["Safari"].IP)
+	assert.False(t, byAgent["Safari"].LoginAt.IsZero())
+
+	// Sessions that expired in Redis drop out of the list.
+	mr.Del("astra:session:" + phoneCookie.Value)
+	devices, err = current.Devices(ctx)
+	require.NoError(t, err)
+	assert.Len(t, devices, 1)
+
+	_, phoneCookie = roundTrip(t, store, nil, "Safari", func(s *Session) { s.Login("42") })
+	require.NoError(t, current.RevokeOthers(ctx))
+	assert.False(t, mr.Exists("astra:session:"+phoneCookie.Value))
+	devices, err = store.UserSessions(ctx, "42")
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, laptopCookie.Value, devices[0].ID)
+
+	// A revoked session comes back empty.
+	phone, _ := roundTrip(t, store, phoneCookie, "Safari", func(*Session) {})
+	assert.Empty(t, phone.UserID())
+
+	// Logging out destroys the session and removes it from the registry.
+	out, outCookie := roundTrip(t, store, laptopCookie, "Firefox", func(s *Session) { s.Logout() })
+	assert.NotEqual(t, laptopCookie.Value, outCookie.Value)
+	assert.Empty(t, out.UserID())
+	assert.False(t, mr.Exists("astra:session:"+laptopCookie.Value))
+	devices, err = store.UserSessions(ctx, "42")
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+
+	others, err := store.UserSessions(ctx, "7")
+	require.NoError(t, err)
+	assert.Len(t, others, 1)
+}
+
+func TestRedisStore_RejectsMalformedIDs(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	store := NewRedisStore(client, time.Hour)
+
+	_, _ = roundTrip(t, store, nil, "Firefox", func(s *Session) { s.Login("42") })
+	assert.True(t, mr.Exists("astra:session-user:42"), "the user index has its own prefix")
+
+	for _, id := range []string{"user:42", "../42", "short"} {
+		sess, _ := roundTrip(t, store, &http.Cookie{Name: defaultCookieName, Value: id}, "Curl", func(*Session) {})
+		assert.True(t, validSessionID(sess.ID()), id)
+		assert.Empty(t, sess.UserID(), id)
+	}
+}
+
+func TestSession_CheckLifetime(t *testing.T) {
+	store := NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+
+	// saveAged stores a logged-in session that started and was last seen
+	// the given time ago.
+	saveAged := func(started, seen time.Duration) *http.Cookie {
+		sess, err := store.Load(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		sess.Login("42")
+		sess.data[createdAtKey] = time.Now().Add(-started).Unix()
+		sess.data[lastSeenKey] = time.Now().Add(-seen).Unix()
+		rec := httptest.NewRecorder()
+		require.NoError(t, store.Save(rec, sess))
+		return rec.Result().Cookies()[0]
+	}
+	load := func(cookie *http.Cookie) *Session {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(cookie)
+		sess, err := store.Load(req)
+		require.NoError(t, err)
+		return sess
+	}
+
+	fresh := load(saveAged(time.Hour, 10*time.Second))
+	assert.False(t, fresh.CheckLifetime(30*time.Minute, 8*time.Hour))
+	assert.Equal(t, "42", fresh.UserID())
+	assert.False(t, fresh.dirty, "a recently seen session is not rewritten")
+
+	touched := load(saveAged(time.Hour, 2*time.Minute))
+	assert.False(t, touched.CheckLifetime(30*time.Minute, 8*time.Hour))
+	assert.True(t, touched.dirty, "the last-seen time is refreshed now and then")
+
+	idle := load(saveAged(time.Hour, 31*time.Minute))
+	assert.True(t, idle.CheckLifetime(30*time.Minute, 8*time.Hour))
+	assert.Empty(t, idle.UserID())
+	assert.True(t, idle.regenerate)
+
+	old := load(saveAged(9*time.Hour, time.Second))
+	assert.True(t, old.CheckLifetime(30*time.Minute, 8*time.Hour))
+	assert.Empty(t, old.UserID())
+
+	assert.False(t, load(saveAged(9*time.Hour, time.Hour)).CheckLifetime(0, 0), "zero disables both checks")
+}
+
+func TestSession_LoginRestartsLifetime(t *testing.T) {
+	store := NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+
+	// An anonymous session that began long ago, but is still in use.
+	sess, err := store.Load(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	sess.Set("cart", "3 items")
+	sess.data[createdAtKey] = time.Now().Add(-9 * time.Hour).Unix()
+	sess.data[lastSeenKey] = time.Now().Add(-time.Minute).Unix()
+
+	sess.Login("42")
+	rec := httptest.NewRecorder()
+	require.NoError(t, store.Save(rec, sess))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(rec.Result().Cookies()[0])
+	loaded, err := store.Load(req)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), loaded.timeOf(createdAtKey), 2*time.Second)
+	assert.False(t, loaded.CheckLifetime(30*time.Minute, 8*time.Hour), "the absolute timeout counts from login")
+	assert.Equal(t, "42", loaded.UserID())
+	assert.Equal(t, "3 items", loaded.GetString("cart"))
+}
@@ -0,0 +1,307 @@
+// Package settings stores runtime-tweakable configuration, such as the site
+// name or an upload limit, in a database table. Unlike the env-based
+// configuration in pkg/engine/config, a setting can be changed while the
+// application runs, from an admin screen or with `astra settings:set`,
+// without a redeploy.
+//
+//	store := settings.New(db, settings.WithCache(redisCache, time.Minute))
+//	_ = store.Migrate(ctx)
+//	_ = store.Set(ctx, "site.name", "Acme")
+//	name := store.String(ctx, "site.name", "Astra App")
+//	limit, err := settings.Get(ctx, store, "uploads.max_mb", 10)
+package settings
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/cache"
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+)
+
+// DefaultTable is the table settings are stored in.
+const DefaultTable = "settings"
+
+// cachePrefix namespaces the cache keys of the store.
+const cachePrefix = "settings:"
+
+// ChangedEvent is emitted after a setting is set or deleted. Old and New are
+// the JSON-encoded values; New is empty after Delete and Old is empty when
+// the key was not set before.
+type ChangedEvent struct {
+	Key string
+	Old string
+	New string
+}
+
+func (e ChangedEvent) Name() string { return "settings.changed" }
+func (e ChangedEvent) Data() any    { return e }
+
+// Store reads and writes settings. Values are kept as JSON, so any value
+// encoding/json handles can be stored. Reads go through the cache; writes
+// update the table and evict the key.
+type Store struct {
+	db      *database.DB
+	table   string
+	cache   cache.Store
+	ttl     time.Duration
+	emitter *event.Emitter
+	logger  *slog.Logger
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithCache caches values in store for ttl (default: an in-process memory
+// store for one minute). With several instances, use a shared store such as
+// Redis, or another instance may serve a changed value for up to ttl.
+func WithCache(store cache.Store, ttl time.Duration) Option {
+	return func(s *Store) { s.cache, s.ttl = store, ttl }
+}
+
+// WithTable stores settings in table instead of DefaultTable.
+func WithTable(table string) Option {
+	return func(s *Store) { s.table = table }
+}
+
+// WithEmitter emits ChangedEvent on emitter instead of event.DefaultEmitter.
+func WithEmitter(emitter *event.Emitter) Option {
+	return func(s *Store) { s.emitter = emitter }
+}
+
+// WithLogger logs read failures that the typed accessors swallow.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Store) { s.logger = logger }
+}
+
+// New creates a Store on db.
+func New(db *database.DB, opts ...Option) *Store {
+	s := &Store{
+		db:      db,
+		table:   DefaultTable,
+		cache:   cache.NewMemoryStore(),
+		ttl:     time.Minute,
+		emitter: event.DefaultEmitter,
+		logger:  slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Migrate creates the settings table if it does not exist.
+func (s *Store) Migrate(ctx context.Context) error {
+	d := s.db.Dialect()
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		%s VARCHAR(191) NOT NULL PRIMARY KEY,
+		%s TEXT NOT NULL,
+		%s TIMESTAMP NOT NULL
+	)`, d.QuoteIdentifier(s.table), d.QuoteIdentifier("key"), d.QuoteIdentifier("value"), d.QuoteIdentifier("updated_at"))
+	if _, err := s.db.Exec(ctx, query); err != nil {
+		return fmt.Errorf("settings: failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Raw returns the JSON-encoded value of key and whether it is set.
+func (s *Store) Raw(ctx context.Context, key string) (string, bool, error) {
+	if s.cache != nil {
+		if cached, err := s.cache.Get(ctx, cachePrefix+key); err == nil {
+			// "1" + value for a set key, "0" for a known miss.
+			return cached[1:], cached[0] == '1', nil
+		}
+	}
+
+	value, found, err := s.load(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	if s.cache != nil {
+		entry := "0"
+		if found {
+			entry = "1" + value
+		}
+		_ = s.cache.Set(ctx, cachePrefix+key, entry, s.ttl)
+	}
+	return value, found, nil
+}
+
+func (s *Store) load(ctx context.Context, key string) (string, bool, error) {
+	d := s.db.Dialect()
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s",
+		d.QuoteIdentifier("value"), d.QuoteIdentifier(s.table), d.QuoteIdentifier("key"), d.Placeholder(1))
+	var value string
+	err := s.db.QueryRow(ctx, query, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("settings: failed to read %q: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Get decodes the value of key into T, returning def when the key is not
+// set. Durations may be stored as strings such as "90s".
+func Get[T any](ctx context.Context, s *Store, key string, def T) (T, error) {
+	raw, found, err := s.Raw(ctx, key)
+	if err != nil || !found {
+		return def, err
+	}
+	var v T
+	if err := decode(raw, &v); err != nil {
+		return def, fmt.Errorf("settings: %q is not a %T: %w", key, v, err)
+	}
+	return v, nil
+}
+
+func decode(raw string, dst any) error {
+	if d, ok := dst.(*time.Duration); ok {
+		var text string
+		if json.Unmarshal([]byte(raw), &text) == nil {
+			parsed, err := time.ParseDuration(text)
+			if err != nil {
+				return err
+			}
+			*d = parsed
+			return nil
+		}
+	}
+	return json.Unmarshal([]byte(raw), dst)
+}
+
+// String returns key as a string, or def when it is not set or unreadable.
+func (s *Store) String(ctx context.Context, key, def string) string {
+	return lookup(ctx, s, key, def)
+}
+
+// Int returns key as an int, or def when it is not set or unreadable.
+func (s *Store) Int(ctx context.Context, key string, def int) int {
+	return lookup(ctx, s, key, def)
+}
+
+// Bool returns key as a bool, or def when it is not set or unreadable.
+func (s *Store) Bool(ctx context.Context, key string, def bool) bool {
+	return lookup(ctx, s, key, def)
+}
+
+// Float returns key as a float64, or def when it is not set or unreadable.
+func (s *Store) Float(ctx context.Context, key string, def float64) float64 {
+	return lookup(ctx, s, key, def)
+}
+
+// Duration returns key as a duration, or def when it is not set or
+// unreadable.
+func (s *Store) Duration(ctx context.Context, key string, def time.Duration) time.Duration {
+	return lookup(ctx, s, key, def)
+}
+
+// lookup is Get for the typed accessors: failures are logged, not returned,
+// so a broken setting falls back to its default instead of failing a request.
+func lookup[T any](ctx context.Context, s *Store, key string, def T) T {
+	v, err := Get(ctx, s, key, def)
+	if err != nil {
+		s.logger.Warn("settings: using default", "key", key, "error", err)
+	}
+	return v
+}
+
+// Set stores value under key and emits ChangedEvent.
+func (s *Store) Set(ctx context.Context, key string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("settings: failed to encode %q: %w", key, err)
+	}
+	return s.SetRaw(ctx, key, string(encoded))
+}
+
+// SetRaw stores an already JSON-encoded value under key.
+func (s *Store) SetRaw(ctx context.Context, key, raw string) error {
+	if key == "" {
+		return errors.New("settings: key must not be empty")
+	}
+	if !json.Valid([]byte(raw)) {
+		return fmt.Errorf("settings: value for %q is not valid JSON", key)
+	}
+	old, _, err := s.load(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	d := s.db.Dialect()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (%s, %s, %s)%s",
+		d.QuoteIdentifier(s.table),
+		d.QuoteIdentifier("key"), d.QuoteIdentifier("value"), d.QuoteIdentifier("updated_at"),
+		d.Placeholder(1), d.Placeholder(2), d.Placeholder(3),
+		d.UpsertSQL([]string{"key"}, []string{"value", "updated_at"}))
+	if _, err := s.db.Exec(ctx, query, key, raw, time.Now().UTC()); err != nil {
+		return fmt.Errorf("settings: failed to write %q: %w", key, err)
+	}
+	s.changed(ctx, key, old, raw)
+	return nil
+}
+
+// Delete removes key, so reads return their defaults again.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	old, found, err := s.load(ctx, key)
+	if err != nil || !found {
+		return err
+	}
+	d := s.db.Dialect()
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = %s",
+		d.QuoteIdentifier(s.table), d.QuoteIdentifier("key"), d.Placeholder(1))
+	if _, err := s.db.Exec(ctx, query, key); err != nil {
+		return fmt.Errorf("settings: failed to delete %q: %w", key, err)
+	}
+	s.changed(ctx, key, old, "")
+	return nil
+}
+
+func (s *Store) changed(ctx context.Context, key, old, value string) {
+	if s.cache != nil {
+		_ = s.cache.Delete(ctx, cachePrefix+key)
+	}
+	if s.emitter != nil && old != value {
+		s.emitter.Emit(ctx, ChangedEvent{Key: key, Old: old, New: value})
+	}
+}
+
+// All returns every setting as JSON-encoded values, read from the table.
+func (s *Store) All(ctx context.Context) (map[string]string, error) {
+	d := s.db.Dialect()
+	query := fmt.Sprintf("SELECT %s, %s FROM %s",
+		d.QuoteIdentifier("key"), d.QuoteIdentifier("value"), d.QuoteIdentifier(s.table))
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("settings: failed to list: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("settings: failed to list: %w", err)
+		}
+		out[key] = value
+	}
+	return out, rows.Err()
+}
+
+// ParseValue turns command-line input into JSON: valid JSON such as 20,
+// true or "x" is kept, anything else is stored as a string.
+func ParseValue(input string) string {
+	if trimmed := strings.TrimSpace(input); trimmed != "" && json.Valid([]byte(trimmed)) {
+		return trimmed
+	}
+	encoded, _ := json.Marshal(input)
+	return string(encoded)
+}
@@ -0,0 +1,104 @@
+package settings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/database"
+	"github.com/shauryagautam/Astra/pkg/engine/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, *database.DB) {
+	t.Helper()
+	db, err := database.Open(database.Config{Driver: "sqlite", DSN: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store := New(db, opts...)
+	require.NoError(t, store.Migrate(context.Background()))
+	return store, db
+}
+
+func TestStore_TypedValues(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	assert.Equal(t, "Astra App", store.String(ctx, "site.name", "Astra App"))
+
+	require.NoError(t, store.Set(ctx, "site.name", "Acme"))
+	require.NoError(t, store.Set(ctx, "uploads.max_mb", 20))
+	require.NoError(t, store.Set(ctx, "signups.open", false))
+	require.NoError(t, store.Set(ctx, "tax.rate", 0.2))
+	require.NoError(t, store.Set(ctx, "session.idle", "90s"))
+	require.NoError(t, store.Set(ctx, "home.featured", []int{3, 1, 2}))
+
+	assert.Equal(t, "Acme", store.String(ctx, "site.name", ""))
+	assert.Equal(t, 20, store.Int(ctx, "uploads.max_mb", 10))
+	assert.False(t, store.Bool(ctx, "signups.open", true))
+	assert.InDelta(t, 0.2, store.Float(ctx, "tax.rate", 0), 1e-9)
+	assert.Equal(t, 90*time.Second, store.Duration(ctx, "session.idle", 0))
+
+	featured, err := Get(ctx, store, "home.featured", []int(nil))
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 1, 2}, featured)
+
+	// A value of the wrong type falls back to the default.
+	_, err = Get(ctx, store, "site.name", 0)
+	assert.Error(t, err)
+	assert.Equal(t, 7, store.Int(ctx, "site.name", 7))
+
+	all, err := store.All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `"Acme"`, all["site.name"])
+	assert.Len(t, all, 6)
+}
+
+func TestStore_CacheAndEvents(t *testing.T) {
+	ctx := context.Background()
+	emitter := event.New()
+	var changes []ChangedEvent
+	emitter.OnFunc("settings.changed", func(_ context.Context, e event.Event) error {
+		changes = append(changes, e.(ChangedEvent))
+		return nil
+	})
+	store, db := newTestStore(t, WithEmitter(emitter))
+
+	require.NoError(t, store.Set(ctx, "site.name", "Acme"))
+	assert.Equal(t, "Acme", store.String(ctx, "site.name", ""))
+
+	// Reads are cached: a write behind the store's back is not seen...
+	_, err := db.Exec(ctx, "UPDATE settings SET value = ? WHERE `key` = ?", `"Elsewhere"`, "site.name")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", store.String(ctx, "site.name", ""))
+
+	// ...but writes through the store evict the entry.
+	require.NoError(t, store.Set(ctx, "site.name", "Acme Inc"))
+	assert.Equal(t, "Acme Inc", store.String(ctx, "site.name", ""))
+
+	// Known misses are cached too, and evicted by the first write.
+	assert.Equal(t, 5, store.Int(ctx, "limits.teams", 5))
+	require.NoError(t, store.Set(ctx, "limits.teams", 8))
+	assert.Equal(t, 8, store.Int(ctx, "limits.teams", 5))
+
+	require.NoError(t, store.Delete(ctx, "limits.teams"))
+	assert.Equal(t, 5, store.Int(ctx, "limits.teams", 5))
+
+	assert.Equal(t, []ChangedEvent{
+		{Key: "site.name", New: `"Acme"`},
+		{Key: "site.name", Old: `"Elsewhere"`, New: `"Acme Inc"`},
+		{Key: "limits.teams", New: `8`},
+		{Key: "limits.teams", Old: `8`},
+	}, changes)
+}
+
+func TestParseValue(t *testing.T) {
+	assert.Equal(t, `20`, ParseValue("20"))
+	assert.Equal(t, `true`, ParseValue("true"))
+	assert.Equal(t, `"quoted"`, ParseValue(`"quoted"`))
+	assert.Equal(t, `{"a":1}`, ParseValue(`{"a":1}`))
+	assert.Equal(t, `"Acme Inc"`, ParseValue("Acme Inc"))
+	assert.Equal(t, `""`, ParseValue(""))
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrReadOnly is returned by writes to a read-only disk.
+var ErrReadOnly = errors.New("storage: disk is read-only")
+
+// FSStorage is a read-only disk over an fs.FS, typically public assets
+// embedded in the binary with go:embed. Writes fail with ErrReadOnly.
+type FSStorage struct {
+	fsys      fs.FS
+	urlPrefix string
+}
+
+// NewFSStorage creates a read-only disk. urlPrefix is where the files are
+// served, e.g. "/public" when mounted with Router.Static.
+func NewFSStorage(fsys fs.FS, urlPrefix string) *FSStorage {
+	return &FSStorage{fsys: fsys, urlPrefix: strings.TrimSuffix(urlPrefix, "/")}
+}
+
+func (s *FSStorage) name(p string) (string, error) {
+	name := path.Clean(strings.TrimPrefix(p, "/"))
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("path traversal attempt: %s", p)
+	}
+	return name, nil
+}
+
+// Open opens a file for streaming.
+func (s *FSStorage) Open(ctx context.Context, p string) (io.ReadCloser, error) {
+	name, err := s.name(p)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return f, nil
+}
+
+// Get reads a file.
+func (s *FSStorage) Get(ctx context.Context, p string) ([]byte, error) {
+	name, err := s.name(p)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
+}
+
+// Exists checks if a file exists.
+func (s *FSStorage) Exists(ctx context.Context, p string) (bool, error) {
+	name, err := s.name(p)
+	if err != nil {
+		return false, err
+	}
+	_, err = fs.Stat(s.fsys, name)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check file existence: %w", err)
+}
+
+// URL returns the public URL of the file.
+func (s *FSStorage) URL(p string) (string, error) {
+	return s.urlPrefix + "/" + strings.TrimPrefix(p, "/"), nil
+}
+
+// SignedURL returns the public URL; embedded files are not access controlled.
+func (s *FSStorage) SignedURL(ctx context.Context, p string, expiresIn time.Duration) (string, error) {
+	return s.URL(p)
+}
+
+// Put always fails with ErrReadOnly.
+func (s *FSStorage) Put(ctx context.Context, p string, content []byte) error { return ErrReadOnly }
+
+// Delete always fails with ErrReadOnly.
+func (s *FSStorage) Delete(ctx context.Context, p string) error { return ErrReadOnly }
+
+// Copy always fails with ErrReadOnly.
+func (s *FSStorage) Copy(ctx context.Context, src, dest string) error { return ErrReadOnly }
+
+// Move always fails with ErrReadOnly.
+func (s *FSStorage) Move(ctx context.Context, src, dest string) error { return ErrReadOnly }
@@ -37,6 +37,37 @@ func (s *LocalStorage) Put(ctx context.Context, path string, content []byte) err
 	return nil
 }
 
+// PutStream writes a file to the local filesystem as it is read from r. The
+// file is written under a temporary name and renamed once complete, so a
+// failed write leaves no partial file at path.
+func (s *LocalStorage) PutStream(ctx context.Context, path string, r io.Reader) error {
+	fullPath, err := s.securePath(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".put-*")
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
 func (s *LocalStorage) securePath(path string) (string, error) {
 	path = filepath.Clean(path)
 	fullPath := filepath.Join(s.rootDir, path)
@@ -71,6 +102,19 @@ func (s *LocalStorage) Get(ctx context.Context, path string) ([]byte, error) {
 	return data, nil
 }
 
+// Open opens a file on the local filesystem for streaming.
+func (s *LocalStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	fullPath, err := s.securePath(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(fullPath) // #nosec G304 -- path validated by securePath
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, nil
+}
+
 // Delete removes a file from the local filesystem.
 func (s *LocalStorage) Delete(ctx context.Context, path string) error {
 	fullPath, err := s.securePath(path)
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 )
@@ -37,6 +39,14 @@ func (s *MemoryStorage) Get(ctx context.Context, path string) ([]byte, error) {
 	return content, nil
 }
 
+func (s *MemoryStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	content, err := s.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
 func (s *MemoryStorage) Delete(ctx context.Context, path string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
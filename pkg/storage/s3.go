@@ -75,6 +75,74 @@ func (s *S3Storage) Put(ctx context.Context, path string, content []byte) error
 	})
 }
 
+// s3PartSize is the size of the parts PutStream uploads; S3 needs at least
+// 5MB for all but the last.
+const s3PartSize = 8 << 20
+
+// PutStream uploads a file to S3 as it is read from r, holding one part in
+// memory at a time. A file smaller than one part is uploaded with Put.
+func (s *S3Storage) PutStream(ctx context.Context, path string, r io.Reader) error {
+	part := make([]byte, s3PartSize)
+	n, err := io.ReadFull(r, part)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return s.Put(ctx, path, part[:n])
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.cb.Execute(ctx, func() error {
+		up, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      aws.String(s.config.S3Bucket),
+			Key:         aws.String(path),
+			ContentType: aws.String("application/octet-stream"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload to s3: %w", err)
+		}
+		abort := func(err error) error {
+			_, _ = s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(s.config.S3Bucket),
+				Key:      aws.String(path),
+				UploadId: up.UploadId,
+			})
+			return fmt.Errorf("failed to upload to s3: %w", err)
+		}
+
+		var parts []types.CompletedPart
+		for num := int32(1); n > 0; num++ {
+			out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:     aws.String(s.config.S3Bucket),
+				Key:        aws.String(path),
+				UploadId:   up.UploadId,
+				PartNumber: aws.Int32(num),
+				Body:       bytes.NewReader(part[:n]),
+			})
+			if err != nil {
+				return abort(err)
+			}
+			parts = append(parts, types.CompletedPart{ETag: out.ETag, PartNumber: aws.Int32(num)})
+
+			var rerr error
+			n, rerr = io.ReadFull(r, part)
+			if rerr != nil && !errors.Is(rerr, io.EOF) && !errors.Is(rerr, io.ErrUnexpectedEOF) {
+				return abort(rerr)
+			}
+		}
+
+		_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(s.config.S3Bucket),
+			Key:             aws.String(path),
+			UploadId:        up.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		if err != nil {
+			return abort(err)
+		}
+		return nil
+	})
+}
+
 // Get reads a file from S3.
 func (s *S3Storage) Get(ctx context.Context, path string) ([]byte, error) {
 	var data []byte
@@ -95,6 +163,23 @@ func (s *S3Storage) Get(ctx context.Context, path string) ([]byte, error) {
 	return data, err
 }
 
+// Open streams a file from S3. The caller must close the returned body.
+func (s *S3Storage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := s.cb.Execute(ctx, func() error {
+		out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(s.config.S3Bucket),
+			Key:    aws.String(path),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to download from s3: %w", err)
+		}
+		body = out.Body
+		return nil
+	})
+	return body, err
+}
+
 // Delete removes a file from S3.
 func (s *S3Storage) Delete(ctx context.Context, path string) error {
 	return s.cb.Execute(ctx, func() error {
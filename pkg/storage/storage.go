@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
 	"time"
 )
@@ -18,6 +20,44 @@ type Storage interface {
 	Move(ctx context.Context, src, dest string) error
 }
 
+// Opener is implemented by disks that can stream a file instead of loading
+// it into memory with Get.
+type Opener interface {
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// Open streams path from disk. Disks that do not implement Opener are read
+// with Get.
+func Open(ctx context.Context, disk Storage, path string) (io.ReadCloser, error) {
+	if o, ok := disk.(Opener); ok {
+		return o.Open(ctx, path)
+	}
+	content, err := disk.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// StreamPutter is implemented by disks that can store a file as it is read,
+// instead of from memory with Put.
+type StreamPutter interface {
+	PutStream(ctx context.Context, path string, r io.Reader) error
+}
+
+// PutStream stores what r yields at path. Disks that do not implement
+// StreamPutter are given it with Put, after it is read into memory.
+func PutStream(ctx context.Context, disk Storage, path string, r io.Reader) error {
+	if p, ok := disk.(StreamPutter); ok {
+		return p.PutStream(ctx, path, r)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return disk.Put(ctx, path, content)
+}
+
 // DetectMIME detects the MIME type of a byte slice.
 func DetectMIME(content []byte) string {
 	if len(content) == 0 {
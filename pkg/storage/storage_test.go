@@ -2,8 +2,14 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +36,21 @@ func TestLocalStorage(t *testing.T) {
 		assert.Equal(t, content, got)
 	})
 
+	t.Run("PutStream", func(t *testing.T) {
+		require.NoError(t, PutStream(ctx, s, "streamed/big.bin", strings.NewReader("streamed content")))
+		got, err := s.Get(ctx, "streamed/big.bin")
+		require.NoError(t, err)
+		assert.Equal(t, "streamed content", string(got))
+
+		// A failed stream leaves nothing behind.
+		err = PutStream(ctx, s, "streamed/broken.bin", io.MultiReader(strings.NewReader("part"), iotest.ErrReader(errors.New("boom"))))
+		require.Error(t, err)
+		entries, err := os.ReadDir(filepath.Join(tempDir, "streamed"))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "big.bin", entries[0].Name())
+	})
+
 	t.Run("Exists", func(t *testing.T) {
 		path := "exists.txt"
 		s.Put(ctx, path, []byte("data"))
@@ -113,3 +134,23 @@ func (m *MockStorage) Exists(ctx context.Context, path string) (bool, error) {
 	_, ok := m.Files[path]
 	return ok, nil
 }
+
+func TestFSStorage(t *testing.T) {
+	ctx := context.Background()
+	disk := NewFSStorage(fstest.MapFS{"img/logo.png": {Data: []byte("png")}}, "/public/")
+
+	data, err := disk.Get(ctx, "img/logo.png")
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+
+	ok, err := disk.Exists(ctx, "img/missing.png")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = disk.Get(ctx, "../secret")
+	assert.Error(t, err)
+
+	url, _ := disk.URL("img/logo.png")
+	assert.Equal(t, "/public/img/logo.png", url)
+	assert.ErrorIs(t, disk.Put(ctx, "x", nil), ErrReadOnly)
+}
@@ -0,0 +1,171 @@
+// Package collection provides a generic slice type with the chainable
+// helpers handlers and services reach for when shaping query results:
+//
+//	active := collection.From(users).Filter(func(u User) bool { return u.Active })
+//	byTeam := collection.GroupBy(active, func(u User) int64 { return u.TeamID })
+//
+// Go methods cannot introduce type parameters, so operations that change the
+// element or key type (Map, KeyBy, GroupBy, Reduce) are package functions.
+package collection
+
+// Collection is a slice with helper methods. It converts to and from []T
+// without copying.
+type Collection[T any] []T
+
+// New returns a collection of items.
+func New[T any](items ...T) Collection[T] {
+	return Collection[T](items)
+}
+
+// From wraps an existing slice.
+func From[T any](items []T) Collection[T] {
+	return Collection[T](items)
+}
+
+// All returns the underlying slice.
+func (c Collection[T]) All() []T {
+	return []T(c)
+}
+
+// Len returns the number of items.
+func (c Collection[T]) Len() int {
+	return len(c)
+}
+
+// IsEmpty reports whether the collection has no items.
+func (c Collection[T]) IsEmpty() bool {
+	return len(c) == 0
+}
+
+// First returns the first item, or false when the collection is empty.
+func (c Collection[T]) First() (T, bool) {
+	if len(c) == 0 {
+		var zero T
+		return zero, false
+	}
+	return c[0], true
+}
+
+// Last returns the last item, or false when the collection is empty.
+func (c Collection[T]) Last() (T, bool) {
+	if len(c) == 0 {
+		var zero T
+		return zero, false
+	}
+	return c[len(c)-1], true
+}
+
+// Each calls fn for every item in order.
+func (c Collection[T]) Each(fn func(T)) {
+	for _, v := range c {
+		fn(v)
+	}
+}
+
+// Filter returns a new collection of the items for which keep returns true.
+func (c Collection[T]) Filter(keep func(T) bool) Collection[T] {
+	out := make(Collection[T], 0, len(c))
+	for _, v := range c {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Reject is the inverse of Filter.
+func (c Collection[T]) Reject(drop func(T) bool) Collection[T] {
+	return c.Filter(func(v T) bool { return !drop(v) })
+}
+
+// Find returns the first item matching fn, or false when none does.
+func (c Collection[T]) Find(fn func(T) bool) (T, bool) {
+	for _, v := range c {
+		if fn(v) {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Any reports whether fn returns true for at least one item.
+func (c Collection[T]) Any(fn func(T) bool) bool {
+	_, ok := c.Find(fn)
+	return ok
+}
+
+// Chunk splits the collection into consecutive pieces of size items; the
+// last piece may be shorter. The pieces share memory with c. A size below 1
+// returns nil.
+func (c Collection[T]) Chunk(size int) []Collection[T] {
+	if size < 1 || len(c) == 0 {
+		return nil
+	}
+	chunks := make([]Collection[T], 0, (len(c)+size-1)/size)
+	for start := 0; start < len(c); start += size {
+		end := min(start+size, len(c))
+		chunks = append(chunks, c[start:end:end])
+	}
+	return chunks
+}
+
+// Map returns the result of fn for every item of c.
+func Map[T, U any](c Collection[T], fn func(T) U) Collection[U] {
+	out := make(Collection[U], len(c))
+	for i, v := range c {
+		out[i] = fn(v)
+	}
+	return out
+}
+
+// Reduce folds c into a single value, starting from initial.
+func Reduce[T, U any](c Collection[T], initial U, fn func(U, T) U) U {
+	acc := initial
+	for _, v := range c {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// KeyBy indexes c by the key fn returns. When two items share a key the
+// later one wins.
+func KeyBy[T any, K comparable](c Collection[T], fn func(T) K) map[K]T {
+	out := make(map[K]T, len(c))
+	for _, v := range c {
+		out[fn(v)] = v
+	}
+	return out
+}
+
+// GroupBy groups the items of c by the key fn returns, keeping their order
+// within each group.
+func GroupBy[T any, K comparable](c Collection[T], fn func(T) K) map[K]Collection[T] {
+	out := make(map[K]Collection[T])
+	for _, v := range c {
+		k := fn(v)
+		out[k] = append(out[k], v)
+	}
+	return out
+}
+
+// Unique returns the items of c with duplicates removed, keeping the first
+// occurrence of each.
+func Unique[T comparable](c Collection[T]) Collection[T] {
+	return UniqueBy(c, func(v T) T { return v })
+}
+
+// UniqueBy removes items whose key, as returned by fn, was already seen.
+func UniqueBy[T any, K comparable](c Collection[T], fn func(T) K) Collection[T] {
+	seen := make(map[K]struct{}, len(c))
+	out := make(Collection[T], 0, len(c))
+	for _, v := range c {
+		k := fn(v)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package collection
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID     int
+	Team   string
+	Active bool
+}
+
+var users = From([]user{
+	{1, "core", true},
+	{2, "web", false},
+	{3, "core", true},
+	{4, "ops", true},
+})
+
+func TestFilterReject(t *testing.T) {
+	active := users.Filter(func(u user) bool { return u.Active })
+	assert.Equal(t, 3, active.Len())
+	assert.Equal(t, []user{{2, "web", false}}, users.Reject(func(u user) bool { return u.Active }).All())
+
+	found, ok := users.Find(func(u user) bool { return u.Team == "ops" })
+	assert.True(t, ok)
+	assert.Equal(t, 4, found.ID)
+	assert.False(t, users.Any(func(u user) bool { return u.ID > 10 }))
+}
+
+func TestMapAndReduce(t *testing.T) {
+	ids := Map(users, func(u user) string { return strconv.Itoa(u.ID) })
+	assert.Equal(t, Collection[string]{"1", "2", "3", "4"}, ids)
+
+	sum := Reduce(users, 0, func(acc int, u user) int { return acc + u.ID })
+	assert.Equal(t, 10, sum)
+}
+
+func TestChunk(t *testing.T) {
+	chunks := New(1, 2, 3, 4, 5).Chunk(2)
+	assert.Equal(t, []Collection[int]{{1, 2}, {3, 4}, {5}}, chunks)
+	assert.Nil(t, New(1, 2).Chunk(0))
+	assert.Nil(t, New[int]().Chunk(3))
+
+	// Appending to one chunk must not overwrite the next.
+	chunks[0] = append(chunks[0], 99)
+	assert.Equal(t, Collection[int]{3, 4}, chunks[1])
+}
+
+func TestKeyByGroupBy(t *testing.T) {
+	byID := KeyBy(users, func(u user) int { return u.ID })
+	assert.Equal(t, "web", byID[2].Team)
+
+	byTeam := GroupBy(users, func(u user) string { return u.Team })
+	assert.Len(t, byTeam, 3)
+	assert.Equal(t, []int{1, 3}, Map(byTeam["core"], func(u user) int { return u.ID }).All())
+}
+
+func TestUnique(t *testing.T) {
+	assert.Equal(t, Collection[int]{3, 1, 2}, Unique(New(3, 1, 3, 2, 1)))
+
+	teams := UniqueBy(users, func(u user) string { return u.Team })
+	assert.Equal(t, []int{1, 2, 4}, Map(teams, func(u user) int { return u.ID }).All())
+}
+
+func TestFirstLast(t *testing.T) {
+	first, ok := users.First()
+	assert.True(t, ok)
+	assert.Equal(t, 1, first.ID)
+	last, _ := users.Last()
+	assert.Equal(t, 4, last.ID)
+
+	_, ok = New[user]().First()
+	assert.False(t, ok)
+	assert.True(t, New[user]().IsEmpty())
+}
@@ -0,0 +1,115 @@
+// Package enum declares a string enum once for every layer that needs its
+// values. A type registered with New is checked by the validator and by the
+// ORM when a model is saved, gets a CHECK constraint (ENUM on MySQL) in
+// generated migrations and is written as a union of its values by astra
+// generate:client:
+//
+//	type Status string
+//
+//	const (
+//		StatusDraft     Status = "draft"
+//		StatusPublished Status = "published"
+//	)
+//
+//	var Statuses = enum.New(StatusDraft, StatusPublished)
+//
+//	func (s Status) IsValid() bool { return Statuses.IsValid(s) }
+package enum
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Enum is a fixed set of string values, such as a *Set.
+type Enum interface {
+	// Strings returns the values in the order they were declared.
+	Strings() []string
+	// Contains reports whether s is one of the values.
+	Contains(s string) bool
+}
+
+// Set is the values of the string type T.
+type Set[T ~string] struct {
+	values []T
+	names  []string
+}
+
+var registry sync.Map // map[reflect.Type]Enum
+
+// New returns the set of values and registers it as the enum of T, so code
+// that only sees a T, such as the ORM, can find it with Lookup. It panics
+// when values is empty or repeats a value, or when T already has a set:
+// each enum type is declared once, usually in a package-level var.
+func New[T ~string](values ...T) *Set[T] {
+	t := reflect.TypeFor[T]()
+	if len(values) == 0 {
+		panic(fmt.Sprintf("enum: %s has no values", t))
+	}
+	s := &Set[T]{values: slices.Clone(values), names: make([]string, len(values))}
+	for i, v := range values {
+		if slices.Contains(s.names[:i], string(v)) {
+			panic(fmt.Sprintf("enum: %s repeats the value %q", t, v))
+		}
+		s.names[i] = string(v)
+	}
+	if _, dup := registry.LoadOrStore(t, Enum(s)); dup {
+		panic(fmt.Sprintf("enum: %s is already declared", t))
+	}
+	return s
+}
+
+// Values returns the values in the order they were declared.
+func (s *Set[T]) Values() []T { return slices.Clone(s.values) }
+
+// Strings returns the values as strings.
+func (s *Set[T]) Strings() []string { return slices.Clone(s.names) }
+
+// IsValid reports whether v is one of the values.
+func (s *Set[T]) IsValid(v T) bool { return slices.Contains(s.values, v) }
+
+// Contains reports whether v is one of the values.
+func (s *Set[T]) Contains(v string) bool { return slices.Contains(s.names, v) }
+
+// Parse returns v as a T, or an error naming the valid values when it is not
+// one of them.
+func (s *Set[T]) Parse(v string) (T, error) {
+	if !s.Contains(v) {
+		return "", fmt.Errorf("enum: %q is not one of %s", v, strings.Join(s.names, ", "))
+	}
+	return T(v), nil
+}
+
+// Lookup returns the enum registered for t, or for the type t points to.
+func Lookup(t reflect.Type) (Enum, bool) {
+	if t == nil {
+		return nil, false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	e, ok := registry.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return e.(Enum), true
+}
+
+// Value returns the string held by v, a value of an enum type or a pointer
+// to one. ok is false for a nil pointer or a value of another kind.
+func Value(v any) (s string, ok bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.String {
+		return "", false
+	}
+	return rv.String(), true
+}
@@ -0,0 +1,48 @@
+package enum
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type color string
+
+const (
+	red   color = "red"
+	green color = "green"
+)
+
+var colors = New(red, green)
+
+func TestSet(t *testing.T) {
+	assert.Equal(t, []color{red, green}, colors.Values())
+	assert.Equal(t, []string{"red", "green"}, colors.Strings())
+	assert.True(t, colors.IsValid(green))
+	assert.False(t, colors.IsValid("blue"))
+
+	c, err := colors.Parse("red")
+	require.NoError(t, err)
+	assert.Equal(t, red, c)
+	_, err = colors.Parse("blue")
+	assert.EqualError(t, err, `enum: "blue" is not one of red, green`)
+
+	e, ok := Lookup(reflect.TypeFor[*color]())
+	require.True(t, ok)
+	assert.Same(t, colors, e)
+	_, ok = Lookup(reflect.TypeFor[string]())
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { New(red) }, "declared twice")
+	type shade string
+	assert.Panics(t, func() { New[shade]() })
+	assert.Panics(t, func() { New[shade]("dark", "dark") })
+
+	s, ok := Value(&c)
+	assert.True(t, ok)
+	assert.Equal(t, "red", s)
+	_, ok = Value((*color)(nil))
+	assert.False(t, ok)
+}
@@ -0,0 +1,125 @@
+// Package secure generates the random tokens the framework hands out, such
+// as session ids, CSRF tokens, OAuth state and opaque access tokens, and
+// compares secrets without leaking timing. Every token comes from
+// crypto/rand and carries at least 128 bits of entropy unless a caller asks
+// for less, so it cannot be guessed by brute force.
+package secure
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// TokenBytes is the default amount of randomness in a token: 256 bits.
+const TokenBytes = 32
+
+// Alphabets for String.
+const (
+	AlphaNumeric = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	URLSafe      = AlphaNumeric + "-_"
+)
+
+// Bytes returns n cryptographically secure random bytes.
+func Bytes(n int) ([]byte, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("secure: invalid length %d", n)
+	}
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("secure: read random bytes: %w", err)
+	}
+	return b, nil
+}
+
+// Token returns n random bytes (TokenBytes when n is 0) encoded with
+// EncodeURL, safe for cookies, URLs and headers without escaping.
+func Token(n int) (string, error) {
+	if n == 0 {
+		n = TokenBytes
+	}
+	b, err := Bytes(n)
+	if err != nil {
+		return "", err
+	}
+	return EncodeURL(b), nil
+}
+
+// MustToken is Token for callers that cannot continue without randomness.
+// It panics if the operating system's random source fails.
+func MustToken(n int) string {
+	t, err := Token(n)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Hex returns n random bytes (TokenBytes when n is 0) hex encoded, for
+// tokens that end up in case-insensitive places such as DNS names.
+func Hex(n int) (string, error) {
+	if n == 0 {
+		n = TokenBytes
+	}
+	b, err := Bytes(n)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// String returns a random string of length characters drawn uniformly from
+// alphabet, such as an API key prefix or a human-typable code. Rejection
+// sampling keeps every character equally likely, unlike taking a random
+// byte modulo the alphabet size.
+func String(length int, alphabet string) (string, error) {
+	if length <= 0 {
+		return "", fmt.Errorf("secure: invalid length %d", length)
+	}
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		return "", errors.New("secure: alphabet must have between 2 and 256 characters")
+	}
+	// Largest multiple of len(alphabet) that fits in a byte; bytes at or
+	// above it are discarded.
+	limit := 256 - 256%len(alphabet)
+	out := make([]byte, 0, length)
+	buf := make([]byte, length+length/4+8)
+	for len(out) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("secure: read random bytes: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) < limit {
+				out = append(out, alphabet[int(b)%len(alphabet)])
+				if len(out) == length {
+					break
+				}
+			}
+		}
+	}
+	return string(out), nil
+}
+
+// Equal reports whether a and b are equal in time that depends only on
+// their lengths, so comparing a submitted token with the stored one does not
+// reveal how many leading characters matched.
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// EncodeURL encodes b as unpadded URL-safe base64.
+func EncodeURL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeURL decodes unpadded URL-safe base64, also accepting the padded
+// form older tokens were issued in.
+func DecodeURL(s string) ([]byte, error) {
+	if len(s)%4 != 0 {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,64 @@
+package secure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToken(t *testing.T) {
+	a, err := Token(0)
+	require.NoError(t, err)
+	b, err := Token(0)
+	require.NoError(t, err)
+
+	assert.Len(t, a, 43, "32 bytes of unpadded base64")
+	assert.NotEqual(t, a, b)
+	assert.NotContains(t, a, "=")
+	assert.NotContains(t, a, "+")
+	assert.NotContains(t, a, "/")
+
+	raw, err := DecodeURL(a)
+	require.NoError(t, err)
+	assert.Len(t, raw, TokenBytes)
+
+	h, err := Hex(16)
+	require.NoError(t, err)
+	assert.Len(t, h, 32)
+
+	_, err = Token(-1)
+	assert.Error(t, err)
+}
+
+func TestString(t *testing.T) {
+	s, err := String(64, "ab")
+	require.NoError(t, err)
+	assert.Len(t, s, 64)
+	assert.Empty(t, strings.Trim(s, "ab"))
+
+	// Over many draws every character of a non power-of-two alphabet shows up
+	// roughly equally often.
+	s, err = String(30000, "abc")
+	require.NoError(t, err)
+	for _, ch := range "abc" {
+		assert.InDelta(t, 10000, strings.Count(s, string(ch)), 600)
+	}
+
+	_, err = String(8, "a")
+	assert.Error(t, err)
+}
+
+func TestEqualAndDecode(t *testing.T) {
+	assert.True(t, Equal("token", "token"))
+	assert.False(t, Equal("token", "tokem"))
+	assert.False(t, Equal("token", "token2"))
+
+	padded, err := DecodeURL("aGk=")
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(padded))
+	raw, err := DecodeURL("aGk")
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(raw))
+}
@@ -0,0 +1,144 @@
+package str
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type inflection struct{ suffix, replace string }
+
+// irregular maps singular to plural for words suffix rules get wrong.
+var irregular = map[string]string{
+	"child": "children", "person": "people", "man": "men", "woman": "women",
+	"mouse": "mice", "goose": "geese", "tooth": "teeth", "foot": "feet",
+	"ox": "oxen", "leaf": "leaves", "life": "lives", "knife": "knives",
+	"wife": "wives", "half": "halves", "self": "selves", "elf": "elves",
+	"criterion": "criteria", "datum": "data", "medium": "media",
+	"analysis": "analyses", "thesis": "theses", "crisis": "crises",
+	"index": "indices", "matrix": "matrices", "vertex": "vertices",
+	"quiz": "quizzes", "cactus": "cacti", "focus": "foci", "radius": "radii",
+	// Regular plurals the suffix rules would singularize wrongly.
+	"movie": "movies", "cookie": "cookies", "pie": "pies", "tie": "ties",
+	"cache": "caches", "niche": "niches", "headache": "headaches",
+}
+
+var irregularPlural = func() map[string]string {
+	m := make(map[string]string, len(irregular))
+	for s, p := range irregular {
+		m[p] = s
+	}
+	return m
+}()
+
+// uncountable words are the same in singular and plural.
+var uncountable = map[string]bool{
+	"equipment": true, "information": true, "rice": true, "money": true,
+	"species": true, "series": true, "fish": true, "sheep": true, "deer": true,
+	"news": true, "metadata": true, "feedback": true, "software": true,
+	"hardware": true, "audio": true, "video": true, "staff": true, "traffic": true,
+	"police": true, "advice": true, "evidence": true, "furniture": true,
+}
+
+// Checked in order; the first matching suffix wins.
+var pluralRules = []inflection{
+	{"ss", "sses"}, {"sh", "shes"}, {"ch", "ches"}, {"x", "xes"}, {"zz", "zzes"}, {"s", "ses"},
+	{"ay", "ays"}, {"ey", "eys"}, {"iy", "iys"}, {"oy", "oys"}, {"uy", "uys"}, {"y", "ies"},
+}
+
+var singularRules = []inflection{
+	{"sses", "ss"}, {"shes", "sh"}, {"ches", "ch"}, {"xes", "x"}, {"zzes", "zz"},
+	{"ies", "y"}, {"ss", "ss"}, {"us", "us"}, {"is", "is"},
+	{"s", ""},
+}
+
+// Words ending in -us or -o whose plural adds "es". Other -uses and -oes
+// plurals (courses, shoes) just drop the "s".
+var esWords = map[string]bool{
+	"status": true, "bus": true, "virus": true, "campus": true, "bonus": true,
+	"census": true, "corpus": true, "octopus": true, "syllabus": true,
+	"hero": true, "potato": true, "tomato": true, "echo": true, "veto": true, "torpedo": true,
+}
+
+// Plural returns the English plural of word, keeping the case of its first
+// letter: "Category" → "Categories", "person" → "people". In a compound name
+// only the last word changes: "BlogPost" → "BlogPosts".
+func Plural(word string) string {
+	return inflectLast(word, pluralize)
+}
+
+// Singular returns the English singular of word: "Categories" → "Category".
+func Singular(word string) string {
+	return inflectLast(word, singularize)
+}
+
+func pluralize(w string) string {
+	if uncountable[w] {
+		return w
+	}
+	if p, ok := irregular[w]; ok {
+		return p
+	}
+	if _, ok := irregularPlural[w]; ok {
+		return w
+	}
+	if esWords[w] {
+		return w + "es"
+	}
+	for _, r := range pluralRules {
+		if strings.HasSuffix(w, r.suffix) {
+			return strings.TrimSuffix(w, r.suffix) + r.replace
+		}
+	}
+	return w + "s"
+}
+
+func singularize(w string) string {
+	if uncountable[w] {
+		return w
+	}
+	if s, ok := irregularPlural[w]; ok {
+		return s
+	}
+	if _, ok := irregular[w]; ok {
+		return w
+	}
+	if base, ok := strings.CutSuffix(w, "es"); ok && esWords[base] {
+		return base
+	}
+	// "apis" and "cpus" would otherwise hit the -is and -us rules.
+	if base, ok := strings.CutSuffix(w, "s"); ok && !initialisms[strings.ToUpper(w)] && initialisms[strings.ToUpper(base)] {
+		return base
+	}
+	for _, r := range singularRules {
+		if strings.HasSuffix(w, r.suffix) {
+			return strings.TrimSuffix(w, r.suffix) + r.replace
+		}
+	}
+	return w
+}
+
+// inflectLast applies fn to the last word of name in lower case and
+// restores its capitalisation.
+func inflectLast(name string, fn func(string) string) string {
+	words := Words(name)
+	if len(words) == 0 {
+		return name
+	}
+	last := words[len(words)-1]
+	i := strings.LastIndex(name, last)
+	out := fn(strings.ToLower(last))
+	caps := strings.TrimSuffix(last, "s")
+	switch {
+	case utf8.RuneCountInString(caps) > 1 && caps == strings.ToUpper(caps):
+		if base, ok := strings.CutSuffix(out, "s"); ok && initialisms[strings.ToUpper(base)] {
+			out = strings.ToUpper(base) + "s"
+		} else {
+			out = strings.ToUpper(out)
+		}
+	case unicode.IsUpper([]rune(last)[0]):
+		r, size := utf8.DecodeRuneInString(out)
+		out = string(unicode.ToUpper(r)) + out[size:]
+	}
+	return name[:i] + out + name[i+len(last):]
+}
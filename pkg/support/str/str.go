@@ -0,0 +1,187 @@
+// Package str converts identifiers between naming conventions and formats
+// strings for display. Conversions split words the way Go names are
+// written, so acronyms and digits survive a round trip:
+//
+//	str.Snake("HTTPServer")  // "http_server"
+//	str.Pascal("user_id")    // "UserID"
+//	str.Camel("api-key")     // "apiKey"
+//	str.Plural("Category")   // "Categories"
+package str
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// initialisms are written in upper case by Pascal and Camel, following the
+// Go naming conventions (UserID, not UserId).
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "CSV": true,
+	"DB": true, "DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "JWT": true, "OTP": true,
+	"RAM": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true,
+	"SSO": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true,
+	"XML": true, "XSRF": true, "XSS": true, "CSRF": true,
+}
+
+// Words splits s into its words. Separators are any rune that is not a
+// letter or digit; inside a run of letters a word starts at a lower-to-upper
+// change, and an upper-case run followed by a lower-case letter ends one
+// letter early so "HTTPServer" gives "HTTP" and "Server". A lone trailing
+// "s" stays with the run, so plural initialisms such as "IDs" are one word.
+// Digits stay with the letters before them ("Address2", "OAuth2").
+func Words(s string) []string {
+	var words []string
+	rs := []rune(s)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			words = append(words, string(rs[start:end]))
+		}
+		start = -1
+	}
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		if unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if nextLower && rs[i+1] == 's' && (i+2 == len(rs) || !unicode.IsLower(rs[i+2])) {
+				nextLower = false
+			}
+			if !unicode.IsUpper(prev) || nextLower {
+				flush(i)
+				start = i
+			}
+		}
+	}
+	flush(len(rs))
+	return words
+}
+
+// Snake converts s to snake_case: "UserID" → "user_id".
+func Snake(s string) string {
+	return joinLower(Words(s), "_")
+}
+
+// Kebab converts s to kebab-case: "UserID" → "user-id".
+func Kebab(s string) string {
+	return joinLower(Words(s), "-")
+}
+
+// Pascal converts s to PascalCase with Go initialisms: "user_id" → "UserID".
+func Pascal(s string) string {
+	var b strings.Builder
+	for _, w := range Words(s) {
+		b.WriteString(title(w))
+	}
+	return b.String()
+}
+
+// Camel converts s to camelCase: "user_id" → "userID", "ID" → "id".
+func Camel(s string) string {
+	words := Words(s)
+	if len(words) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(strings.ToLower(words[0]))
+	for _, w := range words[1:] {
+		b.WriteString(title(w))
+	}
+	return b.String()
+}
+
+// Slug converts s into a lower-case URL segment, dropping accents:
+// "Crème Brûlée!" → "creme-brulee".
+func Slug(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if plain, _, err := transform.String(t, s); err == nil {
+		s = plain
+	}
+	var words []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		words = append(words, strings.ToLower(f))
+	}
+	return strings.Join(words, "-")
+}
+
+// Mask replaces length runes of s starting at start with mask, keeping the
+// rest readable. A negative start counts from the end and a length of 0 or
+// less masks to the end:
+//
+//	str.Mask("4242424242424242", '*', 0, -4) // "************4242"
+//	str.Mask("ada@example.com", '*', 3, 0)   // "ada************"
+//
+// A negative length leaves that many runes unmasked at the end.
+func Mask(s string, mask rune, start, length int) string {
+	rs := []rune(s)
+	n := len(rs)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if start >= n {
+		return s
+	}
+	end := n
+	switch {
+	case length > 0:
+		end = min(start+length, n)
+	case length < 0:
+		end = max(n+length, start)
+	}
+	for i := start; i < end; i++ {
+		rs[i] = mask
+	}
+	return string(rs)
+}
+
+// Truncate shortens s to at most limit runes including suffix, which is
+// appended when anything was cut: Truncate("Hello world", 8, "...") is
+// "Hello...". It never splits a multi-byte character.
+func Truncate(s string, limit int, suffix string) string {
+	if limit <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	keep := limit - utf8.RuneCountInString(suffix)
+	if keep <= 0 {
+		return string([]rune(suffix)[:limit])
+	}
+	return strings.TrimRightFunc(string([]rune(s)[:keep]), unicode.IsSpace) + suffix
+}
+
+func joinLower(words []string, sep string) string {
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, sep)
+}
+
+// title upper-cases the first rune of w and lower-cases the rest, or the
+// whole word when it is a known initialism, plural ones included ("IDs").
+func title(w string) string {
+	u := strings.ToUpper(w)
+	if initialisms[u] {
+		return u
+	}
+	if base, ok := strings.CutSuffix(u, "S"); ok && initialisms[base] {
+		return base + "s"
+	}
+	r, size := utf8.DecodeRuneInString(w)
+	return string(unicode.ToUpper(r)) + strings.ToLower(w[size:])
+}
@@ -0,0 +1,118 @@
+package str
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"UserID":        {"User", "ID"},
+		"HTTPServer":    {"HTTP", "Server"},
+		"userIDs":       {"user", "IDs"},
+		"URLsToFetch":   {"URLs", "To", "Fetch"},
+		"IDSet":         {"ID", "Set"},
+		"OAuth2Token":   {"O", "Auth2", "Token"},
+		"Base64Encode":  {"Base64", "Encode"},
+		"V2API":         {"V2", "API"},
+		"2fa_code":      {"2fa", "code"},
+		"__private__":   {"private"},
+		"ÜberCool":      {"Über", "Cool"},
+		"straßeName":    {"straße", "Name"},
+		"ÄrgerID":       {"Ärger", "ID"},
+		"日本語テキスト":       {"日本語テキスト"},
+		"hello 👋 world": {"hello", "world"},
+		"":              nil,
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, Words(in), "Words(%q)", in)
+	}
+}
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		in, snake, kebab, pascal, camel string
+	}{
+		{"UserID", "user_id", "user-id", "UserID", "userID"},
+		{"ID", "id", "id", "ID", "id"},
+		{"id", "id", "id", "ID", "id"},
+		{"HTTPServer", "http_server", "http-server", "HTTPServer", "httpServer"},
+		{"user_id", "user_id", "user-id", "UserID", "userID"},
+		{"user_ids", "user_ids", "user-ids", "UserIDs", "userIDs"},
+		{"userIDs", "user_ids", "user-ids", "UserIDs", "userIDs"},
+		{"api-key", "api_key", "api-key", "APIKey", "apiKey"},
+		{"json_api_url", "json_api_url", "json-api-url", "JSONAPIURL", "jsonAPIURL"},
+		{"first name", "first_name", "first-name", "FirstName", "firstName"},
+		{"Address2Line", "address2_line", "address2-line", "Address2Line", "address2Line"},
+		{"2fa_code", "2fa_code", "2fa-code", "2faCode", "2faCode"},
+		{"ÜberCool", "über_cool", "über-cool", "ÜberCool", "überCool"},
+		{"éclair_id", "éclair_id", "éclair-id", "ÉclairID", "éclairID"},
+		{"", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.snake, Snake(tt.in), "Snake")
+			assert.Equal(t, tt.kebab, Kebab(tt.in), "Kebab")
+			assert.Equal(t, tt.pascal, Pascal(tt.in), "Pascal")
+			assert.Equal(t, tt.camel, Camel(tt.in), "Camel")
+		})
+	}
+}
+
+func TestCaseRoundTrip(t *testing.T) {
+	for _, name := range []string{"UserID", "HTTPServer", "APIKey", "OrderLineItems", "UserIDs", "ÜberCool"} {
+		assert.Equal(t, name, Pascal(Snake(name)), name)
+		assert.Equal(t, name, Pascal(Camel(name)), name)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	assert.Equal(t, "creme-brulee", Slug("Crème Brûlée!"))
+	assert.Equal(t, "hello-world-2", Slug("  Hello, World 2 "))
+	assert.Equal(t, "", Slug("!!!"))
+}
+
+func TestPluralSingular(t *testing.T) {
+	tests := []struct{ singular, plural string }{
+		{"user", "users"},
+		{"Category", "Categories"},
+		{"BlogPost", "BlogPosts"},
+		{"person", "people"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"status", "statuses"},
+		{"course", "courses"},
+		{"photo", "photos"},
+		{"hero", "heroes"},
+		{"day", "days"},
+		{"cache", "caches"},
+		{"movie", "movies"},
+		{"news", "news"},
+		{"UserID", "UserIDs"},
+		{"API", "APIs"},
+		{"STATUS", "STATUSES"},
+		{"user_address", "user_addresses"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.plural, Plural(tt.singular), "Plural(%q)", tt.singular)
+		assert.Equal(t, tt.singular, Singular(tt.plural), "Singular(%q)", tt.plural)
+	}
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "************4242", Mask("4242424242424242", '*', 0, -4))
+	assert.Equal(t, "ada************", Mask("ada@example.com", '*', 3, 0))
+	assert.Equal(t, "ab**ef", Mask("abcdef", '*', 2, 2))
+	assert.Equal(t, "abcd**", Mask("abcdef", '*', -2, 0))
+	assert.Equal(t, "abc", Mask("abc", '*', 5, 1))
+	assert.Equal(t, "ü**", Mask("üöä", '*', 1, 0))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Hello...", Truncate("Hello world", 8, "..."))
+	assert.Equal(t, "short", Truncate("short", 10, "..."))
+	assert.Equal(t, "héll…", Truncate("héllo wörld", 5, "…"))
+	assert.Equal(t, "..", Truncate("Hello", 2, "..."))
+	assert.Equal(t, "", Truncate("Hello", 0, "..."))
+}
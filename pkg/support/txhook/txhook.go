@@ -0,0 +1,47 @@
+// Package txhook lets code that only holds a context.Context defer work
+// until the database transaction it runs in commits, without importing
+// the ORM. The database package binds its transactions to the context
+// passed to db.Transaction; the queue and event packages use AfterCommit
+// so a job or event triggered inside a transaction that rolls back is
+// never sent.
+package txhook
+
+import "context"
+
+// Deferrer is a transaction that can run callbacks once it commits.
+type Deferrer interface {
+	// AfterCommit registers fn to run after the commit. Callbacks are
+	// dropped when the transaction rolls back.
+	AfterCommit(fn func())
+	// InTransaction reports whether a transaction is open.
+	InTransaction() bool
+}
+
+type deferrerKey struct{}
+
+// WithDeferrer returns a copy of ctx bound to d.
+func WithDeferrer(ctx context.Context, d Deferrer) context.Context {
+	return context.WithValue(ctx, deferrerKey{}, d)
+}
+
+// Detach returns a copy of ctx no longer bound to a transaction, for
+// callbacks that run after the commit.
+func Detach(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deferrerKey{}, nil)
+}
+
+// InTransaction reports whether ctx is bound to an open transaction.
+func InTransaction(ctx context.Context) bool {
+	d, ok := ctx.Value(deferrerKey{}).(Deferrer)
+	return ok && d.InTransaction()
+}
+
+// AfterCommit runs fn once the transaction ctx is bound to commits, or
+// right away when ctx is not in a transaction.
+func AfterCommit(ctx context.Context, fn func()) {
+	if d, ok := ctx.Value(deferrerKey{}).(Deferrer); ok && d.InTransaction() {
+		d.AfterCommit(fn)
+		return
+	}
+	fn()
+}
@@ -28,6 +28,7 @@ func NewTestApp(t *testing.T, setup func(app *engine.App, router *astrahttp.Rout
 	logger := slog.Default()
 	
 	app := engine.New(cfg, env, logger)
+	app.SetEnvironment(engine.EnvironmentTest)
 	
 	// Create a decoupled router for testing
 	router := astrahttp.NewRouter(cfg, logger)
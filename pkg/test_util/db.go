@@ -48,3 +48,10 @@ func WithORMTransaction(t *testing.T, db *database.DB, fn func(txDB *database.DB
 
 	fn(db.WithTx(tx))
 }
+
+// RefreshDatabase empties every table except those in except before a
+// test, in foreign-key order, so tests that commit data start clean.
+func RefreshDatabase(t *testing.T, db *database.DB, except ...string) {
+	t.Helper()
+	require.NoError(t, database.TruncateAll(context.Background(), db, except...))
+}
@@ -8,9 +8,14 @@ import (
 )
 
 // SyncDispatcher executes jobs immmediately in the same goroutine instead of queueing.
+//
+// Deprecated: use fakes.Queue, which records the pushed jobs for
+// assertions; its Run handles them as a worker would.
 type SyncDispatcher struct{}
 
 // NewSyncDispatcher creates a new synchronous dispatcher.
+//
+// Deprecated: use fakes.NewQueue.
 func NewSyncDispatcher() *SyncDispatcher {
 	return &SyncDispatcher{}
 }
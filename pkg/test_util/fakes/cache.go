@@ -0,0 +1,80 @@
+// Package fakes provides in-memory doubles of the framework's
+// infrastructure contracts: cache.Store, queue.Queue, storage.Storage,
+// mail.Mailer and a Redis connection. Each records what the code under
+// test did and has assertion helpers, so unit tests run without Redis, a
+// mail server or a bucket:
+//
+//	q := fakes.NewQueue()
+//	svc := signup.NewService(q)
+//	require.NoError(t, svc.Register(ctx, "ada@example.com"))
+//	q.AssertPushed(t, "SendWelcomeEmail", func(j queue.Job) bool {
+//	    return j.(*jobs.SendWelcomeEmail).To == "ada@example.com"
+//	})
+package fakes
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Cache is an in-memory cache.Store that records the keys written.
+type Cache struct {
+	*cache.MemoryStore
+
+	mu      sync.Mutex
+	written []string
+}
+
+var _ cache.Store = (*Cache)(nil)
+
+// NewCache creates an empty Cache.
+func NewCache() *Cache {
+	return &Cache{MemoryStore: cache.NewMemoryStore()}
+}
+
+// Set stores value and records key as written.
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := c.MemoryStore.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.written = append(c.written, key)
+	c.mu.Unlock()
+	return nil
+}
+
+// AssertHas asserts that key holds a value, and that the value is want
+// when one is given.
+func (c *Cache) AssertHas(t testing.TB, key string, want ...string) {
+	t.Helper()
+	got, err := c.Get(context.Background(), key)
+	if !assert.NoError(t, err, "expected cache key %q to be set", key) {
+		return
+	}
+	if len(want) > 0 {
+		assert.Equal(t, want[0], got, "cache key %q", key)
+	}
+}
+
+// AssertMissing asserts that key holds no value.
+func (c *Cache) AssertMissing(t testing.TB, key string) {
+	t.Helper()
+	ok, err := c.Has(context.Background(), key)
+	assert.NoError(t, err)
+	assert.False(t, ok, "expected cache key %q to be missing", key)
+}
+
+// AssertWritten asserts that key was written at least once, even if it has
+// expired or been deleted since.
+func (c *Cache) AssertWritten(t testing.TB, key string) {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.True(t, slices.Contains(c.written, key), "expected cache key %q to be written, written: %v", key, c.written)
+}
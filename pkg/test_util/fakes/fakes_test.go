@@ -0,0 +1,85 @@
+package fakes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/mail"
+	"github.com/shauryagautam/Astra/pkg/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SendEmail struct {
+	queue.BaseJob
+	To   string
+	sent *[]string
+}
+
+func (j *SendEmail) Handle(context.Context) error {
+	*j.sent = append(*j.sent, j.To)
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	var sent []string
+	q := NewQueue()
+	q.AssertNothingPushed(t)
+
+	require.NoError(t, q.Enqueue(ctx, &SendEmail{To: "ada@example.com", sent: &sent}))
+	require.NoError(t, q.EnqueueIn(ctx, &SendEmail{To: "bob@example.com", sent: &sent}, time.Minute))
+
+	q.AssertPushed(t, "SendEmail", func(j queue.Job) bool { return j.(*SendEmail).To == "bob@example.com" })
+	q.AssertNotPushed(t, "SendEmail", func(j queue.Job) bool { return j.(*SendEmail).To == "eve@example.com" })
+	q.AssertPushedOn(t, "default", "SendEmail")
+	q.AssertPushedTimes(t, "SendEmail", 2)
+	assert.Equal(t, time.Minute, q.Pushed("SendEmail")[1].Delay)
+
+	inner := &testing.T{}
+	q.AssertPushed(inner, "SendEmail", func(j queue.Job) bool { return false })
+	assert.True(t, inner.Failed())
+
+	n, _ := q.Size(ctx, "default")
+	assert.EqualValues(t, 2, n)
+	require.NoError(t, q.Run(ctx))
+	assert.Equal(t, []string{"ada@example.com", "bob@example.com"}, sent)
+	q.AssertNothingPushed(t)
+}
+
+func TestMailer(t *testing.T) {
+	m := NewMailer()
+	m.AssertNothingSent(t)
+	require.NoError(t, m.Send(context.Background(), &mail.Message{To: []string{"ada@example.com"}, Subject: "Welcome"}))
+
+	m.AssertSentTo(t, "ada@example.com", "Welcome")
+	m.AssertSent(t, func(msg *mail.Message) bool { return msg.Subject == "Welcome" })
+	m.AssertSentCount(t, 1)
+
+	inner := &testing.T{}
+	m.AssertSentTo(inner, "bob@example.com")
+	assert.True(t, inner.Failed())
+}
+
+func TestCacheDiskAndRedis(t *testing.T) {
+	ctx := context.Background()
+
+	c := NewCache()
+	require.NoError(t, c.Set(ctx, "user:1", "ada", time.Minute))
+	require.NoError(t, c.Delete(ctx, "user:1"))
+	c.AssertWritten(t, "user:1")
+	c.AssertMissing(t, "user:1")
+	require.NoError(t, c.Set(ctx, "user:2", "bob", 0))
+	c.AssertHas(t, "user:2", "bob")
+
+	d := NewDisk()
+	require.NoError(t, d.Put(ctx, "avatars/1.png", []byte("png")))
+	d.AssertExists(t, "avatars/1.png", []byte("png"))
+	d.AssertMissing(t, "avatars/2.png")
+
+	r := NewRedis(t)
+	require.NoError(t, r.Set(ctx, "counter", "3", 0).Err())
+	r.AssertHas(t, "counter", "3")
+	r.AssertMissing(t, "other")
+}
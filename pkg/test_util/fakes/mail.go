@@ -0,0 +1,81 @@
+package fakes
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/mail"
+	"github.com/stretchr/testify/assert"
+)
+
+// Mailer is a mail.Mailer that keeps sent messages in memory.
+type Mailer struct {
+	mu   sync.Mutex
+	sent []*mail.Message
+}
+
+var _ mail.Mailer = (*Mailer)(nil)
+
+// NewMailer creates a Mailer with no messages.
+func NewMailer() *Mailer {
+	return &Mailer{}
+}
+
+// Send records msg.
+func (m *Mailer) Send(ctx context.Context, msg *mail.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.sent = append(m.sent, msg)
+	m.mu.Unlock()
+	return nil
+}
+
+// Sent returns the messages sent so far, optionally only those satisfying
+// all matchers.
+func (m *Mailer) Sent(matchers ...func(*mail.Message) bool) []*mail.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*mail.Message
+	for _, msg := range m.sent {
+		if matchAll(msg, matchers) {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// AssertSent asserts that a message satisfying all matchers was sent.
+func (m *Mailer) AssertSent(t testing.TB, matchers ...func(*mail.Message) bool) {
+	t.Helper()
+	if len(m.Sent(matchers...)) == 0 {
+		assert.Fail(t, "mail not sent", "expected a matching message among the %d sent", len(m.Sent()))
+	}
+}
+
+// AssertSentTo asserts that a message was sent to address, with subject
+// when one is given.
+func (m *Mailer) AssertSentTo(t testing.TB, address string, subject ...string) {
+	t.Helper()
+	sent := m.Sent(func(msg *mail.Message) bool {
+		return slices.Contains(msg.To, address) && (len(subject) == 0 || msg.Subject == subject[0])
+	})
+	if len(sent) == 0 {
+		assert.Fail(t, "mail not sent", "expected a message sent to %s", address)
+	}
+}
+
+// AssertSentCount asserts that exactly n messages were sent.
+func (m *Mailer) AssertSentCount(t testing.TB, n int) {
+	t.Helper()
+	assert.Len(t, m.Sent(), n, "number of messages sent")
+}
+
+// AssertNothingSent asserts that no message was sent.
+func (m *Mailer) AssertNothingSent(t testing.TB) {
+	t.Helper()
+	assert.Empty(t, m.Sent(), "expected no messages to be sent")
+}
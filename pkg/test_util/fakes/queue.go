@@ -0,0 +1,195 @@
+package fakes
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shauryagautam/Astra/pkg/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+// PushedJob is a job pushed to a Queue.
+type PushedJob struct {
+	Job queue.Job
+	// Name is the job's type name, e.g. "SendEmail" for *jobs.SendEmail.
+	Name  string
+	Queue string
+	// Delay is the delay requested with EnqueueIn, or the time left until
+	// the time given to EnqueueAt.
+	Delay time.Duration
+}
+
+// Queue is a queue.Queue that keeps pushed jobs in memory instead of
+// running them.
+type Queue struct {
+	mu   sync.Mutex
+	jobs []PushedJob
+}
+
+var _ queue.Queue = (*Queue)(nil)
+
+// NewQueue creates an empty Queue.
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+// Enqueue records job.
+func (q *Queue) Enqueue(ctx context.Context, job queue.Job) error {
+	return q.EnqueueIn(ctx, job, 0)
+}
+
+// EnqueueIn records job with its delay.
+func (q *Queue) EnqueueIn(ctx context.Context, job queue.Job, delay time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name := "default"
+	if n := job.Queue(); n != "" {
+		name = n
+	}
+	q.mu.Lock()
+	q.jobs = append(q.jobs, PushedJob{Job: job, Name: jobName(job), Queue: name, Delay: delay})
+	q.mu.Unlock()
+	return nil
+}
+
+// EnqueueAt records job with the delay until at.
+func (q *Queue) EnqueueAt(ctx context.Context, job queue.Job, at time.Time) error {
+	return q.EnqueueIn(ctx, job, max(time.Until(at), 0))
+}
+
+// Size returns the number of jobs pushed to queueName.
+func (q *Queue) Size(_ context.Context, queueName string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var n int64
+	for _, j := range q.jobs {
+		if j.Queue == queueName {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Purge forgets the jobs pushed to queueName.
+func (q *Queue) Purge(_ context.Context, queueName string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	kept := q.jobs[:0]
+	for _, j := range q.jobs {
+		if j.Queue != queueName {
+			kept = append(kept, j)
+		}
+	}
+	q.jobs = kept
+	return nil
+}
+
+// Pushed returns the jobs pushed so far, optionally only those named name.
+func (q *Queue) Pushed(name ...string) []PushedJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var out []PushedJob
+	for _, j := range q.jobs {
+		if len(name) == 0 || j.Name == name[0] {
+			out = append(out, j)
+		}
+	}
+	return out
+}
+
+// Run handles every pushed job in order, as a worker would, and forgets
+// them. It stops at the first error.
+func (q *Queue) Run(ctx context.Context) error {
+	q.mu.Lock()
+	jobs := q.jobs
+	q.jobs = nil
+	q.mu.Unlock()
+	for _, j := range jobs {
+		if err := j.Job.Handle(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssertPushed asserts that a job named name was pushed and, when matchers
+// are given, that one such job satisfies all of them.
+func (q *Queue) AssertPushed(t testing.TB, name string, matchers ...func(queue.Job) bool) {
+	t.Helper()
+	pushed := q.Pushed(name)
+	for _, j := range pushed {
+		if matchAll(j.Job, matchers) {
+			return
+		}
+	}
+	if len(pushed) == 0 {
+		assert.Fail(t, "job not pushed", "expected a %s job to be pushed, pushed: %v", name, q.names())
+		return
+	}
+	assert.Fail(t, "job not pushed", "%d %s job(s) were pushed, but none matched", len(pushed), name)
+}
+
+// AssertPushedOn asserts that a job named name was pushed to queueName.
+func (q *Queue) AssertPushedOn(t testing.TB, queueName, name string) {
+	t.Helper()
+	for _, j := range q.Pushed(name) {
+		if j.Queue == queueName {
+			return
+		}
+	}
+	assert.Fail(t, "job not pushed", "expected a %s job on queue %q", name, queueName)
+}
+
+// AssertPushedTimes asserts that exactly n jobs named name were pushed.
+func (q *Queue) AssertPushedTimes(t testing.TB, name string, n int) {
+	t.Helper()
+	assert.Len(t, q.Pushed(name), n, "number of %s jobs pushed", name)
+}
+
+// AssertNotPushed asserts that no job named name satisfying the matchers
+// was pushed.
+func (q *Queue) AssertNotPushed(t testing.TB, name string, matchers ...func(queue.Job) bool) {
+	t.Helper()
+	for _, j := range q.Pushed(name) {
+		if matchAll(j.Job, matchers) {
+			assert.Fail(t, "unexpected job", "expected no %s job to be pushed", name)
+			return
+		}
+	}
+}
+
+// AssertNothingPushed asserts that no job was pushed.
+func (q *Queue) AssertNothingPushed(t testing.TB) {
+	t.Helper()
+	assert.Empty(t, q.names(), "expected no jobs to be pushed")
+}
+
+func (q *Queue) names() []string {
+	var names []string
+	for _, j := range q.Pushed() {
+		names = append(names, j.Name)
+	}
+	return names
+}
+
+func matchAll[T any](v T, matchers []func(T) bool) bool {
+	for _, m := range matchers {
+		if !m(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// jobName returns the name of a job's type, without pointers.
+func jobName(job queue.Job) string {
+	typ := reflect.TypeOf(job)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Name()
+}
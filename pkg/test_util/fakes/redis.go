@@ -0,0 +1,51 @@
+package fakes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	astraredis "github.com/shauryagautam/Astra/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Redis is a Redis connection backed by an in-process server, for code that
+// takes a *redis.Client. Server exposes the server to inspect or
+// fast-forward keys.
+type Redis struct {
+	*astraredis.Client
+	Server *miniredis.Miniredis
+}
+
+// NewRedis starts an in-process Redis server and connects to it. Both are
+// closed when the test ends.
+func NewRedis(t testing.TB) *Redis {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client, err := astraredis.NewClient(config.RedisConfig{URL: "redis://" + srv.Addr()}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Stop(context.Background()) })
+	return &Redis{Client: client, Server: srv}
+}
+
+// AssertHas asserts that key exists and, when want is given, that it holds
+// the string want.
+func (r *Redis) AssertHas(t testing.TB, key string, want ...string) {
+	t.Helper()
+	if !assert.True(t, r.Server.Exists(key), "expected redis key %q to exist", key) {
+		return
+	}
+	if len(want) > 0 {
+		got, err := r.Server.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, want[0], got, "redis key %q", key)
+	}
+}
+
+// AssertMissing asserts that key does not exist.
+func (r *Redis) AssertMissing(t testing.TB, key string) {
+	t.Helper()
+	assert.False(t, r.Server.Exists(key), "expected redis key %q to be missing", key)
+}
@@ -0,0 +1,42 @@
+package fakes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shauryagautam/Astra/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// Disk is an in-memory storage.Storage with assertions on its contents.
+type Disk struct {
+	*storage.MemoryStorage
+}
+
+var _ storage.Storage = (*Disk)(nil)
+
+// NewDisk creates an empty Disk.
+func NewDisk() *Disk {
+	return &Disk{MemoryStorage: storage.NewMemoryStorage()}
+}
+
+// AssertExists asserts that a file is stored at path and, when want is
+// given, that it holds want.
+func (d *Disk) AssertExists(t testing.TB, path string, want ...[]byte) {
+	t.Helper()
+	got, err := d.Get(context.Background(), path)
+	if !assert.NoError(t, err, "expected file %q to exist", path) {
+		return
+	}
+	if len(want) > 0 {
+		assert.Equal(t, string(want[0]), string(got), "contents of %q", path)
+	}
+}
+
+// AssertMissing asserts that no file is stored at path.
+func (d *Disk) AssertMissing(t testing.TB, path string) {
+	t.Helper()
+	ok, err := d.Exists(context.Background(), path)
+	assert.NoError(t, err)
+	assert.False(t, ok, "expected file %q to be missing", path)
+}
@@ -5,42 +5,49 @@ import (
 	"testing"
 
 	"github.com/shauryagautam/Astra/pkg/mail"
-	"github.com/stretchr/testify/assert"
+	"github.com/shauryagautam/Astra/pkg/test_util/fakes"
 )
 
 // FakeMailer is a test mailer that collects sent messages in memory.
+//
+// Deprecated: use fakes.Mailer, which FakeMailer wraps. It is safe for
+// concurrent use and can match messages on more than their recipient.
 type FakeMailer struct {
+	*fakes.Mailer
 	Messages []*mail.Message
 }
 
 // NewFakeMailer creates a new FakeMailer.
+//
+// Deprecated: use fakes.NewMailer.
 func NewFakeMailer() *FakeMailer {
 	return &FakeMailer{
+		Mailer:   fakes.NewMailer(),
 		Messages: make([]*mail.Message, 0),
 	}
 }
 
 // Send implements the mail.Mailer interface.
 func (m *FakeMailer) Send(ctx context.Context, msg *mail.Message) error {
+	if err := m.Mailer.Send(ctx, msg); err != nil {
+		return err
+	}
 	m.Messages = append(m.Messages, msg)
 	return nil
 }
 
 // AssertSent asserts that an email was sent to the given address.
+//
+// Deprecated: use AssertSentTo.
 func (m *FakeMailer) AssertSent(t *testing.T, to string) {
 	t.Helper()
-	for _, msg := range m.Messages {
-		for _, recipient := range msg.To {
-			if recipient == to {
-				return
-			}
-		}
-	}
-	assert.Fail(t, "Email was not sent to missing address", "Expected email sent to %s", to)
+	m.Mailer.AssertSentTo(t, to)
 }
 
 // AssertNotSent asserts that no emails were sent.
+//
+// Deprecated: use AssertNothingSent.
 func (m *FakeMailer) AssertNotSent(t *testing.T) {
 	t.Helper()
-	assert.Empty(t, m.Messages, "Expected no emails to be sent, but %d were sent", len(m.Messages))
+	m.Mailer.AssertNothingSent(t)
 }
@@ -0,0 +1,73 @@
+package validate
+
+import "testing"
+
+var benchBody = map[string]any{
+	"email": " Jane@Example.com ",
+	"name":  "Jane",
+	"code":  "AB-1234",
+	"tags":  []string{"go", "web", "api"},
+}
+
+func benchSchema() []MemberSchema {
+	return []MemberSchema{
+		Member("email", func(f *FieldBuilder) { f.Required().Trim().NormalizeEmail().Email() }),
+		Member("name", func(f *FieldBuilder) { f.Required().MinLength(2).MaxLength(50) }),
+		Member("code", func(f *FieldBuilder) { f.Pattern(`^[A-Z]{2}-\d{4}$`) }),
+		Member("tags", func(f *FieldBuilder) {
+			f.Array().MaxItems(10).Each(func(t *FieldBuilder) { t.Pattern(`^[a-z]+$`) })
+		}),
+	}
+}
+
+// BenchmarkValidatorSet builds the rules on every call, as a handler that
+// declares them inline does.
+func BenchmarkValidatorSet(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		vs := NewValidatorSet()
+		for _, m := range benchSchema() {
+			applySchemas(vs.Field(m.Name, benchBody[m.Name]).field, m.Schemas)
+		}
+		if !vs.Validate().Valid {
+			b.Fatal("expected valid input")
+		}
+	}
+}
+
+func BenchmarkCompiledSchema(b *testing.B) {
+	schema := Compile(benchSchema()...)
+	b.ReportAllocs()
+	for b.Loop() {
+		if !schema.Validate(benchBody).Valid {
+			b.Fatal("expected valid input")
+		}
+	}
+}
+
+func BenchmarkCompiledSchemaParallel(b *testing.B) {
+	schema := Compile(benchSchema()...)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if !schema.Validate(benchBody).Valid {
+				b.Fatal("expected valid input")
+			}
+		}
+	})
+}
+
+func BenchmarkStruct(b *testing.B) {
+	type signup struct {
+		Email string `json:"email" validate:"required,trim,normalize_email,email"`
+		Name  string `json:"name" validate:"required,minlength=2,maxlength=50"`
+		Code  string `json:"code" validate:"pattern=^[A-Z]{2}-\\d{4}$"`
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		in := signup{Email: " Jane@Example.com ", Name: "Jane", Code: "AB-1234"}
+		if !Struct(&in).Valid {
+			b.Fatal("expected valid input")
+		}
+	}
+}
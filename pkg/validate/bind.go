@@ -0,0 +1,108 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Bind copies data into dst, a pointer to a struct. Keys match the fields'
+// json names. Form and query values arrive as strings, so a string is
+// converted for a numeric or boolean field ("42", "true"); other values,
+// nested objects and arrays included, are decoded as encoding/json would
+// decode them.
+func Bind(data map[string]any, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("validate: Bind needs a pointer to a struct")
+	}
+
+	coerced := make(map[string]any, len(data))
+	for k, v := range data {
+		coerced[k] = v
+	}
+	for _, f := range reflect.VisibleFields(rv.Elem().Type()) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := jsonName(f)
+		s, ok := coerced[name].(string)
+		if name == "" || !ok {
+			continue
+		}
+		v, err := coerceString(s, f.Type)
+		if err != nil {
+			return fmt.Errorf("validate: bind %s: %w", name, err)
+		}
+		coerced[name] = v
+	}
+
+	raw, err := json.Marshal(coerced)
+	if err != nil {
+		return fmt.Errorf("validate: bind: %w", err)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("validate: bind %s: cannot use a %s as %s", typeErr.Field, typeErr.Value, typeErr.Type)
+		}
+		return fmt.Errorf("validate: bind: %w", err)
+	}
+	return nil
+}
+
+// Bind copies the result's Data into dst; see Bind. It fails with the
+// validation errors when the input is invalid.
+func (r *ValidationResult) Bind(dst any) error {
+	if err := r.Err(); err != nil {
+		return err
+	}
+	return Bind(r.Data, dst)
+}
+
+// jsonName returns the key encoding/json uses for f, or "" for a field it
+// skips.
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// coerceString converts s for a field of type t, returning s itself when t
+// takes a string or is not a number or boolean.
+func coerceString(s string, t reflect.Type) (any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s = strings.TrimSpace(s)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if s == "" {
+			return nil, nil
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return json.Number(s), nil
+	case reflect.Bool:
+		if s == "" {
+			return nil, nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", s)
+		}
+		return b, nil
+	}
+	return s, nil
+}
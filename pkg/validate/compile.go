@@ -0,0 +1,128 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/shauryagautam/Astra/pkg/support/enum"
+)
+
+// CompiledSchema is an object schema whose rules are built once, by Compile,
+// and reused for every value it validates. A ValidatorSet builds its rules
+// while a request is handled, so patterns are compiled and closures created
+// on each call; a CompiledSchema does that work at startup. It is immutable
+// and safe for concurrent use.
+type CompiledSchema struct {
+	fields     []*Field
+	collectAll bool
+}
+
+// Compile builds the rules of members, including those of nested Each and
+// Members schemas, into a CompiledSchema:
+//
+//	var signup = validate.Compile(
+//		validate.Member("email", func(f *validate.FieldBuilder) { f.Required().Trim().Email() }),
+//		validate.Member("tags", func(f *validate.FieldBuilder) { f.Array().Each(func(t *validate.FieldBuilder) { t.Pattern(`^[a-z]+$`) }) }),
+//	)
+//
+//	result := signup.Validate(body)
+//
+// Like Pattern, it panics on an invalid pattern, so a broken schema fails at
+// startup rather than on the first request.
+func Compile(members ...MemberSchema) *CompiledSchema {
+	cs := &CompiledSchema{fields: make([]*Field, len(members))}
+	for i, m := range members {
+		cs.fields[i] = compileField(m.Name, m.Schemas)
+	}
+	return cs
+}
+
+// CollectAll returns a copy of the schema that reports every failing rule
+// of a field; see ValidatorSet.CollectAll.
+func (cs *CompiledSchema) CollectAll() *CompiledSchema {
+	c := *cs
+	c.collectAll = true
+	return &c
+}
+
+// Validate validates the schema's fields of data. Keys without a schema are
+// left out of the result's Data.
+func (cs *CompiledSchema) Validate(data map[string]any) *ValidationResult {
+	vs := &ValidatorSet{fields: make([]*Field, len(cs.fields)), collectAll: cs.collectAll}
+	for i, f := range cs.fields {
+		vs.fields[i] = f.instance(f.Name, data[f.Name])
+	}
+	return vs.Validate()
+}
+
+// compileField applies schemas to a field without a value, recursing into
+// its element and member schemas.
+func compileField(name string, schemas []Schema) *Field {
+	f := &Field{Name: name}
+	applySchemas(f, schemas)
+	if len(f.items) > 0 {
+		f.itemField = compileField("", f.items)
+	}
+	if len(f.members) > 0 {
+		f.memberFields = make([]*Field, len(f.members))
+		for i, m := range f.members {
+			f.memberFields[i] = compileField(m.Name, m.Schemas)
+		}
+	}
+	return f
+}
+
+// instance returns a copy of the compiled field f that validates value. The
+// copy shares f's rules, which validation never modifies.
+func (f *Field) instance(name string, value any) *Field {
+	c := *f
+	c.Name, c.Value = name, value
+	return &c
+}
+
+// structField is a struct field that Struct validates.
+type structField struct {
+	index int
+	field *Field
+}
+
+var structSchemas sync.Map // map[reflect.Type][]structField
+
+// structSchemaOf parses the validate tags of t once and caches the result.
+func structSchemaOf(t reflect.Type) []structField {
+	if cached, ok := structSchemas.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if !ft.IsExported() {
+			continue
+		}
+
+		// Get field name from JSON tag or field name
+		name := ft.Name
+		if tag := ft.Tag.Get("json"); tag != "" {
+			if parts := strings.Split(tag, ","); len(parts) > 0 && parts[0] != "" {
+				name = parts[0]
+			}
+		}
+
+		fb := &FieldBuilder{field: &Field{Name: name}}
+		if tag := ft.Tag.Get("validate"); tag != "" {
+			fb.parseValidateTag(tag)
+			if strings.Contains(tag, "required") {
+				fb.Required()
+			}
+		}
+		if e, ok := enum.Lookup(ft.Type); ok {
+			fb.Enum(e)
+		}
+		fields = append(fields, structField{index: i, field: fb.field})
+	}
+
+	cached, _ := structSchemas.LoadOrStore(t, fields)
+	return cached.([]structField)
+}
@@ -0,0 +1,91 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RuleFunc checks one value for a registered rule. field is the path of
+// the field being validated, such as "address.zip", and params are the
+// arguments given where the rule is used. The returned error's text is the
+// field's message.
+type RuleFunc func(field string, value any, params []string) error
+
+var (
+	customRulesMu sync.RWMutex
+	customRules   = map[string]RuleFunc{}
+)
+
+// RegisterRule makes a project-specific rule available by name to
+// FieldBuilder.Rule, to validate struct tags and to validators created by
+// New afterwards. Register rules at startup, typically in an init function:
+//
+//	validate.RegisterRule("slug", func(field string, value any, params []string) error {
+//		s, ok := value.(string)
+//		if !ok || !slugRe.MatchString(s) {
+//			return errors.New("must be a slug")
+//		}
+//		return nil
+//	})
+//
+// Tag parameters are separated with "|", as in `validate:"prefix=sku|art"`,
+// except with New's Validator, which reads "|" as "or" and takes
+// space-separated parameters as its oneof does: `validate:"prefix=sku art"`.
+// Built-in tags take precedence over a registered rule of the same name.
+// RegisterRule panics if name is empty, fn is nil or name is already
+// registered.
+func RegisterRule(name string, fn RuleFunc) {
+	if name == "" || fn == nil {
+		panic("validate: RegisterRule needs a name and a function")
+	}
+	customRulesMu.Lock()
+	defer customRulesMu.Unlock()
+	if _, dup := customRules[name]; dup {
+		panic(fmt.Sprintf("validate: rule %q is already registered", name))
+	}
+	customRules[name] = fn
+}
+
+func lookupRule(name string) (RuleFunc, bool) {
+	customRulesMu.RLock()
+	defer customRulesMu.RUnlock()
+	fn, ok := customRules[name]
+	return fn, ok
+}
+
+// Rule adds the registered rule name, passing it params. It panics if no
+// rule of that name is registered, so a misspelt rule fails at startup
+// rather than letting every value through.
+func (fb *FieldBuilder) Rule(name string, params ...string) *FieldBuilder {
+	fn, ok := lookupRule(name)
+	if !ok {
+		panic(fmt.Sprintf("validate: unknown rule %q; register it with RegisterRule", name))
+	}
+	fb.field.Rules = append(fb.field.Rules, &Rule{
+		Name:  name,
+		check: func(field *Field) error { return fn(field.Name, field.Value, params) },
+	})
+	return fb
+}
+
+// registerCustomRules adds the registered rules to v's struct validator, so
+// `validate:"slug"` also works with Validate and ValidateStruct.
+func registerCustomRules(v *validator.Validate) {
+	customRulesMu.RLock()
+	defer customRulesMu.RUnlock()
+	for name, fn := range customRules {
+		_ = v.RegisterValidation(name, func(fl validator.FieldLevel) bool {
+			return fn(fl.FieldName(), fl.Field().Interface(), strings.Fields(fl.Param())) == nil
+		})
+	}
+}
+
+func splitParams(param string) []string {
+	if param == "" {
+		return nil
+	}
+	return strings.Split(param, "|")
+}
@@ -0,0 +1,206 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/shauryagautam/Astra/pkg/support/enum"
+)
+
+// String, Number, Integer, Boolean, Object and Array declare a field of
+// that type as a MemberSchema, so nested payloads can be described without
+// closures:
+//
+//	var createUser = validate.Compile(
+//		validate.String("email").Required().Trim().Email(),
+//		validate.Object("profile").Required().Children(
+//			validate.String("name").Required().MaxLength(100),
+//			validate.Integer("age").Min(13),
+//		),
+//		validate.Array("tags").MaxItems(10).Each(validate.String().MaxLength(20)),
+//	)
+//
+// The name is omitted for array elements. Each method returns a new
+// MemberSchema, so a declaration can be shared and extended safely.
+func String(name ...string) MemberSchema {
+	return declare(name, func(f *FieldBuilder) { f.isString() })
+}
+
+// Number declares a numeric field; see String.
+func Number(name ...string) MemberSchema {
+	return declare(name, func(f *FieldBuilder) { f.Numeric() })
+}
+
+// Integer declares an integer field; see String.
+func Integer(name ...string) MemberSchema {
+	return declare(name, func(f *FieldBuilder) { f.Integer() })
+}
+
+// Boolean declares a boolean field; see String.
+func Boolean(name ...string) MemberSchema {
+	return declare(name, func(f *FieldBuilder) { f.isBoolean() })
+}
+
+// Object declares an object field whose members are declared with Children;
+// see String.
+func Object(name ...string) MemberSchema {
+	return declare(name, func(f *FieldBuilder) { f.Object() })
+}
+
+// Array declares an array field whose elements are declared with Each; see
+// String.
+func Array(name ...string) MemberSchema {
+	return declare(name, func(f *FieldBuilder) { f.Array() })
+}
+
+func declare(name []string, typ Schema) MemberSchema {
+	m := MemberSchema{Schemas: []Schema{typ}}
+	if len(name) > 0 {
+		m.Name = name[0]
+	}
+	return m
+}
+
+// With returns m with schemas added, for rules without a method here.
+func (m MemberSchema) With(schemas ...Schema) MemberSchema {
+	m.Schemas = append(slices.Clip(m.Schemas), schemas...)
+	return m
+}
+
+// Schema returns the rules of m as one Schema, to use with
+// FieldBuilder.Each.
+func (m MemberSchema) Schema() Schema {
+	schemas := m.Schemas
+	return func(f *FieldBuilder) {
+		for _, schema := range schemas {
+			schema(f)
+		}
+	}
+}
+
+// Children validates the listed members of an object; see
+// FieldBuilder.Members. It panics on a child without a name, so a broken
+// schema fails at startup.
+func (m MemberSchema) Children(children ...MemberSchema) MemberSchema {
+	for _, child := range children {
+		if child.Name == "" {
+			panic(fmt.Sprintf("validate: the children of %q must be named", m.Name))
+		}
+	}
+	return m.With(func(f *FieldBuilder) { f.Members(children...) })
+}
+
+// Each validates every element of an array; see FieldBuilder.Each.
+func (m MemberSchema) Each(elements ...MemberSchema) MemberSchema {
+	schemas := make([]Schema, len(elements))
+	for i, e := range elements {
+		schemas[i] = e.Schema()
+	}
+	return m.With(func(f *FieldBuilder) { f.Each(schemas...) })
+}
+
+// The methods below add the FieldBuilder rule or mutator of the same name.
+
+func (m MemberSchema) Required() MemberSchema { return m.With(func(f *FieldBuilder) { f.Required() }) }
+func (m MemberSchema) Optional() MemberSchema { return m.With(func(f *FieldBuilder) { f.Optional() }) }
+func (m MemberSchema) Strict() MemberSchema   { return m.With(func(f *FieldBuilder) { f.Strict() }) }
+
+func (m MemberSchema) Trim() MemberSchema { return m.With(func(f *FieldBuilder) { f.Trim() }) }
+func (m MemberSchema) Lowercase() MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Lowercase() })
+}
+func (m MemberSchema) Escape() MemberSchema { return m.With(func(f *FieldBuilder) { f.Escape() }) }
+func (m MemberSchema) NormalizeEmail() MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.NormalizeEmail() })
+}
+func (m MemberSchema) Mutate(fn Mutator) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Mutate(fn) })
+}
+
+func (m MemberSchema) MinLength(min int) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.MinLength(min) })
+}
+func (m MemberSchema) MaxLength(max int) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.MaxLength(max) })
+}
+func (m MemberSchema) Pattern(pattern string) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Pattern(pattern) })
+}
+func (m MemberSchema) Email() MemberSchema { return m.With(func(f *FieldBuilder) { f.Email() }) }
+func (m MemberSchema) URL() MemberSchema   { return m.With(func(f *FieldBuilder) { f.URL() }) }
+func (m MemberSchema) UUID() MemberSchema  { return m.With(func(f *FieldBuilder) { f.UUID() }) }
+func (m MemberSchema) Alpha() MemberSchema { return m.With(func(f *FieldBuilder) { f.Alpha() }) }
+func (m MemberSchema) AlphaNumeric() MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.AlphaNumeric() })
+}
+func (m MemberSchema) Phone() MemberSchema    { return m.With(func(f *FieldBuilder) { f.Phone() }) }
+func (m MemberSchema) Password() MemberSchema { return m.With(func(f *FieldBuilder) { f.Password() }) }
+func (m MemberSchema) Date() MemberSchema     { return m.With(func(f *FieldBuilder) { f.Date() }) }
+func (m MemberSchema) DateTime() MemberSchema { return m.With(func(f *FieldBuilder) { f.DateTime() }) }
+func (m MemberSchema) JSON(opts ...JSONOption) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.JSON(opts...) })
+}
+
+func (m MemberSchema) Min(min float64) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Min(min) })
+}
+func (m MemberSchema) Max(max float64) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Max(max) })
+}
+
+func (m MemberSchema) In(values ...any) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.In(values...) })
+}
+func (m MemberSchema) NotIn(values ...any) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.NotIn(values...) })
+}
+func (m MemberSchema) OneOf(values ...string) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.OneOf(values...) })
+}
+func (m MemberSchema) Enum(e enum.Enum) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Enum(e) })
+}
+func (m MemberSchema) Rule(name string, params ...string) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Rule(name, params...) })
+}
+func (m MemberSchema) Custom(validator func(any) error, message string) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.Custom(validator, message) })
+}
+
+func (m MemberSchema) MinItems(min int) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.MinItems(min) })
+}
+func (m MemberSchema) MaxItems(max int) MemberSchema {
+	return m.With(func(f *FieldBuilder) { f.MaxItems(max) })
+}
+func (m MemberSchema) Distinct() MemberSchema { return m.With(func(f *FieldBuilder) { f.Distinct() }) }
+
+// isString adds validation that the field is a string.
+func (fb *FieldBuilder) isString() *FieldBuilder {
+	fb.field.Rules = append(fb.field.Rules, &Rule{
+		Name: "string",
+		Validator: func(value any) error {
+			if _, ok := value.(string); !ok {
+				return errNotString
+			}
+			return nil
+		},
+		StopOnFail: true,
+	})
+	return fb
+}
+
+// isBoolean adds validation that the field is a boolean.
+func (fb *FieldBuilder) isBoolean() *FieldBuilder {
+	fb.field.Rules = append(fb.field.Rules, &Rule{
+		Name: "boolean",
+		Validator: func(value any) error {
+			if _, ok := value.(bool); !ok {
+				return errNotBool
+			}
+			return nil
+		},
+		StopOnFail: true,
+	})
+	return fb
+}
@@ -0,0 +1,66 @@
+package validate
+
+import (
+	"html"
+	"strings"
+)
+
+// Mutator transforms a field value before its rules run. The mutated value
+// is what the rules see and what ValidationResult.Data reports.
+type Mutator func(any) any
+
+// stringMutator lifts a string transform into a Mutator that leaves
+// non-string values untouched.
+func stringMutator(fn func(string) string) Mutator {
+	return func(value any) any {
+		if s, ok := value.(string); ok {
+			return fn(s)
+		}
+		return value
+	}
+}
+
+// Mutate adds a custom transformation applied before validation.
+func (fb *FieldBuilder) Mutate(fn Mutator) *FieldBuilder {
+	fb.field.Mutators = append(fb.field.Mutators, fn)
+	return fb
+}
+
+// Trim removes leading and trailing whitespace.
+func (fb *FieldBuilder) Trim() *FieldBuilder {
+	return fb.Mutate(stringMutator(strings.TrimSpace))
+}
+
+// Lowercase converts the value to lower case.
+func (fb *FieldBuilder) Lowercase() *FieldBuilder {
+	return fb.Mutate(stringMutator(strings.ToLower))
+}
+
+// Escape HTML-escapes <, >, &, ' and ".
+func (fb *FieldBuilder) Escape() *FieldBuilder {
+	return fb.Mutate(stringMutator(html.EscapeString))
+}
+
+// NormalizeEmail trims and lowercases an email address. For Gmail addresses
+// it also drops dots and "+tag" suffixes from the local part, since Gmail
+// delivers all of those variants to the same mailbox.
+func (fb *FieldBuilder) NormalizeEmail() *FieldBuilder {
+	return fb.Mutate(stringMutator(normalizeEmail))
+}
+
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		if plus := strings.IndexByte(local, '+'); plus >= 0 {
+			local = local[:plus]
+		}
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TypeError is returned by a rule when the value has the wrong type for it,
+// such as a word given to Min. It is reported as is, instead of the rule's
+// Message, so "must be a number" is not confused with "must be at least 1".
+type TypeError struct {
+	// Want names the expected type: "string", "number", "integer" or
+	// "boolean".
+	Want string
+}
+
+func (e *TypeError) Error() string {
+	if e.Want == "integer" {
+		return "must be an integer"
+	}
+	if e.Want == "boolean" {
+		return "must be true or false"
+	}
+	return "must be a " + e.Want
+}
+
+var (
+	errNotString  = &TypeError{Want: "string"}
+	errNotNumber  = &TypeError{Want: "number"}
+	errNotInteger = &TypeError{Want: "integer"}
+	errNotBool    = &TypeError{Want: "boolean"}
+)
+
+// Strict turns off type coercion for the field's numeric rules: Numeric,
+// Integer, Min and Max then reject numeric strings such as "42" and accept
+// only numbers. By default such strings are parsed, as form and query
+// values always arrive as strings.
+func (fb *FieldBuilder) Strict() *FieldBuilder {
+	fb.field.Strict = true
+	return fb
+}
+
+// number converts value to a float64 for the numeric rules. Numbers of any
+// Go numeric type and json.Number are accepted; strings only when the field
+// is not strict. NaN and infinities are not numbers.
+func (f *Field) number(value any) (float64, error) {
+	var n float64
+	switch v := value.(type) {
+	case string:
+		if f.Strict {
+			return 0, errNotNumber
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, errNotNumber
+		}
+		n = parsed
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return 0, errNotNumber
+		}
+		n = parsed
+	default:
+		rv := reflect.ValueOf(value)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n = float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = rv.Float()
+		default:
+			return 0, errNotNumber
+		}
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, errNotNumber
+	}
+	return n, nil
+}
+
+// isNumber reports whether value has a Go numeric type, so that zero counts
+// as a value rather than as empty.
+func isNumber(value any) bool {
+	if _, ok := value.(json.Number); ok {
+		return true
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
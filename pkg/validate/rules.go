@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/shauryagautam/Astra/pkg/support/enum"
 )
 
 // identRe matches valid SQL identifiers (letters, digits, underscores).
@@ -123,3 +124,17 @@ func afterDateRule(fl validator.FieldLevel) bool {
 
 	return field.After(compareTo)
 }
+
+// enumRule validates that a field of a type declared with enum.New holds
+// one of its values. A type without an enum fails, so a typo in the field
+// type is not silently accepted.
+//
+//	validate:"enum"
+func enumRule(fl validator.FieldLevel) bool {
+	e, ok := enum.Lookup(fl.Field().Type())
+	if !ok {
+		return false
+	}
+	s, ok := enum.Value(fl.Field().Interface())
+	return ok && e.Contains(s)
+}
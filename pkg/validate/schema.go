@@ -0,0 +1,234 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Schema configures the validation of a nested value (an array element or
+// an object member) with the same fluent API used for top-level fields.
+type Schema func(fb *FieldBuilder)
+
+// MemberSchema describes one key of an object field.
+type MemberSchema struct {
+	Name    string
+	Schemas []Schema
+}
+
+// Member declares a key of an object field and how to validate it:
+//
+//	vs.Field("address", body["address"]).Required().Members(
+//		validate.Member("city", func(f *validate.FieldBuilder) { f.Required().Trim() }),
+//		validate.Member("zip", func(f *validate.FieldBuilder) { f.Pattern(`^\d{5}$`) }),
+//	)
+func Member(name string, schemas ...Schema) MemberSchema {
+	return MemberSchema{Name: name, Schemas: schemas}
+}
+
+// Array adds validation that the field is a slice or array
+func (fb *FieldBuilder) Array() *FieldBuilder {
+	rule := &Rule{
+		Name: "array",
+		Validator: func(value any) error {
+			if _, ok := arrayValue(value); !ok {
+				return fmt.Errorf("must be an array")
+			}
+			return nil
+		},
+		Message:    "must be an array",
+		StopOnFail: true,
+	}
+	fb.field.Rules = append(fb.field.Rules, rule)
+	return fb
+}
+
+// Object adds validation that the field is an object with string keys
+func (fb *FieldBuilder) Object() *FieldBuilder {
+	rule := &Rule{
+		Name: "object",
+		Validator: func(value any) error {
+			if _, ok := objectValue(value); !ok {
+				return fmt.Errorf("must be an object")
+			}
+			return nil
+		},
+		Message:    "must be an object",
+		StopOnFail: true,
+	}
+	fb.field.Rules = append(fb.field.Rules, rule)
+	return fb
+}
+
+// MinItems adds minimum array length validation
+func (fb *FieldBuilder) MinItems(min int) *FieldBuilder {
+	rule := &Rule{
+		Name: "min_items",
+		Validator: func(value any) error {
+			rv, ok := arrayValue(value)
+			if !ok {
+				return fmt.Errorf("must be an array")
+			}
+			if rv.Len() < min {
+				return fmt.Errorf("must contain at least %d items", min)
+			}
+			return nil
+		},
+		Message: fmt.Sprintf("must contain at least %d items", min),
+	}
+	fb.field.Rules = append(fb.field.Rules, rule)
+	return fb
+}
+
+// MaxItems adds maximum array length validation
+func (fb *FieldBuilder) MaxItems(max int) *FieldBuilder {
+	rule := &Rule{
+		Name: "max_items",
+		Validator: func(value any) error {
+			rv, ok := arrayValue(value)
+			if !ok {
+				return fmt.Errorf("must be an array")
+			}
+			if rv.Len() > max {
+				return fmt.Errorf("must contain at most %d items", max)
+			}
+			return nil
+		},
+		Message: fmt.Sprintf("must contain at most %d items", max),
+	}
+	fb.field.Rules = append(fb.field.Rules, rule)
+	return fb
+}
+
+// Distinct adds validation that array elements are unique
+func (fb *FieldBuilder) Distinct() *FieldBuilder {
+	rule := &Rule{
+		Name: "distinct",
+		Validator: func(value any) error {
+			rv, ok := arrayValue(value)
+			if !ok {
+				return fmt.Errorf("must be an array")
+			}
+			seen := make(map[any]struct{}, rv.Len())
+			for i := range rv.Len() {
+				item := rv.Index(i)
+				var key any = fmt.Sprintf("%#v", item.Interface())
+				if item.Comparable() {
+					key = item.Interface()
+				}
+				if _, dup := seen[key]; dup {
+					return fmt.Errorf("must not contain duplicate items")
+				}
+				seen[key] = struct{}{}
+			}
+			return nil
+		},
+		Message: "must not contain duplicate items",
+	}
+	fb.field.Rules = append(fb.field.Rules, rule)
+	return fb
+}
+
+// Each validates every element of an array field against schemas. Element
+// errors are reported under indexed paths such as "tags.2", and mutated
+// elements are written back into the validated output.
+func (fb *FieldBuilder) Each(schemas ...Schema) *FieldBuilder {
+	fb.field.items = append(fb.field.items, schemas...)
+	return fb
+}
+
+// Members validates the listed keys of an object field. Member errors are
+// reported under dotted paths such as "address.city"; keys without a schema
+// are passed through unchanged.
+func (fb *FieldBuilder) Members(members ...MemberSchema) *FieldBuilder {
+	fb.field.members = append(fb.field.members, members...)
+	return fb
+}
+
+// validateItems runs the element schemas of field and returns the array with
+// mutated elements, keeping its element type where possible.
+func (vs *ValidatorSet) validateItems(field *Field) any {
+	rv, ok := arrayValue(field.Value)
+	if !ok {
+		return field.Value
+	}
+	elemType := rv.Type().Elem()
+	out := reflect.MakeSlice(reflect.SliceOf(elemType), rv.Len(), rv.Len())
+	for i := range rv.Len() {
+		name := field.Name + "." + strconv.Itoa(i)
+		var item *Field
+		if field.itemField != nil {
+			item = field.itemField.instance(name, rv.Index(i).Interface())
+		} else {
+			item = &Field{Name: name, Value: rv.Index(i).Interface()}
+			applySchemas(item, field.items)
+		}
+
+		mutated := reflect.ValueOf(vs.validateField(item))
+		if mutated.IsValid() && mutated.Type().AssignableTo(elemType) {
+			out.Index(i).Set(mutated)
+		} else {
+			out.Index(i).Set(rv.Index(i))
+		}
+	}
+	return out.Interface()
+}
+
+// validateMembers runs the member schemas of field and returns the object
+// with mutated members.
+func (vs *ValidatorSet) validateMembers(field *Field) any {
+	rv, ok := objectValue(field.Value)
+	if !ok {
+		return field.Value
+	}
+	out := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		out[iter.Key().String()] = iter.Value().Interface()
+	}
+	if field.memberFields != nil {
+		for _, m := range field.memberFields {
+			value, present := out[m.Name]
+			vs.validateMember(out, m.Name, present, m.instance(field.Name+"."+m.Name, value))
+		}
+		return out
+	}
+	for _, m := range field.members {
+		value, present := out[m.Name]
+		member := &Field{Name: field.Name + "." + m.Name, Value: value}
+		applySchemas(member, m.Schemas)
+		vs.validateMember(out, m.Name, present, member)
+	}
+	return out
+}
+
+// validateMember validates one member and stores its mutated value in out,
+// without adding keys the object did not have.
+func (vs *ValidatorSet) validateMember(out map[string]any, key string, present bool, member *Field) {
+	if mutated := vs.validateField(member); present || mutated != nil {
+		out[key] = mutated
+	}
+}
+
+func applySchemas(field *Field, schemas []Schema) {
+	fb := &FieldBuilder{field: field}
+	for _, schema := range schemas {
+		schema(fb)
+	}
+}
+
+func arrayValue(value any) (reflect.Value, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return rv, false
+	}
+	return rv, true
+}
+
+func objectValue(value any) (reflect.Value, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return rv, false
+	}
+	return rv, true
+}
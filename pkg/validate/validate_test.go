@@ -1,9 +1,13 @@
 package validate
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/shauryagautam/Astra/pkg/support/enum"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -63,8 +67,437 @@ func TestAfterDateRule(t *testing.T) {
 	})
 }
 
-func TestToSnakeCase(t *testing.T) {
-	assert.Equal(t, "user_id", toSnakeCase("UserID"))
-	assert.Equal(t, "name", toSnakeCase("Name"))
-	assert.Equal(t, "first_name", toSnakeCase("FirstName"))
+func TestFieldNamesAreSnakeCase(t *testing.T) {
+	type input struct {
+		UserID        string `validate:"required"`
+		FirstName     string `validate:"required"`
+		HTTPServerURL string `validate:"required"`
+	}
+	err := New().ValidateStruct(input{})
+	var ve *ValidationErrors
+	require.ErrorAs(t, err, &ve)
+	assert.Contains(t, ve.Fields, "user_id")
+	assert.Contains(t, ve.Fields, "first_name")
+	assert.Contains(t, ve.Fields, "http_server_url")
+	assert.Equal(t, []string{"http_server_url is required"}, ve.Fields["http_server_url"])
+}
+
+func TestFieldMutators(t *testing.T) {
+	vs := NewValidatorSet()
+	vs.Field("name", "  Astra  ").Trim().MinLength(5)
+	vs.Field("email", " John.Doe+news@GoogleMail.com ").NormalizeEmail().Email()
+	vs.Field("bio", "<b>hi</b>").Escape()
+	vs.Field("handle", "ASTRA").Lowercase().OneOf("astra")
+
+	res := vs.Validate()
+	require.True(t, res.Valid, res.Errors)
+	assert.Equal(t, "Astra", res.Data["name"])
+	assert.Equal(t, "johndoe@gmail.com", res.Data["email"])
+	assert.Equal(t, "&lt;b&gt;hi&lt;/b&gt;", res.Data["bio"])
+	assert.Equal(t, "astra", res.Data["handle"])
+
+	t.Run("Trimmed to empty fails required", func(t *testing.T) {
+		vs := NewValidatorSet()
+		vs.Field("name", "   ").Trim().Required()
+		assert.False(t, vs.Validate().Valid)
+	})
+}
+
+func TestStructMutatorTags(t *testing.T) {
+	type signup struct {
+		Email string `json:"email" validate:"trim,normalize_email,email"`
+		Name  string `json:"name" validate:"trim,minlength=2"`
+	}
+
+	in := signup{Email: "  Jane@Example.COM ", Name: " Jo "}
+	res := Struct(&in)
+	require.True(t, res.Valid, res.Errors)
+	assert.Equal(t, "jane@example.com", in.Email)
+	assert.Equal(t, "Jo", in.Name)
+}
+
+func TestNestedSchemas(t *testing.T) {
+	minLen := func(n int) Schema {
+		return func(f *FieldBuilder) { f.Trim().MinLength(n) }
+	}
+
+	t.Run("Array elements", func(t *testing.T) {
+		vs := NewValidatorSet()
+		vs.Field("tags", []string{" go ", "web", "x"}).MinItems(1).MaxItems(5).Distinct().Each(minLen(2))
+
+		res := vs.Validate()
+		assert.False(t, res.Valid)
+		assert.Equal(t, map[string]string{"tags.2": "must be at least 2 characters"}, res.Errors)
+		assert.Equal(t, []string{"go", "web", "x"}, res.Data["tags"])
+	})
+
+	t.Run("Array bounds and duplicates", func(t *testing.T) {
+		vs := NewValidatorSet()
+		vs.Field("few", []any{"a"}).Array().MinItems(2)
+		vs.Field("many", []int{1, 2, 3}).MaxItems(2)
+		vs.Field("dupes", []any{"a", "b", "a"}).Distinct()
+
+		res := vs.Validate()
+		assert.Equal(t, "must contain at least 2 items", res.Errors["few"])
+		assert.Equal(t, "must contain at most 2 items", res.Errors["many"])
+		assert.Equal(t, "must not contain duplicate items", res.Errors["dupes"])
+	})
+
+	t.Run("Object members", func(t *testing.T) {
+		address := map[string]any{
+			"city":  "  Pune ",
+			"lines": []any{"221B", ""},
+			"extra": true,
+		}
+		vs := NewValidatorSet()
+		vs.Field("address", address).Object().Members(
+			Member("city", minLen(3)),
+			Member("zip", func(f *FieldBuilder) { f.Required() }),
+			Member("lines", func(f *FieldBuilder) {
+				f.Each(func(line *FieldBuilder) { line.Required() })
+			}),
+		)
+
+		res := vs.Validate()
+		assert.Equal(t, map[string]string{
+			"address.zip":     "address.zip is required",
+			"address.lines.1": "address.lines.1 is required",
+		}, res.Errors)
+		out := res.Data["address"].(map[string]any)
+		assert.Equal(t, "Pune", out["city"])
+		assert.Equal(t, true, out["extra"])
+	})
+
+	t.Run("Type checks", func(t *testing.T) {
+		vs := NewValidatorSet()
+		vs.Field("tags", "go").Array().Each(minLen(2))
+		vs.Field("address", []string{"x"}).Object()
+
+		res := vs.Validate()
+		assert.Equal(t, "must be an array", res.Errors["tags"])
+		assert.Equal(t, "must be an object", res.Errors["address"])
+	})
+}
+
+func TestCompile(t *testing.T) {
+	signup := Compile(
+		Member("email", func(f *FieldBuilder) { f.Required().Trim().NormalizeEmail().Email() }),
+		Member("tags", func(f *FieldBuilder) {
+			f.Array().Each(func(tag *FieldBuilder) { tag.Trim().Pattern(`^[a-z]+$`) })
+		}),
+		Member("address", func(f *FieldBuilder) {
+			f.Object().Members(Member("zip", func(z *FieldBuilder) { z.Required().Pattern(`^\d{5}$`) }))
+		}),
+	)
+
+	res := signup.Validate(map[string]any{
+		"email":   " Jane@Example.com ",
+		"tags":    []string{" go ", "Web"},
+		"address": map[string]any{"zip": "1234"},
+		"admin":   true,
+	})
+	assert.Equal(t, map[string]string{
+		"tags.1":      "must match pattern ^[a-z]+$",
+		"address.zip": `must match pattern ^\d{5}$`,
+	}, res.Errors)
+	assert.Equal(t, "jane@example.com", res.Data["email"])
+	assert.Equal(t, []string{"go", "Web"}, res.Data["tags"])
+	assert.NotContains(t, res.Data, "admin")
+
+	// Results do not leak into the next validation.
+	res = signup.Validate(map[string]any{"tags": []string{"go"}, "address": map[string]any{"zip": "12345"}})
+	assert.Equal(t, map[string]string{"email": "email is required"}, res.Errors)
+
+	res = signup.Validate(map[string]any{"email": "a@b.io"})
+	assert.True(t, res.Valid, res.Errors)
+
+	assert.Panics(t, func() {
+		Compile(Member("code", func(f *FieldBuilder) { f.Pattern(`[`) }))
+	})
+}
+
+func TestDeclarativeSchemas(t *testing.T) {
+	signup := Compile(
+		String("email").Required().Trim().Email(),
+		Object("profile").Required().Children(
+			String("name").Required().Trim().MaxLength(10),
+			Integer("age").Min(13),
+			Boolean("newsletter"),
+		),
+		Array("tags").MaxItems(3).Each(String().Trim().MaxLength(5)),
+	)
+
+	res := signup.Validate(map[string]any{
+		"email":   "jane@example.com",
+		"profile": map[string]any{"name": " Jane ", "age": 12, "newsletter": "yes"},
+		"tags":    []any{" go ", "javascript", 7},
+	})
+	assert.Equal(t, map[string]string{
+		"profile.age":        "must be at least 13",
+		"profile.newsletter": "must be true or false",
+		"tags.1":             "must be at most 5 characters",
+		"tags.2":             "must be a string",
+	}, res.Errors)
+	assert.Equal(t, "Jane", res.Data["profile"].(map[string]any)["name"])
+	assert.Equal(t, []any{"go", "javascript", 7}, res.Data["tags"])
+
+	res = signup.Validate(map[string]any{"email": "jane@example.com", "profile": "Jane"})
+	assert.Equal(t, map[string]string{"profile": "must be an object"}, res.Errors)
+
+	res = signup.Validate(map[string]any{"email": "jane@example.com", "profile": map[string]any{"name": "Jane"}})
+	assert.True(t, res.Valid, res.Errors)
+
+	// Declarations are values: extending one leaves the original alone.
+	name := String("name")
+	_ = name.Required()
+	assert.True(t, Compile(name).Validate(map[string]any{}).Valid)
+
+	assert.Panics(t, func() { Object("profile").Children(String()) })
+}
+
+func TestRegisterRule(t *testing.T) {
+	RegisterRule("test_prefix", func(field string, value any, params []string) error {
+		s, ok := value.(string)
+		if !ok {
+			return errNotString
+		}
+		for _, p := range params {
+			if strings.HasPrefix(s, p) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must start with %s", field, strings.Join(params, " or "))
+	})
+	assert.Panics(t, func() { RegisterRule("test_prefix", func(string, any, []string) error { return nil }) })
+
+	vs := NewValidatorSet()
+	vs.Field("sku", "x-1").Rule("test_prefix", "sku-", "art-")
+	vs.Field("codes", []any{"sku-1", "y"}).Each(func(f *FieldBuilder) { f.Rule("test_prefix", "sku-") })
+	res := vs.Validate()
+	assert.Equal(t, map[string]string{
+		"sku":     "sku must start with sku- or art-",
+		"codes.1": "codes.1 must start with sku-",
+	}, res.Errors)
+
+	res = Compile(String("sku").Rule("test_prefix", "sku-")).Validate(map[string]any{"sku": "sku-9"})
+	assert.True(t, res.Valid, res.Errors)
+
+	type product struct {
+		SKU string `json:"sku" validate:"required,test_prefix=sku-|art-"`
+	}
+	assert.Equal(t, "sku must start with sku- or art-", Struct(&product{SKU: "x"}).Errors["sku"])
+	assert.True(t, Struct(&product{SKU: "art-1"}).Valid)
+	type legacy struct {
+		SKU string `validate:"required,test_prefix=sku- art-"`
+	}
+	assert.Error(t, New().Validate(legacy{SKU: "x"}))
+	assert.NoError(t, New().Validate(legacy{SKU: "art-1"}))
+
+	assert.Panics(t, func() { NewValidatorSet().Field("a", "b").Rule("missing") })
+}
+
+func TestCollectAllAndBind(t *testing.T) {
+	password := func(f *FieldBuilder) { f.MinLength(8).Pattern(`[0-9]`) }
+
+	vs := NewValidatorSet()
+	password(vs.Field("password", "abc"))
+	res := vs.Validate()
+	assert.Equal(t, map[string][]string{"password": {"must be at least 8 characters"}}, res.AllErrors)
+
+	vs = NewValidatorSet().CollectAll()
+	password(vs.Field("password", "abc"))
+	vs.Field("age", "old").Integer().Min(18)
+	res = vs.Validate()
+	assert.Equal(t, map[string][]string{
+		"password": {"must be at least 8 characters", "must match pattern [0-9]"},
+		"age":      {"must be an integer"},
+	}, res.AllErrors)
+	assert.Equal(t, "must be at least 8 characters", res.Errors["password"])
+
+	var ve *ValidationErrors
+	require.ErrorAs(t, res.Err(), &ve)
+	assert.Len(t, ve.Fields["password"], 2)
+
+	type signup struct {
+		Email  string `json:"email"`
+		Age    int    `json:"age"`
+		Terms  *bool  `json:"terms"`
+		Ignore string `json:"-"`
+	}
+	schema := Compile(
+		String("email").Required().Trim().Lowercase().Email(),
+		Integer("age").Min(18),
+		Boolean("terms").Optional(),
+	)
+	var dst signup
+	err := New().ValidateInto(map[string]any{"email": " Ada@Example.com ", "age": "42", "extra": 1}, schema, &dst)
+	require.NoError(t, err)
+	assert.Equal(t, signup{Email: "ada@example.com", Age: 42}, dst)
+
+	require.NoError(t, Bind(map[string]any{"age": " 7 ", "terms": "true"}, &dst))
+	assert.Equal(t, 7, dst.Age)
+	require.NotNil(t, dst.Terms)
+	assert.True(t, *dst.Terms)
+	assert.ErrorContains(t, Bind(map[string]any{"age": "many"}, &dst), `validate: bind age: "many" is not a number`)
+	assert.ErrorContains(t, Bind(map[string]any{"age": "4.5"}, &dst), "validate: bind age")
+	assert.Error(t, Bind(nil, dst))
+
+	err = New(WithCollectAll()).ValidateInto(map[string]any{"age": "12"}, schema, &dst)
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, map[string][]string{"email": {"email is required"}, "age": {"must be at least 18"}}, ve.Fields)
+}
+
+type plan string
+
+var plans = enum.New[plan]("free", "pro")
+
+func TestEnum(t *testing.T) {
+	res := Compile(String("plan").Required().Enum(plans)).Validate(map[string]any{"plan": "gold"})
+	assert.Equal(t, "must be one of: free, pro", res.Errors["plan"])
+
+	vs := NewValidatorSet()
+	vs.Field("plan", plan("pro")).Enum(plans)
+	vs.Field("count", 3).Enum(plans)
+	res = vs.Validate()
+	assert.Equal(t, map[string]string{"count": "must be a string"}, res.Errors)
+
+	// Fields of an enum type are checked without a tag.
+	type account struct {
+		Plan   plan  `json:"plan"`
+		Backup *plan `json:"backup" validate:"enum"`
+	}
+	gold := plan("gold")
+	assert.Equal(t, map[string]string{"backup": "must be one of: free, pro"}, Struct(&account{Plan: "free", Backup: &gold}).Errors)
+	assert.Equal(t, "must be one of: free, pro", Struct(&account{Plan: "gold"}).Errors["plan"])
+	assert.True(t, Struct(&account{}).Valid, "an empty optional field is not checked")
+
+	type legacy struct {
+		Plan plan `validate:"enum"`
+	}
+	assert.NoError(t, New().Validate(legacy{Plan: "pro"}))
+	assert.Error(t, New().Validate(legacy{Plan: "gold"}))
+}
+
+func TestNumericRules(t *testing.T) {
+	validate := func(value any, build func(f *FieldBuilder)) string {
+		vs := NewValidatorSet()
+		build(vs.Field("n", value))
+		return vs.Validate().Errors["n"]
+	}
+	atLeastOne := func(f *FieldBuilder) { f.Min(1) }
+
+	t.Run("Zero and negatives are values", func(t *testing.T) {
+		assert.Equal(t, "must be at least 1", validate(0, atLeastOne))
+		assert.Equal(t, "must be at least 1", validate(-3, atLeastOne))
+		assert.Equal(t, "must be at least 1", validate(0.0, atLeastOne))
+		assert.Empty(t, validate(0, func(f *FieldBuilder) { f.Required().Min(-5).Max(0) }))
+		assert.Equal(t, "must be at most -1", validate(int64(0), func(f *FieldBuilder) { f.Max(-1) }))
+	})
+
+	t.Run("Required accepts zero", func(t *testing.T) {
+		required := func(f *FieldBuilder) { f.Required() }
+		assert.Empty(t, validate(0, required))
+		assert.Empty(t, validate(0.0, required))
+		assert.Empty(t, validate(json.Number("0"), required))
+		assert.NotEmpty(t, validate(nil, required))
+		assert.NotEmpty(t, validate("", required))
+		assert.Equal(t, "must be at least 1", validate(0, func(f *FieldBuilder) { f.Required().Min(1) }))
+	})
+
+	t.Run("Any numeric type", func(t *testing.T) {
+		for _, v := range []any{int8(2), uint(2), int64(2), float32(2), json.Number("2")} {
+			assert.Empty(t, validate(v, func(f *FieldBuilder) { f.Min(1).Max(2).Integer().Numeric() }), "%T", v)
+		}
+	})
+
+	t.Run("Coercive by default", func(t *testing.T) {
+		assert.Empty(t, validate("42", func(f *FieldBuilder) { f.Min(1).Max(100).Integer() }))
+		assert.Equal(t, "must be at most 10", validate(" 42 ", func(f *FieldBuilder) { f.Max(10) }))
+		assert.Equal(t, "must be a number", validate("abc", atLeastOne))
+		assert.Equal(t, "must be a number", validate("NaN", atLeastOne))
+		assert.Equal(t, "must be an integer", validate("4.5", func(f *FieldBuilder) { f.Integer() }))
+		assert.Equal(t, "must be an integer", validate(4.5, func(f *FieldBuilder) { f.Integer() }))
+		assert.Empty(t, validate(float64(4), func(f *FieldBuilder) { f.Integer() }))
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		assert.Equal(t, "must be a number", validate("42", func(f *FieldBuilder) { f.Min(1).Strict() }))
+		assert.Equal(t, "must be an integer", validate("42", func(f *FieldBuilder) { f.Strict().Integer() }))
+		assert.Equal(t, "must be a number", validate(true, func(f *FieldBuilder) { f.Strict().Numeric() }))
+		assert.Empty(t, validate(42.0, func(f *FieldBuilder) { f.Strict().Min(1).Integer() }))
+	})
+
+	t.Run("Type errors are reported distinctly", func(t *testing.T) {
+		assert.Equal(t, "must be a string", validate(12, func(f *FieldBuilder) { f.MinLength(3) }))
+		assert.Equal(t, "must be at least 3 characters", validate("ab", func(f *FieldBuilder) { f.MinLength(3) }))
+	})
+
+	t.Run("Struct tags", func(t *testing.T) {
+		type order struct {
+			Quantity int    `json:"quantity" validate:"min=1"`
+			Code     string `json:"code" validate:"strict,numeric"`
+		}
+		res := Struct(&order{Quantity: 0, Code: "7"})
+		assert.Equal(t, map[string]string{
+			"quantity": "must be at least 1",
+			"code":     "must be a number",
+		}, res.Errors)
+	})
+}
+
+func TestJSONRule(t *testing.T) {
+	check := func(value any, opts ...JSONOption) string {
+		vs := NewValidatorSet()
+		vs.Field("doc", value).JSON(opts...)
+		return vs.Validate().Errors["doc"]
+	}
+
+	assert.Empty(t, check(`{"a":1}`))
+	assert.Empty(t, check(` [1, 2] `))
+	assert.Equal(t, "must be valid JSON", check(`{"a":}`))
+	assert.Equal(t, "must be valid JSON", check(`{a}`))
+	assert.Equal(t, "must be a JSON object or array", check(`42`))
+	assert.Equal(t, "must be a string", check(42))
+	assert.Empty(t, check(`42`, JSONScalars))
+	assert.Empty(t, check(`"hi"`, JSONScalars))
+
+	t.Run("Decoded value feeds later rules", func(t *testing.T) {
+		vs := NewValidatorSet()
+		vs.Field("settings", `{"theme":"blue","tags":["a","a"]}`).JSON(JSONDecode).Object().Members(
+			Member("theme", func(f *FieldBuilder) { f.OneOf("light", "dark") }),
+			Member("tags", func(f *FieldBuilder) { f.Distinct() }),
+		)
+		vs.Field("count", `3`).JSON(JSONScalars, JSONDecode).Integer().Max(2)
+
+		res := vs.Validate()
+		assert.Equal(t, map[string]string{
+			"settings.theme": "must be one of: light, dark",
+			"settings.tags":  "must not contain duplicate items",
+			"count":          "must be at most 2",
+		}, res.Errors)
+		assert.Equal(t, map[string]any{"theme": "blue", "tags": []any{"a", "a"}}, res.Data["settings"])
+		assert.Equal(t, float64(3), res.Data["count"])
+	})
+
+	t.Run("Struct tag", func(t *testing.T) {
+		type form struct {
+			Meta  string `json:"meta" validate:"json=decode"`
+			Score string `json:"score" validate:"json"`
+		}
+		res := Struct(&form{Meta: `{"x":true}`, Score: `7`})
+		assert.Equal(t, map[string]string{"score": "must be a JSON object or array"}, res.Errors)
+		assert.Equal(t, map[string]any{"x": true}, res.Data["meta"])
+	})
+}
+
+func TestStructIgnoresUnknownTags(t *testing.T) {
+	type signup struct {
+		Nickname string   `json:"nickname" validate:"omitempty,len=5"`
+		Age      int      `json:"age" validate:"gte=18"`
+		Tags     []string `json:"tags" validate:"dive,required"`
+	}
+	var res *ValidationResult
+	assert.NotPanics(t, func() { res = Struct(&signup{Age: 12, Tags: []string{"go"}}) })
+	assert.True(t, res.Valid, "go-playground tags are left to ValidateStruct")
+	assert.Error(t, New().Validate(signup{Nickname: "ab", Age: 12}))
 }
@@ -3,16 +3,21 @@ package validate
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/shauryagautam/Astra/pkg/support/enum"
+	"github.com/shauryagautam/Astra/pkg/support/str"
 )
 
 // DBExecutor is the minimal interface the validator needs to run DB-backed rules.
@@ -39,6 +44,15 @@ type DBRow interface {
 	Scan(dest ...any) error
 }
 
+// The patterns of the built-in format rules, compiled once rather than every
+// time a rule is added.
+var (
+	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	urlRegex   = regexp.MustCompile(`^https?://[^\s/$.?#].[^\s]*$`)
+	uuidRegex  = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[1-5][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	phoneRegex = regexp.MustCompile(`^\+?[1-9]\d{1,14}$`)
+)
+
 // MessageFormatter is a function that formats a field error into a human-readable string.
 // It can optionally take a locale for i18n support.
 type MessageFormatter func(fe validator.FieldError, locale ...string) string
@@ -65,11 +79,18 @@ func WithCustomRule(tag string, fn validator.Func) ValidatorOption {
 	}
 }
 
+// WithCollectAll makes ValidateInto report every failing rule of a field
+// rather than only the first; see ValidatorSet.CollectAll.
+func WithCollectAll() ValidatorOption {
+	return func(v *Validator) { v.collectAll = true }
+}
+
 // Validator wraps go-playground/validator with custom configurations.
 type Validator struct {
-	v      *validator.Validate
-	db     DBExecutor
-	msgFmt MessageFormatter
+	v          *validator.Validate
+	db         DBExecutor
+	msgFmt     MessageFormatter
+	collectAll bool
 }
 
 // New creates a new Validator. Pass options to configure it:
@@ -90,6 +111,8 @@ func New(opts ...ValidatorOption) *Validator {
 
 	// Register built-in rules.
 	_ = v.v.RegisterValidation("after_date", afterDateRule)
+	_ = v.v.RegisterValidation("enum", enumRule)
+	registerCustomRules(v.v)
 
 	// Register DB rules only if a DB was provided.
 	if v.db != nil {
@@ -105,6 +128,22 @@ func (v *Validator) Validate(s any) error {
 	return v.v.Struct(s)
 }
 
+// ValidateInto validates data against schema and binds the validated
+// values, mutators applied, into dst, a pointer to a struct; see Bind. It
+// returns *ValidationErrors when data is invalid, and dst is left
+// untouched:
+//
+//	var req CreateUserRequest
+//	if err := v.ValidateInto(c.All(), createUser, &req); err != nil {
+//		return err
+//	}
+func (v *Validator) ValidateInto(data map[string]any, schema *CompiledSchema, dst any) error {
+	if v.collectAll {
+		schema = schema.CollectAll()
+	}
+	return schema.Validate(data).Bind(dst)
+}
+
 // BindAndValidate decodes the request body and validates the struct.
 func (v *Validator) BindAndValidate(r *http.Request, val any) error {
 	if err := json.NewDecoder(r.Body).Decode(val); err != nil {
@@ -133,7 +172,7 @@ func (v *Validator) ValidateStruct(obj any, locale ...string) error {
 
 	ve := NewValidationErrors()
 	for _, fe := range validationErrors {
-		field := toSnakeCase(fe.Field())
+		field := str.Snake(fe.Field())
 		msg := v.msgFmt(fe, lang)
 		ve.Add(field, msg)
 	}
@@ -142,7 +181,7 @@ func (v *Validator) ValidateStruct(obj any, locale ...string) error {
 }
 
 func formatMessage(fe validator.FieldError, locale ...string) string {
-	field := toSnakeCase(fe.Field())
+	field := str.Snake(fe.Field())
 	switch fe.Tag() {
 	case "required":
 		return fmt.Sprintf("%s is required", field)
@@ -165,26 +204,31 @@ func formatMessage(fe validator.FieldError, locale ...string) string {
 	}
 }
 
-func toSnakeCase(s string) string {
-	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			prev := s[i-1]
-			if prev < 'A' || prev > 'Z' {
-				result.WriteByte('_')
-			}
-		}
-		result.WriteRune(r)
-	}
-	return strings.ToLower(result.String())
-}
-
 // ─── Fluent Validator Set (Logic from validator_new.go) ──────────────
 
 // ValidationResult represents the result of validation
 type ValidationResult struct {
-	Valid  bool              `json:"valid"`
+	Valid bool `json:"valid"`
+	// Errors holds the first failure of each field.
 	Errors map[string]string `json:"errors"`
+	// AllErrors holds every failure of each field: one in the default bail
+	// mode, and each failing rule's message with CollectAll.
+	AllErrors map[string][]string `json:"-"`
+	// Data holds every field's value after its mutators ran.
+	Data map[string]any `json:"data"`
+}
+
+// Err returns the failures as *ValidationErrors, or nil when the input is
+// valid.
+func (r *ValidationResult) Err() error {
+	if r.Valid {
+		return nil
+	}
+	ve := NewValidationErrors()
+	for field, messages := range r.AllErrors {
+		ve.Fields[field] = messages
+	}
+	return ve
 }
 
 // CustomValidator interface for custom validators (renamed to avoid conflict)
@@ -198,6 +242,13 @@ type Rule struct {
 	Validator  func(any) error
 	Message    string
 	StopOnFail bool
+
+	// parse, when set, replaces Validator and its result becomes the field
+	// value for the rules that follow.
+	parse func(any) (any, error)
+	// check, when set, replaces Validator for rules that need the field's
+	// name, such as those added with FieldBuilder.Rule.
+	check func(field *Field) error
 }
 
 // Field represents a field to be validated
@@ -205,14 +256,27 @@ type Field struct {
 	Name     string
 	Value    any
 	Rules    []*Rule
+	Mutators []Mutator
 	Required bool
 	Optional bool
+	// Strict disables string coercion in the numeric rules; see Strict.
+	Strict bool
+
+	items   []Schema
+	members []MemberSchema
+
+	// itemField and memberFields are the compiled forms of items and
+	// members, set by Compile so nested schemas are not rebuilt per value.
+	itemField    *Field
+	memberFields []*Field
 }
 
 // ValidatorSet represents a collection of validation rules
 type ValidatorSet struct {
-	fields []*Field
-	errors map[string]string
+	fields     []*Field
+	errors     map[string]string
+	allErrors  map[string][]string
+	collectAll bool
 }
 
 // NewValidatorSet creates a new validator set
@@ -233,49 +297,105 @@ func (vs *ValidatorSet) Field(name string, value any) *FieldBuilder {
 	return &FieldBuilder{field: field}
 }
 
+// CollectAll runs every rule of a field and reports each failure in
+// AllErrors. By default a field bails out at its first failing rule. Rules
+// that guard the others, such as Array, and a value of the wrong type stop
+// a field in either mode.
+func (vs *ValidatorSet) CollectAll() *ValidatorSet {
+	vs.collectAll = true
+	return vs
+}
+
 // Validate runs all validations
 func (vs *ValidatorSet) Validate() *ValidationResult {
 	vs.errors = make(map[string]string)
+	vs.allErrors = make(map[string][]string)
+	data := make(map[string]any, len(vs.fields))
 
 	for _, field := range vs.fields {
-		// Check if field is required but empty
-		if field.Required && vs.isEmpty(field.Value) {
-			vs.errors[field.Name] = fmt.Sprintf("%s is required", field.Name)
-			continue
-		}
+		data[field.Name] = vs.validateField(field)
+	}
 
-		// Skip validation if field is optional and empty
-		if field.Optional && vs.isEmpty(field.Value) {
-			continue
-		}
+	return &ValidationResult{
+		Valid:     len(vs.errors) == 0,
+		Errors:    vs.errors,
+		AllErrors: vs.allErrors,
+		Data:      data,
+	}
+}
 
-		// Skip validation if field is empty and not required
-		if vs.isEmpty(field.Value) && !field.Required {
-			continue
-		}
+// fail records a failure of the field called name.
+func (vs *ValidatorSet) fail(name, message string) {
+	if _, ok := vs.errors[name]; !ok {
+		vs.errors[name] = message
+	}
+	vs.allErrors[name] = append(vs.allErrors[name], message)
+}
 
-		// Run field validations
-		for _, rule := range field.Rules {
-			if err := rule.Validator(field.Value); err != nil {
-				message := rule.Message
-				if message == "" {
-					message = err.Error()
-				}
-				vs.errors[field.Name] = message
-				if rule.StopOnFail {
-					break
-				}
+// validateField mutates and validates a single field, recording failures
+// under the field's name, and returns the sanitized value
+func (vs *ValidatorSet) validateField(field *Field) any {
+	// Apply mutators so rules and callers see the sanitized value
+	for _, mutate := range field.Mutators {
+		field.Value = mutate(field.Value)
+	}
+	field.Mutators = nil
+
+	// Check if field is required but empty
+	if field.Required && vs.isEmpty(field.Value) {
+		vs.fail(field.Name, fmt.Sprintf("%s is required", field.Name))
+		return field.Value
+	}
+
+	// Skip validation if field is empty and not required
+	if vs.isEmpty(field.Value) {
+		return field.Value
+	}
+
+	// Run field validations
+	for _, rule := range field.Rules {
+		if err := rule.apply(field); err != nil {
+			message := rule.Message
+			var typeErr *TypeError
+			isTypeErr := errors.As(err, &typeErr)
+			if message == "" || isTypeErr {
+				message = err.Error()
+			}
+			vs.fail(field.Name, message)
+			// A value of the wrong type would fail the remaining rules too.
+			if rule.StopOnFail || isTypeErr || !vs.collectAll {
+				break
 			}
 		}
 	}
 
-	return &ValidationResult{
-		Valid:  len(vs.errors) == 0,
-		Errors: vs.errors,
+	// Validate nested elements and members
+	if len(field.items) > 0 {
+		field.Value = vs.validateItems(field)
+	}
+	if len(field.members) > 0 {
+		field.Value = vs.validateMembers(field)
+	}
+	return field.Value
+}
+
+// apply runs rule against field, storing a parsed value on success.
+func (rule *Rule) apply(field *Field) error {
+	if rule.check != nil {
+		return rule.check(field)
+	}
+	if rule.parse == nil {
+		return rule.Validator(field.Value)
 	}
+	parsed, err := rule.parse(field.Value)
+	if err == nil {
+		field.Value = parsed
+	}
+	return err
 }
 
-// isEmpty checks if a value is empty
+// isEmpty checks if a value is empty. Numbers never are, zero included,
+// so Required accepts 0 and the numeric rules check it.
 func (vs *ValidatorSet) isEmpty(value any) bool {
 	if value == nil {
 		return true
@@ -289,6 +409,9 @@ func (vs *ValidatorSet) isEmpty(value any) bool {
 	case []any:
 		return len(v) == 0
 	default:
+		if isNumber(value) {
+			return false
+		}
 		return reflect.ValueOf(value).IsZero()
 	}
 }
@@ -298,7 +421,9 @@ type FieldBuilder struct {
 	field *Field
 }
 
-// Required marks the field as required
+// Required marks the field as required. A number, even 0, counts as
+// present; add Min(1) to reject zero, or bind to a pointer to tell a
+// missing value from 0.
 func (fb *FieldBuilder) Required() *FieldBuilder {
 	fb.field.Required = true
 	return fb
@@ -317,7 +442,7 @@ func (fb *FieldBuilder) MinLength(min int) *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			if utf8.RuneCountInString(str) < min {
 				return fmt.Errorf("must be at least %d characters", min)
@@ -337,7 +462,7 @@ func (fb *FieldBuilder) MaxLength(max int) *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			if utf8.RuneCountInString(str) > max {
 				return fmt.Errorf("must be at most %d characters", max)
@@ -352,13 +477,12 @@ func (fb *FieldBuilder) MaxLength(max int) *FieldBuilder {
 
 // Email adds email validation
 func (fb *FieldBuilder) Email() *FieldBuilder {
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	rule := &Rule{
 		Name: "email",
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			if !emailRegex.MatchString(str) {
 				return fmt.Errorf("must be a valid email address")
@@ -373,13 +497,12 @@ func (fb *FieldBuilder) Email() *FieldBuilder {
 
 // URL adds URL validation
 func (fb *FieldBuilder) URL() *FieldBuilder {
-	urlRegex := regexp.MustCompile(`^https?://[^\s/$.?#].[^\s]*$`)
 	rule := &Rule{
 		Name: "url",
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			if !urlRegex.MatchString(str) {
 				return fmt.Errorf("must be a valid URL")
@@ -392,19 +515,15 @@ func (fb *FieldBuilder) URL() *FieldBuilder {
 	return fb
 }
 
-// Numeric adds numeric validation
+// Numeric adds validation that the field is a number, or a numeric string
+// unless the field is Strict.
 func (fb *FieldBuilder) Numeric() *FieldBuilder {
+	field := fb.field
 	rule := &Rule{
 		Name: "numeric",
 		Validator: func(value any) error {
-			str, ok := value.(string)
-			if !ok {
-				return fmt.Errorf("value must be a string")
-			}
-			if _, err := strconv.ParseFloat(str, 64); err != nil {
-				return fmt.Errorf("must be a number")
-			}
-			return nil
+			_, err := field.number(value)
+			return err
 		},
 		Message: "must be a number",
 	}
@@ -412,17 +531,23 @@ func (fb *FieldBuilder) Numeric() *FieldBuilder {
 	return fb
 }
 
-// Integer adds integer validation
+// Integer adds validation that the field is a whole number, or an integer
+// string unless the field is Strict. Floats such as 3.0, which is how JSON
+// numbers decode, pass.
 func (fb *FieldBuilder) Integer() *FieldBuilder {
+	field := fb.field
 	rule := &Rule{
 		Name: "integer",
 		Validator: func(value any) error {
-			str, ok := value.(string)
-			if !ok {
-				return fmt.Errorf("value must be a string")
+			if str, ok := value.(string); ok && !field.Strict {
+				if _, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64); err != nil {
+					return errNotInteger
+				}
+				return nil
 			}
-			if _, err := strconv.Atoi(str); err != nil {
-				return fmt.Errorf("must be an integer")
+			n, err := field.number(value)
+			if err != nil || n != math.Trunc(n) {
+				return errNotInteger
 			}
 			return nil
 		},
@@ -432,29 +557,17 @@ func (fb *FieldBuilder) Integer() *FieldBuilder {
 	return fb
 }
 
-// Min adds minimum value validation
+// Min adds minimum value validation. A value that is not a number fails
+// with a TypeError rather than the range message.
 func (fb *FieldBuilder) Min(min float64) *FieldBuilder {
+	field := fb.field
 	rule := &Rule{
 		Name: "min",
 		Validator: func(value any) error {
-			var num float64
-			var err error
-
-			switch v := value.(type) {
-			case string:
-				num, err = strconv.ParseFloat(v, 64)
-			case float64:
-				num = v
-			case int:
-				num = float64(v)
-			default:
-				return fmt.Errorf("value must be numeric")
-			}
-
+			num, err := field.number(value)
 			if err != nil {
-				return fmt.Errorf("must be numeric")
+				return err
 			}
-
 			if num < min {
 				return fmt.Errorf("must be at least %g", min)
 			}
@@ -466,29 +579,17 @@ func (fb *FieldBuilder) Min(min float64) *FieldBuilder {
 	return fb
 }
 
-// Max adds maximum value validation
+// Max adds maximum value validation. A value that is not a number fails
+// with a TypeError rather than the range message.
 func (fb *FieldBuilder) Max(max float64) *FieldBuilder {
+	field := fb.field
 	rule := &Rule{
 		Name: "max",
 		Validator: func(value any) error {
-			var num float64
-			var err error
-
-			switch v := value.(type) {
-			case string:
-				num, err = strconv.ParseFloat(v, 64)
-			case float64:
-				num = v
-			case int:
-				num = float64(v)
-			default:
-				return fmt.Errorf("value must be numeric")
-			}
-
+			num, err := field.number(value)
 			if err != nil {
-				return fmt.Errorf("must be numeric")
+				return err
 			}
-
 			if num > max {
 				return fmt.Errorf("must be at most %g", max)
 			}
@@ -512,7 +613,7 @@ func (fb *FieldBuilder) Pattern(pattern string) *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			if !regex.MatchString(str) {
 				return fmt.Errorf("must match pattern %s", pattern)
@@ -532,7 +633,7 @@ func (fb *FieldBuilder) Alpha() *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			for _, r := range str {
 				if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
@@ -554,7 +655,7 @@ func (fb *FieldBuilder) AlphaNumeric() *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			for _, r := range str {
 				if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
@@ -571,13 +672,12 @@ func (fb *FieldBuilder) AlphaNumeric() *FieldBuilder {
 
 // UUID adds UUID validation
 func (fb *FieldBuilder) UUID() *FieldBuilder {
-	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[1-5][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
 	rule := &Rule{
 		Name: "uuid",
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			if !uuidRegex.MatchString(strings.ToLower(str)) {
 				return fmt.Errorf("must be a valid UUID")
@@ -633,7 +733,7 @@ func (fb *FieldBuilder) Date() *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			_, err := time.Parse("2006-01-02", str)
 			if err != nil {
@@ -654,7 +754,7 @@ func (fb *FieldBuilder) DateTime() *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			_, err := time.Parse(time.RFC3339, str)
 			if err != nil {
@@ -686,7 +786,7 @@ func (fb *FieldBuilder) OneOf(values ...string) *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			for _, v := range values {
 				if str == v {
@@ -701,6 +801,29 @@ func (fb *FieldBuilder) OneOf(values ...string) *FieldBuilder {
 	return fb
 }
 
+// Enum adds validation that the field holds one of e's values, as a string
+// or as a value of the enum's type. Fields of a type declared with enum.New
+// get this rule in Struct without a tag.
+func (fb *FieldBuilder) Enum(e enum.Enum) *FieldBuilder {
+	message := "must be one of: " + strings.Join(e.Strings(), ", ")
+	rule := &Rule{
+		Name: "enum",
+		Validator: func(value any) error {
+			str, ok := enum.Value(value)
+			if !ok {
+				return errNotString
+			}
+			if !e.Contains(str) {
+				return errors.New(message)
+			}
+			return nil
+		},
+		Message: message,
+	}
+	fb.field.Rules = append(fb.field.Rules, rule)
+	return fb
+}
+
 // Password adds password validation (at least 8 chars, uppercase, lowercase, number, special)
 func (fb *FieldBuilder) Password() *FieldBuilder {
 	rule := &Rule{
@@ -708,7 +831,7 @@ func (fb *FieldBuilder) Password() *FieldBuilder {
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			
 			if len(str) < 8 {
@@ -748,13 +871,12 @@ func (fb *FieldBuilder) Password() *FieldBuilder {
 
 // Phone adds phone number validation
 func (fb *FieldBuilder) Phone() *FieldBuilder {
-	phoneRegex := regexp.MustCompile(`^\+?[1-9]\d{1,14}$`)
 	rule := &Rule{
 		Name: "phone",
 		Validator: func(value any) error {
 			str, ok := value.(string)
 			if !ok {
-				return fmt.Errorf("value must be a string")
+				return errNotString
 			}
 			if !phoneRegex.MatchString(str) {
 				return fmt.Errorf("must be a valid phone number")
@@ -767,71 +889,93 @@ func (fb *FieldBuilder) Phone() *FieldBuilder {
 	return fb
 }
 
-// JSON adds JSON validation
-func (fb *FieldBuilder) JSON() *FieldBuilder {
+// JSONOption changes what the JSON rule accepts and does.
+type JSONOption int
+
+const (
+	// JSONScalars also accepts documents that are a string, number, boolean
+	// or null. Without it only objects and arrays pass.
+	JSONScalars JSONOption = iota + 1
+	// JSONDecode replaces the field value with the decoded document, so the
+	// rules after JSON, Each and Members validate its contents, and
+	// ValidationResult.Data holds the decoded value.
+	JSONDecode
+)
+
+// JSON adds validation that the field is a string holding a JSON object or
+// array:
+//
+//	vs.Field("settings", form["settings"]).JSON(validate.JSONDecode).Object().Members(
+//		validate.Member("theme", func(f *validate.FieldBuilder) { f.OneOf("light", "dark") }),
+//	)
+func (fb *FieldBuilder) JSON(opts ...JSONOption) *FieldBuilder {
+	scalars := slices.Contains(opts, JSONScalars)
 	rule := &Rule{
 		Name: "json",
 		Validator: func(value any) error {
-			str, ok := value.(string)
-			if !ok {
-				return fmt.Errorf("value must be a string")
-			}
-			if !json.Valid([]byte(str)) {
-				return fmt.Errorf("must be valid JSON")
-			}
-			return nil
+			_, err := decodeJSON(value, scalars)
+			return err
 		},
-		Message: "must be valid JSON",
+		StopOnFail: true,
+	}
+	if slices.Contains(opts, JSONDecode) {
+		rule.parse = func(value any) (any, error) { return decodeJSON(value, scalars) }
 	}
 	fb.field.Rules = append(fb.field.Rules, rule)
 	return fb
 }
 
-// Struct validates a struct using struct tags
+// decodeJSON checks and decodes a JSON document held in a string.
+func decodeJSON(value any, scalars bool) (any, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, errNotString
+	}
+	trimmed := strings.TrimSpace(str)
+	if !json.Valid([]byte(trimmed)) {
+		return nil, errors.New("must be valid JSON")
+	}
+	if !scalars && trimmed[0] != '{' && trimmed[0] != '[' {
+		return nil, errors.New("must be a JSON object or array")
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(trimmed), &doc); err != nil {
+		return nil, errors.New("must be valid JSON")
+	}
+	return doc, nil
+}
+
+// Struct validates a struct using struct tags. Mutator tags (trim,
+// lowercase, escape, normalize_email) are written back to string fields
+// when s is a pointer. The tags of each struct type are parsed once.
 func Struct(s any) *ValidationResult {
-	vs := NewValidatorSet()
-	
 	val := reflect.ValueOf(s)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
-	
-	typ := val.Type()
-	
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := typ.Field(i)
-		
-		// Get field name from JSON tag or field name
-		name := fieldType.Name
-		if tag := fieldType.Tag.Get("json"); tag != "" {
-			if parts := strings.Split(tag, ","); len(parts) > 0 && parts[0] != "" {
-				name = parts[0]
+
+	schema := structSchemaOf(val.Type())
+	vs := NewValidatorSet()
+	vs.fields = make([]*Field, len(schema))
+	for i, sf := range schema {
+		vs.fields[i] = sf.field.instance(sf.field.Name, val.Field(sf.index).Interface())
+	}
+
+	result := vs.Validate()
+	for i, field := range vs.fields {
+		if target := val.Field(schema[i].index); target.CanSet() && target.Kind() == reflect.String {
+			if str, ok := field.Value.(string); ok {
+				target.SetString(str)
 			}
 		}
-		
-		// Skip unexported fields
-		if !field.CanInterface() {
-			continue
-		}
-		
-		fb := vs.Field(name, field.Interface())
-		
-		// Parse validate tag
-		if tag := fieldType.Tag.Get("validate"); tag != "" {
-			fb.parseValidateTag(tag)
-		}
-		
-		// Check if field is required
-		if tag := fieldType.Tag.Get("validate"); strings.Contains(tag, "required") {
-			fb.Required()
-		}
 	}
-	
-	return vs.Validate()
+	return result
 }
 
-// parseValidateTag parses validation tags
+// parseValidateTag parses validation tags. Names registered with
+// RegisterRule are added as rules; other unknown names, such as the
+// go-playground tags omitempty or dive that ValidateStruct handles, are
+// ignored.
 func (fb *FieldBuilder) parseValidateTag(tag string) {
 	rules := strings.Split(tag, ",")
 	
@@ -844,6 +988,8 @@ func (fb *FieldBuilder) parseValidateTag(tag string) {
 			fb.Required()
 		case "optional":
 			fb.Optional()
+		case "strict":
+			fb.Strict()
 		case "email":
 			fb.Email()
 		case "url":
@@ -863,11 +1009,50 @@ func (fb *FieldBuilder) parseValidateTag(tag string) {
 		case "phone":
 			fb.Phone()
 		case "json":
-			fb.JSON()
+			var opts []JSONOption
+			if len(parts) > 1 {
+				for _, opt := range strings.Split(parts[1], "|") {
+					switch opt {
+					case "scalars":
+						opts = append(opts, JSONScalars)
+					case "decode":
+						opts = append(opts, JSONDecode)
+					}
+				}
+			}
+			fb.JSON(opts...)
 		case "date":
 			fb.Date()
 		case "datetime":
 			fb.DateTime()
+		case "trim":
+			fb.Trim()
+		case "lowercase":
+			fb.Lowercase()
+		case "escape":
+			fb.Escape()
+		case "normalize_email":
+			fb.NormalizeEmail()
+		case "array":
+			fb.Array()
+		case "object":
+			fb.Object()
+		case "distinct":
+			fb.Distinct()
+		case "enum":
+			// Struct adds the rule for any field of an enum type.
+		case "minitems":
+			if len(parts) > 1 {
+				if min, err := strconv.Atoi(parts[1]); err == nil {
+					fb.MinItems(min)
+				}
+			}
+		case "maxitems":
+			if len(parts) > 1 {
+				if max, err := strconv.Atoi(parts[1]); err == nil {
+					fb.MaxItems(max)
+				}
+			}
 		case "minlength":
 			if len(parts) > 1 {
 				if min, err := strconv.Atoi(parts[1]); err == nil {
@@ -901,6 +1086,16 @@ func (fb *FieldBuilder) parseValidateTag(tag string) {
 				values := strings.Split(parts[1], "|")
 				fb.OneOf(values...)
 			}
+		case "":
+		default:
+			if _, ok := lookupRule(name); !ok {
+				continue
+			}
+			var params []string
+			if len(parts) > 1 {
+				params = splitParams(parts[1])
+			}
+			fb.Rule(name, params...)
 		}
 	}
 }
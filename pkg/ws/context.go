@@ -20,17 +20,27 @@ const (
 type Connection struct {
 	hub      *Hub
 	conn     *websocket.Conn
+	id       string
 	send     chan []byte
 	userID   string
 	rooms    map[string]bool
 	handlers map[string]func(json.RawMessage)
 	mu       sync.RWMutex
+
+	// closeMsg is the close frame writePump sends once send is closed; it
+	// is set by closeSendWith.
+	closeMsg []byte
+	// sendMu guards closed, so Emit never sends on a closed channel.
+	sendMu sync.Mutex
+	closed bool
 }
 
-// InboundMessage represents a JSON message from the client.
+// InboundMessage represents a JSON message from the client. A message with
+// an ID is acknowledged; see Hub.On.
 type InboundMessage struct {
 	Event string          `json:"event"`
 	Data  json.RawMessage `json:"data"`
+	ID    json.RawMessage `json:"id,omitempty"`
 }
 
 // On registers a handler for a specific event type.
@@ -43,7 +53,7 @@ func (c *Connection) On(event string, handler func(json.RawMessage)) {
 // readPump pumps messages from the websocket connection to the hub.
 func (c *Connection) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.enqueue(c.hub.unregister, c)
 		if err := c.conn.Close(); err != nil {
 			// Log close error
 		}
@@ -71,13 +81,16 @@ func (c *Connection) readPump() {
 		if err := json.Unmarshal(raw, &msg); err != nil {
 			continue
 		}
+		if !c.hub.allowMessage(c, msg) {
+			continue
+		}
 
 		c.mu.RLock()
 		handler, ok := c.handlers[msg.Event]
 		c.mu.RUnlock()
 		if ok {
 			go handler(msg.Data)
-		} else {
+		} else if !c.hub.dispatch(c, msg) {
 			log.Printf("ws: no handler registered for event: %s", msg.Event)
 		}
 	}
@@ -99,7 +112,7 @@ func (c *Connection) writePump() {
 				return
 			}
 			if !ok {
-				if err := c.conn.WriteMessage(websocket.CloseMessage, []byte{}); err != nil {
+				if err := c.conn.WriteMessage(websocket.CloseMessage, c.closeMsg); err != nil {
 					// Connection already closed
 				}
 				return
@@ -138,7 +151,9 @@ func (c *Connection) writePump() {
 	}
 }
 
-// Emit sends a message to this connection.
+// Emit sends a message to this connection. It returns ErrConnectionClosed
+// once the connection is closed and ErrSendBufferFull when the client is
+// not reading fast enough to keep up.
 func (c *Connection) Emit(event string, data any) error {
 	msg := map[string]any{
 		"event": event,
@@ -148,8 +163,39 @@ func (c *Connection) Emit(event string, data any) error {
 	if err != nil {
 		return err
 	}
-	c.send <- bytes
-	return nil
+	return c.trySend(bytes)
+}
+
+// trySend queues msg for writePump without blocking.
+func (c *Connection) trySend(msg []byte) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.closed {
+		return ErrConnectionClosed
+	}
+	select {
+	case c.send <- msg:
+		return nil
+	default:
+		return ErrSendBufferFull
+	}
+}
+
+// closeSend closes send, which makes writePump close the connection.
+func (c *Connection) closeSend() {
+	c.closeSendWith(nil)
+}
+
+// closeSendWith closes send, making writePump send closeMsg as the close
+// frame. Only the first call has an effect.
+func (c *Connection) closeSendWith(closeMsg []byte) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.closed {
+		c.closed = true
+		c.closeMsg = closeMsg
+		close(c.send)
+	}
 }
 
 // Join joins a room.
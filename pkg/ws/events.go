@@ -0,0 +1,175 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/shauryagautam/Astra/pkg/engine/json"
+	"github.com/shauryagautam/Astra/pkg/validate"
+)
+
+var (
+	// ErrConnectionClosed is returned by Emit once the connection is closed.
+	ErrConnectionClosed = errors.New("astra/ws: connection is closed")
+	// ErrSendBufferFull is returned by Emit when the connection's send
+	// buffer is full because the client is not reading.
+	ErrSendBufferFull = errors.New("astra/ws: send buffer is full")
+)
+
+// MessageHandler handles one event sent by a client. payload is the
+// message's data, after any WithSchema validation and mutators.
+type MessageHandler func(conn *Connection, payload json.RawMessage) error
+
+// MessageError rejects a client message with a code and message the client
+// may see. Errors of other types are logged and reported to the client as
+// internal_error, so they cannot leak details.
+type MessageError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("astra/ws: %s: %s", e.Code, e.Message)
+}
+
+// Reject returns a MessageError for a handler to return.
+func Reject(code, message string) error {
+	return &MessageError{Code: code, Message: message}
+}
+
+// HandlerOption configures a handler registered with Hub.On.
+type HandlerOption func(*eventHandler)
+
+// WithSchema validates the message data, which must be a JSON object,
+// before the handler runs. Invalid data is rejected with the
+// validation_failed code and per-field messages; the handler does not run.
+func WithSchema(members ...validate.MemberSchema) HandlerOption {
+	compiled := validate.Compile(members...)
+	return func(eh *eventHandler) { eh.schema = compiled }
+}
+
+type eventHandler struct {
+	handle MessageHandler
+	schema *validate.CompiledSchema
+}
+
+// On registers handler for the events a client sends under pattern, so
+// applications route messages by name rather than switching on them:
+//
+//	hub.On("chat:message", func(conn *ws.Connection, payload json.RawMessage) error {
+//		var msg ChatMessage
+//		if err := json.Unmarshal(payload, &msg); err != nil {
+//			return err
+//		}
+//		return hub.BroadcastToRoom(msg.Room, "chat:message", msg)
+//	}, ws.WithSchema(
+//		validate.Member("room", func(f *validate.FieldBuilder) { f.Required() }),
+//		validate.Member("text", func(f *validate.FieldBuilder) { f.Required().Trim().MaxLength(2000) }),
+//	))
+//
+// A pattern ending in "*" matches every event with that prefix, such as
+// "chat:*", and "*" alone matches everything. An exact pattern wins over
+// wildcards, and a longer wildcard over a shorter one. Handlers set with
+// Connection.On take precedence over the hub's.
+//
+// A handler runs on the connection's read loop, so each client's messages
+// are handled in order. When the message carries an "id", the client gets
+// {"event":"ack","data":{"id":...,"ok":true}} once the handler returns, or
+// "ok":false with an "error" object holding a code, message and any
+// fields. A failed message without an id is reported as an "error" event.
+func (h *Hub) On(pattern string, handler MessageHandler, opts ...HandlerOption) {
+	eh := &eventHandler{handle: handler}
+	for _, opt := range opts {
+		opt(eh)
+	}
+	h.handlersMu.Lock()
+	defer h.handlersMu.Unlock()
+	if h.handlers == nil {
+		h.handlers = make(map[string]*eventHandler)
+	}
+	h.handlers[pattern] = eh
+}
+
+// handlerFor returns the handler whose pattern matches event best.
+func (h *Hub) handlerFor(event string) *eventHandler {
+	h.handlersMu.RLock()
+	defer h.handlersMu.RUnlock()
+	if eh, ok := h.handlers[event]; ok {
+		return eh
+	}
+	var best *eventHandler
+	bestLen := -1
+	for pattern, eh := range h.handlers {
+		prefix, ok := strings.CutSuffix(pattern, "*")
+		if ok && strings.HasPrefix(event, prefix) && len(prefix) > bestLen {
+			best, bestLen = eh, len(prefix)
+		}
+	}
+	return best
+}
+
+// dispatch runs the hub handler for msg and reports whether there was one.
+func (h *Hub) dispatch(conn *Connection, msg InboundMessage) bool {
+	eh := h.handlerFor(msg.Event)
+	if eh == nil {
+		return false
+	}
+	h.acknowledge(conn, msg, eh.run(conn, msg))
+	return true
+}
+
+func (eh *eventHandler) run(conn *Connection, msg InboundMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("astra/ws: handler for %q panicked: %v", msg.Event, r)
+		}
+	}()
+
+	payload := msg.Data
+	if eh.schema != nil {
+		var data map[string]any
+		if len(payload) == 0 || json.Unmarshal(payload, &data) != nil || data == nil {
+			return &MessageError{Code: "invalid_payload", Message: "data must be a JSON object"}
+		}
+		result := eh.schema.Validate(data)
+		if !result.Valid {
+			return &MessageError{Code: "validation_failed", Message: "the given data was invalid", Fields: result.Errors}
+		}
+		for k, v := range result.Data {
+			data[k] = v
+		}
+		if payload, err = json.Marshal(data); err != nil {
+			return err
+		}
+	}
+	return eh.handle(conn, payload)
+}
+
+// acknowledge tells the client how its message was handled: with an ack
+// when the message has an ID, and with an error event when it failed
+// without one.
+func (h *Hub) acknowledge(conn *Connection, msg InboundMessage, err error) {
+	var msgErr *MessageError
+	if err != nil && !errors.As(err, &msgErr) {
+		slog.Error("ws: message handler failed", "event", msg.Event, "user_id", conn.userID, "error", err)
+		msgErr = &MessageError{Code: "internal_error", Message: "the message could not be handled"}
+	}
+
+	var sendErr error
+	switch {
+	case len(msg.ID) > 0:
+		ack := map[string]any{"id": msg.ID, "ok": err == nil}
+		if msgErr != nil {
+			ack["error"] = msgErr
+		}
+		sendErr = conn.Emit("ack", ack)
+	case err != nil:
+		sendErr = conn.Emit("error", map[string]any{"event": msg.Event, "error": msgErr})
+	}
+	if sendErr != nil && !errors.Is(sendErr, ErrConnectionClosed) {
+		slog.Warn("ws: could not acknowledge message", "event", msg.Event, "error", sendErr)
+	}
+}
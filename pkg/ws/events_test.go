@@ -0,0 +1,69 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubOn(t *testing.T) {
+	h := NewHub(nil, "")
+	go h.Run()
+	defer h.Stop(context.Background())
+
+	var got []string
+	h.On("chat:message", func(conn *Connection, payload json.RawMessage) error {
+		got = append(got, "message "+string(payload))
+		return nil
+	}, WithSchema(validate.Member("text", func(f *validate.FieldBuilder) { f.Required().Trim().MaxLength(5) })))
+	h.On("chat:*", func(conn *Connection, payload json.RawMessage) error {
+		got = append(got, "chat:*")
+		return Reject("read_only", "the chat is read-only")
+	})
+	h.On("*", func(conn *Connection, payload json.RawMessage) error {
+		return errors.New("database is down")
+	})
+
+	u := NewUpgrader(h, config.WSConfig{}, true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := u.Upgrade(w, r, "42")
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	roundTrip := func(frame string) string {
+		t.Helper()
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(frame)))
+		require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+		_, reply, err := client.ReadMessage()
+		require.NoError(t, err)
+		return string(reply)
+	}
+
+	assert.JSONEq(t, `{"event":"ack","data":{"id":1,"ok":true}}`,
+		roundTrip(`{"event":"chat:message","id":1,"data":{"text":"  hi  ","room":"lobby"}}`))
+	assert.JSONEq(t, `{"event":"ack","data":{"id":"b","ok":false,"error":{"code":"validation_failed","message":"the given data was invalid","fields":{"text":"must be at most 5 characters"}}}}`,
+		roundTrip(`{"event":"chat:message","id":"b","data":{"text":"far too long"}}`))
+	assert.JSONEq(t, `{"event":"ack","data":{"id":3,"ok":false,"error":{"code":"invalid_payload","message":"data must be a JSON object"}}}`,
+		roundTrip(`{"event":"chat:message","id":3,"data":"hi"}`))
+	assert.JSONEq(t, `{"event":"ack","data":{"id":4,"ok":false,"error":{"code":"read_only","message":"the chat is read-only"}}}`,
+		roundTrip(`{"event":"chat:typing","id":4}`))
+	assert.JSONEq(t, `{"event":"error","data":{"event":"presence:ping","error":{"code":"internal_error","message":"the message could not be handled"}}}`,
+		roundTrip(`{"event":"presence:ping"}`))
+
+	assert.Equal(t, []string{`message {"room":"lobby","text":"hi"}`, "chat:*"}, got)
+}
@@ -2,14 +2,22 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bytedance/sonic"
+	"github.com/gorilla/websocket"
 	"github.com/redis/go-redis/v9"
 )
 
+// DefaultQueueSize is the buffer of the hub's register, unregister and
+// broadcast queues.
+const DefaultQueueSize = 256
+
 // Hub manages active WebSocket connections and rooms.
 type Hub struct {
 	// Registered connections
@@ -30,41 +38,98 @@ type Hub struct {
 	redis redis.UniversalClient
 	rChan string
 
+	// handlers holds the event handlers registered with On.
+	handlers   map[string]*eventHandler
+	handlersMu sync.RWMutex
+
+	queueSize        int
+	watchdogInterval time.Duration
+	onStall          func(HubStats)
+
+	messageLimiter     Limiter
+	messageLimitAction RateLimitAction
+
+	// processed counts loop iterations so the watchdog can tell a busy loop
+	// from a stuck one; active mirrors len(connections) without the lock.
+	processed atomic.Uint64
+	active    atomic.Int64
+	stalled   atomic.Bool
+	stalls    atomic.Int64
+
+	running  atomic.Bool
+	done     chan struct{}
 	stop     chan struct{}
 	stopOnce sync.Once
 	mu       sync.RWMutex
 }
 
+// HubOption configures a Hub.
+type HubOption func(*Hub)
+
+// WithQueueSize sets the buffer of the register, unregister and broadcast
+// queues. The default is DefaultQueueSize.
+func WithQueueSize(n int) HubOption {
+	return func(h *Hub) { h.queueSize = n }
+}
+
+// WithWatchdog checks the hub loop every interval, the default being
+// DefaultWatchdogInterval, and calls onStall when it is stalled or its
+// queues are full; see HubStats. A zero interval disables the watchdog.
+// onStall may be nil, as stalls are logged either way.
+func WithWatchdog(interval time.Duration, onStall func(HubStats)) HubOption {
+	return func(h *Hub) {
+		h.watchdogInterval = interval
+		h.onStall = onStall
+	}
+}
+
 // NewHub creates a new Hub.
-func NewHub(redis redis.UniversalClient, rChan string) *Hub {
-	return &Hub{
-		broadcast:   make(chan []byte),
-		register:    make(chan *Connection),
-		unregister:  make(chan *Connection),
-		connections: make(map[*Connection]bool),
-		rooms:       make(map[string]map[*Connection]bool),
-		redis:       redis,
-		rChan:       rChan,
-		stop:        make(chan struct{}),
+func NewHub(redis redis.UniversalClient, rChan string, opts ...HubOption) *Hub {
+	h := &Hub{
+		connections:      make(map[*Connection]bool),
+		rooms:            make(map[string]map[*Connection]bool),
+		redis:            redis,
+		rChan:            rChan,
+		queueSize:        DefaultQueueSize,
+		watchdogInterval: DefaultWatchdogInterval,
+		done:             make(chan struct{}),
+		stop:             make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.broadcast = make(chan []byte, h.queueSize)
+	h.register = make(chan *Connection, h.queueSize)
+	h.unregister = make(chan *Connection, h.queueSize)
+	return h
 }
 
-// Run starts the hub loop and optionally the Redis subscription.
+// Run starts the hub loop, its watchdog and optionally the Redis
+// subscription. It returns once Stop is called.
 func (h *Hub) Run() {
+	h.running.Store(true)
+	defer close(h.done)
 	if h.redis != nil {
 		go h.listenRedis()
 	}
+	if h.watchdogInterval > 0 {
+		go h.watch()
+	}
 
 	for {
 		select {
 		case conn := <-h.register:
 			h.mu.Lock()
-			h.connections[conn] = true
+			if !h.connections[conn] {
+				h.connections[conn] = true
+				h.active.Add(1)
+			}
 			h.mu.Unlock()
 		case conn := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.connections[conn]; ok {
 				delete(h.connections, conn)
+				h.active.Add(-1)
 				for room := range conn.rooms {
 					if _, ok := h.rooms[room]; ok {
 						delete(h.rooms[room], conn)
@@ -73,38 +138,61 @@ func (h *Hub) Run() {
 						}
 					}
 				}
-				close(conn.send)
+				conn.closeSend()
 			}
 			h.mu.Unlock()
 		case message := <-h.broadcast:
 			h.mu.Lock()
 			for conn := range h.connections {
-				select {
-				case conn.send <- message:
-				default:
-					close(conn.send)
+				// trySend, since readPump may close send at any time, as
+				// when the client trips the message rate limit.
+				if errors.Is(conn.trySend(message), ErrSendBufferFull) {
+					conn.closeSend()
 					delete(h.connections, conn)
+					h.active.Add(-1)
 				}
 			}
 			h.mu.Unlock()
 		case <-h.stop:
 			h.mu.Lock()
+			closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
 			for conn := range h.connections {
-				close(conn.send)
+				conn.closeSendWith(closeMsg)
 				delete(h.connections, conn)
 			}
+			h.active.Store(0)
 			h.mu.Unlock()
 			return
 		}
+		h.processed.Add(1)
 	}
 }
 
-// Stop signals the hub to shut down.
+// Stop shuts the hub down. Every connection is closed with a 1001 (going
+// away) close frame so clients know to reconnect elsewhere. If Run is
+// running, Stop waits for it to return or for ctx to be done.
 func (h *Hub) Stop(ctx context.Context) error {
 	h.stopOnce.Do(func() {
 		close(h.stop)
 	})
-	return nil
+	if !h.running.Load() {
+		return nil
+	}
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue sends conn to queue unless the hub stops first, so connections
+// do not block forever on a hub that is gone.
+func (h *Hub) enqueue(queue chan<- *Connection, conn *Connection) {
+	select {
+	case queue <- conn:
+	case <-h.stop:
+	}
 }
 
 func (h *Hub) listenRedis() {
@@ -159,11 +247,8 @@ func (h *Hub) broadcastToRoomLocal(room string, event string, data any) error {
 
 	if connections, ok := h.rooms[room]; ok {
 		for conn := range connections {
-			select {
-			case conn.send <- bytes:
-			default:
-				// handled by unregister
-			}
+			// A full or closed connection is dropped by unregister.
+			_ = conn.trySend(bytes)
 		}
 	}
 	return nil
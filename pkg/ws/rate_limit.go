@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrRateLimited is returned by Upgrade when the client has made too many
+// upgrade attempts; the client has been answered with 429.
+var ErrRateLimited = errors.New("astra/ws: too many connection attempts")
+
+// Limiter reports whether one more event is allowed for key. The hub keys
+// message limits by connection and the upgrader keys attempt limits by
+// client IP.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+}
+
+// LimiterFunc adapts a function to Limiter, for example to share
+// http.RateLimitAttempt's Redis buckets across nodes:
+//
+//	ws.LimiterFunc(func(ctx context.Context, key string) (bool, error) {
+//		res, err := http.RateLimitAttempt(ctx, rdb, "ws:"+key, 10, time.Minute, http.SlidingWindow)
+//		return res.Allowed, err
+//	})
+type LimiterFunc func(ctx context.Context, key string) (bool, error)
+
+// Allow calls f.
+func (f LimiterFunc) Allow(ctx context.Context, key string) (bool, error) {
+	return f(ctx, key)
+}
+
+// MemoryLimiter is a token bucket per key, kept in process memory. Each key
+// may burst up to limit events and then gets limit events per window.
+type MemoryLimiter struct {
+	limit  float64
+	window time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryLimiter returns a MemoryLimiter allowing limit events per window
+// for each key.
+func NewMemoryLimiter(limit int, window time.Duration) *MemoryLimiter {
+	return &MemoryLimiter{
+		limit:     float64(limit),
+		window:    window,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from key's bucket if one is left.
+func (l *MemoryLimiter) Allow(_ context.Context, key string) (bool, error) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Buckets idle for a whole window are full again, so dropping them
+	// changes nothing and keeps closed connections from piling up.
+	if now.Sub(l.lastSweep) > l.window {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > l.window {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.limit, b.tokens+now.Sub(b.last).Seconds()*l.limit/l.window.Seconds())
+	b.last = now
+	if b.tokens < 1 {
+		return false, nil
+	}
+	b.tokens--
+	return true, nil
+}
+
+// RateLimitAction is what the hub does with a message over the limit.
+type RateLimitAction int
+
+const (
+	// RateLimitDrop discards the message. A message with an id is acked
+	// with the rate_limited code.
+	RateLimitDrop RateLimitAction = iota
+	// RateLimitWarn handles the message anyway and sends the client a
+	// rate_limit event, for rolling out limits before enforcing them.
+	RateLimitWarn
+	// RateLimitDisconnect closes the connection with a 1008 (policy
+	// violation) close frame.
+	RateLimitDisconnect
+)
+
+// WithMessageRateLimit limits how many messages each connection may send,
+// handling messages over the limit according to action:
+//
+//	ws.NewHub(rdb, "ws", ws.WithMessageRateLimit(ws.NewMemoryLimiter(20, time.Second), ws.RateLimitDrop))
+//
+// If limiter fails, the message is handled and the error logged, so an
+// outage of a shared limiter does not cut every client off.
+func WithMessageRateLimit(limiter Limiter, action RateLimitAction) HubOption {
+	return func(h *Hub) {
+		h.messageLimiter = limiter
+		h.messageLimitAction = action
+	}
+}
+
+// allowMessage applies the message rate limit to msg and reports whether it
+// should be handled.
+func (h *Hub) allowMessage(conn *Connection, msg InboundMessage) bool {
+	if h.messageLimiter == nil {
+		return true
+	}
+	ok, err := h.messageLimiter.Allow(context.Background(), "conn:"+conn.id)
+	if err != nil {
+		slog.Warn("ws: message rate limiter failed", "error", err)
+		return true
+	}
+	if ok {
+		return true
+	}
+
+	slog.Warn("ws: client is over its message rate limit", "user_id", conn.userID, "event", msg.Event)
+	switch h.messageLimitAction {
+	case RateLimitWarn:
+		_ = conn.Emit("rate_limit", map[string]any{"event": msg.Event, "message": "slow down: you are sending messages too quickly"})
+		return true
+	case RateLimitDisconnect:
+		conn.closeSendWith(websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "rate limit exceeded"))
+		return false
+	default:
+		if len(msg.ID) > 0 {
+			h.acknowledge(conn, msg, &MessageError{Code: "rate_limited", Message: "too many messages"})
+		}
+		return false
+	}
+}
+
+// UpgraderOption configures an Upgrader.
+type UpgraderOption func(*Upgrader)
+
+// WithUpgradeRateLimit limits the upgrade attempts of each client IP.
+// Attempts over the limit are answered with 429 and Upgrade returns
+// ErrRateLimited; if limiter fails, they are answered with 500.
+func WithUpgradeRateLimit(limiter Limiter) UpgraderOption {
+	return func(u *Upgrader) { u.upgradeLimiter = limiter }
+}
+
+// WithClientIP sets how the upgrader finds the client IP for
+// WithUpgradeRateLimit, such as http.GetClientIP with the trusted proxies
+// behind a load balancer. The default is the connection's remote address.
+func WithClientIP(fn func(*http.Request) string) UpgraderOption {
+	return func(u *Upgrader) { u.clientIP = fn }
+}
+
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// allowUpgrade applies the upgrade rate limit, answering the request when
+// it is refused.
+func (u *Upgrader) allowUpgrade(w http.ResponseWriter, r *http.Request) error {
+	if u.upgradeLimiter == nil {
+		return nil
+	}
+	ok, err := u.upgradeLimiter.Allow(r.Context(), "ip:"+u.clientIP(r))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return err
+	}
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return ErrRateLimited
+	}
+	return nil
+}
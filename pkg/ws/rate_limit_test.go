@@ -0,0 +1,172 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	l := NewMemoryLimiter(2, 50*time.Millisecond)
+	ctx := context.Background()
+	for _, want := range []bool{true, true, false} {
+		ok, err := l.Allow(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, want, ok)
+	}
+	ok, _ := l.Allow(ctx, "b")
+	assert.True(t, ok, "keys have separate buckets")
+
+	time.Sleep(30 * time.Millisecond)
+	ok, _ = l.Allow(ctx, "a")
+	assert.True(t, ok, "tokens refill over the window")
+}
+
+// dialHub starts a hub with opts behind an upgrader with uopts and returns
+// the server and a function dialing it.
+func dialHub(t *testing.T, opts []HubOption, uopts ...UpgraderOption) (*Hub, func() (*websocket.Conn, *http.Response, error)) {
+	t.Helper()
+	h := NewHub(nil, "", opts...)
+	go h.Run()
+	t.Cleanup(func() { _ = h.Stop(context.Background()) })
+	u := NewUpgrader(h, config.WSConfig{}, true, uopts...)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = u.Upgrade(w, r, "42")
+	}))
+	t.Cleanup(srv.Close)
+	return h, func() (*websocket.Conn, *http.Response, error) {
+		return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	}
+}
+
+func TestMessageRateLimit(t *testing.T) {
+	// read returns the next frame; writePump joins queued frames with
+	// newlines into one WebSocket message.
+	var pending []string
+	read := func(t *testing.T, client *websocket.Conn) map[string]any {
+		t.Helper()
+		if len(pending) == 0 {
+			require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+			_, raw, err := client.ReadMessage()
+			require.NoError(t, err)
+			pending = strings.Split(string(raw), "\n")
+		}
+		var frame map[string]any
+		require.NoError(t, json.Unmarshal([]byte(pending[0]), &frame))
+		pending = pending[1:]
+		return frame
+	}
+	send := func(t *testing.T, client *websocket.Conn, id int) {
+		t.Helper()
+		frame := `{"event":"ping","id":` + string(rune('0'+id)) + `}`
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(frame)))
+	}
+	ping := func(conn *Connection, _ json.RawMessage) error { return nil }
+
+	t.Run("drop", func(t *testing.T) {
+		h, dial := dialHub(t, []HubOption{WithMessageRateLimit(NewMemoryLimiter(1, time.Hour), RateLimitDrop)})
+		h.On("ping", ping)
+		client, _, err := dial()
+		require.NoError(t, err)
+		defer client.Close()
+
+		send(t, client, 1)
+		send(t, client, 2)
+		assert.Equal(t, true, read(t, client)["data"].(map[string]any)["ok"])
+		second := read(t, client)["data"].(map[string]any)
+		assert.Equal(t, false, second["ok"])
+		assert.Equal(t, "rate_limited", second["error"].(map[string]any)["code"])
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		h, dial := dialHub(t, []HubOption{WithMessageRateLimit(NewMemoryLimiter(1, time.Hour), RateLimitWarn)})
+		h.On("ping", ping)
+		client, _, err := dial()
+		require.NoError(t, err)
+		defer client.Close()
+
+		send(t, client, 1)
+		send(t, client, 2)
+		assert.Equal(t, "ack", read(t, client)["event"])
+		assert.Equal(t, "rate_limit", read(t, client)["event"])
+		assert.Equal(t, true, read(t, client)["data"].(map[string]any)["ok"], "the message is still handled")
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		h, dial := dialHub(t, []HubOption{WithMessageRateLimit(NewMemoryLimiter(1, time.Hour), RateLimitDisconnect)})
+		h.On("ping", ping)
+		client, _, err := dial()
+		require.NoError(t, err)
+		defer client.Close()
+
+		send(t, client, 1)
+		send(t, client, 2)
+		assert.Equal(t, "ack", read(t, client)["event"])
+		_, _, err = client.ReadMessage()
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	})
+}
+
+func TestUpgradeRateLimit(t *testing.T) {
+	_, dial := dialHub(t, nil, WithUpgradeRateLimit(NewMemoryLimiter(1, time.Hour)))
+
+	first, _, err := dial()
+	require.NoError(t, err)
+	defer first.Close()
+
+	_, resp, err := dial()
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestMessageRateLimit_DisconnectDuringBroadcast(t *testing.T) {
+	h, dial := dialHub(t, []HubOption{WithMessageRateLimit(NewMemoryLimiter(1, time.Hour), RateLimitDisconnect)})
+	h.On("join", func(conn *Connection, _ json.RawMessage) error {
+		h.JoinRoom(conn, "lobby")
+		return nil
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case h.broadcast <- []byte(`{"event":"news"}`):
+			}
+			_ = h.BroadcastToRoom("lobby", "news", nil)
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+
+	for range 10 {
+		client, _, err := dial()
+		require.NoError(t, err)
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"event":"join","id":1}`)))
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"event":"join","id":2}`)))
+
+		require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+		var closeErr *websocket.CloseError
+		for {
+			if _, _, err = client.ReadMessage(); err != nil {
+				break
+			}
+		}
+		require.ErrorAs(t, err, &closeErr, "broadcasts to a disconnected client do not panic the hub")
+		assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+		client.Close()
+	}
+}
@@ -1,11 +1,13 @@
 package ws
 
 import (
+	"fmt"
 	"github.com/shauryagautam/Astra/pkg/engine/json"
 	"net/http"
 	"time"
 
 	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/shauryagautam/Astra/pkg/support/secure"
 	"github.com/gorilla/websocket"
 )
 
@@ -19,10 +21,13 @@ var defaultUpgrader = websocket.Upgrader{
 type Upgrader struct {
 	upgrader websocket.Upgrader
 	hub      *Hub
+
+	upgradeLimiter Limiter
+	clientIP       func(*http.Request) string
 }
 
 // NewUpgrader creates a new WS upgrader.
-func NewUpgrader(hub *Hub, wsConfig config.WSConfig, isDev bool) *Upgrader {
+func NewUpgrader(hub *Hub, wsConfig config.WSConfig, isDev bool, opts ...UpgraderOption) *Upgrader {
 	upgrader := defaultUpgrader
 	upgrader.CheckOrigin = func(r *http.Request) bool {
 		if isDev {
@@ -40,14 +45,27 @@ func NewUpgrader(hub *Hub, wsConfig config.WSConfig, isDev bool) *Upgrader {
 		return false
 	}
 
-	return &Upgrader{
+	u := &Upgrader{
 		upgrader: upgrader,
 		hub:      hub,
+		clientIP: remoteIP,
+	}
+	for _, opt := range opts {
+		opt(u)
 	}
+	return u
 }
 
 // Upgrade upgrades the HTTP request to a WS connection.
 func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, userID string) (*Connection, error) {
+	if err := u.allowUpgrade(w, r); err != nil {
+		return nil, err
+	}
+	id, err := secure.Hex(8)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, fmt.Errorf("astra/ws: connection id: %w", err)
+	}
 	conn, err := u.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return nil, err
@@ -56,13 +74,14 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, userID string
 	c := &Connection{
 		hub:      u.hub,
 		conn:     conn,
+		id:       id,
 		send:     make(chan []byte, 256),
 		userID:   userID,
 		rooms:    make(map[string]bool),
 		handlers: make(map[string]func(json.RawMessage)),
 	}
 
-	c.hub.register <- c
+	c.hub.enqueue(c.hub.register, c)
 
 	go c.writePump()
 	go c.readPump()
@@ -0,0 +1,134 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// DefaultWatchdogInterval is how often the watchdog checks the hub loop.
+const DefaultWatchdogInterval = 5 * time.Second
+
+// ErrHubStalled is returned by CheckHealth while the hub loop is stalled.
+var ErrHubStalled = errors.New("astra/ws: hub loop is stalled")
+
+// HubStats is a snapshot of the hub loop.
+type HubStats struct {
+	Connections int64 `json:"connections"`
+	// Queued is the number of register, unregister and broadcast requests
+	// waiting for the loop, out of Capacity.
+	Queued   int `json:"queued"`
+	Capacity int `json:"capacity"`
+	// Stalled is set when requests were queued for a whole watchdog
+	// interval without the loop handling any of them.
+	Stalled bool `json:"stalled"`
+	// Saturated is set when the queues are full, so connections block on
+	// registering.
+	Saturated bool `json:"saturated"`
+	// Stalls counts the watchdog checks that found the loop stalled or
+	// saturated.
+	Stalls int64 `json:"stalls"`
+}
+
+// Stats returns a snapshot of the hub loop. It does not take the hub's
+// lock, so it answers even while the loop is stuck.
+func (h *Hub) Stats() HubStats {
+	queued := len(h.register) + len(h.unregister) + len(h.broadcast)
+	capacity := cap(h.register) + cap(h.unregister) + cap(h.broadcast)
+	return HubStats{
+		Connections: h.active.Load(),
+		Queued:      queued,
+		Capacity:    capacity,
+		Stalled:     h.stalled.Load(),
+		Saturated:   queued >= capacity,
+		Stalls:      h.stalls.Load(),
+	}
+}
+
+// watch checks every watchdogInterval that the loop makes progress while
+// work is queued, until the hub stops.
+func (h *Hub) watch() {
+	ticker := time.NewTicker(h.watchdogInterval)
+	defer ticker.Stop()
+	last := h.processed.Load()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+		processed := h.processed.Load()
+		stats := h.Stats()
+		stalled := stats.Queued > 0 && processed == last
+		last = processed
+		h.stalled.Store(stalled)
+		stats.Stalled = stalled
+
+		if !stalled && !stats.Saturated {
+			continue
+		}
+		stats.Stalls = h.stalls.Add(1)
+		slog.Warn("ws: hub loop is falling behind",
+			"queued", stats.Queued, "capacity", stats.Capacity,
+			"stalled", stats.Stalled, "connections", stats.Connections)
+		if h.onStall != nil {
+			h.onStall(stats)
+		}
+	}
+}
+
+// CheckHealth implements engine.HealthProvider, failing while the watchdog
+// finds the hub loop stalled.
+func (h *Hub) CheckHealth(context.Context) error {
+	if h.stalled.Load() {
+		return ErrHubStalled
+	}
+	return nil
+}
+
+// HealthDetails implements engine.HealthDetailer.
+func (h *Hub) HealthDetails() map[string]any {
+	s := h.Stats()
+	return map[string]any{
+		"connections": s.Connections,
+		"queued":      s.Queued,
+		"capacity":    s.Capacity,
+		"stalls":      s.Stalls,
+	}
+}
+
+// ObserveHub reports the hub's connections and queue depth as the
+// ws.hub.connections and ws.hub.queue.depth gauges, and watchdog alerts as
+// the ws.hub.stalls counter.
+func ObserveHub(h *Hub, meter metric.Meter) error {
+	connections, err := meter.Int64ObservableGauge("ws.hub.connections",
+		metric.WithDescription("WebSocket connections registered with the hub"))
+	if err != nil {
+		return fmt.Errorf("ws/metrics: failed to create connections gauge: %w", err)
+	}
+	depth, err := meter.Int64ObservableGauge("ws.hub.queue.depth",
+		metric.WithDescription("Requests waiting for the hub loop"))
+	if err != nil {
+		return fmt.Errorf("ws/metrics: failed to create queue depth gauge: %w", err)
+	}
+	stalls, err := meter.Int64ObservableCounter("ws.hub.stalls",
+		metric.WithDescription("Watchdog checks that found the hub loop stalled or saturated"))
+	if err != nil {
+		return fmt.Errorf("ws/metrics: failed to create stalls counter: %w", err)
+	}
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := h.Stats()
+		o.ObserveInt64(connections, s.Connections)
+		o.ObserveInt64(depth, int64(s.Queued))
+		o.ObserveInt64(stalls, s.Stalls)
+		return nil
+	}, connections, depth, stalls)
+	if err != nil {
+		return fmt.Errorf("ws/metrics: failed to register hub callback: %w", err)
+	}
+	return nil
+}
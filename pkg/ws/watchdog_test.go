@@ -0,0 +1,69 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shauryagautam/Astra/pkg/engine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubWatchdog(t *testing.T) {
+	alerts := make(chan HubStats, 1)
+	h := NewHub(nil, "", WithQueueSize(1), WithWatchdog(10*time.Millisecond, func(s HubStats) {
+		select {
+		case alerts <- s:
+		default:
+		}
+	}))
+	// The loop is not running, so queued work never drains.
+	go h.watch()
+	defer h.Stop(context.Background())
+
+	require.NoError(t, h.CheckHealth(context.Background()))
+	h.register <- &Connection{}
+
+	select {
+	case s := <-alerts:
+		assert.True(t, s.Stalled)
+		assert.Equal(t, 1, s.Queued)
+		assert.Equal(t, 3, s.Capacity)
+		assert.GreaterOrEqual(t, s.Stalls, int64(1))
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not report the stalled loop")
+	}
+	assert.ErrorIs(t, h.CheckHealth(context.Background()), ErrHubStalled)
+}
+
+func TestHubStopClosesConnections(t *testing.T) {
+	h := NewHub(nil, "")
+	go h.Run()
+	u := NewUpgrader(h, config.WSConfig{}, true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := u.Upgrade(w, r, "42")
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer client.Close()
+	require.Eventually(t, func() bool { return h.Stats().Connections == 1 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, h.Stop(ctx))
+
+	_, _, err = client.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+	assert.Equal(t, "server shutting down", closeErr.Text)
+	assert.Zero(t, h.Stats().Connections)
+}